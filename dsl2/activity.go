@@ -3,11 +3,34 @@ package dsl
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
+
+	"github.com/temporalio/samples-go/dsl2/blob"
+	"github.com/temporalio/samples-go/dsl2/broker"
 )
 
 // 用于 worker.RegisterActivity(a) 注册其方法
-type Activities struct{}
+type Activities struct {
+	// Exec 是 ExecCommand 的允许列表；为 nil 时 ExecCommand 拒绝执行
+	Exec *ExecPolicy
+	// Blobs 是 BlobPut/BlobGet/BlobList 可用的命名存储
+	Blobs map[string]blob.Store
+	// SMTP 是 SendEmail 使用的邮件服务器；为 nil 时 SendEmail 拒绝执行
+	SMTP *SMTPConfig
+	// Brokers 是 PublishMessage/ConsumeMessage 可用的命名消息系统
+	Brokers map[string]broker.Broker
+}
+
+// ActivityNames 返回 Activities 注册后的 Activity 名（即导出方法名），供校验未知 Activity 使用
+func ActivityNames() []string {
+	t := reflect.TypeOf(&Activities{})
+	names := make([]string, 0, t.NumMethod())
+	for i := 0; i < t.NumMethod(); i++ {
+		names = append(names, t.Method(i).Name)
+	}
+	return names
+}
 
 // 模拟计算/IO 活动
 func (a *Activities) DoA(ctx context.Context, x int64) (string, error) {
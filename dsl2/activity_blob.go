@@ -0,0 +1,162 @@
+package dsl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/temporalio/samples-go/dsl2/blob"
+)
+
+// maxBlobInlineBytes 限制 BlobPut/BlobGet 直接携带的数据大小；数据会进入历史，大文件应只在工作流间传递 key
+const maxBlobInlineBytes = 1 << 20
+
+// defaultBlobStore 是未指定 store 时使用的存储名
+const defaultBlobStore = "default"
+
+// BlobPutInput 是 BlobPut 的入参：
+//
+//	variables:
+//	  save: { store: artifacts, key: reports/daily.json, data: { total: 3 } }
+//	root:
+//	  - activity: { name: BlobPut, args: [{ ref: save }] }
+type BlobPutInput struct {
+	Store string `json:"store,omitempty"` // worker 配置的 blobStores 中的名字，默认 default
+	Key   string `json:"key"`
+	// Data 为字符串时原样写入，其他值编码为 JSON 并默认 Content-Type: application/json
+	Data        any    `json:"data"`
+	Base64      bool   `json:"base64,omitempty"` // Data 是 base64 编码的二进制内容
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// BlobGetInput 是 BlobGet 的入参
+type BlobGetInput struct {
+	Store string `json:"store,omitempty"`
+	Key   string `json:"key"`
+	// Format 为 text / json / base64，默认 text
+	Format string `json:"format,omitempty"`
+}
+
+// BlobGetResult 是 BlobGet 的结果；format 为 json 时 Data 是解析后的值
+type BlobGetResult struct {
+	Key  string `json:"key"`
+	Size int    `json:"size"`
+	Data any    `json:"data"`
+}
+
+// BlobListInput 是 BlobList 的入参
+type BlobListInput struct {
+	Store  string `json:"store,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// BlobPut 把数据写入对象存储
+func (a *Activities) BlobPut(ctx context.Context, in BlobPutInput) (blob.Object, error) {
+	s, err := a.blobStore(in.Store)
+	if err != nil {
+		return blob.Object{}, err
+	}
+	var data []byte
+	contentType := in.ContentType
+	switch d := in.Data.(type) {
+	case nil:
+	case string:
+		data = []byte(d)
+		if in.Base64 {
+			if data, err = base64.StdEncoding.DecodeString(d); err != nil {
+				return blob.Object{}, temporal.NewNonRetryableApplicationError("decode base64 data: "+err.Error(), "InvalidRequest", err)
+			}
+		}
+	default:
+		if data, err = json.Marshal(d); err != nil {
+			return blob.Object{}, temporal.NewNonRetryableApplicationError("encode data: "+err.Error(), "InvalidRequest", err)
+		}
+		if contentType == "" {
+			contentType = "application/json"
+		}
+	}
+	if err := s.Put(ctx, in.Key, data, contentType); err != nil {
+		return blob.Object{}, blobError(in.Key, err)
+	}
+	return blob.Object{Key: in.Key, Size: int64(len(data))}, nil
+}
+
+// BlobGet 读取对象内容；超过 1MB 的对象会被拒绝
+func (a *Activities) BlobGet(ctx context.Context, in BlobGetInput) (BlobGetResult, error) {
+	s, err := a.blobStore(in.Store)
+	if err != nil {
+		return BlobGetResult{}, err
+	}
+	data, err := s.Get(ctx, in.Key)
+	if err != nil {
+		return BlobGetResult{}, blobError(in.Key, err)
+	}
+	if len(data) > maxBlobInlineBytes {
+		return BlobGetResult{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("blob %s is %d bytes, over the %d byte limit", in.Key, len(data), maxBlobInlineBytes), "BlobTooLarge", nil)
+	}
+	out := BlobGetResult{Key: in.Key, Size: len(data)}
+	switch in.Format {
+	case "", "text":
+		out.Data = string(data)
+	case "base64":
+		out.Data = base64.StdEncoding.EncodeToString(data)
+	case "json":
+		if err := json.Unmarshal(data, &out.Data); err != nil {
+			return BlobGetResult{}, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("blob %s is not JSON: %v", in.Key, err), "InvalidResponse", err)
+		}
+	default:
+		return BlobGetResult{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("unknown format %q (want text, json or base64)", in.Format), "InvalidRequest", nil)
+	}
+	return out, nil
+}
+
+// BlobList 列出以 prefix 开头的对象
+func (a *Activities) BlobList(ctx context.Context, in BlobListInput) ([]blob.Object, error) {
+	s, err := a.blobStore(in.Store)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := s.List(ctx, in.Prefix)
+	if err != nil {
+		return nil, blobError(in.Prefix, err)
+	}
+	return objs, nil
+}
+
+// blobStore 按名字查找 worker 上配置的存储
+func (a *Activities) blobStore(name string) (blob.Store, error) {
+	if name == "" {
+		name = defaultBlobStore
+	}
+	s, ok := a.Blobs[name]
+	if !ok {
+		names := make([]string, 0, len(a.Blobs))
+		for n := range a.Blobs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		msg := fmt.Sprintf("blob store %q is not configured on this worker (have: %s)", name, strings.Join(names, ", "))
+		return nil, temporal.NewNonRetryableApplicationError(msg, "InvalidRequest", nil)
+	}
+	return s, nil
+}
+
+// blobError 把对象不存在、key 非法转换为不重试的错误，其余错误（网络、5xx）交给重试策略
+func blobError(key string, err error) error {
+	switch {
+	case errors.Is(err, blob.ErrNotFound):
+		return temporal.NewNonRetryableApplicationError("blob "+key+" not found", "BlobNotFound", err)
+	case errors.Is(err, blob.ErrInvalidKey):
+		return temporal.NewNonRetryableApplicationError(err.Error(), "InvalidRequest", err)
+	}
+	return err
+}
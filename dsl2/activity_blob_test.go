@@ -0,0 +1,50 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/temporalio/samples-go/dsl2/blob"
+)
+
+func TestBlobActivities(t *testing.T) {
+	s, err := blob.Open(blob.Config{Type: blob.TypeFile, Dir: t.TempDir()})
+	require.NoError(t, err)
+	a := &Activities{Blobs: map[string]blob.Store{"default": s}}
+	ctx := context.Background()
+
+	obj, err := a.BlobPut(ctx, BlobPutInput{Key: "r/1.json", Data: map[string]any{"total": 3}})
+	require.NoError(t, err)
+	require.Equal(t, int64(11), obj.Size)
+	_, err = a.BlobPut(ctx, BlobPutInput{Key: "r/2.bin", Data: "AAEC", Base64: true})
+	require.NoError(t, err)
+
+	got, err := a.BlobGet(ctx, BlobGetInput{Key: "r/1.json", Format: "json"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"total": float64(3)}, got.Data)
+	got, err = a.BlobGet(ctx, BlobGetInput{Key: "r/2.bin", Format: "base64"})
+	require.NoError(t, err)
+	require.Equal(t, "AAEC", got.Data)
+	require.Equal(t, 3, got.Size)
+
+	objs, err := a.BlobList(ctx, BlobListInput{Prefix: "r/"})
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+
+	var appErr *temporal.ApplicationError
+	_, err = a.BlobGet(ctx, BlobGetInput{Key: "r/none"})
+	require.True(t, errors.As(err, &appErr))
+	require.True(t, appErr.NonRetryable())
+	require.Equal(t, "BlobNotFound", appErr.Type())
+
+	_, err = a.BlobPut(ctx, BlobPutInput{Key: "../x", Data: "x"})
+	require.True(t, errors.As(err, &appErr))
+	require.True(t, appErr.NonRetryable())
+
+	_, err = a.BlobList(ctx, BlobListInput{Store: "archive"})
+	require.ErrorContains(t, err, `blob store "archive" is not configured on this worker (have: default)`)
+}
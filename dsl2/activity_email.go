@@ -0,0 +1,350 @@
+package dsl
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+// maxEmailAttachmentBytes 限制全部附件的总大小
+const maxEmailAttachmentBytes = 20 << 20
+
+// SMTPConfig 是 worker 上 SendEmail 使用的邮件服务器；未配置时 SendEmail 不可用
+//
+//	smtp:
+//	  host: smtp.example.com
+//	  port: 587            # 默认 587（STARTTLS）；implicitTLS 时通常为 465
+//	  username: dsl-bot
+//	  password: ...        # 为空时读 SMTP_PASSWORD
+//	  from: "DSL Bot <dsl-bot@example.com>"
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	From     string `yaml:"from"`
+	// ImplicitTLS 为 true 时直接建立 TLS 连接（SMTPS），否则在服务器支持时使用 STARTTLS
+	ImplicitTLS bool `yaml:"implicitTLS,omitempty"`
+}
+
+// SendEmailInput 是 SendEmail 的第一个入参；To、Cc、Subject 与 Body 都是 text/template 模板，
+// . 为 SendEmail 的第二个入参（可省略）：
+//
+//	variables:
+//	  approval:
+//	    to: ["{{.manager}}"]
+//	    subject: "Approve order {{.id}}"
+//	    body: "Order {{.id}} needs your approval: {{.total}} EUR"
+//	    attachments: [{ store: artifacts, key: "quotes/{{.id}}.pdf" }]
+//	root:
+//	  - activity: { name: SendEmail, args: [{ ref: approval }, { ref: order }] }
+type SendEmailInput struct {
+	To      []string `json:"to"`
+	Cc      []string `json:"cc,omitempty"`
+	Subject string   `json:"subject"`
+	Body    string   `json:"body"`
+	HTML    bool     `json:"html,omitempty"` // Body 是 HTML
+	// Attachments 从对象存储读取（见 BlobPut）
+	Attachments []EmailAttachment `json:"attachments,omitempty"`
+}
+
+// EmailAttachment 是一个附件；Key 也是模板
+type EmailAttachment struct {
+	Store       string `json:"store,omitempty"`
+	Key         string `json:"key"`
+	Filename    string `json:"filename,omitempty"`    // 默认取 key 的最后一段
+	ContentType string `json:"contentType,omitempty"` // 默认按扩展名判断
+}
+
+// SendEmailResult 是 SendEmail 的结果
+type SendEmailResult struct {
+	MessageID  string   `json:"messageId"`
+	Recipients []string `json:"recipients"`
+}
+
+// SendEmail 渲染并通过 worker 配置的 SMTP 服务器发送邮件，用于审批请求与完成报告
+func (a *Activities) SendEmail(ctx context.Context, in SendEmailInput, data any) (SendEmailResult, error) {
+	if a.SMTP == nil || a.SMTP.Host == "" {
+		return SendEmailResult{}, temporal.NewNonRetryableApplicationError(
+			"SendEmail is disabled on this worker; configure smtp", "InvalidRequest", nil)
+	}
+	msg, err := in.render(data)
+	if err != nil {
+		return SendEmailResult{}, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidRequest", err)
+	}
+	from, err := mail.ParseAddress(a.SMTP.From)
+	if err != nil {
+		return SendEmailResult{}, temporal.NewNonRetryableApplicationError("smtp.from: "+err.Error(), "InvalidRequest", err)
+	}
+	var files []attachmentFile
+	total := 0
+	for _, att := range msg.Attachments {
+		s, err := a.blobStore(att.Store)
+		if err != nil {
+			return SendEmailResult{}, err
+		}
+		content, err := s.Get(ctx, att.Key)
+		if err != nil {
+			return SendEmailResult{}, blobError(att.Key, err)
+		}
+		if total += len(content); total > maxEmailAttachmentBytes {
+			return SendEmailResult{}, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("attachments exceed %d bytes", maxEmailAttachmentBytes), "InvalidRequest", nil)
+		}
+		files = append(files, attachmentFile{att, content})
+	}
+
+	id := newMessageID(from.Address)
+	raw, err := buildEmail(from, msg, files, id, time.Now())
+	if err != nil {
+		return SendEmailResult{}, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidRequest", err)
+	}
+	rcpts := append(append([]string{}, msg.to...), msg.cc...)
+	if err := a.SMTP.send(ctx, from.Address, rcpts, raw); err != nil {
+		return SendEmailResult{}, smtpError(err)
+	}
+	return SendEmailResult{MessageID: id, Recipients: rcpts}, nil
+}
+
+// renderedEmail 是渲染后的 SendEmailInput，地址已解析为纯邮箱
+type renderedEmail struct {
+	SendEmailInput
+	to, cc []string
+}
+
+func (in SendEmailInput) render(data any) (renderedEmail, error) {
+	out := renderedEmail{SendEmailInput: in}
+	var err error
+	if out.Subject, err = renderTemplate("subject", in.Subject, data); err != nil {
+		return out, err
+	}
+	if out.Body, err = renderTemplate("body", in.Body, data); err != nil {
+		return out, err
+	}
+	if out.To, out.to, err = renderAddresses("to", in.To, data); err != nil {
+		return out, err
+	}
+	if out.Cc, out.cc, err = renderAddresses("cc", in.Cc, data); err != nil {
+		return out, err
+	}
+	if len(out.to) == 0 {
+		return out, errors.New("to is required")
+	}
+	out.Attachments = make([]EmailAttachment, len(in.Attachments))
+	for i, att := range in.Attachments {
+		if att.Key, err = renderTemplate("attachment", att.Key, data); err != nil {
+			return out, err
+		}
+		if att.Filename == "" {
+			att.Filename = path.Base(att.Key)
+		}
+		out.Attachments[i] = att
+	}
+	return out, nil
+}
+
+// renderAddresses 渲染地址模板；一个模板可展开为逗号分隔的多个地址
+func renderAddresses(field string, tmpls []string, data any) (headers, addrs []string, err error) {
+	for _, t := range tmpls {
+		s, err := renderTemplate(field, t, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		list, err := mail.ParseAddressList(s)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s %q: %w", field, s, err)
+		}
+		for _, a := range list {
+			headers = append(headers, a.String())
+			addrs = append(addrs, a.Address)
+		}
+	}
+	return headers, addrs, nil
+}
+
+type attachmentFile struct {
+	EmailAttachment
+	content []byte
+}
+
+// buildEmail 生成 RFC 5322 邮件；有附件时为 multipart/mixed
+func buildEmail(from *mail.Address, msg renderedEmail, files []attachmentFile, id string, now time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", from.String())
+	header("To", strings.Join(msg.To, ", "))
+	if len(msg.Cc) > 0 {
+		header("Cc", strings.Join(msg.Cc, ", "))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", id)
+	header("MIME-Version", "1.0")
+	bodyType := "text/plain; charset=utf-8"
+	if msg.HTML {
+		bodyType = "text/html; charset=utf-8"
+	}
+	if len(files) == 0 {
+		header("Content-Type", bodyType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		b.WriteString("\r\n")
+		if err := writeQuotedPrintable(&b, msg.Body); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&b)
+	header("Content-Type", `multipart/mixed; boundary="`+mw.Boundary()+`"`)
+	b.WriteString("\r\n")
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {bodyType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeQuotedPrintable(part, msg.Body); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		ct := f.ContentType
+		if ct == "" {
+			if ct = mime.TypeByExtension(path.Ext(f.Filename)); ct == "" {
+				ct = "application/octet-stream"
+			}
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {ct},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": f.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		enc := base64.StdEncoding.EncodeToString(f.content)
+		for len(enc) > 76 {
+			fmt.Fprintf(part, "%s\r\n", enc[:76])
+			enc = enc[76:]
+		}
+		fmt.Fprintf(part, "%s\r\n", enc)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func newMessageID(from string) string {
+	var r [12]byte
+	rand.Read(r[:])
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	return "<" + hex.EncodeToString(r[:]) + "@" + domain + ">"
+}
+
+// send 投递一封邮件；在服务器支持时使用 STARTTLS，配置了用户名时使用 PLAIN 认证
+func (c *SMTPConfig) send(ctx context.Context, from string, to []string, msg []byte) error {
+	port := c.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if c.ImplicitTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: c.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok && !c.ImplicitTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: c.Host}); err != nil {
+			return err
+		}
+	}
+	if c.Username != "" {
+		password := c.Password
+		if password == "" {
+			password = os.Getenv("SMTP_PASSWORD")
+		}
+		if err := client.Auth(smtp.PlainAuth("", c.Username, password, c.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// smtpError 让 5xx 永久性错误（地址被拒、认证失败等）不再重试
+func smtpError(err error) error {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) && tpErr.Code >= 500 {
+		return temporal.NewNonRetryableApplicationError("smtp: "+err.Error(), "SMTPRejected", err, tpErr.Code)
+	}
+	return err
+}
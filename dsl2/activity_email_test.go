@@ -0,0 +1,147 @@
+package dsl
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/temporalio/samples-go/dsl2/blob"
+)
+
+// fakeSMTP 接受一封邮件并把 RCPT 与 DATA 发送到 ch；rejectRcpt 中的地址返回 550
+func fakeSMTP(t *testing.T, rejectRcpt string) (port int, ch chan []string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	ch = make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { fmt.Fprintf(conn, "%s\r\n", s) }
+		reply("220 fake ESMTP")
+		var got []string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250-fake\r\n250 AUTH PLAIN")
+			case strings.HasPrefix(cmd, "AUTH"):
+				got = append(got, strings.TrimSpace(line))
+				reply("235 ok")
+			case strings.HasPrefix(cmd, "RCPT") && rejectRcpt != "" && strings.Contains(line, rejectRcpt):
+				reply("550 no such user")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				got = append(got, strings.TrimSpace(line))
+				reply("250 ok")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				got = append(got, data.String())
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				ch <- got
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, ch
+}
+
+func TestSendEmail(t *testing.T) {
+	ctx := context.Background()
+	_, err := (&Activities{}).SendEmail(ctx, SendEmailInput{To: []string{"a@example.com"}}, nil)
+	require.ErrorContains(t, err, "SendEmail is disabled")
+
+	store, err := blob.Open(blob.Config{Type: blob.TypeFile, Dir: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, "quotes/A-1.txt", []byte("quote body"), ""))
+
+	port, ch := fakeSMTP(t, "")
+	a := &Activities{
+		Blobs: map[string]blob.Store{"default": store},
+		SMTP:  &SMTPConfig{Host: "127.0.0.1", Port: port, Username: "bot", Password: "pw", From: "DSL Bot <bot@example.com>"},
+	}
+	order := map[string]any{"id": "A-1", "manager": "Ann <ann@example.com>", "total": 42}
+	res, err := a.SendEmail(ctx, SendEmailInput{
+		To:          []string{"{{.manager}}"},
+		Cc:          []string{"ops@example.com, audit@example.com"},
+		Subject:     "Approve order {{.id}} – {{.total}} €",
+		Body:        "Order {{.id}} needs approval.",
+		Attachments: []EmailAttachment{{Key: "quotes/{{.id}}.txt"}},
+	}, order)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ann@example.com", "ops@example.com", "audit@example.com"}, res.Recipients)
+
+	got := <-ch
+	require.Equal(t, "MAIL FROM:<bot@example.com>", got[1])
+	require.Equal(t, "RCPT TO:<ann@example.com>", got[2])
+	msg, err := mail.ReadMessage(strings.NewReader(got[len(got)-1]))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	require.Equal(t, "Approve order A-1 – 42 €", subject)
+	require.Equal(t, `"Ann" <ann@example.com>`, msg.Header.Get("To"))
+	require.Equal(t, res.MessageID, msg.Header.Get("Message-ID"))
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	body, err := mr.NextPart()
+	require.NoError(t, err)
+	text, _ := io.ReadAll(body)
+	require.Equal(t, "Order A-1 needs approval.", string(text))
+	att, err := mr.NextPart()
+	require.NoError(t, err)
+	require.Equal(t, "A-1.txt", att.FileName())
+	content, _ := io.ReadAll(base64.NewDecoder(base64.StdEncoding, att))
+	require.Equal(t, "quote body", string(content))
+}
+
+func TestSendEmailErrors(t *testing.T) {
+	ctx := context.Background()
+	port, _ := fakeSMTP(t, "nobody@")
+	a := &Activities{SMTP: &SMTPConfig{Host: "127.0.0.1", Port: port, From: "bot@example.com"}}
+	var appErr *temporal.ApplicationError
+
+	_, err := a.SendEmail(ctx, SendEmailInput{To: []string{"nobody@example.com"}, Subject: "hi"}, nil)
+	require.True(t, errors.As(err, &appErr))
+	require.True(t, appErr.NonRetryable())
+	require.Equal(t, "SMTPRejected", appErr.Type())
+
+	_, err = a.SendEmail(ctx, SendEmailInput{To: []string{"{{.who}}"}}, map[string]any{})
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, "InvalidRequest", appErr.Type())
+
+	_, err = a.SendEmail(ctx, SendEmailInput{To: []string{"a@example.com"}, Attachments: []EmailAttachment{{Key: "x"}}}, nil)
+	require.ErrorContains(t, err, `blob store "default" is not configured`)
+}
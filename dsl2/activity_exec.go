@@ -0,0 +1,216 @@
+package dsl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+// maxExecOutputBytes 限制 ExecCommand 捕获的 stdout/stderr 各自的大小，超出部分丢弃
+const maxExecOutputBytes = 64 << 10
+
+// ExecPolicy 是 worker 上 ExecCommand 的允许范围；未配置时 ExecCommand 不可用
+//
+//	exec:
+//	  allow: [/usr/bin/rsync, /opt/scripts/*]
+//	  inheritEnv: false
+//	  allowEnv: [LANG, RSYNC_*]
+type ExecPolicy struct {
+	// Allow 是允许执行的程序：绝对路径或 filepath.Match 模式，按解析符号链接后的真实路径匹配
+	Allow []string `yaml:"allow" json:"allow"`
+	// InheritEnv 为 true 时子进程继承 worker 的环境变量，否则只有 PATH、HOME 与入参中的 env
+	InheritEnv bool `yaml:"inheritEnv,omitempty" json:"inheritEnv,omitempty"`
+	// AllowEnv 是入参 env 允许设置的变量名（filepath.Match 模式）；为空时除 deniedEnv 外都允许
+	AllowEnv []string `yaml:"allowEnv,omitempty" json:"allowEnv,omitempty"`
+}
+
+// globMeta 是 filepath.Match 模式中的特殊字符
+const globMeta = `*?[\`
+
+// deniedEnv 是入参 env 始终不能设置的变量：它们能让允许的程序加载或执行任意代码
+var deniedEnv = []string{"PATH", "LD_*", "DYLD_*", "BASH_ENV", "ENV"}
+
+// ExecCommandInput 是 ExecCommand 的入参
+type ExecCommandInput struct {
+	Command    string            `json:"command"`
+	Args       []string          `json:"args,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+	Dir        string            `json:"dir,omitempty"`
+	Stdin      string            `json:"stdin,omitempty"`
+	TimeoutSec int               `json:"timeoutSec,omitempty"`
+	// AllowFailure 为 true 时非零退出码不使 Activity 失败，由后续步骤检查 exitCode
+	AllowFailure bool `json:"allowFailure,omitempty"`
+}
+
+// ExecCommandResult 是 ExecCommand 的结果
+type ExecCommandResult struct {
+	ExitCode   int    `json:"exitCode"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	Truncated  bool   `json:"truncated,omitempty"` // 输出超过上限被截断
+	DurationMs int64  `json:"durationMs"`
+}
+
+// ExecCommand 在 worker 上执行允许列表中的程序（不经过 shell），用于编排已有脚本与命令行工具
+func (a *Activities) ExecCommand(ctx context.Context, in ExecCommandInput) (ExecCommandResult, error) {
+	path, err := a.Exec.resolve(in.Command)
+	if err != nil {
+		return ExecCommandResult{}, temporal.NewNonRetryableApplicationError(err.Error(), "ExecNotAllowed", err)
+	}
+	env, err := a.Exec.env(in.Env)
+	if err != nil {
+		return ExecCommandResult{}, temporal.NewNonRetryableApplicationError(err.Error(), "ExecNotAllowed", err)
+	}
+	if in.TimeoutSec > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(in.TimeoutSec)*time.Second)
+		defer cancel()
+	}
+
+	// 执行检查过的真实路径，argv[0] 仍是调用方写的命令名
+	cmd := exec.CommandContext(ctx, path, in.Args...)
+	cmd.Args[0] = in.Command
+	cmd.Dir = in.Dir
+	cmd.Env = env
+	if in.Stdin != "" {
+		cmd.Stdin = strings.NewReader(in.Stdin)
+	}
+	stdout, stderr := &cappedBuffer{max: maxExecOutputBytes}, &cappedBuffer{max: maxExecOutputBytes}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	begin := time.Now()
+	err = cmd.Run()
+	res := ExecCommandResult{
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		Truncated:  stdout.truncated || stderr.truncated,
+		DurationMs: time.Since(begin).Milliseconds(),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return res, nil
+	case ctx.Err() != nil:
+		return res, ctx.Err()
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+		if in.AllowFailure {
+			return res, nil
+		}
+		return res, temporal.NewApplicationError(
+			fmt.Sprintf("%s exited with code %d: %s", in.Command, res.ExitCode, truncate(res.Stderr, 256)),
+			"ExecExitCode", res)
+	default:
+		return res, err
+	}
+}
+
+// resolve 查找程序并检查是否在允许列表中。先解析符号链接，
+// 避免允许目录中的链接指向目录外的程序；条目同样按真实路径比较：字面路径整体解析，
+// 模式只解析目录部分（如 merged-/usr 系统上 /bin/* 即 /usr/bin/*），目录部分含通配符的模式按原样匹配
+func (p *ExecPolicy) resolve(command string) (string, error) {
+	if p == nil || len(p.Allow) == 0 {
+		return "", errors.New("ExecCommand is disabled on this worker; configure exec.allow")
+	}
+	if command == "" {
+		return "", errors.New("command is required")
+	}
+	// 带路径分隔符的相对路径由 LookPath 相对 worker 的工作目录解析，而不是入参 dir，容易误解，因此拒绝
+	if !filepath.IsAbs(command) && filepath.Base(command) != command {
+		return "", fmt.Errorf("command %q must be an absolute path or a program name looked up in PATH", command)
+	}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return "", err
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return "", err
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return "", err
+	}
+	for _, pattern := range p.Allow {
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return path, nil
+		}
+		if !strings.ContainsAny(pattern, globMeta) {
+			if real, err := filepath.EvalSymlinks(pattern); err == nil && real == path {
+				return path, nil
+			}
+			continue
+		}
+		if dir := filepath.Dir(pattern); !strings.ContainsAny(dir, globMeta) {
+			if real, err := filepath.EvalSymlinks(dir); err == nil && filepath.Dir(path) == real {
+				if ok, _ := filepath.Match(filepath.Base(pattern), filepath.Base(path)); ok {
+					return path, nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("command %q (%s) is not in the worker's exec allowlist", command, path)
+}
+
+// env 组装子进程的环境变量；入参 env 中被拒绝或不在 allowEnv 中的变量使调用失败
+func (p *ExecPolicy) env(extra map[string]string) ([]string, error) {
+	var env []string
+	if p.InheritEnv {
+		env = os.Environ()
+	} else {
+		for _, k := range []string{"PATH", "HOME"} {
+			if v, ok := os.LookupEnv(k); ok {
+				env = append(env, k+"="+v)
+			}
+		}
+	}
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if matchEnv(deniedEnv, k) {
+			return nil, fmt.Errorf("env %s cannot be set by ExecCommand", k)
+		}
+		if len(p.AllowEnv) > 0 && !matchEnv(p.AllowEnv, k) {
+			return nil, fmt.Errorf("env %s is not in the worker's exec.allowEnv", k)
+		}
+		env = append(env, k+"="+extra[k])
+	}
+	return env, nil
+}
+
+func matchEnv(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// cappedBuffer 只保留前 max 字节
+type cappedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
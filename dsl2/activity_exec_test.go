@@ -0,0 +1,101 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestExecCommand(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = (&Activities{}).ExecCommand(ctx, ExecCommandInput{Command: "sh"})
+	require.ErrorContains(t, err, "ExecCommand is disabled")
+
+	a := &Activities{Exec: &ExecPolicy{Allow: []string{sh}}}
+	res, err := a.ExecCommand(ctx, ExecCommandInput{
+		Command: "sh",
+		Args:    []string{"-c", `echo "$GREETING $(cat)"; echo oops >&2`},
+		Env:     map[string]string{"GREETING": "hello"},
+		Stdin:   "world",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "hello world\n", res.Stdout)
+	require.Equal(t, "oops\n", res.Stderr)
+
+	_, err = a.ExecCommand(ctx, ExecCommandInput{Command: "sh", Args: []string{"-c", "exit 3"}})
+	var appErr *temporal.ApplicationError
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, "ExecExitCode", appErr.Type())
+	res, err = a.ExecCommand(ctx, ExecCommandInput{Command: "sh", Args: []string{"-c", "exit 3"}, AllowFailure: true})
+	require.NoError(t, err)
+	require.Equal(t, 3, res.ExitCode)
+
+	_, err = a.ExecCommand(ctx, ExecCommandInput{Command: "ls"})
+	require.ErrorContains(t, err, "not in the worker's exec allowlist")
+
+	for _, k := range []string{"LD_PRELOAD", "DYLD_INSERT_LIBRARIES", "PATH"} {
+		_, err = a.ExecCommand(ctx, ExecCommandInput{Command: "sh", Env: map[string]string{k: "/tmp/x"}})
+		require.ErrorContains(t, err, "env "+k+" cannot be set by ExecCommand")
+	}
+	a.Exec.AllowEnv = []string{"GREETING", "APP_*"}
+	res, err = a.ExecCommand(ctx, ExecCommandInput{
+		Command: "sh",
+		Args:    []string{"-c", `echo "$GREETING $APP_NAME"`},
+		Env:     map[string]string{"GREETING": "hi", "APP_NAME": "dsl"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "hi dsl\n", res.Stdout)
+	_, err = a.ExecCommand(ctx, ExecCommandInput{Command: "sh", Env: map[string]string{"OTHER": "1"}})
+	require.ErrorContains(t, err, "env OTHER is not in the worker's exec.allowEnv")
+}
+
+func TestExecCommandSymlink(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	script := filepath.Join(dir, "hello.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho hello\n"), 0o755))
+	// 允许目录中指向目录外程序的链接被拒绝
+	require.NoError(t, os.Symlink(sh, filepath.Join(dir, "escape")))
+	require.NoError(t, os.Symlink(script, filepath.Join(dir, "hello")))
+
+	a := &Activities{Exec: &ExecPolicy{Allow: []string{filepath.Join(dir, "*")}}}
+	ctx := context.Background()
+	_, err = a.ExecCommand(ctx, ExecCommandInput{Command: filepath.Join(dir, "escape"), Args: []string{"-c", "echo pwned"}})
+	require.ErrorContains(t, err, "not in the worker's exec allowlist")
+
+	res, err := a.ExecCommand(ctx, ExecCommandInput{Command: filepath.Join(dir, "hello")})
+	require.NoError(t, err)
+	require.Equal(t, "hello\n", res.Stdout)
+
+	// 模式的目录部分同样解析符号链接：经 bin -> real 的 bin/* 允许 real 中的程序
+	real := filepath.Join(dir, "real")
+	require.NoError(t, os.Mkdir(real, 0o755))
+	require.NoError(t, os.Rename(script, filepath.Join(real, "hello.sh")))
+	require.NoError(t, os.Symlink(real, filepath.Join(dir, "bin")))
+	a.Exec.Allow = []string{filepath.Join(dir, "bin", "*")}
+	res, err = a.ExecCommand(ctx, ExecCommandInput{Command: filepath.Join(dir, "bin", "hello.sh")})
+	require.NoError(t, err)
+	require.Equal(t, "hello\n", res.Stdout)
+
+	// 带分隔符的相对路径会相对 worker 的工作目录解析，被拒绝
+	_, err = a.ExecCommand(ctx, ExecCommandInput{Command: "bin/hello.sh", Dir: dir})
+	require.ErrorContains(t, err, `command "bin/hello.sh" must be an absolute path or a program name looked up in PATH`)
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{max: 4}
+	b.Write([]byte("abc"))
+	b.Write([]byte("def"))
+	require.Equal(t, "abcd", b.String())
+	require.True(t, b.truncated)
+}
@@ -0,0 +1,256 @@
+package dsl
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCCallInput 是 GRPCCall 的入参：
+//
+//	variables:
+//	  check:
+//	    target: inventory:9090
+//	    method: inventory.v1.Inventory/Reserve
+//	    request: { sku: A-1, quantity: 2 }
+//	root:
+//	  - activity: { name: GRPCCall, args: [{ ref: check }], result: reservation }
+type GRPCCallInput struct {
+	Target string `json:"target"`
+	// Method 为完整方法名：package.Service/Method（也接受 package.Service.Method）
+	Method string `json:"method"`
+	// Request 是请求消息的 JSON 形式（protojson 字段名）
+	Request  any               `json:"request,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	TLS      bool              `json:"tls,omitempty"` // 使用系统根证书的 TLS，默认明文
+	// DescriptorSet 是 worker 上的 FileDescriptorSet 文件（protoc --include_imports --descriptor_set_out）；
+	// 为空时通过服务端反射（grpc.reflection.v1）获取描述
+	DescriptorSet string `json:"descriptorSet,omitempty"`
+	TimeoutSec    int    `json:"timeoutSec,omitempty"`
+}
+
+// GRPCCallResult 是 GRPCCall 的结果
+type GRPCCallResult struct {
+	Response any               `json:"response"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// 这些状态码重试也不会成功
+var nonRetryableGRPCCodes = map[codes.Code]bool{
+	codes.InvalidArgument:    true,
+	codes.NotFound:           true,
+	codes.AlreadyExists:      true,
+	codes.PermissionDenied:   true,
+	codes.Unauthenticated:    true,
+	codes.FailedPrecondition: true,
+	codes.Unimplemented:      true,
+	codes.OutOfRange:         true,
+}
+
+// GRPCCall 按完整方法名调用任意 gRPC 服务的 unary 方法，请求与响应以 JSON 映射
+func (a *Activities) GRPCCall(ctx context.Context, in GRPCCallInput) (GRPCCallResult, error) {
+	service, method, err := splitGRPCMethod(in.Method)
+	if err != nil {
+		return GRPCCallResult{}, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidRequest", err)
+	}
+	if in.Target == "" {
+		return GRPCCallResult{}, temporal.NewNonRetryableApplicationError("target is required", "InvalidRequest", nil)
+	}
+	if in.TimeoutSec > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(in.TimeoutSec)*time.Second)
+		defer cancel()
+	}
+	creds := insecure.NewCredentials()
+	if in.TLS {
+		creds = credentials.NewTLS(&tls.Config{})
+	}
+	conn, err := grpc.NewClient(in.Target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return GRPCCallResult{}, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidRequest", err)
+	}
+	defer conn.Close()
+
+	var files *protoregistry.Files
+	if in.DescriptorSet != "" {
+		files, err = loadDescriptorSet(in.DescriptorSet)
+	} else {
+		files, err = reflectFiles(ctx, conn, service)
+	}
+	if err != nil {
+		return GRPCCallResult{}, grpcError("resolve descriptors", err)
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return GRPCCallResult{}, temporal.NewNonRetryableApplicationError(fmt.Sprintf("service %s: %v", service, err), "UnknownMethod", err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return GRPCCallResult{}, temporal.NewNonRetryableApplicationError(service+" is not a service", "UnknownMethod", nil)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return GRPCCallResult{}, temporal.NewNonRetryableApplicationError(fmt.Sprintf("method %s not found in %s", method, service), "UnknownMethod", nil)
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return GRPCCallResult{}, temporal.NewNonRetryableApplicationError(in.Method+" is a streaming method; only unary calls are supported", "UnknownMethod", nil)
+	}
+
+	types := dynamicpb.NewTypes(files)
+	req := dynamicpb.NewMessage(md.Input())
+	if in.Request != nil {
+		bs, err := json.Marshal(in.Request)
+		if err != nil {
+			return GRPCCallResult{}, temporal.NewNonRetryableApplicationError("encode request: "+err.Error(), "InvalidRequest", err)
+		}
+		if err := (protojson.UnmarshalOptions{Resolver: types}).Unmarshal(bs, req); err != nil {
+			return GRPCCallResult{}, temporal.NewNonRetryableApplicationError("request does not match "+string(md.Input().FullName())+": "+err.Error(), "InvalidRequest", err)
+		}
+	}
+	for k, v := range in.Metadata {
+		ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+	}
+
+	resp := dynamicpb.NewMessage(md.Output())
+	var header metadata.MD
+	if err := conn.Invoke(ctx, "/"+service+"/"+method, req, resp, grpc.Header(&header)); err != nil {
+		return GRPCCallResult{}, grpcError(in.Method, err)
+	}
+	bs, err := (protojson.MarshalOptions{Resolver: types}).Marshal(resp)
+	if err != nil {
+		return GRPCCallResult{}, err
+	}
+	out := GRPCCallResult{Headers: map[string]string{}}
+	if err := json.Unmarshal(bs, &out.Response); err != nil {
+		return GRPCCallResult{}, err
+	}
+	for k, v := range header {
+		out.Headers[k] = strings.Join(v, ",")
+	}
+	return out, nil
+}
+
+// splitGRPCMethod 把 "pkg.Service/Method" 或 "pkg.Service.Method" 拆成服务与方法名
+func splitGRPCMethod(full string) (service, method string, err error) {
+	full = strings.TrimPrefix(full, "/")
+	if i := strings.LastIndex(full, "/"); i > 0 {
+		service, method = full[:i], full[i+1:]
+	} else if i := strings.LastIndex(full, "."); i > 0 {
+		service, method = full[:i], full[i+1:]
+	}
+	if service == "" || method == "" {
+		return "", "", fmt.Errorf("method %q must be package.Service/Method", full)
+	}
+	return service, method, nil
+}
+
+// grpcError 把 gRPC 状态转换为 Activity 错误；参数类错误不重试
+func grpcError(what string, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("%s: %w", what, err)
+	}
+	msg := fmt.Sprintf("%s: %s: %s", what, st.Code(), st.Message())
+	if nonRetryableGRPCCodes[st.Code()] {
+		return temporal.NewNonRetryableApplicationError(msg, "GRPCStatus", err, st.Code().String())
+	}
+	return temporal.NewApplicationError(msg, "GRPCStatus", st.Code().String())
+}
+
+func loadDescriptorSet(path string) (*protoregistry.Files, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(bs, &set); err != nil {
+		return nil, fmt.Errorf("parse descriptor set %s: %w", path, err)
+	}
+	return protodesc.NewFiles(&set)
+}
+
+// reflectFiles 通过服务端反射取得定义 service 的文件及其全部依赖
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn, service string) (*protoregistry.Files, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ask := func(req *rpb.ServerReflectionRequest) ([]*descriptorpb.FileDescriptorProto, error) {
+		if err := stream.Send(req); err != nil {
+			return nil, err
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if e := resp.GetErrorResponse(); e != nil {
+			return nil, status.Error(codes.Code(e.ErrorCode), e.ErrorMessage)
+		}
+		var out []*descriptorpb.FileDescriptorProto
+		for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, fd); err != nil {
+				return nil, err
+			}
+			out = append(out, fd)
+		}
+		return out, nil
+	}
+
+	byName := map[string]*descriptorpb.FileDescriptorProto{}
+	pending, err := ask(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	})
+	for err == nil && len(pending) > 0 {
+		fd := pending[0]
+		pending = pending[1:]
+		if byName[fd.GetName()] != nil {
+			continue
+		}
+		byName[fd.GetName()] = fd
+		for _, dep := range fd.GetDependency() {
+			if byName[dep] != nil {
+				continue
+			}
+			var more []*descriptorpb.FileDescriptorProto
+			more, err = ask(&rpb.ServerReflectionRequest{
+				MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			})
+			pending = append(pending, more...)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(byName) == 0 {
+		return nil, errors.New("server returned no descriptors for " + service)
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range byName {
+		set.File = append(set.File, fd)
+	}
+	return protodesc.NewFiles(set)
+}
@@ -0,0 +1,53 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+func TestGRPCCall(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	gs := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus("orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(gs, hs)
+	reflection.Register(gs)
+	go gs.Serve(lis)
+	defer gs.Stop()
+
+	a := &Activities{}
+	ctx := context.Background()
+	res, err := a.GRPCCall(ctx, GRPCCallInput{
+		Target:  lis.Addr().String(),
+		Method:  "grpc.health.v1.Health/Check",
+		Request: map[string]any{"service": "orders"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"status": "NOT_SERVING"}, res.Response)
+
+	_, err = a.GRPCCall(ctx, GRPCCallInput{
+		Target:  lis.Addr().String(),
+		Method:  "grpc.health.v1.Health.Check",
+		Request: map[string]any{"service": "unknown"},
+	})
+	var appErr *temporal.ApplicationError
+	require.True(t, errors.As(err, &appErr))
+	require.True(t, appErr.NonRetryable())
+	require.Contains(t, err.Error(), "NotFound")
+
+	_, err = a.GRPCCall(ctx, GRPCCallInput{Target: lis.Addr().String(), Method: "grpc.health.v1.Health/Watch"})
+	require.ErrorContains(t, err, "streaming method")
+
+	_, err = a.GRPCCall(ctx, GRPCCallInput{Target: lis.Addr().String(), Method: "Check"})
+	require.ErrorContains(t, err, "must be package.Service/Method")
+}
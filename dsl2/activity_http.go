@@ -0,0 +1,162 @@
+package dsl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+// maxHTTPResponseBytes 限制 HTTPRequest 读取的响应体大小；结果会写入变量并进入历史
+const maxHTTPResponseBytes = 1 << 20
+
+// HTTPRequestInput 是 HTTPRequest 的入参，通常以变量（YAML 对象）传入：
+//
+//	variables:
+//	  createUser:
+//	    method: POST
+//	    url: https://api.example.com/users
+//	    headers: { Authorization: "Bearer ..." }
+//	    body: { name: alice }
+//	    expectStatus: [201]
+//	root:
+//	  - activity: { name: HTTPRequest, args: [{ ref: createUser }], result: created }
+type HTTPRequestInput struct {
+	Method  string            `json:"method,omitempty"` // 默认 GET
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Query   map[string]string `json:"query,omitempty"`
+	// Body 为字符串时原样发送，其他值编码为 JSON 并默认 Content-Type: application/json
+	Body any `json:"body,omitempty"`
+	// ExpectStatus 为允许的状态码，默认任意 2xx；其余状态码使 Activity 失败（4xx 不重试）
+	ExpectStatus []int `json:"expectStatus,omitempty"`
+	// ResponseType 为 json / text；默认按响应的 Content-Type 判断
+	ResponseType string `json:"responseType,omitempty"`
+	TimeoutSec   int    `json:"timeoutSec,omitempty"` // 单次请求超时，默认使用 Activity 的超时
+}
+
+// HTTPResponse 是 HTTPRequest 的结果；JSON 响应解析到 JSON，否则 Body 为原文
+type HTTPResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	JSON    any               `json:"json,omitempty"`
+}
+
+// HTTPRequest 发送一个 HTTP 请求，使大多数集成无需编写 Go 代码即可在 YAML 中表达
+func (a *Activities) HTTPRequest(ctx context.Context, in HTTPRequestInput) (HTTPResponse, error) {
+	req, err := in.build(ctx)
+	if err != nil {
+		return HTTPResponse{}, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidRequest", err)
+	}
+	if in.TimeoutSec > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(in.TimeoutSec)*time.Second)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return HTTPResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseBytes+1))
+	if err != nil {
+		return HTTPResponse{}, fmt.Errorf("read response: %w", err)
+	}
+	if len(body) > maxHTTPResponseBytes {
+		return HTTPResponse{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("response body exceeds %d bytes", maxHTTPResponseBytes), "ResponseTooLarge", nil)
+	}
+
+	out := HTTPResponse{Status: resp.StatusCode, Headers: map[string]string{}}
+	for k := range resp.Header {
+		out.Headers[k] = resp.Header.Get(k)
+	}
+	if !in.expects(resp.StatusCode) {
+		msg := fmt.Sprintf("%s %s: unexpected status %d: %s", req.Method, in.URL, resp.StatusCode, truncate(string(body), 256))
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return out, temporal.NewNonRetryableApplicationError(msg, "HTTPStatus", nil, resp.StatusCode)
+		}
+		return out, temporal.NewApplicationError(msg, "HTTPStatus", resp.StatusCode)
+	}
+
+	asJSON := in.ResponseType == "json" ||
+		in.ResponseType == "" && strings.Contains(resp.Header.Get("Content-Type"), "json")
+	if asJSON && len(body) > 0 {
+		if err := json.Unmarshal(body, &out.JSON); err != nil {
+			return out, temporal.NewNonRetryableApplicationError("decode JSON response: "+err.Error(), "InvalidResponse", err)
+		}
+		return out, nil
+	}
+	out.Body = string(body)
+	return out, nil
+}
+
+func (in HTTPRequestInput) build(ctx context.Context) (*http.Request, error) {
+	if in.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(in.URL)
+	if err != nil {
+		return nil, err
+	}
+	if len(in.Query) > 0 {
+		q := u.Query()
+		for k, v := range in.Query {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+	method := strings.ToUpper(in.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	jsonBody := false
+	switch b := in.Body.(type) {
+	case nil:
+	case string:
+		body = strings.NewReader(b)
+	default:
+		bs, err := json.Marshal(b)
+		if err != nil {
+			return nil, fmt.Errorf("encode body: %w", err)
+		}
+		body, jsonBody = bytes.NewReader(bs), true
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if jsonBody {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range in.Headers {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+func (in HTTPRequestInput) expects(status int) bool {
+	if len(in.ExpectStatus) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(in.ExpectStatus, status)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,61 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestHTTPRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users":
+			body, _ := io.ReadAll(r.Body)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.Equal(t, "tok", r.Header.Get("Authorization"))
+			require.Equal(t, "1", r.URL.Query().Get("dry"))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"echo":` + string(body) + `}`))
+		case "/text":
+			w.Write([]byte("plain"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	a := &Activities{}
+	ctx := context.Background()
+
+	resp, err := a.HTTPRequest(ctx, HTTPRequestInput{
+		Method:       "post",
+		URL:          srv.URL + "/users",
+		Headers:      map[string]string{"Authorization": "tok"},
+		Query:        map[string]string{"dry": "1"},
+		Body:         map[string]any{"name": "alice"},
+		ExpectStatus: []int{201},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 201, resp.Status)
+	require.Equal(t, map[string]any{"echo": map[string]any{"name": "alice"}}, resp.JSON)
+
+	resp, err = a.HTTPRequest(ctx, HTTPRequestInput{URL: srv.URL + "/text"})
+	require.NoError(t, err)
+	require.Equal(t, "plain", resp.Body)
+	require.Nil(t, resp.JSON)
+
+	_, err = a.HTTPRequest(ctx, HTTPRequestInput{URL: srv.URL + "/missing"})
+	var appErr *temporal.ApplicationError
+	require.True(t, errors.As(err, &appErr))
+	require.True(t, appErr.NonRetryable())
+	require.Contains(t, err.Error(), "unexpected status 404")
+
+	_, err = a.HTTPRequest(ctx, HTTPRequestInput{})
+	require.ErrorContains(t, err, "url is required")
+}
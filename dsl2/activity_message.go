@@ -0,0 +1,158 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/temporalio/samples-go/dsl2/broker"
+)
+
+// defaultBroker 是未指定 broker 时使用的连接名
+const defaultBroker = "default"
+
+// consumePollInterval 是 ConsumeMessage 单次拉取的最长等待；每次拉取之间记录心跳
+const consumePollInterval = 10 * time.Second
+
+// PublishMessageInput 是 PublishMessage 的入参：
+//
+//	variables:
+//	  orderCreated: { broker: events, topic: orders, key: A-1, body: { id: A-1, total: 42 } }
+//	root:
+//	  - activity: { name: PublishMessage, args: [{ ref: orderCreated }] }
+type PublishMessageInput struct {
+	Broker string `json:"broker,omitempty"` // worker 配置的 brokers 中的名字，默认 default
+	Topic  string `json:"topic"`
+	Key    string `json:"key,omitempty"`
+	ID     string `json:"id,omitempty"` // 去重 ID（SQS FIFO、NATS JetStream）
+	// Body 为字符串时原样发送，其他值编码为 JSON
+	Body    any               `json:"body"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// ConsumeMessageInput 是 ConsumeMessage 的入参
+type ConsumeMessageInput struct {
+	Broker string `json:"broker,omitempty"`
+	Topic  string `json:"topic"`
+	Group  string `json:"group,omitempty"` // Kafka 消费组、NATS durable 名，默认 dsl
+	Max    int    `json:"max,omitempty"`   // 最多返回的消息数，默认 1
+	// WaitSec 是没有消息时最多等待的秒数，默认 20；超时返回空列表，由 while 决定是否继续
+	WaitSec int `json:"waitSec,omitempty"`
+	// VisibilitySec 是收到后到确认前消息对其他消费者不可见的秒数，默认 30；
+	// worker 在确认前崩溃时消息会在此之后重新投递
+	VisibilitySec int  `json:"visibilitySec,omitempty"`
+	JSON          bool `json:"json,omitempty"` // 把消息体解析为 JSON
+}
+
+// ReceivedMessage 是 ConsumeMessage 返回的一条消息；json 为 true 时 Body 是解析后的值
+type ReceivedMessage struct {
+	ID      string            `json:"id,omitempty"`
+	Key     string            `json:"key,omitempty"`
+	Body    any               `json:"body"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// PublishMessage 发布一条消息到 Kafka / NATS / SQS 等 worker 上配置的消息系统
+func (a *Activities) PublishMessage(ctx context.Context, in PublishMessageInput) error {
+	b, err := a.broker(in.Broker)
+	if err != nil {
+		return err
+	}
+	if in.Topic == "" {
+		return temporal.NewNonRetryableApplicationError("topic is required", "InvalidRequest", nil)
+	}
+	var body []byte
+	switch v := in.Body.(type) {
+	case string:
+		body = []byte(v)
+	default:
+		if body, err = json.Marshal(v); err != nil {
+			return temporal.NewNonRetryableApplicationError("encode body: "+err.Error(), "InvalidRequest", err)
+		}
+	}
+	return b.Publish(ctx, in.Topic, []broker.Message{{ID: in.ID, Key: in.Key, Body: body, Headers: in.Headers}})
+}
+
+// ConsumeMessage 等待并取出消息，拉取期间定期记录心跳；取到的消息在返回前确认。
+// 建议设置 opts.heartbeatSeconds，worker 失联时能尽快在其他 worker 上重试
+func (a *Activities) ConsumeMessage(ctx context.Context, in ConsumeMessageInput) ([]ReceivedMessage, error) {
+	b, err := a.broker(in.Broker)
+	if err != nil {
+		return nil, err
+	}
+	if in.Topic == "" {
+		return nil, temporal.NewNonRetryableApplicationError("topic is required", "InvalidRequest", nil)
+	}
+	wait := time.Duration(in.WaitSec) * time.Second
+	if in.WaitSec == 0 {
+		wait = 20 * time.Second
+	}
+	opts := broker.ReceiveOptions{
+		Group:      in.Group,
+		Max:        max(in.Max, 1),
+		Visibility: time.Duration(in.VisibilitySec) * time.Second,
+	}
+	if opts.Visibility == 0 {
+		opts.Visibility = 30 * time.Second
+	}
+
+	deadline := time.Now().Add(wait)
+	var ds []broker.Delivery
+	for {
+		if activity.IsActivity(ctx) {
+			activity.RecordHeartbeat(ctx)
+		}
+		opts.Wait = min(time.Until(deadline), consumePollInterval)
+		if ds, err = b.Receive(ctx, in.Topic, opts); err != nil {
+			return nil, err
+		}
+		if len(ds) > 0 || time.Until(deadline) <= 0 {
+			break
+		}
+	}
+
+	out := make([]ReceivedMessage, 0, len(ds))
+	for _, d := range ds {
+		msg := ReceivedMessage{ID: d.ID, Key: d.Key, Body: string(d.Body), Headers: d.Headers}
+		if in.JSON {
+			var v any
+			if err := json.Unmarshal(d.Body, &v); err != nil {
+				// 不确认，消息在可见性超时后重新投递（或进入死信队列）
+				return nil, temporal.NewNonRetryableApplicationError(
+					fmt.Sprintf("message %s is not JSON: %v", d.ID, err), "InvalidMessage", err)
+			}
+			msg.Body = v
+		}
+		out = append(out, msg)
+	}
+	for _, d := range ds {
+		if err := d.Ack(ctx); err != nil {
+			return nil, fmt.Errorf("ack message %s: %w", d.ID, err)
+		}
+	}
+	return out, nil
+}
+
+// broker 按名字查找 worker 上配置的消息系统
+func (a *Activities) broker(name string) (broker.Broker, error) {
+	if name == "" {
+		name = defaultBroker
+	}
+	b, ok := a.Brokers[name]
+	if !ok {
+		names := make([]string, 0, len(a.Brokers))
+		for n := range a.Brokers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		msg := fmt.Sprintf("broker %q is not configured on this worker (have: %s)", name, strings.Join(names, ", "))
+		return nil, temporal.NewNonRetryableApplicationError(msg, "InvalidRequest", nil)
+	}
+	return b, nil
+}
@@ -0,0 +1,46 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/temporalio/samples-go/dsl2/broker"
+)
+
+func TestMessageActivities(t *testing.T) {
+	ctx := context.Background()
+	m := broker.NewMemory()
+	a := &Activities{Brokers: map[string]broker.Broker{"default": m}}
+
+	require.NoError(t, a.PublishMessage(ctx, PublishMessageInput{Topic: "orders", Key: "A-1", Body: map[string]any{"id": "A-1"}}))
+	require.NoError(t, a.PublishMessage(ctx, PublishMessageInput{Topic: "orders", Body: "not json", Headers: map[string]string{"h": "v"}}))
+
+	got, err := a.ConsumeMessage(ctx, ConsumeMessageInput{Topic: "orders", JSON: true})
+	require.NoError(t, err)
+	require.Equal(t, []ReceivedMessage{{ID: "1", Key: "A-1", Body: map[string]any{"id": "A-1"}}}, got)
+
+	var appErr *temporal.ApplicationError
+	_, err = a.ConsumeMessage(ctx, ConsumeMessageInput{Topic: "orders", JSON: true, VisibilitySec: 1})
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, "InvalidMessage", appErr.Type())
+
+	// 未确认的消息在可见性超时后重新投递
+	got, err = a.ConsumeMessage(ctx, ConsumeMessageInput{Topic: "orders", WaitSec: 3})
+	require.NoError(t, err)
+	require.Equal(t, "not json", got[0].Body)
+	require.Equal(t, map[string]string{"h": "v"}, got[0].Headers)
+
+	begin := time.Now()
+	got, err = a.ConsumeMessage(ctx, ConsumeMessageInput{Topic: "orders", WaitSec: 1})
+	require.NoError(t, err)
+	require.Empty(t, got)
+	require.GreaterOrEqual(t, time.Since(begin), time.Second)
+
+	err = a.PublishMessage(ctx, PublishMessageInput{Broker: "events", Topic: "x"})
+	require.ErrorContains(t, err, `broker "events" is not configured on this worker (have: default)`)
+}
@@ -0,0 +1,156 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+// 通知渠道
+const (
+	NotifyWebhook = "webhook"
+	NotifySlack   = "slack"
+)
+
+// NotifyInput 是 Notify 的第一个入参；第二个入参（可省略）是渲染 Template 的数据：
+//
+//	variables:
+//	  done:
+//	    type: slack
+//	    url: https://hooks.slack.com/services/T000/B000/XXX
+//	    template: "Order {{.id}} shipped to {{.address.city}}"
+//	root:
+//	  - activity: { name: ShipOrder, args: [{ ref: orderId }], result: shipment }
+//	  - activity: { name: Notify, args: [{ ref: done }, { ref: shipment }] }
+type NotifyInput struct {
+	Type string `json:"type,omitempty"` // webhook / slack，默认 webhook
+	URL  string `json:"url"`
+	// Template 是 text/template 模板，. 为 Notify 的第二个入参；缺失的字段视为错误
+	Template string            `json:"template"`
+	Headers  map[string]string `json:"headers,omitempty"` // 仅 webhook
+	// Channel、Username、IconEmoji 覆盖 Slack incoming webhook 的默认值
+	Channel    string `json:"channel,omitempty"`
+	Username   string `json:"username,omitempty"`
+	IconEmoji  string `json:"iconEmoji,omitempty"`
+	TimeoutSec int    `json:"timeoutSec,omitempty"`
+}
+
+// notifyFuncs 是模板中可用的函数
+var notifyFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		bs, err := json.Marshal(v)
+		return string(bs), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// Notify 把模板渲染为消息并发送到 webhook 或 Slack，返回发送的文本；
+// webhook 收到 JSON {"text": 消息, "data": 数据}
+func (a *Activities) Notify(ctx context.Context, in NotifyInput, data any) (string, error) {
+	text, err := in.render(data)
+	if err != nil {
+		return "", temporal.NewNonRetryableApplicationError(err.Error(), "InvalidRequest", err)
+	}
+	req := HTTPRequestInput{
+		Method:       "POST",
+		URL:          in.URL,
+		ResponseType: "text",
+		TimeoutSec:   in.TimeoutSec,
+	}
+	switch in.Type {
+	case "", NotifyWebhook:
+		req.Headers = in.Headers
+		req.Body = map[string]any{"text": text, "data": data}
+	case NotifySlack:
+		msg := map[string]string{"text": text}
+		for k, v := range map[string]string{"channel": in.Channel, "username": in.Username, "icon_emoji": in.IconEmoji} {
+			if v != "" {
+				msg[k] = v
+			}
+		}
+		req.Body = msg
+	default:
+		err := fmt.Errorf("unknown notify type %q (want webhook or slack)", in.Type)
+		return "", temporal.NewNonRetryableApplicationError(err.Error(), "InvalidRequest", err)
+	}
+	if _, err := a.HTTPRequest(ctx, req); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+func (in NotifyInput) render(data any) (string, error) {
+	if in.Template == "" {
+		return "", fmt.Errorf("template is required")
+	}
+	return renderTemplate("notify", in.Template, data)
+}
+
+// renderTemplate 用 notifyFuncs 渲染 text/template，缺失的字段视为错误
+func renderTemplate(name, text string, data any) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Funcs(notifyFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// templateRefs 解析模板并返回其中以 . 开头引用的顶层字段名（{{.order.id}} -> order），用于变量引用检查
+func templateRefs(text string) ([]string, error) {
+	t, err := template.New("refs").Funcs(notifyFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var refs []string
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		switch x := n.(type) {
+		case *parse.ListNode:
+			if x == nil {
+				return
+			}
+			for _, c := range x.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(x.Pipe)
+		case *parse.PipeNode:
+			if x == nil {
+				return
+			}
+			for _, c := range x.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			for _, a := range x.Args {
+				walk(a)
+			}
+		case *parse.FieldNode:
+			if name := x.Ident[0]; !seen[name] {
+				seen[name] = true
+				refs = append(refs, name)
+			}
+		case *parse.IfNode:
+			walk(x.Pipe)
+			walk(x.List)
+			walk(x.ElseList)
+		case *parse.RangeNode:
+			walk(x.Pipe) // range/with 内部的 . 已不是顶层变量
+		case *parse.WithNode:
+			walk(x.Pipe)
+		}
+	}
+	walk(t.Tree.Root)
+	return refs, nil
+}
@@ -0,0 +1,53 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestNotify(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	a := &Activities{}
+	ctx := context.Background()
+	order := map[string]any{"id": "A-1", "items": []any{"x", "y"}}
+
+	text, err := a.Notify(ctx, NotifyInput{
+		Type:     NotifySlack,
+		URL:      srv.URL,
+		Template: "Order {{.id | upper}} has {{len .items}} items",
+		Channel:  "#ops",
+	}, order)
+	require.NoError(t, err)
+	require.Equal(t, "Order A-1 has 2 items", text)
+	require.Equal(t, map[string]any{"text": text, "channel": "#ops"}, got)
+
+	_, err = a.Notify(ctx, NotifyInput{URL: srv.URL, Template: "{{json .}}"}, order)
+	require.NoError(t, err)
+	require.Equal(t, `{"id":"A-1","items":["x","y"]}`, got["text"])
+	require.Equal(t, "A-1", got["data"].(map[string]any)["id"])
+
+	var appErr *temporal.ApplicationError
+	_, err = a.Notify(ctx, NotifyInput{URL: srv.URL, Template: "{{.missing}}"}, order)
+	require.True(t, errors.As(err, &appErr))
+	require.True(t, appErr.NonRetryable())
+
+	_, err = a.Notify(ctx, NotifyInput{URL: srv.URL + "/gone", Template: "hi"}, nil)
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, "HTTPStatus", appErr.Type())
+}
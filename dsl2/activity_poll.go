@@ -0,0 +1,156 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+// pollHeartbeatInterval 是 PollUntil 在两次检查之间记录心跳的间隔
+const pollHeartbeatInterval = 5 * time.Second
+
+// PollUntilInput 是 PollUntil 的入参。Until / FailWhen 使用与 if/while 相同的条件语法，
+// 可引用的变量是 JSON 响应对象的顶层字段，以及 status（状态码）、body（原文）与 json（解析后的响应）：
+//
+//	variables:
+//	  waitExport:
+//	    request: { url: "https://api.example.com/exports/42" }
+//	    until: { eq: { left: { ref: state }, right: { str: done } } }
+//	    failWhen: { eq: { left: { ref: state }, right: { str: failed } } }
+//	    intervalSec: 30
+//	root:
+//	  - activity:
+//	      name: PollUntil
+//	      args: [{ ref: waitExport }]
+//	      result: export
+//	      opts: { startToCloseSeconds: 7200, heartbeatSeconds: 60 }
+type PollUntilInput struct {
+	Request  HTTPRequestInput `json:"request"`
+	Until    Cond             `json:"until"`
+	FailWhen *Cond            `json:"failWhen,omitempty"`
+	// IntervalSec 是两次检查的间隔，默认 30；BackoffCoefficient > 1 时逐次放大，至多 MaxIntervalSec
+	IntervalSec        int     `json:"intervalSec,omitempty"`
+	BackoffCoefficient float64 `json:"backoffCoefficient,omitempty"`
+	MaxIntervalSec     int     `json:"maxIntervalSec,omitempty"`
+	// MaxAttempts 限制检查次数（含重试前的次数），0 表示直到 Activity 超时
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+}
+
+// PollUntilResult 是 PollUntil 的结果
+type PollUntilResult struct {
+	Attempts int          `json:"attempts"`
+	Response HTTPResponse `json:"response"`
+}
+
+// pollProgress 是 PollUntil 的心跳内容；Activity 重试（如 worker 重启）时从这里继续
+type pollProgress struct {
+	Attempts    int    `json:"attempts"`
+	IntervalSec int    `json:"intervalSec"`
+	LastStatus  int    `json:"lastStatus,omitempty"`
+	LastError   string `json:"lastError,omitempty"`
+}
+
+// PollUntil 反复请求状态端点直到 until 成立，期间以心跳记录进度，
+// 使"等待外部任务"无需在 DSL 中写由大量 Activity 组成的 while 循环
+func (a *Activities) PollUntil(ctx context.Context, in PollUntilInput) (PollUntilResult, error) {
+	progress := pollProgress{IntervalSec: in.IntervalSec}
+	if progress.IntervalSec <= 0 {
+		progress.IntervalSec = 30
+	}
+	if activity.IsActivity(ctx) && activity.HasHeartbeatDetails(ctx) {
+		var last pollProgress
+		if err := activity.GetHeartbeatDetails(ctx, &last); err == nil && last.Attempts > 0 {
+			progress = last
+			activity.GetLogger(ctx).Info("PollUntil resuming", "attempts", last.Attempts, "intervalSec", last.IntervalSec)
+		}
+	}
+
+	for {
+		progress.Attempts++
+		resp, err := a.HTTPRequest(ctx, in.Request)
+		progress.LastStatus, progress.LastError = resp.Status, ""
+		var appErr *temporal.ApplicationError
+		switch {
+		case ctx.Err() != nil:
+			return PollUntilResult{}, ctx.Err()
+		case errors.As(err, &appErr) && appErr.NonRetryable():
+			return PollUntilResult{Attempts: progress.Attempts, Response: resp}, err
+		case err != nil:
+			// 网络错误、5xx 等视为暂时性错误，下个周期再试
+			progress.LastError = err.Error()
+		default:
+			done, err := evalPollCond(in, resp)
+			if err != nil {
+				return PollUntilResult{}, err
+			}
+			if done {
+				return PollUntilResult{Attempts: progress.Attempts, Response: resp}, nil
+			}
+		}
+		if in.MaxAttempts > 0 && progress.Attempts >= in.MaxAttempts {
+			return PollUntilResult{Attempts: progress.Attempts, Response: resp}, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("condition not met after %d attempts", progress.Attempts), "PollExhausted", nil, progress)
+		}
+		if err := waitWithHeartbeat(ctx, time.Duration(progress.IntervalSec)*time.Second, progress); err != nil {
+			return PollUntilResult{}, err
+		}
+		if in.BackoffCoefficient > 1 {
+			next := int(float64(progress.IntervalSec) * in.BackoffCoefficient)
+			if in.MaxIntervalSec > 0 && next > in.MaxIntervalSec {
+				next = in.MaxIntervalSec
+			}
+			progress.IntervalSec = next
+		}
+	}
+}
+
+// evalPollCond 先检查 failWhen 再检查 until
+func evalPollCond(in PollUntilInput, resp HTTPResponse) (bool, error) {
+	bindings := map[string]any{}
+	if obj, ok := resp.JSON.(map[string]any); ok {
+		for k, v := range obj {
+			bindings[k] = v
+		}
+	}
+	bindings["status"] = int64(resp.Status)
+	bindings["body"] = resp.Body
+	bindings["json"] = resp.JSON
+	if in.FailWhen != nil {
+		failed, err := evalCond(*in.FailWhen, bindings)
+		if err != nil {
+			return false, temporal.NewNonRetryableApplicationError("failWhen: "+err.Error(), "InvalidRequest", err)
+		}
+		if failed {
+			return false, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("failWhen matched (status %d)", resp.Status), "PollFailed", nil, resp)
+		}
+	}
+	done, err := evalCond(in.Until, bindings)
+	if err != nil {
+		return false, temporal.NewNonRetryableApplicationError("until: "+err.Error(), "InvalidRequest", err)
+	}
+	return done, nil
+}
+
+// waitWithHeartbeat 等待 d，期间定期以 details 记录心跳
+func waitWithHeartbeat(ctx context.Context, d time.Duration, details any) error {
+	deadline := time.Now().Add(d)
+	for {
+		if activity.IsActivity(ctx) {
+			activity.RecordHeartbeat(ctx, details)
+		}
+		left := time.Until(deadline)
+		if left <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(min(left, pollHeartbeatInterval)):
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func TestPollUntil(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/broken":
+			w.Write([]byte(`{"state":"failed"}`))
+		case n == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case n == 2:
+			w.Write([]byte(`{"state":"running"}`))
+		default:
+			w.Write([]byte(`{"state":"done","url":"s3://out"}`))
+		}
+	}))
+	defer srv.Close()
+	done, failed := "done", "failed"
+	in := PollUntilInput{
+		Request:     HTTPRequestInput{URL: srv.URL},
+		Until:       Cond{Eq: &Compare{Left: Value{Ref: "state"}, Right: Value{Str: &done}}},
+		FailWhen:    &Cond{Eq: &Compare{Left: Value{Ref: "state"}, Right: Value{Str: &failed}}},
+		IntervalSec: 1,
+	}
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	a := &Activities{}
+	env.RegisterActivity(a)
+	var beats []pollProgress
+	env.SetOnActivityHeartbeatListener(func(_ *activity.Info, details converter.EncodedValues) {
+		var p pollProgress
+		require.NoError(t, details.Get(&p))
+		beats = append(beats, p)
+	})
+
+	// 从心跳恢复：已检查过 5 次
+	env.SetHeartbeatDetails(pollProgress{Attempts: 5, IntervalSec: 1})
+	val, err := env.ExecuteActivity(a.PollUntil, in)
+	require.NoError(t, err)
+	var res PollUntilResult
+	require.NoError(t, val.Get(&res))
+	require.Equal(t, 8, res.Attempts)
+	require.Equal(t, "s3://out", res.Response.JSON.(map[string]any)["url"])
+	require.NotEmpty(t, beats)
+	require.Equal(t, 6, beats[0].Attempts)
+	require.Equal(t, http.StatusServiceUnavailable, beats[0].LastStatus)
+	require.NotEmpty(t, beats[0].LastError)
+
+	in.Request.URL = srv.URL + "/broken"
+	_, err = (&Activities{}).PollUntil(context.Background(), in)
+	var appErr *temporal.ApplicationError
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, "PollFailed", appErr.Type())
+
+	in.Request.URL = srv.URL
+	in.FailWhen, in.MaxAttempts = nil, 1
+	calls.Store(1)
+	_, err = (&Activities{}).PollUntil(context.Background(), in)
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, "PollExhausted", appErr.Type())
+}
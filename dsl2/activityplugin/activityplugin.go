@@ -0,0 +1,211 @@
+// Package activityplugin 让 worker 在启动时加载额外的 Activity 实现，DSL 即可按名调用而无需重新编译 worker。
+// 支持两种插件：
+//   - go：Go plugin（go build -buildmode=plugin），导出 func Activities() map[string]any；
+//     须与 worker 使用相同的 Go 版本与依赖版本构建
+//   - process：hashicorp/go-plugin 子进程，插件程序在 main 中调用 Serve
+package activityplugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"plugin"
+	"reflect"
+	"sort"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"go.temporal.io/sdk/activity"
+)
+
+// 插件类型
+const (
+	TypeGo      = "go"
+	TypeProcess = "process"
+)
+
+// Spec 是配置文件中声明的一个插件
+type Spec struct {
+	Type string   `yaml:"type"` // go / process
+	Path string   `yaml:"path"` // .so 文件或可执行文件
+	Args []string `yaml:"args,omitempty"`
+}
+
+// Registry 是 worker.ActivityRegistry 中 LoadAll 用到的部分
+type Registry interface {
+	RegisterActivityWithOptions(a any, options activity.RegisterOptions)
+}
+
+// Plugins 是已加载的插件；Close 结束 process 插件的子进程
+type Plugins struct {
+	// Names 是插件注册的全部 Activity 名（已排序）
+	Names   []string
+	clients []*goplugin.Client
+}
+
+func (p *Plugins) Close() {
+	for _, c := range p.clients {
+		c.Kill()
+	}
+}
+
+// LoadAll 依次加载 specs 并把其中的 Activity 注册到 r；
+// 与 reserved（如内置 Activity）或其他插件重名时报错，避免 SDK 在重复注册时 panic
+func LoadAll(specs []Spec, r Registry, reserved []string) (*Plugins, error) {
+	taken := map[string]string{}
+	for _, name := range reserved {
+		taken[name] = "the worker"
+	}
+	p := &Plugins{}
+	for _, spec := range specs {
+		acts, client, err := load(spec)
+		if client != nil {
+			p.clients = append(p.clients, client)
+		}
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("plugin %s: %w", spec.Path, err)
+		}
+		names := make([]string, 0, len(acts))
+		for name := range acts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if owner, ok := taken[name]; ok {
+				p.Close()
+				return nil, fmt.Errorf("plugin %s: activity %q is already registered by %s", spec.Path, name, owner)
+			}
+			taken[name] = spec.Path
+			r.RegisterActivityWithOptions(acts[name], activity.RegisterOptions{Name: name})
+			p.Names = append(p.Names, name)
+		}
+	}
+	sort.Strings(p.Names)
+	return p, nil
+}
+
+func load(spec Spec) (map[string]any, *goplugin.Client, error) {
+	if spec.Path == "" {
+		return nil, nil, errors.New("path is required")
+	}
+	switch spec.Type {
+	case TypeGo:
+		acts, err := loadGo(spec.Path)
+		return acts, nil, err
+	case TypeProcess:
+		return loadProcess(spec)
+	}
+	return nil, nil, fmt.Errorf("unsupported type %q (want go or process)", spec.Type)
+}
+
+func loadGo(path string) (map[string]any, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup("Activities")
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := sym.(func() map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("Activities has type %T, want func() map[string]any", sym)
+	}
+	acts := fn()
+	for name, a := range acts {
+		if reflect.TypeOf(a) == nil || reflect.TypeOf(a).Kind() != reflect.Func {
+			return nil, fmt.Errorf("activity %q is %T, not a function", name, a)
+		}
+	}
+	return acts, nil
+}
+
+func loadProcess(spec Spec) (map[string]any, *goplugin.Client, error) {
+	client := goplugin.NewClient(&goplugin.ClientConfig{
+		HandshakeConfig:  Handshake,
+		Plugins:          map[string]goplugin.Plugin{pluginName: &rpcPlugin{}},
+		Cmd:              exec.Command(spec.Path, spec.Args...),
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolNetRPC},
+	})
+	rpcClient, err := client.Client()
+	if err != nil {
+		return nil, client, err
+	}
+	raw, err := rpcClient.Dispense(pluginName)
+	if err != nil {
+		return nil, client, err
+	}
+	acts, err := remoteActivities(raw.(*remote))
+	return acts, client, err
+}
+
+// remoteActivities 为插件声明的每个 Activity 生成 func(ctx, any×N) (any, error)，
+// 使 SDK 按声明的参数个数解码入参
+func remoteActivities(r *remote) (map[string]any, error) {
+	specs, err := r.Describe()
+	if err != nil {
+		return nil, err
+	}
+	anyType := reflect.TypeOf((*any)(nil)).Elem()
+	acts := map[string]any{}
+	for _, s := range specs {
+		in := []reflect.Type{ctxType}
+		for i := 0; i < s.Args; i++ {
+			in = append(in, anyType)
+		}
+		fnType := reflect.FuncOf(in, []reflect.Type{anyType, errType}, false)
+		name := s.Name
+		fn := reflect.MakeFunc(fnType, func(vals []reflect.Value) []reflect.Value {
+			args := make([]any, 0, len(vals)-1)
+			for _, v := range vals[1:] {
+				args = append(args, v.Interface())
+			}
+			res, err := r.call(vals[0].Interface().(context.Context), name, args)
+			out := reflect.New(anyType).Elem()
+			if res != nil {
+				out.Set(reflect.ValueOf(res))
+			}
+			errOut := reflect.New(errType).Elem()
+			if err != nil {
+				errOut.Set(reflect.ValueOf(err))
+			}
+			return []reflect.Value{out, errOut}
+		})
+		acts[name] = fn.Interface()
+	}
+	return acts, nil
+}
+
+// call 通过 RPC 执行 Activity；ctx 取消时立即返回（插件侧的调用无法中断，结果被丢弃）
+func (r *remote) call(ctx context.Context, name string, args []any) (any, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	type reply struct {
+		out []byte
+		err error
+	}
+	ch := make(chan reply, 1)
+	go func() {
+		out, err := r.Execute(name, data)
+		ch <- reply{out, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case rep := <-ch:
+		if rep.err != nil {
+			return nil, rep.err
+		}
+		var res any
+		if len(rep.out) > 0 {
+			if err := json.Unmarshal(rep.out, &res); err != nil {
+				return nil, fmt.Errorf("decode result: %w", err)
+			}
+		}
+		return res, nil
+	}
+}
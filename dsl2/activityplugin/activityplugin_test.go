@@ -0,0 +1,61 @@
+package activityplugin
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+)
+
+type fakeRegistry map[string]any
+
+func (r fakeRegistry) RegisterActivityWithOptions(a any, opts activity.RegisterOptions) {
+	r[opts.Name] = a
+}
+
+func TestRemoteActivities(t *testing.T) {
+	set, err := newFuncSet(map[string]any{
+		"Greet": func(ctx context.Context, name string, times int) (string, error) {
+			out := ""
+			for i := 0; i < times; i++ {
+				out += "hi " + name + ";"
+			}
+			return out, nil
+		},
+		"Fail": func() error { return errors.New("boom") },
+	})
+	require.NoError(t, err)
+	client, _ := goplugin.TestPluginRPCConn(t, map[string]goplugin.Plugin{pluginName: &rpcPlugin{set: set}}, nil)
+	defer client.Close()
+	raw, err := client.Dispense(pluginName)
+	require.NoError(t, err)
+
+	acts, err := remoteActivities(raw.(*remote))
+	require.NoError(t, err)
+	require.Len(t, acts, 2)
+
+	// SDK 按函数签名解码入参：Greet 接收 context 加两个参数
+	greet := reflect.ValueOf(acts["Greet"])
+	require.Equal(t, 3, greet.Type().NumIn())
+	out := greet.Call([]reflect.Value{reflect.ValueOf(context.Background()), reflect.ValueOf(any("bob")), reflect.ValueOf(any(2))})
+	require.Equal(t, "hi bob;hi bob;", out[0].Interface())
+	require.Nil(t, out[1].Interface())
+
+	out = reflect.ValueOf(acts["Fail"]).Call([]reflect.Value{reflect.ValueOf(context.Background())})
+	require.EqualError(t, out[1].Interface().(error), "boom")
+
+	_, err = set.call(context.Background(), "Greet", []byte(`["bob"]`))
+	require.EqualError(t, err, `activity "Greet" takes 2 args, got 1`)
+}
+
+func TestLoadAllValidation(t *testing.T) {
+	_, err := newFuncSet(map[string]any{"Bad": func() string { return "" }})
+	require.EqualError(t, err, `activity "Bad" must return error or (result, error)`)
+
+	_, err = LoadAll([]Spec{{Type: "wasm", Path: "x"}}, fakeRegistry{}, nil)
+	require.EqualError(t, err, `plugin x: unsupported type "wasm" (want go or process)`)
+}
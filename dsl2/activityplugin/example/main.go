@@ -0,0 +1,28 @@
+// example 是一个 process 类型的 Activity 插件示例：
+//
+//	go build -o /plugins/text-activities ./activityplugin/example
+//
+// 然后在 worker 配置中声明：
+//
+//	plugins:
+//	  - type: process
+//	    path: /plugins/text-activities
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/temporalio/samples-go/dsl2/activityplugin"
+)
+
+func main() {
+	activityplugin.Serve(map[string]any{
+		"Upper": func(ctx context.Context, s string) (string, error) {
+			return strings.ToUpper(s), nil
+		},
+		"Join": func(ctx context.Context, items []string, sep string) (string, error) {
+			return strings.Join(items, sep), nil
+		},
+	})
+}
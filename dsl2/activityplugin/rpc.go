@@ -0,0 +1,176 @@
+package activityplugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/rpc"
+	"reflect"
+	"sort"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// Handshake 是 worker 与 process 插件之间的握手配置；协议不兼容时递增 ProtocolVersion
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "DSL_ACTIVITY_PLUGIN",
+	MagicCookieValue: "activities",
+}
+
+const pluginName = "activities"
+
+// ActivitySpec 描述插件中的一个 Activity；Args 不含 context 参数
+type ActivitySpec struct {
+	Name string
+	Args int
+}
+
+// ExecuteArgs 是一次 RPC 调用：Args 为 JSON 数组
+type ExecuteArgs struct {
+	Name string
+	Args []byte
+}
+
+// Serve 在插件程序的 main 中调用，把 acts 中的函数作为 Activity 提供给 worker，直到 worker 退出。
+// 函数形如 func([ctx context.Context,] args...) ([R,] error)，入参与返回值经 JSON 编解码
+func Serve(acts map[string]any) {
+	set, err := newFuncSet(acts)
+	if err != nil {
+		panic(err)
+	}
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         map[string]goplugin.Plugin{pluginName: &rpcPlugin{set: set}},
+	})
+}
+
+// rpcPlugin 实现 go-plugin 的 net/rpc 插件：插件侧为 rpcServer，worker 侧为 remote
+type rpcPlugin struct {
+	set *funcSet
+}
+
+func (p *rpcPlugin) Server(*goplugin.MuxBroker) (any, error) {
+	return &rpcServer{set: p.set}, nil
+}
+
+func (p *rpcPlugin) Client(_ *goplugin.MuxBroker, c *rpc.Client) (any, error) {
+	return &remote{c: c}, nil
+}
+
+type rpcServer struct {
+	set *funcSet
+}
+
+func (s *rpcServer) Describe(_ any, out *[]ActivitySpec) error {
+	*out = s.set.specs()
+	return nil
+}
+
+func (s *rpcServer) Execute(in ExecuteArgs, out *[]byte) error {
+	res, err := s.set.call(context.Background(), in.Name, in.Args)
+	if err != nil {
+		return err
+	}
+	*out = res
+	return nil
+}
+
+// remote 是 worker 侧的 RPC 客户端
+type remote struct {
+	c *rpc.Client
+}
+
+func (r *remote) Describe() ([]ActivitySpec, error) {
+	var out []ActivitySpec
+	err := r.c.Call("Plugin.Describe", new(any), &out)
+	return out, err
+}
+
+func (r *remote) Execute(name string, args []byte) ([]byte, error) {
+	var out []byte
+	err := r.c.Call("Plugin.Execute", ExecuteArgs{Name: name, Args: args}, &out)
+	return out, err
+}
+
+// funcSet 按名调用插件中的 Activity 函数
+type funcSet struct {
+	fns map[string]reflect.Value
+}
+
+var (
+	ctxType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+func newFuncSet(acts map[string]any) (*funcSet, error) {
+	s := &funcSet{fns: map[string]reflect.Value{}}
+	for name, a := range acts {
+		v := reflect.ValueOf(a)
+		if v.Kind() != reflect.Func {
+			return nil, fmt.Errorf("activity %q is %T, not a function", name, a)
+		}
+		t := v.Type()
+		if t.IsVariadic() {
+			return nil, fmt.Errorf("activity %q: variadic functions are not supported", name)
+		}
+		if t.NumOut() == 0 || t.NumOut() > 2 || t.Out(t.NumOut()-1) != errType {
+			return nil, fmt.Errorf("activity %q must return error or (result, error)", name)
+		}
+		s.fns[name] = v
+	}
+	return s, nil
+}
+
+func hasCtx(t reflect.Type) bool {
+	return t.NumIn() > 0 && t.In(0) == ctxType
+}
+
+func (s *funcSet) specs() []ActivitySpec {
+	out := make([]ActivitySpec, 0, len(s.fns))
+	for name, v := range s.fns {
+		n := v.Type().NumIn()
+		if hasCtx(v.Type()) {
+			n--
+		}
+		out = append(out, ActivitySpec{Name: name, Args: n})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (s *funcSet) call(ctx context.Context, name string, data []byte) ([]byte, error) {
+	fn, ok := s.fns[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown activity %q", name)
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decode args: %w", err)
+	}
+	t := fn.Type()
+	var in []reflect.Value
+	first := 0
+	if hasCtx(t) {
+		in = append(in, reflect.ValueOf(ctx))
+		first = 1
+	}
+	if len(raw) != t.NumIn()-first {
+		return nil, fmt.Errorf("activity %q takes %d args, got %d", name, t.NumIn()-first, len(raw))
+	}
+	for i, r := range raw {
+		p := reflect.New(t.In(first + i))
+		if err := json.Unmarshal(r, p.Interface()); err != nil {
+			return nil, fmt.Errorf("arg[%d]: %w", i, err)
+		}
+		in = append(in, p.Elem())
+	}
+	out := fn.Call(in)
+	if err, _ := out[len(out)-1].Interface().(error); err != nil {
+		return nil, err
+	}
+	if len(out) == 1 {
+		return nil, nil
+	}
+	return json.Marshal(out[0].Interface())
+}
@@ -0,0 +1,285 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: dsl.proto
+
+// DSL workflow 提交服务：与 Web UI 的 HTTP API 等价，供内部平台以强类型客户端调用。
+
+package dslpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	DSLWorkflowService_Execute_FullMethodName       = "/dsl.v1.DSLWorkflowService/Execute"
+	DSLWorkflowService_Validate_FullMethodName      = "/dsl.v1.DSLWorkflowService/Validate"
+	DSLWorkflowService_GetStatus_FullMethodName     = "/dsl.v1.DSLWorkflowService/GetStatus"
+	DSLWorkflowService_ListWorkflows_FullMethodName = "/dsl.v1.DSLWorkflowService/ListWorkflows"
+	DSLWorkflowService_Signal_FullMethodName        = "/dsl.v1.DSLWorkflowService/Signal"
+)
+
+// DSLWorkflowServiceClient is the client API for DSLWorkflowService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DSLWorkflowServiceClient interface {
+	// 校验并启动工作流；wait=true 时等待执行结束并返回结果 bindings
+	Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error)
+	// 仅解析并校验工作流定义
+	Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error)
+	// 查询单个执行的状态
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*WorkflowStatus, error)
+	// 列出最近的 DSL 工作流执行
+	ListWorkflows(ctx context.Context, in *ListWorkflowsRequest, opts ...grpc.CallOption) (*ListWorkflowsResponse, error)
+	// 向运行中的工作流发送信号
+	Signal(ctx context.Context, in *SignalRequest, opts ...grpc.CallOption) (*SignalResponse, error)
+}
+
+type dSLWorkflowServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDSLWorkflowServiceClient(cc grpc.ClientConnInterface) DSLWorkflowServiceClient {
+	return &dSLWorkflowServiceClient{cc}
+}
+
+func (c *dSLWorkflowServiceClient) Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecuteResponse)
+	err := c.cc.Invoke(ctx, DSLWorkflowService_Execute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSLWorkflowServiceClient) Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateResponse)
+	err := c.cc.Invoke(ctx, DSLWorkflowService_Validate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSLWorkflowServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*WorkflowStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WorkflowStatus)
+	err := c.cc.Invoke(ctx, DSLWorkflowService_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSLWorkflowServiceClient) ListWorkflows(ctx context.Context, in *ListWorkflowsRequest, opts ...grpc.CallOption) (*ListWorkflowsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWorkflowsResponse)
+	err := c.cc.Invoke(ctx, DSLWorkflowService_ListWorkflows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSLWorkflowServiceClient) Signal(ctx context.Context, in *SignalRequest, opts ...grpc.CallOption) (*SignalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignalResponse)
+	err := c.cc.Invoke(ctx, DSLWorkflowService_Signal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DSLWorkflowServiceServer is the server API for DSLWorkflowService service.
+// All implementations must embed UnimplementedDSLWorkflowServiceServer
+// for forward compatibility.
+type DSLWorkflowServiceServer interface {
+	// 校验并启动工作流；wait=true 时等待执行结束并返回结果 bindings
+	Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error)
+	// 仅解析并校验工作流定义
+	Validate(context.Context, *ValidateRequest) (*ValidateResponse, error)
+	// 查询单个执行的状态
+	GetStatus(context.Context, *GetStatusRequest) (*WorkflowStatus, error)
+	// 列出最近的 DSL 工作流执行
+	ListWorkflows(context.Context, *ListWorkflowsRequest) (*ListWorkflowsResponse, error)
+	// 向运行中的工作流发送信号
+	Signal(context.Context, *SignalRequest) (*SignalResponse, error)
+	mustEmbedUnimplementedDSLWorkflowServiceServer()
+}
+
+// UnimplementedDSLWorkflowServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDSLWorkflowServiceServer struct{}
+
+func (UnimplementedDSLWorkflowServiceServer) Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Execute not implemented")
+}
+func (UnimplementedDSLWorkflowServiceServer) Validate(context.Context, *ValidateRequest) (*ValidateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validate not implemented")
+}
+func (UnimplementedDSLWorkflowServiceServer) GetStatus(context.Context, *GetStatusRequest) (*WorkflowStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedDSLWorkflowServiceServer) ListWorkflows(context.Context, *ListWorkflowsRequest) (*ListWorkflowsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWorkflows not implemented")
+}
+func (UnimplementedDSLWorkflowServiceServer) Signal(context.Context, *SignalRequest) (*SignalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Signal not implemented")
+}
+func (UnimplementedDSLWorkflowServiceServer) mustEmbedUnimplementedDSLWorkflowServiceServer() {}
+func (UnimplementedDSLWorkflowServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeDSLWorkflowServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DSLWorkflowServiceServer will
+// result in compilation errors.
+type UnsafeDSLWorkflowServiceServer interface {
+	mustEmbedUnimplementedDSLWorkflowServiceServer()
+}
+
+func RegisterDSLWorkflowServiceServer(s grpc.ServiceRegistrar, srv DSLWorkflowServiceServer) {
+	// If the following call pancis, it indicates UnimplementedDSLWorkflowServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DSLWorkflowService_ServiceDesc, srv)
+}
+
+func _DSLWorkflowService_Execute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSLWorkflowServiceServer).Execute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DSLWorkflowService_Execute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSLWorkflowServiceServer).Execute(ctx, req.(*ExecuteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSLWorkflowService_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSLWorkflowServiceServer).Validate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DSLWorkflowService_Validate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSLWorkflowServiceServer).Validate(ctx, req.(*ValidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSLWorkflowService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSLWorkflowServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DSLWorkflowService_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSLWorkflowServiceServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSLWorkflowService_ListWorkflows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWorkflowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSLWorkflowServiceServer).ListWorkflows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DSLWorkflowService_ListWorkflows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSLWorkflowServiceServer).ListWorkflows(ctx, req.(*ListWorkflowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSLWorkflowService_Signal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSLWorkflowServiceServer).Signal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DSLWorkflowService_Signal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSLWorkflowServiceServer).Signal(ctx, req.(*SignalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DSLWorkflowService_ServiceDesc is the grpc.ServiceDesc for DSLWorkflowService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DSLWorkflowService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dsl.v1.DSLWorkflowService",
+	HandlerType: (*DSLWorkflowServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Execute",
+			Handler:    _DSLWorkflowService_Execute_Handler,
+		},
+		{
+			MethodName: "Validate",
+			Handler:    _DSLWorkflowService_Validate_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _DSLWorkflowService_GetStatus_Handler,
+		},
+		{
+			MethodName: "ListWorkflows",
+			Handler:    _DSLWorkflowService_ListWorkflows_Handler,
+		},
+		{
+			MethodName: "Signal",
+			Handler:    _DSLWorkflowService_Signal_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "dsl.proto",
+}
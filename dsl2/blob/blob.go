@@ -0,0 +1,162 @@
+// Package blob 是 BlobPut/BlobGet/BlobList Activity 使用的对象存储抽象，提供本地文件系统、S3（及兼容实现）、GCS 与 Redis 后端。
+// S3 与 GCS 直接使用其 HTTP API，Redis 直接使用 RESP 协议，不依赖云厂商 SDK 或客户端库
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound 表示对象不存在
+var ErrNotFound = errors.New("blob: object not found")
+
+// ErrInvalidKey 表示 key 为空或越出存储范围
+var ErrInvalidKey = errors.New("blob: invalid key")
+
+// Store 是一个存储桶（或目录）
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List 返回以 prefix 开头的全部对象，按 Key 排序
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// Object 是 List 返回的对象元数据
+type Object struct {
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	Updated time.Time `json:"updated"`
+}
+
+// 后端类型
+const (
+	TypeFile  = "file"
+	TypeS3    = "s3"
+	TypeGCS   = "gcs"
+	TypeRedis = "redis"
+)
+
+// Config 描述一个命名存储；未填写的凭证从环境变量读取（AWS_ACCESS_KEY_ID 等、GOOGLE_OAUTH_ACCESS_TOKEN）
+//
+//	artifacts: { type: s3, bucket: my-artifacts, region: eu-west-1 }
+//	minio:     { type: s3, bucket: dev, endpoint: "http://localhost:9000" }
+//	reports:   { type: gcs, bucket: my-reports }
+//	scratch:   { type: file, dir: /var/lib/dsl/blobs }
+//	cache:     { type: redis, endpoint: "redis:6379", db: 1, ttl: 24h }
+type Config struct {
+	Type   string `yaml:"type"`
+	Dir    string `yaml:"dir,omitempty"`    // file
+	Bucket string `yaml:"bucket,omitempty"` // s3 / gcs
+	Prefix string `yaml:"prefix,omitempty"` // 所有 key 的公共前缀
+	// Endpoint 覆盖服务地址（S3 兼容存储、fake-gcs-server 等）；S3 设置后使用 path-style 请求
+	Endpoint        string `yaml:"endpoint,omitempty"`
+	Region          string `yaml:"region,omitempty"`
+	AccessKeyID     string `yaml:"accessKeyId,omitempty"`
+	SecretAccessKey string `yaml:"secretAccessKey,omitempty"`
+	SessionToken    string `yaml:"sessionToken,omitempty"`
+	// AccessToken 是 GCS 的 OAuth2 访问令牌；为空时读 GOOGLE_OAUTH_ACCESS_TOKEN，再退回 GCE 元数据服务器
+	AccessToken string `yaml:"accessToken,omitempty"`
+	// Redis：Endpoint 为 host:port（默认 localhost:6379），Password 为空时读 REDIS_PASSWORD；TTL 为写入的 key 的过期时间
+	Password string        `yaml:"password,omitempty"`
+	DB       int           `yaml:"db,omitempty"`
+	TTL      time.Duration `yaml:"ttl,omitempty"`
+}
+
+// Open 按配置创建 Store
+func Open(cfg Config) (Store, error) {
+	var (
+		s   Store
+		err error
+	)
+	switch cfg.Type {
+	case TypeFile:
+		s, err = newFileStore(cfg)
+	case TypeS3:
+		s, err = newS3Store(cfg)
+	case TypeGCS:
+		s, err = newGCSStore(cfg)
+	case TypeRedis:
+		s, err = newRedisStore(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported blob store type %q (want file, s3, gcs or redis)", cfg.Type)
+	}
+	if err != nil || cfg.Prefix == "" {
+		return s, err
+	}
+	return prefixed{s, cfg.Prefix}, nil
+}
+
+// ParseURL 把 file:///var/lib/dsl/blobs、s3://bucket/prefix、gcs://bucket/prefix、redis://:password@host:6379/db 形式的地址
+// 转换为 Config，便于用单个参数或环境变量指定存储；查询参数 endpoint、region、prefix、ttl 对应同名字段，其余凭证仍从环境变量读取
+func ParseURL(raw string) (Config, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Config{}, fmt.Errorf("blob url: %w", err)
+	}
+	cfg := Config{Type: u.Scheme, Endpoint: u.Query().Get("endpoint"), Region: u.Query().Get("region")}
+	switch u.Scheme {
+	case TypeFile:
+		cfg.Dir = u.Host + u.Path
+	case TypeS3, TypeGCS:
+		cfg.Bucket = u.Host
+		if p := strings.Trim(u.Path, "/"); p != "" {
+			cfg.Prefix = p + "/"
+		}
+	case TypeRedis:
+		cfg.Endpoint = u.Host
+		cfg.Password, _ = u.User.Password()
+		if db := strings.Trim(u.Path, "/"); db != "" {
+			if cfg.DB, err = strconv.Atoi(db); err != nil {
+				return Config{}, fmt.Errorf("blob url %q: db must be a number", u.Redacted())
+			}
+		}
+		cfg.Prefix = u.Query().Get("prefix")
+		if ttl := u.Query().Get("ttl"); ttl != "" {
+			if cfg.TTL, err = time.ParseDuration(ttl); err != nil {
+				return Config{}, fmt.Errorf("blob url %q: ttl: %w", u.Redacted(), err)
+			}
+		}
+	default:
+		return Config{}, fmt.Errorf("blob url %q: scheme must be file, s3, gcs or redis", raw)
+	}
+	return cfg, nil
+}
+
+// prefixed 给所有 key 加上公共前缀，List 返回的 key 去掉前缀
+type prefixed struct {
+	Store
+	prefix string
+}
+
+func (p prefixed) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	return p.Store.Put(ctx, p.prefix+key, data, contentType)
+}
+
+func (p prefixed) Get(ctx context.Context, key string) ([]byte, error) {
+	return p.Store.Get(ctx, p.prefix+key)
+}
+
+func (p prefixed) List(ctx context.Context, prefix string) ([]Object, error) {
+	objs, err := p.Store.List(ctx, p.prefix+prefix)
+	for i := range objs {
+		objs[i].Key = objs[i].Key[len(p.prefix):]
+	}
+	return objs, err
+}
+
+// httpStatusError 把非 2xx 响应转换为错误；404 映射为 ErrNotFound
+func httpStatusError(resp *http.Response, body []byte) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if len(body) > 512 {
+		body = body[:512]
+	}
+	return fmt.Errorf("blob: %s %s: %s: %s", resp.Request.Method, resp.Request.URL.Redacted(), resp.Status, body)
+}
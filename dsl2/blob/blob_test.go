@@ -0,0 +1,301 @@
+package blob
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(Config{Type: TypeFile, Dir: t.TempDir(), Prefix: "runs/"})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(ctx, "a/1.json", []byte(`{"n":1}`), "application/json"))
+	require.NoError(t, s.Put(ctx, "a/2.json", []byte(`{"n":2}`), ""))
+	require.NoError(t, s.Put(ctx, "b.txt", []byte("hi"), ""))
+
+	data, err := s.Get(ctx, "a/2.json")
+	require.NoError(t, err)
+	require.Equal(t, `{"n":2}`, string(data))
+
+	_, err = s.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	objs, err := s.List(ctx, "a/")
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+	require.Equal(t, "a/1.json", objs[0].Key)
+	require.Equal(t, int64(7), objs[0].Size)
+
+	raw, err := Open(Config{Type: TypeFile, Dir: t.TempDir()})
+	require.NoError(t, err)
+	for _, key := range []string{"", "../escape", "a/../../x"} {
+		require.Error(t, raw.Put(ctx, key, nil, ""), key)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	_, err := Open(Config{Type: "ftp"})
+	require.ErrorContains(t, err, "unsupported")
+	_, err = Open(Config{Type: TypeFile})
+	require.ErrorContains(t, err, "dir")
+	_, err = Open(Config{Type: TypeGCS})
+	require.ErrorContains(t, err, "bucket")
+}
+
+func TestS3Store(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		objects = map[string]string{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Contains(t, r.Header.Get("Authorization"), "Credential=AK/")
+		mu.Lock()
+		defer mu.Unlock()
+		key := strings.TrimPrefix(r.URL.Path, "/bkt/")
+		switch {
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[key] = string(body)
+		case r.URL.Query().Get("list-type") == "2":
+			// 每页一个对象，检验分页
+			var keys []string
+			for k := range objects {
+				if strings.HasPrefix(k, r.URL.Query().Get("prefix")) && k > r.URL.Query().Get("continuation-token") {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			type content struct {
+				Key  string
+				Size int
+			}
+			res := struct {
+				XMLName               xml.Name `xml:"ListBucketResult"`
+				Contents              []content
+				IsTruncated           bool
+				NextContinuationToken string
+			}{}
+			if len(keys) > 0 {
+				res.Contents = []content{{keys[0], len(objects[keys[0]])}}
+				res.IsTruncated = len(keys) > 1
+				res.NextContinuationToken = keys[0]
+			}
+			xml.NewEncoder(w).Encode(res)
+		default:
+			v, ok := objects[key]
+			if !ok {
+				http.Error(w, "NoSuchKey", http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(v))
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	s, err := Open(Config{Type: TypeS3, Bucket: "bkt", Endpoint: srv.URL, AccessKeyID: "AK", SecretAccessKey: "SK"})
+	require.NoError(t, err)
+	require.NoError(t, s.Put(ctx, "out/a b.txt", []byte("one"), "text/plain"))
+	require.NoError(t, s.Put(ctx, "out/c.txt", []byte("three"), ""))
+	require.NoError(t, s.Put(ctx, "other", []byte("x"), ""))
+
+	data, err := s.Get(ctx, "out/a b.txt")
+	require.NoError(t, err)
+	require.Equal(t, "one", string(data))
+	_, err = s.Get(ctx, "nope")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	objs, err := s.List(ctx, "out/")
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+	require.Equal(t, "out/a b.txt", objs[0].Key)
+	require.Equal(t, int64(5), objs[1].Size)
+}
+
+func TestGCSStore(t *testing.T) {
+	objects := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		switch {
+		case r.URL.Path == "/upload/storage/v1/b/bkt/o":
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Query().Get("name")] = string(body)
+			w.Write([]byte(`{}`))
+		case r.URL.Path == "/storage/v1/b/bkt/o":
+			require.Equal(t, "out/", r.URL.Query().Get("prefix"))
+			if r.URL.Query().Get("pageToken") == "" {
+				w.Write([]byte(`{"items":[{"name":"out/b","size":"2","updated":"2024-01-02T03:04:05Z"}],"nextPageToken":"p2"}`))
+				return
+			}
+			w.Write([]byte(`{"items":[{"name":"out/a","size":"1","updated":"2024-01-02T03:04:05Z"}]}`))
+		default:
+			name, _ := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/storage/v1/b/bkt/o/"))
+			v, ok := objects[name]
+			if !ok || r.URL.Query().Get("alt") != "media" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(v))
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	s, err := Open(Config{Type: TypeGCS, Bucket: "bkt", Endpoint: srv.URL, AccessToken: "tok"})
+	require.NoError(t, err)
+	require.NoError(t, s.Put(ctx, "dir/x.json", []byte("{}"), "application/json"))
+	data, err := s.Get(ctx, "dir/x.json")
+	require.NoError(t, err)
+	require.Equal(t, "{}", string(data))
+	_, err = s.Get(ctx, "dir/none")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	objs, err := s.List(ctx, "out/")
+	require.NoError(t, err)
+	require.Equal(t, []string{"out/a", "out/b"}, []string{objs[0].Key, objs[1].Key})
+	require.Equal(t, int64(2), objs[1].Size)
+}
+
+func TestParseURL(t *testing.T) {
+	for raw, want := range map[string]Config{
+		"file:///var/lib/dsl/claims":                    {Type: TypeFile, Dir: "/var/lib/dsl/claims"},
+		"s3://artifacts/dsl/claims?region=eu-west-1":    {Type: TypeS3, Bucket: "artifacts", Prefix: "dsl/claims/", Region: "eu-west-1"},
+		"s3://dev?endpoint=http://localhost:9000":       {Type: TypeS3, Bucket: "dev", Endpoint: "http://localhost:9000"},
+		"gcs://reports/":                                {Type: TypeGCS, Bucket: "reports"},
+		"redis://:pw@cache:6380/2?prefix=spill/&ttl=1h": {Type: TypeRedis, Endpoint: "cache:6380", Password: "pw", DB: 2, Prefix: "spill/", TTL: time.Hour},
+	} {
+		got, err := ParseURL(raw)
+		require.NoError(t, err, raw)
+		require.Equal(t, want, got, raw)
+	}
+	_, err := ParseURL("ftp://host/x")
+	require.Error(t, err)
+	_, err = ParseURL("redis://cache/zero")
+	require.ErrorContains(t, err, "db must be a number")
+}
+
+// fakeRedis 是只实现 AUTH/SELECT/SET/GET/SCAN/STRLEN 的 RESP 服务器，SCAN 每页返回一个 key 以检验游标
+func fakeRedis(t *testing.T, password string) (addr string, objects map[string]string, commands *[]string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	var mu sync.Mutex
+	objects, commands = map[string]string{}, &[]string{}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				r := bufio.NewReader(c)
+				authed := password == ""
+				for {
+					v, err := readRESP(r)
+					if err != nil {
+						return
+					}
+					var args []string
+					for _, a := range v.([]any) {
+						args = append(args, string(a.([]byte)))
+					}
+					mu.Lock()
+					*commands = append(*commands, strings.Join(args, " "))
+					var reply string
+					switch {
+					case args[0] == "AUTH":
+						authed = args[1] == password
+						reply = "+OK\r\n"
+						if !authed {
+							reply = "-WRONGPASS invalid password\r\n"
+						}
+					case !authed:
+						reply = "-NOAUTH Authentication required.\r\n"
+					case args[0] == "SELECT":
+						reply = "+OK\r\n"
+					case args[0] == "SET":
+						objects[args[1]] = args[2]
+						reply = "+OK\r\n"
+					case args[0] == "GET":
+						if v, ok := objects[args[1]]; ok {
+							reply = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+						} else {
+							reply = "$-1\r\n"
+						}
+					case args[0] == "STRLEN":
+						reply = fmt.Sprintf(":%d\r\n", len(objects[args[1]]))
+					case args[0] == "SCAN":
+						var keys []string
+						for k := range objects {
+							if ok, _ := path.Match(args[3], k); ok {
+								keys = append(keys, k)
+							}
+						}
+						sort.Strings(keys)
+						i, _ := strconv.Atoi(args[1])
+						next, page := "0", ""
+						if i < len(keys) {
+							page = fmt.Sprintf("$%d\r\n%s\r\n", len(keys[i]), keys[i])
+							if i+1 < len(keys) {
+								next = strconv.Itoa(i + 1)
+							}
+						}
+						reply = fmt.Sprintf("*2\r\n$%d\r\n%s\r\n*%d\r\n%s", len(next), next, strings.Count(page, "\r\n")/2, page)
+					default:
+						reply = "-ERR unknown command\r\n"
+					}
+					mu.Unlock()
+					if _, err := io.WriteString(c, reply); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return l.Addr().String(), objects, commands
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	addr, _, commands := fakeRedis(t, "secret")
+	s, err := Open(Config{Type: TypeRedis, Endpoint: addr, Password: "secret", DB: 3, TTL: time.Minute, Prefix: "runs/"})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(ctx, "a/1", []byte("one\r\nline"), ""))
+	require.NoError(t, s.Put(ctx, "a/2", []byte("two"), ""))
+	require.NoError(t, s.Put(ctx, "b", []byte("x"), ""))
+	data, err := s.Get(ctx, "a/1")
+	require.NoError(t, err)
+	require.Equal(t, "one\r\nline", string(data))
+	_, err = s.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	objs, err := s.List(ctx, "a/")
+	require.NoError(t, err)
+	require.Equal(t, []Object{{Key: "a/1", Size: 9}, {Key: "a/2", Size: 3}}, objs)
+
+	// 连接被复用：只认证、选库一次
+	require.Equal(t, []string{"AUTH secret", "SELECT 3", "SET runs/a/1 one\r\nline PX 60000"}, (*commands)[:3])
+
+	bad, err := Open(Config{Type: TypeRedis, Endpoint: addr, Password: "wrong"})
+	require.NoError(t, err)
+	require.ErrorContains(t, bad.Put(ctx, "k", nil, ""), "WRONGPASS")
+}
@@ -0,0 +1,178 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metadataTokenURL 是 GCE/GKE 元数据服务器上默认服务账号的令牌地址
+const metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// gcsStore 通过 GCS JSON API 访问一个桶
+type gcsStore struct {
+	bucket   string
+	endpoint string // 默认 https://storage.googleapis.com
+	client   *http.Client
+	// anonymous 为 true 时请求不带认证：设置了 Endpoint（本地模拟器）且未配置令牌
+	anonymous bool
+
+	mu       sync.Mutex
+	token    string
+	expires  time.Time // 零值表示令牌来自配置，不会过期
+	tokenURL string
+}
+
+func newGCSStore(cfg Config) (*gcsStore, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("gcs store needs bucket")
+	}
+	token := or(cfg.AccessToken, os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"))
+	return &gcsStore{
+		bucket:    cfg.Bucket,
+		anonymous: cfg.Endpoint != "" && token == "",
+		endpoint:  strings.TrimSuffix(or(cfg.Endpoint, "https://storage.googleapis.com"), "/"),
+		client:    http.DefaultClient,
+		token:     token,
+		tokenURL:  metadataTokenURL,
+	}, nil
+}
+
+func (s *gcsStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		s.endpoint, url.PathEscape(s.bucket), url.QueryEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", or(contentType, "application/octet-stream"))
+	_, err = s.do(req)
+	return err
+}
+
+func (s *gcsStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if key == "" {
+		return nil, ErrInvalidKey
+	}
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", s.endpoint, url.PathEscape(s.bucket), url.PathEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.do(req)
+}
+
+type gcsObjectList struct {
+	Items []struct {
+		Name    string    `json:"name"`
+		Size    int64     `json:"size,string"`
+		Updated time.Time `json:"updated"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+func (s *gcsStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	var (
+		out   []Object
+		token string
+	)
+	for {
+		q := url.Values{"prefix": {prefix}}
+		if token != "" {
+			q.Set("pageToken", token)
+		}
+		u := fmt.Sprintf("%s/storage/v1/b/%s/o?%s", s.endpoint, url.PathEscape(s.bucket), q.Encode())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		body, err := s.do(req)
+		if err != nil {
+			return nil, err
+		}
+		var page gcsObjectList
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("blob: decode list response: %w", err)
+		}
+		for _, it := range page.Items {
+			out = append(out, Object{Key: it.Name, Size: it.Size, Updated: it.Updated})
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		token = page.NextPageToken
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+func (s *gcsStore) do(req *http.Request) ([]byte, error) {
+	token, err := s.accessToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, httpStatusError(resp, body)
+	}
+	return body, nil
+}
+
+// accessToken 返回配置的令牌，否则从元数据服务器获取并缓存到过期前一分钟
+func (s *gcsStore) accessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && (s.expires.IsZero() || time.Now().Before(s.expires)) {
+		return s.token, nil
+	}
+	if s.anonymous {
+		return "", nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.tokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("blob: no GCS access token configured and metadata server unavailable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("blob: metadata server token request: %s", resp.Status)
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("blob: decode metadata token: %w", err)
+	}
+	s.token = tok.AccessToken
+	s.expires = time.Now().Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+	return s.token, nil
+}
@@ -0,0 +1,242 @@
+package blob
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redisMaxIdle 是连接池中保留的空闲连接数
+const redisMaxIdle = 4
+
+// redisStore 把对象保存为 Redis 字符串（RESP2 协议，不依赖客户端库）；适合生命周期短、需要低延迟的数据，
+// 设置 TTL 后写入的 key 自动过期
+type redisStore struct {
+	addr     string
+	password string
+	db       int
+	ttl      time.Duration
+
+	mu   sync.Mutex
+	idle []*redisConn
+}
+
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// redisError 是服务器返回的错误回复，连接仍可继续使用
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+func newRedisStore(cfg Config) (*redisStore, error) {
+	if cfg.DB < 0 {
+		return nil, errors.New("redis store db must not be negative")
+	}
+	return &redisStore{
+		addr:     or(cfg.Endpoint, "localhost:6379"),
+		password: or(cfg.Password, os.Getenv("REDIS_PASSWORD")),
+		db:       cfg.DB,
+		ttl:      cfg.TTL,
+	}, nil
+}
+
+func (s *redisStore) Put(ctx context.Context, key string, data []byte, _ string) error {
+	if key == "" {
+		return fmt.Errorf("%w %q", ErrInvalidKey, key)
+	}
+	args := []string{"SET", key, string(data)}
+	if s.ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(s.ttl.Milliseconds(), 10))
+	}
+	_, err := s.do(ctx, args...)
+	return err
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if key == "" {
+		return nil, fmt.Errorf("%w %q", ErrInvalidKey, key)
+	}
+	v, err := s.do(ctx, "GET", key)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, ErrNotFound
+	}
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected GET reply %T", v)
+	}
+	return b, nil
+}
+
+// List 用 SCAN 遍历匹配前缀的 key，再逐个用 STRLEN 取大小；Redis 不记录修改时间，Updated 为零值
+func (s *redisStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	pattern := redisGlobEscaper.Replace(prefix) + "*"
+	seen := map[string]bool{}
+	cursor := "0"
+	for {
+		v, err := s.do(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", "500")
+		if err != nil {
+			return nil, err
+		}
+		reply, ok := v.([]any)
+		if !ok || len(reply) != 2 {
+			return nil, fmt.Errorf("redis: unexpected SCAN reply %v", v)
+		}
+		next, _ := reply[0].([]byte)
+		keys, _ := reply[1].([]any)
+		for _, k := range keys {
+			if b, ok := k.([]byte); ok {
+				seen[string(b)] = true
+			}
+		}
+		if cursor = string(next); cursor == "0" || cursor == "" {
+			break
+		}
+	}
+	objs := make([]Object, 0, len(seen))
+	for key := range seen {
+		v, err := s.do(ctx, "STRLEN", key)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := v.(int64)
+		objs = append(objs, Object{Key: key, Size: n})
+	}
+	sort.Slice(objs, func(i, j int) bool { return objs[i].Key < objs[j].Key })
+	return objs, nil
+}
+
+// redisGlobEscaper 转义 MATCH 模式中的通配字符，使前缀按字面匹配
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// do 执行一条命令并返回回复：nil、[]byte、int64、string（状态回复）或 []any
+func (s *redisStore) do(ctx context.Context, args ...string) (any, error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v, err := c.do(ctx, args...)
+	var re redisError
+	if err != nil && !errors.As(err, &re) {
+		c.Close() // 网络或协议错误后连接状态未知，不再复用
+		return nil, err
+	}
+	s.release(c)
+	return v, err
+}
+
+func (s *redisStore) conn(ctx context.Context) (*redisConn, error) {
+	s.mu.Lock()
+	if n := len(s.idle); n > 0 {
+		c := s.idle[n-1]
+		s.idle = s.idle[:n-1]
+		s.mu.Unlock()
+		return c, nil
+	}
+	s.mu.Unlock()
+
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	c := &redisConn{Conn: nc, r: bufio.NewReader(nc)}
+	if s.password != "" {
+		if _, err := c.do(ctx, "AUTH", s.password); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if s.db != 0 {
+		if _, err := c.do(ctx, "SELECT", strconv.Itoa(s.db)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (s *redisStore) release(c *redisConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.idle) >= redisMaxIdle {
+		c.Close()
+		return
+	}
+	s.idle = append(s.idle, c)
+}
+
+func (c *redisConn) do(ctx context.Context, args ...string) (any, error) {
+	deadline, _ := ctx.Deadline() // 无截止时间时为零值，即不超时
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := io.WriteString(c, b.String()); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	return readRESP(c.r)
+}
+
+// readRESP 读取一个 RESP2 回复
+func readRESP(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+	body := line[1:]
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err // $-1 为空值
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		out := make([]any, n)
+		for i := range out {
+			if out[i], err = readRESP(r); err != nil {
+				// 数组中途出错时剩余元素未读，不能作为 redisError 返回（连接会被复用）
+				return nil, fmt.Errorf("redis: array element %d: %v", i, err)
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
@@ -0,0 +1,163 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/temporalio/samples-go/dsl2/internal/sigv4"
+)
+
+// s3Store 通过 S3 REST API（SigV4 签名）访问一个桶；设置 Endpoint 时适用于 MinIO 等兼容实现
+type s3Store struct {
+	bucket  string
+	base    *url.URL // 桶的根地址，对象路径直接拼在其后
+	signer  sigv4.Signer
+	client  *http.Client
+	nowFunc func() time.Time
+}
+
+func newS3Store(cfg Config) (*s3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3 store needs bucket")
+	}
+	s := &s3Store{
+		bucket: cfg.Bucket,
+		signer: sigv4.Signer{
+			Region:          or(cfg.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1"),
+			Service:         "s3",
+			AccessKeyID:     or(cfg.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID")),
+			SecretAccessKey: or(cfg.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY")),
+			SessionToken:    or(cfg.SessionToken, os.Getenv("AWS_SESSION_TOKEN")),
+		},
+		client:  http.DefaultClient,
+		nowFunc: time.Now,
+	}
+	if s.signer.AccessKeyID == "" || s.signer.SecretAccessKey == "" {
+		return nil, errors.New("s3 store needs accessKeyId/secretAccessKey (or AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY)")
+	}
+	raw := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", cfg.Bucket, s.signer.Region)
+	if cfg.Endpoint != "" {
+		raw = strings.TrimSuffix(cfg.Endpoint, "/") + "/" + cfg.Bucket + "/"
+	}
+	base, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	s.base = base
+	return s, nil
+}
+
+func (s *s3Store) objectURL(key string) *url.URL {
+	u := *s.base
+	u.Path += key
+	return &u
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	_, err = s.do(req, data)
+	return err
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	if key == "" {
+		return nil, ErrInvalidKey
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.do(req, nil)
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string
+		Size         int64
+		LastModified time.Time
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+func (s *s3Store) List(ctx context.Context, prefix string) ([]Object, error) {
+	var (
+		out   []Object
+		token string
+	)
+	for {
+		u := *s.base
+		q := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		u.RawQuery = q.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		body, err := s.do(req, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page listBucketResult
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("blob: decode list response: %w", err)
+		}
+		for _, c := range page.Contents {
+			out = append(out, Object{Key: c.Key, Size: c.Size, Updated: c.LastModified})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+func (s *s3Store) do(req *http.Request, payload []byte) ([]byte, error) {
+	s.signer.Sign(req, payload, s.nowFunc())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, httpStatusError(resp, body)
+	}
+	return body, nil
+}
+
+// or 返回第一个非空字符串
+func or(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
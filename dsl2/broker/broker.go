@@ -0,0 +1,110 @@
+// Package broker 是 PublishMessage/ConsumeMessage Activity 使用的消息队列抽象。
+// 内置 Kafka、NATS JetStream、SQS 与进程内 memory 后端，其他后端可通过 Register 接入
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message 是一条消息
+type Message struct {
+	// ID 用于去重：SQS FIFO 的 MessageDeduplicationId、NATS 的 Nats-Msg-Id；接收时为后端的消息 ID
+	ID      string            `json:"id,omitempty"`
+	Key     string            `json:"key,omitempty"` // Kafka 分区键、SQS FIFO 的 MessageGroupId
+	Body    []byte            `json:"body"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Delivery 是收到的一条消息；处理完成后调用 Ack，未确认的消息在可见性超时后重新投递
+type Delivery struct {
+	Message
+	Ack func(ctx context.Context) error
+}
+
+// ReceiveOptions 控制一次 Receive
+type ReceiveOptions struct {
+	Group string        // Kafka 消费组、NATS durable 名；SQS 与 memory 忽略
+	Max   int           // 最多返回的消息数
+	Wait  time.Duration // 没有消息时最多等待的时长
+	// Visibility 是收到后未确认的消息对其他消费者不可见的时长（SQS、NATS AckWait、memory）
+	Visibility time.Duration
+}
+
+// Broker 是一个消息系统的连接；topic 在 Kafka 中为 topic，NATS 中为 subject，SQS 中为队列名或队列 URL
+type Broker interface {
+	Publish(ctx context.Context, topic string, msgs []Message) error
+	// Receive 在 opts.Wait 内等到至少一条消息后返回；超时返回空列表
+	Receive(ctx context.Context, topic string, opts ReceiveOptions) ([]Delivery, error)
+	Close() error
+}
+
+// 内置后端类型
+const (
+	TypeKafka  = "kafka"
+	TypeNATS   = "nats"
+	TypeSQS    = "sqs"
+	TypeMemory = "memory"
+)
+
+// Config 描述一个命名的消息系统连接；未识别的键保留在 Extra 中，供 Register 的后端使用
+//
+//	events: { type: kafka, brokers: [kafka-1:9092, kafka-2:9092] }
+//	jobs:   { type: sqs, region: eu-west-1 }
+//	bus:    { type: nats, url: "nats://nats:4222" }
+type Config struct {
+	Type    string   `yaml:"type"`
+	Brokers []string `yaml:"brokers,omitempty"` // kafka
+	URL     string   `yaml:"url,omitempty"`     // nats
+	TLS     bool     `yaml:"tls,omitempty"`     // kafka
+	// Username、Password 用于 Kafka SASL/PLAIN 与 NATS 用户认证
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// Endpoint 覆盖 SQS 服务地址（如 LocalStack）；凭证为空时读 AWS_* 环境变量
+	Endpoint        string         `yaml:"endpoint,omitempty"`
+	Region          string         `yaml:"region,omitempty"`
+	AccessKeyID     string         `yaml:"accessKeyId,omitempty"`
+	SecretAccessKey string         `yaml:"secretAccessKey,omitempty"`
+	SessionToken    string         `yaml:"sessionToken,omitempty"`
+	Extra           map[string]any `yaml:",inline"`
+}
+
+// OpenFunc 按配置创建 Broker
+type OpenFunc func(cfg Config) (Broker, error)
+
+var (
+	mu       sync.RWMutex
+	backends = map[string]OpenFunc{
+		TypeKafka:  openKafka,
+		TypeNATS:   openNATS,
+		TypeSQS:    openSQS,
+		TypeMemory: func(Config) (Broker, error) { return NewMemory(), nil },
+	}
+)
+
+// Register 注册一种后端；与已有类型重名时覆盖，便于替换内置实现
+func Register(typ string, open OpenFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	backends[typ] = open
+}
+
+// Open 按配置创建 Broker
+func Open(cfg Config) (Broker, error) {
+	mu.RLock()
+	open, ok := backends[cfg.Type]
+	types := make([]string, 0, len(backends))
+	for t := range backends {
+		types = append(types, t)
+	}
+	mu.RUnlock()
+	if !ok {
+		sort.Strings(types)
+		return nil, fmt.Errorf("unsupported broker type %q (want %s)", cfg.Type, strings.Join(types, ", "))
+	}
+	return open(cfg)
+}
@@ -0,0 +1,127 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	require.NoError(t, m.Publish(ctx, "jobs", []Message{{Body: []byte("a")}, {Body: []byte("b")}}))
+
+	opts := ReceiveOptions{Max: 1, Wait: time.Second, Visibility: 50 * time.Millisecond}
+	ds, err := m.Receive(ctx, "jobs", opts)
+	require.NoError(t, err)
+	require.Len(t, ds, 1)
+	require.Equal(t, "a", string(ds[0].Body))
+
+	// 未确认的 a 在可见性超时前不会再次投递
+	ds2, err := m.Receive(ctx, "jobs", ReceiveOptions{Max: 5, Wait: 10 * time.Millisecond, Visibility: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, ds2, 1)
+	require.Equal(t, "b", string(ds2[0].Body))
+	require.NoError(t, ds2[0].Ack(ctx))
+
+	time.Sleep(60 * time.Millisecond)
+	ds, err = m.Receive(ctx, "jobs", opts)
+	require.NoError(t, err)
+	require.Equal(t, "a", string(ds[0].Body))
+	require.NoError(t, ds[0].Ack(ctx))
+
+	// 等待中的 Receive 在 Publish 后立即返回
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		m.Publish(ctx, "jobs", []Message{{Body: []byte("c")}})
+	}()
+	begin := time.Now()
+	ds, err = m.Receive(ctx, "jobs", ReceiveOptions{Max: 1, Wait: 5 * time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "c", string(ds[0].Body))
+	require.Less(t, time.Since(begin), time.Second)
+
+	ds, err = m.Receive(ctx, "empty", ReceiveOptions{Wait: 10 * time.Millisecond})
+	require.NoError(t, err)
+	require.Empty(t, ds)
+}
+
+func TestOpen(t *testing.T) {
+	_, err := Open(Config{Type: "carrier-pigeon"})
+	require.ErrorContains(t, err, "want kafka, memory, nats, sqs")
+
+	Register("carrier-pigeon", func(cfg Config) (Broker, error) {
+		require.Equal(t, "fast", cfg.Extra["speed"])
+		return NewMemory(), nil
+	})
+	_, err = Open(Config{Type: "carrier-pigeon", Extra: map[string]any{"speed": "fast"}})
+	require.NoError(t, err)
+
+	_, err = Open(Config{Type: TypeKafka})
+	require.ErrorContains(t, err, "brokers")
+}
+
+func TestSQS(t *testing.T) {
+	var (
+		sent    []map[string]any
+		deleted []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Contains(t, r.Header.Get("Authorization"), "/sqs/aws4_request")
+		var in map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AmazonSQS.") {
+		case "GetQueueUrl":
+			if in["QueueName"] != "jobs" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"__type":"com.amazonaws.sqs#QueueDoesNotExist","message":"The specified queue does not exist."}`))
+				return
+			}
+			w.Write([]byte(`{"QueueUrl":"http://sqs.local/123/jobs"}`))
+		case "SendMessageBatch":
+			require.Equal(t, "http://sqs.local/123/jobs", in["QueueUrl"])
+			for _, e := range in["Entries"].([]any) {
+				sent = append(sent, e.(map[string]any))
+			}
+			w.Write([]byte(`{"Successful":[]}`))
+		case "ReceiveMessage":
+			require.Equal(t, float64(20), in["WaitTimeSeconds"])
+			require.Equal(t, float64(30), in["VisibilityTimeout"])
+			w.Write([]byte(`{"Messages":[{"MessageId":"m1","ReceiptHandle":"rh1","Body":"hello",
+				"MessageAttributes":{"trace":{"DataType":"String","StringValue":"t1"}}}]}`))
+		case "DeleteMessage":
+			deleted = append(deleted, in["ReceiptHandle"].(string))
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	b, err := Open(Config{Type: TypeSQS, Endpoint: srv.URL, AccessKeyID: "AK", SecretAccessKey: "SK"})
+	require.NoError(t, err)
+
+	var msgs []Message
+	for i := 0; i < 12; i++ {
+		msgs = append(msgs, Message{Body: []byte("x"), Headers: map[string]string{"trace": "t1"}})
+	}
+	require.NoError(t, b.Publish(ctx, "jobs", msgs))
+	require.Len(t, sent, 12)
+	require.Equal(t, map[string]any{"DataType": "String", "StringValue": "t1"}, sent[0]["MessageAttributes"].(map[string]any)["trace"])
+
+	ds, err := b.Receive(ctx, "jobs", ReceiveOptions{Max: 5, Wait: time.Minute, Visibility: 30 * time.Second})
+	require.NoError(t, err)
+	require.Len(t, ds, 1)
+	require.Equal(t, Message{ID: "m1", Body: []byte("hello"), Headers: map[string]string{"trace": "t1"}}, ds[0].Message)
+	require.NoError(t, ds[0].Ack(ctx))
+	require.Equal(t, []string{"rh1"}, deleted)
+
+	err = b.Publish(ctx, "missing", msgs[:1])
+	require.EqualError(t, err, "sqs GetQueueUrl: QueueDoesNotExist: The specified queue does not exist.")
+}
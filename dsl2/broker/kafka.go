@@ -0,0 +1,134 @@
+package broker
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+)
+
+// defaultGroup 是未指定消费组时使用的 Kafka 消费组 / NATS durable 名
+const defaultGroup = "dsl"
+
+// kafkaBroker 共用一个生产者客户端；每个 (topic, group) 一个消费者客户端，
+// 保留到 Close，避免每次 Receive 都触发消费组重平衡
+type kafkaBroker struct {
+	opts     []kgo.Opt
+	producer *kgo.Client
+
+	mu        sync.Mutex
+	consumers map[[2]string]*kgo.Client
+}
+
+func openKafka(cfg Config) (Broker, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("kafka broker needs brokers")
+	}
+	opts := []kgo.Opt{kgo.SeedBrokers(cfg.Brokers...)}
+	if cfg.TLS {
+		opts = append(opts, kgo.DialTLSConfig(&tls.Config{}))
+	}
+	if cfg.Username != "" {
+		opts = append(opts, kgo.SASL(plain.Auth{User: cfg.Username, Pass: cfg.Password}.AsMechanism()))
+	}
+	producer, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaBroker{opts: opts, producer: producer, consumers: map[[2]string]*kgo.Client{}}, nil
+}
+
+func (b *kafkaBroker) Publish(ctx context.Context, topic string, msgs []Message) error {
+	recs := make([]*kgo.Record, 0, len(msgs))
+	for _, m := range msgs {
+		r := &kgo.Record{Topic: topic, Value: m.Body}
+		if m.Key != "" {
+			r.Key = []byte(m.Key)
+		}
+		for k, v := range m.Headers {
+			r.Headers = append(r.Headers, kgo.RecordHeader{Key: k, Value: []byte(v)})
+		}
+		recs = append(recs, r)
+	}
+	return b.producer.ProduceSync(ctx, recs...).FirstErr()
+}
+
+// Receive 拉取记录；Ack 提交该记录的 offset。Kafka 没有可见性超时，
+// 未提交的记录在消费者重启或重平衡后重新投递
+func (b *kafkaBroker) Receive(ctx context.Context, topic string, opts ReceiveOptions) ([]Delivery, error) {
+	c, err := b.consumer(topic, opts.Group)
+	if err != nil {
+		return nil, err
+	}
+	pollCtx, cancel := context.WithTimeout(ctx, opts.Wait)
+	defer cancel()
+	fetches := c.PollRecords(pollCtx, max(opts.Max, 1))
+	if fetches.IsClientClosed() {
+		return nil, errors.New("kafka consumer is closed")
+	}
+	for _, fe := range fetches.Errors() {
+		if errors.Is(fe.Err, context.DeadlineExceeded) || errors.Is(fe.Err, context.Canceled) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		return nil, fmt.Errorf("kafka %s[%d]: %w", fe.Topic, fe.Partition, fe.Err)
+	}
+	var ds []Delivery
+	for _, r := range fetches.Records() {
+		d := Delivery{Message: Message{
+			ID:   r.Topic + "/" + strconv.Itoa(int(r.Partition)) + "/" + strconv.FormatInt(r.Offset, 10),
+			Key:  string(r.Key),
+			Body: r.Value,
+		}}
+		for _, h := range r.Headers {
+			if d.Headers == nil {
+				d.Headers = map[string]string{}
+			}
+			d.Headers[h.Key] = string(h.Value)
+		}
+		rec := r
+		d.Ack = func(ctx context.Context) error { return c.CommitRecords(ctx, rec) }
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
+
+func (b *kafkaBroker) consumer(topic, group string) (*kgo.Client, error) {
+	if group == "" {
+		group = defaultGroup
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := [2]string{topic, group}
+	if c, ok := b.consumers[key]; ok {
+		return c, nil
+	}
+	opts := append(append([]kgo.Opt{}, b.opts...),
+		kgo.ConsumerGroup(group),
+		kgo.ConsumeTopics(topic),
+		kgo.DisableAutoCommit(),
+	)
+	c, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	b.consumers[key] = c
+	return c, nil
+}
+
+func (b *kafkaBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, c := range b.consumers {
+		c.Close()
+	}
+	b.producer.Close()
+	return nil
+}
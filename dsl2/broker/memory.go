@@ -0,0 +1,92 @@
+package broker
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Memory 是进程内的 Broker，用于本地开发与测试；每个 topic 是一个队列，消费组被忽略
+type Memory struct {
+	mu     sync.Mutex
+	queues map[string][]*memoryEntry
+	nextID int
+	notify chan struct{} // Publish 时关闭并替换，唤醒等待中的 Receive
+}
+
+type memoryEntry struct {
+	msg       Message
+	invisible time.Time // 在此之前不会再次投递
+}
+
+// NewMemory 创建一个空的 Memory
+func NewMemory() *Memory {
+	return &Memory{queues: map[string][]*memoryEntry{}, notify: make(chan struct{})}
+}
+
+func (m *Memory) Publish(_ context.Context, topic string, msgs []Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, msg := range msgs {
+		m.nextID++
+		if msg.ID == "" {
+			msg.ID = strconv.Itoa(m.nextID)
+		}
+		m.queues[topic] = append(m.queues[topic], &memoryEntry{msg: msg})
+	}
+	close(m.notify)
+	m.notify = make(chan struct{})
+	return nil
+}
+
+func (m *Memory) Receive(ctx context.Context, topic string, opts ReceiveOptions) ([]Delivery, error) {
+	timer := time.NewTimer(opts.Wait)
+	defer timer.Stop()
+	for {
+		m.mu.Lock()
+		now := time.Now()
+		var out []Delivery
+		for _, e := range m.queues[topic] {
+			if len(out) >= max(opts.Max, 1) {
+				break
+			}
+			if now.Before(e.invisible) {
+				continue
+			}
+			e.invisible = now.Add(opts.Visibility)
+			out = append(out, Delivery{Message: e.msg, Ack: m.acker(topic, e)})
+		}
+		notify := m.notify
+		m.mu.Unlock()
+		if len(out) > 0 {
+			return out, nil
+		}
+		// 不可见的消息到期时也需要醒来，这里简单地每 100ms 检查一次
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, nil
+		case <-notify:
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (m *Memory) acker(topic string, e *memoryEntry) func(context.Context) error {
+	return func(context.Context) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		q := m.queues[topic]
+		for i := range q {
+			if q[i] == e {
+				m.queues[topic] = append(q[:i:i], q[i+1:]...)
+				break
+			}
+		}
+		return nil
+	}
+}
+
+func (m *Memory) Close() error { return nil }
@@ -0,0 +1,114 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsBroker 通过 JetStream 发布与拉取，消息持久化在覆盖该 subject 的 stream 中；
+// 每个 (subject, durable) 一个 pull 订阅，保留到 Close
+type natsBroker struct {
+	nc *nats.Conn
+	js nats.JetStreamContext
+
+	mu   sync.Mutex
+	subs map[[2]string]*nats.Subscription
+}
+
+func openNATS(cfg Config) (Broker, error) {
+	var opts []nats.Option
+	if cfg.Username != "" {
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+	nc, err := nats.Connect(firstNonEmpty(cfg.URL, nats.DefaultURL), opts...)
+	if err != nil {
+		return nil, err
+	}
+	js, err := nc.JetStream()
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return &natsBroker{nc: nc, js: js, subs: map[[2]string]*nats.Subscription{}}, nil
+}
+
+func (b *natsBroker) Publish(ctx context.Context, topic string, msgs []Message) error {
+	for _, m := range msgs {
+		msg := nats.NewMsg(topic)
+		msg.Data = m.Body
+		for k, v := range m.Headers {
+			msg.Header.Set(k, v)
+		}
+		if m.ID != "" {
+			msg.Header.Set(nats.MsgIdHdr, m.ID)
+		}
+		if _, err := b.js.PublishMsg(msg, nats.Context(ctx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Receive 从 durable pull 消费者拉取；Visibility 在首次创建消费者时作为 AckWait
+func (b *natsBroker) Receive(ctx context.Context, topic string, opts ReceiveOptions) ([]Delivery, error) {
+	sub, err := b.subscription(topic, opts)
+	if err != nil {
+		return nil, err
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, opts.Wait)
+	defer cancel()
+	msgs, err := sub.Fetch(max(opts.Max, 1), nats.Context(fetchCtx))
+	if err != nil && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, nats.ErrTimeout)) && ctx.Err() == nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ds []Delivery
+	for _, m := range msgs {
+		d := Delivery{Message: Message{Body: m.Data}}
+		if meta, err := m.Metadata(); err == nil {
+			d.ID = strconv.FormatUint(meta.Sequence.Stream, 10)
+		}
+		for k := range m.Header {
+			if d.Headers == nil {
+				d.Headers = map[string]string{}
+			}
+			d.Headers[k] = m.Header.Get(k)
+		}
+		msg := m
+		d.Ack = func(ctx context.Context) error { return msg.AckSync(nats.Context(ctx)) }
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
+
+func (b *natsBroker) subscription(topic string, opts ReceiveOptions) (*nats.Subscription, error) {
+	durable := firstNonEmpty(opts.Group, defaultGroup)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := [2]string{topic, durable}
+	if sub, ok := b.subs[key]; ok {
+		return sub, nil
+	}
+	subOpts := []nats.SubOpt{nats.ManualAck()}
+	if opts.Visibility > 0 {
+		subOpts = append(subOpts, nats.AckWait(opts.Visibility.Round(time.Second)))
+	}
+	sub, err := b.js.PullSubscribe(topic, durable, subOpts...)
+	if err != nil {
+		return nil, err
+	}
+	b.subs[key] = sub
+	return sub, nil
+}
+
+func (b *natsBroker) Close() error {
+	b.nc.Close()
+	return nil
+}
@@ -0,0 +1,217 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/temporalio/samples-go/dsl2/internal/sigv4"
+)
+
+// sqsMaxWait 是 SQS 单次长轮询的上限
+const sqsMaxWait = 20 * time.Second
+
+// sqsBroker 通过 SQS JSON 协议（AWS SDK 同款）访问队列，不依赖 AWS SDK
+type sqsBroker struct {
+	endpoint string
+	signer   sigv4.Signer
+	client   *http.Client
+
+	mu   sync.Mutex
+	urls map[string]string // 队列名 -> 队列 URL
+}
+
+func openSQS(cfg Config) (Broker, error) {
+	region := firstNonEmpty(cfg.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1")
+	b := &sqsBroker{
+		endpoint: strings.TrimSuffix(firstNonEmpty(cfg.Endpoint, "https://sqs."+region+".amazonaws.com"), "/"),
+		signer: sigv4.Signer{
+			Region:          region,
+			Service:         "sqs",
+			AccessKeyID:     firstNonEmpty(cfg.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID")),
+			SecretAccessKey: firstNonEmpty(cfg.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY")),
+			SessionToken:    firstNonEmpty(cfg.SessionToken, os.Getenv("AWS_SESSION_TOKEN")),
+		},
+		client: http.DefaultClient,
+		urls:   map[string]string{},
+	}
+	if b.signer.AccessKeyID == "" || b.signer.SecretAccessKey == "" {
+		return nil, errors.New("sqs broker needs accessKeyId/secretAccessKey (or AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY)")
+	}
+	return b, nil
+}
+
+type sqsAttribute struct {
+	DataType    string `json:"DataType"`
+	StringValue string `json:"StringValue"`
+}
+
+type sqsBatchEntry struct {
+	Id                     string                  `json:"Id"`
+	MessageBody            string                  `json:"MessageBody"`
+	MessageAttributes      map[string]sqsAttribute `json:"MessageAttributes,omitempty"`
+	MessageGroupId         string                  `json:"MessageGroupId,omitempty"`
+	MessageDeduplicationId string                  `json:"MessageDeduplicationId,omitempty"`
+}
+
+func (b *sqsBroker) Publish(ctx context.Context, topic string, msgs []Message) error {
+	queue, err := b.queueURL(ctx, topic)
+	if err != nil {
+		return err
+	}
+	// SendMessageBatch 每批最多 10 条
+	for start := 0; start < len(msgs); start += 10 {
+		var entries []sqsBatchEntry
+		for i, m := range msgs[start:min(start+10, len(msgs))] {
+			e := sqsBatchEntry{
+				Id:                     strconv.Itoa(i),
+				MessageBody:            string(m.Body),
+				MessageGroupId:         m.Key,
+				MessageDeduplicationId: m.ID,
+			}
+			for k, v := range m.Headers {
+				if e.MessageAttributes == nil {
+					e.MessageAttributes = map[string]sqsAttribute{}
+				}
+				e.MessageAttributes[k] = sqsAttribute{DataType: "String", StringValue: v}
+			}
+			entries = append(entries, e)
+		}
+		var out struct {
+			Failed []struct{ Id, Code, Message string }
+		}
+		err := b.call(ctx, "SendMessageBatch", map[string]any{"QueueUrl": queue, "Entries": entries}, &out)
+		if err != nil {
+			return err
+		}
+		if len(out.Failed) > 0 {
+			f := out.Failed[0]
+			return fmt.Errorf("sqs: %d of %d messages failed: %s: %s", len(out.Failed), len(entries), f.Code, f.Message)
+		}
+	}
+	return nil
+}
+
+func (b *sqsBroker) Receive(ctx context.Context, topic string, opts ReceiveOptions) ([]Delivery, error) {
+	queue, err := b.queueURL(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	in := map[string]any{
+		"QueueUrl":              queue,
+		"MaxNumberOfMessages":   min(max(opts.Max, 1), 10),
+		"WaitTimeSeconds":       int(min(opts.Wait, sqsMaxWait) / time.Second),
+		"MessageAttributeNames": []string{"All"},
+	}
+	if opts.Visibility > 0 {
+		in["VisibilityTimeout"] = int(opts.Visibility / time.Second)
+	}
+	var out struct {
+		Messages []struct {
+			MessageId         string
+			ReceiptHandle     string
+			Body              string
+			MessageAttributes map[string]sqsAttribute
+		}
+	}
+	if err := b.call(ctx, "ReceiveMessage", in, &out); err != nil {
+		return nil, err
+	}
+	var ds []Delivery
+	for _, m := range out.Messages {
+		d := Delivery{Message: Message{ID: m.MessageId, Body: []byte(m.Body)}}
+		for k, a := range m.MessageAttributes {
+			if d.Headers == nil {
+				d.Headers = map[string]string{}
+			}
+			d.Headers[k] = a.StringValue
+		}
+		receipt := m.ReceiptHandle
+		d.Ack = func(ctx context.Context) error {
+			return b.call(ctx, "DeleteMessage", map[string]any{"QueueUrl": queue, "ReceiptHandle": receipt}, nil)
+		}
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
+
+func (b *sqsBroker) Close() error { return nil }
+
+// queueURL 把队列名解析为 URL；已是 URL 时原样返回
+func (b *sqsBroker) queueURL(ctx context.Context, topic string) (string, error) {
+	if strings.HasPrefix(topic, "https://") || strings.HasPrefix(topic, "http://") {
+		return topic, nil
+	}
+	b.mu.Lock()
+	u, ok := b.urls[topic]
+	b.mu.Unlock()
+	if ok {
+		return u, nil
+	}
+	var out struct{ QueueUrl string }
+	if err := b.call(ctx, "GetQueueUrl", map[string]any{"QueueName": topic}, &out); err != nil {
+		return "", err
+	}
+	b.mu.Lock()
+	b.urls[topic] = out.QueueUrl
+	b.mu.Unlock()
+	return out.QueueUrl, nil
+}
+
+// call 调用一个 SQS JSON 协议的操作
+func (b *sqsBroker) call(ctx context.Context, action string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	req.Header.Set("X-Amz-Target", "AmazonSQS."+action)
+	b.signer.Sign(req, body, time.Now())
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		json.Unmarshal(data, &e)
+		if e.Type == "" {
+			return fmt.Errorf("sqs %s: %s", action, resp.Status)
+		}
+		return fmt.Errorf("sqs %s: %s: %s", action, e.Type[strings.LastIndex(e.Type, "#")+1:], e.Message)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,241 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+/*
+   =============== 跨 ContinueAsNew 的状态 ===============
+*/
+
+// StateVersion 是 State 的格式版本；格式不兼容地变化时递增，恢复时拒绝其他版本
+const StateVersion = 1
+
+// State 是跨 ContinueAsNew 携带的引擎状态，随 Workflow.Resume 传给新的执行。
+// 新的执行从 PC 指向的节点继续而不是从 root[0] 开始：PC 之前的语句被跳过，
+// PC 所在的 If 沿原分支进入，While 与 Map 按 Cursors 中的进度继续
+type State struct {
+	Version  int            `json:"version"`
+	Bindings map[string]any `json:"bindings"`
+	// PC 是恢复点的节点路径（如 root[2].while.body），该节点在上一次执行中尚未开始；
+	// 不能位于 parallel 分支或 map.body 之内
+	PC string `json:"pc"`
+	// Cursors 按节点路径记录 PC 本身及其外层的 While/Map 的进度
+	Cursors map[string]*Cursor `json:"cursors,omitempty"`
+}
+
+// Cursor 是一个 While 或 Map 节点的进度
+type Cursor struct {
+	// Iter 是 While 已完成的轮数，恢复后继续计入 maxIters
+	Iter int `json:"iter,omitempty"`
+	// Items 是 Map 已完成的元素（键为下标），恢复后不再执行
+	Items map[int]MapItemState `json:"items,omitempty"`
+}
+
+// MapItemState 是 Map 一个已完成元素的结果：分支相对 Map 开始时变量的改动，或失败信息
+type MapItemState struct {
+	Changes map[string]any `json:"changes,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// check 确认状态可以在 wf 上恢复
+func (s State) check(wf Workflow) error {
+	if s.Version != StateVersion {
+		return fmt.Errorf("unsupported state version %d (want %d)", s.Version, StateVersion)
+	}
+	if s.PC == "" {
+		return errors.New("pc is required")
+	}
+	if wf.statementAt(s.PC) == nil {
+		return fmt.Errorf("pc %q matches no statement", s.PC)
+	}
+	if i := strings.Index(s.PC, ".parallel["); i >= 0 {
+		return fmt.Errorf("pc %q: cannot resume inside parallel branch %s", s.PC, s.PC[:i])
+	}
+	if i := strings.Index(s.PC, ".map.body"); i >= 0 {
+		return fmt.Errorf("pc %q: cannot resume inside the body of map %s", s.PC, s.PC[:i])
+	}
+	for path, c := range s.Cursors {
+		if !within(s.PC, path) {
+			return fmt.Errorf("cursor %q does not enclose pc %q", path, s.PC)
+		}
+		st := wf.statementAt(path)
+		switch {
+		case st == nil:
+			return fmt.Errorf("cursor %q matches no statement", path)
+		case c == nil:
+			return fmt.Errorf("cursor %q is empty", path)
+		case st.While != nil && c.Items == nil:
+		case st.Map != nil && c.Iter == 0:
+		default:
+			return fmt.Errorf("cursor %q does not match the %s statement", path, st.Kind())
+		}
+	}
+	return nil
+}
+
+// statementAt 返回路径对应的语句；路径不存在时返回 nil
+func (wf Workflow) statementAt(path string) *Statement {
+	var find func(p string, st *Statement) *Statement
+	find = func(p string, st *Statement) *Statement {
+		if st == nil || !within(path, p) {
+			return nil
+		}
+		if p == path {
+			return st
+		}
+		for _, c := range st.children() {
+			if found := find(p+"."+c.rel, c.stmt); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	for i, st := range wf.Root {
+		if found := find(rootPath(i), st); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// within 判断 pc 是否为 path 本身或位于其内
+func within(pc, path string) bool {
+	return pc == path || strings.HasPrefix(pc, path+".")
+}
+
+type resumerKey struct{}
+
+// resumer 保存恢复点与 While/Map 的进度：恢复时据此跳过已完成的部分，ContinueAsNew 时据此生成 State
+type resumer struct {
+	pc      string             // 尚未到达的恢复点，到达后清空
+	cursors map[string]*Cursor // 待恢复的进度，相应节点开始时取走
+	live    map[string]*Cursor // 正在执行的 While/Map 的进度
+}
+
+// withResumer 在 ctx 中放入 resumer；st 为 nil 表示从头执行
+func withResumer(ctx workflow.Context, st *State) workflow.Context {
+	r := &resumer{cursors: map[string]*Cursor{}, live: map[string]*Cursor{}}
+	if st != nil {
+		r.pc = st.PC
+		for path, c := range st.Cursors {
+			r.cursors[path] = c
+		}
+	}
+	return workflow.WithValue(ctx, resumerKey{}, r)
+}
+
+func resumerFrom(ctx workflow.Context) *resumer {
+	r, _ := ctx.Value(resumerKey{}).(*resumer)
+	return r
+}
+
+// skip 在语句开始时调用：恢复点之前（不包含恢复点）的语句已在上一次执行中完成，返回 true；
+// 到达恢复点本身时清空恢复点，此后正常执行
+func (r *resumer) skip(path string) bool {
+	if r == nil || r.pc == "" {
+		return false
+	}
+	if !within(r.pc, path) {
+		return true
+	}
+	if r.pc == path {
+		r.pc = ""
+	}
+	return false
+}
+
+// inside 判断恢复点是否尚未到达且位于 path 之内（组合节点据此沿原分支进入、跳过条件判断）
+func (r *resumer) inside(path string) bool {
+	return r != nil && r.pc != "" && within(r.pc, path)
+}
+
+// enter 登记 path 处 While/Map 的进度，返回上一次执行留下的进度（没有时为新的空进度）；
+// 返回的函数在节点结束时注销
+func (r *resumer) enter(path string) (*Cursor, func()) {
+	if r == nil {
+		return &Cursor{}, func() {}
+	}
+	c := r.cursors[path]
+	delete(r.cursors, path)
+	if c == nil {
+		c = &Cursor{}
+	}
+	r.live[path] = c
+	return c, func() {
+		if r.live[path] == c {
+			delete(r.live, path)
+		}
+	}
+}
+
+// continueAsNew 以 pc 为恢复点结束本次执行，由新的执行从 pc 继续；
+// 调用方须保证 pc 尚未开始，且 pc 外层没有仍在执行的并发分支
+func continueAsNew(ctx workflow.Context, wf Workflow, bindings map[string]any, pc string) error {
+	st := &State{Version: StateVersion, Bindings: cloneMap(bindings), PC: pc}
+	if r := resumerFrom(ctx); r != nil {
+		for path, c := range r.live {
+			if within(pc, path) {
+				if st.Cursors == nil {
+					st.Cursors = map[string]*Cursor{}
+				}
+				cp := *c
+				st.Cursors[path] = &cp
+			}
+		}
+	}
+	if err := st.check(wf); err != nil {
+		return fmt.Errorf("continue as new: %w", err)
+	}
+	next := wf
+	next.Resume = st
+	workflow.GetLogger(ctx).Info("Continuing as new", "pc", pc)
+	return workflow.NewContinueAsNewError(ctx, SimpleDSLWorkflow, next)
+}
+
+// checkResume 拒绝无法在 wf 上恢复的状态（不可重试，重试也不会改变结果）
+func checkResume(wf Workflow) error {
+	if wf.Resume == nil {
+		return nil
+	}
+	if err := wf.Resume.check(wf); err != nil {
+		return temporal.NewNonRetryableApplicationError("resume: "+err.Error(), "InvalidResumeState", err)
+	}
+	return nil
+}
+
+// mapItemState 记录 Map 元素的结果：只保存相对 Map 开始时变量的改动
+func mapItemState(bindings, local map[string]any, err error) MapItemState {
+	st := MapItemState{}
+	if err != nil {
+		st.Error = err.Error()
+		return st
+	}
+	for k, v := range local {
+		if old, ok := bindings[k]; !ok || !reflect.DeepEqual(old, v) {
+			if st.Changes == nil {
+				st.Changes = map[string]any{}
+			}
+			st.Changes[k] = v
+		}
+	}
+	return st
+}
+
+// restore 由记录的结果还原元素结束时的变量与错误
+func (st MapItemState) restore(bindings map[string]any) (map[string]any, error) {
+	local := cloneMap(bindings)
+	for k, v := range st.Changes {
+		local[k] = v
+	}
+	if st.Error != "" {
+		return local, errors.New(st.Error)
+	}
+	return local, nil
+}
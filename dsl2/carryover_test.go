@@ -0,0 +1,156 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+const carryOverYAML = `
+variables: { n: 0, go: true, items: [1, 2, 3] }
+root:
+  - activity: { name: DoA, args: [{ str: first }], result: a }
+  - if:
+      cond: { truthy: { ref: go } }
+      then:
+        while:
+          cond: { ne: { left: { ref: n }, right: { int: 3 } } }
+          maxIters: 3
+          body:
+            increment: { var: n }
+  - map:
+      itemsRef: items
+      collectVar: out
+      body:
+        activity: { name: DoA, args: [{ ref: _item }], result: r }
+`
+
+// runResumed 执行带恢复状态的定义，返回结果、错误与已启动 Activity 的参数
+func runResumed(t *testing.T, st *State) (map[string]any, []string, error) {
+	t.Helper()
+	var wf Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(carryOverYAML), &wf))
+	wf.Resume = st
+
+	var started []string
+	env := startWorkflow(t, wf, beforeRun(func(env *testsuite.TestWorkflowEnvironment) {
+		env.SetOnActivityStartedListener(func(_ *activity.Info, _ context.Context, args converter.EncodedValues) {
+			var arg any
+			require.NoError(t, args.Get(&arg))
+			started = append(started, fmt.Sprint(arg))
+		})
+	}))
+	if err := env.GetWorkflowError(); err != nil {
+		return nil, started, err
+	}
+	var out map[string]any
+	require.NoError(t, env.GetWorkflowResult(&out))
+	return out, started, nil
+}
+
+func TestResumeMidLoop(t *testing.T) {
+	// 恢复点在 while 第三轮的循环体内：root[0] 不再执行，if 不再求值（go 已为 false），本轮不再判断条件
+	out, started, err := runResumed(t, &State{
+		Version:  StateVersion,
+		Bindings: map[string]any{"n": 2, "go": false, "items": []any{1, 2, 3}},
+		PC:       "root[1].if.then.while.body",
+		Cursors:  map[string]*Cursor{"root[1].if.then": {Iter: 2}},
+	})
+	require.NoError(t, err)
+	require.NotContains(t, out, "a")
+	require.EqualValues(t, 3, out["n"])
+	require.Equal(t, []any{"A:1", "A:2", "A:3"}, out["out"])
+	require.ElementsMatch(t, []string{"1", "2", "3"}, started)
+
+	// 已完成的轮数继续计入 maxIters
+	_, _, err = runResumed(t, &State{
+		Version:  StateVersion,
+		Bindings: map[string]any{"n": 0, "items": []any{}},
+		PC:       "root[1].if.then",
+		Cursors:  map[string]*Cursor{"root[1].if.then": {Iter: 3}},
+	})
+	require.ErrorContains(t, err, "while exceeded MaxIters=3")
+}
+
+func TestResumeMap(t *testing.T) {
+	out, started, err := runResumed(t, &State{
+		Version:  StateVersion,
+		Bindings: map[string]any{"n": 3, "items": []any{1, 2, 3}},
+		PC:       "root[2]",
+		Cursors: map[string]*Cursor{"root[2]": {Items: map[int]MapItemState{
+			0: {Changes: map[string]any{"r": "A:1"}},
+			2: {Changes: map[string]any{"r": "A:3"}},
+		}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []any{"A:1", "A:2", "A:3"}, out["out"])
+	require.Equal(t, []string{"2"}, started)
+}
+
+// continueAsNewTestWorkflow 模拟 While 在第三轮循环体开始前触发 ContinueAsNew
+func continueAsNewTestWorkflow(ctx workflow.Context, wf Workflow) error {
+	ctx = withResumer(ctx, nil)
+	r := resumerFrom(ctx)
+	outer, leave := r.enter("root[1].if.then")
+	defer leave()
+	outer.Iter = 2
+	// 不包含恢复点的循环不携带
+	inner, leaveInner := r.enter("root[0]")
+	defer leaveInner()
+	inner.Iter = 7
+	return continueAsNew(ctx, wf, map[string]any{"n": 2, "go": false, "items": []any{1, 2, 3}}, "root[1].if.then.while.body")
+}
+
+func TestContinueAsNewCarriesState(t *testing.T) {
+	var wf Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(carryOverYAML), &wf))
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(continueAsNewTestWorkflow)
+	env.ExecuteWorkflow(continueAsNewTestWorkflow, wf)
+	var can *workflow.ContinueAsNewError
+	require.True(t, errors.As(env.GetWorkflowError(), &can))
+	require.Equal(t, WorkflowType, can.WorkflowType.Name)
+
+	var next Workflow
+	require.NoError(t, converter.GetDefaultDataConverter().FromPayloads(can.Input, &next))
+	require.NotNil(t, next.Resume)
+	require.Equal(t, StateVersion, next.Resume.Version)
+	require.Equal(t, "root[1].if.then.while.body", next.Resume.PC)
+	require.Equal(t, map[string]*Cursor{"root[1].if.then": {Iter: 2}}, next.Resume.Cursors)
+
+	out, _, err := runResumed(t, next.Resume)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, out["n"])
+	require.NotContains(t, out, "a")
+}
+
+func TestResumeRejectsInvalidState(t *testing.T) {
+	for _, tc := range []struct {
+		state State
+		err   string
+	}{
+		{State{Version: 99, PC: "root[0]"}, "unsupported state version 99"},
+		{State{Version: StateVersion}, "pc is required"},
+		{State{Version: StateVersion, PC: "root[9]"}, `pc "root[9]" matches no statement`},
+		{State{Version: StateVersion, PC: "root[2].map.body"}, "cannot resume inside the body of map root[2]"},
+		{State{Version: StateVersion, PC: "root[1]", Cursors: map[string]*Cursor{"root[2]": {}}}, `cursor "root[2]" does not enclose pc "root[1]"`},
+		{State{Version: StateVersion, PC: "root[2]", Cursors: map[string]*Cursor{"root[2]": {Iter: 1}}}, `cursor "root[2]" does not match the map statement`},
+	} {
+		_, _, err := runResumed(t, &tc.state)
+		var appErr *temporal.ApplicationError
+		require.True(t, errors.As(err, &appErr), "%v", err)
+		require.Equal(t, "InvalidResumeState", appErr.Type())
+		require.ErrorContains(t, err, tc.err)
+	}
+}
@@ -0,0 +1,234 @@
+// Package chaos 在 worker 上为 Activity 注入故障：按名称与概率匹配规则，附加延迟、
+// 阻塞到超时或返回错误，用于在真实执行中演练 DSL 定义的重试策略、FailFast 与错误处理分支。
+// 规则来自 worker 配置，或由 starter -chaos 随单次执行经 Header 传入（需 worker 允许）
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"path"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// HeaderKey 是单次执行的规则在 Temporal Header 中的键
+const HeaderKey = "dsl-chaos"
+
+// ErrorType 是注入错误的 ApplicationError 类型，可在重试策略的 nonRetryableErrorTypes 中引用
+const ErrorType = "ChaosInjected"
+
+// Rule 描述一类故障；多条规则按顺序匹配，第一条命中（按概率触发）的规则生效
+//
+//	rules:
+//	  - activity: Charge*      # Activity 名或 path.Match 通配符，省略表示全部
+//	    probability: 0.3       # 触发概率，省略为 1
+//	    attempts: 2            # 只在前 2 次尝试注入，0 表示每次
+//	    latency: 2s            # 执行前的额外延迟
+//	    jitter: 1s             # 延迟再随机增加 0~jitter
+//	    error: card declined   # 返回该错误（不执行 Activity）
+//	    nonRetryable: true
+//	  - activity: Ship
+//	    timeout: true          # 阻塞到 Activity 超时或被取消（不执行 Activity）
+type Rule struct {
+	Activity     string        `yaml:"activity,omitempty" json:"activity,omitempty"`
+	Probability  float64       `yaml:"probability,omitempty" json:"probability,omitempty"`
+	Attempts     int           `yaml:"attempts,omitempty" json:"attempts,omitempty"`
+	Latency      time.Duration `yaml:"latency,omitempty" json:"latency,omitempty"`
+	Jitter       time.Duration `yaml:"jitter,omitempty" json:"jitter,omitempty"`
+	Error        string        `yaml:"error,omitempty" json:"error,omitempty"`
+	NonRetryable bool          `yaml:"nonRetryable,omitempty" json:"nonRetryable,omitempty"`
+	Timeout      bool          `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// Config 是 worker 配置中的 chaos 段
+type Config struct {
+	Rules []Rule `yaml:"rules"`
+	// AllowPerRun 为 true 时也应用随执行传入的规则（优先于 Rules）；否则忽略它们
+	AllowPerRun bool `yaml:"allowPerRun"`
+}
+
+// Validate 检查规则的取值
+func Validate(rules []Rule) error {
+	for i, r := range rules {
+		if _, err := path.Match(r.Activity, ""); err != nil {
+			return fmt.Errorf("rules[%d].activity: %w", i, err)
+		}
+		if r.Probability < 0 || r.Probability > 1 {
+			return fmt.Errorf("rules[%d].probability must be between 0 and 1", i)
+		}
+		if r.Attempts < 0 || r.Latency < 0 || r.Jitter < 0 {
+			return fmt.Errorf("rules[%d]: attempts, latency and jitter must not be negative", i)
+		}
+		if r.Timeout && r.Error != "" {
+			return fmt.Errorf("rules[%d]: timeout and error are mutually exclusive", i)
+		}
+		if !r.Timeout && r.Error == "" && r.Latency == 0 && r.Jitter == 0 {
+			return fmt.Errorf("rules[%d] injects nothing; set latency, error or timeout", i)
+		}
+	}
+	return nil
+}
+
+// matches 判断规则是否适用于本次尝试，并按概率掷骰
+func (r Rule) matches(name string, attempt int32) bool {
+	if r.Activity != "" {
+		if ok, _ := path.Match(r.Activity, name); !ok {
+			return false
+		}
+	}
+	if r.Attempts > 0 && int(attempt) > r.Attempts {
+		return false
+	}
+	p := r.Probability
+	if p == 0 {
+		p = 1
+	}
+	return rand.Float64() < p
+}
+
+// apply 注入故障；返回 done 为 false 时继续执行 Activity
+func (r Rule) apply(ctx context.Context) (done bool, err error) {
+	if d := r.Latency + jitter(r.Jitter); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case <-t.C:
+		}
+	}
+	switch {
+	case r.Timeout:
+		<-ctx.Done()
+		return true, ctx.Err()
+	case r.Error != "" && r.NonRetryable:
+		return true, temporal.NewNonRetryableApplicationError(r.Error, ErrorType, nil)
+	case r.Error != "":
+		return true, temporal.NewApplicationError(r.Error, ErrorType)
+	}
+	return false, nil
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return rand.N(max)
+}
+
+// NewInterceptor 返回按 cfg 注入故障的 worker 拦截器
+func NewInterceptor(cfg Config) interceptor.WorkerInterceptor {
+	return &workerInterceptor{cfg: cfg}
+}
+
+type workerInterceptor struct {
+	interceptor.WorkerInterceptorBase
+	cfg Config
+}
+
+func (w *workerInterceptor) InterceptActivity(ctx context.Context, next interceptor.ActivityInboundInterceptor) interceptor.ActivityInboundInterceptor {
+	a := &activityInterceptor{cfg: w.cfg}
+	a.Next = next
+	return a
+}
+
+type activityInterceptor struct {
+	interceptor.ActivityInboundInterceptorBase
+	cfg Config
+}
+
+func (a *activityInterceptor) ExecuteActivity(ctx context.Context, in *interceptor.ExecuteActivityInput) (any, error) {
+	info := activity.GetInfo(ctx)
+	rules := a.cfg.Rules
+	if a.cfg.AllowPerRun {
+		rules = append(RulesFrom(ctx), rules...)
+	}
+	for _, r := range rules {
+		if !r.matches(info.ActivityType.Name, info.Attempt) {
+			continue
+		}
+		activity.GetLogger(ctx).Warn("chaos: injecting fault", "Activity", info.ActivityType.Name, "Attempt", info.Attempt,
+			"Latency", r.Latency, "Error", r.Error, "Timeout", r.Timeout)
+		if done, err := r.apply(ctx); done {
+			return nil, err
+		}
+		break
+	}
+	return a.Next.ExecuteActivity(ctx, in)
+}
+
+type rulesKey struct{}
+
+// WithRules 把单次执行的规则放入启动执行所用的 context，由 Propagator 写入 Header
+func WithRules(ctx context.Context, rules []Rule) context.Context {
+	return context.WithValue(ctx, rulesKey{}, rules)
+}
+
+// RulesFrom 返回 context 中随执行传入的规则
+func RulesFrom(ctx context.Context) []Rule {
+	rules, _ := ctx.Value(rulesKey{}).([]Rule)
+	return rules
+}
+
+// Propagator 把规则从客户端 context 带到工作流，再随每个 Activity 的 Header 带到 worker；
+// 启动执行的客户端与 worker 的客户端都需要注册
+func Propagator() workflow.ContextPropagator {
+	return propagator{}
+}
+
+type propagator struct{}
+
+func (propagator) Inject(ctx context.Context, w workflow.HeaderWriter) error {
+	return inject(RulesFrom(ctx), w)
+}
+
+func (propagator) InjectFromWorkflow(ctx workflow.Context, w workflow.HeaderWriter) error {
+	rules, _ := ctx.Value(rulesKey{}).([]Rule)
+	return inject(rules, w)
+}
+
+func (propagator) Extract(ctx context.Context, r workflow.HeaderReader) (context.Context, error) {
+	rules, err := extract(r)
+	if err != nil || rules == nil {
+		return ctx, err
+	}
+	return WithRules(ctx, rules), nil
+}
+
+func (propagator) ExtractToWorkflow(ctx workflow.Context, r workflow.HeaderReader) (workflow.Context, error) {
+	rules, err := extract(r)
+	if err != nil || rules == nil {
+		return ctx, err
+	}
+	return workflow.WithValue(ctx, rulesKey{}, rules), nil
+}
+
+func inject(rules []Rule, w workflow.HeaderWriter) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	p, err := converter.GetDefaultDataConverter().ToPayload(rules)
+	if err != nil {
+		return err
+	}
+	w.Set(HeaderKey, p)
+	return nil
+}
+
+func extract(r workflow.HeaderReader) ([]Rule, error) {
+	p, ok := r.Get(HeaderKey)
+	if !ok {
+		return nil, nil
+	}
+	var rules []Rule
+	if err := converter.GetDefaultDataConverter().FromPayload(p, &rules); err != nil {
+		return nil, fmt.Errorf("decode %s header: %w", HeaderKey, err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,120 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// callTwice 以给定超时调用两次 Echo，每次最多重试 3 次
+func callTwice(ctx workflow.Context, timeout time.Duration) ([]string, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: timeout,
+		RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 3, InitialInterval: time.Millisecond},
+	})
+	var out []string
+	for _, s := range []string{"a", "b"} {
+		var r string
+		if err := workflow.ExecuteActivity(ctx, "Echo", s).Get(ctx, &r); err != nil {
+			return out, err
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func newEnv(t *testing.T, cfg Config) (*testsuite.TestWorkflowEnvironment, *int) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(worker.Options{Interceptors: []interceptor.WorkerInterceptor{NewInterceptor(cfg)}})
+	env.SetContextPropagators([]workflow.ContextPropagator{Propagator()})
+	env.RegisterWorkflow(callTwice)
+	calls := new(int)
+	env.RegisterActivityWithOptions(func(ctx context.Context, s string) (string, error) {
+		*calls++
+		return s, nil
+	}, activity.RegisterOptions{Name: "Echo"})
+	return env, calls
+}
+
+func TestInjectedErrorsAreRetried(t *testing.T) {
+	env, calls := newEnv(t, Config{Rules: []Rule{{Activity: "Ech?", Attempts: 2, Error: "flaky"}}})
+	env.ExecuteWorkflow(callTwice, time.Minute)
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatal(err)
+	}
+	// 每次调用的前两次尝试被注入错误，第三次才真正执行
+	if *calls != 2 {
+		t.Errorf("activity ran %d time(s), want 2", *calls)
+	}
+}
+
+func TestNonRetryableError(t *testing.T) {
+	env, calls := newEnv(t, Config{Rules: []Rule{{Activity: "Other"}, {Error: "declined", NonRetryable: true}}})
+	env.ExecuteWorkflow(callTwice, time.Minute)
+	var appErr *temporal.ApplicationError
+	if err := env.GetWorkflowError(); !errors.As(err, &appErr) || appErr.Type() != ErrorType || !strings.Contains(err.Error(), "declined") {
+		t.Fatalf("workflow error = %v, want a %s error", err, ErrorType)
+	}
+	if *calls != 0 {
+		t.Errorf("activity ran %d time(s), want 0", *calls)
+	}
+}
+
+func TestPerRunRules(t *testing.T) {
+	header := &commonpb.Header{Fields: map[string]*commonpb.Payload{}}
+	ctx := WithRules(context.Background(), []Rule{{Timeout: true}})
+	if err := Propagator().Inject(ctx, headerWriter{header}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未允许时忽略随执行传入的规则
+	env, calls := newEnv(t, Config{})
+	env.SetHeader(header)
+	env.ExecuteWorkflow(callTwice, time.Minute)
+	if err := env.GetWorkflowError(); err != nil || *calls != 2 {
+		t.Fatalf("error = %v, calls = %d; want per-run rules ignored", err, *calls)
+	}
+
+	env, calls = newEnv(t, Config{AllowPerRun: true})
+	env.SetHeader(header)
+	env.ExecuteWorkflow(callTwice, 50*time.Millisecond)
+	var timeoutErr *temporal.TimeoutError
+	if err := env.GetWorkflowError(); !errors.As(err, &timeoutErr) {
+		t.Fatalf("workflow error = %v, want a timeout", err)
+	}
+	if *calls != 0 {
+		t.Errorf("activity ran %d time(s), want 0", *calls)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, rules := range [][]Rule{
+		{{Activity: "[", Error: "x"}},
+		{{Probability: 2, Error: "x"}},
+		{{Latency: -time.Second}},
+		{{Timeout: true, Error: "x"}},
+		{{Activity: "Echo"}},
+	} {
+		if err := Validate(rules); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", rules)
+		}
+	}
+	if err := Validate([]Rule{{Activity: "Charge*", Probability: 0.5, Latency: time.Second, Jitter: time.Second}}); err != nil {
+		t.Error(err)
+	}
+}
+
+type headerWriter struct{ h *commonpb.Header }
+
+func (w headerWriter) Set(key string, p *commonpb.Payload) { w.h.Fields[key] = p }
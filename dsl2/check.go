@@ -0,0 +1,549 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/temporalio/samples-go/dsl2/internal/jsonschema"
+)
+
+// 校验问题级别：error 会导致执行失败，warning 仅提示
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// maxRandomInt 是 random int 上下限的绝对值上限（float64 可精确表示的最大整数 2^53）；
+// 更大的范围在 int64 转换与 hi-lo+1 时可能溢出，使 rand.Int63n panic
+const maxRandomInt = 1 << 53
+
+// Issue 是一条结构化的校验问题；Path 与图节点 ID 一致，工作流级问题为空
+type Issue struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+	// Rule 是可由 Lint 调整级别的规则 ID（见 LintRules）；结构性错误没有 Rule，始终为 error
+	Rule string `json:"rule,omitempty"`
+}
+
+func (i Issue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return i.Path + ": " + i.Message
+}
+
+// CheckOptions 控制 Check 的可选检查
+type CheckOptions struct {
+	// KnownActivities 是 worker 注册的 Activity 名；为空时不检查未知 Activity
+	KnownActivities []string
+}
+
+// Check 返回定义中的全部问题（不止第一个），包括未使用的变量、未定义的引用、未知 Activity 等警告
+func (wf Workflow) Check(opts CheckOptions) []Issue {
+	c := &checker{
+		known:   map[string]bool{},
+		defined: map[string]bool{},
+		refs:    map[string][]string{},
+		nodes:   map[string]bool{},
+	}
+	for _, name := range opts.KnownActivities {
+		c.known[name] = true
+	}
+	for k := range wf.Variables {
+		c.defined[k] = true
+	}
+	if err := checkVersion(wf.Version); err != nil {
+		c.errorf("", "%v", err)
+	}
+	if len(wf.Root) == 0 && len(wf.Entrypoints) == 0 {
+		c.errorf("", "root statement array is empty")
+	}
+	if wf.StartDelaySec < 0 {
+		c.errorf("", "startDelaySec must not be negative")
+	}
+	if wf.ExecutionTimeoutSec < 0 {
+		c.errorf("", "executionTimeoutSec must not be negative")
+	}
+	if wf.RunTimeoutSec < 0 {
+		c.errorf("", "runTimeoutSec must not be negative")
+	}
+	if wf.ExecutionTimeoutSec > 0 && wf.RunTimeoutSec > wf.ExecutionTimeoutSec {
+		c.warnf("", "runTimeoutSec %d exceeds executionTimeoutSec %d; the execution timeout applies first", wf.RunTimeoutSec, wf.ExecutionTimeoutSec)
+	}
+	if wf.WorkflowIDTemplate != "" {
+		if _, err := ParseIDTemplate(wf.WorkflowIDTemplate); err != nil {
+			c.errorf("", "workflowIdTemplate: %v", err)
+		} else if refs, err := templateRefs(wf.WorkflowIDTemplate); err == nil {
+			// ID 在启动前渲染，只能引用初始变量
+			for _, name := range refs {
+				if _, ok := wf.Variables[name]; ok {
+					c.ref("workflowIdTemplate", name)
+				} else {
+					c.warnf("", "workflowIdTemplate references %q, which is not in variables and must be supplied at start", name)
+				}
+			}
+		}
+	}
+	if _, err := wf.StartReusePolicy(); err != nil {
+		c.errorf("", "%v", err)
+	}
+	if wf.OutputSchema != nil {
+		if err := jsonschema.Check(wf.OutputSchema); err != nil {
+			c.errorf("", "outputSchema: %v", err)
+		}
+	}
+	for i, st := range wf.Root {
+		c.stmt(rootPath(i), st)
+	}
+	for i, st := range wf.OnCancel {
+		c.stmt(onCancelPath(i), st)
+	}
+	for _, name := range wf.EntryNames() {
+		if len(wf.Entrypoints[name]) == 0 {
+			c.errorf("entrypoints."+name, "entry point has no statements")
+		}
+		for i, st := range wf.Entrypoints[name] {
+			c.stmt(entryPath(name, i), st)
+		}
+	}
+
+	// 引用检查需要先收集全部写入点，因此放在遍历之后
+	names := make([]string, 0, len(c.refs))
+	for name := range c.refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !c.defined[name] {
+			for _, p := range c.refs[name] {
+				c.rulef(RuleUnknownRef, p, "variable %q is never defined", name)
+			}
+		}
+	}
+	unused := make([]string, 0)
+	for k := range wf.Variables {
+		if _, ok := c.refs[k]; !ok {
+			unused = append(unused, k)
+		}
+	}
+	sort.Strings(unused)
+	for _, k := range unused {
+		c.rulef(RuleUnusedVariable, "", "variable %q is never referenced", k)
+	}
+	for _, b := range wf.Breakpoints {
+		if !c.nodes[b] {
+			c.warnf("", "breakpoint %q matches no statement id or node path", b)
+		}
+	}
+	return c.issues
+}
+
+// HasErrors 判断问题列表中是否存在 error 级别的问题
+func HasErrors(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+type checker struct {
+	issues  []Issue
+	known   map[string]bool
+	defined map[string]bool     // 声明或写入过的变量
+	refs    map[string][]string // 变量名 -> 引用位置
+	nodes   map[string]bool     // 出现过的节点路径与 Statement.ID，用于检查断点
+}
+
+func (c *checker) errorf(path, format string, args ...any) {
+	c.issues = append(c.issues, Issue{Severity: SeverityError, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) warnf(path, format string, args ...any) {
+	c.issues = append(c.issues, Issue{Severity: SeverityWarning, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// rulef 记录一条属于 rule 的警告，Lint 可按配置调整其级别
+func (c *checker) rulef(rule, path, format string, args ...any) {
+	c.issues = append(c.issues, Issue{Severity: SeverityWarning, Path: path, Message: fmt.Sprintf(format, args...), Rule: rule})
+}
+
+func (c *checker) ref(path, name string) {
+	c.refs[name] = append(c.refs[name], path)
+}
+
+func (c *checker) stmt(path string, s *Statement) {
+	if s == nil {
+		c.errorf(path, "nil statement")
+		return
+	}
+	c.nodes[path] = true
+	if s.ID != "" {
+		c.nodes[s.ID] = true
+	}
+	if countKinds(s) != 1 {
+		c.errorf(path, "statement(id=%s) must have exactly one of activity/parallel/map/while/if/session/marker/log/noop/append/merge/unset/increment/random/now/newId/aggregate/set/wait", s.ID)
+		return
+	}
+	if s.When != nil {
+		c.cond(path, *s.When)
+	}
+	switch {
+	case s.Activity != nil:
+		a := s.Activity
+		if a.Name == "" {
+			c.errorf(path, "activity name required")
+		} else if len(c.known) > 0 && !c.known[a.Name] {
+			c.rulef(RuleUnknownActivity, path, "activity %q is not registered by the worker", a.Name)
+		}
+		for i, v := range a.Args {
+			c.value(path, fmt.Sprintf("arg[%d]", i), v)
+		}
+		if a.Result != "" {
+			c.defined[a.Result] = true
+		}
+	case s.Parallel != nil:
+		if len(*s.Parallel) == 0 {
+			c.warnf(path, "parallel has no branches")
+		}
+		c.parallelWrites(path, *s.Parallel)
+	case s.Map != nil:
+		if s.Map.ItemsRef == "" {
+			c.errorf(path, "map itemsRef required")
+		} else {
+			c.ref(path, s.Map.ItemsRef)
+		}
+		if s.Map.Body == nil {
+			c.errorf(path, "map body required")
+		}
+		if s.Map.RatePerMinute < 0 {
+			c.errorf(path, "map ratePerMinute must not be negative")
+		}
+		if st := s.Map.StreamTo; st != nil {
+			switch {
+			case (st.WorkflowID == "") == (st.WorkflowIDRef == ""):
+				c.errorf(path, "map streamTo requires exactly one of workflowId and workflowIdRef")
+			case st.WorkflowIDRef != "":
+				c.ref(path, st.WorkflowIDRef)
+			}
+		}
+		itemVar := s.Map.ItemVar
+		if itemVar == "" {
+			itemVar = "_item"
+		}
+		c.defined[itemVar] = true
+		if s.Map.CollectVar != "" {
+			c.defined[s.Map.CollectVar] = true
+		}
+	case s.While != nil:
+		c.cond(path, s.While.Cond)
+		if s.While.Body == nil {
+			c.errorf(path, "while body required")
+		}
+		if s.While.MaxIters <= 0 {
+			c.rulef(RuleUnboundedWhile, path, "while has no maxIters and may loop forever")
+		}
+	case s.If != nil:
+		c.cond(path, s.If.Cond)
+		if s.If.Then == nil {
+			c.errorf(path, "if then branch required")
+		}
+	case s.Session != nil:
+		if len(s.Session.Steps) == 0 {
+			c.warnf(path, "session has no steps")
+		}
+		if strings.Contains(path, "session.steps") {
+			c.errorf(path, "sessions cannot be nested")
+		}
+	case s.Marker != nil:
+		if s.Marker.Name == "" {
+			c.errorf(path, "marker name required")
+		}
+		for _, name := range s.Marker.Refs {
+			c.ref(path, name)
+		}
+	case s.Append != nil:
+		if s.Append.To == "" {
+			c.errorf(path, "append to required")
+		} else {
+			// 既读取又写入目标变量；变量可以不存在
+			c.ref(path, s.Append.To)
+			c.defined[s.Append.To] = true
+		}
+		c.value(path, "value", s.Append.Value)
+	case s.Merge != nil:
+		if s.Merge.Into == "" {
+			c.errorf(path, "merge into required")
+		} else {
+			c.ref(path, s.Merge.Into)
+			c.defined[s.Merge.Into] = true
+		}
+		if s.Merge.Value.Ref == "" {
+			c.errorf(path, "merge value must reference an object variable")
+		} else {
+			c.ref(path, s.Merge.Value.Ref)
+		}
+	case s.Increment != nil:
+		inc := s.Increment
+		if inc.Var == "" {
+			c.errorf(path, "increment var required")
+		} else {
+			c.ref(path, inc.Var)
+			c.defined[inc.Var] = true
+		}
+		if inc.By != nil {
+			c.value(path, "by", *inc.By)
+			if inc.By.Str != nil || inc.By.Bool != nil {
+				c.errorf(path, "increment by must be a number")
+			}
+		}
+		if inc.Min != nil && inc.Max != nil && *inc.Min > *inc.Max {
+			c.errorf(path, "increment min %v is greater than max %v", *inc.Min, *inc.Max)
+		}
+	case s.Random != nil:
+		r := s.Random
+		if r.Result == "" {
+			c.errorf(path, "random result required")
+		} else {
+			c.defined[r.Result] = true
+		}
+		n := 0
+		for _, set := range []bool{r.Int != nil, r.Float != nil, r.Choice != nil, r.From != ""} {
+			if set {
+				n++
+			}
+		}
+		if n != 1 {
+			c.errorf(path, "random must have exactly one of int/float/choice/from")
+		}
+		switch {
+		case r.Int != nil && !(math.Abs(r.Int.Min) <= maxRandomInt && math.Abs(r.Int.Max) <= maxRandomInt):
+			c.errorf(path, "random int range [%v, %v] exceeds ±%d", r.Int.Min, r.Int.Max, int64(maxRandomInt))
+		case r.Int != nil && math.Ceil(r.Int.Min) > math.Floor(r.Int.Max):
+			c.errorf(path, "random int range [%v, %v] contains no integer", r.Int.Min, r.Int.Max)
+		case r.Float != nil && r.Float.Min >= r.Float.Max:
+			c.errorf(path, "random float min %v must be less than max %v", r.Float.Min, r.Float.Max)
+		case r.Choice != nil && len(r.Choice) == 0:
+			c.errorf(path, "random choice is empty")
+		case r.From != "":
+			c.ref(path, r.From)
+		}
+		for i, v := range r.Choice {
+			c.value(path, fmt.Sprintf("choice[%d]", i), v)
+		}
+	case s.Now != nil:
+		if s.Now.Result == "" {
+			c.errorf(path, "now result required")
+		} else {
+			c.defined[s.Now.Result] = true
+		}
+		if _, err := s.Now.location(); err != nil {
+			c.errorf(path, "now timezone: %v", err)
+		}
+		if _, err := truncateTime(time.Time{}, s.Now.Truncate); err != nil {
+			c.errorf(path, "now: %v", err)
+		}
+	case s.NewID != nil:
+		n := s.NewID
+		if n.Result == "" {
+			c.errorf(path, "newId result required")
+		} else {
+			c.defined[n.Result] = true
+		}
+		switch n.Format {
+		case "", "uuid":
+			if n.Length != 0 {
+				c.errorf(path, "newId length only applies to the hex format")
+			}
+		case "hex":
+			if n.Length < 0 || n.Length > 32 {
+				c.errorf(path, "newId length must be between 1 and 32")
+			}
+		default:
+			c.errorf(path, "unknown newId format %q (want uuid/hex)", n.Format)
+		}
+	case s.Aggregate != nil:
+		a := s.Aggregate
+		switch a.Op {
+		case AggSum, AggAvg, AggMin, AggMax, AggCount:
+		default:
+			c.errorf(path, "unknown aggregate op %q (want sum/avg/min/max/count)", a.Op)
+		}
+		if a.From == "" {
+			c.errorf(path, "aggregate from required")
+		} else {
+			c.ref(path, a.From)
+		}
+		if a.Result == "" {
+			c.errorf(path, "aggregate result required")
+		} else {
+			c.defined[a.Result] = true
+		}
+		if a.Op == AggCount && a.Field != "" {
+			c.warnf(path, "aggregate field is ignored by count")
+		}
+	case s.Set != nil:
+		if s.Set.Var == "" {
+			c.errorf(path, "set var required")
+		} else {
+			c.defined[s.Set.Var] = true
+		}
+		c.value(path, "value", s.Set.Value)
+	case s.Wait != nil:
+		w := s.Wait
+		n := 0
+		for _, set := range []bool{w.Seconds != 0, w.SecondsRef != "", w.Until != "", w.UntilRef != ""} {
+			if set {
+				n++
+			}
+		}
+		if n != 1 {
+			c.errorf(path, "wait must have exactly one of seconds/secondsRef/until/untilRef")
+		}
+		if w.Seconds < 0 {
+			c.errorf(path, "wait seconds must not be negative")
+		}
+		if w.Until != "" {
+			if _, err := time.Parse(time.RFC3339, w.Until); err != nil {
+				c.errorf(path, "wait until: %v", err)
+			}
+		}
+		for _, r := range []string{w.SecondsRef, w.UntilRef} {
+			if r != "" {
+				c.ref(path, r)
+			}
+		}
+	case s.Unset != nil:
+		if len(s.Unset.Vars) == 0 {
+			c.warnf(path, "unset has no vars")
+		}
+		for _, name := range s.Unset.Vars {
+			c.ref(path, name)
+		}
+	case s.Log != nil:
+		switch s.Log.Level {
+		case "", LogDebug, LogInfo, LogWarn, LogError:
+		default:
+			c.errorf(path, "unknown log level %q (want debug/info/warn/error)", s.Log.Level)
+		}
+		if s.Log.Message == "" {
+			c.errorf(path, "log message required")
+		} else if refs, err := templateRefs(s.Log.Message); err != nil {
+			c.errorf(path, "log message: %v", err)
+		} else {
+			for _, name := range refs {
+				c.ref(path, name)
+			}
+		}
+	}
+	for _, ch := range s.children() {
+		if ch.stmt == nil && ch.edge != "branch" && ch.edge != "step" {
+			continue // 缺失的 body/then 已在上面报告
+		}
+		c.stmt(path+"."+ch.rel, ch.stmt)
+	}
+}
+
+func countKinds(s *Statement) int {
+	n := 0
+	for _, set := range []bool{
+		s.Activity != nil, s.Parallel != nil, s.Map != nil, s.While != nil, s.If != nil, s.Session != nil,
+		s.Marker != nil, s.Log != nil, s.Noop != nil, s.Append != nil, s.Merge != nil, s.Unset != nil,
+		s.Increment != nil, s.Random != nil, s.Now != nil, s.NewID != nil,
+		s.Aggregate != nil, s.Set != nil, s.Wait != nil,
+	} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// parallelWrites 提示多个分支写入同一变量（值不同时合并会失败）
+func (c *checker) parallelWrites(path string, branches Parallel) {
+	writers := map[string][]int{}
+	for i, b := range branches {
+		if b != nil && b.Activity != nil && b.Activity.Result != "" {
+			writers[b.Activity.Result] = append(writers[b.Activity.Result], i)
+		}
+	}
+	vars := make([]string, 0, len(writers))
+	for v, idx := range writers {
+		if len(idx) > 1 {
+			vars = append(vars, v)
+		}
+	}
+	sort.Strings(vars)
+	for _, v := range vars {
+		c.rulef(RuleParallelConflict, path, "branches %v all write %q; differing values fail the merge", writers[v], v)
+	}
+}
+
+func (c *checker) cond(path string, cd Cond) {
+	n := 0
+	if cd.Not != nil {
+		n++
+		c.cond(path, *cd.Not)
+	}
+	if len(cd.All) > 0 {
+		n++
+		for _, sub := range cd.All {
+			c.cond(path, sub)
+		}
+	}
+	if len(cd.Any) > 0 {
+		n++
+		for _, sub := range cd.Any {
+			c.cond(path, sub)
+		}
+	}
+	if cd.Truthy != nil {
+		n++
+		c.value(path, "truthy", *cd.Truthy)
+	}
+	for _, cmp := range []*Compare{cd.Eq, cd.Ne} {
+		if cmp != nil {
+			n++
+			c.value(path, "left", cmp.Left)
+			c.value(path, "right", cmp.Right)
+		}
+	}
+	if n == 0 {
+		c.errorf(path, "empty condition")
+	}
+}
+
+func (c *checker) value(path, what string, v Value) {
+	if v.Ref != "" {
+		c.ref(path, v.Ref)
+		return
+	}
+	if v.Fn != nil {
+		if err := checkFunc(*v.Fn); err != nil {
+			c.errorf(path, "%s: %v", what, err)
+		}
+		for i, a := range v.Fn.Args {
+			c.value(path, fmt.Sprintf("%s.%s[%d]", what, v.Fn.Name, i), a)
+		}
+		return
+	}
+	if v.Str == nil && v.Int == nil && v.Float == nil && v.Bool == nil {
+		c.errorf(path, "%s: empty value", what)
+	}
+}
+
+// firstError 把 Check 的第一个 error 级问题转为 error
+func firstError(issues []Issue) error {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return errors.New(i.String())
+		}
+	}
+	return nil
+}
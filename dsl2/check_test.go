@@ -0,0 +1,94 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCheckReportsAllIssues(t *testing.T) {
+	var wf Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(`
+variables:
+  items: [1, 2]
+  debug: true
+breakpoints: ["root[2]", missing]
+root:
+  - map:
+      itemsRef: itms
+      body:
+        activity: { name: ProcessItem, args: [{ ref: _item }] }
+  - if:
+      cond: {}
+      then:
+        activity: { args: [{}] }
+  - while:
+      cond: { truthy: { ref: done } }
+      body:
+        activity: { name: Poll, result: done }
+  - session:
+      steps:
+        - session: {}
+  - marker: { refs: [debug] }
+  - log: { level: loud, message: "{{.total}} of {{range .items}}{{.id}}{{end}}" }
+  - log: { message: "{{.broken" }
+  - append: { value: {} }
+  - merge: { into: cfg, value: { str: x } }
+  - increment: { var: n, by: { str: x }, min: 5, max: 1 }
+  - random: { int: { min: 1.5, max: 1.8 }, from: pool }
+  - now: { result: ts, truncate: week, timezone: Mars/Olympus }
+  - newId: { result: id, length: 8 }
+  - aggregate: { op: median, from: items, field: x, result: mid }
+  - set: { var: name, value: { fn: { name: upper, args: [{ ref: first }, {}] } } }
+  - wait: { seconds: 5, until: tomorrow }
+  - map:
+      itemsRef: items
+      streamTo: { workflowId: consumer, workflowIdRef: consumerId }
+      body: { noop: {} }
+  - when: { truthy: { ref: rush } }
+    noop: {}
+`), &wf))
+
+	issues := wf.Check(CheckOptions{KnownActivities: ActivityNames()})
+	var got []string
+	for _, i := range issues {
+		got = append(got, i.Severity+" "+i.String())
+	}
+	require.ElementsMatch(t, []string{
+		"error root[1]: empty condition",
+		"error root[1].if.then: activity name required",
+		"error root[1].if.then: arg[0]: empty value",
+		"warning root[2]: while has no maxIters and may loop forever",
+		`warning root[2].while.body: activity "Poll" is not registered by the worker`,
+		"warning root[3].session.steps[0]: session has no steps",
+		"error root[3].session.steps[0]: sessions cannot be nested",
+		`warning root[0]: variable "itms" is never defined`,
+		"error root[4]: marker name required",
+		`error root[5]: unknown log level "loud" (want debug/info/warn/error)`,
+		`warning root[5]: variable "total" is never defined`,
+		"error root[6]: log message: template: refs:1: unclosed action",
+		"error root[7]: append to required",
+		"error root[7]: value: empty value",
+		"error root[8]: merge value must reference an object variable",
+		"error root[9]: increment by must be a number",
+		"error root[9]: increment min 5 is greater than max 1",
+		"error root[10]: random result required",
+		"error root[10]: random must have exactly one of int/float/choice/from",
+		"error root[10]: random int range [1.5, 1.8] contains no integer",
+		"error root[11]: now timezone: unknown time zone Mars/Olympus",
+		`error root[11]: now: invalid truncate "week" (want day/hour/minute/second or a positive duration)`,
+		"error root[12]: newId length only applies to the hex format",
+		`error root[13]: unknown aggregate op "median" (want sum/avg/min/max/count)`,
+		"error root[14]: value: upper takes 1 arg, got 2",
+		"error root[14]: value.upper[1]: empty value",
+		`warning root[14]: variable "first" is never defined`,
+		"error root[15]: wait must have exactly one of seconds/secondsRef/until/untilRef",
+		`error root[15]: wait until: parsing time "tomorrow" as "2006-01-02T15:04:05Z07:00": cannot parse "tomorrow" as "2006"`,
+		"error root[16]: map streamTo requires exactly one of workflowId and workflowIdRef",
+		`warning root[17]: variable "rush" is never defined`,
+		`warning breakpoint "missing" matches no statement id or node path`,
+	}, got)
+	require.True(t, HasErrors(issues))
+	require.EqualError(t, wf.Validate(), "root[1]: empty condition")
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// 集群内 Pod 挂载的 ServiceAccount 凭据
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	apiGroupVersion   = "dsl.temporal.io/v1alpha1"
+	resourcePlural    = "dslworkflows"
+)
+
+// requestTimeout 限制 watch 以外的单次 API 请求
+const requestTimeout = 30 * time.Second
+
+// kubeClient 是访问 DSLWorkflow 资源的最小 Kubernetes REST 客户端（只用标准库，不依赖 client-go）
+type kubeClient struct {
+	server    string
+	tokenFile string // 每次请求重新读取，ServiceAccount 的投射 token 会轮换
+	http      *http.Client
+}
+
+// kubeConfig 是连接 API Server 的参数；Server 为空时使用集群内配置
+type kubeConfig struct {
+	Server    string
+	CAFile    string
+	TokenFile string
+}
+
+func newKubeClient(cfg kubeConfig) (*kubeClient, error) {
+	if cfg.Server == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, errors.New("not running in a cluster; set -kube-server (e.g. http://127.0.0.1:8001 with kubectl proxy)")
+		}
+		cfg.Server = "https://" + net.JoinHostPort(host, port)
+		if cfg.CAFile == "" {
+			cfg.CAFile = serviceAccountDir + "/ca.crt"
+		}
+		if cfg.TokenFile == "" {
+			cfg.TokenFile = serviceAccountDir + "/token"
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read kubernetes CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", cfg.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &kubeClient{
+		server:    strings.TrimSuffix(cfg.Server, "/"),
+		tokenFile: cfg.TokenFile,
+		http:      &http.Client{Transport: transport},
+	}, nil
+}
+
+// apiError 是 API Server 返回的非 2xx 响应（Status 对象）
+type apiError struct {
+	Code    int    `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("kubernetes API %d %s: %s", e.Code, e.Reason, e.Message)
+}
+
+func isStatus(err error, code int) bool {
+	var ae *apiError
+	return errors.As(err, &ae) && ae.Code == code
+}
+
+// resourcePath 返回 DSLWorkflow 的集合路径；namespace 为空表示所有命名空间
+func resourcePath(namespace string) string {
+	if namespace == "" {
+		return "/apis/" + apiGroupVersion + "/" + resourcePlural
+	}
+	return "/apis/" + apiGroupVersion + "/namespaces/" + url.PathEscape(namespace) + "/" + resourcePlural
+}
+
+func objectPath(obj *DSLWorkflow) string {
+	return resourcePath(obj.Metadata.Namespace) + "/" + url.PathEscape(obj.Metadata.Name)
+}
+
+func (k *kubeClient) request(ctx context.Context, method, path, contentType string, body any) (*http.Response, error) {
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, k.server+path, rd)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if k.tokenFile != "" {
+		token, err := os.ReadFile(k.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("read kubernetes token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	resp, err := k.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		ae := &apiError{Code: resp.StatusCode}
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(b, ae) != nil || ae.Message == "" {
+			ae.Message = strings.TrimSpace(string(b))
+		}
+		ae.Code = resp.StatusCode
+		return nil, ae
+	}
+	return resp, nil
+}
+
+// do 发送一次请求并把响应解码到 out（可为 nil）
+func (k *kubeClient) do(ctx context.Context, method, path, contentType string, body, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	resp, err := k.request(ctx, method, path, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// list 列出资源，返回的 resourceVersion 作为 watch 的起点
+func (k *kubeClient) list(ctx context.Context, namespace string) (*dslWorkflowList, error) {
+	var l dslWorkflowList
+	if err := k.do(ctx, http.MethodGet, resourcePath(namespace), "", nil, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// watchEvent 是 watch 流中的一个事件；ERROR 事件的 Object 是 Status
+type watchEvent struct {
+	Type   string          `json:"type"` // ADDED/MODIFIED/DELETED/BOOKMARK/ERROR
+	Object json.RawMessage `json:"object"`
+}
+
+// errWatchExpired 表示 resourceVersion 已过期（410 Gone），需要重新 list
+var errWatchExpired = errors.New("watch resource version expired")
+
+// watch 从 resourceVersion 起监听变化并逐个回调 fn；timeout 到期时服务端正常结束流，返回 nil
+func (k *kubeClient) watch(ctx context.Context, namespace, resourceVersion string, timeout time.Duration, fn func(typ string, obj *DSLWorkflow)) error {
+	q := url.Values{
+		"watch":               {"1"},
+		"resourceVersion":     {resourceVersion},
+		"timeoutSeconds":      {fmt.Sprint(int(timeout.Seconds()))},
+		"allowWatchBookmarks": {"true"},
+	}
+	resp, err := k.request(ctx, http.MethodGet, resourcePath(namespace)+"?"+q.Encode(), "", nil)
+	if err != nil {
+		if isStatus(err, http.StatusGone) {
+			return errWatchExpired
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var ev watchEvent
+		if err := dec.Decode(&ev); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if ev.Type == "ERROR" {
+			var ae apiError
+			_ = json.Unmarshal(ev.Object, &ae)
+			if ae.Code == http.StatusGone {
+				return errWatchExpired
+			}
+			return &ae
+		}
+		var obj DSLWorkflow
+		if err := json.Unmarshal(ev.Object, &obj); err != nil {
+			return fmt.Errorf("decode %s event: %w", ev.Type, err)
+		}
+		if ev.Type != "BOOKMARK" {
+			fn(ev.Type, &obj)
+		}
+	}
+}
+
+// jsonPatch 是一条 RFC 6902 操作；用 JSON Patch 而不是 merge patch，才能清除不再需要的字段
+type jsonPatch struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// updateStatus 整体替换 status 子资源
+func (k *kubeClient) updateStatus(ctx context.Context, obj *DSLWorkflow, status DSLWorkflowStatus) error {
+	patch := []jsonPatch{{Op: "add", Path: "/status", Value: status}}
+	return k.do(ctx, http.MethodPatch, objectPath(obj)+"/status", "application/json-patch+json", patch, nil)
+}
+
+// setFinalizers 替换 finalizers；附带 resourceVersion 校验，避免覆盖他人并发写入的 finalizer
+func (k *kubeClient) setFinalizers(ctx context.Context, obj *DSLWorkflow, finalizers []string) error {
+	if finalizers == nil {
+		finalizers = []string{}
+	}
+	patch := []jsonPatch{
+		{Op: "test", Path: "/metadata/resourceVersion", Value: obj.Metadata.ResourceVersion},
+		{Op: "add", Path: "/metadata/finalizers", Value: finalizers},
+	}
+	return k.do(ctx, http.MethodPatch, objectPath(obj), "application/json-patch+json", patch, nil)
+}
@@ -0,0 +1,96 @@
+// controller 监听 Kubernetes 中的 DSLWorkflow 资源（deploy/kubernetes/crd.yaml），校验内嵌的定义，
+// 按 spec 启动工作流或维护 Temporal Schedule，并把结果写回资源的 status，使工作流定义可以由 GitOps 管理
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/temporalio/samples-go/dsl2/cmd/internal/conn"
+)
+
+// retryDelay 是 list/watch 失败后的等待时间
+const retryDelay = 5 * time.Second
+
+func main() {
+	if err := conn.LoadProfile(os.Args[1:]); err != nil {
+		log.Fatalf("%v", err)
+	}
+	var (
+		connOpts  conn.Options
+		kubeCfg   kubeConfig
+		namespace string
+		taskQueue string
+		resync    time.Duration
+	)
+	connOpts.Register(flag.CommandLine)
+	flag.StringVar(&kubeCfg.Server, "kube-server", os.Getenv("KUBE_SERVER"), "Kubernetes API server URL, e.g. http://127.0.0.1:8001 via kubectl proxy (default: in-cluster config)")
+	flag.StringVar(&kubeCfg.CAFile, "kube-ca", "", "CA certificate (PEM) for -kube-server")
+	flag.StringVar(&kubeCfg.TokenFile, "kube-token-file", "", "Bearer token file for -kube-server")
+	flag.StringVar(&namespace, "namespace", os.Getenv("WATCH_NAMESPACE"), "Kubernetes namespace to watch (env WATCH_NAMESPACE, default all namespaces)")
+	flag.StringVar(&taskQueue, "q", os.Getenv("TASK_QUEUE"), "Task queue for definitions that set neither spec.taskQueue nor taskQueue (default the profile's taskQueue, then demo)")
+	flag.DurationVar(&resync, "resync", 30*time.Second, "Interval to re-list resources and refresh running executions")
+	flag.Parse()
+	if err := conn.ApplyDefaults(flag.CommandLine); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if resync < time.Second {
+		log.Fatalf("-resync must be at least 1s")
+	}
+
+	kube, err := newKubeClient(kubeCfg)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	c, err := connOpts.Dial()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	defer c.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	r := &reconciler{kube: kube, temporal: c, taskQueue: taskQueue}
+	scope := namespace
+	if scope == "" {
+		scope = "all namespaces"
+	}
+	log.Printf("Controller started (kubernetes=%s, watching %s, temporal=%s/%s)", kube.server, scope, connOpts.HostPort, connOpts.Namespace)
+	for ctx.Err() == nil {
+		if err := r.sync(ctx, namespace, resync); err != nil && ctx.Err() == nil {
+			log.Printf("sync: %v", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryDelay):
+			}
+		}
+	}
+	log.Printf("Controller stopped")
+}
+
+// sync 列出全部资源逐个处理，再从 list 的 resourceVersion 起 watch 到 resync 到期；
+// 返回后由调用方重新 list，即定期全量同步（刷新运行中执行的状态、重试 Error）
+func (r *reconciler) sync(ctx context.Context, namespace string, resync time.Duration) error {
+	list, err := r.kube.list(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		r.reconcile(ctx, &list.Items[i])
+	}
+	err = r.kube.watch(ctx, namespace, list.Metadata.ResourceVersion, resync, func(typ string, obj *DSLWorkflow) {
+		if typ == "ADDED" || typ == "MODIFIED" {
+			r.reconcile(ctx, obj)
+		}
+	})
+	if errors.Is(err, errWatchExpired) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/cmd/internal/conn"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+// DSLWorkflow 是 CRD（deploy/kubernetes/crd.yaml）的对象；spec.definition 内嵌工作流 YAML
+type DSLWorkflow struct {
+	Metadata objectMeta        `json:"metadata"`
+	Spec     DSLWorkflowSpec   `json:"spec"`
+	Status   DSLWorkflowStatus `json:"status"`
+}
+
+type objectMeta struct {
+	Name              string     `json:"name"`
+	Namespace         string     `json:"namespace"`
+	ResourceVersion   string     `json:"resourceVersion"`
+	Generation        int64      `json:"generation"`
+	DeletionTimestamp *time.Time `json:"deletionTimestamp,omitempty"`
+	Finalizers        []string   `json:"finalizers,omitempty"`
+}
+
+type dslWorkflowList struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Items []DSLWorkflow `json:"items"`
+}
+
+// DSLWorkflowSpec 描述要运行的定义；有 schedule 时维护一个 Temporal Schedule，否则每个 generation 启动一次
+type DSLWorkflowSpec struct {
+	Definition string         `json:"definition"`
+	WorkflowID string         `json:"workflowId,omitempty"` // 默认 <namespace>-<name>
+	TaskQueue  string         `json:"taskQueue,omitempty"`
+	Variables  map[string]any `json:"variables,omitempty"`
+	Schedule   *ScheduleSpec  `json:"schedule,omitempty"`
+}
+
+// ScheduleSpec 对应 `starter schedule create` 的 -cron/-every/-overlap/-paused
+type ScheduleSpec struct {
+	Cron    []string `json:"cron,omitempty"`
+	Every   string   `json:"every,omitempty"` // Go duration，如 1h
+	Overlap string   `json:"overlap,omitempty"`
+	Paused  bool     `json:"paused,omitempty"`
+}
+
+// DSLWorkflowStatus 由控制器写回；ObservedGeneration 等于 metadata.generation 表示当前 spec 已处理
+type DSLWorkflowStatus struct {
+	ObservedGeneration int64      `json:"observedGeneration,omitempty"`
+	Phase              string     `json:"phase,omitempty"`
+	Message            string     `json:"message,omitempty"`
+	Issues             []string   `json:"issues,omitempty"`
+	WorkflowID         string     `json:"workflowId,omitempty"`
+	RunID              string     `json:"runId,omitempty"`
+	ScheduleID         string     `json:"scheduleId,omitempty"`
+	StartedAt          *time.Time `json:"startedAt,omitempty"`
+}
+
+// status.phase 的取值；执行结束后的取值与 Temporal 的执行状态同名
+const (
+	PhaseInvalid   = "Invalid"   // 定义无法解析或有 error 级问题，未提交
+	PhaseError     = "Error"     // 提交到 Temporal 失败，下次同步时重试
+	PhaseRunning   = "Running"   // 已启动，同步时刷新执行状态
+	PhaseScheduled = "Scheduled" // Schedule 已创建或更新
+)
+
+// scheduleFinalizer 保证删除 CR 时先删除对应的 Temporal Schedule
+const scheduleFinalizer = "dsl.temporal.io/schedule"
+
+var overlapPolicies = map[string]enumspb.ScheduleOverlapPolicy{
+	"Skip":           enumspb.SCHEDULE_OVERLAP_POLICY_SKIP,
+	"BufferOne":      enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE,
+	"BufferAll":      enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ALL,
+	"CancelOther":    enumspb.SCHEDULE_OVERLAP_POLICY_CANCEL_OTHER,
+	"TerminateOther": enumspb.SCHEDULE_OVERLAP_POLICY_TERMINATE_OTHER,
+	"AllowAll":       enumspb.SCHEDULE_OVERLAP_POLICY_ALLOW_ALL,
+}
+
+// executionPhases 把 Temporal 执行状态映射为 phase
+var executionPhases = map[enumspb.WorkflowExecutionStatus]string{
+	enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING:    PhaseRunning,
+	enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED:  "Completed",
+	enumspb.WORKFLOW_EXECUTION_STATUS_FAILED:     "Failed",
+	enumspb.WORKFLOW_EXECUTION_STATUS_CANCELED:   "Canceled",
+	enumspb.WORKFLOW_EXECUTION_STATUS_TERMINATED: "Terminated",
+	enumspb.WORKFLOW_EXECUTION_STATUS_TIMED_OUT:  "TimedOut",
+}
+
+type reconciler struct {
+	kube      *kubeClient
+	temporal  client.Client
+	taskQueue string // spec 与定义都未指定时使用；为空时使用 Profile 的 taskQueue，最后是 demo
+}
+
+// reconcile 让 Temporal 与一个 CR 保持一致并写回 status；错误记录在 status 中，不向上返回
+func (r *reconciler) reconcile(ctx context.Context, obj *DSLWorkflow) {
+	key := obj.Metadata.Namespace + "/" + obj.Metadata.Name
+	if obj.Metadata.DeletionTimestamp != nil {
+		if err := r.finalize(ctx, obj); err != nil {
+			log.Printf("%s: finalize: %v", key, err)
+		}
+		return
+	}
+
+	status := obj.Status
+	switch {
+	case status.ObservedGeneration != obj.Metadata.Generation, status.Phase == PhaseError:
+		status = r.apply(ctx, obj)
+	case status.Phase == PhaseRunning:
+		status = r.refresh(ctx, status)
+	}
+	if sameStatus(status, obj.Status) {
+		return
+	}
+	if status.Phase != obj.Status.Phase {
+		log.Printf("%s: %s %s", key, status.Phase, status.Message)
+	}
+	if err := r.kube.updateStatus(ctx, obj, status); err != nil {
+		log.Printf("%s: update status: %v", key, err)
+	}
+}
+
+// sameStatus 按序列化结果比较，读回的时间与本地生成的时间表示不同但值相同
+func sameStatus(a, b DSLWorkflowStatus) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return bytes.Equal(x, y)
+}
+
+// apply 校验当前 spec 并提交：创建/更新 Schedule，或启动本 generation 的执行
+func (r *reconciler) apply(ctx context.Context, obj *DSLWorkflow) DSLWorkflowStatus {
+	status := DSLWorkflowStatus{ObservedGeneration: obj.Metadata.Generation}
+	wf, err := r.definition(obj.Spec)
+	if err != nil {
+		status.Phase, status.Message = PhaseInvalid, err.Error()
+		return status
+	}
+	issues := wf.Check(dsl.CheckOptions{})
+	if dsl.HasErrors(issues) {
+		status.Phase = PhaseInvalid
+		status.Message = fmt.Sprintf("definition has %d issue(s)", len(issues))
+		for _, i := range issues {
+			status.Issues = append(status.Issues, fmt.Sprintf("%s: %s", i.Severity, i))
+		}
+		return status
+	}
+
+	base := obj.Spec.WorkflowID
+	if base == "" {
+		base = obj.Metadata.Namespace + "-" + obj.Metadata.Name
+	}
+	// 换了 Schedule ID 或改为一次性执行时，删掉旧的 Schedule
+	if old := obj.Status.ScheduleID; old != "" && (obj.Spec.Schedule == nil || old != base) {
+		if err := r.deleteSchedule(ctx, old); err != nil {
+			status.Phase, status.Message = PhaseError, err.Error()
+			status.ScheduleID = old
+			return status
+		}
+	}
+
+	if obj.Spec.Schedule != nil {
+		status.ScheduleID = base
+		if err := r.ensureFinalizer(ctx, obj); err != nil {
+			status.Phase, status.Message = PhaseError, err.Error()
+			return status
+		}
+		action, err := r.saveSchedule(ctx, base, wf, obj.Spec.Schedule)
+		if err != nil {
+			status.Phase, status.Message = PhaseError, err.Error()
+			return status
+		}
+		status.Phase, status.Message = PhaseScheduled, "schedule "+action
+		return status
+	}
+
+	// 每个 generation 使用固定的 ID：控制器重启或重复同步都不会再启动一次
+	status.WorkflowID = fmt.Sprintf("%s-%d", base, obj.Metadata.Generation)
+	run, err := r.temporal.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:                       status.WorkflowID,
+		TaskQueue:                wf.TaskQueue,
+		WorkflowIDReusePolicy:    enumspb.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+		StartDelay:               time.Duration(wf.StartDelaySec) * time.Second,
+		WorkflowExecutionTimeout: time.Duration(wf.ExecutionTimeoutSec) * time.Second,
+		WorkflowRunTimeout:       time.Duration(wf.RunTimeoutSec) * time.Second,
+	}, dsl.SimpleDSLWorkflow, wf)
+	var started *serviceerror.WorkflowExecutionAlreadyStarted
+	switch {
+	case errors.As(err, &started):
+		status.Phase = PhaseRunning
+		return r.refresh(ctx, status)
+	case err != nil:
+		status.Phase, status.Message = PhaseError, err.Error()
+		return status
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	status.Phase, status.RunID, status.StartedAt = PhaseRunning, run.GetRunID(), &now
+	return status
+}
+
+// definition 解析内嵌 YAML 并叠加 spec 中的变量与任务队列，规则与 starter 相同
+func (r *reconciler) definition(spec DSLWorkflowSpec) (dsl.Workflow, error) {
+	if strings.TrimSpace(spec.Definition) == "" {
+		return dsl.Workflow{}, errors.New("spec.definition is empty")
+	}
+	wf, err := dsl.ParseYAML([]byte(spec.Definition))
+	if err != nil {
+		return dsl.Workflow{}, fmt.Errorf("parse definition: %w", err)
+	}
+	wf = wf.WithVariables(spec.Variables)
+	wf.TaskQueue = conn.ResolveTaskQueue(spec.TaskQueue, wf.TaskQueue, r.taskQueue)
+	return wf, nil
+}
+
+// refresh 读取执行的最新状态；continue-as-new 之后跟随到最新的 run
+func (r *reconciler) refresh(ctx context.Context, status DSLWorkflowStatus) DSLWorkflowStatus {
+	desc, err := r.temporal.DescribeWorkflowExecution(ctx, status.WorkflowID, "")
+	if err != nil {
+		status.Message = err.Error()
+		return status
+	}
+	info := desc.GetWorkflowExecutionInfo()
+	phase, ok := executionPhases[info.GetStatus()]
+	if !ok {
+		phase = PhaseRunning
+	}
+	start := info.GetStartTime().AsTime().UTC()
+	status.Phase, status.Message = phase, ""
+	status.RunID, status.StartedAt = info.GetExecution().GetRunId(), &start
+	return status
+}
+
+func (r *reconciler) saveSchedule(ctx context.Context, id string, wf dsl.Workflow, s *ScheduleSpec) (string, error) {
+	spec := client.ScheduleSpec{CronExpressions: s.Cron}
+	if s.Every != "" {
+		every, err := time.ParseDuration(s.Every)
+		if err != nil || every <= 0 {
+			return "", fmt.Errorf("schedule.every %q is not a positive duration", s.Every)
+		}
+		spec.Intervals = []client.ScheduleIntervalSpec{{Every: every}}
+	}
+	if len(spec.CronExpressions) == 0 && len(spec.Intervals) == 0 {
+		return "", errors.New("schedule needs cron or every")
+	}
+	overlap := enumspb.SCHEDULE_OVERLAP_POLICY_UNSPECIFIED
+	if s.Overlap != "" {
+		p, ok := overlapPolicies[s.Overlap]
+		if !ok {
+			names := make([]string, 0, len(overlapPolicies))
+			for n := range overlapPolicies {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return "", fmt.Errorf("unknown schedule.overlap %q (want %s)", s.Overlap, strings.Join(names, ", "))
+		}
+		overlap = p
+	}
+	action := &client.ScheduleWorkflowAction{
+		ID:                       id,
+		Workflow:                 dsl.SimpleDSLWorkflow,
+		Args:                     []any{wf},
+		TaskQueue:                wf.TaskQueue,
+		WorkflowExecutionTimeout: time.Duration(wf.ExecutionTimeoutSec) * time.Second,
+		WorkflowRunTimeout:       time.Duration(wf.RunTimeoutSec) * time.Second,
+	}
+
+	_, err := r.temporal.ScheduleClient().Create(ctx, client.ScheduleOptions{
+		ID:      id,
+		Spec:    spec,
+		Action:  action,
+		Overlap: overlap,
+		Paused:  s.Paused,
+		Note:    "managed by dsl controller",
+	})
+	if !errors.Is(err, temporal.ErrScheduleAlreadyRunning) {
+		return "created", err
+	}
+	h := r.temporal.ScheduleClient().GetHandle(ctx, id)
+	err = h.Update(ctx, client.ScheduleUpdateOptions{
+		DoUpdate: func(in client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+			sched := in.Description.Schedule
+			sched.Spec, sched.Action = &spec, action
+			if sched.Policy != nil {
+				sched.Policy.Overlap = overlap
+			}
+			return &client.ScheduleUpdate{Schedule: &sched}, nil
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	// 暂停状态由 spec 决定；Update 不修改 State，单独同步
+	desc, err := h.Describe(ctx)
+	if err != nil {
+		return "", err
+	}
+	if desc.Schedule.State == nil {
+		return "updated", nil
+	}
+	switch paused := desc.Schedule.State.Paused; {
+	case s.Paused && !paused:
+		err = h.Pause(ctx, client.SchedulePauseOptions{Note: "paused by dsl controller"})
+	case !s.Paused && paused:
+		err = h.Unpause(ctx, client.ScheduleUnpauseOptions{Note: "unpaused by dsl controller"})
+	}
+	return "updated", err
+}
+
+func (r *reconciler) deleteSchedule(ctx context.Context, id string) error {
+	err := r.temporal.ScheduleClient().GetHandle(ctx, id).Delete(ctx)
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		return nil
+	}
+	return err
+}
+
+func (r *reconciler) ensureFinalizer(ctx context.Context, obj *DSLWorkflow) error {
+	if slices.Contains(obj.Metadata.Finalizers, scheduleFinalizer) {
+		return nil
+	}
+	return r.kube.setFinalizers(ctx, obj, append(slices.Clone(obj.Metadata.Finalizers), scheduleFinalizer))
+}
+
+// finalize 在 CR 删除时删除其 Schedule 并移除 finalizer；一次性执行不受影响，继续运行到结束
+func (r *reconciler) finalize(ctx context.Context, obj *DSLWorkflow) error {
+	if !slices.Contains(obj.Metadata.Finalizers, scheduleFinalizer) {
+		return nil
+	}
+	if id := obj.Status.ScheduleID; id != "" {
+		if err := r.deleteSchedule(ctx, id); err != nil {
+			return err
+		}
+	}
+	rest := slices.DeleteFunc(slices.Clone(obj.Metadata.Finalizers), func(f string) bool { return f == scheduleFinalizer })
+	return r.kube.setFinalizers(ctx, obj, rest)
+}
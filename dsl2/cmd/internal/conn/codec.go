@@ -0,0 +1,166 @@
+package conn
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+// 与仓库 encryption 示例相同的元数据，便于复用其 codec server 的约定
+const (
+	metadataEncodingEncrypted = "binary/encrypted"
+	metadataEncryptionKeyID   = "encryption-key-id"
+)
+
+// CodecConfig 配置载荷编码：远程 codec server，或本地 AES-GCM 加密（二选一），可再叠加压缩与大载荷转存。
+// 参数、变量与结果在 Temporal 中均以编码后的形式保存；starter、worker 与 web UI 必须使用相同配置
+type CodecConfig struct {
+	CodecEndpoint     string `yaml:"codecEndpoint,omitempty"`
+	EncryptionKeyFile string `yaml:"encryptionKeyFile,omitempty"` // 16/24/32 字节密钥：原始字节、hex 或 base64
+	EncryptionKeyID   string `yaml:"encryptionKeyId,omitempty"`   // 写入载荷元数据，默认 "default"
+	// Compression 为 gzip / zstd：在加密（或远程 codec）之前压缩，减少大的变量与 Map 收集结果占用的历史空间
+	Compression string `yaml:"compression,omitempty"`
+	// ClaimCheckStore 为 file:///dir、s3://bucket/prefix 或 gcs://bucket/prefix：超过 ClaimCheckThreshold 字节
+	// （默认 128KB）的载荷存入其中，历史中只保留引用。所有客户端都须能读写该存储
+	ClaimCheckStore     string `yaml:"claimCheckStore,omitempty"`
+	ClaimCheckThreshold int    `yaml:"claimCheckThreshold,omitempty"`
+}
+
+// DataConverter 返回带 codec 的转换器；未配置时返回 nil（使用 SDK 默认值）
+func (c CodecConfig) DataConverter() (converter.DataConverter, error) {
+	// 编码时从后往前应用：先压缩，再加密或交给远程 codec，最后转存大载荷（对象存储中也是密文）
+	var codecs []converter.PayloadCodec
+	if c.ClaimCheckStore != "" {
+		codec, err := newClaimCheckCodec(c.ClaimCheckStore, c.ClaimCheckThreshold)
+		if err != nil {
+			return nil, err
+		}
+		codecs = append(codecs, codec)
+	}
+	switch {
+	case c.CodecEndpoint != "" && c.EncryptionKeyFile != "":
+		return nil, errors.New("codec endpoint and encryption key are mutually exclusive")
+	case c.CodecEndpoint != "":
+		codecs = append(codecs, converter.NewRemotePayloadCodec(converter.RemotePayloadCodecOptions{Endpoint: c.CodecEndpoint}))
+	case c.EncryptionKeyFile != "":
+		key, err := loadKey(c.EncryptionKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keyID := c.EncryptionKeyID
+		if keyID == "" {
+			keyID = "default"
+		}
+		codecs = append(codecs, &aesCodec{keyID: keyID, key: key})
+	}
+	if c.Compression != "" {
+		codec, err := newCompressCodec(c.Compression)
+		if err != nil {
+			return nil, err
+		}
+		codecs = append(codecs, codec)
+	}
+	if len(codecs) == 0 {
+		return nil, nil
+	}
+	return converter.NewCodecDataConverter(converter.GetDefaultDataConverter(), codecs...), nil
+}
+
+func loadKey(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read encryption key: %w", err)
+	}
+	valid := func(k []byte) bool { return len(k) == 16 || len(k) == 24 || len(k) == 32 }
+	text := string(bytes.TrimSpace(b))
+	if k, err := hex.DecodeString(text); err == nil && valid(k) {
+		return k, nil
+	}
+	if k, err := base64.StdEncoding.DecodeString(text); err == nil && valid(k) {
+		return k, nil
+	}
+	if valid(b) {
+		return b, nil
+	}
+	return nil, fmt.Errorf("encryption key in %s must be 16, 24 or 32 bytes (raw, hex or base64)", path)
+}
+
+// aesCodec 用 AES-GCM 加密整个载荷；解密时只处理带有本 codec 标记的载荷
+type aesCodec struct {
+	keyID string
+	key   []byte
+}
+
+func (e *aesCodec) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (e *aesCodec) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	gcm, err := e.gcm()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*commonpb.Payload, len(payloads))
+	for i, p := range payloads {
+		plain, err := p.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, err
+		}
+		out[i] = &commonpb.Payload{
+			Metadata: map[string][]byte{
+				converter.MetadataEncoding: []byte(metadataEncodingEncrypted),
+				metadataEncryptionKeyID:    []byte(e.keyID),
+			},
+			Data: gcm.Seal(nonce, nonce, plain, nil),
+		}
+	}
+	return out, nil
+}
+
+func (e *aesCodec) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	gcm, err := e.gcm()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*commonpb.Payload, len(payloads))
+	for i, p := range payloads {
+		if string(p.Metadata[converter.MetadataEncoding]) != metadataEncodingEncrypted {
+			out[i] = p
+			continue
+		}
+		if id := string(p.Metadata[metadataEncryptionKeyID]); id != e.keyID {
+			return nil, fmt.Errorf("payload encrypted with key %q, have %q", id, e.keyID)
+		}
+		n := gcm.NonceSize()
+		if len(p.Data) < n {
+			return nil, errors.New("encrypted payload too short")
+		}
+		plain, err := gcm.Open(nil, p.Data[:n], p.Data[n:], nil)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = &commonpb.Payload{}
+		if err := out[i].Unmarshal(plain); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
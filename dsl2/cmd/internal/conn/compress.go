@@ -0,0 +1,116 @@
+package conn
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+// 压缩后载荷的编码标记；解码按标记处理，与当前配置的算法无关，切换算法不影响已有历史
+const (
+	metadataEncodingGzip = "binary/gzip"
+	metadataEncodingZstd = "binary/zstd"
+)
+
+// compressCodec 压缩整个载荷（含元数据）；压缩后不变小的载荷原样保留
+type compressCodec struct {
+	algorithm string // gzip / zstd
+}
+
+func newCompressCodec(algorithm string) (*compressCodec, error) {
+	switch algorithm {
+	case "gzip", "zstd":
+		return &compressCodec{algorithm: algorithm}, nil
+	}
+	return nil, fmt.Errorf("compression %q must be gzip or zstd", algorithm)
+}
+
+// zstd 的编解码器可并发复用，按需创建一次
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func zstdCodecs() (*zstd.Encoder, *zstd.Decoder) {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil)
+		zstdDecoder, _ = zstd.NewReader(nil)
+	})
+	return zstdEncoder, zstdDecoder
+}
+
+func (c *compressCodec) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	out := make([]*commonpb.Payload, len(payloads))
+	for i, p := range payloads {
+		plain, err := p.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		var (
+			packed   []byte
+			encoding string
+		)
+		switch c.algorithm {
+		case "zstd":
+			enc, _ := zstdCodecs()
+			packed, encoding = enc.EncodeAll(plain, nil), metadataEncodingZstd
+		default:
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			if _, err := zw.Write(plain); err != nil {
+				return nil, err
+			}
+			if err := zw.Close(); err != nil {
+				return nil, err
+			}
+			packed, encoding = buf.Bytes(), metadataEncodingGzip
+		}
+		if len(packed) >= len(plain) {
+			out[i] = p
+			continue
+		}
+		out[i] = &commonpb.Payload{
+			Metadata: map[string][]byte{converter.MetadataEncoding: []byte(encoding)},
+			Data:     packed,
+		}
+	}
+	return out, nil
+}
+
+func (c *compressCodec) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	out := make([]*commonpb.Payload, len(payloads))
+	for i, p := range payloads {
+		var (
+			plain []byte
+			err   error
+		)
+		switch string(p.Metadata[converter.MetadataEncoding]) {
+		case metadataEncodingZstd:
+			_, dec := zstdCodecs()
+			plain, err = dec.DecodeAll(p.Data, nil)
+		case metadataEncodingGzip:
+			var zr *gzip.Reader
+			if zr, err = gzip.NewReader(bytes.NewReader(p.Data)); err == nil {
+				plain, err = io.ReadAll(zr)
+			}
+		default:
+			out[i] = p
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decompress payload: %w", err)
+		}
+		out[i] = &commonpb.Payload{}
+		if err := out[i].Unmarshal(plain); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
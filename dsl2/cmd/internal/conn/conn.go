@@ -0,0 +1,195 @@
+// Package conn 汇总 starter、worker 与 web UI 连接 Temporal 的参数（地址、命名空间、TLS/mTLS、API Key、载荷编码），
+// 命令行参数与环境变量同名于 temporal CLI（TEMPORAL_TLS_CA 等），未设置时取配置文件（~/.dslrc.yaml）中所选 Profile 的值
+package conn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/temporalio/samples-go/dsl2/chaos"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+// TLSConfig 是连接 Temporal 的 TLS/mTLS 配置（文件路径）
+type TLSConfig struct {
+	CAFile     string `yaml:"caFile,omitempty"`
+	CertFile   string `yaml:"certFile,omitempty"`
+	KeyFile    string `yaml:"keyFile,omitempty"`
+	ServerName string `yaml:"serverName,omitempty"`
+}
+
+// Load 读取证书文件；未指定 CA 时使用系统根证书
+func (c *TLSConfig) Load() (*tls.Config, error) {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, errors.New("tls: cert and key must be set together")
+	}
+	cfg := &tls.Config{ServerName: c.ServerName}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client cert: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// Options 是一个 Temporal 连接的全部参数
+type Options struct {
+	HostPort  string
+	Namespace string
+	TLS       bool // 使用 TLS；设置任一 TLS 文件或 API Key 时自动启用
+	TLSConfig
+	APIKey string
+	CodecConfig
+}
+
+// FromEnv 从环境变量读取参数（worker 以环境变量配置）；未设置的环境变量取当前 Profile 的值
+func FromEnv() Options {
+	p := active
+	codec := CodecConfig{}
+	if p.Codec != nil {
+		codec = *p.Codec
+	}
+	return Options{
+		HostPort:  envOr("TEMPORAL_HOSTPORT", or(p.Host, "localhost:7233")),
+		Namespace: envOr("TEMPORAL_NAMESPACE", or(p.Namespace, "default")),
+		TLS:       envOr("TEMPORAL_TLS", fmt.Sprint(p.TLS)) == "true",
+		TLSConfig: TLSConfig{
+			CAFile:     envOr("TEMPORAL_TLS_CA", p.TLSConfig.CAFile),
+			CertFile:   envOr("TEMPORAL_TLS_CERT", p.TLSConfig.CertFile),
+			KeyFile:    envOr("TEMPORAL_TLS_KEY", p.TLSConfig.KeyFile),
+			ServerName: envOr("TEMPORAL_TLS_SERVER_NAME", p.TLSConfig.ServerName),
+		},
+		APIKey: envOr("TEMPORAL_API_KEY", p.APIKey),
+		CodecConfig: CodecConfig{
+			CodecEndpoint:       envOr("TEMPORAL_CODEC_ENDPOINT", codec.CodecEndpoint),
+			EncryptionKeyFile:   envOr("DSL_ENCRYPTION_KEY_FILE", codec.EncryptionKeyFile),
+			EncryptionKeyID:     envOr("DSL_ENCRYPTION_KEY_ID", codec.EncryptionKeyID),
+			Compression:         envOr("DSL_COMPRESSION", codec.Compression),
+			ClaimCheckStore:     envOr("DSL_CLAIM_CHECK_STORE", codec.ClaimCheckStore),
+			ClaimCheckThreshold: envInt("DSL_CLAIM_CHECK_THRESHOLD", codec.ClaimCheckThreshold),
+		},
+	}
+}
+
+// ResolveTaskQueue 返回 queues 中第一个非空值（调用方按优先级排列，如显式的 -q、定义中的 taskQueue），
+// 都为空时使用当前 Profile 的 taskQueue，最后是 demo。Profile 只填补未指定的 taskQueue，不覆盖定义
+func ResolveTaskQueue(queues ...string) string {
+	for _, q := range queues {
+		if q != "" {
+			return q
+		}
+	}
+	return or(active.TaskQueue, "demo")
+}
+
+// TaskQueue 返回 TASK_QUEUE 环境变量，其次是当前 Profile 的 taskQueue；都未设置时返回 def
+func TaskQueue(def string) string {
+	return envOr("TASK_QUEUE", or(active.TaskQueue, def))
+}
+
+// Register 在 fs 上注册 -config/-profile、-host/-ns/-tls*/-api-key，默认值取自环境变量与当前 Profile
+func (o *Options) Register(fs *flag.FlagSet) {
+	RegisterProfileFlags(fs)
+	env := FromEnv()
+	fs.StringVar(&o.HostPort, "host", env.HostPort, "Temporal Host:Port")
+	fs.StringVar(&o.Namespace, "ns", env.Namespace, "Temporal Namespace")
+	fs.BoolVar(&o.TLS, "tls", env.TLS, "Connect over TLS (implied by any -tls-* flag or -api-key)")
+	fs.StringVar(&o.CAFile, "tls-ca", env.CAFile, "CA certificate (PEM) used to verify the Temporal server")
+	fs.StringVar(&o.CertFile, "tls-cert", env.CertFile, "Client certificate (PEM) for mTLS")
+	fs.StringVar(&o.KeyFile, "tls-key", env.KeyFile, "Client private key (PEM) for mTLS")
+	fs.StringVar(&o.ServerName, "tls-server-name", env.ServerName, "Override the TLS server name (SNI)")
+	fs.StringVar(&o.APIKey, "api-key", env.APIKey, "API key sent as a bearer token, e.g. for Temporal Cloud (env TEMPORAL_API_KEY)")
+	o.CodecConfig.Register(fs)
+}
+
+// Register 注册 -codec-endpoint/-encryption-key-file/-encryption-key-id/-compression/-claim-check-*，默认值取自环境变量
+func (c *CodecConfig) Register(fs *flag.FlagSet) {
+	env := FromEnv().CodecConfig
+	fs.StringVar(&c.CodecEndpoint, "codec-endpoint", env.CodecEndpoint, "Remote payload codec server URL (env TEMPORAL_CODEC_ENDPOINT)")
+	fs.StringVar(&c.EncryptionKeyFile, "encryption-key-file", env.EncryptionKeyFile, "AES key file for local payload encryption (env DSL_ENCRYPTION_KEY_FILE)")
+	fs.StringVar(&c.EncryptionKeyID, "encryption-key-id", env.EncryptionKeyID, "Key ID recorded in encrypted payloads (env DSL_ENCRYPTION_KEY_ID, default \"default\")")
+	fs.StringVar(&c.Compression, "compression", env.Compression, "Compress payloads with gzip or zstd (env DSL_COMPRESSION)")
+	fs.StringVar(&c.ClaimCheckStore, "claim-check-store", env.ClaimCheckStore, "Offload large payloads to this blob store: file:///dir, s3://bucket/prefix or gcs://bucket/prefix (env DSL_CLAIM_CHECK_STORE)")
+	fs.IntVar(&c.ClaimCheckThreshold, "claim-check-threshold", env.ClaimCheckThreshold, "Payloads larger than this many bytes are offloaded (env DSL_CLAIM_CHECK_THRESHOLD, default 131072)")
+}
+
+// UsesTLS 报告连接是否需要 TLS
+func (o Options) UsesTLS() bool {
+	return o.TLS || o.TLSConfig != (TLSConfig{}) || o.APIKey != ""
+}
+
+// ClientOptions 生成 client.Options；调用方可再设置 Identity 等字段
+func (o Options) ClientOptions() (client.Options, error) {
+	opts := client.Options{HostPort: o.HostPort, Namespace: o.Namespace}
+	if o.UsesTLS() {
+		tlsCfg, err := o.TLSConfig.Load()
+		if err != nil {
+			return client.Options{}, err
+		}
+		opts.ConnectionOptions.TLS = tlsCfg
+	}
+	if o.APIKey != "" {
+		opts.Credentials = client.NewAPIKeyStaticCredentials(o.APIKey)
+	}
+	dc, err := o.CodecConfig.DataConverter()
+	if err != nil {
+		return client.Options{}, err
+	}
+	opts.DataConverter = dc
+	// 把 starter -chaos 的规则从启动请求带到 Activity，见 chaos 包
+	opts.ContextPropagators = []workflow.ContextPropagator{chaos.Propagator()}
+	return opts, nil
+}
+
+// Dial 按 Options 连接 Temporal
+func (o Options) Dial() (client.Client, error) {
+	opts, err := o.ClientOptions()
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.Dial(opts)
+	if err != nil {
+		return nil, fmt.Errorf("client.Dial: %w", err)
+	}
+	return c, nil
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envInt(key string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return n
+	}
+	return def
+}
+
+func or(v, def string) string {
+	if v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,156 @@
+package conn
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile 是配置文件中的一组命名设置；优先级为 命令行参数 > 环境变量 > Profile > 内置默认值
+type Profile struct {
+	Host      string       `yaml:"host,omitempty"`
+	Namespace string       `yaml:"namespace,omitempty"`
+	TLS       bool         `yaml:"tls,omitempty"`
+	TLSConfig TLSConfig    `yaml:"tlsConfig,omitempty"`
+	APIKey    string       `yaml:"apiKey,omitempty"`
+	Codec     *CodecConfig `yaml:"codec,omitempty"`
+	TaskQueue string       `yaml:"taskQueue,omitempty"`
+	// Defaults 按参数名覆盖其余参数的默认值（如 timeout: 5m、output: json）；
+	// 各程序只应用自己认识的参数，命令行显式给出的参数不受影响
+	Defaults map[string]string `yaml:"defaults,omitempty"`
+}
+
+// Config 是 ~/.dslrc.yaml 的格式
+//
+//	current: dev
+//	profiles:
+//	  dev:
+//	    host: localhost:7233
+//	    taskQueue: demo
+//	  cloud:
+//	    host: my-ns.a1b2c.tmprl.cloud:7233
+//	    namespace: my-ns.a1b2c
+//	    apiKey: ...
+//	    defaults: { timeout: 10m }
+type Config struct {
+	Current  string             `yaml:"current,omitempty"` // 未指定 -profile / DSL_PROFILE 时使用的 Profile
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// active 是 LoadProfile 选中的 Profile，FromEnv 以它为底
+var active Profile
+
+// ActiveProfile 返回当前 Profile；未加载配置时为零值
+func ActiveProfile() Profile {
+	return active
+}
+
+// DefaultConfigPath 返回 ~/.dslrc.yaml
+func DefaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".dslrc.yaml")
+}
+
+// LoadProfile 在注册参数之前调用：从 args 中找出 -config/-profile（也可用 DSL_CONFIG/DSL_PROFILE），
+// 加载配置文件并选中 Profile，使 FromEnv 与各参数的默认值取自该 Profile。
+// 未显式指定时 ~/.dslrc.yaml 不存在不算错误
+func LoadProfile(args []string) error {
+	path, explicit := os.Getenv("DSL_CONFIG"), true
+	if v, ok := scanFlag(args, "config"); ok {
+		path = v
+	}
+	if path == "" {
+		path, explicit = DefaultConfigPath(), false
+	}
+	name := os.Getenv("DSL_PROFILE")
+	if v, ok := scanFlag(args, "profile"); ok {
+		name = v
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			if name != "" {
+				return fmt.Errorf("profile %q requested but %s does not exist", name, path)
+			}
+			return nil
+		}
+		return fmt.Errorf("read config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if name == "" {
+		name = cfg.Current
+	}
+	if name == "" {
+		if _, ok := cfg.Profiles["default"]; !ok {
+			return nil
+		}
+		name = "default"
+	}
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(cfg.Profiles))
+		for n := range cfg.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("profile %q not found in %s (have: %s)", name, path, strings.Join(names, ", "))
+	}
+	active = p
+	return nil
+}
+
+// scanFlag 在未解析的参数中查找 -name v / -name=v（也接受 --name）
+func scanFlag(args []string, name string) (string, bool) {
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+		a = strings.TrimPrefix(strings.TrimPrefix(a, "-"), "-")
+		if a == name && i+1 < len(args) {
+			return args[i+1], true
+		}
+		if v, ok := strings.CutPrefix(a, name+"="); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// RegisterProfileFlags 注册 -config/-profile，使参数解析接受它们；实际值已由 LoadProfile 读取
+func RegisterProfileFlags(fs *flag.FlagSet) {
+	fs.String("config", os.Getenv("DSL_CONFIG"), "Config file with named profiles (env DSL_CONFIG, default ~/.dslrc.yaml)")
+	fs.String("profile", os.Getenv("DSL_PROFILE"), "Profile to use from the config file (env DSL_PROFILE, default its current profile)")
+}
+
+// ApplyDefaults 在 fs.Parse 之后调用：把 Profile.Defaults 中 fs 认识且未在命令行给出的参数设为 Profile 的值
+func ApplyDefaults(fs *flag.FlagSet) error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	names := make([]string, 0, len(active.Defaults))
+	for name := range active.Defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if set[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, active.Defaults[name]); err != nil {
+			return fmt.Errorf("profile default %s: %w", name, err)
+		}
+	}
+	return nil
+}
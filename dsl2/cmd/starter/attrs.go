@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+// searchAttributes 把 -search-attr 的值映射为类型化的 Search Attribute：
+// 字符串→Keyword（RFC3339 时间→Datetime）、整数→Int、小数→Double、布尔→Bool、字符串列表→KeywordList。
+// 类型必须与命名空间中注册的一致，否则服务端会拒绝启动
+func searchAttributes(attrs map[string]any) (temporal.SearchAttributes, error) {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	updates := make([]temporal.SearchAttributeUpdate, 0, len(attrs))
+	for _, k := range keys {
+		switch v := attrs[k].(type) {
+		case string:
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				updates = append(updates, temporal.NewSearchAttributeKeyTime(k).ValueSet(t))
+			} else {
+				updates = append(updates, temporal.NewSearchAttributeKeyKeyword(k).ValueSet(v))
+			}
+		case time.Time: // YAML 会把未加引号的时间戳解析为 time.Time
+			updates = append(updates, temporal.NewSearchAttributeKeyTime(k).ValueSet(v))
+		case int:
+			updates = append(updates, temporal.NewSearchAttributeKeyInt64(k).ValueSet(int64(v)))
+		case float64:
+			updates = append(updates, temporal.NewSearchAttributeKeyFloat64(k).ValueSet(v))
+		case bool:
+			updates = append(updates, temporal.NewSearchAttributeKeyBool(k).ValueSet(v))
+		case []any:
+			list := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return temporal.SearchAttributes{}, fmt.Errorf("-search-attr %s: list items must be strings", k)
+				}
+				list = append(list, s)
+			}
+			updates = append(updates, temporal.NewSearchAttributeKeyKeywordList(k).ValueSet(list))
+		default:
+			return temporal.SearchAttributes{}, fmt.Errorf("-search-attr %s: unsupported value %v", k, v)
+		}
+	}
+	return temporal.NewSearchAttributes(updates...), nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/temporalio/samples-go/dsl2/simulate"
+	"go.temporal.io/sdk/client"
+)
+
+// BatchReport 是 -batch 的汇总结果；Runs 与匹配到的文件顺序一致
+type BatchReport struct {
+	Total      int           `json:"total"`
+	Completed  int           `json:"completed"`
+	Failed     int           `json:"failed"`
+	Running    int           `json:"running"`
+	DurationMs int64         `json:"durationMs"`
+	Runs       []BatchResult `json:"runs"`
+}
+
+// BatchResult 是批量中的一次执行；加载或启动失败时只有 File 与 Error
+type BatchResult struct {
+	File string `json:"file"`
+	StartResult
+}
+
+// batchFiles 展开 -batch 或 lint 的参数：目录取其中的 *.yaml/*.yml（跳过 *.test.yaml 测试文件），否则按 glob 匹配
+func batchFiles(pattern string) ([]string, error) {
+	var files []string
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		for _, ext := range []string{"*.yaml", "*.yml"} {
+			m, _ := filepath.Glob(filepath.Join(pattern, ext))
+			for _, f := range m {
+				if !strings.HasSuffix(f, simulate.TestFileSuffix) {
+					files = append(files, f)
+				}
+			}
+		}
+	} else {
+		m, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = m
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%q matched no files", pattern)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// runBatch 以最多 parallel 个并发启动（wait 为 true 时并等待）每个文件，打印汇总；有失败时返回非零退出码
+func runBatch(ctx context.Context, c client.Client, cfg startConfig, files []string, parallel int, wait bool) int {
+	begin := time.Now()
+	prefix := cfg.workflowID
+	if prefix == "" {
+		prefix = fmt.Sprintf("dsl-batch-%d", begin.Unix())
+	}
+
+	rep := BatchReport{Total: len(files), Runs: make([]BatchResult, len(files))}
+	sem := make(chan struct{}, max(parallel, 1))
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			rep.Runs[i] = startBatchOne(ctx, c, cfg, file, batchID(prefix, file), wait)
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range rep.Runs {
+		switch r.Status {
+		case "Completed":
+			rep.Completed++
+		case "Running":
+			rep.Running++
+		default:
+			rep.Failed++
+		}
+	}
+	rep.DurationMs = time.Since(begin).Milliseconds()
+	emit(rep, func() {
+		for _, r := range rep.Runs {
+			line := fmt.Sprintf("%-9s %s", r.Status, r.File)
+			if r.WorkflowID != "" {
+				line += "  " + r.WorkflowID
+			}
+			if r.DurationMs > 0 {
+				line += fmt.Sprintf("  %s", time.Duration(r.DurationMs)*time.Millisecond)
+			}
+			if r.Error != "" {
+				line += ": " + r.Error
+			}
+			fmt.Println(line)
+		}
+		fmt.Printf("%d total, %d completed, %d failed, %d running in %s\n",
+			rep.Total, rep.Completed, rep.Failed, rep.Running, time.Since(begin).Round(time.Millisecond))
+	})
+	if rep.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func startBatchOne(ctx context.Context, c client.Client, cfg startConfig, file, id string, wait bool) BatchResult {
+	failed := func(err error) BatchResult {
+		return BatchResult{File: file, StartResult: StartResult{Status: "Failed", Error: err.Error()}}
+	}
+	wf, err := cfg.load(file)
+	if err != nil {
+		return failed(err)
+	}
+	// 有 ID 模板时由各文件的变量渲染 ID，不使用批次 ID
+	if cfg.hasIDTemplate(wf) {
+		id = ""
+	}
+	res, run, err := cfg.start(ctx, c, wf, id)
+	if err != nil {
+		return failed(err)
+	}
+	if wait {
+		var out map[string]any
+		err := run.Get(ctx, &out)
+		res.finish(out, err)
+		// 汇总报告只保留状态，结果可通过 query/describe 获取
+		res.Result = nil
+	}
+	return BatchResult{File: file, StartResult: res}
+}
+
+// batchID 由前缀与文件名组成，同一批次内的 ID 可读且不重复
+func batchID(prefix, file string) string {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	return prefix + "-" + name
+}
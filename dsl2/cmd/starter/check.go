@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/simulate"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidateReport 是 -validate 的检查报告；-output json 时供 CI 解析
+type ValidateReport struct {
+	File   string      `json:"file"`
+	Valid  bool        `json:"valid"`
+	Issues []dsl.Issue `json:"issues"`
+}
+
+// checkWorkflow 执行完整的静态检查（含未知 Activity），任何 error 级问题都使 Valid 为 false
+func checkWorkflow(path string, wf dsl.Workflow) ValidateReport {
+	issues := wf.Check(dsl.CheckOptions{KnownActivities: dsl.ActivityNames()})
+	if issues == nil {
+		issues = []dsl.Issue{}
+	}
+	return ValidateReport{File: path, Valid: !dsl.HasErrors(issues), Issues: issues}
+}
+
+// runValidate 打印检查报告，定义无效时返回非零退出码
+func runValidate(path string, wf dsl.Workflow) int {
+	rep := checkWorkflow(path, wf)
+	emit(rep, func() {
+		for _, i := range rep.Issues {
+			fmt.Printf("%s: %s\n", i.Severity, i)
+		}
+		if rep.Valid {
+			fmt.Printf("%s: ok\n", path)
+		} else {
+			fmt.Printf("%s: invalid\n", path)
+		}
+	})
+	if !rep.Valid {
+		return 1
+	}
+	return 0
+}
+
+// runDryRun 先做静态检查，再在本地测试环境中以 mock Activity 执行，不连接 Temporal 集群
+func runDryRun(path string, wf dsl.Workflow, mocksPath string) int {
+	if rep := checkWorkflow(path, wf); !rep.Valid {
+		for _, i := range rep.Issues {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", i.Severity, i)
+		}
+		return 1
+	}
+	mocks, err := loadMocks(mocksPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	res := simulate.Run(wf, mocks)
+	emit(res, func() {
+		for _, ev := range res.Trace {
+			fmt.Println(formatTraceEvent(ev))
+		}
+		if !res.Success {
+			fmt.Printf("Dry run failed: %s\n", res.Error)
+			return
+		}
+		bs, _ := json.MarshalIndent(res.Result, "", "  ")
+		fmt.Printf("Result bindings:\n%s\n", bs)
+		if cov, err := simulate.NewCoverage(wf); err == nil {
+			cov.Add(res.Trace)
+			printCoverage(path, cov)
+		}
+	})
+	if !res.Success {
+		return 1
+	}
+	return 0
+}
+
+// loadMocks 读取 -mocks 文件：JSON 或 YAML 对象，键为 Activity 名，值同 web UI 的 Simulate mocks
+func loadMocks(path string) (map[string]simulate.Mock, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read mocks file: %w", err)
+	}
+	var mocks map[string]simulate.Mock
+	if err := yaml.Unmarshal(b, &mocks); err != nil {
+		return nil, fmt.Errorf("parse mocks file %s: %w", path, err)
+	}
+	return mocks, nil
+}
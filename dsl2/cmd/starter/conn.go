@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/temporalio/samples-go/dsl2/cmd/internal/conn"
+	"go.temporal.io/sdk/client"
+)
+
+// connFlags 是操作已有执行的子命令共用的连接与输出参数
+type connFlags struct {
+	conn.Options
+	output string
+}
+
+func addConnFlags(fs *flag.FlagSet) *connFlags {
+	cf := &connFlags{}
+	cf.Register(fs)
+	fs.StringVar(&cf.output, "output", outputText, "Output format: text/json/yaml")
+	return cf
+}
+
+// dial 应用 -output 并连接 Temporal；在 fs.Parse 之后调用
+func (cf *connFlags) dial() (client.Client, error) {
+	if err := parseOutputFormat(cf.output); err != nil {
+		return nil, err
+	}
+	return cf.Dial()
+}
+
+// parseFlags 解析子命令参数，并把 Profile 中的参数默认值应用到未显式给出的参数上
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if fs.Lookup("profile") == nil {
+		conn.RegisterProfileFlags(fs)
+	}
+	fs.Parse(args)
+	return conn.ApplyDefaults(fs)
+}
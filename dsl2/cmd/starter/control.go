@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+// ControlResult 是 cancel/terminate/pause/resume/step/continue 子命令的结构化输出
+type ControlResult struct {
+	WorkflowID string `json:"workflowId"`
+	RunID      string `json:"runId,omitempty"`
+	Action     string `json:"action"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// runCancel 实现 `starter cancel -id <wfid>`：请求取消，工作流可执行清理逻辑后结束
+func runCancel(args []string) error {
+	fs := flag.NewFlagSet("cancel", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	wfid := fs.String("id", "", "Workflow ID (required)")
+	runID := fs.String("run-id", "", "Run ID (optional, default latest run)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *wfid == "" {
+		return errors.New("-id is required")
+	}
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.CancelWorkflow(context.Background(), *wfid, *runID); err != nil {
+		return err
+	}
+	res := ControlResult{WorkflowID: *wfid, RunID: *runID, Action: "cancel"}
+	emit(res, func() { fmt.Printf("Requested cancellation of %s\n", res.WorkflowID) })
+	return nil
+}
+
+// runTerminate 实现 `starter terminate -id <wfid> [-reason ...]`：立即结束，不执行任何工作流代码
+func runTerminate(args []string) error {
+	fs := flag.NewFlagSet("terminate", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	wfid := fs.String("id", "", "Workflow ID (required)")
+	runID := fs.String("run-id", "", "Run ID (optional, default latest run)")
+	reason := fs.String("reason", "terminated from starter", "Termination reason recorded in history")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *wfid == "" {
+		return errors.New("-id is required")
+	}
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.TerminateWorkflow(context.Background(), *wfid, *runID, *reason); err != nil {
+		return err
+	}
+	res := ControlResult{WorkflowID: *wfid, RunID: *runID, Action: "terminate", Reason: *reason}
+	emit(res, func() { fmt.Printf("Terminated %s: %s\n", res.WorkflowID, res.Reason) })
+	return nil
+}
+
+// runPause 实现 `starter pause -id <wfid>`：引擎在节点边界停下，不再开始新节点，已在执行的节点继续完成
+func runPause(args []string) error {
+	return sendControlSignal("pause", dsl.SignalPause, args, "Paused %s; running nodes finish, no new nodes start until resume\n")
+}
+
+// runResume 实现 `starter resume -id <wfid>`：从暂停处继续
+func runResume(args []string) error {
+	return sendControlSignal("resume", dsl.SignalResume, args, "Resumed %s\n")
+}
+
+func sendControlSignal(action, signal string, args []string, format string) error {
+	fs := flag.NewFlagSet(action, flag.ExitOnError)
+	cf := addConnFlags(fs)
+	wfid := fs.String("id", "", "Workflow ID (required)")
+	runID := fs.String("run-id", "", "Run ID (optional, default latest run)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *wfid == "" {
+		return errors.New("-id is required")
+	}
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.SignalWorkflow(context.Background(), *wfid, *runID, signal, nil); err != nil {
+		return err
+	}
+	res := ControlResult{WorkflowID: *wfid, RunID: *runID, Action: action}
+	emit(res, func() { fmt.Printf(format, res.WorkflowID) })
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/cmd/internal/conn"
+	"github.com/temporalio/samples-go/dsl2/convert/argo"
+	"github.com/temporalio/samples-go/dsl2/convert/asl"
+	"github.com/temporalio/samples-go/dsl2/convert/dslv1"
+	"github.com/temporalio/samples-go/dsl2/convert/sw"
+)
+
+// converters 是 convert 子命令支持的外部格式；返回的 notes 为已格式化的说明
+var converters = map[string]func(data []byte, taskQueue string) (dsl.Workflow, []string, error){
+	"asl": func(data []byte, tq string) (dsl.Workflow, []string, error) {
+		wf, notes, err := asl.Convert(data, asl.Options{TaskQueue: tq})
+		return wf, noteStrings(notes), err
+	},
+	"serverlessworkflow": func(data []byte, tq string) (dsl.Workflow, []string, error) {
+		wf, notes, err := sw.Convert(data, sw.Options{TaskQueue: tq})
+		return wf, noteStrings(notes), err
+	},
+	"argo": func(data []byte, tq string) (dsl.Workflow, []string, error) {
+		wf, notes, err := argo.Convert(data, argo.Options{TaskQueue: tq})
+		return wf, noteStrings(notes), err
+	},
+	"dslv1": func(data []byte, tq string) (dsl.Workflow, []string, error) {
+		wf, notes, err := dslv1.Convert(data, dslv1.Options{TaskQueue: tq})
+		return wf, noteStrings(notes), err
+	},
+}
+
+func noteStrings[N fmt.Stringer](notes []N) []string {
+	out := make([]string, 0, len(notes))
+	for _, n := range notes {
+		out = append(out, n.String())
+	}
+	return out
+}
+
+// runConvert 实现 `starter convert -from asl|serverlessworkflow|argo|dslv1 input.json [-o out.yaml]`；
+// 无法映射的结构作为说明输出到 stderr，转换结果仍会写出以便手工修改
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	from := fs.String("from", "", "Source format: asl/serverlessworkflow/argo/dslv1 (required)")
+	out := fs.String("o", "", "Write the DSL YAML to this file instead of stdout")
+	taskQueue := fs.String("q", "", "Task queue of the generated workflow (default the profile's taskQueue, then demo)")
+	strict := fs.Bool("strict", false, "Exit non-zero when some constructs could not be converted")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	conv, ok := converters[*from]
+	if !ok {
+		return fmt.Errorf("unsupported -from %q (want asl, serverlessworkflow, argo or dslv1)", *from)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("exactly one input file (or - for stdin) is required")
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if path := fs.Arg(0); path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	wf, notes, err := conv(data, conn.ResolveTaskQueue(*taskQueue))
+	if err != nil {
+		return err
+	}
+	for _, n := range notes {
+		log.Printf("note: %s", n)
+	}
+	for _, i := range wf.Check(dsl.CheckOptions{KnownActivities: dsl.ActivityNames()}) {
+		log.Printf("%s: %s", i.Severity, i)
+	}
+	yml, err := dsl.MarshalYAML(wf)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		os.Stdout.Write(yml)
+	} else if err := os.WriteFile(*out, yml, 0o644); err != nil {
+		return err
+	}
+	if *strict && len(notes) > 0 {
+		return fmt.Errorf("%d construct(s) could not be converted", len(notes))
+	}
+	return nil
+}
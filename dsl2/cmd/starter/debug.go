@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"go.temporal.io/sdk/client"
+)
+
+// runDebug 实现 `starter debug -id <wfid> [-add second] [-remove root[2]] [-clear]`：
+// 先按参数增删断点，再打印调试状态（断点与停住的节点及其变量）
+func runDebug(args []string) error {
+	fs := flag.NewFlagSet("debug", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	wfid := fs.String("id", "", "Workflow ID (required)")
+	runID := fs.String("run-id", "", "Run ID (optional, default latest run)")
+	var add, remove stringList
+	fs.Var(&add, "add", "Add a breakpoint, a statement id or node path (repeatable)")
+	fs.Var(&remove, "remove", "Remove a breakpoint (repeatable)")
+	clearAll := fs.Bool("clear", false, "Remove every breakpoint before -add")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *wfid == "" {
+		return errors.New("-id is required")
+	}
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	if len(add) > 0 || len(remove) > 0 || *clearAll {
+		handle, err := c.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{
+			WorkflowID:   *wfid,
+			RunID:        *runID,
+			UpdateName:   dsl.UpdateBreakpoints,
+			Args:         []any{dsl.BreakpointsRequest{Add: add, Remove: remove, Clear: *clearAll}},
+			WaitForStage: client.WorkflowUpdateStageCompleted,
+		})
+		if err != nil {
+			return err
+		}
+		if err := handle.Get(ctx, nil); err != nil {
+			return err
+		}
+	}
+	v, err := c.QueryWorkflow(ctx, *wfid, *runID, dsl.QueryDebug)
+	if err != nil {
+		return err
+	}
+	var st dsl.DebugState
+	if err := v.Get(&st); err != nil {
+		return err
+	}
+	emit(st, func() {
+		bps := strings.Join(st.Breakpoints, ", ")
+		if bps == "" {
+			bps = "none"
+		}
+		fmt.Printf("Breakpoints: %s\n", bps)
+		if st.Stepping {
+			fmt.Println("Stepping:    halts before every node")
+		}
+		if len(st.Halted) == 0 {
+			fmt.Println("Halted:      none")
+		}
+		for _, h := range st.Halted {
+			name := h.Path
+			if h.ID != "" {
+				name = fmt.Sprintf("%s (%s)", h.ID, h.Path)
+			}
+			bs, _ := json.MarshalIndent(h.Bindings, "  ", "  ")
+			fmt.Printf("Halted:      %s [%s] on %s\n  %s\n", name, h.Kind, h.Reason, bs)
+		}
+	})
+	return nil
+}
+
+// runStep 实现 `starter step -id <wfid>`：放行最早停住的节点，并在下一个节点前再次停下
+func runStep(args []string) error {
+	return sendControlSignal("step", dsl.SignalStep, args, "Stepped %s; it halts again before the next node\n")
+}
+
+// runContinue 实现 `starter continue -id <wfid>`：放行全部停住的节点，运行到下一个断点
+func runContinue(args []string) error {
+	return sendControlSignal("continue", dsl.SignalContinue, args, "Continued %s to the next breakpoint\n")
+}
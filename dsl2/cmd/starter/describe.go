@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+// Description 是 describe 子命令的输出：执行状态 + 引擎进度 + 挂起的 Activity
+type Description struct {
+	WorkflowID        string            `json:"workflowId"`
+	RunID             string            `json:"runId"`
+	WorkflowType      string            `json:"workflowType"`
+	TaskQueue         string            `json:"taskQueue"`
+	Status            string            `json:"status"`
+	StartTime         *time.Time        `json:"startTime,omitempty"`
+	CloseTime         *time.Time        `json:"closeTime,omitempty"`
+	HistoryLength     int64             `json:"historyLength"`
+	Progress          *dsl.Progress     `json:"progress,omitempty"`
+	ProgressError     string            `json:"progressError,omitempty"` // 查询失败（如没有 worker）时的原因
+	PendingActivities []PendingActivity `json:"pendingActivities,omitempty"`
+}
+
+type PendingActivity struct {
+	ActivityID  string     `json:"activityId"`
+	Type        string     `json:"type"`
+	State       string     `json:"state"`
+	Attempt     int32      `json:"attempt"`
+	MaxAttempts int32      `json:"maxAttempts,omitempty"`
+	LastFailure string     `json:"lastFailure,omitempty"`
+	LastStarted *time.Time `json:"lastStarted,omitempty"`
+}
+
+// runDescribe 实现 `starter describe -id <wfid>`
+func runDescribe(args []string) error {
+	fs := flag.NewFlagSet("describe", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	wfid := fs.String("id", "", "Workflow ID (required)")
+	runID := fs.String("run-id", "", "Run ID (optional, default latest run)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *wfid == "" {
+		return errors.New("-id is required")
+	}
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	resp, err := c.DescribeWorkflowExecution(ctx, *wfid, *runID)
+	if err != nil {
+		return err
+	}
+	info := resp.GetWorkflowExecutionInfo()
+	d := Description{
+		WorkflowID:    info.GetExecution().GetWorkflowId(),
+		RunID:         info.GetExecution().GetRunId(),
+		WorkflowType:  info.GetType().GetName(),
+		TaskQueue:     info.GetTaskQueue(),
+		Status:        strings.TrimPrefix(info.GetStatus().String(), "WORKFLOW_EXECUTION_STATUS_"),
+		HistoryLength: info.GetHistoryLength(),
+	}
+	if t := info.GetStartTime(); t != nil {
+		start := t.AsTime()
+		d.StartTime = &start
+	}
+	if t := info.GetCloseTime(); t != nil {
+		closed := t.AsTime()
+		d.CloseTime = &closed
+	}
+	for _, pa := range resp.GetPendingActivities() {
+		p := PendingActivity{
+			ActivityID:  pa.GetActivityId(),
+			Type:        pa.GetActivityType().GetName(),
+			State:       strings.TrimPrefix(pa.GetState().String(), "PENDING_ACTIVITY_STATE_"),
+			Attempt:     pa.GetAttempt(),
+			MaxAttempts: pa.GetMaximumAttempts(),
+			LastFailure: pa.GetLastFailure().GetMessage(),
+		}
+		if t := pa.GetLastStartedTime(); t != nil {
+			started := t.AsTime()
+			p.LastStarted = &started
+		}
+		d.PendingActivities = append(d.PendingActivities, p)
+	}
+
+	// 进度来自引擎的查询，需要有 worker 在线；失败时仍输出服务端信息
+	if v, err := c.QueryWorkflow(ctx, d.WorkflowID, d.RunID, dsl.QueryProgress); err != nil {
+		d.ProgressError = err.Error()
+	} else {
+		var p dsl.Progress
+		if err := v.Get(&p); err != nil {
+			d.ProgressError = err.Error()
+		} else {
+			d.Progress = &p
+		}
+	}
+
+	emit(d, func() { printDescription(d) })
+	return nil
+}
+
+func printDescription(d Description) {
+	fmt.Printf("Workflow:  %s (run %s)\n", d.WorkflowID, d.RunID)
+	fmt.Printf("Type:      %s on %s\n", d.WorkflowType, d.TaskQueue)
+	fmt.Printf("Status:    %s\n", d.Status)
+	if d.StartTime != nil {
+		fmt.Printf("Started:   %s\n", d.StartTime.Local().Format(time.RFC3339))
+	}
+	if d.CloseTime != nil && d.StartTime != nil {
+		fmt.Printf("Closed:    %s (%s)\n", d.CloseTime.Local().Format(time.RFC3339), d.CloseTime.Sub(*d.StartTime).Round(time.Millisecond))
+	}
+	fmt.Printf("History:   %d events\n", d.HistoryLength)
+	switch {
+	case d.Progress != nil:
+		fmt.Printf("Nodes:     %d completed, %d failed, %d running\n", d.Progress.Completed, d.Progress.Failed, len(d.Progress.Running))
+		fmt.Printf("Progress:  %s\n", formatProgress(*d.Progress))
+		if d.Progress.Current != "" {
+			fmt.Printf("Current:   %s\n", d.Progress.Current)
+		}
+		if d.Progress.Paused {
+			fmt.Println("Paused:    no new nodes start until resume")
+		}
+	case d.ProgressError != "":
+		fmt.Printf("Nodes:     unavailable (%s)\n", d.ProgressError)
+	}
+	if len(d.PendingActivities) > 0 {
+		fmt.Println("Pending activities:")
+		for _, pa := range d.PendingActivities {
+			attempts := fmt.Sprintf("attempt %d", pa.Attempt)
+			if pa.MaxAttempts > 0 {
+				attempts += fmt.Sprintf("/%d", pa.MaxAttempts)
+			}
+			fmt.Printf("  %s %s [%s, %s]", pa.ActivityID, pa.Type, pa.State, attempts)
+			if pa.LastFailure != "" {
+				fmt.Printf(" last failure: %s", pa.LastFailure)
+			}
+			fmt.Println()
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"go.temporal.io/sdk/client"
+)
+
+// followPollInterval 是 -follow 查询 trace 的间隔
+const followPollInterval = time.Second
+
+// followRun 轮询 trace 查询，逐行打印节点状态（每批新事件后附一行进度条），直到工作流结束；返回 run.Get 的结果
+func followRun(ctx context.Context, c client.Client, run client.WorkflowRun, w io.Writer) (map[string]any, error) {
+	type result struct {
+		out map[string]any
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		r.err = run.Get(ctx, &r.out)
+		done <- r
+	}()
+
+	last := 0
+	flush := func() {
+		v, err := c.QueryWorkflow(ctx, run.GetID(), run.GetRunID(), dsl.QueryTrace)
+		if err != nil {
+			return // 尚未被 worker 处理的工作流无法查询，下一轮重试
+		}
+		var events []dsl.TraceEvent
+		if v.Get(&events) != nil {
+			return
+		}
+		prev := last
+		for _, ev := range events {
+			if ev.Seq > last {
+				fmt.Fprintln(w, formatTraceEvent(ev))
+				last = ev.Seq
+			}
+		}
+		if last == prev {
+			return
+		}
+		if v, err = c.QueryWorkflow(ctx, run.GetID(), run.GetRunID(), dsl.QueryProgress); err != nil {
+			return
+		}
+		var p dsl.Progress
+		if v.Get(&p) == nil {
+			fmt.Fprintln(w, formatProgress(p))
+		}
+	}
+
+	ticker := time.NewTicker(followPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case r := <-done:
+			// 已关闭的工作流仍可查询，补齐最后一轮事件
+			flush()
+			return r.out, r.err
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// formatTraceEvent 输出形如 "15:04:05.000 completed root[1].if (if) 120ms" 的一行
+func formatTraceEvent(ev dsl.TraceEvent) string {
+	line := fmt.Sprintf("%s %-9s %s (%s)", ev.Time.Local().Format("15:04:05.000"), ev.Status, ev.Path, ev.Kind)
+	if ev.ID != "" {
+		line += " #" + ev.ID
+	}
+	switch ev.Status {
+	case dsl.TraceStarted:
+	case dsl.TraceSkipped:
+		line += ": " + ev.Reason
+	default:
+		line += fmt.Sprintf(" %dms", ev.DurationMs)
+	}
+	if ev.Error != "" {
+		line += ": " + ev.Error
+	}
+	return line
+}
+
+// formatProgress 输出形如 "[##########----------] 50% 3/6 nodes, ETA 2m0s" 的进度条
+func formatProgress(p dsl.Progress) string {
+	const width = 20
+	filled := p.Percent * width / 100
+	line := fmt.Sprintf("[%s%s] %3d%% %d/%d nodes", strings.Repeat("#", filled), strings.Repeat("-", width-filled), p.Percent, p.Done, p.Total)
+	if p.ETASec > 0 {
+		line += fmt.Sprintf(", ETA %s", time.Duration(p.ETASec)*time.Second)
+	}
+	return line
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+// runGraph 实现 `starter graph -f workflow.yaml [-entry name] [-format mermaid|dot] [-o out]`
+func runGraph(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	yamlPath := fs.String("f", "workflow.yaml", "Path to workflow YAML, or - for stdin")
+	format := fs.String("format", dsl.DiagramMermaid, "Diagram format: mermaid/dot")
+	out := fs.String("o", "", "Write the diagram to this file instead of stdout")
+	entry := fs.String("entry", "", "Entry point to draw from the YAML's entrypoints (default: root)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	wf, err := loadWorkflowFromYAML(*yamlPath)
+	if err != nil {
+		return err
+	}
+	if wf, err = wf.SelectEntry(*entry); err != nil {
+		return err
+	}
+	diagram, err := dsl.RenderDiagram(wf, *format)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		fmt.Print(diagram)
+		return nil
+	}
+	return os.WriteFile(*out, []byte(diagram), 0o644)
+}
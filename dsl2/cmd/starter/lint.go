@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"gopkg.in/yaml.v3"
+)
+
+// LintReport 是 lint 子命令的结构化输出，供 CI 解析
+type LintReport struct {
+	Files    []LintFile `json:"files"`
+	Errors   int        `json:"errors"`
+	Warnings int        `json:"warnings"`
+}
+
+type LintFile struct {
+	File   string      `json:"file"`
+	Issues []dsl.Issue `json:"issues"`
+}
+
+// lintConfig 是 -lint-config 文件的格式
+//
+//	maxLiteralBytes: 8192
+//	rules:
+//	  missing-timeout: error
+//	  unused-variable: off
+type lintConfig struct {
+	MaxLiteralBytes int               `yaml:"maxLiteralBytes"`
+	Rules           map[string]string `yaml:"rules"`
+}
+
+// ruleFlags 收集可重复的 -rule id=severity
+type ruleFlags map[string]string
+
+func (r ruleFlags) String() string { return fmt.Sprint(map[string]string(r)) }
+
+func (r ruleFlags) Set(s string) error {
+	id, sev, ok := strings.Cut(s, "=")
+	if !ok || id == "" {
+		return fmt.Errorf("expected rule=severity, got %q", s)
+	}
+	r[id] = sev
+	return nil
+}
+
+// runLint 实现 `starter lint [-lint-config lint.yaml] [-rule id=severity] files...`；
+// 参数可以是文件、目录或 glob，存在 error 级问题时返回非零退出码
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	configPath := fs.String("lint-config", "", "Lint config YAML with rules: {id: error|warning|off} and maxLiteralBytes")
+	rules := ruleFlags{}
+	fs.Var(rules, "rule", "Override a rule severity, e.g. missing-timeout=error (repeatable, wins over -lint-config)")
+	maxLiteral := fs.Int("max-literal", 0, "Largest literal in bytes before oversized-literal fires (default 4096)")
+	listRules := fs.Bool("rules", false, "List the rules and their default severities, then exit")
+	output := fs.String("output", outputText, "Output format: text/json/yaml")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := parseOutputFormat(*output); err != nil {
+		return err
+	}
+
+	if *listRules {
+		emit(dsl.LintRules, func() {
+			for _, r := range dsl.LintRules {
+				fmt.Printf("%-20s %-8s %s\n", r.ID, r.Severity, r.Description)
+			}
+		})
+		return nil
+	}
+
+	opts := dsl.LintOptions{KnownActivities: dsl.ActivityNames(), Severity: map[string]string{}}
+	if *configPath != "" {
+		b, err := os.ReadFile(*configPath)
+		if err != nil {
+			return fmt.Errorf("read config: %w", err)
+		}
+		var cfg lintConfig
+		if err := yaml.Unmarshal(b, &cfg); err != nil {
+			return fmt.Errorf("parse config %s: %w", *configPath, err)
+		}
+		opts.MaxLiteralBytes = cfg.MaxLiteralBytes
+		for id, sev := range cfg.Rules {
+			opts.Severity[id] = sev
+		}
+	}
+	for id, sev := range rules {
+		opts.Severity[id] = sev
+	}
+	if *maxLiteral > 0 {
+		opts.MaxLiteralBytes = *maxLiteral
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return errors.New("at least one file, directory or glob is required")
+	}
+	var files []string
+	for _, arg := range fs.Args() {
+		m, err := batchFiles(arg)
+		if err != nil {
+			return err
+		}
+		files = append(files, m...)
+	}
+
+	rep := LintReport{Files: []LintFile{}}
+	for _, path := range files {
+		lf := LintFile{File: path}
+		if b, err := os.ReadFile(path); err != nil {
+			lf.Issues = []dsl.Issue{{Severity: dsl.SeverityError, Message: err.Error()}}
+		} else if wf, err := dsl.ParseYAML(b); err != nil {
+			lf.Issues = []dsl.Issue{{Severity: dsl.SeverityError, Message: err.Error()}}
+		} else {
+			lf.Issues = wf.Lint(opts)
+		}
+		for _, i := range lf.Issues {
+			if i.Severity == dsl.SeverityError {
+				rep.Errors++
+			} else {
+				rep.Warnings++
+			}
+		}
+		rep.Files = append(rep.Files, lf)
+	}
+
+	emit(rep, func() {
+		for _, f := range rep.Files {
+			for _, i := range f.Issues {
+				loc := f.File
+				if i.Path != "" {
+					loc += ":" + i.Path
+				}
+				if i.Rule != "" {
+					fmt.Printf("%s: %s: %s [%s]\n", loc, i.Severity, i.Message, i.Rule)
+				} else {
+					fmt.Printf("%s: %s: %s\n", loc, i.Severity, i.Message)
+				}
+			}
+		}
+		fmt.Printf("%d file(s), %d error(s), %d warning(s)\n", len(rep.Files), rep.Errors, rep.Warnings)
+	})
+	if rep.Errors > 0 {
+		return fmt.Errorf("%d error(s)", rep.Errors)
+	}
+	return nil
+}
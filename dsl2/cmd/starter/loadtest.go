@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"go.temporal.io/sdk/client"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadTestReport 是 loadtest 的汇总；延迟单位为毫秒
+type LoadTestReport struct {
+	File          string         `json:"file"`
+	Runs          int            `json:"runs"`
+	Concurrency   int            `json:"concurrency"`
+	Started       int            `json:"started"`
+	StartFailures int            `json:"startFailures"`
+	Completed     int            `json:"completed"`
+	Failed        int            `json:"failed"`
+	DurationMs    int64          `json:"durationMs"`
+	Throughput    float64        `json:"throughput"` // 每秒完成（-wait=false 时为启动）的执行数
+	StartLatency  LatencySummary `json:"startLatency"`
+	EndToEnd      LatencySummary `json:"endToEnd"`
+	Errors        map[string]int `json:"errors,omitempty"` // 错误信息 -> 次数
+}
+
+// LatencySummary 是一组延迟的分位数摘要（毫秒）
+type LatencySummary struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"minMs"`
+	Mean  float64 `json:"meanMs"`
+	P50   float64 `json:"p50Ms"`
+	P90   float64 `json:"p90Ms"`
+	P95   float64 `json:"p95Ms"`
+	P99   float64 `json:"p99Ms"`
+	Max   float64 `json:"maxMs"`
+}
+
+// varTemplates 收集可重复的 -var-template key=template，每次执行单独渲染
+type varTemplates map[string]*template.Template
+
+func (v varTemplates) String() string {
+	keys := mapsKeys(v)
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func (v varTemplates) Set(s string) error {
+	key, text, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=template, got %q", s)
+	}
+	t, err := template.New(key).Option("missingkey=error").Funcs(template.FuncMap{
+		"rand": func(n int) int { return rand.IntN(max(n, 1)) },
+		"pick": func(items ...any) any { return items[rand.IntN(len(items))] },
+	}).Parse(text)
+	if err != nil {
+		return err
+	}
+	v[key] = t
+	return nil
+}
+
+// render 渲染第 i 次执行的变量；结果按 YAML 标量解析，与 -var 一致
+func (v varTemplates) render(i, n int) (map[string]any, error) {
+	vars := map[string]any{}
+	data := map[string]int{"i": i, "n": n}
+	for key, t := range v {
+		var b strings.Builder
+		if err := t.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("-var-template %s: %w", key, err)
+		}
+		var val any
+		if err := yaml.Unmarshal([]byte(b.String()), &val); err != nil || b.Len() == 0 {
+			val = b.String()
+		}
+		vars[key] = val
+	}
+	return vars, nil
+}
+
+func mapsKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// loadRun 是一次执行的测量结果
+type loadRun struct {
+	start, total time.Duration
+	startErr     error
+	err          error
+}
+
+// runLoadTest 实现 `starter loadtest -n 100 -c 10 [-rate 20] [-var-template orderId='{{.i}}'] workflow.yaml`：
+// 以最多 -c 个并发启动 -n 次执行并等待结束，统计启动延迟、端到端延迟与失败率，用于评估 worker 容量
+func runLoadTest(args []string) error {
+	fs := flag.NewFlagSet("loadtest", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	cfg := startConfig{vars: varFlags{}}
+	templates := varTemplates{}
+	n := fs.Int("n", 100, "Number of executions to start")
+	concurrency := fs.Int("c", 10, "Maximum executions in flight")
+	rate := fs.Float64("rate", 0, "Maximum starts per second (0 = as fast as -c allows)")
+	wait := fs.Bool("wait", true, "Wait for each execution to finish and measure end-to-end latency")
+	prefix := fs.String("id-prefix", "", "Workflow ID prefix, followed by -<i> (default dsl-load-<unix time>)")
+	fs.StringVar(&cfg.entry, "entry", "", "Entry point to run from the YAML's entrypoints (default: root)")
+	fs.StringVar(&cfg.taskQueue, "q", "", "Override the YAML's taskQueue (default YAML.taskQueue, then the profile's taskQueue, then demo)")
+	fs.DurationVar(&cfg.timeout, "timeout", 10*time.Minute, "Overall time limit; unfinished executions count as failed (0 = no limit)")
+	fs.Var(cfg.vars, "var", "Override a workflow variable for every run, key=value (repeatable; value parsed as YAML)")
+	fs.StringVar(&cfg.varsFile, "vars-file", "", "JSON file of variables merged over the YAML variables (-var wins)")
+	fs.Func("chaos", "YAML file of fault-injection rules sent with every run (the worker needs chaos.allowPerRun)", cfg.setChaos)
+	fs.Var(templates, "var-template", "Per-run variable, key=Go template with {{.i}} (0-based run index), {{.n}}, {{rand N}} and {{pick a b}} (repeatable)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("exactly one workflow YAML is required")
+	}
+	if *n < 1 || *concurrency < 1 {
+		return errors.New("-n and -c must be at least 1")
+	}
+	if *prefix == "" {
+		*prefix = fmt.Sprintf("dsl-load-%d", time.Now().Unix())
+	}
+	wf, err := cfg.load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	// 先渲染一次，模板错误在启动任何执行之前报告
+	if _, err := templates.render(0, *n); err != nil {
+		return err
+	}
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	ctx, cancel := cfg.context()
+	defer cancel()
+
+	var limiter <-chan time.Time
+	if *rate > 0 {
+		t := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+		defer t.Stop()
+		limiter = t.C
+	}
+
+	infof("Starting %d execution(s) of %s on %s (concurrency %d)", *n, fs.Arg(0), wf.TaskQueue, *concurrency)
+	begin := time.Now()
+	runs := make([]loadRun, *n)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		finished int
+	)
+	sem := make(chan struct{}, *concurrency)
+	progress := time.NewTicker(5 * time.Second)
+	defer progress.Stop()
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-progress.C:
+				mu.Lock()
+				infof("%d/%d finished", finished, *n)
+				mu.Unlock()
+			}
+		}
+	}()
+
+loop:
+	for i := range *n {
+		if limiter != nil {
+			select {
+			case <-limiter:
+			case <-ctx.Done():
+				break loop
+			}
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break loop
+		}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			runs[i] = loadOne(ctx, c, cfg, wf, templates, i, *n, fmt.Sprintf("%s-%d", *prefix, i), *wait)
+			mu.Lock()
+			finished++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	close(stop)
+
+	rep := loadReport(runs, ctx.Err(), *wait)
+	rep.File, rep.Concurrency = fs.Arg(0), *concurrency
+	rep.DurationMs = time.Since(begin).Milliseconds()
+	done := rep.Completed
+	if !*wait {
+		done = rep.Started
+	}
+	rep.Throughput = float64(done) / time.Since(begin).Seconds()
+
+	emit(rep, func() {
+		fmt.Printf("%d runs in %s: %d started, %d start failures, %d completed, %d failed (%.1f/s)\n",
+			rep.Runs, time.Duration(rep.DurationMs)*time.Millisecond, rep.Started, rep.StartFailures, rep.Completed, rep.Failed, rep.Throughput)
+		fmt.Printf("%-12s %8s %8s %8s %8s %8s %8s %8s\n", "latency(ms)", "min", "mean", "p50", "p90", "p95", "p99", "max")
+		printLatency("start", rep.StartLatency)
+		if *wait {
+			printLatency("end-to-end", rep.EndToEnd)
+		}
+		if len(rep.Errors) > 0 {
+			fmt.Println("Errors:")
+			msgs := mapsKeys(rep.Errors)
+			sort.Slice(msgs, func(a, b int) bool {
+				if rep.Errors[msgs[a]] != rep.Errors[msgs[b]] {
+					return rep.Errors[msgs[a]] > rep.Errors[msgs[b]]
+				}
+				return msgs[a] < msgs[b]
+			})
+			for _, msg := range msgs {
+				fmt.Printf("  %5d  %s\n", rep.Errors[msg], msg)
+			}
+		}
+	})
+	if rep.StartFailures+rep.Failed > 0 {
+		return fmt.Errorf("%d of %d execution(s) failed", rep.StartFailures+rep.Failed, rep.Runs)
+	}
+	return nil
+}
+
+func loadOne(ctx context.Context, c client.Client, cfg startConfig, wf dsl.Workflow, templates varTemplates, i, n int, id string, wait bool) loadRun {
+	vars, err := templates.render(i, n)
+	if err != nil {
+		return loadRun{startErr: err}
+	}
+	begin := time.Now()
+	_, run, err := cfg.start(ctx, c, wf.WithVariables(vars), id)
+	r := loadRun{start: time.Since(begin), startErr: err}
+	if err != nil || !wait {
+		return r
+	}
+	var out map[string]any
+	r.err = run.Get(ctx, &out)
+	r.total = time.Since(begin)
+	return r
+}
+
+// loadReport 汇总测量结果；未轮到启动的执行（超时）计为启动失败
+func loadReport(runs []loadRun, ctxErr error, wait bool) LoadTestReport {
+	rep := LoadTestReport{Runs: len(runs), Errors: map[string]int{}}
+	var starts, totals []time.Duration
+	for _, r := range runs {
+		switch {
+		case r.startErr != nil:
+			rep.StartFailures++
+			rep.Errors["start: "+rootCause(r.startErr)]++
+			continue
+		case r.start == 0:
+			rep.StartFailures++
+			rep.Errors["not started: "+fmt.Sprint(ctxErr)]++
+			continue
+		}
+		rep.Started++
+		starts = append(starts, r.start)
+		if !wait {
+			continue
+		}
+		if r.err != nil {
+			rep.Failed++
+			rep.Errors[rootCause(r.err)]++
+			continue
+		}
+		rep.Completed++
+		totals = append(totals, r.total)
+	}
+	rep.StartLatency = summarize(starts)
+	rep.EndToEnd = summarize(totals)
+	return rep
+}
+
+// rootCause 取错误链最内层的信息，外层包含 Workflow ID 等每次不同的内容，无法按次数汇总
+func rootCause(err error) string {
+	for u := errors.Unwrap(err); u != nil; u = errors.Unwrap(err) {
+		err = u
+	}
+	return err.Error()
+}
+
+// summarize 计算最近秩分位数
+func summarize(ds []time.Duration) LatencySummary {
+	if len(ds) == 0 {
+		return LatencySummary{}
+	}
+	slices.Sort(ds)
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	at := func(p float64) float64 {
+		idx := int(p*float64(len(ds))+0.999999) - 1
+		return ms(ds[min(max(idx, 0), len(ds)-1)])
+	}
+	var sum time.Duration
+	for _, d := range ds {
+		sum += d
+	}
+	return LatencySummary{
+		Count: len(ds),
+		Min:   ms(ds[0]),
+		Mean:  ms(sum / time.Duration(len(ds))),
+		P50:   at(0.50),
+		P90:   at(0.90),
+		P95:   at(0.95),
+		P99:   at(0.99),
+		Max:   ms(ds[len(ds)-1]),
+	}
+}
+
+func printLatency(name string, s LatencySummary) {
+	fmt.Printf("%-12s %8.1f %8.1f %8.1f %8.1f %8.1f %8.1f %8.1f\n", name, s.Min, s.Mean, s.P50, s.P90, s.P95, s.P99, s.Max)
+}
@@ -1,112 +1,222 @@
 package main
 
 import (
-	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
 	"time"
 
-	yaml "github.com/goccy/go-yaml"
-
 	dsl "github.com/temporalio/samples-go/dsl2"
-	"go.temporal.io/sdk/client"
+	"github.com/temporalio/samples-go/dsl2/cmd/internal/conn"
 )
 
+// subcommands 是 starter 支持的子命令；不带子命令时启动工作流
+var subcommands = map[string]func(args []string) error{
+	"graph":     runGraph,
+	"signal":    runSignal,
+	"query":     runQuery,
+	"cancel":    runCancel,
+	"terminate": runTerminate,
+	"pause":     runPause,
+	"resume":    runResume,
+	"debug":     runDebug,
+	"step":      runStep,
+	"continue":  runContinue,
+	"describe":  runDescribe,
+	"schedule":  runSchedule,
+	"sync":      runSync,
+	"replay":    runReplay,
+	"convert":   runConvert,
+	"openapi":   runOpenAPI,
+	"timeline":  runTimeline,
+	"lint":      runLint,
+	"migrate":   runMigrate,
+	"tune":      runTune,
+	"test":      runTest,
+	"loadtest":  runLoadTest,
+	"verify":    runVerify,
+}
+
 func main() {
+	// 先于参数注册加载 Profile，使各参数的默认值取自所选 Profile
+	if err := conn.LoadProfile(os.Args[1:]); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				log.Fatalf("%s: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+
 	// ----- CLI flags -----
 	var (
 		yamlPath  string
-		hostport  string
-		namespace string
-		taskQueue string
-		wfid      string
-		timeout   time.Duration
+		connOpts  conn.Options
+		cfg       = startConfig{vars: varFlags{}, memo: varFlags{}, attrs: varFlags{}}
+		validate  bool
+		dryRun    bool
+		mocksPath string
+		noWait    bool
+		follow    bool
+		output    string
+		batch     string
+		parallel  int
 	)
-	flag.StringVar(&yamlPath, "f", "", "Path to workflow YAML (required)")
-	flag.StringVar(&yamlPath, "file", "", "Path to workflow YAML (required)") // alias
-	flag.StringVar(&hostport, "host", envOr("TEMPORAL_HOSTPORT", "localhost:7233"), "Temporal Host:Port")
-	flag.StringVar(&namespace, "ns", envOr("TEMPORAL_NAMESPACE", "default"), "Temporal Namespace")
-	flag.StringVar(&taskQueue, "q", "", "Override task queue (optional, otherwise use YAML.taskQueue or 'demo')")
-	flag.StringVar(&wfid, "id", "", "Workflow ID (optional, default auto-generate)")
-	flag.DurationVar(&timeout, "timeout", 2*time.Minute, "Starter context timeout")
+	flag.StringVar(&yamlPath, "f", "", "Path to workflow YAML, or - for stdin (required)")
+	flag.StringVar(&yamlPath, "file", "", "Path to workflow YAML, or - for stdin (required)") // alias
+	connOpts.Register(flag.CommandLine)
+	flag.StringVar(&cfg.taskQueue, "q", "", "Override the YAML's taskQueue (default YAML.taskQueue, then the profile's taskQueue, then demo)")
+	flag.StringVar(&cfg.entry, "entry", "", "Entry point to run from the YAML's entrypoints (default: root)")
+	flag.StringVar(&cfg.workflowID, "id", "", "Workflow ID (optional, default auto-generate; ID prefix with -batch)")
+	flag.DurationVar(&cfg.timeout, "timeout", 2*time.Minute, "Starter context timeout (0 = no timeout)")
+	flag.Var(cfg.vars, "var", "Override a workflow variable, key=value (repeatable; value parsed as YAML)")
+	flag.Func("id-template", "Workflow ID template rendered from the merged variables, e.g. 'order-{{.orderId}}'", cfg.setIDTemplate)
+	flag.Func("id-reuse-policy", "Workflow ID reuse policy: AllowDuplicate/AllowDuplicateFailedOnly/RejectDuplicate/TerminateIfRunning", cfg.setReusePolicy)
+	flag.IntVar(&cfg.retryMaxAttempts, "retry-max-attempts", 0, "Override the global activity retry maxAttempts (0 = keep YAML)")
+	flag.DurationVar(&cfg.retryInitial, "retry-initial", 0, "Override the global retry initial interval, e.g. 5s (0 = keep YAML)")
+	flag.IntVar(&cfg.timeoutSec, "timeout-sec", 0, "Override the global activity timeoutSec (0 = keep YAML)")
+	flag.DurationVar(&cfg.startDelay, "start-delay", 0, "Delay the first workflow task, e.g. 2h (overrides YAML startDelaySec)")
+	flag.DurationVar(&cfg.executionTimeout, "execution-timeout", 0, "Server-side limit for the whole execution, e.g. 24h (overrides YAML executionTimeoutSec)")
+	flag.DurationVar(&cfg.runTimeout, "run-timeout", 0, "Server-side limit for a single run, e.g. 1h (overrides YAML runTimeoutSec)")
+	flag.Var(cfg.memo, "memo", "Add a memo entry, key=value (repeatable; value parsed as YAML)")
+	flag.Var(cfg.attrs, "search-attr", "Set a search attribute, key=value (repeatable; type inferred from the value)")
+	flag.StringVar(&cfg.varsFile, "vars-file", "", "JSON file of variables merged over the YAML variables (-var wins)")
+	flag.Func("chaos", "YAML file of fault-injection rules sent with the run (the worker needs chaos.allowPerRun)", cfg.setChaos)
+	flag.BoolVar(&validate, "validate", false, "Only run static validation, print a JSON report and exit non-zero on errors")
+	flag.BoolVar(&dryRun, "dry-run", false, "Execute locally in the test environment with mocked activities (no cluster)")
+	flag.StringVar(&mocksPath, "mocks", "", "JSON/YAML file of activity mocks for -dry-run (name -> {result|results|error})")
+	flag.BoolVar(&noWait, "no-wait", false, "Print the workflow and run IDs after starting and exit without waiting")
+	flag.BoolVar(&follow, "follow", false, "Print node-level status lines while waiting for the result")
+	flag.StringVar(&output, "output", outputText, "Output format: text/json/yaml (json/yaml print a single document to stdout)")
+	flag.StringVar(&batch, "batch", "", "Start every workflow matching this glob or directory and print an aggregate report")
+	flag.IntVar(&parallel, "parallel", 4, "Maximum executions in flight with -batch")
 	flag.Parse()
+	if err := conn.ApplyDefaults(flag.CommandLine); err != nil {
+		log.Fatalf("%v", err)
+	}
 
-	if yamlPath == "" {
-		yamlPath = "workflow.yaml"
+	if err := parseOutputFormat(output); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if noWait && follow {
+		log.Fatalf("-no-wait and -follow are mutually exclusive")
+	}
+	if batch != "" && (follow || validate || dryRun || yamlPath != "") {
+		log.Fatalf("-batch cannot be combined with -f, -follow, -validate or -dry-run")
 	}
 
-	// ----- Load YAML -> Workflow -----
-	wf, err := loadWorkflowFromYAML(yamlPath)
-	if err != nil {
-		log.Fatalf("load yaml: %v", err)
+	var (
+		wf         dsl.Workflow
+		batchPaths []string
+	)
+	if batch != "" {
+		var err error
+		if batchPaths, err = batchFiles(batch); err != nil {
+			log.Fatalf("-batch: %v", err)
+		}
+	} else {
+		if yamlPath == "" {
+			yamlPath = "workflow.yaml"
+		}
+		var err error
+		if wf, err = cfg.load(yamlPath); err != nil {
+			log.Fatalf("%v", err)
+		}
 	}
 
-	// 允许通过 CLI 覆盖 YAML 内的 taskQueue
-	if taskQueue != "" {
-		wf.TaskQueue = taskQueue
+	// 校验与演练都不连接集群，供 CI 在合并前检查定义
+	if validate {
+		os.Exit(runValidate(yamlPath, wf))
 	}
-	if wf.TaskQueue == "" {
-		wf.TaskQueue = "demo"
+	if dryRun {
+		os.Exit(runDryRun(yamlPath, wf, mocksPath))
 	}
 
 	// ----- Connect Temporal -----
-	c, err := client.Dial(client.Options{
-		HostPort:  hostport,
-		Namespace: namespace,
-	})
+	c, err := connOpts.Dial()
 	if err != nil {
-		log.Fatalf("client.Dial: %v", err)
+		log.Fatalf("%v", err)
 	}
 	defer c.Close()
 
-	// ----- Start Workflow -----
-	if wfid == "" {
-		wfid = fmt.Sprintf("dsl-%d", time.Now().UnixNano())
-	}
-	opts := client.StartWorkflowOptions{
-		ID:        wfid,
-		TaskQueue: wf.TaskQueue,
+	ctx, cancel := cfg.context()
+	defer cancel()
+
+	if batch != "" {
+		os.Exit(runBatch(ctx, c, cfg, batchPaths, parallel, !noWait))
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-	fmt.Printf("Starting Workflow: %+v\n", wf)
-	run, err := c.ExecuteWorkflow(ctx, opts, dsl.SimpleDSLWorkflow, wf)
+	// ----- Start Workflow -----
+	infof("Starting Workflow: %+v", wf)
+	res, run, err := cfg.start(ctx, c, wf, cfg.workflowID)
 	if err != nil {
 		log.Fatalf("start workflow: %v", err)
 	}
-	log.Printf("Started Workflow: WorkflowID=%s RunID=%s (taskQueue=%s)", run.GetID(), run.GetRunID(), wf.TaskQueue)
+	infof("Started Workflow: WorkflowID=%s RunID=%s (taskQueue=%s)", res.WorkflowID, res.RunID, res.TaskQueue)
+	if noWait {
+		emit(res, func() { fmt.Printf("WorkflowID=%s RunID=%s\n", res.WorkflowID, res.RunID) })
+		return
+	}
 
 	// ----- Wait result & pretty print bindings -----
 	var out map[string]any
-	if err := run.Get(ctx, &out); err != nil {
-		log.Fatalf("get result: %v", err)
+	if follow {
+		// 结构化输出时进度行写到 stderr，stdout 只保留最终结果
+		progress := os.Stdout
+		if outputFormat != outputText {
+			progress = os.Stderr
+		}
+		out, err = followRun(ctx, c, run, progress)
+	} else {
+		err = run.Get(ctx, &out)
+	}
+	res.finish(out, err)
+	if err != nil {
+		if outputFormat == outputText {
+			if f := res.Failure; f != nil {
+				node := f.Kind + " " + f.Path
+				if f.Activity != "" {
+					node += " (" + f.Activity + ")"
+				}
+				log.Printf("Failed node: %s; bindings: %s", node, strings.Join(f.Bindings, ", "))
+			}
+			log.Fatalf("get result: %v", err)
+		}
+		emit(res, nil)
+		os.Exit(1)
 	}
-	bs, _ := json.MarshalIndent(out, "", "  ")
-	log.Printf("Result bindings:\n%s", string(bs))
+	emit(res, func() {
+		bs, _ := json.MarshalIndent(out, "", "  ")
+		log.Printf("Result bindings:\n%s", string(bs))
+	})
 }
 
+// loadWorkflowFromYAML 读取定义文件；path 为 "-" 时从 stdin 读取，便于接在模板或 curl 管道之后
 func loadWorkflowFromYAML(path string) (dsl.Workflow, error) {
-	b, err := os.ReadFile(path)
+	var (
+		b   []byte
+		err error
+	)
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
 	if err != nil {
 		return dsl.Workflow{}, fmt.Errorf("read file: %w", err)
 	}
-	var wf dsl.Workflow
-	// 使用 sigs.k8s.io/yaml 以支持结构体上的 json 标签
-	if err := yaml.Unmarshal(b, &wf); err != nil {
+	// 与 web UI 使用同一解析入口，避免两边对定义的理解不一致
+	wf, err := dsl.ParseYAML(b)
+	if err != nil {
 		return dsl.Workflow{}, fmt.Errorf("unmarshal yaml: %w", err)
 	}
-	fmt.Printf("Loaded Workflow from %s: %+v\n", path, wf)
+	infof("Loaded Workflow from %s: %+v", path, wf)
 	return wf, nil
 }
-
-// envOr returns env var value if present, otherwise fallback.
-func envOr(key, def string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return def
-}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+// convertHint 附在升级失败的错误后：原 dsl 示例格式不是旧版本，不能 migrate
+const convertHint = "; files in the original dsl sample format are converted with `starter convert -from dslv1`"
+
+// runMigrate 实现 `starter migrate [-from version] [-w | -check] files...`：把旧版本的定义升级到当前 schema 版本。
+// 不带 -w 时只接受一个文件（或 - 表示 stdin），结果写到 stdout；-check 只列出需要升级的文件，存在时返回非零退出码。
+// 原 dsl 示例格式的文件用 `convert -from dslv1` 转换
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := fs.String("from", "", "Schema version of files that have no version field")
+	write := fs.Bool("w", false, "Rewrite the files in place instead of printing the result")
+	check := fs.Bool("check", false, "Only list files that need migrating; exit non-zero if there are any")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *write && *check {
+		return errors.New("-w and -check are mutually exclusive")
+	}
+	if fs.NArg() == 0 {
+		return errors.New("at least one file, directory or glob is required")
+	}
+
+	if !*write && !*check {
+		if fs.NArg() != 1 {
+			return errors.New("without -w or -check exactly one file (or - for stdin) is required")
+		}
+		var (
+			b   []byte
+			err error
+		)
+		if path := fs.Arg(0); path == "-" {
+			b, err = io.ReadAll(os.Stdin)
+		} else {
+			b, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return fmt.Errorf("read file: %w", err)
+		}
+		out, notes, err := dsl.Migrate(b, *from)
+		if err != nil {
+			return fmt.Errorf("%w%s", err, convertHint)
+		}
+		for _, n := range notes {
+			log.Printf("note: %s", n)
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+
+	var files []string
+	for _, arg := range fs.Args() {
+		m, err := batchFiles(arg)
+		if err != nil {
+			return err
+		}
+		files = append(files, m...)
+	}
+	pending := 0
+	for _, path := range files {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		out, notes, err := dsl.Migrate(b, *from)
+		if err != nil {
+			return fmt.Errorf("%s: %w%s", path, err, convertHint)
+		}
+		if bytes.Equal(out, b) {
+			continue
+		}
+		pending++
+		if *check {
+			fmt.Printf("%s: needs migrating to %s (%d change(s))\n", path, dsl.SchemaVersion, len(notes))
+			continue
+		}
+		for _, n := range notes {
+			log.Printf("%s: note: %s", path, n)
+		}
+		if err := os.WriteFile(path, out, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("%s: migrated to %s\n", path, dsl.SchemaVersion)
+	}
+	if *check && pending > 0 {
+		return fmt.Errorf("%d file(s) need migrating", pending)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/openapi"
+)
+
+// runOpenAPI 实现 `starter openapi [-prefix p.] [-example operationId] spec.yaml`：
+// 列出 worker 会为该文档注册的 Activity 及其参数；-example 输出调用某个操作的 DSL 片段
+func runOpenAPI(args []string) error {
+	fs := flag.NewFlagSet("openapi", flag.ExitOnError)
+	prefix := fs.String("prefix", "", "Activity name prefix, the same as the worker config's prefix")
+	baseURL := fs.String("base-url", "", "Override the document's servers[0].url")
+	example := fs.String("example", "", "Print a workflow snippet that calls this activity (name or operationId)")
+	output := fs.String("output", outputText, "Output format: text/json/yaml")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := parseOutputFormat(*output); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("exactly one OpenAPI document is required")
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+	ops, err := openapi.Operations(data, openapi.Config{Prefix: *prefix, BaseURL: *baseURL})
+	if err != nil {
+		return err
+	}
+
+	if *example != "" {
+		for _, op := range ops {
+			if op.Name == *example || op.OperationID == *example {
+				return printOpenAPIExample(op)
+			}
+		}
+		return fmt.Errorf("no operation %q in the document", *example)
+	}
+	emit(ops, func() {
+		for _, op := range ops {
+			var params []string
+			for _, p := range op.Params {
+				params = append(params, argName(p.Name, p.Required))
+			}
+			if op.Body != nil {
+				params = append(params, argName(openapi.BodyArg, op.BodyRequired))
+			}
+			fmt.Printf("%-32s %-7s %-32s %s\n", op.Name, op.Method, op.Path, strings.Join(params, ", "))
+		}
+	})
+	return nil
+}
+
+// argName 用 * 标出必填参数
+func argName(name string, required bool) string {
+	if required {
+		return name + "*"
+	}
+	return name
+}
+
+// printOpenAPIExample 输出一个可直接校验的工作流：参数对象放在变量中，以 ref 传给 Activity
+func printOpenAPIExample(op openapi.Operation) error {
+	argsVar := op.OperationID + "Args"
+	wf := dsl.Workflow{
+		Version:   dsl.SchemaVersion,
+		TaskQueue: "demo",
+		Variables: map[string]any{argsVar: op.Example()},
+		Root: []*dsl.Statement{{Activity: &dsl.ActivityInvocation{
+			Name:   op.Name,
+			Args:   []dsl.Value{{Ref: argsVar}},
+			Result: op.OperationID + "Result", // HTTPResponse：status、headers，以及 json 或 body
+		}}},
+	}
+	b, err := dsl.MarshalYAML(wf)
+	if err != nil {
+		return err
+	}
+	if op.Summary != "" {
+		fmt.Printf("# %s\n", op.Summary)
+	}
+	fmt.Printf("# %s %s\n", op.Method, op.Path)
+	fmt.Print(string(b))
+	return nil
+}
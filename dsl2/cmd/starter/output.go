@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"gopkg.in/yaml.v3"
+)
+
+// -output 支持的格式；json/yaml 只向 stdout 写一份结构化结果，日志与进度输出到 stderr
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// outputFormat 由 -output 设置
+var outputFormat = outputText
+
+// StartResult 是启动（并等待）一次执行后输出的结构化结果
+type StartResult struct {
+	WorkflowID string           `json:"workflowId"`
+	RunID      string           `json:"runId"`
+	TaskQueue  string           `json:"taskQueue"`
+	Status     string           `json:"status"` // Running（-no-wait）/ Completed / Failed
+	Result     map[string]any   `json:"result,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	Failure    *dsl.NodeFailure `json:"failure,omitempty"` // 语句失败时的节点信息
+	StartedAt  time.Time        `json:"startedAt"`
+	DurationMs int64            `json:"durationMs,omitempty"`
+}
+
+func parseOutputFormat(s string) error {
+	switch s {
+	case outputText, outputJSON, outputYAML:
+		outputFormat = s
+		return nil
+	}
+	return fmt.Errorf("unsupported -output %q (want json, yaml or text)", s)
+}
+
+// infof 只在 text 模式下输出，避免结构化输出混入 %+v 之类的调试信息
+func infof(format string, args ...any) {
+	if outputFormat == outputText {
+		log.Printf(format, args...)
+	}
+}
+
+// emit 按 -output 输出 v；text 模式调用 text 自行打印
+func emit(v any, text func()) {
+	switch outputFormat {
+	case outputJSON:
+		bs, _ := json.MarshalIndent(v, "", "  ")
+		fmt.Println(string(bs))
+	case outputYAML:
+		// 先经 JSON 往返，使 YAML 的字段名与 JSON 一致
+		var generic any
+		bs, _ := json.Marshal(v)
+		_ = json.Unmarshal(bs, &generic)
+		out, _ := yaml.Marshal(generic)
+		os.Stdout.Write(out)
+	default:
+		text()
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+// runQuery 实现 `starter query -id <wfid> [-type bindings|progress|trace]`；其他查询名原样转发
+func runQuery(args []string) error {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	wfid := fs.String("id", "", "Workflow ID (required)")
+	runID := fs.String("run-id", "", "Run ID (optional, default latest run)")
+	queryType := fs.String("type", dsl.QueryProgress, "Query: bindings/getProgress/trace (or any registered query name)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *wfid == "" {
+		return errors.New("-id is required")
+	}
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	v, err := c.QueryWorkflow(context.Background(), *wfid, *runID, *queryType)
+	if err != nil {
+		return err
+	}
+	switch *queryType {
+	case dsl.QueryTrace:
+		var events []dsl.TraceEvent
+		if err := v.Get(&events); err != nil {
+			return err
+		}
+		emit(events, func() {
+			for _, ev := range events {
+				fmt.Println(formatTraceEvent(ev))
+			}
+		})
+	case dsl.QueryProgress, dsl.QueryProgressAlias:
+		var p dsl.Progress
+		if err := v.Get(&p); err != nil {
+			return err
+		}
+		emit(p, func() {
+			fmt.Printf("completed=%d failed=%d running=%d\n", p.Completed, p.Failed, len(p.Running))
+			fmt.Println(formatProgress(p))
+			if len(p.Running) > 0 {
+				fmt.Printf("running: %s\n", strings.Join(p.Running, ", "))
+			}
+		})
+	default:
+		var out any
+		if err := v.Get(&out); err != nil {
+			return err
+		}
+		emit(out, func() {
+			bs, _ := json.MarshalIndent(out, "", "  ")
+			fmt.Println(string(bs))
+		})
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/worker"
+)
+
+// ReplayResult 是 replay 子命令的结构化输出
+type ReplayResult struct {
+	Source   string `json:"source"`
+	Events   int    `json:"events"`
+	Replayed bool   `json:"replayed"`
+	Error    string `json:"error,omitempty"`
+	// DefinitionMatches 仅在提供 -f 时出现：历史中的输入是否就是该定义
+	DefinitionMatches *bool `json:"definitionMatches,omitempty"`
+	// Commands 是重放中引擎依次发出的命令（见 commandRecorder），供 verify 比较不同构建
+	Commands []string `json:"commands,omitempty"`
+}
+
+// runReplay 实现 `starter replay -history history.json [-f workflow.yaml]`，
+// 或 `starter replay -id <wfid>` 直接从集群取历史；用当前二进制中的引擎重放，检查是否与进行中的执行兼容
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	historyPath := fs.String("history", "", "Exported event history (JSON, as from the Temporal UI or CLI)")
+	wfid := fs.String("id", "", "Fetch the history of this workflow from the cluster instead of -history")
+	runID := fs.String("run-id", "", "Run ID for -id (optional, default latest run)")
+	yamlPath := fs.String("f", "", "Workflow YAML expected as the history's input (optional)")
+	commandsPath := fs.String("commands", "", "Also write the JSON result with the commands issued during replay to this file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if (*historyPath == "") == (*wfid == "") {
+		return errors.New("exactly one of -history or -id is required")
+	}
+	dc, err := cf.CodecConfig.DataConverter()
+	if err != nil {
+		return err
+	}
+	if dc == nil {
+		dc = converter.GetDefaultDataConverter()
+	}
+
+	var (
+		history *historypb.History
+		res     ReplayResult
+	)
+	if *historyPath != "" {
+		res.Source = *historyPath
+		if history, err = readHistory(*historyPath); err != nil {
+			return err
+		}
+	} else {
+		res.Source = *wfid
+		if history, err = fetchHistory(cf, *wfid, *runID); err != nil {
+			return err
+		}
+	}
+	if err := parseOutputFormat(cf.output); err != nil {
+		return err
+	}
+	res.Events = len(history.GetEvents())
+
+	if *yamlPath != "" {
+		got, err := historyInput(history, dc)
+		if err != nil {
+			return err
+		}
+		// 与启动时相同的默认值处理（如缺省 taskQueue）与入口选择；启动时的 -var 覆盖不会体现
+		want, err := startConfig{vars: varFlags{}, entry: got.Entry}.load(*yamlPath)
+		if err != nil {
+			return err
+		}
+		match := sameWorkflow(want, got)
+		res.DefinitionMatches = &match
+	}
+
+	if res.Commands, err = replayHistory(history, dc); err != nil {
+		res.Error = err.Error()
+	} else {
+		res.Replayed = true
+	}
+	if *commandsPath != "" {
+		bs, _ := json.MarshalIndent(res, "", "  ")
+		if err := os.WriteFile(*commandsPath, bs, 0o644); err != nil {
+			return err
+		}
+	}
+
+	emit(res, func() {
+		if res.DefinitionMatches != nil && !*res.DefinitionMatches {
+			fmt.Printf("warning: %s was not started with %s\n", res.Source, *yamlPath)
+		}
+		if res.Replayed {
+			fmt.Printf("%s: replayed %d events OK\n", res.Source, res.Events)
+		} else {
+			fmt.Printf("%s: replay failed: %s\n", res.Source, res.Error)
+		}
+	})
+	if !res.Replayed {
+		return errors.New("history is not replay-compatible with this binary")
+	}
+	return nil
+}
+
+// replayHistory 用当前二进制中的引擎重放 history，返回重放中发出的命令
+func replayHistory(history *historypb.History, dc converter.DataConverter) ([]string, error) {
+	rec := &commandRecorder{}
+	replayer, err := worker.NewWorkflowReplayerWithOptions(worker.WorkflowReplayerOptions{
+		DataConverter: dc,
+		Interceptors:  []interceptor.WorkerInterceptor{rec},
+	})
+	if err != nil {
+		return nil, err
+	}
+	replayer.RegisterWorkflow(dsl.SimpleDSLWorkflow)
+	err = replayer.ReplayWorkflowHistory(nil, history)
+	return rec.commands, err
+}
+
+// readHistory 读取导出的事件历史（Temporal UI 或 CLI 的 JSON 格式）
+func readHistory(path string) (*historypb.History, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	history, err := client.HistoryFromJSON(f, client.HistoryJSONOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("parse history: %w", err)
+	}
+	return history, nil
+}
+
+func fetchHistory(cf *connFlags, wfid, runID string) (*historypb.History, error) {
+	c, err := cf.Dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	history := &historypb.History{}
+	iter := c.GetWorkflowHistory(context.Background(), wfid, runID, false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		ev, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		history.Events = append(history.Events, ev)
+	}
+	return history, nil
+}
+
+// historyInput 解码 WorkflowExecutionStarted 事件中的定义参数
+func historyInput(history *historypb.History, dc converter.DataConverter) (dsl.Workflow, error) {
+	var wf dsl.Workflow
+	events := history.GetEvents()
+	if len(events) == 0 {
+		return wf, errors.New("history is empty")
+	}
+	attrs := events[0].GetWorkflowExecutionStartedEventAttributes()
+	if attrs == nil {
+		return wf, errors.New("first event is not WorkflowExecutionStarted")
+	}
+	if err := dc.FromPayloads(attrs.GetInput(), &wf); err != nil {
+		return wf, fmt.Errorf("decode workflow input: %w", err)
+	}
+	return wf, nil
+}
+
+// sameWorkflow 以 JSON 形式比较，忽略 YAML 与载荷编码在数字类型上的差异
+func sameWorkflow(a, b dsl.Workflow) bool {
+	norm := func(wf dsl.Workflow) any {
+		bs, _ := json.Marshal(wf)
+		var v any
+		_ = json.Unmarshal(bs, &v)
+		return v
+	}
+	return reflect.DeepEqual(norm(a), norm(b))
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// ScheduleResult 是 schedule 子命令的结构化输出
+type ScheduleResult struct {
+	ScheduleID string `json:"scheduleId"`
+	Action     string `json:"action"`
+}
+
+// overlapPolicies 是 -overlap 接受的取值
+var overlapPolicies = map[string]enumspb.ScheduleOverlapPolicy{
+	"Skip":           enumspb.SCHEDULE_OVERLAP_POLICY_SKIP,
+	"BufferOne":      enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE,
+	"BufferAll":      enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ALL,
+	"CancelOther":    enumspb.SCHEDULE_OVERLAP_POLICY_CANCEL_OTHER,
+	"TerminateOther": enumspb.SCHEDULE_OVERLAP_POLICY_TERMINATE_OTHER,
+	"AllowAll":       enumspb.SCHEDULE_OVERLAP_POLICY_ALLOW_ALL,
+}
+
+func parseOverlap(s string) (enumspb.ScheduleOverlapPolicy, error) {
+	if s == "" {
+		return enumspb.SCHEDULE_OVERLAP_POLICY_UNSPECIFIED, nil
+	}
+	p, ok := overlapPolicies[s]
+	if !ok {
+		names := make([]string, 0, len(overlapPolicies))
+		for n := range overlapPolicies {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return 0, fmt.Errorf("unknown -overlap %q (want %s)", s, strings.Join(names, ", "))
+	}
+	return p, nil
+}
+
+// stringList 收集可重复的字符串参数
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(s string) error { *l = append(*l, s); return nil }
+
+var scheduleCommands = map[string]func(args []string) error{
+	"create":  func(args []string) error { return runScheduleSave("create", args) },
+	"update":  func(args []string) error { return runScheduleSave("update", args) },
+	"pause":   func(args []string) error { return runScheduleControl("pause", args) },
+	"unpause": func(args []string) error { return runScheduleControl("unpause", args) },
+	"trigger": func(args []string) error { return runScheduleControl("trigger", args) },
+	"delete":  func(args []string) error { return runScheduleControl("delete", args) },
+}
+
+// runSchedule 实现 `starter schedule create|update|pause|unpause|trigger|delete ...`
+func runSchedule(args []string) error {
+	if len(args) == 0 || scheduleCommands[args[0]] == nil {
+		fmt.Fprintln(os.Stderr, "usage: starter schedule create|update|pause|unpause|trigger|delete -id <scheduleId> [flags]")
+		return errors.New("missing or unknown schedule command")
+	}
+	return scheduleCommands[args[0]](args[1:])
+}
+
+// runScheduleSave 创建或更新计划：-f 给出要执行的定义，-cron/-every 给出时间规则。
+// update 只替换提供了的部分
+func runScheduleSave(action string, args []string) error {
+	fs := flag.NewFlagSet("schedule "+action, flag.ExitOnError)
+	cf := addConnFlags(fs)
+	cfg := startConfig{vars: varFlags{}}
+	id := fs.String("id", "", "Schedule ID (required)")
+	yamlPath := fs.String("f", "", "Path to workflow YAML, or - for stdin (required for create)")
+	var crons stringList
+	fs.Var(&crons, "cron", "Cron expression, e.g. '0 2 * * *' (repeatable)")
+	every := fs.Duration("every", 0, "Run at a fixed interval, e.g. 1h")
+	workflowID := fs.String("workflow-id", "", "Workflow ID prefix for started runs (default: the schedule ID)")
+	overlap := fs.String("overlap", "", "Overlap policy: Skip/BufferOne/BufferAll/CancelOther/TerminateOther/AllowAll")
+	note := fs.String("note", "", "Note recorded on the schedule")
+	paused := fs.Bool("paused", false, "Create the schedule in the paused state (create only)")
+	fs.StringVar(&cfg.entry, "entry", "", "Entry point to run from the YAML's entrypoints (default: root)")
+	fs.StringVar(&cfg.taskQueue, "q", "", "Override the YAML's taskQueue (default YAML.taskQueue, then the profile's taskQueue, then demo)")
+	fs.Var(cfg.vars, "var", "Override a workflow variable, key=value (repeatable)")
+	fs.StringVar(&cfg.varsFile, "vars-file", "", "JSON/YAML file of variables merged over the YAML variables")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *id == "" {
+		return errors.New("-id is required")
+	}
+	overlapPolicy, err := parseOverlap(*overlap)
+	if err != nil {
+		return err
+	}
+	var spec *client.ScheduleSpec
+	if len(crons) > 0 || *every > 0 {
+		spec = &client.ScheduleSpec{CronExpressions: crons}
+		if *every > 0 {
+			spec.Intervals = []client.ScheduleIntervalSpec{{Every: *every}}
+		}
+	}
+	var wfAction *client.ScheduleWorkflowAction
+	if *yamlPath != "" {
+		wf, err := cfg.load(*yamlPath)
+		if err != nil {
+			return err
+		}
+		if issues := wf.Check(dsl.CheckOptions{}); dsl.HasErrors(issues) {
+			return fmt.Errorf("invalid workflow: %v", issues)
+		}
+		prefix := *workflowID
+		if prefix == "" {
+			prefix = *id
+		}
+		wfAction = &client.ScheduleWorkflowAction{
+			ID:                       prefix,
+			Workflow:                 dsl.SimpleDSLWorkflow,
+			Args:                     []any{wf},
+			TaskQueue:                wf.TaskQueue,
+			WorkflowExecutionTimeout: time.Duration(wf.ExecutionTimeoutSec) * time.Second,
+			WorkflowRunTimeout:       time.Duration(wf.RunTimeoutSec) * time.Second,
+		}
+	}
+	if action == "create" && (spec == nil || wfAction == nil) {
+		return errors.New("create requires -f and at least one of -cron or -every")
+	}
+
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if action == "create" {
+		_, err = c.ScheduleClient().Create(ctx, client.ScheduleOptions{
+			ID:      *id,
+			Spec:    *spec,
+			Action:  wfAction,
+			Overlap: overlapPolicy,
+			Paused:  *paused,
+			Note:    *note,
+		})
+	} else {
+		err = c.ScheduleClient().GetHandle(ctx, *id).Update(ctx, client.ScheduleUpdateOptions{
+			DoUpdate: func(in client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+				s := in.Description.Schedule
+				if spec != nil {
+					s.Spec = spec
+				}
+				if wfAction != nil {
+					s.Action = wfAction
+				}
+				if s.Policy != nil && overlapPolicy != enumspb.SCHEDULE_OVERLAP_POLICY_UNSPECIFIED {
+					s.Policy.Overlap = overlapPolicy
+				}
+				if s.State != nil && *note != "" {
+					s.State.Note = *note
+				}
+				return &client.ScheduleUpdate{Schedule: &s}, nil
+			},
+		})
+	}
+	if err != nil {
+		return err
+	}
+	res := ScheduleResult{ScheduleID: *id, Action: action}
+	emit(res, func() { fmt.Printf("Schedule %s: %sd\n", res.ScheduleID, strings.TrimSuffix(action, "e")) })
+	return nil
+}
+
+// runScheduleControl 处理 pause/unpause/trigger/delete
+func runScheduleControl(action string, args []string) error {
+	fs := flag.NewFlagSet("schedule "+action, flag.ExitOnError)
+	cf := addConnFlags(fs)
+	id := fs.String("id", "", "Schedule ID (required)")
+	note := fs.String("note", "", "Note recorded with pause/unpause")
+	overlap := fs.String("overlap", "", "Overlap policy for trigger (default: the schedule's policy)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *id == "" {
+		return errors.New("-id is required")
+	}
+	overlapPolicy, err := parseOverlap(*overlap)
+	if err != nil {
+		return err
+	}
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	h := c.ScheduleClient().GetHandle(ctx, *id)
+	switch action {
+	case "pause":
+		err = h.Pause(ctx, client.SchedulePauseOptions{Note: *note})
+	case "unpause":
+		err = h.Unpause(ctx, client.ScheduleUnpauseOptions{Note: *note})
+	case "trigger":
+		err = h.Trigger(ctx, client.ScheduleTriggerOptions{Overlap: overlapPolicy})
+	case "delete":
+		err = h.Delete(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	res := ScheduleResult{ScheduleID: *id, Action: action}
+	emit(res, func() { fmt.Printf("Schedule %s: %s\n", res.ScheduleID, action) })
+	return nil
+}
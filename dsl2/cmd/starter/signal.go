@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SignalResult 是 signal 子命令的结构化输出
+type SignalResult struct {
+	WorkflowID string `json:"workflowId"`
+	RunID      string `json:"runId,omitempty"`
+	Signal     string `json:"signal"`
+}
+
+// runSignal 实现 `starter signal -id <wfid> -name approve [-payload '{"ok":true}']`
+func runSignal(args []string) error {
+	fs := flag.NewFlagSet("signal", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	wfid := fs.String("id", "", "Workflow ID (required)")
+	runID := fs.String("run-id", "", "Run ID (optional, default latest run)")
+	name := fs.String("name", "", "Signal name (required)")
+	payload := fs.String("payload", "", "Signal payload as JSON/YAML (optional)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *wfid == "" || *name == "" {
+		return errors.New("-id and -name are required")
+	}
+	var arg any
+	if *payload != "" {
+		if err := yaml.Unmarshal([]byte(*payload), &arg); err != nil {
+			return fmt.Errorf("parse -payload: %w", err)
+		}
+	}
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.SignalWorkflow(context.Background(), *wfid, *runID, *name, arg); err != nil {
+		return err
+	}
+	res := SignalResult{WorkflowID: *wfid, RunID: *runID, Signal: *name}
+	emit(res, func() { fmt.Printf("Signaled %s with %q\n", res.WorkflowID, res.Signal) })
+	return nil
+}
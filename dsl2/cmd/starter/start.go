@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/template"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/chaos"
+	"github.com/temporalio/samples-go/dsl2/cmd/internal/conn"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+	"gopkg.in/yaml.v3"
+)
+
+// startConfig 汇总影响“加载定义并启动一次执行”的参数，单个启动与 -batch 共用
+type startConfig struct {
+	taskQueue  string
+	workflowID string
+	timeout    time.Duration
+	varsFile   string
+	vars       varFlags
+	memo       varFlags
+	attrs      varFlags
+	// idTemplate 由合并后的变量渲染出业务主键式的 Workflow ID（如 order-{{.orderId}}）
+	idTemplate  *template.Template
+	reusePolicy enumspb.WorkflowIdReusePolicy
+	// 覆盖 YAML 中的全局重试与超时（0 表示沿用 YAML）
+	retryMaxAttempts int
+	retryInitial     time.Duration
+	timeoutSec       int
+	// startDelay 覆盖 YAML 的 startDelaySec
+	startDelay time.Duration
+	// executionTimeout/runTimeout 覆盖 YAML 的 executionTimeoutSec/runTimeoutSec
+	executionTimeout time.Duration
+	runTimeout       time.Duration
+	// chaos 随执行传给 worker 的故障注入规则（worker 需配置 chaos.allowPerRun）
+	chaos []chaos.Rule
+	// entry 选择 YAML entrypoints 中的入口，为空时执行 root
+	entry string
+}
+
+func (cfg *startConfig) setIDTemplate(s string) error {
+	t, err := dsl.ParseIDTemplate(s)
+	if err != nil {
+		return err
+	}
+	cfg.idTemplate = t
+	return nil
+}
+
+func (cfg *startConfig) setReusePolicy(s string) error {
+	p, err := dsl.ParseIDReusePolicy(s)
+	if err != nil {
+		return err
+	}
+	cfg.reusePolicy = p
+	return nil
+}
+
+// setChaos 读取 -chaos 文件，格式同 worker 配置的 chaos 段（rules 列表）
+func (cfg *startConfig) setChaos(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var c chaos.Config
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(c.Rules) == 0 {
+		return fmt.Errorf("%s has no rules", path)
+	}
+	if err := chaos.Validate(c.Rules); err != nil {
+		return err
+	}
+	cfg.chaos = c.Rules
+	return nil
+}
+
+// workflowIDFor 确定一次执行的 ID：显式 id > -id-template > YAML 的 workflowIdTemplate > 时间戳
+func (cfg startConfig) workflowIDFor(wf dsl.Workflow, id string) (string, error) {
+	if id != "" {
+		return id, nil
+	}
+	if cfg.idTemplate != nil {
+		id, err := dsl.RenderID(cfg.idTemplate, wf.Variables)
+		if err != nil {
+			return "", fmt.Errorf("-id-template: %w", err)
+		}
+		return id, nil
+	}
+	if id, err := wf.StartID(); err != nil || id != "" {
+		return id, err
+	}
+	return fmt.Sprintf("dsl-%d", time.Now().UnixNano()), nil
+}
+
+// hasIDTemplate 表示 ID 由变量渲染（-id-template 或 YAML 的 workflowIdTemplate），而不是由调用方给出
+func (cfg startConfig) hasIDTemplate(wf dsl.Workflow) bool {
+	return cfg.idTemplate != nil || wf.WorkflowIDTemplate != ""
+}
+
+// load 读取定义并应用变量与 taskQueue 覆盖
+func (cfg startConfig) load(path string) (dsl.Workflow, error) {
+	wf, err := loadWorkflowFromYAML(path)
+	if err != nil {
+		return dsl.Workflow{}, fmt.Errorf("load yaml: %w", err)
+	}
+	if wf, err = wf.SelectEntry(cfg.entry); err != nil {
+		if cfg.entry == "" {
+			return dsl.Workflow{}, fmt.Errorf("%w; choose one with -entry", err)
+		}
+		return dsl.Workflow{}, err
+	}
+
+	// 变量覆盖顺序：YAML variables < -vars-file < -var
+	if cfg.varsFile != "" {
+		fileVars, err := loadVarsFile(cfg.varsFile)
+		if err != nil {
+			return dsl.Workflow{}, err
+		}
+		wf = wf.WithVariables(fileVars)
+	}
+	wf = wf.WithVariables(cfg.vars)
+
+	wf.TaskQueue = conn.ResolveTaskQueue(cfg.taskQueue, wf.TaskQueue)
+
+	if cfg.retryMaxAttempts > 0 || cfg.retryInitial > 0 {
+		retry := dsl.RetryPolicy{}
+		if wf.Retry != nil {
+			retry = *wf.Retry
+		}
+		if cfg.retryMaxAttempts > 0 {
+			retry.MaxAttempts = cfg.retryMaxAttempts
+		}
+		if cfg.retryInitial > 0 {
+			retry.InitialIntervalSec = max(int(cfg.retryInitial.Round(time.Second)/time.Second), 1)
+		}
+		wf.Retry = &retry
+	}
+	if cfg.timeoutSec > 0 {
+		wf.TimeoutSec = cfg.timeoutSec
+	}
+	return wf, nil
+}
+
+// context 返回整个启动过程的上下文；-timeout 0 表示不限时
+func (cfg startConfig) context() (context.Context, context.CancelFunc) {
+	if cfg.timeout > 0 {
+		return context.WithTimeout(context.Background(), cfg.timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// start 启动一次执行；id 为空时按 -id-template / workflowIdTemplate 渲染或自动生成
+func (cfg startConfig) start(ctx context.Context, c client.Client, wf dsl.Workflow, id string) (StartResult, client.WorkflowRun, error) {
+	id, err := cfg.workflowIDFor(wf, id)
+	if err != nil {
+		return StartResult{}, nil, err
+	}
+	// -id-reuse-policy 覆盖 YAML 的 idReusePolicy
+	reuse := cfg.reusePolicy
+	if reuse == enumspb.WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED {
+		if reuse, err = wf.StartReusePolicy(); err != nil {
+			return StartResult{}, nil, err
+		}
+	}
+	opts := client.StartWorkflowOptions{
+		ID:                       id,
+		TaskQueue:                wf.TaskQueue,
+		WorkflowIDReusePolicy:    reuse,
+		StartDelay:               time.Duration(wf.StartDelaySec) * time.Second,
+		WorkflowExecutionTimeout: time.Duration(wf.ExecutionTimeoutSec) * time.Second,
+		WorkflowRunTimeout:       time.Duration(wf.RunTimeoutSec) * time.Second,
+	}
+	if cfg.startDelay > 0 {
+		opts.StartDelay = cfg.startDelay
+	}
+	if cfg.executionTimeout > 0 {
+		opts.WorkflowExecutionTimeout = cfg.executionTimeout
+	}
+	if cfg.runTimeout > 0 {
+		opts.WorkflowRunTimeout = cfg.runTimeout
+	}
+	if len(cfg.memo) > 0 {
+		opts.Memo = cfg.memo
+	}
+	if len(cfg.attrs) > 0 {
+		sa, err := searchAttributes(cfg.attrs)
+		if err != nil {
+			return StartResult{}, nil, err
+		}
+		opts.TypedSearchAttributes = sa
+	}
+	if len(cfg.chaos) > 0 {
+		ctx = chaos.WithRules(ctx, cfg.chaos)
+	}
+	started := time.Now()
+	run, err := c.ExecuteWorkflow(ctx, opts, dsl.SimpleDSLWorkflow, wf)
+	if err != nil {
+		return StartResult{}, nil, err
+	}
+	return StartResult{WorkflowID: run.GetID(), RunID: run.GetRunID(), TaskQueue: wf.TaskQueue, Status: "Running", StartedAt: started.UTC()}, run, nil
+}
+
+// finish 记录等待结果后的状态与耗时
+func (res *StartResult) finish(out map[string]any, err error) {
+	res.DurationMs = time.Since(res.StartedAt).Milliseconds()
+	if err != nil {
+		res.Status, res.Error = "Failed", err.Error()
+		if f, ok := dsl.FailureOf(err); ok {
+			res.Failure = &f
+		}
+		return
+	}
+	res.Status, res.Result = "Completed", out
+}
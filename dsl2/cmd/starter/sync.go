@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/cmd/internal/conn"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"gopkg.in/yaml.v3"
+)
+
+// syncOwnerMemo 是 sync 创建的 Schedule 上记录所有者的 memo 键；只有所有者相同的 Schedule 才会被更新或删除
+const syncOwnerMemo = "dslSyncOwner"
+
+// syncFrontMatter 是同步目录中定义文件开头的 front-matter；没有 schedule 的文件被忽略：
+//
+//	---
+//	schedule:
+//	  id: nightly-report      # 默认取文件名
+//	  cron: ["0 2 * * *"]
+//	  every: 1h
+//	  overlap: Skip
+//	  paused: false
+//	  workflowId: report      # 启动的执行的 ID 前缀，默认为 id
+//	---
+//	version: "1.0"
+//	...
+type syncFrontMatter struct {
+	Schedule *syncSchedule `yaml:"schedule"`
+}
+
+type syncSchedule struct {
+	ID         string   `yaml:"id"`
+	Cron       []string `yaml:"cron"`
+	Every      string   `yaml:"every"`
+	Overlap    string   `yaml:"overlap"`
+	Paused     bool     `yaml:"paused"`
+	WorkflowID string   `yaml:"workflowId"`
+	Note       string   `yaml:"note"`
+}
+
+// SyncAction 是一轮同步中对一个 Schedule 的操作
+type SyncAction struct {
+	ScheduleID string `json:"scheduleId,omitempty"`
+	File       string `json:"file,omitempty"`
+	Action     string `json:"action"` // create/update/delete/error
+	Error      string `json:"error,omitempty"`
+}
+
+// SyncReport 是一轮同步的结构化输出
+type SyncReport struct {
+	Source    string       `json:"source"`
+	Revision  string       `json:"revision,omitempty"` // -git 模式下同步的提交
+	DryRun    bool         `json:"dryRun,omitempty"`
+	Actions   []SyncAction `json:"actions"`
+	Unchanged int          `json:"unchanged"`
+	Ignored   int          `json:"ignored"` // 没有 schedule front-matter 的文件
+	Errors    int          `json:"errors"`
+}
+
+// runSync 实现 `starter sync -dir <dir> | -git <url>`：让带 schedule front-matter 的定义文件与 Temporal Schedule 保持一致。
+// 文件新增或修改时创建/更新 Schedule，文件删除时删除其 Schedule；只处理本所有者（-owner）创建的 Schedule
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	dir := fs.String("dir", "", "Directory of workflow YAMLs to sync (searched recursively)")
+	gitURL := fs.String("git", "", "Git repository to clone and poll instead of -dir")
+	branch := fs.String("branch", "", "Branch to sync with -git (default the remote HEAD)")
+	gitPath := fs.String("path", "", "Subdirectory of the repository holding the YAMLs (with -git)")
+	checkout := fs.String("checkout", "", "Where to clone -git (default a temporary directory)")
+	interval := fs.Duration("interval", 30*time.Second, "Time between sync passes")
+	once := fs.Bool("once", false, "Run a single pass and exit (non-zero on errors)")
+	prune := fs.Bool("prune", true, "Delete managed schedules whose file is gone")
+	owner := fs.String("owner", "dsl-sync", "Owner recorded on created schedules; only schedules with this owner are updated or deleted")
+	dryRun := fs.Bool("dry-run", false, "Report the changes without applying them")
+	taskQueue := fs.String("q", "", "Override the YAML's taskQueue (default YAML.taskQueue, then the profile's taskQueue, then demo)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if (*dir == "") == (*gitURL == "") {
+		return errors.New("exactly one of -dir or -git is required")
+	}
+
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	dc, err := cf.CodecConfig.DataConverter()
+	if err != nil {
+		return err
+	}
+	if dc == nil {
+		dc = converter.GetDefaultDataConverter()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	src := syncSource{dir: *dir}
+	if *gitURL != "" {
+		src.git = &gitCheckout{url: *gitURL, branch: *branch, dir: *checkout}
+		if src.git.dir == "" {
+			tmp, err := os.MkdirTemp("", "dsl-sync-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmp)
+			src.git.dir = tmp
+		}
+		src.dir = filepath.Join(src.git.dir, *gitPath)
+	}
+	s := &syncer{c: c, dc: dc, owner: *owner, prune: *prune, dryRun: *dryRun, taskQueue: *taskQueue, applied: map[string]string{}}
+
+	for {
+		rep := s.pass(ctx, src)
+		emit(rep, func() {
+			for _, a := range rep.Actions {
+				switch {
+				case a.Error != "":
+					fmt.Printf("error %s: %s\n", strings.Trim(a.ScheduleID+" "+a.File, " "), a.Error)
+				case a.File != "":
+					fmt.Printf("%s %s (%s)\n", a.Action, a.ScheduleID, a.File)
+				default:
+					fmt.Printf("%s %s\n", a.Action, a.ScheduleID)
+				}
+			}
+			if *once || len(rep.Actions) > 0 {
+				fmt.Printf("%d change(s), %d unchanged, %d ignored, %d error(s)\n", len(rep.Actions)-rep.Errors, rep.Unchanged, rep.Ignored, rep.Errors)
+			}
+		})
+		if *once {
+			if rep.Errors > 0 {
+				return fmt.Errorf("%d error(s)", rep.Errors)
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// syncSource 是定义文件的来源：本地目录，或每轮先更新的 git 工作区
+type syncSource struct {
+	dir string
+	git *gitCheckout
+}
+
+// syncer 保存跨轮次的状态：applied 记录本进程已应用到各 Schedule 的文件内容摘要，内容不变时不再更新
+type syncer struct {
+	c         client.Client
+	dc        converter.DataConverter
+	owner     string
+	prune     bool
+	dryRun    bool
+	taskQueue string
+	applied   map[string]string
+}
+
+// desiredSchedule 是一个文件声明的 Schedule
+type desiredSchedule struct {
+	file  string
+	sched syncSchedule
+	wf    dsl.Workflow
+	hash  string
+}
+
+// pass 执行一轮同步。有文件无法解析时不删除任何 Schedule，避免把写错的文件当作已删除
+func (s *syncer) pass(ctx context.Context, src syncSource) SyncReport {
+	rep := SyncReport{Source: src.dir, DryRun: s.dryRun, Actions: []SyncAction{}}
+	fail := func(id, file string, err error) {
+		rep.Actions = append(rep.Actions, SyncAction{ScheduleID: id, File: file, Action: "error", Error: err.Error()})
+		rep.Errors++
+	}
+	if src.git != nil {
+		rev, err := src.git.update(ctx)
+		if err != nil {
+			fail("", src.git.url, err)
+			return rep
+		}
+		rep.Source, rep.Revision = src.git.url, rev
+	}
+
+	desired, ignored, loadErrs := s.load(src.dir)
+	rep.Ignored = ignored
+	for _, e := range loadErrs {
+		fail(e.ScheduleID, e.File, errors.New(e.Error))
+	}
+	existing, err := s.listManaged(ctx)
+	if err != nil {
+		fail("", "", fmt.Errorf("list schedules: %w", err))
+		return rep
+	}
+
+	ids := make([]string, 0, len(desired))
+	for id := range desired {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		d := desired[id]
+		owner, exists := existing[id]
+		switch {
+		case exists && owner != s.owner:
+			fail(id, d.file, fmt.Errorf("schedule exists and is not managed by owner %q", s.owner))
+			continue
+		case exists && s.applied[id] == d.hash:
+			rep.Unchanged++
+			continue
+		}
+		action := "create"
+		if exists {
+			action = "update"
+		}
+		if !s.dryRun {
+			if err := s.save(ctx, d, exists); err != nil {
+				fail(id, d.file, err)
+				continue
+			}
+			s.applied[id] = d.hash
+		}
+		rep.Actions = append(rep.Actions, SyncAction{ScheduleID: id, File: d.file, Action: action})
+	}
+
+	if !s.prune || len(loadErrs) > 0 {
+		return rep
+	}
+	var stale []string
+	for id, owner := range existing {
+		if _, ok := desired[id]; !ok && owner == s.owner {
+			stale = append(stale, id)
+		}
+	}
+	sort.Strings(stale)
+	for _, id := range stale {
+		if !s.dryRun {
+			if err := s.c.ScheduleClient().GetHandle(ctx, id).Delete(ctx); err != nil {
+				fail(id, "", err)
+				continue
+			}
+			delete(s.applied, id)
+		}
+		rep.Actions = append(rep.Actions, SyncAction{ScheduleID: id, Action: "delete"})
+	}
+	return rep
+}
+
+// load 读取目录下全部 YAML 文件（含子目录，跳过隐藏目录），返回按 Schedule ID 索引的声明与被忽略的文件数
+func (s *syncer) load(dir string) (map[string]desiredSchedule, int, []SyncAction) {
+	desired := map[string]desiredSchedule{}
+	ignored := 0
+	var errs []SyncAction
+	walkErr := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		d, ok, err := s.parseFile(path, rel)
+		switch {
+		case err != nil:
+			errs = append(errs, SyncAction{ScheduleID: d.sched.ID, File: rel, Action: "error", Error: err.Error()})
+		case !ok:
+			ignored++
+		case desired[d.sched.ID].file != "":
+			errs = append(errs, SyncAction{ScheduleID: d.sched.ID, File: rel, Action: "error",
+				Error: fmt.Sprintf("schedule id is also declared by %s", desired[d.sched.ID].file)})
+		default:
+			desired[d.sched.ID] = d
+		}
+		return nil
+	})
+	if walkErr != nil {
+		errs = append(errs, SyncAction{File: dir, Action: "error", Error: walkErr.Error()})
+	}
+	return desired, ignored, errs
+}
+
+// parseFile 拆分 front-matter 与定义并校验；ok 为 false 表示文件没有 schedule front-matter
+func (s *syncer) parseFile(path, rel string) (d desiredSchedule, ok bool, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return d, false, err
+	}
+	rest, found := bytes.CutPrefix(b, []byte("---\n"))
+	if !found {
+		return d, false, nil
+	}
+	front, body, found := bytes.Cut(rest, []byte("\n---\n"))
+	if !found {
+		return d, false, errors.New("unterminated front-matter")
+	}
+	var fm syncFrontMatter
+	if err := yaml.Unmarshal(front, &fm); err != nil {
+		return d, false, fmt.Errorf("front-matter: %w", err)
+	}
+	if fm.Schedule == nil {
+		return d, false, nil
+	}
+	d = desiredSchedule{file: rel, sched: *fm.Schedule}
+	if d.sched.ID == "" {
+		d.sched.ID = strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+	}
+	if len(d.sched.Cron) == 0 && d.sched.Every == "" {
+		return d, false, errors.New("schedule needs cron or every")
+	}
+	if _, err := parseOverlap(d.sched.Overlap); err != nil {
+		return d, false, err
+	}
+	if d.sched.Every != "" {
+		if every, err := time.ParseDuration(d.sched.Every); err != nil || every <= 0 {
+			return d, false, fmt.Errorf("schedule.every %q is not a positive duration", d.sched.Every)
+		}
+	}
+	if d.wf, err = dsl.ParseYAML(body); err != nil {
+		return d, false, fmt.Errorf("parse workflow: %w", err)
+	}
+	d.wf.TaskQueue = conn.ResolveTaskQueue(s.taskQueue, d.wf.TaskQueue)
+	if issues := d.wf.Check(dsl.CheckOptions{}); dsl.HasErrors(issues) {
+		return d, false, fmt.Errorf("invalid workflow: %v", issues)
+	}
+	sum := sha256.Sum256(append(b, d.wf.TaskQueue...))
+	d.hash = hex.EncodeToString(sum[:])
+	return d, true, nil
+}
+
+// listManaged 列出全部 Schedule 及其所有者（不是 sync 创建的为空串）
+func (s *syncer) listManaged(ctx context.Context) (map[string]string, error) {
+	it, err := s.c.ScheduleClient().List(ctx, client.ScheduleListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	for it.HasNext() {
+		e, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		owner := ""
+		if p := e.Memo.GetFields()[syncOwnerMemo]; p != nil {
+			_ = s.dc.FromPayload(p, &owner)
+		}
+		out[e.ID] = owner
+	}
+	return out, nil
+}
+
+// save 创建或整体更新一个 Schedule，暂停状态也与文件一致
+func (s *syncer) save(ctx context.Context, d desiredSchedule, exists bool) error {
+	spec := client.ScheduleSpec{CronExpressions: d.sched.Cron}
+	if d.sched.Every != "" {
+		every, _ := time.ParseDuration(d.sched.Every)
+		spec.Intervals = []client.ScheduleIntervalSpec{{Every: every}}
+	}
+	// 文件未指定时回到服务端默认的 Skip，删除 overlap 一行也会生效
+	overlap, _ := parseOverlap(d.sched.Overlap)
+	if overlap == enumspb.SCHEDULE_OVERLAP_POLICY_UNSPECIFIED {
+		overlap = enumspb.SCHEDULE_OVERLAP_POLICY_SKIP
+	}
+	prefix := d.sched.WorkflowID
+	if prefix == "" {
+		prefix = d.sched.ID
+	}
+	action := &client.ScheduleWorkflowAction{
+		ID:                       prefix,
+		Workflow:                 dsl.SimpleDSLWorkflow,
+		Args:                     []any{d.wf},
+		TaskQueue:                d.wf.TaskQueue,
+		WorkflowExecutionTimeout: time.Duration(d.wf.ExecutionTimeoutSec) * time.Second,
+		WorkflowRunTimeout:       time.Duration(d.wf.RunTimeoutSec) * time.Second,
+	}
+	note := d.sched.Note
+	if note == "" {
+		note = "synced from " + d.file
+	}
+	if !exists {
+		_, err := s.c.ScheduleClient().Create(ctx, client.ScheduleOptions{
+			ID:      d.sched.ID,
+			Spec:    spec,
+			Action:  action,
+			Overlap: overlap,
+			Paused:  d.sched.Paused,
+			Note:    note,
+			Memo:    map[string]any{syncOwnerMemo: s.owner},
+		})
+		return err
+	}
+
+	h := s.c.ScheduleClient().GetHandle(ctx, d.sched.ID)
+	err := h.Update(ctx, client.ScheduleUpdateOptions{
+		DoUpdate: func(in client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+			sched := in.Description.Schedule
+			sched.Spec, sched.Action = &spec, action
+			if sched.Policy != nil {
+				sched.Policy.Overlap = overlap
+			}
+			return &client.ScheduleUpdate{Schedule: &sched}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	desc, err := h.Describe(ctx)
+	if err != nil || desc.Schedule.State == nil {
+		return err
+	}
+	switch paused := desc.Schedule.State.Paused; {
+	case d.sched.Paused && !paused:
+		return h.Pause(ctx, client.SchedulePauseOptions{Note: note})
+	case !d.sched.Paused && paused:
+		return h.Unpause(ctx, client.ScheduleUnpauseOptions{Note: note})
+	}
+	return nil
+}
+
+// gitCheckout 是 -git 模式的本地工作区；每轮浅拉取远端分支并硬重置，本地修改会被丢弃
+type gitCheckout struct {
+	url, branch, dir string
+}
+
+// update 克隆或拉取仓库，返回当前提交
+func (g *gitCheckout) update(ctx context.Context) (string, error) {
+	if _, err := os.Stat(filepath.Join(g.dir, ".git")); err != nil {
+		args := []string{"clone", "--depth", "1"}
+		if g.branch != "" {
+			args = append(args, "--branch", g.branch)
+		}
+		if _, err := git(ctx, "", append(args, g.url, g.dir)...); err != nil {
+			return "", err
+		}
+	} else {
+		ref := g.branch
+		if ref == "" {
+			ref = "HEAD"
+		}
+		if _, err := git(ctx, g.dir, "fetch", "--depth", "1", "origin", ref); err != nil {
+			return "", err
+		}
+		if _, err := git(ctx, g.dir, "reset", "--hard", "FETCH_HEAD"); err != nil {
+			return "", err
+		}
+	}
+	return git(ctx, g.dir, "rev-parse", "HEAD")
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
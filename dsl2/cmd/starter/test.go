@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/simulate"
+)
+
+// TestReport 是 test 子命令的结构化输出，供 CI 解析
+type TestReport struct {
+	Files  []TestFile `json:"files"`
+	Passed int        `json:"passed"`
+	Failed int        `json:"failed"`
+	// Coverage 仅在 -cover 时输出，按被测定义汇总其全部用例
+	Coverage []TestCoverage `json:"coverage,omitempty"`
+}
+
+type TestCoverage struct {
+	Workflow string `json:"workflow"`
+	*simulate.Coverage
+}
+
+// TestFile 是一个测试来源；Error 非空时（无法加载或定义无效）其用例都未执行
+type TestFile struct {
+	File     string                `json:"file"`
+	Workflow string                `json:"workflow,omitempty"`
+	Error    string                `json:"error,omitempty"`
+	Cases    []simulate.CaseResult `json:"cases,omitempty"`
+}
+
+// runTest 实现 `starter test [-run regexp] [-v] [-cover] [-update] files...`：在本地测试环境中执行 *.test.yaml
+// 与定义文件 tests 段中的用例。参数可以是文件、目录或 glob，有失败时返回非零退出码
+func runTest(args []string) error {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	run := fs.String("run", "", "Only run tests whose name matches this regexp")
+	verbose := fs.Bool("v", false, "Also list passing tests")
+	cover := fs.Bool("cover", false, "Report which nodes and if branches the tests executed")
+	update := fs.Bool("update", false, "Rewrite the expect.snapshot files with the current results instead of comparing")
+	output := fs.String("output", outputText, "Output format: text/json/yaml")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := parseOutputFormat(*output); err != nil {
+		return err
+	}
+	filter, err := regexp.Compile(*run)
+	if err != nil {
+		return fmt.Errorf("-run: %w", err)
+	}
+	if fs.NArg() == 0 {
+		return errors.New("at least one file, directory or glob is required")
+	}
+	files, err := testFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	rep := TestReport{Files: []TestFile{}}
+	coverage := map[string]*simulate.Coverage{}
+	for _, path := range files {
+		tf := TestFile{File: path}
+		suite, wf, err := simulate.LoadSuite(path)
+		if err != nil {
+			tf.Error = err.Error()
+			rep.Failed++
+			rep.Files = append(rep.Files, tf)
+			continue
+		}
+		tf.Workflow = suite.Workflow
+		wfPath := suite.Workflow
+		if wfPath == "" {
+			wfPath = path
+		}
+		suite.Tests = filterTests(suite.Tests, filter)
+		if len(suite.Tests) == 0 {
+			continue
+		}
+		// 用例会模拟全部 Activity，因此不检查 Activity 是否已知
+		if issues := wf.Check(dsl.CheckOptions{}); dsl.HasErrors(issues) {
+			var msgs []string
+			for _, i := range issues {
+				if i.Severity == dsl.SeverityError {
+					msgs = append(msgs, i.String())
+				}
+			}
+			tf.Error = "invalid workflow: " + strings.Join(msgs, "; ")
+			rep.Failed += len(suite.Tests)
+			rep.Files = append(rep.Files, tf)
+			continue
+		}
+		var cov *simulate.Coverage
+		if *cover {
+			if cov = coverage[wfPath]; cov == nil {
+				// 定义已通过检查，构图不会失败
+				cov, _ = simulate.NewCoverage(wf)
+				coverage[wfPath] = cov
+				rep.Coverage = append(rep.Coverage, TestCoverage{Workflow: wfPath, Coverage: cov})
+			}
+		}
+		suite.UpdateSnapshots = *update
+		tf.Cases = suite.Run(wf, cov)
+		for _, c := range tf.Cases {
+			if c.Passed {
+				rep.Passed++
+			} else {
+				rep.Failed++
+			}
+		}
+		rep.Files = append(rep.Files, tf)
+	}
+	if len(rep.Files) == 0 {
+		return errors.New("no tests found")
+	}
+
+	emit(rep, func() {
+		for _, f := range rep.Files {
+			if f.Error != "" {
+				fmt.Printf("--- FAIL: %s\n    %s\n", f.File, f.Error)
+				continue
+			}
+			for _, c := range f.Cases {
+				switch {
+				case !c.Passed:
+					fmt.Printf("--- FAIL: %s: %s (%dms)\n", f.File, c.Name, c.DurationMs)
+					for _, msg := range c.Failures {
+						// 快照差异跨多行，逐行缩进
+						fmt.Printf("    %s\n", strings.ReplaceAll(msg, "\n", "\n    "))
+					}
+				case *verbose:
+					fmt.Printf("--- PASS: %s: %s (%dms)\n", f.File, c.Name, c.DurationMs)
+				}
+			}
+		}
+		for _, c := range rep.Coverage {
+			printCoverage(c.Workflow, c.Coverage)
+		}
+		fmt.Printf("%d passed, %d failed\n", rep.Passed, rep.Failed)
+	})
+	if rep.Failed > 0 {
+		return fmt.Errorf("%d test(s) failed", rep.Failed)
+	}
+	return nil
+}
+
+// testFiles 展开参数：目录取其中全部的 *.yaml/*.yml（含 *.test.yaml），否则同 batchFiles
+func testFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			for _, ext := range []string{"*.yaml", "*.yml"} {
+				m, _ := filepath.Glob(filepath.Join(arg, ext))
+				files = append(files, m...)
+			}
+			continue
+		}
+		m, err := batchFiles(arg)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, m...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func filterTests(tests []simulate.Case, filter *regexp.Regexp) []simulate.Case {
+	var out []simulate.Case
+	for i, c := range tests {
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("test %d", i+1)
+		}
+		if filter.MatchString(name) {
+			c.Name = name
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// printCoverage 打印覆盖率摘要，并列出未执行的节点与未选中的分支
+func printCoverage(name string, c *simulate.Coverage) {
+	fmt.Printf("coverage: %s: %d/%d nodes (%s), %d/%d branches (%s)\n", name,
+		c.NodesCovered, len(c.Nodes), percent(c.NodesCovered, len(c.Nodes)),
+		c.BranchesCovered, len(c.Branches), percent(c.BranchesCovered, len(c.Branches)))
+	nodes, branches := c.Uncovered()
+	for _, n := range nodes {
+		fmt.Printf("    not run: %s (%s)\n", n.ID, n.Label)
+	}
+	for _, b := range branches {
+		fmt.Printf("    branch not taken: %s %s\n", b.Node, b.Branch)
+	}
+}
+
+func percent(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// runTimeline 实现 `starter timeline -id <wfid> [-run-id r] [-o timeline.json]` 或 `-history history.json`：
+// 把执行的事件历史整理为按节点标注的时间线 JSON（耗时、重试、载荷大小），供外部工具绘制甘特图
+func runTimeline(args []string) error {
+	fs := flag.NewFlagSet("timeline", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	wfid := fs.String("id", "", "Workflow ID to fetch the history of")
+	runID := fs.String("run-id", "", "Run ID for -id (optional, default latest run)")
+	historyPath := fs.String("history", "", "Exported event history (JSON) instead of -id")
+	out := fs.String("o", "", "Write the timeline JSON to this file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if (*historyPath == "") == (*wfid == "") {
+		return errors.New("exactly one of -history or -id is required")
+	}
+	if err := parseOutputFormat(cf.output); err != nil {
+		return err
+	}
+	dc, err := cf.CodecConfig.DataConverter()
+	if err != nil {
+		return err
+	}
+
+	var history *historypb.History
+	if *historyPath != "" {
+		f, err := os.Open(*historyPath)
+		if err != nil {
+			return err
+		}
+		history, err = client.HistoryFromJSON(f, client.HistoryJSONOptions{})
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("parse history: %w", err)
+		}
+	} else if history, err = fetchHistory(cf, *wfid, *runID); err != nil {
+		return err
+	}
+	tl := dsl.BuildTimeline(history, dc)
+	tl.WorkflowID, tl.RunID = *wfid, *runID
+
+	if *out != "" {
+		bs, err := json.MarshalIndent(tl, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*out, append(bs, '\n'), 0o644); err != nil {
+			return err
+		}
+		infof("wrote %d nodes, %d activities, %d timers to %s\n", len(tl.Nodes), len(tl.Activities), len(tl.Timers), *out)
+		return nil
+	}
+	emit(tl, func() { printTimeline(tl) })
+	return nil
+}
+
+func printTimeline(tl dsl.Timeline) {
+	fmt.Printf("Status: %s", tl.Status)
+	if tl.End != nil {
+		fmt.Printf(" after %s", time.Duration(tl.DurationMs)*time.Millisecond)
+	}
+	fmt.Printf(" (%d events)\n", tl.Events)
+	for _, n := range tl.Nodes {
+		if n.Start == nil {
+			continue
+		}
+		depth := strings.Count(n.Path, ".")
+		line := fmt.Sprintf("%s%-*s %-9s +%-8s %8s", strings.Repeat("  ", depth), 36-2*depth, n.Path, n.Kind,
+			n.Start.Sub(tl.Start).Round(time.Millisecond), time.Duration(n.DurationMs)*time.Millisecond)
+		if n.Retries > 0 {
+			line += fmt.Sprintf("  retries=%d", n.Retries)
+		}
+		if n.Failed > 0 {
+			line += fmt.Sprintf("  failed=%d", n.Failed)
+		}
+		fmt.Println(line)
+	}
+}
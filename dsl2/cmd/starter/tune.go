@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"go.temporal.io/sdk/client"
+)
+
+// runTune 实现 `starter tune -id <wfid> [-path root[1]] [-concurrency 2] [-rate 30]`：
+// 调整运行中 Map 的并发窗口与速率；不带调整参数时只打印当前状态
+func runTune(args []string) error {
+	fs := flag.NewFlagSet("tune", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	wfid := fs.String("id", "", "Workflow ID (required)")
+	runID := fs.String("run-id", "", "Run ID (optional, default latest run)")
+	path := fs.String("path", "", "Map node path, e.g. root[1] (default: every running map)")
+	concurrency := fs.Int("concurrency", 0, "New concurrency window (0 = unchanged)")
+	rate := fs.Int("rate", -1, "Maximum iterations started per minute (0 = unlimited, -1 = unchanged)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *wfid == "" {
+		return errors.New("-id is required")
+	}
+	req := dsl.TuneRequest{Path: *path, Concurrency: *concurrency}
+	if *rate >= 0 {
+		req.RatePerMinute = rate
+	}
+	c, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	handle, err := c.UpdateWorkflow(context.Background(), client.UpdateWorkflowOptions{
+		WorkflowID:   *wfid,
+		RunID:        *runID,
+		UpdateName:   dsl.UpdateTune,
+		Args:         []any{req},
+		WaitForStage: client.WorkflowUpdateStageCompleted,
+	})
+	if err != nil {
+		return err
+	}
+	var maps []dsl.MapTuning
+	if err := handle.Get(context.Background(), &maps); err != nil {
+		return err
+	}
+	emit(maps, func() {
+		for _, m := range maps {
+			rate := "unlimited"
+			if m.RatePerMinute > 0 {
+				rate = fmt.Sprintf("%d/min", m.RatePerMinute)
+			}
+			fmt.Printf("%s: concurrency=%d rate=%s inflight=%d remaining=%d\n", m.Path, m.Concurrency, rate, m.Inflight, m.Remaining)
+		}
+	})
+	return nil
+}
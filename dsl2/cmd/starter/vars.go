@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// varFlags 收集可重复的 -var/-memo/-search-attr key=value；值按 YAML 标量解析（3、true、[a,b] 保持类型，需要字符串时加引号）
+type varFlags map[string]any
+
+func (v varFlags) String() string {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func (v varFlags) Set(s string) error {
+	key, raw, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	var val any
+	if err := yaml.Unmarshal([]byte(raw), &val); err != nil || raw == "" {
+		val = raw // 无法解析时按原样作为字符串
+	}
+	v[key] = val
+	return nil
+}
+
+// loadVarsFile 读取 JSON（或 YAML）对象形式的变量文件
+func loadVarsFile(path string) (map[string]any, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read vars file: %w", err)
+	}
+	var vars map[string]any
+	if err := yaml.Unmarshal(b, &vars); err != nil {
+		return nil, fmt.Errorf("parse vars file %s: %w", path, err)
+	}
+	return vars, nil
+}
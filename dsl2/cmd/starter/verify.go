@@ -0,0 +1,378 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// VerifyReport 是 verify 子命令的结构化输出
+type VerifyReport struct {
+	Old       string         `json:"old"`
+	New       string         `json:"new"`
+	Histories int            `json:"histories"`
+	Passed    int            `json:"passed"`
+	Failed    int            `json:"failed"`
+	Skipped   int            `json:"skipped"`
+	Results   []VerifyResult `json:"results"`
+}
+
+// 单个历史的比较结论
+const (
+	verifyOK         = "ok"         // 两个构建都能重放，且发出的命令相同
+	verifyDiverged   = "diverged"   // 命令序列不同
+	verifyRegression = "regression" // 旧构建能重放，新构建不能
+	verifySkipped    = "skipped"    // 旧构建本身不能重放，无法据此判断
+	verifyError      = "error"      // 无法运行重放
+)
+
+// VerifyResult 是一个历史在两个构建上的比较结果
+type VerifyResult struct {
+	History    string      `json:"history"`
+	Status     string      `json:"status"`
+	Commands   int         `json:"commands"`
+	OldError   string      `json:"oldError,omitempty"`
+	NewError   string      `json:"newError,omitempty"`
+	Divergence *Divergence `json:"divergence,omitempty"`
+}
+
+// Divergence 是两个命令序列第一个不同的位置；序列提前结束时对应一侧为空
+type Divergence struct {
+	Index int    `json:"index"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// runVerify 实现 `starter verify -old ./starter-v1 [-new ./starter-v2] histories/`：
+// 用两个构建分别重放一组导出的事件历史，比较各自发出的命令序列（含 Activity 参数、定时器时长等，
+// 这些差异 Temporal 的重放检查并不都能发现），在引擎改动上线前确认它与进行中的执行兼容。
+// 构建为空表示当前二进制；其他构建需支持 `replay -commands`
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	cf := addConnFlags(fs)
+	oldBin := fs.String("old", "", "starter binary of the build in production (required)")
+	newBin := fs.String("new", "", "starter binary of the candidate build (default this binary)")
+	parallel := fs.Int("parallel", 4, "Histories replayed concurrently")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := parseOutputFormat(cf.output); err != nil {
+		return err
+	}
+	if *oldBin == "" || fs.NArg() != 1 {
+		return errors.New("usage: starter verify -old <binary> [-new <binary>] <history.json|dir|glob>")
+	}
+	files, err := historyFiles(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	dc, err := cf.CodecConfig.DataConverter()
+	if err != nil {
+		return err
+	}
+	if dc == nil {
+		dc = converter.GetDefaultDataConverter()
+	}
+	// 外部构建使用相同的连接与编解码参数，以便解码加密或压缩的载荷
+	forward := forwardedFlags(fs)
+
+	rep := VerifyReport{Old: *oldBin, New: *newBin, Histories: len(files), Results: make([]VerifyResult, len(files))}
+	if rep.New == "" {
+		rep.New = "(this binary)"
+	}
+	sem := make(chan struct{}, max(*parallel, 1))
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			oldRes := replayWith(*oldBin, file, dc, forward)
+			newRes := replayWith(*newBin, file, dc, forward)
+			rep.Results[i] = compareReplays(file, oldRes, newRes)
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range rep.Results {
+		switch r.Status {
+		case verifyOK:
+			rep.Passed++
+		case verifySkipped:
+			rep.Skipped++
+		default:
+			rep.Failed++
+		}
+	}
+	emit(rep, func() {
+		for _, r := range rep.Results {
+			fmt.Printf("%-10s %s (%d commands)\n", strings.ToUpper(r.Status), r.History, r.Commands)
+			if r.Divergence != nil {
+				fmt.Printf("           command #%d: old %s, new %s\n", r.Divergence.Index, orNone(r.Divergence.Old), orNone(r.Divergence.New))
+			}
+			if r.OldError != "" {
+				fmt.Printf("           old: %s\n", r.OldError)
+			}
+			if r.NewError != "" {
+				fmt.Printf("           new: %s\n", r.NewError)
+			}
+		}
+		fmt.Printf("%d histories: %d passed, %d failed, %d skipped\n", rep.Histories, rep.Passed, rep.Failed, rep.Skipped)
+	})
+	if rep.Failed > 0 {
+		return fmt.Errorf("%d of %d histories are not replay-safe with %s", rep.Failed, rep.Histories, rep.New)
+	}
+	return nil
+}
+
+// historyFiles 展开历史语料：目录取其中的 *.json，否则按 glob 匹配
+func historyFiles(pattern string) ([]string, error) {
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		pattern = filepath.Join(pattern, "*.json")
+	}
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%q matched no files", pattern)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// forwardedFlags 返回命令行上显式给出的连接与编解码参数（-output 除外）
+func forwardedFlags(fs *flag.FlagSet) []string {
+	connFlagSet := flag.NewFlagSet("", flag.ContinueOnError)
+	addConnFlags(connFlagSet)
+	var out []string
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name != "output" && connFlagSet.Lookup(f.Name) != nil {
+			out = append(out, fmt.Sprintf("-%s=%s", f.Name, f.Value))
+		}
+	})
+	return out
+}
+
+// replayWith 用指定构建重放 file；bin 为空时在当前进程内重放
+func replayWith(bin, file string, dc converter.DataConverter, forward []string) ReplayResult {
+	res := ReplayResult{Source: file}
+	if bin == "" {
+		history, err := readHistory(file)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.Events = len(history.GetEvents())
+		if res.Commands, err = replayHistory(history, dc); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Replayed = true
+		}
+		return res
+	}
+
+	// 引擎把调试信息打印到 stdout，结果经文件传回
+	out, err := os.CreateTemp("", "dsl-verify-*.json")
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	out.Close()
+	defer os.Remove(out.Name())
+	cmdArgs := append([]string{"replay", "-history", file, "-commands", out.Name()}, forward...)
+	logs, runErr := exec.Command(bin, cmdArgs...).CombinedOutput()
+	bs, _ := os.ReadFile(out.Name())
+	if len(bs) == 0 {
+		res.Error = fmt.Sprintf("%s replay: %v: %s", bin, runErr, lastLine(logs))
+		return res
+	}
+	if err := json.Unmarshal(bs, &res); err != nil {
+		res.Error = fmt.Sprintf("%s replay: decode result: %v", bin, err)
+	}
+	return res
+}
+
+// compareReplays 得出一个历史的结论；无法运行重放的错误以 "error" 报告
+func compareReplays(file string, oldRes, newRes ReplayResult) VerifyResult {
+	r := VerifyResult{History: file, Commands: len(newRes.Commands), OldError: oldRes.Error, NewError: newRes.Error}
+	if i := firstDifference(oldRes.Commands, newRes.Commands); i >= 0 {
+		r.Divergence = &Divergence{Index: i, Old: at(oldRes.Commands, i), New: at(newRes.Commands, i)}
+	}
+	switch {
+	case oldRes.Events == 0 || newRes.Events == 0:
+		r.Status = verifyError
+	case !oldRes.Replayed:
+		r.Status = verifySkipped
+	case !newRes.Replayed:
+		r.Status = verifyRegression
+	case r.Divergence != nil:
+		r.Status = verifyDiverged
+	default:
+		r.Status = verifyOK
+	}
+	return r
+}
+
+func firstDifference(a, b []string) int {
+	for i := range max(len(a), len(b)) {
+		if at(a, i) != at(b, i) || i >= len(a) || i >= len(b) {
+			return i
+		}
+	}
+	return -1
+}
+
+func at(s []string, i int) string {
+	if i < len(s) {
+		return s[i]
+	}
+	return ""
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func lastLine(b []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	return lines[len(lines)-1]
+}
+
+// commandRecorder 在重放时按顺序记录工作流发出的命令及其关键参数。
+// 工作流协程按确定的顺序调度，同一历史在兼容的构建上应得到相同的序列
+type commandRecorder struct {
+	interceptor.WorkerInterceptorBase
+	mu       sync.Mutex
+	commands []string
+}
+
+func (r *commandRecorder) record(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = append(r.commands, fmt.Sprintf(format, args...))
+}
+
+func (r *commandRecorder) InterceptWorkflow(ctx workflow.Context, next interceptor.WorkflowInboundInterceptor) interceptor.WorkflowInboundInterceptor {
+	i := &recordingInbound{rec: r}
+	i.Next = next
+	return i
+}
+
+type recordingInbound struct {
+	interceptor.WorkflowInboundInterceptorBase
+	rec *commandRecorder
+}
+
+func (i *recordingInbound) Init(outbound interceptor.WorkflowOutboundInterceptor) error {
+	o := &recordingOutbound{rec: i.rec}
+	o.Next = outbound
+	return i.Next.Init(o)
+}
+
+type recordingOutbound struct {
+	interceptor.WorkflowOutboundInterceptorBase
+	rec *commandRecorder
+}
+
+func (o *recordingOutbound) ExecuteActivity(ctx workflow.Context, activityType string, args ...any) workflow.Future {
+	o.rec.record("ScheduleActivity %s %s", activityType, jsonArgs(args))
+	return o.Next.ExecuteActivity(ctx, activityType, args...)
+}
+
+func (o *recordingOutbound) ExecuteLocalActivity(ctx workflow.Context, activityType string, args ...any) workflow.Future {
+	o.rec.record("LocalActivity %s %s", activityType, jsonArgs(args))
+	return o.Next.ExecuteLocalActivity(ctx, activityType, args...)
+}
+
+func (o *recordingOutbound) ExecuteChildWorkflow(ctx workflow.Context, childWorkflowType string, args ...any) workflow.ChildWorkflowFuture {
+	o.rec.record("StartChildWorkflow %s %s", childWorkflowType, jsonArgs(args))
+	return o.Next.ExecuteChildWorkflow(ctx, childWorkflowType, args...)
+}
+
+func (o *recordingOutbound) NewTimer(ctx workflow.Context, d time.Duration) workflow.Future {
+	o.rec.record("StartTimer %s", d)
+	return o.Next.NewTimer(ctx, d)
+}
+
+func (o *recordingOutbound) NewTimerWithOptions(ctx workflow.Context, d time.Duration, options workflow.TimerOptions) workflow.Future {
+	o.rec.record("StartTimer %s", d)
+	return o.Next.NewTimerWithOptions(ctx, d, options)
+}
+
+func (o *recordingOutbound) Sleep(ctx workflow.Context, d time.Duration) error {
+	o.rec.record("StartTimer %s", d)
+	return o.Next.Sleep(ctx, d)
+}
+
+func (o *recordingOutbound) SideEffect(ctx workflow.Context, f func(ctx workflow.Context) any) converter.EncodedValue {
+	o.rec.record("SideEffect")
+	return o.Next.SideEffect(ctx, f)
+}
+
+func (o *recordingOutbound) MutableSideEffect(ctx workflow.Context, id string, f func(ctx workflow.Context) any, equals func(a, b any) bool) converter.EncodedValue {
+	o.rec.record("MutableSideEffect %s", id)
+	return o.Next.MutableSideEffect(ctx, id, f, equals)
+}
+
+func (o *recordingOutbound) GetVersion(ctx workflow.Context, changeID string, minSupported, maxSupported workflow.Version) workflow.Version {
+	o.rec.record("GetVersion %s %d..%d", changeID, minSupported, maxSupported)
+	return o.Next.GetVersion(ctx, changeID, minSupported, maxSupported)
+}
+
+func (o *recordingOutbound) SignalExternalWorkflow(ctx workflow.Context, workflowID, runID, signalName string, arg any) workflow.Future {
+	o.rec.record("SignalExternalWorkflow %s %s %s", workflowID, signalName, jsonArgs([]any{arg}))
+	return o.Next.SignalExternalWorkflow(ctx, workflowID, runID, signalName, arg)
+}
+
+func (o *recordingOutbound) RequestCancelExternalWorkflow(ctx workflow.Context, workflowID, runID string) workflow.Future {
+	o.rec.record("RequestCancelExternalWorkflow %s", workflowID)
+	return o.Next.RequestCancelExternalWorkflow(ctx, workflowID, runID)
+}
+
+func (o *recordingOutbound) UpsertSearchAttributes(ctx workflow.Context, attributes map[string]any) error {
+	o.rec.record("UpsertSearchAttributes %s", strings.Join(slices.Sorted(maps.Keys(attributes)), ","))
+	return o.Next.UpsertSearchAttributes(ctx, attributes)
+}
+
+func (o *recordingOutbound) UpsertTypedSearchAttributes(ctx workflow.Context, attributes ...temporal.SearchAttributeUpdate) error {
+	o.rec.record("UpsertSearchAttributes %d", len(attributes))
+	return o.Next.UpsertTypedSearchAttributes(ctx, attributes...)
+}
+
+func (o *recordingOutbound) UpsertMemo(ctx workflow.Context, memo map[string]any) error {
+	o.rec.record("UpsertMemo %s", strings.Join(slices.Sorted(maps.Keys(memo)), ","))
+	return o.Next.UpsertMemo(ctx, memo)
+}
+
+func (o *recordingOutbound) NewContinueAsNewError(ctx workflow.Context, wfn any, args ...any) error {
+	o.rec.record("ContinueAsNew %s", jsonArgs(args))
+	return o.Next.NewContinueAsNewError(ctx, wfn, args...)
+}
+
+// jsonArgs 以 JSON 记录参数，参数变化（如变量求值顺序改变）也视为分歧
+func jsonArgs(args []any) string {
+	bs, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprint(args)
+	}
+	return string(bs)
+}
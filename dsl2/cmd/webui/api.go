@@ -0,0 +1,282 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/convert/asl"
+)
+
+// apiVersionPrefix 是当前 REST API 的路径前缀；不带版本的 /api/* 作为兼容别名保留
+const apiVersionPrefix = "/api/v1"
+
+// route 描述一个 API 端点，同时用于注册路由和生成 OpenAPI 文档
+type route struct {
+	Method   string
+	Path     string     // 相对 apiVersionPrefix，如 "/workflow/execute"
+	Cap      Capability // 空表示只需认证
+	Handler  http.HandlerFunc
+	Summary  string
+	Query    []string // query 参数名
+	Request  any      // 请求体类型的零值；nil 表示无请求体
+	Response any      // 响应体类型的零值；string 表示 text/plain
+}
+
+func (s *Server) routes() []route {
+	return []route{
+		{Method: "GET", Path: "/me", Handler: s.handleMe,
+			Summary: "Current user, role and capabilities", Response: UserInfo{}},
+		{Method: "GET", Path: "/targets", Cap: CapView, Handler: s.handleTargets,
+			Summary: "Configured Temporal targets and namespaces", Response: []TargetInfo{}},
+		{Method: "POST", Path: "/workflow/execute", Cap: CapExecute, Handler: s.handleExecuteWorkflow,
+			Summary: "Validate and execute a workflow definition", Query: []string{"target", "namespace"},
+			Request: WorkflowRequest{}, Response: WorkflowResponse{}},
+		{Method: "POST", Path: "/workflow/validate", Cap: CapEdit, Handler: s.handleValidateWorkflow,
+			Summary: "List all validation errors and warnings of a definition", Request: WorkflowRequest{}, Response: ValidateResponse{}},
+		{Method: "POST", Path: "/workflow/migrate", Cap: CapEdit, Handler: s.handleMigrateWorkflow,
+			Summary: "Upgrade a definition from an older schema version to the current one", Request: MigrateRequest{}, Response: MigrateResponse{}},
+		{Method: "GET", Path: "/workflow/status", Cap: CapView, Handler: s.handleWorkflowStatus,
+			Summary: "Status of a workflow execution", Query: []string{"id", "runId", "target", "namespace"},
+			Response: WorkflowStatus{}},
+		{Method: "GET", Path: "/workflow/result", Cap: CapView, Handler: s.handleWorkflowResult,
+			Summary: "Download the bindings of a completed execution as JSON or YAML",
+			Query:   []string{"id", "runId", "format", "target", "namespace"}, Response: map[string]any{}},
+		{Method: "GET", Path: "/workflow/events", Cap: CapView, Handler: s.handleWorkflowEvents,
+			Summary: "WebSocket stream of node events (started/completed/failed) until the execution closes",
+			Query:   []string{"id", "runId", "target", "namespace", "access_token"}, Response: EventMessage{}},
+		{Method: "POST", Path: "/workflow/simulate", Cap: CapEdit, Handler: s.handleSimulateWorkflow,
+			Summary: "Dry-run a definition in an in-memory test environment with mocked activities",
+			Request: SimulateRequest{}, Response: SimulateResponse{}},
+		{Method: "POST", Path: "/workflow/graph", Cap: CapView, Handler: s.handleWorkflowGraph,
+			Summary: "Parse a definition into a node/edge graph", Request: WorkflowRequest{}, Response: GraphResponse{}},
+		{Method: "POST", Path: "/workflow/diagram", Cap: CapView, Handler: s.handleWorkflowDiagram,
+			Summary: "Render a definition as Mermaid or DOT", Query: []string{"format"},
+			Request: WorkflowRequest{}, Response: ""},
+		{Method: "POST", Path: "/workflow/compose", Cap: CapEdit, Handler: s.handleWorkflowCompose,
+			Summary: "Compose canonical YAML from a designer graph", Request: ComposeRequest{}, Response: ComposeResponse{}},
+		{Method: "POST", Path: "/workflow/import", Cap: CapEdit, Handler: s.handleImportWorkflow,
+			Summary: "Convert an AWS Step Functions (ASL) definition to DSL YAML", Query: []string{"format", "taskQueue"},
+			Request: asl.StateMachine{}, Response: ImportResponse{}},
+		{Method: "GET", Path: "/workflow/list", Cap: CapView, Handler: s.handleListWorkflows,
+			Summary:  "Workflow executions, newest first, with paging and filters",
+			Query:    []string{"pageSize", "pageToken", "q", "status", "startedAfter", "startedBefore", "target", "namespace"},
+			Response: WorkflowList{}},
+		{Method: "POST", Path: "/workflow/signal", Cap: CapSignal, Handler: s.handleSignalWorkflow,
+			Summary: "Signal a running workflow", Query: []string{"target", "namespace"},
+			Request: SignalRequest{}, Response: WorkflowResponse{}},
+		{Method: "POST", Path: "/workflow/terminate", Cap: CapTerminate, Handler: s.handleTerminateWorkflow,
+			Summary: "Terminate a running workflow", Query: []string{"target", "namespace"},
+			Request: TerminateRequest{}, Response: WorkflowResponse{}},
+		{Method: "POST", Path: "/workflow/tune", Cap: CapSignal, Handler: s.handleTuneWorkflow,
+			Summary: "Adjust the concurrency window and rate of running map nodes", Query: []string{"target", "namespace"},
+			Request: TuneRequest{}, Response: TuneResponse{}},
+		{Method: "GET", Path: "/workflow/timeline", Cap: CapView, Handler: s.handleWorkflowTimeline,
+			Summary: "Node-annotated timeline of an execution's history (timings, retries, payload sizes)",
+			Query:   []string{"id", "runId", "download", "target", "namespace"}, Response: dsl.Timeline{}},
+		{Method: "GET", Path: "/workflow/debug", Cap: CapView, Handler: s.handleWorkflowDebug,
+			Summary: "Debugger state: breakpoints and the halted nodes with their bindings", Query: []string{"id", "runId", "target", "namespace"},
+			Response: dsl.DebugState{}},
+		{Method: "POST", Path: "/workflow/breakpoints", Cap: CapSignal, Handler: s.handleWorkflowBreakpoints,
+			Summary: "Add or remove breakpoints of a running workflow", Query: []string{"target", "namespace"},
+			Request: BreakpointsRequest{}, Response: BreakpointsResponse{}},
+		{Method: "GET", Path: "/definition/list", Cap: CapView, Handler: s.handleListDefinitions,
+			Summary: "Saved definitions with revision and current editors", Query: []string{"session"},
+			Response: []Definition{}},
+		{Method: "GET", Path: "/definition/get", Cap: CapView, Handler: s.handleGetDefinition,
+			Summary: "A saved definition; the ETag header carries its revision", Query: []string{"name", "session"},
+			Response: Definition{}},
+		{Method: "POST", Path: "/definition/save", Cap: CapEdit, Handler: s.handleSaveDefinition,
+			Summary: "Save a definition if its revision (or If-Match) is current; 412 otherwise",
+			Request: SaveDefinitionRequest{}, Response: Definition{}},
+		{Method: "POST", Path: "/definition/editing", Cap: CapEdit, Handler: s.handleEditingDefinition,
+			Summary: "Editing heartbeat; returns the other users editing the same definition",
+			Request: EditingRequest{}, Response: EditingResponse{}},
+		{Method: "GET", Path: "/examples", Cap: CapView, Handler: s.handleExamples,
+			Summary: "Example definitions grouped by category", Response: []Example{}},
+	}
+}
+
+// apiHandler 注册所有路由（/api/v1/* 与兼容的 /api/*）以及 /api/openapi.json，并依次套上 CORS、请求体大小限制与认证中间件
+func (s *Server) apiHandler() http.Handler {
+	api := http.NewServeMux()
+	routes := s.routes()
+	for _, rt := range routes {
+		h := rt.Handler
+		if rt.Cap != "" {
+			h = s.require(rt.Cap, h)
+		}
+		api.HandleFunc(apiVersionPrefix+rt.Path, h)
+		api.HandleFunc("/api"+rt.Path, h)
+	}
+	spec := openAPIDocument(routes)
+	api.HandleFunc("/api/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, spec)
+	})
+	return s.cors(s.limitBody(s.requireAuth(api)))
+}
+
+/*
+   =============== OpenAPI 3 文档（由路由表与请求/响应结构体反射生成） ===============
+*/
+
+func openAPIDocument(routes []route) map[string]any {
+	gen := &schemaGen{components: map[string]any{}}
+	paths := map[string]any{}
+	for _, rt := range routes {
+		op := map[string]any{
+			"summary":     rt.Summary,
+			"operationId": operationID(rt),
+			"responses":   map[string]any{},
+		}
+		if rt.Cap != "" {
+			op["description"] = "Requires the `" + string(rt.Cap) + "` capability."
+		}
+		if len(rt.Query) > 0 {
+			params := make([]any, 0, len(rt.Query))
+			for _, q := range rt.Query {
+				params = append(params, map[string]any{
+					"name": q, "in": "query", "required": q == "id",
+					"schema": map[string]any{"type": "string"},
+				})
+			}
+			op["parameters"] = params
+		}
+		if rt.Request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": gen.schema(reflect.TypeOf(rt.Request))},
+				},
+			}
+		}
+		content := map[string]any{}
+		if _, ok := rt.Response.(string); ok {
+			content["text/plain"] = map[string]any{"schema": map[string]any{"type": "string"}}
+		} else if rt.Response != nil {
+			content["application/json"] = map[string]any{"schema": gen.schema(reflect.TypeOf(rt.Response))}
+		}
+		op["responses"] = map[string]any{
+			"200": map[string]any{"description": "OK", "content": content},
+			"401": map[string]any{"description": "Unauthenticated"},
+			"403": map[string]any{"description": "Missing capability"},
+		}
+		paths[apiVersionPrefix+rt.Path] = map[string]any{strings.ToLower(rt.Method): op}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "DSL Workflow Designer API",
+			"version": strings.TrimPrefix(apiVersionPrefix, "/api/"),
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": gen.components,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+				"basic":  map[string]any{"type": "http", "scheme": "basic"},
+			},
+		},
+		"security": []any{map[string]any{"bearer": []any{}}, map[string]any{"basic": []any{}}},
+	}
+}
+
+// operationID 由方法与路径生成，如 POST /workflow/execute -> postWorkflowExecute
+func operationID(rt route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(rt.Method))
+	for _, part := range strings.Split(rt.Path, "/") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// schemaGen 把 Go 类型转换为 JSON Schema；具名结构体放入 components 并以 $ref 引用（支持递归类型）
+type schemaGen struct {
+	components map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Interface:
+		return map[string]any{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := t.Name()
+		if _, ok := g.components[name]; !ok {
+			g.components[name] = map[string]any{} // 占位，防止递归类型无限展开
+			g.components[name] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	g.fields(t, props, &required)
+	out := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		sort.Strings(required)
+		out["required"] = required
+	}
+	return out
+}
+
+func (g *schemaGen) fields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, props, required)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// 模板与静态资源默认编译进二进制，部署时只需单个可执行文件
+//
+//go:embed templates static
+var embeddedAssets embed.FS
+
+// assets 提供页面模板与静态文件；dev 模式下每次请求都从磁盘读取，便于前端调试
+type assets struct {
+	fsys  fs.FS
+	dev   bool
+	index *template.Template
+}
+
+// newAssets 在 dir 非空时从磁盘加载（dev 模式），否则使用嵌入的资源
+func newAssets(dir string) (*assets, error) {
+	a := &assets{fsys: embeddedAssets}
+	if dir != "" {
+		if _, err := os.Stat(filepath.Join(dir, "templates")); err != nil {
+			return nil, err
+		}
+		a.fsys, a.dev = os.DirFS(dir), true
+		return a, nil
+	}
+	t, err := template.ParseFS(a.fsys, "templates/index.html")
+	if err != nil {
+		return nil, err
+	}
+	a.index = t
+	return a, nil
+}
+
+func (a *assets) indexTemplate() (*template.Template, error) {
+	if a.dev {
+		return template.ParseFS(a.fsys, "templates/index.html")
+	}
+	return a.index, nil
+}
+
+// staticHandler 服务 /static/ 下的文件
+func (a *assets) staticHandler() http.Handler {
+	sub, err := fs.Sub(a.fsys, "static")
+	if err != nil {
+		// static 目录在嵌入时已保证存在；dev 模式目录缺失时返回 404
+		return http.NotFoundHandler()
+	}
+	return http.StripPrefix("/static/", http.FileServer(http.FS(sub)))
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// Identity 是通过认证的调用方
+type Identity struct {
+	Subject string `json:"subject"`
+	Method  string `json:"method"` // none / token / basic / oidc
+}
+
+// Authenticator 从请求中识别调用方；无法识别时返回 errUnauthenticated
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Identity, error)
+	// Challenge 用于 401 响应的 WWW-Authenticate 头
+	Challenge() string
+}
+
+var errUnauthenticated = errors.New("unauthenticated")
+
+type identityKey struct{}
+
+// identityFrom 取出中间件写入的 Identity；未经过认证中间件时返回 nil
+func identityFrom(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
+
+// requireAuth 是 /api 路由的认证中间件
+func (s *Server) requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := s.auth.Authenticate(r)
+		if err != nil {
+			if c := s.auth.Challenge(); c != "" {
+				w.Header().Set("WWW-Authenticate", c)
+			}
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
+	})
+}
+
+// newAuthenticator 按 -auth 模式构建认证器
+func newAuthenticator(cfg authConfig) (Authenticator, error) {
+	switch cfg.Mode {
+	case "", "none":
+		return noAuth{}, nil
+	case "token":
+		tokens, err := parsePairs(cfg.Tokens, "=")
+		if err != nil {
+			return nil, fmt.Errorf("auth tokens: %w", err)
+		}
+		// 配置格式为 user=token，查找时按 token 反查
+		byToken := make(map[string]string, len(tokens))
+		for user, tok := range tokens {
+			byToken[tok] = user
+		}
+		return &tokenAuth{tokens: byToken}, nil
+	case "basic":
+		users, err := parsePairs(cfg.Users, ":")
+		if err != nil {
+			return nil, fmt.Errorf("auth users: %w", err)
+		}
+		return &basicAuth{users: users}, nil
+	case "oidc":
+		if cfg.OIDCIssuer == "" {
+			return nil, errors.New("oidc issuer required")
+		}
+		claim := cfg.OIDCUserClaim
+		if claim == "" {
+			claim = "email"
+		}
+		return &oidcAuth{
+			issuer:    strings.TrimRight(cfg.OIDCIssuer, "/"),
+			audience:  cfg.OIDCClientID,
+			userClaim: claim,
+			http:      &http.Client{Timeout: 10 * time.Second},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q (none/token/basic/oidc)", cfg.Mode)
+	}
+}
+
+type authConfig struct {
+	Mode          string
+	Tokens        string // user=token,user2=token2
+	Users         string // user:password,user2:password2
+	OIDCIssuer    string
+	OIDCClientID  string
+	OIDCUserClaim string
+}
+
+// parsePairs 解析 "k<sep>v,k2<sep>v2" 形式的列表
+func parsePairs(s, sep string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(item, sep)
+		if !ok || k == "" || v == "" {
+			return nil, fmt.Errorf("invalid entry %q, want key%svalue", item, sep)
+		}
+		out[k] = v
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no entries configured")
+	}
+	return out, nil
+}
+
+// ----- none -----
+
+type noAuth struct{}
+
+func (noAuth) Authenticate(*http.Request) (*Identity, error) {
+	return &Identity{Subject: "anonymous", Method: "none"}, nil
+}
+
+func (noAuth) Challenge() string { return "" }
+
+// ----- 静态 token -----
+
+type tokenAuth struct {
+	tokens map[string]string // token -> user
+}
+
+func (a *tokenAuth) Authenticate(r *http.Request) (*Identity, error) {
+	tok := bearerToken(r)
+	if tok == "" {
+		return nil, errUnauthenticated
+	}
+	for known, user := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(tok), []byte(known)) == 1 {
+			return &Identity{Subject: user, Method: "token"}, nil
+		}
+	}
+	return nil, errUnauthenticated
+}
+
+func (a *tokenAuth) Challenge() string { return `Bearer realm="dsl-webui"` }
+
+// ----- Basic Auth -----
+
+type basicAuth struct {
+	users map[string]string // user -> password
+}
+
+func (a *basicAuth) Authenticate(r *http.Request) (*Identity, error) {
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		return nil, errUnauthenticated
+	}
+	want, found := a.users[user]
+	// 用户不存在时也做一次比较，避免时序差异
+	if subtle.ConstantTimeCompare([]byte(pass), []byte(want)) != 1 || !found {
+		return nil, errUnauthenticated
+	}
+	return &Identity{Subject: user, Method: "basic"}, nil
+}
+
+func (a *basicAuth) Challenge() string { return `Basic realm="dsl-webui"` }
+
+// ----- OIDC -----
+// 校验 Bearer 中的 JWT（ID Token 或 JWT 形式的 Access Token），签名公钥来自 issuer 的 JWKS
+
+type oidcAuth struct {
+	issuer    string
+	audience  string
+	userClaim string
+	http      *http.Client
+
+	mu        sync.Mutex
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+	// attemptAt 与 fetchErr 记录最近一次拉取，限制未知 kid 与 IdP 故障时的拉取频率
+	attemptAt time.Time
+	fetchErr  error
+	fetch     singleflight.Group
+}
+
+const (
+	jwksRefreshInterval = 10 * time.Minute
+	// jwksRetryInterval 是未知 kid 或拉取失败后再次拉取的最短间隔
+	jwksRetryInterval = time.Minute
+)
+
+func (a *oidcAuth) Authenticate(r *http.Request) (*Identity, error) {
+	raw := bearerToken(r)
+	if raw == "" {
+		return nil, errUnauthenticated
+	}
+	tok, err := jwt.ParseSigned(raw)
+	if err != nil || len(tok.Headers) == 0 {
+		return nil, errUnauthenticated
+	}
+	kid := tok.Headers[0].KeyID
+	keys, err := a.keySet(r.Context(), kid)
+	if err != nil {
+		return nil, err
+	}
+	matched := keys.Key(kid)
+	if len(matched) == 0 {
+		return nil, errUnauthenticated
+	}
+
+	var std jwt.Claims
+	var extra map[string]any
+	if err := tok.Claims(matched[0].Key, &std, &extra); err != nil {
+		return nil, errUnauthenticated
+	}
+	expected := jwt.Expected{Issuer: a.issuer, Time: time.Now()}
+	if a.audience != "" {
+		expected.Audience = jwt.Audience{a.audience}
+	}
+	if err := std.ValidateWithLeeway(expected, time.Minute); err != nil {
+		return nil, errUnauthenticated
+	}
+
+	subject := std.Subject
+	if v, ok := extra[a.userClaim].(string); ok && v != "" {
+		subject = v
+	}
+	return &Identity{Subject: subject, Method: "oidc"}, nil
+}
+
+func (a *oidcAuth) Challenge() string { return `Bearer realm="dsl-webui"` }
+
+// keySet 返回缓存的 JWKS；缓存过期时重新拉取，遇到未知 kid 时最多每 jwksRetryInterval 拉取一次，
+// 期间返回现有的 key set（调用方按未知 key 拒绝）。拉取在锁外进行，并发请求共享同一次拉取
+func (a *oidcAuth) keySet(ctx context.Context, kid string) (*jose.JSONWebKeySet, error) {
+	a.mu.Lock()
+	keys, ok, err := a.cachedLocked(kid)
+	a.mu.Unlock()
+	if ok {
+		return keys, err
+	}
+	v, err, _ := a.fetch.Do("jwks", func() (any, error) {
+		a.mu.Lock()
+		if keys, ok, err := a.cachedLocked(kid); ok {
+			a.mu.Unlock()
+			return keys, err
+		}
+		a.attemptAt = time.Now()
+		a.mu.Unlock()
+
+		// 结果由并发请求共享，不随第一个请求取消
+		keys, err := a.fetchKeys(context.WithoutCancel(ctx))
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.fetchErr = err; err != nil {
+			return nil, err
+		}
+		a.keys, a.fetchedAt = keys, time.Now()
+		return keys, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*jose.JSONWebKeySet), nil
+}
+
+// cachedLocked 在无需拉取时返回 ok；调用方持有 a.mu
+func (a *oidcAuth) cachedLocked(kid string) (*jose.JSONWebKeySet, bool, error) {
+	fresh := a.keys != nil && time.Since(a.fetchedAt) < jwksRefreshInterval
+	recent := !a.attemptAt.IsZero() && time.Since(a.attemptAt) < jwksRetryInterval
+	switch {
+	case fresh && len(a.keys.Key(kid)) > 0:
+		return a.keys, true, nil
+	case recent && a.fetchErr != nil:
+		return nil, true, a.fetchErr
+	case recent && fresh:
+		return a.keys, true, nil
+	}
+	return nil, false, nil
+}
+
+func (a *oidcAuth) fetchKeys(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := a.getJSON(ctx, a.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	var keys jose.JSONWebKeySet
+	if err := a.getJSON(ctx, discovery.JWKSURI, &keys); err != nil {
+		return nil, fmt.Errorf("oidc jwks: %w", err)
+	}
+	return &keys, nil
+}
+
+func (a *oidcAuth) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	// 浏览器的 WebSocket 无法设置请求头，仅握手请求允许通过 access_token 参数传递令牌
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return r.URL.Query().Get("access_token")
+	}
+	return ""
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+// editingTTL 内没有心跳的编辑者视为已离开
+const editingTTL = 30 * time.Second
+
+// 编辑心跳的会话 ID 长度与每个定义同时在编辑的会话数上限
+const (
+	maxEditingSessionLen = 64
+	maxEditingSessions   = 32
+)
+
+var definitionNameRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// Definition 是保存在服务端的一份工作流定义；Revision 每次保存加一，同时作为 ETag
+type Definition struct {
+	Name      string    `json:"name"`
+	YAML      string    `json:"yaml,omitempty"`
+	Revision  int64     `json:"revision"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	// Editors 是最近发送过编辑心跳的用户（不含请求者自己的会话）
+	Editors []string `json:"editors,omitempty"`
+}
+
+type SaveDefinitionRequest struct {
+	Name string `json:"name"`
+	YAML string `json:"yaml"`
+	// Revision 是编辑开始时加载的版本；新建时为 0。请求头 If-Match 优先
+	Revision int64 `json:"revision"`
+}
+
+// DefinitionConflict 是保存冲突（412）时的响应，Current 为服务端当前版本
+type DefinitionConflict struct {
+	Error   string     `json:"error"`
+	Current Definition `json:"current"`
+}
+
+type EditingRequest struct {
+	Name    string `json:"name"`
+	Session string `json:"session"`        // 浏览器标签页生成的随机 ID，区分同一用户的多个窗口
+	Done    bool   `json:"done,omitempty"` // 关闭或切换定义时发送，立即移除
+}
+
+type EditingResponse struct {
+	Editors []string `json:"editors"`
+}
+
+// errRevisionConflict 表示保存时基于的版本已不是最新
+type errRevisionConflict struct {
+	current Definition
+}
+
+func (e *errRevisionConflict) Error() string {
+	if e.current.Revision == 0 {
+		return "definition was deleted since it was loaded"
+	}
+	return fmt.Sprintf("definition was changed by %s at %s (revision %d)",
+		e.current.UpdatedBy, e.current.UpdatedAt.Format(time.RFC3339), e.current.Revision)
+}
+
+var (
+	errDefinitionNotFound = errors.New("definition not found")
+	errTooManyEditors     = fmt.Errorf("too many sessions are editing this definition (limit %d)", maxEditingSessions)
+)
+
+// definitionStore 保存定义并跟踪正在编辑的用户；dir 为空时只保存在内存中
+type definitionStore struct {
+	dir string
+
+	mu      sync.Mutex
+	defs    map[string]*Definition
+	editing map[string]map[string]editor // name -> session -> editor
+}
+
+type editor struct {
+	subject string
+	seen    time.Time
+}
+
+// 磁盘上每个定义对应 <name>.yaml 与记录版本信息的 <name>.meta.json
+type definitionMeta struct {
+	Revision  int64     `json:"revision"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+}
+
+func newDefinitionStore(dir string) (*definitionStore, error) {
+	st := &definitionStore{dir: dir, defs: map[string]*Definition{}, editing: map[string]map[string]editor{}}
+	if dir == "" {
+		return st, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), ".yaml")
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		d := &Definition{Name: name, YAML: string(b), Revision: 1}
+		if mb, err := os.ReadFile(st.metaPath(name)); err == nil {
+			var m definitionMeta
+			if err := json.Unmarshal(mb, &m); err != nil {
+				return nil, fmt.Errorf("%s: %w", st.metaPath(name), err)
+			}
+			d.Revision, d.UpdatedAt, d.UpdatedBy = m.Revision, m.UpdatedAt, m.UpdatedBy
+		} else if info, err := os.Stat(f); err == nil {
+			// 手工放入目录、没有元数据的文件从版本 1 开始
+			d.UpdatedAt = info.ModTime()
+		}
+		st.defs[name] = d
+	}
+	return st, nil
+}
+
+func (st *definitionStore) metaPath(name string) string {
+	return filepath.Join(st.dir, name+".meta.json")
+}
+
+// Check 供 /readyz 使用：磁盘模式下目录必须存在且可访问
+func (st *definitionStore) Check() error {
+	if st.dir == "" {
+		return nil
+	}
+	info, err := os.Stat(st.dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", st.dir)
+	}
+	return nil
+}
+
+// List 返回按名称排序的定义（不含 YAML 正文）
+func (st *definitionStore) List(session string) []Definition {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	out := make([]Definition, 0, len(st.defs))
+	for _, d := range st.defs {
+		cp := *d
+		cp.YAML = ""
+		cp.Editors = st.editorsLocked(d.Name, session)
+		out = append(out, cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (st *definitionStore) Get(name, session string) (Definition, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	d, ok := st.defs[name]
+	if !ok {
+		return Definition{}, errDefinitionNotFound
+	}
+	cp := *d
+	cp.Editors = st.editorsLocked(name, session)
+	return cp, nil
+}
+
+// Save 仅当 base 等于当前版本（新建时为 0）才写入，否则返回 *errRevisionConflict
+func (st *definitionStore) Save(name, text string, base int64, by string) (Definition, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var current Definition
+	if d, ok := st.defs[name]; ok {
+		current = *d
+		current.YAML = ""
+	}
+	if base != current.Revision {
+		return Definition{}, &errRevisionConflict{current: current}
+	}
+	d := &Definition{Name: name, YAML: text, Revision: base + 1, UpdatedAt: time.Now().UTC(), UpdatedBy: by}
+	if st.dir != "" {
+		meta, err := json.Marshal(definitionMeta{Revision: d.Revision, UpdatedAt: d.UpdatedAt, UpdatedBy: d.UpdatedBy})
+		if err != nil {
+			return Definition{}, err
+		}
+		if err := writeFileAtomic(filepath.Join(st.dir, name+".yaml"), []byte(text)); err != nil {
+			return Definition{}, err
+		}
+		if err := writeFileAtomic(st.metaPath(name), meta); err != nil {
+			return Definition{}, err
+		}
+	}
+	st.defs[name] = d
+	return *d, nil
+}
+
+// Touch 记录编辑心跳（done 为 true 时移除），返回其他正在编辑的用户。
+// 只能编辑已保存的定义；每次心跳顺带清理所有定义上过期的会话
+func (st *definitionStore) Touch(name, session, subject string, done bool) ([]string, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.pruneEditingLocked()
+	if done {
+		delete(st.editing[name], session)
+		if len(st.editing[name]) == 0 {
+			delete(st.editing, name)
+		}
+		return st.editorsLocked(name, session), nil
+	}
+	if _, ok := st.defs[name]; !ok {
+		return nil, errDefinitionNotFound
+	}
+	sessions := st.editing[name]
+	if sessions == nil {
+		sessions = map[string]editor{}
+		st.editing[name] = sessions
+	}
+	if _, ok := sessions[session]; !ok && len(sessions) >= maxEditingSessions {
+		return nil, errTooManyEditors
+	}
+	sessions[session] = editor{subject: subject, seen: time.Now()}
+	return st.editorsLocked(name, session), nil
+}
+
+func (st *definitionStore) pruneEditingLocked() {
+	for name, sessions := range st.editing {
+		for id, e := range sessions {
+			if time.Since(e.seen) > editingTTL {
+				delete(sessions, id)
+			}
+		}
+		if len(sessions) == 0 {
+			delete(st.editing, name)
+		}
+	}
+}
+
+// editorsLocked 清理过期会话并返回除 session 以外的编辑者（同一用户只列一次）
+func (st *definitionStore) editorsLocked(name, session string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for id, e := range st.editing[name] {
+		if time.Since(e.seen) > editingTTL {
+			delete(st.editing[name], id)
+			continue
+		}
+		if id != session && !seen[e.subject] {
+			seen[e.subject] = true
+			out = append(out, e.subject)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func writeFileAtomic(path string, b []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func revisionETag(rev int64) string {
+	return `"` + strconv.FormatInt(rev, 10) + `"`
+}
+
+// parseIfMatch 解析 If-Match 中的版本号；ok 为 false 表示未提供
+func parseIfMatch(h string) (rev int64, ok bool, err error) {
+	h = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(h), "W/"))
+	if h == "" {
+		return 0, false, nil
+	}
+	rev, err = strconv.ParseInt(strings.Trim(h, `"`), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid If-Match %q", h)
+	}
+	return rev, true, nil
+}
+
+func subjectOf(r *http.Request) string {
+	if id := identityFrom(r.Context()); id != nil {
+		return id.Subject
+	}
+	return ""
+}
+
+func (s *Server) handleListDefinitions(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, s.definitions.List(r.URL.Query().Get("session")))
+}
+
+func (s *Server) handleGetDefinition(w http.ResponseWriter, r *http.Request) {
+	d, err := s.definitions.Get(r.URL.Query().Get("name"), r.URL.Query().Get("session"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("ETag", revisionETag(d.Revision))
+	respondJSON(w, d)
+}
+
+// handleSaveDefinition 以乐观锁保存：基于的版本不是最新时返回 412 与当前版本信息，由前端决定重新加载或覆盖
+func (s *Server) handleSaveDefinition(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req SaveDefinitionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if !definitionNameRE.MatchString(req.Name) {
+		http.Error(w, "name must be 1-128 letters, digits, '.', '_' or '-'", http.StatusBadRequest)
+		return
+	}
+	// 允许保存未通过校验的草稿，但必须是可解析的 YAML
+	if _, err := dsl.ParseYAML([]byte(req.YAML)); err != nil {
+		http.Error(w, fmt.Sprintf("YAML parsing error: %v", err), http.StatusBadRequest)
+		return
+	}
+	base := req.Revision
+	if rev, ok, err := parseIfMatch(r.Header.Get("If-Match")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if ok {
+		base = rev
+	}
+
+	d, err := s.definitions.Save(req.Name, req.YAML, base, subjectOf(r))
+	var conflict *errRevisionConflict
+	switch {
+	case errors.As(err, &conflict):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		respondJSON(w, DefinitionConflict{Error: conflict.Error(), Current: conflict.current})
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", revisionETag(d.Revision))
+	d.YAML = ""
+	respondJSON(w, d)
+}
+
+// handleEditingDefinition 接收编辑心跳并返回同时在编辑的其他用户
+func (s *Server) handleEditingDefinition(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req EditingRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if !definitionNameRE.MatchString(req.Name) {
+		http.Error(w, "name must be 1-128 letters, digits, '.', '_' or '-'", http.StatusBadRequest)
+		return
+	}
+	if req.Session == "" || len(req.Session) > maxEditingSessionLen {
+		http.Error(w, fmt.Sprintf("session must be 1-%d characters", maxEditingSessionLen), http.StatusBadRequest)
+		return
+	}
+	editors, err := s.definitions.Touch(req.Name, req.Session, subjectOf(r), req.Done)
+	switch {
+	case errors.Is(err, errDefinitionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if editors == nil {
+		editors = []string{}
+	}
+	respondJSON(w, EditingResponse{Editors: editors})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"go.temporal.io/sdk/client"
+	"golang.org/x/net/websocket"
+)
+
+// 节点事件推送：轮询引擎的 trace 查询，把新事件逐条写入 WebSocket
+const (
+	eventsPollInterval = time.Second
+	eventsWriteTimeout = 10 * time.Second
+)
+
+// EventMessage 是 /workflow/events 推送的一条消息：
+// type=node 携带一个节点事件；type=progress 在一批新事件之后携带进度（完成比例与剩余时间估计）；
+// type=closed 表示执行结束，附带最终状态与结果；type=error 表示无法继续推送
+type EventMessage struct {
+	Type     string           `json:"type"`
+	Event    *dsl.TraceEvent  `json:"event,omitempty"`
+	Progress *dsl.Progress    `json:"progress,omitempty"`
+	Status   string           `json:"status,omitempty"`
+	Result   any              `json:"result,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Failure  *dsl.NodeFailure `json:"failure,omitempty"`
+}
+
+// handleWorkflowEvents 把执行中的节点事件（started/completed/failed 及耗时）通过 WebSocket 推送给前端
+func (s *Server) handleWorkflowEvents(w http.ResponseWriter, r *http.Request) {
+	workflowID := r.URL.Query().Get("id")
+	if workflowID == "" {
+		http.Error(w, "Missing workflow ID", http.StatusBadRequest)
+		return
+	}
+	c, err := s.clientFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c == nil {
+		http.Error(w, "No Temporal connection available", http.StatusServiceUnavailable)
+		return
+	}
+	runID := r.URL.Query().Get("runId")
+	websocket.Server{
+		Handshake: s.checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			streamEvents(ws, c, workflowID, runID)
+		},
+	}.ServeHTTP(w, r)
+}
+
+// checkOrigin 只接受同源或 -cors-origins 允许的来源；非浏览器客户端不带 Origin
+func (s *Server) checkOrigin(cfg *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if u.Host != r.Host && !s.originAllowed(origin) {
+		return websocket.ErrBadWebSocketOrigin
+	}
+	cfg.Origin = u
+	return nil
+}
+
+func streamEvents(ws *websocket.Conn, c client.Client, workflowID, runID string) {
+	// 连接已被接管，清除 http.Server 的读写超时；每次写入单独设置超时
+	ws.SetDeadline(time.Time{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// 客户端断开时 Receive 返回错误
+	go func() {
+		var discard string
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+		cancel()
+	}()
+	send := func(msg EventMessage) bool {
+		ws.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+		return websocket.JSON.Send(ws, msg) == nil
+	}
+
+	last := 0
+	flush := func() bool {
+		v, err := c.QueryWorkflow(ctx, workflowID, runID, dsl.QueryTrace)
+		if err != nil {
+			return true // 尚未开始执行的工作流无法查询，下一轮重试
+		}
+		var events []dsl.TraceEvent
+		if err := v.Get(&events); err != nil {
+			return true
+		}
+		prev := last
+		for i := range events {
+			if events[i].Seq <= last {
+				continue
+			}
+			if !send(EventMessage{Type: "node", Event: &events[i]}) {
+				return false
+			}
+			last = events[i].Seq
+		}
+		if last == prev {
+			return true
+		}
+		v, err = c.QueryWorkflow(ctx, workflowID, runID, dsl.QueryProgress)
+		if err != nil {
+			return true
+		}
+		var p dsl.Progress
+		if err := v.Get(&p); err != nil {
+			return true
+		}
+		return send(EventMessage{Type: "progress", Progress: &p})
+	}
+
+	ticker := time.NewTicker(eventsPollInterval)
+	defer ticker.Stop()
+	for {
+		st, err := describeWorkflow(ctx, c, workflowID, runID)
+		if err != nil {
+			send(EventMessage{Type: "error", Error: err.Error()})
+			return
+		}
+		runID = st.RunID
+		if !flush() {
+			return
+		}
+		if st.CloseTime != nil {
+			send(EventMessage{Type: "closed", Status: st.Status, Result: st.Result, Error: st.Error, Failure: st.Failure})
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
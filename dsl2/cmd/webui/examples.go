@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 内置示例；-examples-dir 指向磁盘目录时改为从磁盘加载并热更新
+//
+//go:embed examples
+var embeddedExamples embed.FS
+
+// Example 是一个示例定义，元数据来自文件开头的 front-matter：
+//
+//	---
+//	name: Basic Parallel
+//	category: Basics
+//	description: Run DoA and DoB in parallel
+//	---
+//	version: "1.0"
+//	...
+type Example struct {
+	Name        string `json:"name" yaml:"name"`
+	Category    string `json:"category" yaml:"category"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	File        string `json:"file" yaml:"-"`
+	YAML        string `json:"yaml" yaml:"-"`
+}
+
+const defaultExampleCategory = "General"
+
+// exampleStore 缓存已解析的示例；磁盘模式下目录内容（文件名、大小、修改时间）变化时重新加载
+type exampleStore struct {
+	fsys fs.FS
+	dir  string // 磁盘目录，嵌入模式为空
+
+	mu       sync.Mutex
+	stamp    string
+	examples []Example
+}
+
+func newExampleStore(dir string) (*exampleStore, error) {
+	if dir == "" {
+		sub, err := fs.Sub(embeddedExamples, "examples")
+		if err != nil {
+			return nil, err
+		}
+		return &exampleStore{fsys: sub}, nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	return &exampleStore{fsys: os.DirFS(dir), dir: dir}, nil
+}
+
+// List 返回按分类、名称排序的示例
+func (st *exampleStore) List() ([]Example, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	stamp, err := st.fingerprint()
+	if err != nil {
+		return nil, err
+	}
+	if st.examples != nil && stamp == st.stamp {
+		return st.examples, nil
+	}
+
+	entries, err := fs.ReadDir(st.fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	out := []Example{}
+	for _, e := range entries {
+		if e.IsDir() || !isYAMLFile(e.Name()) {
+			continue
+		}
+		b, err := fs.ReadFile(st.fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		ex, err := parseExample(e.Name(), b)
+		if err != nil {
+			// 单个文件写错不影响其他示例
+			log.Printf("Warning: skipping example %s: %v", e.Name(), err)
+			continue
+		}
+		out = append(out, ex)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Category != out[j].Category {
+			return out[i].Category < out[j].Category
+		}
+		return out[i].Name < out[j].Name
+	})
+	if st.dir != "" && st.examples != nil {
+		log.Printf("Reloaded %d examples from %s", len(out), st.dir)
+	}
+	st.examples, st.stamp = out, stamp
+	return out, nil
+}
+
+// fingerprint 汇总目录中 YAML 文件的元信息，用于判断是否需要重新加载
+func (st *exampleStore) fingerprint() (string, error) {
+	if st.dir == "" {
+		return "embedded", nil
+	}
+	entries, err := fs.ReadDir(st.fsys, ".")
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		if e.IsDir() || !isYAMLFile(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", e.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
+
+func isYAMLFile(name string) bool {
+	ext := path.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// parseExample 拆分 front-matter 与工作流正文；没有 front-matter 时名称取自文件名
+func parseExample(file string, b []byte) (Example, error) {
+	ex := Example{File: file}
+	body := b
+	if rest, ok := bytes.CutPrefix(b, []byte("---\n")); ok {
+		front, after, found := bytes.Cut(rest, []byte("\n---\n"))
+		if !found {
+			return ex, fmt.Errorf("unterminated front-matter")
+		}
+		if err := yaml.Unmarshal(front, &ex); err != nil {
+			return ex, fmt.Errorf("front-matter: %w", err)
+		}
+		body = after
+	}
+	if ex.Name == "" {
+		ex.Name = strings.TrimSuffix(file, path.Ext(file))
+	}
+	if ex.Category == "" {
+		ex.Category = defaultExampleCategory
+	}
+	ex.YAML = strings.TrimSpace(string(body))
+	return ex, nil
+}
+
+func (s *Server) handleExamples(w http.ResponseWriter, r *http.Request) {
+	examples, err := s.examples.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, examples)
+}
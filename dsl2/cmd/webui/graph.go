@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"gopkg.in/yaml.v3"
+)
+
+type GraphResponse struct {
+	Success bool       `json:"success"`
+	Error   string     `json:"error,omitempty"`
+	Graph   *dsl.Graph `json:"graph,omitempty"`
+	// Workflow 是顶层设置（不含 root），compose 时原样带回
+	Workflow *dsl.Workflow `json:"workflow,omitempty"`
+}
+
+// handleWorkflowGraph 把 YAML 定义解析为节点/边图，画布直接据此渲染
+func (s *Server) handleWorkflowGraph(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req WorkflowRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	workflow, err := dsl.ParseYAML([]byte(req.YAML))
+	if err != nil {
+		respondJSON(w, GraphResponse{Success: false, Error: fmt.Sprintf("YAML parsing error: %v", err)})
+		return
+	}
+	g, err := dsl.BuildGraph(workflow)
+	if err != nil {
+		respondJSON(w, GraphResponse{Success: false, Error: fmt.Sprintf("Workflow validation error: %v", err)})
+		return
+	}
+	settings := workflow
+	settings.Root = nil
+	respondJSON(w, GraphResponse{Success: true, Graph: g, Workflow: &settings})
+}
+
+// ComposeRequest 是画布提交的图；顶层设置（taskQueue/variables 等）放在 workflow 中。
+// 条件可以用 condYaml 以 YAML 文本给出（画布属性面板中直接编辑的内容）
+type ComposeRequest struct {
+	Workflow dsl.Workflow     `json:"workflow"`
+	Nodes    []*ComposeNode   `json:"nodes"`
+	Edges    []*dsl.GraphEdge `json:"edges"`
+}
+
+type ComposeNode struct {
+	dsl.GraphNode
+	CondYAML string `json:"condYaml,omitempty"`
+}
+
+type ComposeResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	YAML    string `json:"yaml,omitempty"`
+}
+
+// handleWorkflowCompose 把画布的图还原为规范 YAML，使用引擎自身的类型与校验
+func (s *Server) handleWorkflowCompose(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ComposeRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	g := &dsl.Graph{Edges: req.Edges}
+	for _, n := range req.Nodes {
+		if n.CondYAML != "" && n.Spec != nil {
+			var cond dsl.Cond
+			if err := yaml.Unmarshal([]byte(n.CondYAML), &cond); err != nil {
+				respondJSON(w, ComposeResponse{Success: false, Error: fmt.Sprintf("node %s: condition YAML error: %v", n.ID, err)})
+				return
+			}
+			switch {
+			case n.Spec.If != nil:
+				n.Spec.If.Cond = cond
+			case n.Spec.While != nil:
+				n.Spec.While.Cond = cond
+			}
+		}
+		node := n.GraphNode
+		g.Nodes = append(g.Nodes, &node)
+	}
+
+	root, err := dsl.ComposeGraph(g)
+	if err != nil {
+		respondJSON(w, ComposeResponse{Success: false, Error: fmt.Sprintf("Graph error: %v", err)})
+		return
+	}
+	wf := req.Workflow
+	wf.Root = root
+	out, err := dsl.MarshalYAML(wf)
+	if err != nil {
+		respondJSON(w, ComposeResponse{Success: false, Error: fmt.Sprintf("Workflow validation error: %v", err)})
+		return
+	}
+	respondJSON(w, ComposeResponse{Success: true, YAML: string(out)})
+}
+
+// handleWorkflowDiagram 以 Mermaid（默认）或 DOT 文本返回工作流图：?format=mermaid|dot
+func (s *Server) handleWorkflowDiagram(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req WorkflowRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	workflow, err := dsl.ParseYAML([]byte(req.YAML))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("YAML parsing error: %v", err), http.StatusBadRequest)
+		return
+	}
+	diagram, err := dsl.RenderDiagram(workflow, r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(diagram))
+}
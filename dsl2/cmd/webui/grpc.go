@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/api/dslpb"
+	"go.temporal.io/sdk/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcCapabilities 是每个 RPC 所需的权限，与 HTTP 路由表保持一致
+var grpcCapabilities = map[string]Capability{
+	dslpb.DSLWorkflowService_Execute_FullMethodName:       CapExecute,
+	dslpb.DSLWorkflowService_Validate_FullMethodName:      CapEdit,
+	dslpb.DSLWorkflowService_GetStatus_FullMethodName:     CapView,
+	dslpb.DSLWorkflowService_ListWorkflows_FullMethodName: CapView,
+	dslpb.DSLWorkflowService_Signal_FullMethodName:        CapSignal,
+}
+
+// newGRPCServer 创建与 HTTP API 共用认证、授权和 Temporal 客户端的 gRPC 服务
+func (s *Server) newGRPCServer() *grpc.Server {
+	opts := []grpc.ServerOption{grpc.UnaryInterceptor(s.grpcAuth)}
+	if s.maxBody > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(int(s.maxBody)))
+	}
+	gs := grpc.NewServer(opts...)
+	dslpb.RegisterDSLWorkflowServiceServer(gs, &grpcService{s: s})
+	// 支持 grpcurl 等工具直接发现服务
+	reflection.Register(gs)
+	return gs
+}
+
+// grpcAuth 复用 HTTP 认证器：把 metadata 中的 authorization 转成请求头后认证，再按方法授权
+func (s *Server) grpcAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	need, ok := grpcCapabilities[info.FullMethod]
+	if !ok {
+		// 反射等内置服务不需要认证
+		return handler(ctx, req)
+	}
+	r := (&http.Request{Header: http.Header{}}).WithContext(ctx)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			r.Header.Add("Authorization", v)
+		}
+	}
+	id, err := s.auth.Authenticate(r)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	if !s.authz.Can(id, need) {
+		return nil, status.Errorf(codes.PermissionDenied, "%q capability required", need)
+	}
+	return handler(context.WithValue(ctx, identityKey{}, id), req)
+}
+
+type grpcService struct {
+	dslpb.UnimplementedDSLWorkflowServiceServer
+	s *Server
+}
+
+// client 选择 Target；与 HTTP 不同，没有可用连接时直接返回 Unavailable
+func (g *grpcService) client(t *dslpb.Target) (client.Client, error) {
+	c, err := g.s.clientForTarget(t.GetName(), t.GetNamespace())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if c == nil {
+		return nil, status.Error(codes.Unavailable, "no Temporal connection available")
+	}
+	return c, nil
+}
+
+func (g *grpcService) Execute(ctx context.Context, req *dslpb.ExecuteRequest) (*dslpb.ExecuteResponse, error) {
+	// gRPC 的 ExecuteRequest 没有入口字段，执行 root
+	workflow, err := parseWorkflow(req.GetYaml(), "")
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	workflow = workflow.WithVariables(req.GetVariables().AsMap())
+	c, err := g.client(req.GetTarget())
+	if err != nil {
+		return nil, err
+	}
+	we, err := startWorkflow(ctx, c, workflow)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to start workflow: %v", err)
+	}
+	resp := &dslpb.ExecuteResponse{WorkflowId: we.GetID(), RunId: we.GetRunID()}
+	if !req.GetWait() {
+		return resp, nil
+	}
+	var result map[string]interface{}
+	if err := we.Get(ctx, &result); err != nil {
+		return nil, status.Errorf(codes.Aborted, "workflow %s failed: %v", we.GetID(), err)
+	}
+	if resp.Result, err = toStruct(result); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return resp, nil
+}
+
+func (g *grpcService) Validate(_ context.Context, req *dslpb.ValidateRequest) (*dslpb.ValidateResponse, error) {
+	res := g.s.checkWorkflow(req.GetYaml())
+	resp := &dslpb.ValidateResponse{Valid: res.Valid}
+	for _, i := range res.Issues {
+		if resp.Error == "" && i.Severity == dsl.SeverityError {
+			resp.Error = i.String()
+		}
+		resp.Issues = append(resp.Issues, &dslpb.Issue{Severity: i.Severity, Path: i.Path, Message: i.Message})
+	}
+	return resp, nil
+}
+
+func (g *grpcService) GetStatus(ctx context.Context, req *dslpb.GetStatusRequest) (*dslpb.WorkflowStatus, error) {
+	if req.GetWorkflowId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "workflow_id is required")
+	}
+	c, err := g.client(req.GetTarget())
+	if err != nil {
+		return nil, err
+	}
+	st, err := describeWorkflow(ctx, c, req.GetWorkflowId(), req.GetRunId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toStatusPB(st)
+}
+
+func (g *grpcService) ListWorkflows(ctx context.Context, req *dslpb.ListWorkflowsRequest) (*dslpb.ListWorkflowsResponse, error) {
+	c, err := g.client(req.GetTarget())
+	if err != nil {
+		return nil, err
+	}
+	f := ListFilter{
+		PageSize:      int(req.GetPageSize()),
+		NextPageToken: req.GetPageToken(),
+		Search:        req.GetSearch(),
+		Status:        req.GetStatus(),
+	}
+	if req.GetStartedAfter() != nil {
+		f.StartedAfter = req.GetStartedAfter().AsTime()
+	}
+	if req.GetStartedBefore() != nil {
+		f.StartedBefore = req.GetStartedBefore().AsTime()
+	}
+	if _, err := f.query(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	list, next, err := listWorkflows(ctx, c, f)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	resp := &dslpb.ListWorkflowsResponse{NextPageToken: next}
+	for _, st := range list {
+		pb, err := toStatusPB(st)
+		if err != nil {
+			return nil, err
+		}
+		resp.Workflows = append(resp.Workflows, pb)
+	}
+	return resp, nil
+}
+
+func (g *grpcService) Signal(ctx context.Context, req *dslpb.SignalRequest) (*dslpb.SignalResponse, error) {
+	if req.GetWorkflowId() == "" || req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "workflow_id and name are required")
+	}
+	c, err := g.client(req.GetTarget())
+	if err != nil {
+		return nil, err
+	}
+	var payload interface{}
+	if req.GetPayload() != nil {
+		payload = req.GetPayload().AsInterface()
+	}
+	if err := c.SignalWorkflow(ctx, req.GetWorkflowId(), req.GetRunId(), req.GetName(), payload); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to signal workflow: %v", err)
+	}
+	return &dslpb.SignalResponse{}, nil
+}
+
+func toStatusPB(st WorkflowStatus) (*dslpb.WorkflowStatus, error) {
+	out := &dslpb.WorkflowStatus{
+		WorkflowId: st.WorkflowID,
+		RunId:      st.RunID,
+		Status:     st.Status,
+		StartTime:  toTimestamp(st.StartTime),
+		CloseTime:  toTimestamp(st.CloseTime),
+		Error:      st.Error,
+	}
+	if st.Result != nil {
+		var err error
+		if out.Result, err = toStruct(st.Result); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return out, nil
+}
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// toStruct 经 JSON 转换任意结果，避免 structpb.NewStruct 不支持的类型（如 []string）
+func toStruct(v interface{}) (*structpb.Struct, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	out := &structpb.Struct{}
+	if err := protojson.Unmarshal(b, out); err != nil {
+		return nil, errors.New("result is not a JSON object")
+	}
+	return out, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/samples-go/dsl2/api/dslpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCAuthValidateRequiresEdit(t *testing.T) {
+	auth, err := newAuthenticator(authConfig{Mode: "token", Tokens: "alice=view-token,bob=edit-token"})
+	require.NoError(t, err)
+	authz, err := newAuthorizer("alice=viewer,bob=editor", "viewer")
+	require.NoError(t, err)
+	s := &Server{auth: auth, authz: authz}
+
+	info := &grpc.UnaryServerInfo{FullMethod: dslpb.DSLWorkflowService_Validate_FullMethodName}
+	handler := func(context.Context, any) (any, error) { return &dslpb.ValidateResponse{Valid: true}, nil }
+	call := func(token string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		_, err := s.grpcAuth(ctx, &dslpb.ValidateRequest{}, info, handler)
+		return err
+	}
+
+	// 与 HTTP 的 POST /workflow/validate 一样需要 edit 能力
+	require.Equal(t, codes.PermissionDenied, status.Code(call("view-token")))
+	require.NoError(t, call("edit-token"))
+	require.Equal(t, codes.Unauthenticated, status.Code(call("bogus")))
+}
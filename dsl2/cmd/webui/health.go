@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+// readyCheckTimeout 限制单次就绪检查中每个依赖的耗时
+const readyCheckTimeout = 2 * time.Second
+
+// Readiness 是 /readyz 的响应；Checks 为每个依赖的检查结果（"ok" 或错误信息）
+type Readiness struct {
+	Status string            `json:"status"` // ok / unavailable
+	Checks map[string]string `json:"checks"`
+}
+
+// handleHealthz 是存活探针：进程能处理请求即可，不检查依赖
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, map[string]string{"status": "ok"})
+}
+
+// handleReadyz 是就绪探针：默认 Temporal Target 可达、示例与定义存储可用且未在关闭中
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	res := Readiness{Status: "ok", Checks: map[string]string{}}
+	check := func(name string, err error) {
+		if err != nil {
+			res.Status = "unavailable"
+			res.Checks[name] = err.Error()
+			return
+		}
+		res.Checks[name] = "ok"
+	}
+
+	if s.draining.Load() {
+		res.Status = "unavailable"
+		res.Checks["server"] = "shutting down"
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), readyCheckTimeout)
+	defer cancel()
+	c, err := s.clients.Get("", "")
+	if err == nil {
+		_, err = c.CheckHealth(ctx, &client.CheckHealthRequest{})
+	}
+	check("temporal", err)
+	_, err = s.examples.List()
+	check("examples", err)
+	check("definitions", s.definitions.Check())
+
+	if res.Status != "ok" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	respondJSON(w, res)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/convert/asl"
+)
+
+// ImportResponse 返回转换后的 YAML 以及无法（完整）转换的状态
+type ImportResponse struct {
+	Success bool       `json:"success"`
+	Error   string     `json:"error,omitempty"`
+	YAML    string     `json:"yaml,omitempty"`
+	Notes   []asl.Note `json:"notes"`
+}
+
+// handleImportWorkflow 把外部格式的定义（请求体原文）转换为 DSL YAML；目前支持 format=asl
+func (s *Server) handleImportWorkflow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if format := r.URL.Query().Get("format"); format != "asl" {
+		http.Error(w, "unsupported format "+format+" (supported: asl)", http.StatusBadRequest)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	workflow, notes, err := asl.Convert(body, asl.Options{TaskQueue: r.URL.Query().Get("taskQueue")})
+	if notes == nil {
+		notes = []asl.Note{}
+	}
+	if err != nil {
+		respondJSON(w, ImportResponse{Success: false, Error: err.Error(), Notes: notes})
+		return
+	}
+	out, err := dsl.MarshalYAML(workflow)
+	if err != nil {
+		respondJSON(w, ImportResponse{Success: false, Error: err.Error(), Notes: notes})
+		return
+	}
+	respondJSON(w, ImportResponse{Success: true, YAML: string(out), Notes: notes})
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+)
+
+const (
+	defaultListPageSize = 20
+	maxListPageSize     = 1000
+)
+
+// listStatuses 是可用于过滤的执行状态（ExecutionStatus 的取值）
+var listStatuses = []string{"Running", "Completed", "Failed", "Canceled", "Terminated", "ContinuedAsNew", "TimedOut"}
+
+// ListFilter 是列表的分页与过滤条件，最终映射为 Temporal 可见性查询
+type ListFilter struct {
+	PageSize      int
+	NextPageToken []byte
+	Search        string // WorkflowId 前缀
+	Status        string // listStatuses 之一，大小写不敏感
+	StartedAfter  time.Time
+	StartedBefore time.Time
+}
+
+// WorkflowList 是 /api/v1/workflow/list 的响应
+type WorkflowList struct {
+	Workflows []WorkflowStatus `json:"workflows"`
+	// NextPageToken 传回 pageToken 参数获取下一页；为空表示没有更多
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
+
+// query 生成可见性查询；只列出 DSL 工作流
+func (f ListFilter) query() (string, error) {
+	clauses := []string{"WorkflowType = '" + dsl.WorkflowType + "'"}
+	if f.Search != "" {
+		// 不做转义，直接拒绝可能破坏查询的字符
+		if strings.ContainsAny(f.Search, `'"\`) {
+			return "", fmt.Errorf("search must not contain quotes or backslashes")
+		}
+		clauses = append(clauses, fmt.Sprintf("WorkflowId STARTS_WITH '%s'", f.Search))
+	}
+	if f.Status != "" {
+		status := ""
+		for _, st := range listStatuses {
+			if strings.EqualFold(st, f.Status) {
+				status = st
+			}
+		}
+		if status == "" {
+			return "", fmt.Errorf("unknown status %q (want one of %s)", f.Status, strings.Join(listStatuses, ", "))
+		}
+		clauses = append(clauses, fmt.Sprintf("ExecutionStatus = '%s'", status))
+	}
+	if !f.StartedAfter.IsZero() {
+		clauses = append(clauses, fmt.Sprintf("StartTime >= '%s'", f.StartedAfter.UTC().Format(time.RFC3339)))
+	}
+	if !f.StartedBefore.IsZero() {
+		clauses = append(clauses, fmt.Sprintf("StartTime < '%s'", f.StartedBefore.UTC().Format(time.RFC3339)))
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+// parseListFilter 读取 pageSize、pageToken、q、status、startedAfter、startedBefore 参数；
+// 时间支持 RFC3339 或 2006-01-02
+func parseListFilter(q url.Values) (ListFilter, error) {
+	f := ListFilter{Search: q.Get("q"), Status: q.Get("status")}
+	if v := q.Get("pageSize"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return f, fmt.Errorf("pageSize: %w", err)
+		}
+		f.PageSize = n
+	}
+	if v := q.Get("pageToken"); v != "" {
+		tok, err := base64.URLEncoding.DecodeString(v)
+		if err != nil {
+			return f, fmt.Errorf("pageToken: %w", err)
+		}
+		f.NextPageToken = tok
+	}
+	var err error
+	if f.StartedAfter, err = parseListTime(q.Get("startedAfter")); err != nil {
+		return f, fmt.Errorf("startedAfter: %w", err)
+	}
+	if f.StartedBefore, err = parseListTime(q.Get("startedBefore")); err != nil {
+		return f, fmt.Errorf("startedBefore: %w", err)
+	}
+	_, err = f.query()
+	return f, err
+}
+
+func parseListTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+func (s *Server) handleListWorkflows(w http.ResponseWriter, r *http.Request) {
+	f, err := parseListFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c, err := s.clientFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c == nil {
+		respondJSON(w, WorkflowList{Workflows: []WorkflowStatus{}})
+		return
+	}
+	list, next, err := listWorkflows(r.Context(), c, f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	resp := WorkflowList{Workflows: list}
+	if len(next) > 0 {
+		resp.NextPageToken = base64.URLEncoding.EncodeToString(next)
+	}
+	respondJSON(w, resp)
+}
+
+// listWorkflows 返回一页 DSL 工作流执行（不含结果）及下一页的 token
+func listWorkflows(ctx context.Context, c client.Client, f ListFilter) ([]WorkflowStatus, []byte, error) {
+	query, err := f.query()
+	if err != nil {
+		return nil, nil, err
+	}
+	pageSize := f.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultListPageSize
+	}
+	pageSize = min(pageSize, maxListPageSize)
+	resp, err := c.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+		PageSize:      int32(pageSize),
+		NextPageToken: f.NextPageToken,
+		Query:         query,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("list workflows: %w", err)
+	}
+	out := make([]WorkflowStatus, 0, len(resp.GetExecutions()))
+	for _, info := range resp.GetExecutions() {
+		out = append(out, statusFromInfo(info))
+	}
+	return out, resp.GetNextPageToken(), nil
+}
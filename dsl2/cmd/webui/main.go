@@ -3,281 +3,346 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"html/template"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/cmd/internal/conn"
+	workflowpb "go.temporal.io/api/workflow/v1"
 	"go.temporal.io/sdk/client"
-	"go.temporal.io/sdk/workflow"
-	"gopkg.in/yaml.v3"
+	"google.golang.org/grpc"
 )
 
-// 简化的工作流函数（用于演示）
-func SimpleDSLWorkflow(ctx workflow.Context, wf dsl.Workflow) (map[string]any, error) {
-	// 这里是一个简化版本，仅用于演示和验证
-	// 实际执行需要完整的 DSL 引擎
-	return map[string]any{
-		"status": "simulated",
-		"message": "This is a web UI demo. Connect to real Temporal worker for full execution.",
-	}, nil
-}
-
 type Server struct {
-	temporalClient client.Client
+	clients  *clientPool
+	auth     Authenticator
+	authz    *Authorizer
+	assets   *assets
+	examples *exampleStore
+	// definitions 保存用户在设计器中保存的定义（带版本号的乐观锁）
+	definitions *definitionStore
+	// activities 是 worker 注册的 Activity 名，校验时据此提示未知 Activity；为空不检查
+	activities []string
+	// basePath 是反向代理下的挂载前缀，如 "/dsl"；根路径时为空
+	basePath string
+	// corsOrigins 是允许跨域调用 API 的来源，"*" 表示任意来源；为空不启用 CORS
+	corsOrigins []string
+	// maxBody 是 API 请求体的字节上限，<=0 不限制
+	maxBody int64
+	// draining 在收到关闭信号后置位，/readyz 据此返回 503 让负载均衡摘除实例
+	draining atomic.Bool
 }
 
 type WorkflowRequest struct {
 	YAML string `json:"yaml"`
+	// Variables 覆盖 YAML 中的同名变量（仅 execute 使用），便于用不同输入重跑同一定义
+	Variables map[string]interface{} `json:"variables,omitempty"`
+	// Wait 为 false 时 execute 启动后立即返回，结果通过 /workflow/status 或 /workflow/events 获取
+	Wait *bool `json:"wait,omitempty"`
+	// Entry 选择 YAML entrypoints 中要执行的入口（仅 execute 使用），为空时执行 root
+	Entry string `json:"entry,omitempty"`
 }
 
 type WorkflowResponse struct {
-	Success    bool        `json:"success"`
-	Error      string      `json:"error,omitempty"`
-	Result     interface{} `json:"result,omitempty"`
-	WorkflowID string      `json:"workflowId,omitempty"`
+	Success    bool             `json:"success"`
+	Error      string           `json:"error,omitempty"`
+	Failure    *dsl.NodeFailure `json:"failure,omitempty"`
+	Result     interface{}      `json:"result,omitempty"`
+	WorkflowID string           `json:"workflowId,omitempty"`
+	RunID      string           `json:"runId,omitempty"`
+}
+
+type SignalRequest struct {
+	WorkflowID string      `json:"workflowId"`
 	RunID      string      `json:"runId,omitempty"`
+	Name       string      `json:"name"`
+	Payload    interface{} `json:"payload,omitempty"`
+}
+
+type TerminateRequest struct {
+	WorkflowID string `json:"workflowId"`
+	RunID      string `json:"runId,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// TuneRequest 调整运行中 Map 的并发窗口与速率，字段含义见 dsl.TuneRequest
+type TuneRequest struct {
+	WorkflowID    string `json:"workflowId"`
+	RunID         string `json:"runId,omitempty"`
+	Path          string `json:"path,omitempty"`
+	Concurrency   int    `json:"concurrency,omitempty"`
+	RatePerMinute *int   `json:"ratePerMinute,omitempty"`
+}
+
+type TuneResponse struct {
+	Success bool            `json:"success"`
+	Error   string          `json:"error,omitempty"`
+	Maps    []dsl.MapTuning `json:"maps,omitempty"`
+}
+
+// BreakpointsRequest 运行中增删调试断点，字段含义见 dsl.BreakpointsRequest
+type BreakpointsRequest struct {
+	WorkflowID string   `json:"workflowId"`
+	RunID      string   `json:"runId,omitempty"`
+	Add        []string `json:"add,omitempty"`
+	Remove     []string `json:"remove,omitempty"`
+	Clear      bool     `json:"clear,omitempty"`
+}
+
+type BreakpointsResponse struct {
+	Success     bool     `json:"success"`
+	Error       string   `json:"error,omitempty"`
+	Breakpoints []string `json:"breakpoints,omitempty"`
 }
 
 type WorkflowStatus struct {
 	WorkflowID string      `json:"workflowId"`
 	RunID      string      `json:"runId"`
 	Status     string      `json:"status"`
+	StartTime  *time.Time  `json:"startTime,omitempty"`
+	CloseTime  *time.Time  `json:"closeTime,omitempty"`
 	Result     interface{} `json:"result,omitempty"`
 	Error      string      `json:"error,omitempty"`
+	// Failure 是语句失败时的节点信息，见 dsl.NodeFailure
+	Failure *dsl.NodeFailure `json:"failure,omitempty"`
 }
 
 func main() {
-	// 尝试创建 Temporal 客户端，但如果失败也能继续运行（仅验证模式）
-	var c client.Client
-	var err error
-	
-	c, err = client.Dial(client.Options{})
+	// 先于参数注册加载 Profile（-config/-profile），使下面的默认值取自所选 Profile
+	if err := conn.LoadProfile(os.Args[1:]); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// ----- CLI flags -----
+	var ac authConfig
+	flag.StringVar(&ac.Mode, "auth", envOr("DSL_WEBUI_AUTH", "none"), "API authentication: none/token/basic/oidc")
+	flag.StringVar(&ac.Tokens, "auth-tokens", os.Getenv("DSL_WEBUI_AUTH_TOKENS"), "Static tokens for -auth=token, e.g. alice=tok1,bob=tok2")
+	flag.StringVar(&ac.Users, "auth-users", os.Getenv("DSL_WEBUI_AUTH_USERS"), "Users for -auth=basic, e.g. alice:pw1,bob:pw2")
+	flag.StringVar(&ac.OIDCIssuer, "oidc-issuer", os.Getenv("DSL_WEBUI_OIDC_ISSUER"), "OIDC issuer URL for -auth=oidc")
+	flag.StringVar(&ac.OIDCClientID, "oidc-client-id", os.Getenv("DSL_WEBUI_OIDC_CLIENT_ID"), "Expected token audience for -auth=oidc (optional)")
+	flag.StringVar(&ac.OIDCUserClaim, "oidc-user-claim", envOr("DSL_WEBUI_OIDC_USER_CLAIM", "email"), "Claim used as user name for -auth=oidc")
+	roles := flag.String("roles", os.Getenv("DSL_WEBUI_ROLES"), "Role assignments, e.g. alice=operator,bob=viewer")
+	defaultRole := flag.String("default-role", os.Getenv("DSL_WEBUI_DEFAULT_ROLE"), "Role for users without an assignment (default: operator with -auth=none, otherwise viewer)")
+	// 默认 Target 的连接参数与 starter、worker 共用同一份环境变量和 Profile
+	env := conn.FromEnv()
+	conn.RegisterProfileFlags(flag.CommandLine)
+	hostport := flag.String("host", env.HostPort, "Temporal Host:Port of the default target")
+	namespace := flag.String("ns", env.Namespace, "Temporal Namespace of the default target")
+	var tc TLSConfig
+	useTLS := flag.Bool("tls", env.TLS, "Connect to the default target over TLS (implied by any -tls-* flag)")
+	flag.StringVar(&tc.CAFile, "tls-ca", env.CAFile, "CA certificate (PEM) used to verify the Temporal server")
+	flag.StringVar(&tc.CertFile, "tls-cert", env.CertFile, "Client certificate (PEM) for mTLS")
+	flag.StringVar(&tc.KeyFile, "tls-key", env.KeyFile, "Client private key (PEM) for mTLS")
+	flag.StringVar(&tc.ServerName, "tls-server-name", env.ServerName, "Override the TLS server name (SNI)")
+	apiKey := flag.String("api-key", env.APIKey, "API key for the default target, sent as a bearer token (env TEMPORAL_API_KEY)")
+	var codec conn.CodecConfig
+	codec.Register(flag.CommandLine)
+	targetsFile := flag.String("targets", os.Getenv("DSL_WEBUI_TARGETS"), "YAML file declaring multiple Temporal targets (overrides -host/-ns)")
+	dev := flag.Bool("dev", os.Getenv("DSL_WEBUI_DEV") == "true", "Serve templates/static from -assets-dir instead of the embedded copies")
+	assetsDir := flag.String("assets-dir", ".", "Directory containing templates/ and static/ for -dev")
+	listenAddr := flag.String("addr", os.Getenv("DSL_WEBUI_ADDR"), "Listen address (host/IP), empty for all interfaces")
+	port := flag.String("port", envOr("DSL_WEBUI_PORT", envOr("PORT", "8080")), "Listen port")
+	basePath := flag.String("base-path", os.Getenv("DSL_WEBUI_BASE_PATH"), "URL prefix when served behind a reverse proxy, e.g. /dsl")
+	definitionsDir := flag.String("definitions-dir", os.Getenv("DSL_WEBUI_DEFINITIONS_DIR"), "Directory where saved definitions are stored (in memory when empty)")
+	examplesDir := flag.String("examples-dir", os.Getenv("DSL_WEBUI_EXAMPLES_DIR"), "Directory of example definitions, reloaded on change (default: built-in examples; <assets-dir>/examples with -dev)")
+	activities := flag.String("activities", os.Getenv("DSL_WEBUI_ACTIVITIES"), "Comma-separated activity names registered by the worker, used to warn about unknown activities (default: the sample Activities)")
+	grpcPort := flag.String("grpc-port", os.Getenv("DSL_WEBUI_GRPC_PORT"), "Also serve the gRPC API on this port (disabled when empty)")
+	corsOrigins := flag.String("cors-origins", os.Getenv("DSL_WEBUI_CORS_ORIGINS"), "Comma-separated origins allowed to call the API cross-origin, or * (disabled when empty)")
+	maxBody := flag.Int64("max-body", envInt64("DSL_WEBUI_MAX_BODY", defaultMaxBodyBytes), "Maximum API request body size in bytes (0 for no limit)")
+	readTimeout := flag.Duration("read-timeout", envDuration("DSL_WEBUI_READ_TIMEOUT", 30*time.Second), "Maximum duration for reading a request")
+	writeTimeout := flag.Duration("write-timeout", envDuration("DSL_WEBUI_WRITE_TIMEOUT", 5*time.Minute), "Maximum duration for writing a response; bounds how long execute waits for a result (0 for no limit)")
+	idleTimeout := flag.Duration("idle-timeout", envDuration("DSL_WEBUI_IDLE_TIMEOUT", 2*time.Minute), "Keep-alive idle timeout")
+	shutdownTimeout := flag.Duration("shutdown-timeout", envDuration("DSL_WEBUI_SHUTDOWN_TIMEOUT", 30*time.Second), "Time to let in-flight requests finish on SIGINT/SIGTERM")
+	flag.Parse()
+	if err := conn.ApplyDefaults(flag.CommandLine); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	auth, err := newAuthenticator(ac)
+	if err != nil {
+		log.Fatalf("auth: %v", err)
+	}
+	if *defaultRole == "" {
+		// 无认证时保持原有行为：所有人都可以执行
+		*defaultRole = string(RoleViewer)
+		if ac.Mode == "" || ac.Mode == "none" {
+			*defaultRole = string(RoleOperator)
+		}
+	}
+	authz, err := newAuthorizer(*roles, *defaultRole)
+	if err != nil {
+		log.Fatalf("authz: %v", err)
+	}
+
+	targets := []*Target{{Name: "default", Host: *hostport, Namespace: *namespace, APIKey: *apiKey}}
+	if *useTLS || tc != (TLSConfig{}) {
+		if (tc.CertFile == "") != (tc.KeyFile == "") {
+			log.Fatalf("tls: -tls-cert and -tls-key must be set together")
+		}
+		targets[0].TLS = &tc
+	}
+	if codec != (conn.CodecConfig{}) {
+		targets[0].Codec = &codec
+	}
+	if *targetsFile != "" {
+		if targets, err = loadTargets(*targetsFile); err != nil {
+			log.Fatalf("targets: %v", err)
+		}
+	}
+	clients := newClientPool(targets)
+	defer clients.Close()
+
+	// 尝试连接默认 Target，但如果失败也能继续运行（仅验证模式）
+	c, err := clients.Get("", "")
 	if err != nil {
 		log.Printf("Warning: Unable to create Temporal client: %v. Running in validation-only mode.", err)
 	}
-	
+
+	dir := ""
+	if *dev {
+		dir = *assetsDir
+	}
+	ui, err := newAssets(dir)
+	if err != nil {
+		log.Fatalf("assets: %v", err)
+	}
+	if *examplesDir == "" && *dev {
+		*examplesDir = filepath.Join(*assetsDir, "examples")
+	}
+	examples, err := newExampleStore(*examplesDir)
+	if err != nil {
+		log.Fatalf("examples: %v", err)
+	}
+
+	definitions, err := newDefinitionStore(*definitionsDir)
+	if err != nil {
+		log.Fatalf("definitions: %v", err)
+	}
+	if *definitionsDir == "" {
+		log.Printf("Warning: -definitions-dir not set; saved definitions are kept in memory only")
+	}
+
 	server := &Server{
-		temporalClient: c,
+		clients:     clients,
+		auth:        auth,
+		authz:       authz,
+		assets:      ui,
+		examples:    examples,
+		definitions: definitions,
+		activities:  dsl.ActivityNames(),
+
+		basePath: normalizeBasePath(*basePath),
+		maxBody:  *maxBody,
 	}
+	if *activities != "" {
+		server.activities = strings.Split(*activities, ",")
+	}
+	if *corsOrigins != "" {
+		server.corsOrigins = strings.Split(*corsOrigins, ",")
+	}
+	mux := http.NewServeMux()
 
 	// 静态文件服务
-	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("./static/"))))
-	
+	mux.Handle("/static/", ui.staticHandler())
+
 	// 主页面
-	http.HandleFunc("/", server.handleIndex)
-	
-	// API 路由
-	http.HandleFunc("/api/workflow/execute", server.handleExecuteWorkflow)
-	http.HandleFunc("/api/workflow/status", server.handleWorkflowStatus)
-	http.HandleFunc("/api/workflow/list", server.handleListWorkflows)
-	http.HandleFunc("/api/examples", server.handleExamples)
-
-	fmt.Println("🚀 Starting DSL Workflow Web UI on http://localhost:8080")
+	mux.HandleFunc("/", server.handleIndex)
+
+	// API 路由（统一经过认证中间件，按端点授权；见 api.go 中的路由表）
+	mux.Handle("/api/", server.apiHandler())
+
+	// 健康检查不需要认证；配置了 base-path 时在根路径上也提供，便于探针直接访问 Pod
+	mux.HandleFunc("/healthz", server.handleHealthz)
+	mux.HandleFunc("/readyz", server.handleReadyz)
+
+	var handler http.Handler = mux
+	if server.basePath != "" {
+		top := http.NewServeMux()
+		top.Handle(server.basePath+"/", http.StripPrefix(server.basePath, mux))
+		top.HandleFunc("/healthz", server.handleHealthz)
+		top.HandleFunc("/readyz", server.handleReadyz)
+		handler = top
+	}
+	addr := net.JoinHostPort(*listenAddr, *port)
+	displayHost := *listenAddr
+	if displayHost == "" {
+		displayHost = "localhost"
+	}
+
+	fmt.Printf("🚀 Starting DSL Workflow Web UI on http://%s%s/\n", net.JoinHostPort(displayHost, *port), server.basePath)
 	fmt.Println("📝 Features: YAML Editor, Workflow Validation, Execution, Examples")
+	fmt.Printf("🔒 API authentication: %s\n", ac.Mode)
+	var grpcServer *grpc.Server
+	if *grpcPort != "" {
+		lis, err := net.Listen("tcp", net.JoinHostPort(*listenAddr, *grpcPort))
+		if err != nil {
+			log.Fatalf("grpc: %v", err)
+		}
+		fmt.Printf("🔌 gRPC API on %s\n", net.JoinHostPort(displayHost, *grpcPort))
+		grpcServer = server.newGRPCServer()
+		go func() { log.Fatal(grpcServer.Serve(lis)) }()
+	}
 	if c == nil {
 		fmt.Println("⚠️  Running in validation-only mode (no Temporal connection)")
 	} else {
 		fmt.Println("✅ Connected to Temporal server")
 	}
-	
-	log.Fatal(http.ListenAndServe(":8080", nil))
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       *readTimeout,
+		WriteTimeout:      *writeTimeout,
+		IdleTimeout:       *idleTimeout,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// 收到 SIGINT/SIGTERM 后停止接收新请求，等待进行中的请求结束
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	server.draining.Store(true)
+	log.Printf("Shutting down (waiting up to %s for in-flight requests)", *shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if grpcServer != nil {
+		done := make(chan struct{})
+		go func() { grpcServer.GracefulStop(); close(done) }()
+		select {
+		case <-done:
+		case <-shutdownCtx.Done():
+			grpcServer.Stop()
+		}
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Warning: shutdown: %v", err)
+	}
 }
 
 func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
-	tmpl := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>DSL Workflow Visual Designer</title>
-    <link rel="stylesheet" href="/static/visual-style.css">
-    <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.4.0/css/all.min.css">
-</head>
-<body>
-    <div class="app-container">
-        <!-- 顶部工具栏 -->
-        <header class="toolbar">
-            <div class="toolbar-left">
-                <h1><i class="fas fa-project-diagram"></i> DSL Workflow Designer</h1>
-            </div>
-            <div class="toolbar-center">
-                <button id="validateBtn" class="btn btn-secondary">
-                    <i class="fas fa-check-circle"></i> Validate
-                </button>
-                <button id="executeBtn" class="btn btn-primary">
-                    <i class="fas fa-play"></i> Execute
-                </button>
-                <button id="saveBtn" class="btn btn-secondary">
-                    <i class="fas fa-save"></i> Save
-                </button>
-            </div>
-            <div class="toolbar-right">
-                <select id="exampleSelect" class="form-select">
-                    <option value="">Load Example...</option>
-                </select>
-            </div>
-        </header>
-
-        <div class="main-workspace">
-            <!-- 左侧节点面板 -->
-            <div class="node-palette">
-                <div class="palette-section">
-                    <h3><i class="fas fa-cube"></i> Basic Nodes</h3>
-                    <div class="node-category">
-                        <div class="palette-node" data-type="activity" draggable="true">
-                            <i class="fas fa-cog"></i>
-                            <span>Activity</span>
-                        </div>
-                        <div class="palette-node" data-type="parallel" draggable="true">
-                            <i class="fas fa-code-branch"></i>
-                            <span>Parallel</span>
-                        </div>
-                    </div>
-                </div>
-                
-                <div class="palette-section">
-                    <h3><i class="fas fa-magic"></i> Control Flow</h3>
-                    <div class="node-category">
-                        <div class="palette-node" data-type="if" draggable="true">
-                            <i class="fas fa-question"></i>
-                            <span>If/Else</span>
-                        </div>
-                        <div class="palette-node" data-type="while" draggable="true">
-                            <i class="fas fa-sync"></i>
-                            <span>While Loop</span>
-                        </div>
-                        <div class="palette-node" data-type="map" draggable="true">
-                            <i class="fas fa-list"></i>
-                            <span>Map</span>
-                        </div>
-                    </div>
-                </div>
-
-                <div class="palette-section">
-                    <h3><i class="fas fa-tools"></i> Utilities</h3>
-                    <div class="node-category">
-                        <div class="palette-node" data-type="start" draggable="true">
-                            <i class="fas fa-play-circle"></i>
-                            <span>Start</span>
-                        </div>
-                        <div class="palette-node" data-type="end" draggable="true">
-                            <i class="fas fa-stop-circle"></i>
-                            <span>End</span>
-                        </div>
-                    </div>
-                </div>
-            </div>
-
-            <!-- 中央工作区 -->
-            <div class="workflow-canvas" id="workflowCanvas">
-                <div class="canvas-grid"></div>
-                <div class="canvas-content" id="canvasContent">
-                    <!-- 拖拽的节点将出现在这里 -->
-                </div>
-                
-                <!-- 画布右键菜单 -->
-                <div id="contextMenu" class="context-menu">
-                    <div class="menu-item" data-action="delete">
-                        <i class="fas fa-trash"></i> Delete
-                    </div>
-                    <div class="menu-item" data-action="disconnect">
-                        <i class="fas fa-unlink"></i> Disconnect
-                    </div>
-                    <div class="menu-item" data-action="copy">
-                        <i class="fas fa-copy"></i> Copy
-                    </div>
-                    <div class="menu-item" data-action="edit">
-                        <i class="fas fa-edit"></i> Edit
-                    </div>
-                </div>
-            </div>
-
-            <!-- 右侧属性面板 -->
-            <div class="properties-panel" id="propertiesPanel">
-                <div class="panel-header">
-                    <h3><i class="fas fa-sliders-h"></i> Properties</h3>
-                </div>
-                <div class="panel-content" id="propertiesContent">
-                    <div class="no-selection">
-                        <i class="fas fa-mouse-pointer"></i>
-                        <p>Select a node to edit its properties</p>
-                    </div>
-                </div>
-            </div>
-        </div>
-
-        <!-- 底部状态栏和结果面板 -->
-        <div class="bottom-panel">
-            <div class="status-bar" id="statusBar">
-                <span class="status-text">Ready</span>
-                <div class="status-actions">
-                    <button id="toggleResults" class="btn-small">
-                        <i class="fas fa-terminal"></i> Results
-                    </button>
-                    <button id="toggleYaml" class="btn-small">
-                        <i class="fas fa-code"></i> YAML
-                    </button>
-                </div>
-            </div>
-            
-            <div class="results-container" id="resultsContainer" style="display: none;">
-                <div class="results-tabs">
-                    <button class="tab-btn active" data-tab="execution">Execution Results</button>
-                    <button class="tab-btn" data-tab="yaml">Generated YAML</button>
-                    <button class="tab-btn" data-tab="validation">Validation</button>
-                </div>
-                <div class="results-content">
-                    <div class="tab-pane active" id="executionResults"></div>
-                    <div class="tab-pane" id="yamlOutput">
-                        <textarea id="yamlEditor" placeholder="Generated YAML will appear here or paste your own YAML to validate..." style="width: 100%; height: 300px; font-family: monospace; font-size: 12px; border: 1px solid #ddd; padding: 10px; resize: vertical;"></textarea>
-                        <div style="margin-top: 10px;">
-                            <small style="color: #666;">💡 Tip: You can edit this YAML directly and click Validate to check it.</small>
-                        </div>
-                    </div>
-                    <div class="tab-pane" id="validationResults"></div>
-                </div>
-            </div>
-        </div>
-
-        <!-- 节点编辑模态框 -->
-        <div id="nodeEditModal" class="modal">
-            <div class="modal-content">
-                <div class="modal-header">
-                    <h3 id="modalTitle">Edit Node</h3>
-                    <button class="modal-close" id="modalClose">
-                        <i class="fas fa-times"></i>
-                    </button>
-                </div>
-                <div class="modal-body" id="modalBody">
-                    <!-- 动态内容 -->
-                </div>
-                <div class="modal-footer">
-                    <button id="modalCancel" class="btn btn-secondary">Cancel</button>
-                    <button id="modalSave" class="btn btn-primary">Save</button>
-                </div>
-            </div>
-        </div>
-    </div>
-
-    <!-- SVG 定义 -->
-    <svg width="0" height="0" style="position: absolute;">
-        <defs>
-            <marker id="arrowhead" markerWidth="10" markerHeight="7" 
-                    refX="0" refY="3.5" orient="auto">
-                <polygon points="0 0, 10 3.5, 0 7" fill="#666" />
-            </marker>
-        </defs>
-    </svg>
-
-    <script src="/static/visual-app.js"></script>
-</body>
-</html>`
-
-	t, _ := template.New("index").Parse(tmpl)
-	t.Execute(w, nil)
+	t, err := s.assets.indexTemplate()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	t.Execute(w, map[string]string{"BasePath": s.basePath})
 }
 
 func (s *Server) handleExecuteWorkflow(w http.ResponseWriter, r *http.Request) {
@@ -287,42 +352,43 @@ func (s *Server) handleExecuteWorkflow(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req WorkflowRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	// 解析 YAML
-	var workflow dsl.Workflow
-	if err := yaml.Unmarshal([]byte(req.YAML), &workflow); err != nil {
-		respondJSON(w, WorkflowResponse{
-			Success: false,
-			Error:   fmt.Sprintf("YAML parsing error: %v", err),
-		})
+	workflow, err := parseWorkflow(req.YAML, req.Entry)
+	if err != nil {
+		respondJSON(w, WorkflowResponse{Success: false, Error: err.Error()})
 		return
 	}
+	workflow = workflow.WithVariables(req.Variables)
 
-	// 验证工作流
-	if err := workflow.Validate(); err != nil {
-		respondJSON(w, WorkflowResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Workflow validation error: %v", err),
-		})
+	c, err := s.clientFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// 如果没有 Temporal 客户端，返回验证成功信息
-	if s.temporalClient == nil {
+	// 如果没有 Temporal 客户端，返回验证成功信息（有 workflowIdTemplate 时展示渲染出的 ID）
+	if c == nil {
+		id, err := workflow.StartID()
+		if err != nil {
+			respondJSON(w, WorkflowResponse{Success: false, Error: err.Error()})
+			return
+		}
+		if id == "" {
+			id = fmt.Sprintf("demo-%d", time.Now().UnixNano())
+		}
 		respondJSON(w, WorkflowResponse{
 			Success:    true,
-			WorkflowID: fmt.Sprintf("demo-%d", time.Now().UnixNano()),
+			WorkflowID: id,
 			RunID:      "demo-run",
 			Result: map[string]interface{}{
 				"status":  "validated",
 				"message": "Workflow YAML is valid. Connect to Temporal worker for execution.",
 				"workflow": map[string]interface{}{
 					"version":   workflow.Version,
-					"taskQueue": workflow.TaskQueue,
+					"taskQueue": conn.ResolveTaskQueue(workflow.TaskQueue),
 					"variables": workflow.Variables,
 				},
 			},
@@ -330,14 +396,7 @@ func (s *Server) handleExecuteWorkflow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 尝试执行工作流
-	workflowID := fmt.Sprintf("dsl-%d", time.Now().UnixNano())
-	workflowOptions := client.StartWorkflowOptions{
-		ID:        workflowID,
-		TaskQueue: workflow.TaskQueue,
-	}
-
-	we, err := s.temporalClient.ExecuteWorkflow(context.Background(), workflowOptions, SimpleDSLWorkflow, workflow)
+	we, err := startWorkflow(r.Context(), c, workflow)
 	if err != nil {
 		respondJSON(w, WorkflowResponse{
 			Success: false,
@@ -346,10 +405,15 @@ func (s *Server) handleExecuteWorkflow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Wait != nil && !*req.Wait {
+		respondJSON(w, WorkflowResponse{Success: true, WorkflowID: we.GetID(), RunID: we.GetRunID()})
+		return
+	}
+
 	// 等待结果
 	var result map[string]interface{}
-	err = we.Get(context.Background(), &result)
-	
+	err = we.Get(r.Context(), &result)
+
 	response := WorkflowResponse{
 		Success:    err == nil,
 		WorkflowID: we.GetID(),
@@ -358,6 +422,9 @@ func (s *Server) handleExecuteWorkflow(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		response.Error = err.Error()
+		if f, ok := dsl.FailureOf(err); ok {
+			response.Failure = &f
+		}
 	} else {
 		response.Result = result
 	}
@@ -365,6 +432,54 @@ func (s *Server) handleExecuteWorkflow(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, response)
 }
 
+// parseWorkflow 解析 YAML 定义、选择入口并校验；HTTP 与 gRPC 入口共用
+func parseWorkflow(text, entry string) (dsl.Workflow, error) {
+	workflow, err := dsl.ParseYAML([]byte(text))
+	if err != nil {
+		return workflow, fmt.Errorf("YAML parsing error: %v", err)
+	}
+	if workflow, err = workflow.SelectEntry(entry); err != nil {
+		return workflow, err
+	}
+	if err := workflow.Validate(); err != nil {
+		return workflow, fmt.Errorf("Workflow validation error: %v", err)
+	}
+	return workflow, nil
+}
+
+// startWorkflow 启动一次执行，并在 Memo 中记录发起人，便于在 Temporal UI 中追溯
+func startWorkflow(ctx context.Context, c client.Client, workflow dsl.Workflow) (client.WorkflowRun, error) {
+	// workflowIdTemplate 让同一业务实体的重复提交落到同一 ID：运行中时返回已有执行，已结束时按 idReusePolicy 处理
+	id, err := workflow.StartID()
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		id = fmt.Sprintf("dsl-%d", time.Now().UnixNano())
+	}
+	reuse, err := workflow.StartReusePolicy()
+	if err != nil {
+		return nil, err
+	}
+	// 定义未指定 taskQueue 时与 starter 一样使用 Profile 的 taskQueue，最后是 demo
+	workflow.TaskQueue = conn.ResolveTaskQueue(workflow.TaskQueue)
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                       id,
+		TaskQueue:                workflow.TaskQueue,
+		WorkflowIDReusePolicy:    reuse,
+		StartDelay:               time.Duration(workflow.StartDelaySec) * time.Second,
+		WorkflowExecutionTimeout: time.Duration(workflow.ExecutionTimeoutSec) * time.Second,
+		WorkflowRunTimeout:       time.Duration(workflow.RunTimeoutSec) * time.Second,
+	}
+	if id := identityFrom(ctx); id != nil {
+		workflowOptions.Memo = map[string]interface{}{
+			"startedBy":  id.Subject,
+			"authMethod": id.Method,
+		}
+	}
+	return c.ExecuteWorkflow(ctx, workflowOptions, dsl.SimpleDSLWorkflow, workflow)
+}
+
 func (s *Server) handleWorkflowStatus(w http.ResponseWriter, r *http.Request) {
 	workflowID := r.URL.Query().Get("id")
 	if workflowID == "" {
@@ -372,7 +487,12 @@ func (s *Server) handleWorkflowStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if s.temporalClient == nil {
+	c, err := s.clientFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c == nil {
 		respondJSON(w, WorkflowStatus{
 			WorkflowID: workflowID,
 			Status:     "Demo Mode",
@@ -381,145 +501,268 @@ func (s *Server) handleWorkflowStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 这里可以实现真正的状态查询
-	respondJSON(w, WorkflowStatus{
-		WorkflowID: workflowID,
-		Status:     "Unknown",
-		Result:     map[string]interface{}{"message": "Status query not implemented in demo"},
-	})
+	st, err := describeWorkflow(r.Context(), c, workflowID, r.URL.Query().Get("runId"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	respondJSON(w, st)
+}
+
+// describeWorkflow 查询执行状态；已完成的执行附带结果或失败原因
+func describeWorkflow(ctx context.Context, c client.Client, workflowID, runID string) (WorkflowStatus, error) {
+	resp, err := c.DescribeWorkflowExecution(ctx, workflowID, runID)
+	if err != nil {
+		return WorkflowStatus{}, fmt.Errorf("describe workflow: %w", err)
+	}
+	st := statusFromInfo(resp.GetWorkflowExecutionInfo())
+	if st.CloseTime != nil {
+		var result map[string]interface{}
+		if err := c.GetWorkflow(ctx, st.WorkflowID, st.RunID).Get(ctx, &result); err != nil {
+			st.Error = err.Error()
+			if f, ok := dsl.FailureOf(err); ok {
+				st.Failure = &f
+			}
+		} else {
+			st.Result = result
+		}
+	}
+	return st, nil
+}
+
+func statusFromInfo(info *workflowpb.WorkflowExecutionInfo) WorkflowStatus {
+	st := WorkflowStatus{
+		WorkflowID: info.GetExecution().GetWorkflowId(),
+		RunID:      info.GetExecution().GetRunId(),
+		Status:     strings.TrimPrefix(info.GetStatus().String(), "WORKFLOW_EXECUTION_STATUS_"),
+	}
+	if t := info.GetStartTime(); t != nil {
+		start := t.AsTime()
+		st.StartTime = &start
+	}
+	if t := info.GetCloseTime(); t != nil {
+		closed := t.AsTime()
+		st.CloseTime = &closed
+	}
+	return st
+}
+
+func (s *Server) handleSignalWorkflow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req SignalRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.WorkflowID == "" || req.Name == "" {
+		http.Error(w, "workflowId and name are required", http.StatusBadRequest)
+		return
+	}
+	c, err := s.clientFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c == nil {
+		respondJSON(w, WorkflowResponse{Success: false, Error: "No Temporal connection available"})
+		return
+	}
+
+	if err := c.SignalWorkflow(r.Context(), req.WorkflowID, req.RunID, req.Name, req.Payload); err != nil {
+		respondJSON(w, WorkflowResponse{Success: false, Error: fmt.Sprintf("Failed to signal workflow: %v", err)})
+		return
+	}
+	respondJSON(w, WorkflowResponse{Success: true, WorkflowID: req.WorkflowID, RunID: req.RunID})
 }
 
-func (s *Server) handleListWorkflows(w http.ResponseWriter, r *http.Request) {
-	// 返回空列表（演示）
-	respondJSON(w, []interface{}{})
+func (s *Server) handleTerminateWorkflow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req TerminateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.WorkflowID == "" {
+		http.Error(w, "workflowId is required", http.StatusBadRequest)
+		return
+	}
+	c, err := s.clientFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c == nil {
+		respondJSON(w, WorkflowResponse{Success: false, Error: "No Temporal connection available"})
+		return
+	}
+
+	// 在终止原因中带上操作人
+	reason := req.Reason
+	if id := identityFrom(r.Context()); id != nil {
+		reason = fmt.Sprintf("%s (terminated by %s)", reason, id.Subject)
+	}
+	if err := c.TerminateWorkflow(r.Context(), req.WorkflowID, req.RunID, reason); err != nil {
+		respondJSON(w, WorkflowResponse{Success: false, Error: fmt.Sprintf("Failed to terminate workflow: %v", err)})
+		return
+	}
+	respondJSON(w, WorkflowResponse{Success: true, WorkflowID: req.WorkflowID, RunID: req.RunID})
 }
 
-func (s *Server) handleExamples(w http.ResponseWriter, r *http.Request) {
-	examples := map[string]string{
-		"Basic Parallel": `version: "1.0"
-taskQueue: "demo"
-timeoutSec: 30
-variables:
-  x: 1
-  y: 2
-root:
-  - parallel:
-      - activity:
-          name: "DoA"
-          args: [{ ref: "x" }]
-          result: "a"
-      - activity:
-          name: "DoB"
-          args: [{ ref: "y" }]
-          result: "b"
-  - activity:
-      name: "DoC"
-      args: [{ ref: "a" }, { ref: "b" }]
-      result: "c"`,
-
-		"Map with Collection": `version: "1.0"
-taskQueue: "demo"
-timeoutSec: 30
-variables:
-  urls: ["https://a", "https://b", "https://c"]
-root:
-  - map:
-      itemsRef: "urls"
-      itemVar: "url"
-      concurrency: 3
-      collectVar: "pages"
-      body:
-        activity:
-          name: "Fetch"
-          args: [{ ref: "url" }]
-          result: "page"`,
-
-		"Conditional Branch": `version: "1.0"
-taskQueue: "demo"
-timeoutSec: 30
-variables:
-  x: 5
-  testFlag: true
-root:
-  - if:
-      cond:
-        eq:
-          left: { ref: "x" }
-          right: { int: 5 }
-      then:
-        activity:
-          name: "DoA"
-          args: [{ ref: "x" }]
-          result: "result"
-      else:
-        activity:
-          name: "DoB"
-          args: [{ int: 0 }]
-          result: "result"`,
-
-		"While Loop": `version: "1.0"
-taskQueue: "demo"
-timeoutSec: 30
-variables:
-  approved: false
-root:
-  - while:
-      cond:
-        not:
-          truthy: { ref: "approved" }
-      sleepSeconds: 1
-      maxIters: 3
-      body:
-        activity:
-          name: "MockApprove"
-          result: "approved"`,
-
-		"Complex Sequential": `version: "1.0"
-taskQueue: "demo"
-timeoutSec: 30
-variables:
-  mode: "production"
-  items: [1, 2, 3]
-root:
-  - activity:
-      name: "ValidateInput"
-      result: "validated"
-  - if:
-      cond:
-        eq:
-          left: { ref: "mode" }
-          right: { str: "production" }
-      then:
-        parallel:
-          - activity:
-              name: "CheckPermissions"
-              result: "authorized"
-          - activity:
-              name: "LoadConfig"
-              result: "config"
-      else:
-        activity:
-          name: "DevModeSetup"
-          result: "dev_config"
-  - map:
-      itemsRef: "items"
-      itemVar: "item"
-      collectVar: "results"
-      body:
-        activity:
-          name: "ProcessItem"
-          args: [{ ref: "item" }]
-          result: "processed"
-  - activity:
-      name: "FinalizeResults"
-      args: [{ ref: "results" }]
-      result: "final"`,
-	}
-
-	respondJSON(w, examples)
+func (s *Server) handleTuneWorkflow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req TuneRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.WorkflowID == "" {
+		http.Error(w, "workflowId is required", http.StatusBadRequest)
+		return
+	}
+	c, err := s.clientFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c == nil {
+		respondJSON(w, TuneResponse{Success: false, Error: "No Temporal connection available"})
+		return
+	}
+
+	handle, err := c.UpdateWorkflow(r.Context(), client.UpdateWorkflowOptions{
+		WorkflowID:   req.WorkflowID,
+		RunID:        req.RunID,
+		UpdateName:   dsl.UpdateTune,
+		Args:         []any{dsl.TuneRequest{Path: req.Path, Concurrency: req.Concurrency, RatePerMinute: req.RatePerMinute}},
+		WaitForStage: client.WorkflowUpdateStageCompleted,
+	})
+	var maps []dsl.MapTuning
+	if err == nil {
+		err = handle.Get(r.Context(), &maps)
+	}
+	if err != nil {
+		respondJSON(w, TuneResponse{Success: false, Error: fmt.Sprintf("Failed to tune workflow: %v", err)})
+		return
+	}
+	respondJSON(w, TuneResponse{Success: true, Maps: maps})
 }
 
 func respondJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
-}
\ No newline at end of file
+}
+
+// normalizeBasePath 把 "dsl/"、"/dsl/" 等统一为 "/dsl"，根路径返回空串
+func normalizeBasePath(p string) string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
+
+// envInt64 和 envDuration 解析数值型环境变量，格式错误时使用默认值
+func envInt64(key string, def int64) int64 {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+		log.Printf("Warning: invalid %s=%q, using %d", key, v, def)
+	}
+	return def
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+		log.Printf("Warning: invalid %s=%q, using %s", key, v, def)
+	}
+	return def
+}
+
+// envOr returns env var value if present, otherwise fallback.
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// handleWorkflowDebug 返回调试状态：断点、是否单步以及停住的节点与其变量
+func (s *Server) handleWorkflowDebug(w http.ResponseWriter, r *http.Request) {
+	workflowID := r.URL.Query().Get("id")
+	if workflowID == "" {
+		http.Error(w, "Missing workflow ID", http.StatusBadRequest)
+		return
+	}
+	c, err := s.clientFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c == nil {
+		http.Error(w, "No Temporal connection available", http.StatusServiceUnavailable)
+		return
+	}
+
+	v, err := c.QueryWorkflow(r.Context(), workflowID, r.URL.Query().Get("runId"), dsl.QueryDebug)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to query workflow: %v", err), http.StatusNotFound)
+		return
+	}
+	var st dsl.DebugState
+	if err := v.Get(&st); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, st)
+}
+
+func (s *Server) handleWorkflowBreakpoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req BreakpointsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.WorkflowID == "" {
+		http.Error(w, "workflowId is required", http.StatusBadRequest)
+		return
+	}
+	c, err := s.clientFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c == nil {
+		respondJSON(w, BreakpointsResponse{Success: false, Error: "No Temporal connection available"})
+		return
+	}
+
+	handle, err := c.UpdateWorkflow(r.Context(), client.UpdateWorkflowOptions{
+		WorkflowID:   req.WorkflowID,
+		RunID:        req.RunID,
+		UpdateName:   dsl.UpdateBreakpoints,
+		Args:         []any{dsl.BreakpointsRequest{Add: req.Add, Remove: req.Remove, Clear: req.Clear}},
+		WaitForStage: client.WorkflowUpdateStageCompleted,
+	})
+	var breakpoints []string
+	if err == nil {
+		err = handle.Get(r.Context(), &breakpoints)
+	}
+	if err != nil {
+		respondJSON(w, BreakpointsResponse{Success: false, Error: fmt.Sprintf("Failed to update breakpoints: %v", err)})
+		return
+	}
+	respondJSON(w, BreakpointsResponse{Success: true, Breakpoints: breakpoints})
+}
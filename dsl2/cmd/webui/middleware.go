@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// defaultMaxBodyBytes 是 API 请求体的默认上限（YAML 定义通常只有几 KB）
+const defaultMaxBodyBytes = 1 << 20
+
+// decodeJSON 解析请求体；超过 -max-body 时返回 413，其他解析错误返回 400
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+	} else {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+	return false
+}
+
+// limitBody 限制请求体大小；<=0 表示不限制
+func (s *Server) limitBody(next http.Handler) http.Handler {
+	if s.maxBody <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxBody)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// cors 按 -cors-origins 设置跨域响应头，并直接应答预检请求（预检不带凭据，需在认证之前处理）。
+// 未配置时不输出任何 CORS 头，浏览器只允许同源访问
+func (s *Server) cors(next http.Handler) http.Handler {
+	if len(s.corsOrigins) == 0 {
+		return next
+	}
+	wildcard := false
+	allowed := map[string]bool{}
+	for _, o := range s.corsOrigins {
+		wildcard = wildcard || o == "*"
+		allowed[strings.TrimSuffix(o, "/")] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if origin != "" && (wildcard || allowed[origin]) {
+			h := w.Header()
+			if wildcard {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+				h.Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Temporal-Target, X-Temporal-Namespace")
+				h.Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed 判断跨域来源是否在 -cors-origins 中（WebSocket 握手不经过 CORS，需要单独检查）
+func (s *Server) originAllowed(origin string) bool {
+	for _, o := range s.corsOrigins {
+		if o == "*" || strings.TrimSuffix(o, "/") == origin {
+			return true
+		}
+	}
+	return false
+}
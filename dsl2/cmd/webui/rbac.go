@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Capability 是一类可被授权的操作
+type Capability string
+
+const (
+	CapView      Capability = "view"      // 查看定义、状态、列表、示例
+	CapEdit      Capability = "edit"      // 校验/编辑定义
+	CapExecute   Capability = "execute"   // 启动工作流
+	CapSignal    Capability = "signal"    // 向运行中的工作流发信号
+	CapTerminate Capability = "terminate" // 终止工作流
+)
+
+// Role 是一组 Capability 的命名集合
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"
+	RoleEditor   Role = "editor"
+	RoleOperator Role = "operator"
+)
+
+var roleCapabilities = map[Role][]Capability{
+	RoleViewer:   {CapView},
+	RoleEditor:   {CapView, CapEdit},
+	RoleOperator: {CapView, CapEdit, CapExecute, CapSignal, CapTerminate},
+}
+
+// Authorizer 把用户映射到角色；未显式配置的用户使用默认角色
+type Authorizer struct {
+	roles       map[string]Role
+	defaultRole Role
+}
+
+func newAuthorizer(assignments, defaultRole string) (*Authorizer, error) {
+	az := &Authorizer{roles: map[string]Role{}, defaultRole: Role(defaultRole)}
+	if _, ok := roleCapabilities[az.defaultRole]; !ok {
+		return nil, fmt.Errorf("unknown default role %q", defaultRole)
+	}
+	if strings.TrimSpace(assignments) == "" {
+		return az, nil
+	}
+	pairs, err := parsePairs(assignments, "=")
+	if err != nil {
+		return nil, fmt.Errorf("roles: %w", err)
+	}
+	for user, role := range pairs {
+		if _, ok := roleCapabilities[Role(role)]; !ok {
+			return nil, fmt.Errorf("user %q: unknown role %q (viewer/editor/operator)", user, role)
+		}
+		az.roles[user] = Role(role)
+	}
+	return az, nil
+}
+
+// RoleOf 返回调用方的角色
+func (az *Authorizer) RoleOf(id *Identity) Role {
+	if id != nil {
+		if r, ok := az.roles[id.Subject]; ok {
+			return r
+		}
+	}
+	return az.defaultRole
+}
+
+// Can 判断调用方是否拥有某项能力
+func (az *Authorizer) Can(id *Identity, c Capability) bool {
+	for _, have := range roleCapabilities[az.RoleOf(id)] {
+		if have == c {
+			return true
+		}
+	}
+	return false
+}
+
+// require 是按端点授权的中间件，需位于 requireAuth 之后
+func (s *Server) require(c Capability, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authz.Can(identityFrom(r.Context()), c) {
+			http.Error(w, fmt.Sprintf("Forbidden: %q capability required", c), http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// UserInfo 是 /api/me 的响应，前端据此显示/隐藏操作按钮
+type UserInfo struct {
+	Subject      string       `json:"subject"`
+	Method       string       `json:"method"`
+	Role         Role         `json:"role"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	id := identityFrom(r.Context())
+	caps := append([]Capability(nil), roleCapabilities[s.authz.RoleOf(id)]...)
+	sort.Slice(caps, func(i, j int) bool { return caps[i] < caps[j] })
+	respondJSON(w, UserInfo{
+		Subject:      id.Subject,
+		Method:       id.Method,
+		Role:         s.authz.RoleOf(id),
+		Capabilities: caps,
+	})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+)
+
+// handleWorkflowResult 把已完成执行的变量绑定作为附件下载（format=json|yaml，默认 json）
+func (s *Server) handleWorkflowResult(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	workflowID := q.Get("id")
+	if workflowID == "" {
+		http.Error(w, "Missing workflow ID", http.StatusBadRequest)
+		return
+	}
+	format := q.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "yaml" {
+		http.Error(w, "format must be json or yaml", http.StatusBadRequest)
+		return
+	}
+
+	c, err := s.clientFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c == nil {
+		http.Error(w, "No Temporal connection available", http.StatusServiceUnavailable)
+		return
+	}
+	st, err := describeWorkflow(r.Context(), c, workflowID, q.Get("runId"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	// 只有成功完成的执行才有结果；运行中或失败返回 409 说明原因
+	if st.CloseTime == nil {
+		http.Error(w, "workflow is still "+st.Status, http.StatusConflict)
+		return
+	}
+	if st.Error != "" {
+		http.Error(w, "workflow "+st.Status+": "+st.Error, http.StatusConflict)
+		return
+	}
+
+	var body []byte
+	contentType := "application/json"
+	if format == "yaml" {
+		body, err = yaml.Marshal(st.Result)
+		contentType = "application/yaml"
+	} else {
+		body, err = json.MarshalIndent(st.Result, "", "  ")
+		body = append(body, '\n')
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": st.WorkflowID + "-result." + format,
+	}))
+	w.Write(body)
+}
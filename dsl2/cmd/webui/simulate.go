@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/simulate"
+)
+
+// ActivityMock 是模拟执行中某个 Activity 的返回值
+type ActivityMock struct {
+	Result  any    `json:"result,omitempty"`  // 每次调用返回的值
+	Results []any  `json:"results,omitempty"` // 依次返回；用完后重复最后一个（优先于 result）
+	Error   string `json:"error,omitempty"`   // 非空时返回不可重试的错误
+}
+
+type SimulateRequest struct {
+	YAML      string                  `json:"yaml"`
+	Variables map[string]any          `json:"variables,omitempty"` // 同 WorkflowRequest.Variables
+	Mocks     map[string]ActivityMock `json:"mocks,omitempty"`     // 按 Activity 名；未列出的返回 "<name>:mock"
+	Entry     string                  `json:"entry,omitempty"`     // 同 WorkflowRequest.Entry
+}
+
+type SimulateResponse struct {
+	Success bool             `json:"success"`
+	Error   string           `json:"error,omitempty"`
+	Result  map[string]any   `json:"result,omitempty"`
+	Trace   []dsl.TraceEvent `json:"trace,omitempty"`
+	Calls   map[string]int   `json:"calls,omitempty"`
+	// Coverage 是本次模拟覆盖的节点与 If 分支
+	Coverage *simulate.Coverage `json:"coverage,omitempty"`
+}
+
+// handleSimulateWorkflow 在内存中执行定义（见 simulate 包），不需要 Temporal 服务或 worker
+func (s *Server) handleSimulateWorkflow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req SimulateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	workflow, err := parseWorkflow(req.YAML, req.Entry)
+	if err != nil {
+		respondJSON(w, SimulateResponse{Success: false, Error: err.Error()})
+		return
+	}
+	respondJSON(w, simulateWorkflow(workflow.WithVariables(req.Variables), req.Mocks))
+}
+
+func simulateWorkflow(workflow dsl.Workflow, mocks map[string]ActivityMock) SimulateResponse {
+	m := make(map[string]simulate.Mock, len(mocks))
+	for name, mock := range mocks {
+		m[name] = simulate.Mock(mock)
+	}
+	res := simulate.Run(workflow, m)
+	resp := SimulateResponse{Success: res.Success, Error: res.Error, Result: res.Result, Trace: res.Trace, Calls: res.Calls}
+	if cov, err := simulate.NewCoverage(workflow); err == nil {
+		cov.Add(res.Trace)
+		resp.Coverage = cov
+	}
+	return resp
+}
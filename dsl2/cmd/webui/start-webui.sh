@@ -20,7 +20,7 @@ cd "$(dirname "$0")"
 
 # 构建应用
 echo "📦 构建应用..."
-go build -o webui main.go
+go build -o webui .
 
 if [ $? -eq 0 ]; then
     echo "✅ 构建成功"
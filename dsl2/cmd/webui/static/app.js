@@ -1,3 +1,6 @@
+// 反向代理下的挂载前缀，由页面模板注入
+const BASE_PATH = window.BASE_PATH || '';
+
 // 全局变量
 let currentExecution = null;
 let resultCounter = 0;
@@ -72,18 +75,29 @@ root:
     updateStatus('Ready to execute', 'info');
 }
 
+// 示例列表（服务端按分类、名称排序），用于按文件名查找
+let exampleList = [];
+
 function loadExamples() {
-    fetch('/api/examples')
+    fetch(BASE_PATH + '/api/v1/examples')
         .then(response => response.json())
         .then(examples => {
+            exampleList = examples;
             const select = document.getElementById('exampleSelect');
             select.innerHTML = '<option value="">Select Example...</option>';
-            
-            Object.keys(examples).forEach(name => {
+
+            const groups = {};
+            examples.forEach(example => {
+                if (!groups[example.category]) {
+                    groups[example.category] = document.createElement('optgroup');
+                    groups[example.category].label = example.category;
+                    select.appendChild(groups[example.category]);
+                }
                 const option = document.createElement('option');
-                option.value = name;
-                option.textContent = name;
-                select.appendChild(option);
+                option.value = example.file;
+                option.textContent = example.name;
+                option.title = example.description || '';
+                groups[example.category].appendChild(option);
             });
         })
         .catch(error => {
@@ -93,22 +107,12 @@ function loadExamples() {
 
 function loadSelectedExample() {
     const select = document.getElementById('exampleSelect');
-    const selectedExample = select.value;
-    
-    if (!selectedExample) return;
-    
-    fetch('/api/examples')
-        .then(response => response.json())
-        .then(examples => {
-            if (examples[selectedExample]) {
-                document.getElementById('workflowEditor').value = examples[selectedExample];
-                updateStatus(`Loaded example: ${selectedExample}`, 'info');
-            }
-        })
-        .catch(error => {
-            console.error('Failed to load example:', error);
-            updateStatus('Failed to load example', 'error');
-        });
+    const example = exampleList.find(e => e.file === select.value);
+
+    if (!example) return;
+
+    document.getElementById('workflowEditor').value = example.yaml;
+    updateStatus(`Loaded example: ${example.name}`, 'info');
 }
 
 function validateWorkflow() {
@@ -150,7 +154,7 @@ function executeWorkflow() {
     
     updateStatus('Executing workflow...', 'info');
     
-    fetch('/api/workflow/execute', {
+    fetch(BASE_PATH + '/api/v1/workflow/execute', {
         method: 'POST',
         headers: {
             'Content-Type': 'application/json',
@@ -238,9 +242,10 @@ function refreshWorkflows() {
     // 显示加载状态
     workflowsList.innerHTML = '<p style="text-align: center; padding: 20px; color: #666;">Loading workflows...</p>';
     
-    fetch('/api/workflow/list')
+    fetch(BASE_PATH + '/api/v1/workflow/list')
         .then(response => response.json())
-        .then(workflows => {
+        .then(list => {
+            const workflows = list.workflows || [];
             if (workflows.length === 0) {
                 workflowsList.innerHTML = '<p style="text-align: center; padding: 20px; color: #666;">No recent workflows found</p>';
             } else {
@@ -288,7 +293,7 @@ function viewWorkflow(workflowId) {
 function getWorkflowStatus(workflowId) {
     updateStatus(`Querying status for ${workflowId}...`, 'info');
     
-    fetch(`/api/workflow/status?id=${workflowId}`)
+    fetch(`${BASE_PATH}/api/v1/workflow/status?id=${workflowId}`)
         .then(response => response.json())
         .then(status => {
             const result = {
@@ -1,4 +1,6 @@
 // 全局变量
+// 反向代理下的挂载前缀，由页面模板注入
+const BASE_PATH = window.BASE_PATH || '';
 let workflowData = {
     nodes: new Map(),
     connections: [],
@@ -88,6 +90,16 @@ const NODE_TYPES = {
             collectVar: { type: 'text', label: 'Collect Variable' },
             failFast: { type: 'checkbox', label: 'Fail Fast', default: true }
         }
+    },
+    noop: {
+        title: 'No-op',
+        icon: 'fas fa-comment',
+        color: '#9e9e9e',
+        inputs: 1,
+        outputs: 1,
+        properties: {
+            description: { type: 'text', label: 'Description' }
+        }
     }
 };
 
@@ -102,6 +114,9 @@ function initializeApp() {
     
     setupEventListeners();
     loadExamples();
+    loadDefinitions();
+    loadCapabilities();
+    loadTargets();
     
     // 创建默认的开始节点
     createNode('start', { x: 100, y: 200 });
@@ -112,8 +127,13 @@ function initializeApp() {
 function setupEventListeners() {
     // 工具栏按钮
     document.getElementById('validateBtn').addEventListener('click', validateWorkflow);
+    document.getElementById('simulateBtn').addEventListener('click', simulateWorkflow);
     document.getElementById('executeBtn').addEventListener('click', executeWorkflow);
-    document.getElementById('saveBtn').addEventListener('click', saveWorkflow);
+    document.getElementById('saveBtn').addEventListener('click', () => saveWorkflow());
+    document.getElementById('downloadBtn').addEventListener('click', downloadWorkflow);
+    document.getElementById('definitionSelect').addEventListener('change', e => {
+        if (e.target.value) openDefinition(e.target.value);
+    });
     document.getElementById('exampleSelect').addEventListener('change', loadSelectedExample);
     
     // 底部面板控制
@@ -371,6 +391,8 @@ function getNodeDescription(nodeData) {
             return `Process ${props.itemsRef || 'items'} with concurrency ${props.concurrency || 1}`;
         case 'parallel':
             return `Execute multiple branches in parallel`;
+        case 'noop':
+            return props.description || 'Placeholder (does nothing)';
         default:
             return NODE_TYPES[type]?.title || type;
     }
@@ -901,235 +923,121 @@ function drawConnection(connection) {
 }
 
 // 工作流生成和验证
+// 画布 -> 图 -> 服务端 compose，YAML 由引擎的 Go 类型生成并校验
+let lastComposed = null;
+
 function generateYAML() {
-    // 简化版本的YAML生成
-    const workflow = {
-        version: "1.0",
-        taskQueue: "demo",
-        timeoutSec: 30,
-        variables: {},
-        root: [] // 直接是数组，不再是sequence包装
-    };
-    
-    console.log("Starting YAML generation, nodes:", workflowData.nodes.size, "connections:", workflowData.connections.length);
-    
-    // 如果没有节点，生成一个默认的示例
-    if (workflowData.nodes.size === 0) {
-        console.log("No nodes found, generating default example");
-        workflow.root = [
-            {
-                activity: {
-                    name: "DoA",
-                    args: [{ int: 42 }],
-                    result: "result"
-                }
-            }
-        ];
-    } else {
-        // 寻找开始节点并按连接顺序构建root数组
-        let startNode = null;
-        for (let [id, node] of workflowData.nodes) {
-            if (node.type === 'start') {
-                startNode = node;
-                break;
-            }
-        }
-        
-        console.log("Start node found:", startNode ? startNode.id : "none");
-        
-        if (startNode) {
-            // 从开始节点开始，构建连接的Statement序列
-            workflow.root = buildStatementSequence(startNode);
-        } else {
-            // 如果没有start节点，但有其他节点，生成所有非start/end节点
-            console.log("No start node, generating from all activity nodes");
-            for (let [id, node] of workflowData.nodes) {
-                if (node.type !== 'start' && node.type !== 'end') {
-                    const statement = buildNodeStructure(node);
-                    if (statement) {
-                        workflow.root.push(statement);
-                    }
-                }
-            }
-        }
+    const request = canvasToGraph();
+    const key = JSON.stringify(request);
+    if (key === lastComposed) {
+        return Promise.resolve(document.getElementById('yamlEditor').value);
     }
-    
-    console.log("Generated root with", workflow.root.length, "statements");
-    
-    // 生成简单的YAML字符串
-    const yamlString = generateSimpleYAML(workflow);
-    document.getElementById('yamlEditor').value = yamlString;
-    
-    return workflow;
-}
-
-function generateSimpleYAML(obj) {
-    let yaml = `version: "${obj.version}"
-taskQueue: "${obj.taskQueue}"
-timeoutSec: ${obj.timeoutSec}
-variables: {}
-root:`;
-
-    if (obj.root && obj.root.length > 0) {
-        // 为每个语句生成YAML
-        for (let statement of obj.root) {
-            yaml += '\n  - ';
-            if (statement.activity) {
-                yaml += `activity:
-      name: "${statement.activity.name || 'UnnamedActivity'}"
-      args: ${JSON.stringify(statement.activity.args || [])}
-      result: "${statement.activity.result || 'result'}"`;
-            } else if (statement.parallel) {
-                yaml += `parallel: ${JSON.stringify(statement.parallel)}`;
-            } else {
-                yaml += `activity:
-      name: "Placeholder"
-      result: "result"`;
-            }
+    return fetch(BASE_PATH + '/api/v1/workflow/compose', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: key
+    })
+    .then(response => response.json())
+    .then(data => {
+        lastComposed = key;
+        if (!data.success) {
+            updateStatus(`Graph incomplete: ${data.error}`);
+            return document.getElementById('yamlEditor').value;
         }
-    } else {
-        // 生成默认示例
-        yaml += `
-  - activity:
-      name: "DoA"
-      args: [{ int: 42 }]
-      result: "result"`;
-    }
-    
-    return yaml;
+        document.getElementById('yamlEditor').value = data.yaml;
+        return data.yaml;
+    });
 }
 
-function buildStatementSequence(startNode) {
-    console.log("Building statement sequence from start node:", startNode.id);
-    
-    // 构建拓扑排序来确保正确的执行顺序
-    function topologicalSort() {
-        const graph = new Map();
-        const inDegree = new Map();
-        const allNodes = new Set();
-        
-        // 初始化图结构
-        for (let [id, node] of workflowData.nodes) {
-            if (node.type !== 'start' && node.type !== 'end') {
-                graph.set(id, []);
-                inDegree.set(id, 0);
-                allNodes.add(id);
-            }
-        }
-        
-        // 构建连接关系
-        for (let conn of workflowData.connections) {
-            const fromNode = workflowData.nodes.get(conn.from);
-            const toNode = workflowData.nodes.get(conn.to);
-            
-            if (fromNode && toNode && 
-                fromNode.type !== 'end' && toNode.type !== 'end' &&
-                toNode.type !== 'start') {
-                
-                // 确保from节点在图中（除了start节点）
-                if (fromNode.type !== 'start') {
-                    if (!graph.has(conn.from)) {
-                        graph.set(conn.from, []);
-                        inDegree.set(conn.from, 0);
-                        allNodes.add(conn.from);
-                    }
-                    // 添加边
-                    if (!graph.get(conn.from).includes(conn.to)) {
-                        graph.get(conn.from).push(conn.to);
-                        inDegree.set(conn.to, inDegree.get(conn.to) + 1);
-                    }
-                } else {
-                    // start节点的连接不影响图结构，只设置目标节点入度为0
-                    // 这部分逻辑在后面的start连接处理中完成
-                }
-            }
-        }
-        
-        // 找出所有从start节点直接连接的节点作为入口
-        const startConnections = workflowData.connections.filter(conn => conn.from === startNode.id);
-        for (let conn of startConnections) {
-            const toNode = workflowData.nodes.get(conn.to);
-            if (toNode && toNode.type !== 'end') {
-                inDegree.set(conn.to, 0); // 从start连接的节点入度设为0
-            }
+// 把画布节点/连线转换为 /api/workflow/compose 的请求体
+function canvasToGraph() {
+    const nodes = [];
+    const edges = [];
+    const outCount = new Map();
+
+    for (let [id, node] of workflowData.nodes) {
+        if (node.type === 'start') {
+            nodes.push({ id: 'start', type: 'start' });
+            continue;
         }
-        
-        console.log("Graph structure:", Array.from(graph.entries()));
-        console.log("In-degrees:", Array.from(inDegree.entries()));
-        
-        // Kahn算法进行拓扑排序
-        const queue = [];
-        const result = [];
-        
-        // 找到所有入度为0的节点
-        for (let [nodeId, degree] of inDegree) {
-            if (degree === 0) {
-                queue.push(nodeId);
-            }
+        if (node.type === 'end') {
+            nodes.push({ id, type: 'end' });
+            continue;
         }
-        
-        console.log("Initial queue (zero in-degree nodes):", queue);
-        
-        while (queue.length > 0) {
-            const current = queue.shift();
-            const node = workflowData.nodes.get(current);
-            
-            if (node) {
-                result.push(node);
-                console.log(`Added node to result: ${current} (${node.type})`);
-                
-                // 减少邻接节点的入度
-                const neighbors = graph.get(current) || [];
-                for (let neighbor of neighbors) {
-                    inDegree.set(neighbor, inDegree.get(neighbor) - 1);
-                    if (inDegree.get(neighbor) === 0) {
-                        queue.push(neighbor);
-                        console.log(`Added to queue: ${neighbor}`);
-                    }
-                }
-            }
+        const graphNode = { id, type: node.type, spec: nodeSpec(node) };
+        if ((node.type === 'if' || node.type === 'while') && node.properties.condition) {
+            graphNode.condYaml = node.properties.condition;
         }
-        
-        console.log(`Topological sort result: ${result.length} nodes`);
-        return result;
+        nodes.push(graphNode);
     }
-    
-    // 获取排序后的节点
-    const sortedNodes = topologicalSort();
-    const statements = [];
-    
-    // 按拓扑顺序构建语句
-    for (let node of sortedNodes) {
-        const statement = buildNodeStructure(node);
-        if (statement) {
-            statements.push(statement);
-            console.log(`Added statement for node: ${node.id} (${node.type})`);
+
+    for (let conn of workflowData.connections) {
+        const from = workflowData.nodes.get(conn.from);
+        const to = workflowData.nodes.get(conn.to);
+        if (!from || !to) continue;
+        const fromId = from.type === 'start' ? 'start' : conn.from;
+        let kind = conn.kind;
+        if (!kind) {
+            // 手工连线：if 的两个输出依次为 then/else，while 依次为 body/next，其余为顺序
+            const n = outCount.get(conn.from) || 0;
+            outCount.set(conn.from, n + 1);
+            if (from.type === 'if') kind = n === 0 ? 'then' : 'else';
+            else if (from.type === 'while') kind = n === 0 ? 'body' : 'next';
+            else kind = 'next';
         }
+        edges.push({ from: fromId, to: conn.to, kind, index: conn.index || 0 });
     }
-    
-    console.log(`Built ${statements.length} statements total`);
-    return statements;
+
+    return { workflow: workflowData.settings || { version: '1.0', taskQueue: 'demo' }, nodes, edges };
 }
 
-function buildNodeStructure(node) {
-    // 简化的节点结构生成
-    switch(node.type) {
-        case 'activity':
+// 从节点属性构造 Statement（保留从图加载时属性面板不可编辑的字段）
+function nodeSpec(node) {
+    const base = node.spec ? JSON.parse(JSON.stringify(node.spec)) : {};
+    const p = node.properties;
+    switch (node.type) {
+        case 'activity': {
+            const activity = Object.assign({}, base.activity, {
+                name: p.name || 'UnnamedActivity',
+                args: parseJSONSafely(p.args) || []
+            });
+            if (p.result) activity.result = p.result; else delete activity.result;
+            if (p.timeout) {
+                activity.opts = Object.assign({}, activity.opts, { startToCloseSeconds: parseInt(p.timeout, 10) });
+            }
+            return { id: base.id, activity };
+        }
+        case 'parallel':
+            return { id: base.id, parallel: [] };
+        case 'if':
+            return { id: base.id, if: Object.assign({}, base.if) };
+        case 'while':
             return {
-                activity: {
-                    name: node.properties.name || 'UnnamedActivity',
-                    args: parseJSONSafely(node.properties.args) || [],
-                    result: node.properties.result || 'result'
-                }
+                id: base.id,
+                while: Object.assign({}, base.while, {
+                    maxIters: parseInt(p.maxIters, 10) || 0,
+                    sleepSeconds: parseInt(p.sleepSeconds, 10) || 0
+                })
             };
-        case 'parallel':
+        case 'map':
             return {
-                parallel: [] // 简化结构，直接数组不需要branches包装
+                id: base.id,
+                map: Object.assign({}, base.map, {
+                    itemsRef: p.itemsRef,
+                    itemVar: p.itemVar,
+                    concurrency: parseInt(p.concurrency, 10) || 0,
+                    collectVar: p.collectVar,
+                    failFast: !!p.failFast
+                })
             };
-        // 其他节点类型...
-        default:
-            return { activity: { name: 'Placeholder', result: 'result' } };
+        case 'noop': {
+            // disabled 中临时关闭的语句从图加载而来，画布上不可编辑，原样保留
+            const noop = Object.assign({}, base.noop);
+            if (p.description) noop.description = p.description; else delete noop.description;
+            return { id: base.id, noop };
+        }
     }
+    return base;
 }
 
 function parseJSONSafely(jsonString) {
@@ -1142,93 +1050,21 @@ function parseJSONSafely(jsonString) {
     }
 }
 
-function generateYAMLString(obj) {
-    // 简化的YAML序列化
-    function yamlify(value, indent = 0) {
-        const spaces = '  '.repeat(indent);
-        
-        if (value === null || value === undefined) {
-            return 'null';
-        }
-        
-        if (typeof value === 'string') {
-            // 简单字符串不需要引号，包含特殊字符的需要引号
-            if (/^[a-zA-Z_][a-zA-Z0-9_]*$/.test(value) || /^[0-9]+$/.test(value)) {
-                return `"${value}"`;
-            }
-            return `"${value}"`;
-        }
-        
-        if (typeof value === 'number' || typeof value === 'boolean') {
-            return String(value);
-        }
-        
-        if (Array.isArray(value)) {
-            if (value.length === 0) {
-                return '[]';
-            }
-            let result = '';
-            for (let i = 0; i < value.length; i++) {
-                result += `\n${spaces}- `;
-                const itemYaml = yamlify(value[i], indent + 1);
-                if (typeof value[i] === 'object' && value[i] !== null && !Array.isArray(value[i])) {
-                    // 对象项需要特殊处理
-                    const lines = itemYaml.split('\n').filter(line => line.trim());
-                    result += lines[0];
-                    for (let j = 1; j < lines.length; j++) {
-                        result += `\n${spaces}  ${lines[j]}`;
-                    }
-                } else {
-                    result += itemYaml;
-                }
-            }
-            return result;
-        }
-        
-        if (typeof value === 'object') {
-            let result = '';
-            const keys = Object.keys(value);
-            for (let i = 0; i < keys.length; i++) {
-                const key = keys[i];
-                const val = value[key];
-                if (i > 0) result += '\n';
-                result += `${spaces}${key}: `;
-                
-                const valYaml = yamlify(val, indent + 1);
-                if (typeof val === 'object' && val !== null) {
-                    if (Array.isArray(val) && val.length > 0) {
-                        result += valYaml;
-                    } else if (!Array.isArray(val)) {
-                        result += `\n${valYaml}`;
-                    } else {
-                        result += valYaml;
-                    }
-                } else {
-                    result += valYaml;
-                }
-            }
-            return result;
-        }
-        
-        return String(value);
-    }
-    
-    return yamlify(obj).trim();
-}
-
 function validateWorkflow() {
     // 从可编辑的YAML编辑器读取内容，如果为空则先生成
     let yamlContent = document.getElementById('yamlEditor').value;
     
     if (!yamlContent.trim()) {
         console.log("YAML editor is empty, generating YAML first");
-        generateYAML();
-        yamlContent = document.getElementById('yamlEditor').value;
+        generateYAML().then(yaml => {
+            if (yaml.trim()) validateWorkflow();
+        });
+        return;
     }
     
     console.log("Validating YAML:", yamlContent.substring(0, 100) + "...");
     
-    fetch('/api/workflow/execute', {
+    fetch(BASE_PATH + '/api/v1/workflow/validate', {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify({ yaml: yamlContent })
@@ -1236,26 +1072,25 @@ function validateWorkflow() {
     .then(response => response.json())
     .then(data => {
         const validationResults = document.getElementById('validationResults');
-        
-        if (data.success) {
-            validationResults.innerHTML = `
-                <div style="color: #4CAF50;">
-                    <i class="fas fa-check-circle"></i>
-                    <strong>Validation Successful</strong>
-                    <p>Workflow structure is valid and ready for execution.</p>
-                </div>
-            `;
-            updateStatus('Workflow validation passed');
-        } else {
-            validationResults.innerHTML = `
-                <div style="color: #f44336;">
-                    <i class="fas fa-times-circle"></i>
-                    <strong>Validation Failed</strong>
-                    <p>${data.error}</p>
-                </div>
-            `;
-            updateStatus('Workflow validation failed');
-        }
+        const issues = data.issues || [];
+        const errors = issues.filter(i => i.severity === 'error').length;
+        const warnings = issues.length - errors;
+        const items = issues.map(i => `
+            <li class="issue issue-${i.severity}">
+                <i class="fas ${i.severity === 'error' ? 'fa-times-circle' : 'fa-exclamation-triangle'}"></i>
+                ${i.path ? `<code>${i.path}</code>` : ''} ${i.message}
+            </li>`).join('');
+
+        validationResults.innerHTML = `
+            <div style="color: ${data.valid ? '#4CAF50' : '#f44336'};">
+                <i class="fas ${data.valid ? 'fa-check-circle' : 'fa-times-circle'}"></i>
+                <strong>${data.valid ? 'Validation Successful' : 'Validation Failed'}</strong>
+                <p>${errors} error(s), ${warnings} warning(s)</p>
+            </div>
+            <ul class="issue-list">${items}</ul>
+        `;
+        highlightIssues(issues);
+        updateStatus(data.valid ? 'Workflow validation passed' : 'Workflow validation failed');
         
         switchTab('validation');
         toggleResultsPanel(true);
@@ -1267,47 +1102,35 @@ function validateWorkflow() {
 }
 
 function executeWorkflow() {
-    const yamlContent = document.getElementById('yamlCode').textContent;
+    const yamlContent = document.getElementById('yamlEditor').value;
     
     if (!yamlContent.trim()) {
         updateStatus('No workflow to execute');
         return;
     }
     
+    const variables = runVariables();
+    if (variables === null) return;
+
     updateStatus('Executing workflow...');
     
-    fetch('/api/workflow/execute', {
+    fetch(BASE_PATH + '/api/v1/workflow/execute', {
         method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify({ yaml: yamlContent })
+        headers: { 'Content-Type': 'application/json', ...targetHeaders() },
+        body: JSON.stringify({ yaml: yamlContent, variables, entry: runEntry(), wait: false })
     })
     .then(response => response.json())
     .then(data => {
-        const executionResults = document.getElementById('executionResults');
-        
-        if (data.success) {
-            executionResults.innerHTML = `
-                <div style="color: #4CAF50; margin-bottom: 16px;">
-                    <h4><i class="fas fa-check-circle"></i> Execution Successful</h4>
-                    <p><strong>Workflow ID:</strong> ${data.workflowId}</p>
-                    <p><strong>Run ID:</strong> ${data.runId}</p>
-                </div>
-                <div style="background: #f8f9fa; padding: 16px; border-radius: 8px;">
-                    <h5>Results:</h5>
-                    <pre>${JSON.stringify(data.result, null, 2)}</pre>
-                </div>
-            `;
+        if (!data.success) {
+            showExecutionResult(data);
+            updateStatus('Workflow execution failed');
+        } else if (data.result) {
+            // 演示模式直接返回结果
+            showExecutionResult(data);
             updateStatus('Workflow executed successfully');
         } else {
-            executionResults.innerHTML = `
-                <div style="color: #f44336;">
-                    <h4><i class="fas fa-times-circle"></i> Execution Failed</h4>
-                    <p><strong>Error:</strong> ${data.error}</p>
-                </div>
-            `;
-            updateStatus('Workflow execution failed');
+            watchExecution(data.workflowId, data.runId);
         }
-        
         switchTab('execution');
         toggleResultsPanel(true);
     })
@@ -1317,6 +1140,336 @@ function executeWorkflow() {
     });
 }
 
+function showExecutionResult(data) {
+    const executionResults = document.getElementById('executionResults');
+    if (data.success) {
+        executionResults.innerHTML = `
+            <div style="color: #4CAF50; margin-bottom: 16px;">
+                <h4><i class="fas fa-check-circle"></i> Execution Successful</h4>
+                <p><strong>Workflow ID:</strong> ${data.workflowId}</p>
+                <p><strong>Run ID:</strong> ${data.runId}</p>
+            </div>
+            <div style="background: #f8f9fa; padding: 16px; border-radius: 8px;">
+                <h5>Results:</h5>
+                <pre>${JSON.stringify(data.result, null, 2)}</pre>
+                <button class="btn btn-secondary" onclick="downloadResult('${data.workflowId}', '${data.runId}', 'json')">
+                    <i class="fas fa-download"></i> JSON
+                </button>
+                <button class="btn btn-secondary" onclick="downloadResult('${data.workflowId}', '${data.runId}', 'yaml')">
+                    <i class="fas fa-download"></i> YAML
+                </button>
+            </div>
+        `;
+    } else {
+        executionResults.innerHTML = `
+            <div style="color: #f44336;">
+                <h4><i class="fas fa-times-circle"></i> Execution Failed</h4>
+                ${data.workflowId ? `<p><strong>Workflow ID:</strong> ${data.workflowId}</p>` : ''}
+                <p><strong>Error:</strong> ${data.error}</p>
+                ${failedNode(data.failure)}
+            </div>
+        `;
+    }
+}
+
+// failedNode 显示导致执行失败的节点（dsl.NodeFailure）
+function failedNode(f) {
+    if (!f) return '';
+    const name = f.nodeId ? `${f.nodeId} (${f.path})` : f.path;
+    const activity = f.activity ? `, activity ${f.activity}${f.attempt ? ` attempt ${f.attempt}` : ''}` : '';
+    const stack = f.stack ? `<p><strong>Stack:</strong> ${f.stack.join(' &gt; ')}</p>` : '';
+    return `<p><strong>Failed node:</strong> ${name} [${f.kind}${activity}]</p>${stack}`;
+}
+
+// 通过 WebSocket 接收节点事件，滚动显示日志并给节点着色；执行结束后显示最终结果
+let eventSocket = null;
+
+function watchExecution(workflowId, runId) {
+    if (eventSocket) eventSocket.close();
+    const executionResults = document.getElementById('executionResults');
+    executionResults.innerHTML = `
+        <div style="margin-bottom: 12px;">
+            <h4><i class="fas fa-spinner fa-spin"></i> Running</h4>
+            <p><strong>Workflow ID:</strong> ${workflowId}</p>
+        </div>
+        <div class="progress-bar" id="executionProgress"><div class="progress-fill"></div><span></span></div>
+        <div class="event-log" id="eventLog"></div>
+        <div class="debug-panel" id="debugPanel" style="display: none;"></div>
+        <div id="executionOutcome"></div>
+    `;
+    updateStatus('Workflow running...');
+    watchDebugger(workflowId, runId);
+
+    const params = new URLSearchParams({ id: workflowId, runId });
+    const target = targetHeaders();
+    if (target['X-Temporal-Target']) params.set('target', target['X-Temporal-Target']);
+    if (target['X-Temporal-Namespace']) params.set('namespace', target['X-Temporal-Namespace']);
+    const scheme = location.protocol === 'https:' ? 'wss:' : 'ws:';
+    const socket = new WebSocket(`${scheme}//${location.host}${BASE_PATH}/api/v1/workflow/events?${params}`);
+    eventSocket = socket;
+
+    const trace = [];
+    socket.onmessage = message => {
+        const msg = JSON.parse(message.data);
+        if (msg.type === 'node') {
+            trace.push(msg.event);
+            appendEventLog(msg.event);
+            highlightTrace(trace);
+        } else if (msg.type === 'progress') {
+            renderProgress(msg.progress);
+        } else if (msg.type === 'closed') {
+            stopDebugger();
+            const ok = msg.status === 'COMPLETED';
+            executionResults.querySelector('h4').innerHTML = ok
+                ? '<i class="fas fa-check-circle" style="color: #4CAF50;"></i> ' + msg.status
+                : '<i class="fas fa-times-circle" style="color: #f44336;"></i> ' + msg.status;
+            const outcome = document.getElementById('executionOutcome');
+            outcome.innerHTML = ok ? `
+                <h5>Results:</h5>
+                <pre>${JSON.stringify(msg.result || {}, null, 2)}</pre>
+                <button class="btn btn-secondary" onclick="downloadResult('${workflowId}', '${runId}', 'json')">
+                    <i class="fas fa-download"></i> JSON
+                </button>
+                <button class="btn btn-secondary" onclick="downloadResult('${workflowId}', '${runId}', 'yaml')">
+                    <i class="fas fa-download"></i> YAML
+                </button>
+            ` : `<p style="color: #f44336;"><strong>Error:</strong> ${msg.error || msg.status}</p>${failedNode(msg.failure)}`;
+            updateStatus(ok ? 'Workflow executed successfully' : 'Workflow execution failed');
+        } else if (msg.type === 'error') {
+            updateStatus('Event stream error: ' + msg.error);
+        }
+    };
+    socket.onerror = () => updateStatus('Event stream disconnected');
+}
+
+// 进度条：按定义中的节点计数，附带粗略的剩余时间
+function renderProgress(p) {
+    const bar = document.getElementById('executionProgress');
+    if (!bar) return;
+    bar.querySelector('.progress-fill').style.width = p.percent + '%';
+    const eta = p.etaSec ? `, ~${formatDuration(p.etaSec)} left` : '';
+    bar.querySelector('span').textContent = `${p.done}/${p.total} nodes (${p.percent}%)${eta}${p.current ? ' - ' + p.current : ''}`;
+}
+
+function formatDuration(sec) {
+    if (sec < 60) return sec + 's';
+    if (sec < 3600) return Math.round(sec / 60) + 'm';
+    return (sec / 3600).toFixed(1) + 'h';
+}
+
+function appendEventLog(ev) {
+    const log = document.getElementById('eventLog');
+    if (!log) return;
+    const line = document.createElement('div');
+    line.className = 'event-line event-' + ev.status;
+    const time = new Date(ev.time).toLocaleTimeString();
+    const duration = ev.status === 'started' || ev.status === 'skipped' ? '' : ` (${ev.durationMs || 0} ms)`;
+    const detail = ev.error || ev.reason;
+    line.textContent = `${time}  ${ev.status.padEnd(9)} ${ev.id || ev.path} [${ev.kind}]${duration}${detail ? ' - ' + detail : ''}`;
+    line.title = ev.path;
+    log.appendChild(line);
+    log.scrollTop = log.scrollHeight;
+}
+
+// 给有问题的节点加上标记（按 graphId 匹配问题路径）
+function highlightIssues(issues) {
+    const worst = {};
+    issues.forEach(i => {
+        if (i.path && worst[i.path] !== 'error') worst[i.path] = i.severity;
+    });
+    workflowData.nodes.forEach(node => {
+        const element = document.querySelector(`[data-node-id="${node.id}"]`);
+        if (!element) return;
+        element.classList.remove('issue-error', 'issue-warning');
+        const severity = node.graphId && worst[node.graphId];
+        if (severity) {
+            element.classList.add('issue-' + severity);
+        }
+    });
+}
+
+// 读取 Inputs 标签页中的变量覆盖；为空返回 undefined，格式错误返回 null
+function runVariables() {
+    const text = document.getElementById('variablesEditor').value.trim();
+    if (!text) return undefined;
+    try {
+        const variables = JSON.parse(text);
+        if (typeof variables !== 'object' || Array.isArray(variables) || variables === null) {
+            throw new Error('must be a JSON object');
+        }
+        return variables;
+    } catch (e) {
+        updateStatus(`Invalid input variables: ${e.message}`);
+        switchTab('inputs');
+        toggleResultsPanel(true);
+        return null;
+    }
+}
+
+// 定义声明了 entrypoints 时要执行的入口；为空时执行 root
+function runEntry() {
+    return document.getElementById('entryInput').value.trim() || undefined;
+}
+
+// 在服务端的测试环境中模拟执行（Activity 自动 mock），并按轨迹给节点着色
+function simulateWorkflow() {
+    const yamlContent = document.getElementById('yamlEditor').value;
+
+    if (!yamlContent.trim()) {
+        updateStatus('No workflow to simulate');
+        return;
+    }
+
+    const variables = runVariables();
+    if (variables === null) return;
+
+    updateStatus('Simulating workflow...');
+
+    fetch(BASE_PATH + '/api/v1/workflow/simulate', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ yaml: yamlContent, variables, entry: runEntry() })
+    })
+    .then(response => response.json())
+    .then(data => {
+        const executionResults = document.getElementById('executionResults');
+        const rows = (data.trace || [])
+            .filter(ev => ev.status !== 'started')
+            .map(ev => `<tr><td>${ev.path}</td><td>${ev.kind}</td><td>${ev.status}</td><td>${ev.error || ev.reason || ''}</td></tr>`)
+            .join('');
+        const cov = data.coverage;
+        const coverage = cov ? `
+                <h5>Coverage: ${cov.nodesCovered}/${cov.nodes.length} nodes, ${cov.branchesCovered}/${cov.branches.length} branches</h5>
+                <pre>${[
+                    ...cov.nodes.filter(n => n.hits === 0).map(n => `not run: ${n.id} (${n.label})`),
+                    ...cov.branches.filter(b => b.implicit && b.hits === 0 && cov.nodes.some(n => n.id === b.node && n.hits > 0)).map(b => `branch not taken: ${b.node} ${b.branch}`)
+                ].join('\n') || 'All nodes and branches ran'}</pre>` : '';
+        const header = data.success
+            ? '<div style="color: #4CAF50; margin-bottom: 16px;"><h4><i class="fas fa-flask"></i> Simulation Completed</h4></div>'
+            : `<div style="color: #f44336; margin-bottom: 16px;"><h4><i class="fas fa-times-circle"></i> Simulation Failed</h4><p><strong>Error:</strong> ${data.error}</p></div>`;
+
+        executionResults.innerHTML = `
+            ${header}
+            <div style="background: #f8f9fa; padding: 16px; border-radius: 8px;">
+                <h5>Final bindings:</h5>
+                <pre>${JSON.stringify(data.result || {}, null, 2)}</pre>
+                <h5>Node trace:</h5>
+                <table class="trace-table"><tr><th>Path</th><th>Kind</th><th>Status</th><th>Error</th></tr>${rows}</table>${coverage}
+            </div>
+        `;
+        highlightTrace(data.trace || []);
+        updateStatus(data.success ? 'Simulation completed' : 'Simulation failed');
+        switchTab('execution');
+        toggleResultsPanel(true);
+    })
+    .catch(error => {
+        console.error('Simulation error:', error);
+        updateStatus('Simulation request failed');
+    });
+}
+
+// 以节点最后一次状态着色；只有从 YAML 加载的节点带有 graphId（与轨迹路径一致）
+function highlightTrace(trace) {
+    const last = {};
+    trace.forEach(ev => { last[ev.path] = ev.status; });
+    workflowData.nodes.forEach(node => {
+        const element = document.querySelector(`[data-node-id="${node.id}"]`);
+        if (!element) return;
+        element.classList.remove('trace-completed', 'trace-failed', 'trace-started', 'trace-skipped');
+        const status = node.graphId && last[node.graphId];
+        if (status) {
+            element.classList.add('trace-' + status);
+        }
+    });
+}
+
+// 调试器：轮询停住的节点，显示其变量并提供 Step / Continue 与运行中增删断点
+let debugTimer = null;
+
+function watchDebugger(workflowId, runId) {
+    stopDebugger();
+    const params = new URLSearchParams({ id: workflowId, runId });
+    const poll = () => fetch(`${BASE_PATH}/api/v1/workflow/debug?${params}`, { headers: targetHeaders() })
+        .then(response => response.ok ? response.json() : null)
+        .then(state => { if (state) renderDebugger(workflowId, runId, state); })
+        .catch(error => console.error('Debugger poll failed:', error));
+    poll();
+    debugTimer = setInterval(poll, 2000);
+}
+
+function stopDebugger() {
+    if (debugTimer) clearInterval(debugTimer);
+    debugTimer = null;
+    const panel = document.getElementById('debugPanel');
+    if (panel) panel.style.display = 'none';
+    highlightHalted([]);
+}
+
+function renderDebugger(workflowId, runId, state) {
+    const panel = document.getElementById('debugPanel');
+    if (!panel) return;
+    const halted = state.halted || [];
+    highlightHalted(halted);
+    if (halted.length === 0 && (state.breakpoints || []).length === 0) {
+        panel.style.display = 'none';
+        return;
+    }
+    panel.style.display = 'block';
+    panel.innerHTML = `
+        <h5><i class="fas fa-bug"></i> Debugger${state.stepping ? ' (stepping)' : ''}</h5>
+        <p><strong>Breakpoints:</strong> ${(state.breakpoints || []).map(escapeHtml).join(', ') || 'none'}</p>
+        ${halted.map(h => `
+            <div class="debug-halted">
+                <strong>${escapeHtml(h.id || h.path)}</strong> [${h.kind}] halted on ${h.reason}
+                <pre>${escapeHtml(JSON.stringify(h.bindings || {}, null, 2))}</pre>
+            </div>
+        `).join('')}
+        <button class="btn btn-secondary" id="debugStep" ${halted.length ? '' : 'disabled'}>
+            <i class="fas fa-step-forward"></i> Step
+        </button>
+        <button class="btn btn-secondary" id="debugContinue" ${halted.length ? '' : 'disabled'}>
+            <i class="fas fa-play"></i> Continue
+        </button>
+        <input type="text" id="debugBreakpoint" placeholder="statement id or node path">
+        <button class="btn btn-secondary" id="debugAdd">Add</button>
+        <button class="btn btn-secondary" id="debugRemove">Remove</button>
+    `;
+    const signal = name => fetch(BASE_PATH + '/api/v1/workflow/signal', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...targetHeaders() },
+        body: JSON.stringify({ workflowId, runId, name })
+    }).then(() => watchDebugger(workflowId, runId));
+    const breakpoints = change => fetch(BASE_PATH + '/api/v1/workflow/breakpoints', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...targetHeaders() },
+        body: JSON.stringify({ workflowId, runId, ...change })
+    })
+    .then(response => response.json())
+    .then(data => {
+        if (!data.success) updateStatus(data.error);
+        watchDebugger(workflowId, runId);
+    });
+    const input = () => document.getElementById('debugBreakpoint').value.trim();
+    document.getElementById('debugStep').addEventListener('click', () => signal('step'));
+    document.getElementById('debugContinue').addEventListener('click', () => signal('continue'));
+    document.getElementById('debugAdd').addEventListener('click', () => input() && breakpoints({ add: [input()] }));
+    document.getElementById('debugRemove').addEventListener('click', () => input() && breakpoints({ remove: [input()] }));
+}
+
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+}
+
+function highlightHalted(halted) {
+    const paths = new Set(halted.map(h => h.path));
+    workflowData.nodes.forEach(node => {
+        const element = document.querySelector(`[data-node-id="${node.id}"]`);
+        if (element) element.classList.toggle('debug-halted-node', !!node.graphId && paths.has(node.graphId));
+    });
+}
+
 // UI 控制函数
 function updateStatus(message) {
     document.querySelector('.status-text').textContent = message;
@@ -1352,24 +1505,107 @@ function switchTab(tabName) {
     
     document.querySelectorAll('.tab-pane').forEach(pane => {
         pane.classList.remove('active');
-        if (pane.id === tabName + 'Results' || pane.id === tabName + 'Output' || pane.id === tabName + 'Results') {
+        if (pane.id === tabName + 'Results' || pane.id === tabName + 'Output' || pane.id === tabName + 'Pane') {
             pane.classList.add('active');
         }
     });
 }
 
+// 加载可选的 Temporal target / namespace
+function loadTargets() {
+    fetch(BASE_PATH + '/api/v1/targets')
+        .then(response => response.json())
+        .then(targets => {
+            const select = document.getElementById('targetSelect');
+            select.innerHTML = '';
+            targets.forEach(t => {
+                t.namespaces.forEach(ns => {
+                    const option = document.createElement('option');
+                    option.value = JSON.stringify({ target: t.name, namespace: ns });
+                    option.textContent = `${t.name} / ${ns}`;
+                    select.appendChild(option);
+                });
+            });
+        })
+        .catch(error => {
+            console.error('Failed to load targets:', error);
+        });
+}
+
+// 当前选中的 target / namespace，作为请求头发送
+// 下载已完成执行的结果；通过 fetch 以便带上认证与目标请求头
+function downloadResult(workflowId, runId, format) {
+    const params = new URLSearchParams({ id: workflowId, runId, format });
+    fetch(BASE_PATH + '/api/v1/workflow/result?' + params, { headers: targetHeaders() })
+        .then(response => {
+            if (!response.ok) return response.text().then(text => { throw new Error(text.trim()); });
+            return response.blob();
+        })
+        .then(blob => {
+            const link = document.createElement('a');
+            link.href = URL.createObjectURL(blob);
+            link.download = `${workflowId}-result.${format}`;
+            link.click();
+            URL.revokeObjectURL(link.href);
+        })
+        .catch(error => updateStatus('Download failed: ' + error.message));
+}
+
+function targetHeaders() {
+    const select = document.getElementById('targetSelect');
+    if (!select || !select.value) return {};
+    const sel = JSON.parse(select.value);
+    return { 'X-Temporal-Target': sel.target, 'X-Temporal-Namespace': sel.namespace };
+}
+
+// 根据当前用户的能力禁用无权限的操作按钮
+function loadCapabilities() {
+    fetch(BASE_PATH + '/api/v1/me')
+        .then(response => response.json())
+        .then(me => {
+            const caps = new Set(me.capabilities || []);
+            const executeBtn = document.getElementById('executeBtn');
+            if (!caps.has('execute')) {
+                executeBtn.disabled = true;
+                executeBtn.title = `Role "${me.role}" cannot execute workflows`;
+            }
+            if (!caps.has('edit')) {
+                ['simulateBtn', 'validateBtn', 'saveBtn'].forEach(id => {
+                    const btn = document.getElementById(id);
+                    btn.disabled = true;
+                    btn.title = `Role "${me.role}" cannot validate, simulate or save workflows`;
+                });
+            }
+            updateStatus(`Signed in as ${me.subject} (${me.role})`);
+        })
+        .catch(error => {
+            console.error('Failed to load capabilities:', error);
+        });
+}
+
+// 示例列表（服务端按分类、名称排序），用于按文件名查找
+let exampleList = [];
+
 function loadExamples() {
-    fetch('/api/examples')
+    fetch(BASE_PATH + '/api/v1/examples')
         .then(response => response.json())
         .then(examples => {
+            exampleList = examples;
             const select = document.getElementById('exampleSelect');
             select.innerHTML = '<option value="">Load Example...</option>';
-            
-            Object.keys(examples).forEach(name => {
+
+            const groups = {};
+            examples.forEach(example => {
+                if (!groups[example.category]) {
+                    groups[example.category] = document.createElement('optgroup');
+                    groups[example.category].label = example.category;
+                    select.appendChild(groups[example.category]);
+                }
                 const option = document.createElement('option');
-                option.value = name;
-                option.textContent = name;
-                select.appendChild(option);
+                option.value = example.file;
+                option.textContent = example.name;
+                option.title = example.description || '';
+                groups[example.category].appendChild(option);
             });
         })
         .catch(error => {
@@ -1379,29 +1615,234 @@ function loadExamples() {
 
 function loadSelectedExample() {
     const select = document.getElementById('exampleSelect');
-    const selectedExample = select.value;
-    
-    if (!selectedExample) return;
-    
-    // 这里可以实现从示例YAML反向生成节点图
-    updateStatus(`Loading example: ${selectedExample}`);
-    
-    // 简化版：直接显示YAML
-    fetch('/api/examples')
+    const example = exampleList.find(e => e.file === select.value);
+
+    if (!example) return;
+
+    document.getElementById('yamlEditor').value = example.yaml;
+    loadGraphFromYAML(example.yaml);
+    switchTab('yaml');
+    toggleResultsPanel(true);
+    updateStatus(`Loaded example: ${example.name}`);
+}
+
+// 由服务端解析 YAML 为节点/边图，再渲染到画布（不在前端重复实现解析）
+function loadGraphFromYAML(yamlContent) {
+    fetch(BASE_PATH + '/api/v1/workflow/graph', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ yaml: yamlContent })
+    })
+    .then(response => response.json())
+    .then(data => {
+        if (!data.success) {
+            updateStatus(`Cannot render workflow: ${data.error}`);
+            return;
+        }
+        workflowData.settings = data.workflow;
+        renderGraph(data.graph);
+        // 画布与编辑器内容一致，无需立即重新生成
+        lastComposed = JSON.stringify(canvasToGraph());
+    })
+    .catch(error => {
+        console.error('Failed to load graph:', error);
+    });
+}
+
+// 将服务端图节点的 spec 映射为画布节点属性
+function specToProperties(node) {
+    const spec = node.spec || {};
+    switch (node.type) {
+        case 'activity':
+            return {
+                name: spec.activity.name,
+                args: JSON.stringify(spec.activity.args || []),
+                result: spec.activity.result || '',
+                timeout: (spec.activity.opts && spec.activity.opts.startToCloseSeconds) || ''
+            };
+        case 'if':
+            // YAML 是 JSON 的超集，条件直接以 JSON 形式展示
+            return { condition: JSON.stringify(spec.if.cond), description: spec.id || '' };
+        case 'while':
+            return {
+                condition: JSON.stringify(spec.while.cond),
+                maxIters: spec.while.maxIters || '',
+                sleepSeconds: spec.while.sleepSeconds || ''
+            };
+        case 'map':
+            return {
+                itemsRef: spec.map.itemsRef,
+                itemVar: spec.map.itemVar || '_item',
+                concurrency: spec.map.concurrency || '',
+                collectVar: spec.map.collectVar || '',
+                failFast: !!spec.map.failFast
+            };
+        case 'noop':
+            return { description: spec.noop.description || '' };
+        default:
+            return {};
+    }
+}
+
+function renderGraph(graph) {
+    // 清空画布
+    Array.from(workflowData.nodes.keys()).forEach(id => {
+        const el = document.querySelector(`[data-node-id="${id}"]`);
+        if (el) el.remove();
+    });
+    workflowData.nodes.clear();
+    workflowData.connections = [];
+
+    // 简单布局：根语句横向排列，子语句在所在列内纵向堆叠
+    const idMap = new Map();
+    const rows = new Map();
+    let column = -1;
+    graph.nodes.forEach(node => {
+        let x, y;
+        if (node.type === 'start' || node.type === 'end' || !node.parent) {
+            column++;
+            x = 100 + column * 220;
+            y = 200;
+        } else {
+            const parentPos = workflowData.nodes.get(idMap.get(node.parent)).position;
+            const row = (rows.get(parentPos.x) || 0) + 1;
+            rows.set(parentPos.x, row);
+            x = parentPos.x + (node.depth > 1 ? 40 : 0);
+            y = 200 + row * 120;
+        }
+        const created = createNode(node.type, { x, y }, specToProperties(node));
+        created.graphId = node.id;
+        created.parent = node.parent ? idMap.get(node.parent) : null;
+        created.spec = node.spec;
+        idMap.set(node.id, created.id);
+    });
+
+    graph.edges.forEach(edge => {
+        workflowData.connections.push({
+            from: idMap.get(edge.from),
+            to: idMap.get(edge.to),
+            kind: edge.kind,
+            index: edge.index || 0,
+            id: `conn_${edge.from}_${edge.to}`
+        });
+    });
+    updateConnections();
+    updateStatus(`Rendered ${graph.nodes.length} nodes`);
+}
+
+// 服务端保存的定义；revision 是加载（或上次保存）时的版本，保存时用于乐观锁
+let currentDefinition = null;
+const editSession = Math.random().toString(36).slice(2) + Date.now().toString(36);
+let editingTimer = null;
+
+function loadDefinitions() {
+    fetch(BASE_PATH + '/api/v1/definition/list?session=' + editSession)
         .then(response => response.json())
-        .then(examples => {
-            if (examples[selectedExample]) {
-                document.getElementById('yamlEditor').value = examples[selectedExample];
-                switchTab('yaml');
-                toggleResultsPanel(true);
-                updateStatus(`Loaded example: ${selectedExample}`);
-            }
+        .then(defs => {
+            const select = document.getElementById('definitionSelect');
+            select.innerHTML = '<option value="">Open Saved...</option>';
+            defs.forEach(def => {
+                const option = document.createElement('option');
+                option.value = def.name;
+                option.textContent = def.editors && def.editors.length
+                    ? `${def.name} (editing: ${def.editors.join(', ')})`
+                    : def.name;
+                option.title = `Revision ${def.revision}, saved by ${def.updatedBy || 'unknown'} at ${new Date(def.updatedAt).toLocaleString()}`;
+                select.appendChild(option);
+            });
+        })
+        .catch(error => console.error('Failed to load definitions:', error));
+}
+
+function openDefinition(name) {
+    fetch(`${BASE_PATH}/api/v1/definition/get?name=${encodeURIComponent(name)}&session=${editSession}`)
+        .then(response => {
+            if (!response.ok) return response.text().then(text => { throw new Error(text.trim()); });
+            return response.json();
+        })
+        .then(def => {
+            document.getElementById('yamlEditor').value = def.yaml;
+            loadGraphFromYAML(def.yaml);
+            currentDefinition = { name: def.name, revision: def.revision };
+            startEditing(def.name);
+            updateEditors(def.editors || []);
+            updateStatus(`Opened ${def.name} (revision ${def.revision})`);
+        })
+        .catch(error => updateStatus('Open failed: ' + error.message));
+}
+
+// 保存到服务端；他人已保存新版本时（412）询问是否覆盖
+function saveWorkflow(overwriteRevision) {
+    let name = currentDefinition && currentDefinition.name;
+    if (!name) {
+        name = prompt('Save as (letters, digits, ".", "_", "-"):');
+        if (!name) return;
+    }
+    const revision = overwriteRevision !== undefined
+        ? overwriteRevision
+        : (currentDefinition && currentDefinition.name === name ? currentDefinition.revision : 0);
+
+    fetch(BASE_PATH + '/api/v1/definition/save', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ name, yaml: document.getElementById('yamlEditor').value, revision })
+    })
+    .then(response => {
+        if (response.status === 412) {
+            return response.json().then(conflict => {
+                if (confirm(`${name}: ${conflict.error}.\n\nOverwrite their changes with yours?`)) {
+                    saveWorkflow(conflict.current.revision);
+                } else {
+                    updateStatus('Save cancelled - reopen the definition to get the latest version');
+                }
+            });
+        }
+        if (!response.ok) return response.text().then(text => { throw new Error(text.trim()); });
+        return response.json().then(def => {
+            currentDefinition = { name: def.name, revision: def.revision };
+            startEditing(def.name);
+            loadDefinitions();
+            updateStatus(`Saved ${def.name} (revision ${def.revision})`);
         });
+    })
+    .catch(error => updateStatus('Save failed: ' + error.message));
 }
 
-function saveWorkflow() {
-    const workflow = generateYAML();
-    const blob = new Blob([document.getElementById('yamlCode').textContent], { type: 'text/yaml' });
+// 定期发送编辑心跳，显示同时在编辑同一定义的其他用户
+function startEditing(name) {
+    if (editingTimer && editingTimer.name === name) return;
+    stopEditing();
+    const beat = () => fetch(BASE_PATH + '/api/v1/definition/editing', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ name, session: editSession })
+    })
+        .then(response => response.ok ? response.json() : { editors: [] })
+        .then(data => updateEditors(data.editors || []))
+        .catch(() => {});
+    beat();
+    editingTimer = { name, id: setInterval(beat, 10000) };
+}
+
+function stopEditing() {
+    if (!editingTimer) return;
+    clearInterval(editingTimer.id);
+    navigator.sendBeacon(BASE_PATH + '/api/v1/definition/editing',
+        JSON.stringify({ name: editingTimer.name, session: editSession, done: true }));
+    editingTimer = null;
+    updateEditors([]);
+}
+
+function updateEditors(editors) {
+    const indicator = document.getElementById('editorsIndicator');
+    indicator.textContent = editors.length ? `Also editing: ${editors.join(', ')}` : '';
+    indicator.style.display = editors.length ? 'inline-block' : 'none';
+}
+
+window.addEventListener('beforeunload', stopEditing);
+
+function downloadWorkflow() {
+    const blob = new Blob([document.getElementById('yamlEditor').value], { type: 'text/yaml' });
     const url = URL.createObjectURL(blob);
     const a = document.createElement('a');
     a.href = url;
@@ -1410,7 +1851,7 @@ function saveWorkflow() {
     a.click();
     document.body.removeChild(a);
     URL.revokeObjectURL(url);
-    updateStatus('Workflow saved');
+    updateStatus('Workflow downloaded');
 }
 
 function handleKeyboard(e) {
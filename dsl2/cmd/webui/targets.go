@@ -0,0 +1,293 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/temporalio/samples-go/dsl2/cmd/internal/conn"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"golang.org/x/sync/singleflight"
+	"gopkg.in/yaml.v3"
+)
+
+// Target 描述一个可连接的 Temporal 集群
+type Target struct {
+	Name       string     `yaml:"name" json:"name"`
+	Host       string     `yaml:"host" json:"host"`
+	Namespace  string     `yaml:"namespace" json:"namespace"`
+	Namespaces []string   `yaml:"namespaces,omitempty" json:"namespaces,omitempty"` // 可选：允许按请求切换的命名空间，默认仅 Namespace
+	TLS        *TLSConfig `yaml:"tls,omitempty" json:"-"`
+	APIKey     string     `yaml:"apiKey,omitempty" json:"-"` // 以 bearer token 认证（如 Temporal Cloud），隐含 TLS
+	// Codec 为载荷编码（远程 codec server 或本地加密），须与 worker 一致
+	Codec *conn.CodecConfig `yaml:"codec,omitempty" json:"-"`
+}
+
+// TLSConfig 是连接 Temporal 的 TLS/mTLS 配置（文件路径），与 starter、worker 共用
+type TLSConfig = conn.TLSConfig
+
+func (t *Target) dataConverter() (converter.DataConverter, error) {
+	if t.Codec == nil {
+		return nil, nil
+	}
+	return t.Codec.DataConverter()
+}
+
+// allowedNamespaces 返回该 Target 可选择的命名空间，第一个为默认值
+func (t *Target) allowedNamespaces() []string {
+	out := []string{t.Namespace}
+	for _, ns := range t.Namespaces {
+		if ns != t.Namespace {
+			out = append(out, ns)
+		}
+	}
+	return out
+}
+
+// loadTargets 读取 targets 配置文件：
+//
+//	targets:
+//	  - name: local
+//	    host: localhost:7233
+//	    namespace: default
+func loadTargets(path string) ([]*Target, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read targets file: %w", err)
+	}
+	var cfg struct {
+		Targets []*Target `yaml:"targets"`
+	}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal targets file: %w", err)
+	}
+	if len(cfg.Targets) == 0 {
+		return nil, errors.New("targets file declares no targets")
+	}
+	seen := map[string]bool{}
+	for i, t := range cfg.Targets {
+		if t.Name == "" || t.Host == "" {
+			return nil, fmt.Errorf("targets[%d]: name and host are required", i)
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("targets[%d]: duplicate name %q", i, t.Name)
+		}
+		seen[t.Name] = true
+		if t.Namespace == "" {
+			t.Namespace = "default"
+		}
+	}
+	return cfg.Targets, nil
+}
+
+// clientPool 按 (target, namespace) 懒加载并缓存 Temporal 客户端；
+// 同一 Target 的不同命名空间复用同一条连接
+type clientPool struct {
+	targets []*Target
+
+	mu      sync.Mutex
+	clients map[string]client.Client
+	failed  map[string]time.Time // 最近一次连接失败的时间，避免每个请求都重新拨号
+	// dialing 合并同一 key 的并发建连；拨号在 mu 之外进行，不可达的 Target 不会阻塞其他请求
+	dialing singleflight.Group
+}
+
+const redialBackoff = 30 * time.Second
+
+func newClientPool(targets []*Target) *clientPool {
+	return &clientPool{targets: targets, clients: map[string]client.Client{}, failed: map[string]time.Time{}}
+}
+
+func (p *clientPool) target(name string) (*Target, error) {
+	if name == "" {
+		return p.targets[0], nil
+	}
+	for _, t := range p.targets {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown target %q", name)
+}
+
+// Get 返回指定 target/namespace 的客户端；空字符串表示默认值
+func (p *clientPool) Get(targetName, namespace string) (client.Client, error) {
+	t, err := p.target(targetName)
+	if err != nil {
+		return nil, err
+	}
+	allowed := t.allowedNamespaces()
+	if namespace == "" {
+		namespace = allowed[0]
+	}
+	ok := false
+	for _, ns := range allowed {
+		ok = ok || ns == namespace
+	}
+	if !ok {
+		return nil, fmt.Errorf("namespace %q is not allowed for target %q", namespace, t.Name)
+	}
+
+	key := t.Name + "/" + namespace
+	p.mu.Lock()
+	c, ok := p.clients[key]
+	p.mu.Unlock()
+	if ok {
+		return c, nil
+	}
+	if namespace == t.Namespace {
+		return p.base(t)
+	}
+	v, err, _ := p.dialing.Do("ns:"+key, func() (any, error) {
+		p.mu.Lock()
+		c, ok := p.clients[key]
+		p.mu.Unlock()
+		if ok {
+			return c, nil
+		}
+		base, err := p.base(t)
+		if err != nil {
+			return nil, err
+		}
+		// 新客户端不会继承 DataConverter，需要重新指定
+		dc, err := t.dataConverter()
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %w", t.Name, err)
+		}
+		c, err = client.NewClientFromExisting(base, client.Options{Namespace: namespace, DataConverter: dc})
+		if err != nil {
+			return nil, fmt.Errorf("target %q namespace %q: %w", t.Name, namespace, err)
+		}
+		p.mu.Lock()
+		p.clients[key] = c
+		p.mu.Unlock()
+		return c, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(client.Client), nil
+}
+
+// base 建立（或复用）Target 默认命名空间的基础连接
+func (p *clientPool) base(t *Target) (client.Client, error) {
+	baseKey := t.Name + "/" + t.Namespace
+	p.mu.Lock()
+	base, ok := p.clients[baseKey]
+	at, failed := p.failed[t.Name]
+	p.mu.Unlock()
+	if ok {
+		return base, nil
+	}
+	if failed && time.Since(at) < redialBackoff {
+		return nil, fmt.Errorf("target %q unavailable (last dial failed %s ago)", t.Name, time.Since(at).Round(time.Second))
+	}
+	v, err, _ := p.dialing.Do("base:"+baseKey, func() (any, error) {
+		p.mu.Lock()
+		base, ok := p.clients[baseKey]
+		p.mu.Unlock()
+		if ok {
+			return base, nil
+		}
+		base, err := t.dial()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if err != nil {
+			p.failed[t.Name] = time.Now()
+			return nil, err
+		}
+		delete(p.failed, t.Name)
+		p.clients[baseKey] = base
+		return base, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(client.Client), nil
+}
+
+// dial 按 Target 的配置连接其默认命名空间
+func (t *Target) dial() (client.Client, error) {
+	opts := client.Options{HostPort: t.Host, Namespace: t.Namespace}
+	if t.TLS != nil || t.APIKey != "" {
+		tc := t.TLS
+		if tc == nil {
+			tc = &TLSConfig{}
+		}
+		tlsCfg, err := tc.Load()
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %w", t.Name, err)
+		}
+		opts.ConnectionOptions.TLS = tlsCfg
+	}
+	if t.APIKey != "" {
+		opts.Credentials = client.NewAPIKeyStaticCredentials(t.APIKey)
+	}
+	var err error
+	if opts.DataConverter, err = t.dataConverter(); err != nil {
+		return nil, fmt.Errorf("target %q: %w", t.Name, err)
+	}
+	c, err := client.Dial(opts)
+	if err != nil {
+		return nil, fmt.Errorf("dial target %q: %w", t.Name, err)
+	}
+	return c, nil
+}
+
+// Close 关闭所有已建立的客户端
+func (p *clientPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for k, c := range p.clients {
+		c.Close()
+		delete(p.clients, k)
+	}
+}
+
+// clientFor 按请求选择客户端：?target=&namespace= 或 X-Temporal-Target / X-Temporal-Namespace 头。
+// 默认 Target 无法连接时返回 (nil, nil)，处理函数退化为仅验证模式
+func (s *Server) clientFor(r *http.Request) (client.Client, error) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		target = r.Header.Get("X-Temporal-Target")
+	}
+	namespace := r.URL.Query().Get("namespace")
+	if namespace == "" {
+		namespace = r.Header.Get("X-Temporal-Namespace")
+	}
+	return s.clientForTarget(target, namespace)
+}
+
+// clientForTarget 与 clientFor 相同，供不经过 HTTP 的入口（gRPC）使用
+func (s *Server) clientForTarget(target, namespace string) (client.Client, error) {
+	c, err := s.clients.Get(target, namespace)
+	if err != nil {
+		if target == "" && namespace == "" {
+			log.Printf("Warning: default Temporal target unavailable: %v", err)
+			return nil, nil
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// TargetInfo 是 /api/v1/targets 返回的 Target 摘要（不含证书路径）
+type TargetInfo struct {
+	Name       string   `json:"name"`
+	Host       string   `json:"host"`
+	Namespaces []string `json:"namespaces"`
+	TLS        bool     `json:"tls"`
+}
+
+func (s *Server) handleTargets(w http.ResponseWriter, r *http.Request) {
+	out := make([]TargetInfo, 0, len(s.clients.targets))
+	for _, t := range s.clients.targets {
+		out = append(out, TargetInfo{Name: t.Name, Host: t.Host, Namespaces: t.allowedNamespaces(), TLS: t.TLS != nil})
+	}
+	respondJSON(w, out)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+// handleWorkflowTimeline 遍历执行的事件历史，返回按节点标注的时间线（dsl.Timeline）；download=1 时作为附件下载
+func (s *Server) handleWorkflowTimeline(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	workflowID := q.Get("id")
+	if workflowID == "" {
+		http.Error(w, "Missing workflow ID", http.StatusBadRequest)
+		return
+	}
+	c, err := s.clientFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c == nil {
+		http.Error(w, "No Temporal connection available", http.StatusServiceUnavailable)
+		return
+	}
+
+	history := &historypb.History{}
+	iter := c.GetWorkflowHistory(r.Context(), workflowID, q.Get("runId"), false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		ev, err := iter.Next()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		history.Events = append(history.Events, ev)
+	}
+	tl := dsl.BuildTimeline(history, s.dataConverterFor(r))
+	tl.WorkflowID, tl.RunID = workflowID, q.Get("runId")
+	if tl.RunID == "" && len(history.Events) > 0 {
+		tl.RunID = history.Events[0].GetWorkflowExecutionStartedEventAttributes().GetOriginalExecutionRunId()
+	}
+
+	if q.Get("download") != "" {
+		body, err := json.MarshalIndent(tl, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": workflowID + "-timeline.json",
+		}))
+		w.Write(append(body, '\n'))
+		return
+	}
+	respondJSON(w, tl)
+}
+
+// dataConverterFor 返回请求所选 Target 的载荷转换器（含 codec）；未配置 codec 时返回 nil
+func (s *Server) dataConverterFor(r *http.Request) converter.DataConverter {
+	name := r.URL.Query().Get("target")
+	if name == "" {
+		name = r.Header.Get("X-Temporal-Target")
+	}
+	t, err := s.clients.target(name)
+	if err != nil {
+		return nil
+	}
+	dc, err := t.dataConverter()
+	if err != nil {
+		return nil
+	}
+	return dc
+}
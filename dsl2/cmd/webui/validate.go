@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+// ValidateResponse 列出全部校验问题；Valid 表示没有 error 级问题（可以有警告）
+type ValidateResponse struct {
+	Valid  bool        `json:"valid"`
+	Issues []dsl.Issue `json:"issues"`
+}
+
+// checkWorkflow 解析并检查定义；YAML 解析失败作为一条 error 返回
+func (s *Server) checkWorkflow(text string) ValidateResponse {
+	workflow, err := dsl.ParseYAML([]byte(text))
+	if err != nil {
+		return ValidateResponse{Issues: []dsl.Issue{{
+			Severity: dsl.SeverityError,
+			Message:  fmt.Sprintf("YAML parsing error: %v", err),
+		}}}
+	}
+	issues := workflow.Check(dsl.CheckOptions{KnownActivities: s.activities})
+	if issues == nil {
+		issues = []dsl.Issue{}
+	}
+	return ValidateResponse{Valid: !dsl.HasErrors(issues), Issues: issues}
+}
+
+func (s *Server) handleValidateWorkflow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req WorkflowRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	respondJSON(w, s.checkWorkflow(req.YAML))
+}
+
+// MigrateRequest 是待升级的定义；From 用于没有写 version 的旧文件
+type MigrateRequest struct {
+	YAML string `json:"yaml"`
+	From string `json:"from,omitempty"`
+}
+
+// MigrateResponse 是升级到当前 schema 版本后的 YAML 与每处改写的说明；已是当前版本时 YAML 原样返回
+type MigrateResponse struct {
+	Success bool     `json:"success"`
+	Error   string   `json:"error,omitempty"`
+	YAML    string   `json:"yaml,omitempty"`
+	Version string   `json:"version"`
+	Notes   []string `json:"notes"`
+}
+
+func (s *Server) handleMigrateWorkflow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req MigrateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	out, notes, err := dsl.Migrate([]byte(req.YAML), req.From)
+	if err != nil {
+		respondJSON(w, MigrateResponse{Success: false, Error: err.Error(), Version: dsl.SchemaVersion, Notes: []string{}})
+		return
+	}
+	if notes == nil {
+		notes = []string{}
+	}
+	respondJSON(w, MigrateResponse{Success: true, YAML: string(out), Version: dsl.SchemaVersion, Notes: notes})
+}
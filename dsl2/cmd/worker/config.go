@@ -0,0 +1,351 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/activityplugin"
+	"github.com/temporalio/samples-go/dsl2/blob"
+	"github.com/temporalio/samples-go/dsl2/broker"
+	"github.com/temporalio/samples-go/dsl2/chaos"
+	"github.com/temporalio/samples-go/dsl2/openapi"
+	"github.com/temporalio/samples-go/dsl2/spill"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// workerConfig 是 worker 的调优参数：先读 WORKER_CONFIG 指向的 YAML 文件，再由同名环境变量覆盖；
+// 零值表示使用 SDK 默认值
+//
+//	identity: orders-worker-1
+//	mode: all                           # all / workflow / activity，见 Mode
+//	maxConcurrentActivities: 200
+//	maxConcurrentWorkflowTasks: 50
+//	workflowTaskPollers: 4
+//	activityTaskPollers: 8
+//	activitiesPerSecond: 100            # 本 worker 的 Activity 速率上限
+//	taskQueueActivitiesPerSecond: 500   # 整个任务队列的 Activity 速率上限（由服务端执行）
+//	stickyCacheSize: 20000
+//	drainTimeout: 10m                   # SIGTERM 后等待进行中 Activity 完成的时长
+//	buildId: "2024-06-01.1"             # 本 worker 的 Build ID
+//	useVersioning: true                 # 启用 Worker Deployment Versioning，见 versioning
+//	deploymentName: dsl-worker
+//	versioningBehavior: pinned          # pinned / auto-upgrade
+//	enableSessions: true                # 允许 session 语句把 Activity 固定到本主机
+//	maxConcurrentSessions: 100
+//	exec:                               # ExecCommand 允许执行的程序，未配置时禁用
+//	  allow: [/usr/bin/rsync, /opt/scripts/*]
+//	blobStores:                         # BlobPut/BlobGet/BlobList 可用的存储，见 blob 包
+//	  default: { type: file, dir: /var/lib/dsl/blobs }
+//	  artifacts: { type: s3, bucket: my-artifacts, region: eu-west-1 }
+//	  cache: { type: redis, endpoint: "redis:6379", db: 1, ttl: 24h }
+//	spill: { store: cache, threshold: 262144 }  # 超过阈值的 Activity 结果转存，变量中只保留引用，见 spill 包
+//	brokers:                            # PublishMessage/ConsumeMessage 可用的消息系统，见 broker 包
+//	  default: { type: kafka, brokers: [kafka:9092] }
+//	smtp:                               # SendEmail 使用的邮件服务器，未配置时禁用
+//	  { host: smtp.example.com, port: 587, username: dsl-bot, from: "DSL Bot <dsl-bot@example.com>" }
+//	plugins:                            # 额外的 Activity 实现，见 activityplugin 包
+//	  - { type: process, path: /plugins/text-activities }
+//	  - { type: go, path: /plugins/billing.so }
+//	openapi:                            # 按 operationId 生成的 HTTP Activity，见 openapi 包
+//	  - { spec: /specs/petstore.yaml, prefix: petstore., headerEnv: { Authorization: PETSTORE_AUTH } }
+//	chaos:                              # 为 Activity 注入故障，只用于演练环境，见 chaos 包
+//	  allowPerRun: true                 # 接受 starter -chaos 随执行传入的规则
+//	  rules:
+//	    - { activity: Charge*, probability: 0.2, error: gateway unavailable }
+type workerConfig struct {
+	// Mode 决定本进程轮询哪类任务：all（默认）同时执行 DSL 引擎与 Activity；workflow 只执行确定性的引擎；
+	// activity 只执行 Activity。两类 worker 共用任务队列，可以分别扩容
+	Mode                         string  `yaml:"mode"`
+	Identity                     string  `yaml:"identity"`
+	MaxConcurrentActivities      int     `yaml:"maxConcurrentActivities"`
+	MaxConcurrentWorkflowTasks   int     `yaml:"maxConcurrentWorkflowTasks"`
+	WorkflowTaskPollers          int     `yaml:"workflowTaskPollers"`
+	ActivityTaskPollers          int     `yaml:"activityTaskPollers"`
+	ActivitiesPerSecond          float64 `yaml:"activitiesPerSecond"`
+	TaskQueueActivitiesPerSecond float64 `yaml:"taskQueueActivitiesPerSecond"`
+	StickyCacheSize              int     `yaml:"stickyCacheSize"`
+	// DrainTimeout 是关闭时等待进行中 Activity 的上限，超时后取消其 context；0 为 SDK 默认（不等待）
+	DrainTimeout time.Duration `yaml:"drainTimeout"`
+	// BuildID 标识本 worker 的代码版本；UseVersioning 为 true 时服务端只把兼容的任务派给它，
+	// 新旧版本的引擎可共用任务队列，旧版本上的执行按 VersioningBehavior 留在原版本上跑完
+	BuildID            string `yaml:"buildId"`
+	UseVersioning      bool   `yaml:"useVersioning"`
+	DeploymentName     string `yaml:"deploymentName"`     // 默认 dsl-worker
+	VersioningBehavior string `yaml:"versioningBehavior"` // 默认 pinned
+	// EnableSessions 启用会话 worker，DSL 的 session 语句才能在本 worker 上创建会话；
+	// MaxConcurrentSessions 限制同时进行的会话数（0 为 SDK 默认）
+	EnableSessions        bool `yaml:"enableSessions"`
+	MaxConcurrentSessions int  `yaml:"maxConcurrentSessions"`
+	// Exec 是 ExecCommand 的允许列表
+	Exec *dsl.ExecPolicy `yaml:"exec"`
+	// BlobStores 是按名字引用的对象存储，未指定 store 的 Blob* Activity 使用 default
+	BlobStores map[string]blob.Config `yaml:"blobStores"`
+	// Spill 非空时把编码后超过阈值的 Activity 结果存入 blobStores 中的一个存储，工作流状态只保留引用
+	Spill *spill.Config `yaml:"spill"`
+	// Brokers 是按名字引用的消息系统，未指定 broker 的消息 Activity 使用 default
+	Brokers map[string]broker.Config `yaml:"brokers"`
+	// SMTP 是 SendEmail 的邮件服务器；密码可由 SMTP_PASSWORD 提供
+	SMTP *dsl.SMTPConfig `yaml:"smtp"`
+	// Plugins 在启动时加载，其中的 Activity 与内置 Activity 一起注册
+	Plugins []activityplugin.Spec `yaml:"plugins"`
+	// OpenAPI 中每份文档的每个操作注册为一个 Activity，请求经由 HTTPRequest 发送
+	OpenAPI []openapi.Config `yaml:"openapi"`
+	// Chaos 非空时按规则为本 worker 执行的 Activity 注入延迟、超时与错误
+	Chaos *chaos.Config `yaml:"chaos"`
+}
+
+// loadWorkerConfig 读取配置文件（可为空）并应用 WORKER_* 环境变量
+func loadWorkerConfig(path string) (workerConfig, error) {
+	var cfg workerConfig
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("read worker config: %w", err)
+		}
+		if err := yaml.Unmarshal(b, &cfg); err != nil {
+			return cfg, fmt.Errorf("parse worker config %s: %w", path, err)
+		}
+	}
+	if v := os.Getenv("WORKER_IDENTITY"); v != "" {
+		cfg.Identity = v
+	}
+	if v := os.Getenv("WORKER_MODE"); v != "" {
+		cfg.Mode = v
+	}
+	for env, dst := range map[string]*int{
+		"WORKER_MAX_CONCURRENT_ACTIVITIES":     &cfg.MaxConcurrentActivities,
+		"WORKER_MAX_CONCURRENT_WORKFLOW_TASKS": &cfg.MaxConcurrentWorkflowTasks,
+		"WORKER_WORKFLOW_TASK_POLLERS":         &cfg.WorkflowTaskPollers,
+		"WORKER_ACTIVITY_TASK_POLLERS":         &cfg.ActivityTaskPollers,
+		"WORKER_STICKY_CACHE_SIZE":             &cfg.StickyCacheSize,
+		"WORKER_MAX_CONCURRENT_SESSIONS":       &cfg.MaxConcurrentSessions,
+	} {
+		if v := os.Getenv(env); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return cfg, fmt.Errorf("%s: %w", env, err)
+			}
+			*dst = n
+		}
+	}
+	for env, dst := range map[string]*float64{
+		"WORKER_ACTIVITIES_PER_SECOND":            &cfg.ActivitiesPerSecond,
+		"WORKER_TASK_QUEUE_ACTIVITIES_PER_SECOND": &cfg.TaskQueueActivitiesPerSecond,
+	} {
+		if v := os.Getenv(env); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return cfg, fmt.Errorf("%s: %w", env, err)
+			}
+			*dst = f
+		}
+	}
+	for env, dst := range map[string]*string{
+		"WORKER_BUILD_ID":            &cfg.BuildID,
+		"WORKER_DEPLOYMENT_NAME":     &cfg.DeploymentName,
+		"WORKER_VERSIONING_BEHAVIOR": &cfg.VersioningBehavior,
+	} {
+		if v := os.Getenv(env); v != "" {
+			*dst = v
+		}
+	}
+	for env, dst := range map[string]*bool{
+		"WORKER_USE_VERSIONING":  &cfg.UseVersioning,
+		"WORKER_ENABLE_SESSIONS": &cfg.EnableSessions,
+	} {
+		if v := os.Getenv(env); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return cfg, fmt.Errorf("%s: %w", env, err)
+			}
+			*dst = b
+		}
+	}
+	if v := os.Getenv("WORKER_DRAIN_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("WORKER_DRAIN_TIMEOUT: %w", err)
+		}
+		cfg.DrainTimeout = d
+	}
+	return cfg, cfg.validate()
+}
+
+func (cfg workerConfig) validate() error {
+	for _, f := range []struct {
+		name string
+		n    float64
+	}{
+		{"maxConcurrentActivities", float64(cfg.MaxConcurrentActivities)},
+		{"maxConcurrentWorkflowTasks", float64(cfg.MaxConcurrentWorkflowTasks)},
+		{"workflowTaskPollers", float64(cfg.WorkflowTaskPollers)},
+		{"activityTaskPollers", float64(cfg.ActivityTaskPollers)},
+		{"activitiesPerSecond", cfg.ActivitiesPerSecond},
+		{"taskQueueActivitiesPerSecond", cfg.TaskQueueActivitiesPerSecond},
+		{"stickyCacheSize", float64(cfg.StickyCacheSize)},
+		{"maxConcurrentSessions", float64(cfg.MaxConcurrentSessions)},
+		{"drainTimeout", float64(cfg.DrainTimeout)},
+	} {
+		if f.n < 0 {
+			return fmt.Errorf("%s must not be negative", f.name)
+		}
+	}
+	switch cfg.Mode {
+	case "", modeAll, modeWorkflow, modeActivity:
+	default:
+		return fmt.Errorf("mode %q must be all, workflow or activity", cfg.Mode)
+	}
+	if cfg.Mode == modeWorkflow && cfg.EnableSessions {
+		return fmt.Errorf("enableSessions requires a worker that runs activities (mode all or activity)")
+	}
+	if cfg.UseVersioning && cfg.BuildID == "" {
+		return fmt.Errorf("useVersioning requires buildId")
+	}
+	// 会话任务走每个主机专属的任务队列，SDK 目前不支持与 worker versioning 同时使用
+	if cfg.UseVersioning && cfg.EnableSessions {
+		return fmt.Errorf("enableSessions cannot be combined with useVersioning")
+	}
+	if _, err := cfg.versioningBehavior(); err != nil {
+		return err
+	}
+	if cfg.Spill != nil {
+		if _, ok := cfg.BlobStores[cfg.Spill.Store]; !ok {
+			return fmt.Errorf("spill.store %q is not in blobStores", cfg.Spill.Store)
+		}
+		if cfg.Spill.Threshold < 0 {
+			return fmt.Errorf("spill.threshold must not be negative")
+		}
+	}
+	if cfg.Chaos != nil {
+		if err := chaos.Validate(cfg.Chaos.Rules); err != nil {
+			return fmt.Errorf("chaos: %w", err)
+		}
+	}
+	return nil
+}
+
+// worker 的运行模式
+const (
+	modeAll      = "all"
+	modeWorkflow = "workflow"
+	modeActivity = "activity"
+)
+
+// runsWorkflows 表示本 worker 注册并轮询 DSL Workflow
+func (cfg workerConfig) runsWorkflows() bool { return cfg.Mode != modeActivity }
+
+// runsActivities 表示本 worker 注册并轮询 Activity
+func (cfg workerConfig) runsActivities() bool { return cfg.Mode != modeWorkflow }
+
+// versioningBehavior 解析 VersioningBehavior；DSL 执行默认 pinned，解释器升级不会影响进行中的执行
+func (cfg workerConfig) versioningBehavior() (workflow.VersioningBehavior, error) {
+	switch cfg.VersioningBehavior {
+	case "", "pinned":
+		return workflow.VersioningBehaviorPinned, nil
+	case "auto-upgrade":
+		return workflow.VersioningBehaviorAutoUpgrade, nil
+	}
+	return workflow.VersioningBehaviorUnspecified, fmt.Errorf("versioningBehavior %q must be pinned or auto-upgrade", cfg.VersioningBehavior)
+}
+
+// redacted 返回去掉凭证的副本，用于打印日志
+func (cfg workerConfig) redacted() workerConfig {
+	out := cfg
+	out.BlobStores = map[string]blob.Config{}
+	for name, bc := range cfg.BlobStores {
+		bc.SecretAccessKey, bc.SessionToken, bc.AccessToken = redact(bc.SecretAccessKey), redact(bc.SessionToken), redact(bc.AccessToken)
+		bc.Password = redact(bc.Password)
+		out.BlobStores[name] = bc
+	}
+	out.Brokers = map[string]broker.Config{}
+	for name, bc := range cfg.Brokers {
+		bc.Password, bc.SecretAccessKey, bc.SessionToken = redact(bc.Password), redact(bc.SecretAccessKey), redact(bc.SessionToken)
+		out.Brokers[name] = bc
+	}
+	if cfg.SMTP != nil {
+		smtp := *cfg.SMTP
+		smtp.Password = redact(smtp.Password)
+		out.SMTP = &smtp
+	}
+	return out
+}
+
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "***"
+}
+
+// openBlobStores 打开配置中的全部对象存储
+func (cfg workerConfig) openBlobStores() (map[string]blob.Store, error) {
+	stores := map[string]blob.Store{}
+	for name, bc := range cfg.BlobStores {
+		s, err := blob.Open(bc)
+		if err != nil {
+			return nil, fmt.Errorf("blob store %s: %w", name, err)
+		}
+		stores[name] = s
+	}
+	return stores, nil
+}
+
+// openBrokers 连接配置中的全部消息系统；出错时关闭已建立的连接
+func (cfg workerConfig) openBrokers() (map[string]broker.Broker, error) {
+	brokers := map[string]broker.Broker{}
+	for name, bc := range cfg.Brokers {
+		b, err := broker.Open(bc)
+		if err != nil {
+			closeBrokers(brokers)
+			return nil, fmt.Errorf("broker %s: %w", name, err)
+		}
+		brokers[name] = b
+	}
+	return brokers, nil
+}
+
+func closeBrokers(brokers map[string]broker.Broker) {
+	for _, b := range brokers {
+		b.Close()
+	}
+}
+
+// options 生成 worker.Options；粘性缓存是进程级设置，须在创建 worker 之前调用
+func (cfg workerConfig) options() worker.Options {
+	if cfg.StickyCacheSize > 0 {
+		worker.SetStickyWorkflowCacheSize(cfg.StickyCacheSize)
+	}
+	opts := worker.Options{
+		MaxConcurrentActivityExecutionSize:     cfg.MaxConcurrentActivities,
+		MaxConcurrentWorkflowTaskExecutionSize: cfg.MaxConcurrentWorkflowTasks,
+		MaxConcurrentWorkflowTaskPollers:       cfg.WorkflowTaskPollers,
+		MaxConcurrentActivityTaskPollers:       cfg.ActivityTaskPollers,
+		WorkerActivitiesPerSecond:              cfg.ActivitiesPerSecond,
+		TaskQueueActivitiesPerSecond:           cfg.TaskQueueActivitiesPerSecond,
+		WorkerStopTimeout:                      cfg.DrainTimeout,
+		EnableSessionWorker:                    cfg.EnableSessions,
+		MaxConcurrentSessionExecutionSize:      cfg.MaxConcurrentSessions,
+		DisableWorkflowWorker:                  !cfg.runsWorkflows(),
+		LocalActivityWorkerOnly:                !cfg.runsActivities(),
+	}
+	if cfg.BuildID != "" {
+		deployment := cfg.DeploymentName
+		if deployment == "" {
+			deployment = "dsl-worker"
+		}
+		opts.DeploymentOptions.Version = worker.WorkerDeploymentVersion{DeploymentName: deployment, BuildId: cfg.BuildID}
+	}
+	if cfg.UseVersioning {
+		opts.DeploymentOptions.UseVersioning = true
+		opts.DeploymentOptions.DefaultVersioningBehavior, _ = cfg.versioningBehavior()
+	}
+	if cfg.Chaos != nil && cfg.runsActivities() {
+		opts.Interceptors = append(opts.Interceptors, chaos.NewInterceptor(*cfg.Chaos))
+	}
+	return opts
+}
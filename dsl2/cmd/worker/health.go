@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// pollerStaleAfter 内未在任务队列上出现过本 worker 的轮询即视为不健康
+const pollerStaleAfter = time.Minute
+
+// healthServer 是 worker 的健康检查 sidecar：/healthz 表示进程存活，
+// /readyz 检查 worker 已启动、Temporal 可达且本 worker 的 poller 最近在任务队列上出现过
+type healthServer struct {
+	c         client.Client
+	taskQueue string
+	identity  string
+	// activityOnly 的 worker 不轮询 Workflow 任务，改为检查 Activity 任务队列上的 poller
+	activityOnly bool
+	started      atomic.Bool
+}
+
+func (h *healthServer) serve(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("/readyz", h.handleReadyz)
+	log.Printf("Health endpoints on %s (/healthz, /readyz)", addr)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	if err := srv.ListenAndServe(); err != nil {
+		log.Printf("health server: %v", err)
+	}
+}
+
+func (h *healthServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]string{}
+	ready := true
+	fail := func(name string, err error) {
+		if err != nil {
+			ready = false
+			checks[name] = err.Error()
+		} else {
+			checks[name] = "ok"
+		}
+	}
+
+	if !h.started.Load() {
+		fail("worker", fmt.Errorf("not running"))
+	} else {
+		fail("worker", nil)
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	_, err := h.c.CheckHealth(ctx, &client.CheckHealthRequest{})
+	fail("temporal", err)
+	if err == nil {
+		fail("poller", h.checkPoller(ctx))
+	}
+
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, map[string]any{"status": status, "checks": checks})
+}
+
+// checkPoller 在任务队列的 poller 列表中查找本 worker 的身份
+func (h *healthServer) checkPoller(ctx context.Context) error {
+	queueType := enumspb.TASK_QUEUE_TYPE_WORKFLOW
+	if h.activityOnly {
+		queueType = enumspb.TASK_QUEUE_TYPE_ACTIVITY
+	}
+	resp, err := h.c.DescribeTaskQueue(ctx, h.taskQueue, queueType)
+	if err != nil {
+		return err
+	}
+	for _, p := range resp.GetPollers() {
+		if p.GetIdentity() != h.identity {
+			continue
+		}
+		if age := time.Since(p.GetLastAccessTime().AsTime()); age > pollerStaleAfter {
+			return fmt.Errorf("last poll %s ago", age.Round(time.Second))
+		}
+		return nil
+	}
+	return fmt.Errorf("no poller with identity %q on task queue %q", h.identity, h.taskQueue)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
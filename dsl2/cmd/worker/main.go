@@ -1,46 +1,137 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/activityplugin"
+	"github.com/temporalio/samples-go/dsl2/blob"
+	"github.com/temporalio/samples-go/dsl2/cmd/internal/conn"
+	"github.com/temporalio/samples-go/dsl2/openapi"
+	"github.com/temporalio/samples-go/dsl2/spill"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
 )
 
 func main() {
-	host := envOr("TEMPORAL_HOSTPORT", "localhost:7233")
-	ns := envOr("TEMPORAL_NAMESPACE", "default")
-	taskQueue := envOr("TASK_QUEUE", "demo")
-
-	c, err := client.Dial(client.Options{
-		HostPort:  host,
-		Namespace: ns,
-	})
+	// 连接参数（含 TEMPORAL_TLS_* 与 TEMPORAL_API_KEY）与 starter 相同；-profile/-config 或 DSL_PROFILE/DSL_CONFIG 选择共享的 Profile
+	if err := conn.LoadProfile(os.Args[1:]); err != nil {
+		log.Fatalf("%v", err)
+	}
+	connOpts := conn.FromEnv()
+	host, ns := connOpts.HostPort, connOpts.Namespace
+	taskQueue := conn.TaskQueue("demo")
+	healthAddr := os.Getenv("HEALTH_ADDR") // 如 ":8081"；为空不启动健康检查端点
+
+	cfg, err := loadWorkerConfig(os.Getenv("WORKER_CONFIG"))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// 显式设置身份，健康检查据此在任务队列的 poller 列表中找到自己
+	identity := cfg.Identity
+	if identity == "" {
+		hostname, _ := os.Hostname()
+		identity = fmt.Sprintf("%d@%s@dsl-worker", os.Getpid(), hostname)
+	}
+
+	opts, err := connOpts.ClientOptions()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	opts.Identity = identity
+
+	// 对象存储先于客户端打开：变量转存包装客户端的 DataConverter，Activity 也使用同一组存储
+	var blobs map[string]blob.Store
+	if cfg.runsActivities() {
+		if blobs, err = cfg.openBlobStores(); err != nil {
+			log.Fatalf("%v", err)
+		}
+		if cfg.Spill != nil {
+			opts.DataConverter = spill.NewDataConverter(opts.DataConverter, blobs[cfg.Spill.Store], *cfg.Spill)
+		}
+	}
+	c, err := client.Dial(opts)
 	if err != nil {
 		log.Fatalf("client.Dial: %v", err)
 	}
 	defer c.Close()
 
-	w := worker.New(c, taskQueue, worker.Options{})
+	running := &inflight{}
+	wopts := cfg.options()
+	wopts.Interceptors = append(wopts.Interceptors, running)
+	w := worker.New(c, taskQueue, wopts)
 
 	// 注册 DSL 的 Workflow
-	w.RegisterWorkflow(dsl.SimpleDSLWorkflow)
+	if cfg.runsWorkflows() {
+		w.RegisterWorkflow(dsl.SimpleDSLWorkflow)
+	}
 
 	// 注册示例 Activities
-	a := &dsl.Activities{}
-	w.RegisterActivity(a)
+	if cfg.runsActivities() {
+		brokers, err := cfg.openBrokers()
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer closeBrokers(brokers)
+		a := &dsl.Activities{Exec: cfg.Exec, Blobs: blobs, SMTP: cfg.SMTP, Brokers: brokers}
+		w.RegisterActivity(a)
+
+		plugins, err := activityplugin.LoadAll(cfg.Plugins, w, dsl.ActivityNames())
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer plugins.Close()
+		if len(plugins.Names) > 0 {
+			log.Printf("Plugin activities: %s", strings.Join(plugins.Names, ", "))
+		}
 
-	log.Printf("Worker started (namespace=%s, host=%s, taskQueue=%s)", ns, host, taskQueue)
-	if err := w.Run(worker.InterruptCh()); err != nil {
-		log.Fatalf("worker run failed: %v", err)
+		var ops []openapi.Operation
+		for _, spec := range cfg.OpenAPI {
+			loaded, err := openapi.Load(spec)
+			if err != nil {
+				log.Fatalf("openapi: %v", err)
+			}
+			ops = append(ops, loaded...)
+		}
+		if err := openapi.Register(w, ops, a, append(dsl.ActivityNames(), plugins.Names...)); err != nil {
+			log.Fatalf("openapi: %v", err)
+		}
+		if len(ops) > 0 {
+			log.Printf("OpenAPI activities: %d operation(s) from %d document(s)", len(ops), len(cfg.OpenAPI))
+		}
+	}
+
+	health := &healthServer{c: c, taskQueue: taskQueue, identity: identity, activityOnly: !cfg.runsWorkflows()}
+	if healthAddr != "" {
+		go health.serve(healthAddr)
 	}
-}
 
-func envOr(k, def string) string {
-	if v := os.Getenv(k); v != "" {
-		return v
+	if err := w.Start(); err != nil {
+		log.Fatalf("worker start failed: %v", err)
+	}
+	health.started.Store(true)
+	mode := cfg.Mode
+	if mode == "" {
+		mode = modeAll
+	}
+	log.Printf("Worker started (namespace=%s, host=%s, taskQueue=%s, identity=%s, mode=%s)", ns, host, taskQueue, identity, mode)
+	if cfg.BuildID != "" {
+		log.Printf("Worker version: buildId=%s useVersioning=%t", cfg.BuildID, cfg.UseVersioning)
+	}
+	log.Printf("Worker tuning: %+v", cfg.redacted())
+	if cfg.Chaos != nil && cfg.runsActivities() {
+		log.Printf("WARNING: chaos fault injection is enabled (%d rule(s), allowPerRun=%t)", len(cfg.Chaos.Rules), cfg.Chaos.AllowPerRun)
+	}
+	if cfg.Spill != nil && cfg.runsActivities() {
+		threshold := cfg.Spill.Threshold
+		if threshold == 0 {
+			threshold = spill.DefaultThreshold
+		}
+		log.Printf("Spilling activity results larger than %d bytes to blob store %s", threshold, cfg.Spill.Store)
 	}
-	return def
+	waitAndDrain(w, health, running, cfg.DrainTimeout)
 }
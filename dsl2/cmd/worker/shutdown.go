@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/worker"
+)
+
+// drainLogEvery 是排空期间报告剩余 Activity 数的间隔
+const drainLogEvery = 5 * time.Second
+
+// inflight 统计本 worker 正在执行的 Activity 数，用于关闭时的日志
+type inflight struct {
+	interceptor.WorkerInterceptorBase
+	n atomic.Int64
+}
+
+func (i *inflight) InterceptActivity(ctx context.Context, next interceptor.ActivityInboundInterceptor) interceptor.ActivityInboundInterceptor {
+	a := &inflightActivity{n: &i.n}
+	a.Next = next
+	return a
+}
+
+type inflightActivity struct {
+	interceptor.ActivityInboundInterceptorBase
+	n *atomic.Int64
+}
+
+func (a *inflightActivity) ExecuteActivity(ctx context.Context, in *interceptor.ExecuteActivityInput) (any, error) {
+	a.n.Add(1)
+	defer a.n.Add(-1)
+	return a.Next.ExecuteActivity(ctx, in)
+}
+
+// waitAndDrain 阻塞到 SIGINT/SIGTERM，然后标记未就绪并停止 worker：
+// 不再轮询新任务，进行中的 Activity 最多有 drainTimeout 完成（之后其 context 被取消）。
+// 排空期间再次收到信号立即退出
+func waitAndDrain(w worker.Worker, health *healthServer, running *inflight, drainTimeout time.Duration) {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigs
+
+	health.started.Store(false)
+	begin := time.Now()
+	slog.Info("worker draining", "signal", sig.String(), "drainTimeout", drainTimeout, "inFlightActivities", running.n.Load())
+
+	done := make(chan struct{})
+	go func() {
+		w.Stop()
+		close(done)
+	}()
+	tick := time.NewTicker(drainLogEvery)
+	defer tick.Stop()
+	for {
+		select {
+		case <-done:
+			elapsed := time.Since(begin)
+			slog.Info("worker stopped",
+				"elapsed", elapsed.Round(time.Millisecond),
+				"timedOut", drainTimeout > 0 && elapsed >= drainTimeout,
+				"abandonedActivities", running.n.Load())
+			return
+		case <-tick.C:
+			slog.Info("worker still draining", "elapsed", time.Since(begin).Round(time.Second), "inFlightActivities", running.n.Load())
+		case sig := <-sigs:
+			slog.Warn("second signal during drain, exiting now", "signal", sig.String(), "inFlightActivities", running.n.Load())
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package dsl
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeGraph 是 BuildGraph 的逆过程：把节点/边图还原为根语句数组。
+// 根序列沿 start 出发的 next 边展开，组合节点的子语句由 branch/body/then/else/step 边给出；
+// 存在环、悬空边或不可达节点时报错，保证画布上看到的就是引擎将要执行的
+func ComposeGraph(g *Graph) ([]*Statement, error) {
+	if g == nil {
+		return nil, fmt.Errorf("graph is empty")
+	}
+	nodes := make(map[string]*GraphNode, len(g.Nodes))
+	for _, n := range g.Nodes {
+		if _, dup := nodes[n.ID]; dup {
+			return nil, fmt.Errorf("duplicate node id %q", n.ID)
+		}
+		nodes[n.ID] = n
+	}
+	if _, ok := nodes[GraphStartID]; !ok {
+		return nil, fmt.Errorf("graph has no %q node", GraphStartID)
+	}
+
+	next := map[string]string{}
+	children := map[string][]*GraphEdge{}
+	for _, e := range g.Edges {
+		if nodes[e.From] == nil || nodes[e.To] == nil {
+			return nil, fmt.Errorf("edge %s -> %s references unknown node", e.From, e.To)
+		}
+		if e.Kind == "next" || e.Kind == "" {
+			if prev, ok := next[e.From]; ok && prev != e.To {
+				return nil, fmt.Errorf("node %q has more than one next node (%s, %s)", e.From, prev, e.To)
+			}
+			next[e.From] = e.To
+			continue
+		}
+		children[e.From] = append(children[e.From], e)
+	}
+
+	used := map[string]bool{GraphStartID: true}
+	var build func(id string) (*Statement, error)
+	build = func(id string) (*Statement, error) {
+		if used[id] {
+			return nil, fmt.Errorf("node %q is reachable more than once (cycle or shared child)", id)
+		}
+		used[id] = true
+		n := nodes[id]
+		if n.Spec == nil {
+			return nil, fmt.Errorf("node %q has no spec", id)
+		}
+		st := shallowSpec(n.Spec)
+		if st.Kind() != n.Type {
+			return nil, fmt.Errorf("node %q: type %q does not match spec (%q)", id, n.Type, st.Kind())
+		}
+
+		edges := children[id]
+		sort.SliceStable(edges, func(i, j int) bool { return edges[i].Index < edges[j].Index })
+		for _, e := range edges {
+			child, err := build(e.To)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case e.Kind == "branch" && st.Parallel != nil:
+				*st.Parallel = append(*st.Parallel, child)
+			case e.Kind == "body" && st.Map != nil:
+				st.Map.Body = child
+			case e.Kind == "body" && st.While != nil:
+				st.While.Body = child
+			case e.Kind == "step" && st.Session != nil:
+				st.Session.Steps = append(st.Session.Steps, child)
+			case e.Kind == "then" && st.If != nil:
+				st.If.Then = child
+			case e.Kind == "else" && st.If != nil:
+				st.If.Else = child
+			default:
+				return nil, fmt.Errorf("edge %s -> %s: %q is not valid for a %s node", id, e.To, e.Kind, n.Type)
+			}
+		}
+		return st, nil
+	}
+
+	var root []*Statement
+	for cur := next[GraphStartID]; cur != "" && nodes[cur].Type != "end"; cur = next[cur] {
+		st, err := build(cur)
+		if err != nil {
+			return nil, err
+		}
+		root = append(root, st)
+	}
+
+	var unreachable []string
+	for id, n := range nodes {
+		if !used[id] && n.Type != "end" {
+			unreachable = append(unreachable, id)
+		}
+	}
+	if len(unreachable) > 0 {
+		sort.Strings(unreachable)
+		return nil, fmt.Errorf("nodes not connected to start: %s", strings.Join(unreachable, ", "))
+	}
+	return root, nil
+}
+
+// ParseYAML 把 YAML 定义解析为 Workflow（不做校验）；starter、web UI 等入口统一使用，保证与引擎的模型一致。
+// 先检查 version：旧版本或未知版本的定义不按当前格式解析，旧版本需先用 Migrate 升级
+func ParseYAML(b []byte) (Workflow, error) {
+	var head struct {
+		Version string `yaml:"version"`
+	}
+	if yaml.Unmarshal(b, &head) == nil {
+		if err := checkVersion(head.Version); err != nil {
+			return Workflow{}, err
+		}
+	}
+	var wf Workflow
+	if err := yaml.Unmarshal(b, &wf); err != nil {
+		return Workflow{}, err
+	}
+	return wf, nil
+}
+
+// MarshalYAML 校验工作流并以规范格式（两空格缩进、字段按模型顺序）输出 YAML
+func MarshalYAML(wf Workflow) ([]byte, error) {
+	if err := wf.validate(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(wf); err != nil {
+		return nil, fmt.Errorf("marshal yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,423 @@
+// Package argo 把 Argo Workflows 定义（Workflow / WorkflowTemplate）转换为 DSL 工作流。
+// 容器、脚本等叶子模板映射为同名 Activity；无法表达的结构记录在返回的 Note 列表中
+package argo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"gopkg.in/yaml.v3"
+)
+
+// Document 只包含转换用到的字段，其余字段被忽略
+type Document struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name         string `yaml:"name"`
+		GenerateName string `yaml:"generateName"`
+	} `yaml:"metadata"`
+	Spec Spec `yaml:"spec"`
+}
+
+type Spec struct {
+	Entrypoint string     `yaml:"entrypoint"`
+	Arguments  Arguments  `yaml:"arguments"`
+	Templates  []Template `yaml:"templates"`
+}
+
+type Arguments struct {
+	Parameters []Parameter `yaml:"parameters"`
+}
+
+type Parameter struct {
+	Name  string `yaml:"name"`
+	Value any    `yaml:"value"`
+}
+
+// Template 的叶子类型只关心是否存在
+type Template struct {
+	Name      string    `yaml:"name"`
+	Container any       `yaml:"container"`
+	Script    any       `yaml:"script"`
+	Resource  any       `yaml:"resource"`
+	HTTP      any       `yaml:"http"`
+	Suspend   any       `yaml:"suspend"`
+	Steps     [][]Step  `yaml:"steps"`
+	DAG       *DAG      `yaml:"dag"`
+	Inputs    Arguments `yaml:"inputs"`
+	// ActiveDeadlineSeconds 映射为 Activity 的 StartToClose 超时
+	ActiveDeadlineSeconds int `yaml:"activeDeadlineSeconds"`
+}
+
+// Step 同时用于 steps 与 dag.tasks
+type Step struct {
+	Name         string    `yaml:"name"`
+	Template     string    `yaml:"template"`
+	Arguments    Arguments `yaml:"arguments"`
+	When         string    `yaml:"when"`
+	WithItems    []any     `yaml:"withItems"`
+	WithParam    string    `yaml:"withParam"`
+	Dependencies []string  `yaml:"dependencies"`
+	Depends      string    `yaml:"depends"`
+}
+
+type DAG struct {
+	Tasks []Step `yaml:"tasks"`
+}
+
+// Note 记录一个未能（完整）转换的步骤或模板
+type Note struct {
+	Step    string `json:"step,omitempty"`
+	Message string `json:"message"`
+}
+
+func (n Note) String() string {
+	if n.Step == "" {
+		return n.Message
+	}
+	return n.Step + ": " + n.Message
+}
+
+// Options 控制生成的工作流
+type Options struct {
+	TaskQueue string // 为空时使用 "demo"
+}
+
+// Convert 解析 Argo YAML/JSON 并生成 DSL 工作流；步骤名写入 Statement.ID 便于对照
+func Convert(data []byte, opts Options) (dsl.Workflow, []Note, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return dsl.Workflow{}, nil, fmt.Errorf("parse argo workflow: %w", err)
+	}
+	if doc.Spec.Entrypoint == "" || len(doc.Spec.Templates) == 0 {
+		return dsl.Workflow{}, nil, fmt.Errorf("workflow needs spec.entrypoint and spec.templates")
+	}
+	if opts.TaskQueue == "" {
+		opts.TaskQueue = "demo"
+	}
+	c := &converter{
+		templates: map[string]*Template{},
+		vars:      map[string]any{},
+		used:      outputRefs(data),
+		visiting:  map[string]bool{},
+	}
+	for i := range doc.Spec.Templates {
+		c.templates[doc.Spec.Templates[i].Name] = &doc.Spec.Templates[i]
+	}
+	for _, p := range doc.Spec.Arguments.Parameters {
+		c.vars[p.Name] = p.Value
+	}
+	wf := dsl.Workflow{
+		Version:   dsl.SchemaVersion,
+		TaskQueue: opts.TaskQueue,
+		Root:      c.template(doc.Spec.Entrypoint, doc.Spec.Entrypoint, nil),
+	}
+	if len(c.vars) > 0 {
+		wf.Variables = c.vars
+	}
+	return wf, c.notes, nil
+}
+
+type converter struct {
+	templates map[string]*Template
+	vars      map[string]any
+	used      map[string]bool // 被 {{steps/tasks.X.outputs.result}} 引用的步骤
+	visiting  map[string]bool
+	notes     []Note
+}
+
+func (c *converter) notef(step, format string, args ...any) {
+	c.notes = append(c.notes, Note{Step: step, Message: fmt.Sprintf(format, args...)})
+}
+
+var outputRefRE = regexp.MustCompile(`\{\{\s*(?:steps|tasks)\.([A-Za-z0-9_-]+)\.outputs\.result\s*\}\}`)
+
+func outputRefs(data []byte) map[string]bool {
+	out := map[string]bool{}
+	for _, m := range outputRefRE.FindAllSubmatch(data, -1) {
+		out[string(m[1])] = true
+	}
+	return out
+}
+
+// template 转换一次模板调用；step 是调用它的步骤名（入口为模板名）
+func (c *converter) template(name, step string, inv *Step) []*dsl.Statement {
+	t := c.templates[name]
+	if t == nil {
+		c.notef(step, "template %q does not exist", name)
+		return nil
+	}
+	if c.visiting[name] {
+		c.notef(step, "template %q is recursive; recursion is not converted", name)
+		return nil
+	}
+	c.visiting[name] = true
+	defer delete(c.visiting, name)
+
+	switch {
+	case t.Steps != nil:
+		return c.steps(t)
+	case t.DAG != nil:
+		return c.dag(t)
+	case t.Suspend != nil:
+		c.notef(step, "suspend template %q is not supported", name)
+		return nil
+	case t.Container != nil || t.Script != nil || t.Resource != nil || t.HTTP != nil:
+		return []*dsl.Statement{c.leaf(t, step, inv)}
+	}
+	c.notef(step, "template %q has no supported body", name)
+	return nil
+}
+
+// leaf 把容器/脚本等模板映射为同名 Activity；参数按调用处 arguments 的顺序传入
+func (c *converter) leaf(t *Template, step string, inv *Step) *dsl.Statement {
+	act := &dsl.ActivityInvocation{Name: activityName(t.Name)}
+	if inv != nil {
+		for _, p := range inv.Arguments.Parameters {
+			act.Args = append(act.Args, c.value(step, p.Value))
+		}
+		if c.used[inv.Name] {
+			act.Result = varName(inv.Name)
+		}
+	}
+	if t.ActiveDeadlineSeconds > 0 {
+		act.Opts = &dsl.ActOpts{StartToCloseSeconds: t.ActiveDeadlineSeconds}
+	}
+	return &dsl.Statement{ID: step, Activity: act}
+}
+
+// activityName 把 kebab-case 模板名转为 Go 风格的 Activity 名：send-email -> SendEmail
+func activityName(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// varName 把步骤名转为变量名：fetch-data -> fetch_data
+func varName(s string) string {
+	return strings.NewReplacer("-", "_", ".", "_").Replace(s)
+}
+
+var exprRE = regexp.MustCompile(`^\{\{\s*([^}]+?)\s*\}\}$`)
+
+// value 把参数值转为 DSL 值：{{workflow.parameters.x}}/{{inputs.parameters.x}} -> ref x，
+// {{item}} -> ref item，{{steps.X.outputs.result}} -> ref X；其他按字面量
+func (c *converter) value(step string, v any) dsl.Value {
+	s, ok := v.(string)
+	if !ok {
+		return literal(v)
+	}
+	m := exprRE.FindStringSubmatch(s)
+	if m == nil {
+		if strings.Contains(s, "{{") {
+			c.notef(step, "interpolated value %q is passed as a literal string", s)
+		}
+		return dsl.Value{Str: &s}
+	}
+	if ref, ok := c.ref(m[1]); ok {
+		return dsl.Value{Ref: ref}
+	}
+	c.notef(step, "expression %q cannot be mapped to a variable", s)
+	return dsl.Value{Str: &s}
+}
+
+func (c *converter) ref(expr string) (string, bool) {
+	for _, prefix := range []string{"workflow.parameters.", "inputs.parameters."} {
+		if name, ok := strings.CutPrefix(expr, prefix); ok {
+			return name, true
+		}
+	}
+	if expr == "item" {
+		return "item", true
+	}
+	if m := outputRefRE.FindStringSubmatch("{{" + expr + "}}"); m != nil {
+		return varName(m[1]), true
+	}
+	return "", false
+}
+
+func literal(v any) dsl.Value {
+	switch x := v.(type) {
+	case bool:
+		return dsl.Value{Bool: &x}
+	case int:
+		n := int64(x)
+		return dsl.Value{Int: &n}
+	case float64:
+		return dsl.Value{Float: &x}
+	}
+	s := fmt.Sprint(v)
+	return dsl.Value{Str: &s}
+}
+
+// steps：外层列表顺序执行，同一组内的步骤并行
+func (c *converter) steps(t *Template) []*dsl.Statement {
+	var out []*dsl.Statement
+	for _, group := range t.Steps {
+		var stmts []*dsl.Statement
+		for i := range group {
+			if s := c.step(&group[i]); s != nil {
+				stmts = append(stmts, s)
+			}
+		}
+		out = appendGroup(out, stmts, "")
+	}
+	return out
+}
+
+// dag 按依赖关系分层：同一层的任务并行，层与层之间顺序执行
+func (c *converter) dag(t *Template) []*dsl.Statement {
+	level := map[string]int{}
+	byName := map[string]*Step{}
+	for i := range t.DAG.Tasks {
+		byName[t.DAG.Tasks[i].Name] = &t.DAG.Tasks[i]
+	}
+	var depth func(name string, seen map[string]bool) int
+	depth = func(name string, seen map[string]bool) int {
+		if l, ok := level[name]; ok {
+			return l
+		}
+		task := byName[name]
+		if task == nil || seen[name] {
+			return 0
+		}
+		seen[name] = true
+		l := 0
+		for _, d := range c.deps(task) {
+			l = max(l, depth(d, seen)+1)
+		}
+		level[name] = l
+		return l
+	}
+	maxLevel := 0
+	for name := range byName {
+		maxLevel = max(maxLevel, depth(name, map[string]bool{}))
+	}
+	var out []*dsl.Statement
+	for l := 0; l <= maxLevel; l++ {
+		var stmts []*dsl.Statement
+		for i := range t.DAG.Tasks {
+			task := &t.DAG.Tasks[i]
+			if level[task.Name] != l {
+				continue
+			}
+			if s := c.step(task); s != nil {
+				stmts = append(stmts, s)
+			}
+		}
+		out = appendGroup(out, stmts, fmt.Sprintf("%s-level-%d", t.Name, l))
+	}
+	return out
+}
+
+var dependsNameRE = regexp.MustCompile(`[A-Za-z0-9_-]+(\.[A-Za-z]+)?`)
+
+// deps 读取 dependencies 或 depends；depends 中的 ||、! 与结果判断只按“依赖该任务”处理
+func (c *converter) deps(task *Step) []string {
+	if task.Depends == "" {
+		return task.Dependencies
+	}
+	if strings.ContainsAny(task.Depends, "|!") || strings.Contains(task.Depends, ".") {
+		c.notef(task.Name, "depends %q is simplified to run after all referenced tasks", task.Depends)
+	}
+	var out []string
+	for _, m := range dependsNameRE.FindAllString(task.Depends, -1) {
+		name, _, _ := strings.Cut(m, ".")
+		out = append(out, name)
+	}
+	return out
+}
+
+// appendGroup 把一组并行语句追加到序列：单个语句直接追加，多个包成 parallel
+func appendGroup(out, stmts []*dsl.Statement, id string) []*dsl.Statement {
+	switch len(stmts) {
+	case 0:
+		return out
+	case 1:
+		return append(out, stmts[0])
+	}
+	p := dsl.Parallel(stmts)
+	return append(out, &dsl.Statement{ID: id, Parallel: &p})
+}
+
+// step 转换一个步骤/任务，处理 withItems/withParam 与 when
+func (c *converter) step(s *Step) *dsl.Statement {
+	body := c.single(s.Name, c.template(s.Template, s.Name, s))
+	if body == nil {
+		return nil
+	}
+	switch {
+	case s.WithItems != nil:
+		itemsVar := varName(s.Name) + "_items"
+		c.vars[itemsVar] = s.WithItems
+		body = &dsl.Statement{ID: s.Name, Map: &dsl.Map{ItemsRef: itemsVar, ItemVar: "item", Body: body}}
+	case s.WithParam != "":
+		m := exprRE.FindStringSubmatch(s.WithParam)
+		ref := ""
+		if m != nil {
+			ref, _ = c.ref(m[1])
+		}
+		if ref == "" {
+			c.notef(s.Name, "withParam %q cannot be mapped to a variable", s.WithParam)
+			ref = varName(s.Name) + "_items"
+		}
+		body = &dsl.Statement{ID: s.Name, Map: &dsl.Map{ItemsRef: ref, ItemVar: "item", Body: body}}
+	}
+	if s.When != "" {
+		body = &dsl.Statement{ID: s.Name, If: &dsl.If{Cond: c.when(s.Name, s.When), Then: body}}
+	}
+	return body
+}
+
+// single 把模板展开的语句收敛为一条；DSL 的分支/循环体只能容纳一条语句
+func (c *converter) single(step string, stmts []*dsl.Statement) *dsl.Statement {
+	if len(stmts) == 0 {
+		return nil
+	}
+	if len(stmts) > 1 {
+		dropped := make([]string, 0, len(stmts)-1)
+		for _, s := range stmts[1:] {
+			dropped = append(dropped, s.ID)
+		}
+		c.notef(step, "template expands to %d steps but a DSL branch holds one statement; dropped %s",
+			len(stmts), strings.Join(dropped, ", "))
+	}
+	return stmts[0]
+}
+
+var whenRE = regexp.MustCompile(`^\s*(\S+)\s*(==|!=)\s*(\S+)\s*$`)
+
+// when 支持 "{{x}} == value" / "!=" 形式；其他表达式退化为恒真条件并记录 Note
+func (c *converter) when(step, expr string) dsl.Cond {
+	m := whenRE.FindStringSubmatch(expr)
+	if m == nil {
+		c.notef(step, "when %q is not supported; replaced with an always-true check, review the condition", expr)
+		t := true
+		return dsl.Cond{Truthy: &dsl.Value{Bool: &t}}
+	}
+	left, right := c.operand(step, m[1]), c.operand(step, m[3])
+	cmp := &dsl.Compare{Left: left, Right: right}
+	if m[2] == "!=" {
+		return dsl.Cond{Ne: cmp}
+	}
+	return dsl.Cond{Eq: cmp}
+}
+
+func (c *converter) operand(step, s string) dsl.Value {
+	if exprRE.MatchString(s) {
+		return c.value(step, s)
+	}
+	s = strings.Trim(s, `"'`)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return dsl.Value{Int: &n}
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return dsl.Value{Bool: &b}
+	}
+	return dsl.Value{Str: &s}
+}
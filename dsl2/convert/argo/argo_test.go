@@ -0,0 +1,107 @@
+package argo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+const pipeline = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: pipeline-
+spec:
+  entrypoint: main
+  arguments:
+    parameters:
+      - name: env
+        value: prod
+  templates:
+    - name: main
+      steps:
+        - - name: fetch
+            template: fetch-data
+            arguments:
+              parameters:
+                - { name: env, value: "{{workflow.parameters.env}}" }
+        - - name: train
+            template: train-model
+            arguments:
+              parameters:
+                - { name: data, value: "{{steps.fetch.outputs.result}}" }
+          - name: notify
+            template: send-email
+            when: "{{workflow.parameters.env}} == prod"
+        - - name: publish
+            template: publish
+            withItems: [eu, us]
+            arguments:
+              parameters:
+                - { name: region, value: "{{item}}" }
+        - - name: wait
+            template: approve
+    - name: fetch-data
+      container: { image: alpine }
+      activeDeadlineSeconds: 60
+    - name: train-model
+      script: { image: python, source: "print(1)" }
+    - name: send-email
+      container: { image: mailer }
+    - name: publish
+      container: { image: alpine }
+    - name: approve
+      suspend: {}
+`
+
+func TestConvertSteps(t *testing.T) {
+	wf, notes, err := Convert([]byte(pipeline), Options{})
+	require.NoError(t, err)
+	require.Equal(t, "prod", wf.Variables["env"])
+	require.Equal(t, []any{"eu", "us"}, wf.Variables["publish_items"])
+	require.Len(t, wf.Root, 3)
+
+	fetch := wf.Root[0].Activity
+	require.Equal(t, "FetchData", fetch.Name)
+	require.Equal(t, "env", fetch.Args[0].Ref)
+	require.Equal(t, "fetch", fetch.Result)
+	require.Equal(t, 60, fetch.Opts.StartToCloseSeconds)
+
+	par := *wf.Root[1].Parallel
+	require.Equal(t, "fetch", par[0].Activity.Args[0].Ref)
+	require.Equal(t, "env", par[1].If.Cond.Eq.Left.Ref)
+	require.Equal(t, "prod", *par[1].If.Cond.Eq.Right.Str)
+	require.Equal(t, "SendEmail", par[1].If.Then.Activity.Name)
+
+	m := wf.Root[2].Map
+	require.Equal(t, "publish_items", m.ItemsRef)
+	require.Equal(t, "item", m.Body.Activity.Args[0].Ref)
+
+	require.Equal(t, []Note{{Step: "wait", Message: `suspend template "approve" is not supported`}}, notes)
+	require.False(t, dsl.HasErrors(wf.Check(dsl.CheckOptions{})))
+}
+
+func TestConvertDAG(t *testing.T) {
+	wf, notes, err := Convert([]byte(`
+spec:
+  entrypoint: diamond
+  templates:
+    - name: diamond
+      dag:
+        tasks:
+          - { name: A, template: echo }
+          - { name: B, template: echo, dependencies: [A] }
+          - { name: C, template: echo, dependencies: [A] }
+          - { name: D, template: echo, depends: "B && C.Succeeded" }
+    - name: echo
+      container: { image: alpine }
+`), Options{TaskQueue: "argo"})
+	require.NoError(t, err)
+	require.Equal(t, "argo", wf.TaskQueue)
+	require.Len(t, wf.Root, 3)
+	require.Equal(t, "A", wf.Root[0].ID)
+	require.Len(t, *wf.Root[1].Parallel, 2)
+	require.Equal(t, "D", wf.Root[2].ID)
+	require.Equal(t, []Note{{Step: "D", Message: `depends "B && C.Succeeded" is simplified to run after all referenced tasks`}}, notes)
+}
@@ -0,0 +1,583 @@
+// Package asl 把 AWS Step Functions（Amazon States Language）定义转换为 DSL 工作流：
+// Task/Parallel/Map/Choice/Wait 映射为对应语句，Task 的 Retry 映射为重试策略。
+// 无法表达的结构（如 Catch）不会中断转换，而是记录在返回的 Note 列表中供人工复核
+package asl
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+// StateMachine 是 ASL 顶层文档，也用于 Parallel 分支与 Map 迭代器
+type StateMachine struct {
+	Comment string            `json:"Comment,omitempty"`
+	StartAt string            `json:"StartAt"`
+	States  map[string]*State `json:"States"`
+}
+
+// State 只包含转换用到的字段，其余字段被忽略
+type State struct {
+	Type    string `json:"Type"`
+	Comment string `json:"Comment,omitempty"`
+	Next    string `json:"Next,omitempty"`
+	End     bool   `json:"End,omitempty"`
+
+	// Task
+	Resource         string         `json:"Resource,omitempty"`
+	Parameters       map[string]any `json:"Parameters,omitempty"`
+	ResultPath       string         `json:"ResultPath,omitempty"`
+	TimeoutSeconds   int            `json:"TimeoutSeconds,omitempty"`
+	HeartbeatSeconds int            `json:"HeartbeatSeconds,omitempty"`
+	Retry            []Retrier      `json:"Retry,omitempty"`
+	Catch            []Catcher      `json:"Catch,omitempty"`
+
+	// Wait
+	Seconds       int    `json:"Seconds,omitempty"`
+	SecondsPath   string `json:"SecondsPath,omitempty"`
+	Timestamp     string `json:"Timestamp,omitempty"`
+	TimestampPath string `json:"TimestampPath,omitempty"`
+
+	// Choice
+	Choices []ChoiceRule `json:"Choices,omitempty"`
+	Default string       `json:"Default,omitempty"`
+
+	// Parallel
+	Branches []StateMachine `json:"Branches,omitempty"`
+
+	// Map（Iterator 为旧写法，ItemProcessor 优先）
+	ItemsPath      string        `json:"ItemsPath,omitempty"`
+	MaxConcurrency int           `json:"MaxConcurrency,omitempty"`
+	Iterator       *StateMachine `json:"Iterator,omitempty"`
+	ItemProcessor  *StateMachine `json:"ItemProcessor,omitempty"`
+
+	// Pass
+	Result any `json:"Result,omitempty"`
+}
+
+// Retrier 是 Retry 中的一项；未填的字段使用 ASL 默认值（间隔 1 秒、重试 3 次、倍率 2.0）
+type Retrier struct {
+	ErrorEquals     []string `json:"ErrorEquals"`
+	IntervalSeconds *int     `json:"IntervalSeconds,omitempty"`
+	MaxAttempts     *int     `json:"MaxAttempts,omitempty"`
+	BackoffRate     *float64 `json:"BackoffRate,omitempty"`
+	MaxDelaySeconds int      `json:"MaxDelaySeconds,omitempty"`
+}
+
+// Catcher 是 Catch 中的一项
+type Catcher struct {
+	ErrorEquals []string `json:"ErrorEquals"`
+	Next        string   `json:"Next"`
+}
+
+// ChoiceRule 是 Choice 的一条规则；只支持相等比较与 And/Or/Not 组合
+type ChoiceRule struct {
+	Variable      string       `json:"Variable,omitempty"`
+	StringEquals  *string      `json:"StringEquals,omitempty"`
+	NumericEquals *float64     `json:"NumericEquals,omitempty"`
+	BooleanEquals *bool        `json:"BooleanEquals,omitempty"`
+	And           []ChoiceRule `json:"And,omitempty"`
+	Or            []ChoiceRule `json:"Or,omitempty"`
+	Not           *ChoiceRule  `json:"Not,omitempty"`
+	Next          string       `json:"Next,omitempty"`
+
+	unsupported []string // 无法转换的比较运算符
+}
+
+var knownRuleKeys = map[string]bool{
+	"Variable": true, "StringEquals": true, "NumericEquals": true, "BooleanEquals": true,
+	"And": true, "Or": true, "Not": true, "Next": true, "Comment": true,
+}
+
+func (r *ChoiceRule) UnmarshalJSON(b []byte) error {
+	type plain ChoiceRule
+	if err := json.Unmarshal(b, (*plain)(r)); err != nil {
+		return err
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return err
+	}
+	for k := range keys {
+		if !knownRuleKeys[k] {
+			r.unsupported = append(r.unsupported, k)
+		}
+	}
+	sort.Strings(r.unsupported)
+	return nil
+}
+
+// Note 记录一个未能（完整）转换的状态
+type Note struct {
+	State   string `json:"state,omitempty"`
+	Message string `json:"message"`
+}
+
+func (n Note) String() string {
+	if n.State == "" {
+		return n.Message
+	}
+	return n.State + ": " + n.Message
+}
+
+// Options 控制生成的工作流
+type Options struct {
+	TaskQueue string // 为空时使用 "demo"
+}
+
+// Convert 解析 ASL JSON 并生成等价的 DSL 工作流；状态名写入 Statement.ID 便于对照
+func Convert(data []byte, opts Options) (dsl.Workflow, []Note, error) {
+	var sm StateMachine
+	if err := json.Unmarshal(data, &sm); err != nil {
+		return dsl.Workflow{}, nil, fmt.Errorf("parse state machine: %w", err)
+	}
+	if sm.StartAt == "" || len(sm.States) == 0 {
+		return dsl.Workflow{}, nil, fmt.Errorf("state machine needs StartAt and States")
+	}
+	if opts.TaskQueue == "" {
+		opts.TaskQueue = "demo"
+	}
+	c := &converter{vars: map[string]any{}}
+	wf := dsl.Workflow{
+		Version:   dsl.SchemaVersion,
+		TaskQueue: opts.TaskQueue,
+		Root:      c.sequence(sm.States, sm.StartAt, "", ""),
+	}
+	if len(c.vars) > 0 {
+		wf.Variables = c.vars
+	}
+	return wf, c.notes, nil
+}
+
+type converter struct {
+	notes []Note
+	vars  map[string]any // Pass 状态折叠出的初始变量
+}
+
+func (c *converter) notef(state, format string, args ...any) {
+	c.notes = append(c.notes, Note{State: state, Message: fmt.Sprintf(format, args...)})
+}
+
+// sequence 从 start 沿 Next 转换到 stop（不含）或终止状态；item 是所在 Map 的元素变量名
+func (c *converter) sequence(states map[string]*State, start, stop, item string) []*dsl.Statement {
+	var out []*dsl.Statement
+	seen := map[string]bool{}
+	for cur := start; cur != "" && cur != stop; {
+		st := states[cur]
+		if st == nil {
+			c.notef(cur, "state does not exist")
+			break
+		}
+		if seen[cur] {
+			c.notef(cur, "loops back to an earlier state; loops are not converted")
+			break
+		}
+		seen[cur] = true
+
+		next := st.Next
+		if st.Type == "Parallel" || st.Type == "Map" {
+			if len(st.Retry) > 0 {
+				c.notef(cur, "Retry on a %s state is not converted; add retries to the activities inside", st.Type)
+			}
+			c.catch(cur, st)
+		}
+		switch st.Type {
+		case "Task":
+			out = appendStmt(out, c.task(cur, st, item))
+		case "Parallel":
+			out = appendStmt(out, c.parallel(cur, st, item))
+		case "Map":
+			out = appendStmt(out, c.mapState(cur, st, item))
+		case "Pass":
+			c.pass(cur, st)
+		case "Wait":
+			out = appendStmt(out, c.wait(cur, st, item))
+		case "Choice":
+			join := joinOf(states, st, map[*State]bool{})
+			out = appendStmt(out, c.choice(cur, st, states, join, item))
+			next = join
+		case "Succeed":
+			return out
+		case "Fail":
+			c.notef(cur, "Fail state is not converted; the branch simply ends")
+			return out
+		default:
+			c.notef(cur, "%s state is not supported", st.Type)
+		}
+		if st.End {
+			break
+		}
+		cur = next
+	}
+	return out
+}
+
+func appendStmt(out []*dsl.Statement, s *dsl.Statement) []*dsl.Statement {
+	if s == nil {
+		return out
+	}
+	return append(out, s)
+}
+
+// single 把一段状态序列收敛为一条语句；DSL 的分支/循环体只能容纳一条语句
+func (c *converter) single(states map[string]*State, start, stop, item string) *dsl.Statement {
+	stmts := c.sequence(states, start, stop, item)
+	if len(stmts) == 0 {
+		return nil
+	}
+	if len(stmts) > 1 {
+		dropped := make([]string, 0, len(stmts)-1)
+		for _, s := range stmts[1:] {
+			dropped = append(dropped, s.ID)
+		}
+		c.notef(start, "branch has %d steps but a DSL branch holds one statement; dropped %s",
+			len(stmts), strings.Join(dropped, ", "))
+	}
+	return stmts[0]
+}
+
+func (c *converter) task(name string, st *State, item string) *dsl.Statement {
+	act := &dsl.ActivityInvocation{Name: activityName(st)}
+	if act.Name == "" {
+		c.notef(name, "cannot derive an activity name from Resource %q", st.Resource)
+		act.Name = name
+	}
+	params := st.Parameters
+	if strings.HasSuffix(st.Resource, ":lambda:invoke") {
+		params = nil
+		if p, ok := st.Parameters["Payload"].(map[string]any); ok {
+			params = p
+		} else if p, ok := st.Parameters["Payload.$"].(string); ok {
+			params = map[string]any{"Payload.$": p}
+		}
+	}
+	act.Args = c.args(name, params, item)
+	act.Result = c.resultVar(name, st.ResultPath)
+	if st.TimeoutSeconds > 0 || st.HeartbeatSeconds > 0 {
+		act.Opts = &dsl.ActOpts{StartToCloseSeconds: st.TimeoutSeconds, HeartbeatSeconds: st.HeartbeatSeconds}
+	}
+	if len(st.Retry) > 0 {
+		if act.Opts == nil {
+			act.Opts = &dsl.ActOpts{}
+		}
+		act.Opts.Retry = c.retry(name, st.Retry)
+	}
+	c.catch(name, st)
+	return &dsl.Statement{ID: name, Activity: act}
+}
+
+// retry 把 Retry 转为 Activity 的重试策略。Temporal 的策略对所有错误生效，
+// 因此优先取 States.ALL 的重试器，否则取第一个，其余重试器记入说明
+func (c *converter) retry(state string, retriers []Retrier) *dsl.RetryPolicy {
+	pick := 0
+	for i, r := range retriers {
+		if slices.Contains(r.ErrorEquals, "States.ALL") {
+			pick = i
+			break
+		}
+	}
+	r := retriers[pick]
+	if len(retriers) > 1 {
+		c.notef(state, "%d retriers found; only the one for %s is converted and applies to all errors",
+			len(retriers), strings.Join(r.ErrorEquals, ", "))
+	} else if !slices.Contains(r.ErrorEquals, "States.ALL") {
+		c.notef(state, "retrier for %s now applies to all errors", strings.Join(r.ErrorEquals, ", "))
+	}
+	// ASL 的 MaxAttempts 是重试次数，Temporal 的是总尝试次数
+	p := &dsl.RetryPolicy{MaxAttempts: 4, InitialIntervalSec: 1, BackoffCoefficient: 2, MaxIntervalSec: r.MaxDelaySeconds}
+	if r.MaxAttempts != nil {
+		p.MaxAttempts = *r.MaxAttempts + 1
+	}
+	if r.IntervalSeconds != nil {
+		p.InitialIntervalSec = *r.IntervalSeconds
+	}
+	if r.BackoffRate != nil {
+		p.BackoffCoefficient = *r.BackoffRate
+	}
+	return p
+}
+
+// catch 记录无法转换的 Catch：DSL 没有错误处理分支，出错时工作流直接失败
+func (c *converter) catch(state string, st *State) {
+	for _, h := range st.Catch {
+		c.notef(state, "Catch for %s -> %s is not converted; the error fails the workflow instead",
+			strings.Join(h.ErrorEquals, ", "), h.Next)
+	}
+}
+
+// wait 把 Wait 状态转为 wait 语句；*Path 字段映射为变量引用
+func (c *converter) wait(name string, st *State, item string) *dsl.Statement {
+	w := &dsl.Wait{Seconds: st.Seconds, Until: st.Timestamp}
+	if st.SecondsPath != "" {
+		w.SecondsRef = c.ref(name, st.SecondsPath, item)
+	}
+	if st.TimestampPath != "" {
+		w.UntilRef = c.ref(name, st.TimestampPath, item)
+	}
+	if *w == (dsl.Wait{}) {
+		c.notef(name, "Wait state has no Seconds/SecondsPath/Timestamp/TimestampPath")
+		return nil
+	}
+	return &dsl.Statement{ID: name, Wait: w}
+}
+
+// activityName 取 Resource ARN 的最后一段；lambda:invoke 集成取 FunctionName
+func activityName(st *State) string {
+	res := st.Resource
+	if strings.HasSuffix(res, ":lambda:invoke") {
+		res, _ = st.Parameters["FunctionName"].(string)
+	}
+	res = strings.TrimSuffix(res, ".sync")
+	// 函数 ARN 可能带版本/别名后缀：...:function:Name:$LATEST
+	if _, fn, ok := strings.Cut(res, ":function:"); ok {
+		name, _, _ := strings.Cut(fn, ":")
+		return name
+	}
+	if i := strings.LastIndexAny(res, ":/"); i >= 0 {
+		res = res[i+1:]
+	}
+	return res
+}
+
+// args 按键名排序把 Parameters 转为位置参数；"key.$" 视为变量引用
+func (c *converter) args(state string, params map[string]any, item string) []dsl.Value {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var out []dsl.Value
+	for _, k := range keys {
+		v := params[k]
+		if strings.HasSuffix(k, ".$") {
+			p, _ := v.(string)
+			out = append(out, dsl.Value{Ref: c.ref(state, p, item)})
+			continue
+		}
+		lit, ok := literal(v)
+		if !ok {
+			c.notef(state, "parameter %q: only scalar values are supported", k)
+			continue
+		}
+		out = append(out, lit)
+	}
+	return out
+}
+
+func literal(v any) (dsl.Value, bool) {
+	switch x := v.(type) {
+	case string:
+		return dsl.Value{Str: &x}, true
+	case bool:
+		return dsl.Value{Bool: &x}, true
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<53 {
+			n := int64(x)
+			return dsl.Value{Int: &n}, true
+		}
+		return dsl.Value{Float: &x}, true
+	}
+	return dsl.Value{}, false
+}
+
+// ref 把 JSONPath 转为变量名：$.x -> x；Map 体内 $ 指当前元素
+func (c *converter) ref(state, path, item string) string {
+	if path == "$" && item != "" {
+		return item
+	}
+	name, ok := strings.CutPrefix(path, "$.")
+	if !ok || name == "" || strings.ContainsAny(name, ".[") {
+		c.notef(state, "path %q cannot be mapped to a variable; use a top-level field like $.name", path)
+		name = strings.NewReplacer("$", "", ".", "_", "[", "_", "]", "").Replace(strings.TrimPrefix(path, "$."))
+		if name == "" {
+			name = "input"
+		}
+	}
+	return name
+}
+
+func (c *converter) resultVar(state, path string) string {
+	if path == "" {
+		return ""
+	}
+	return c.ref(state, path, "")
+}
+
+func (c *converter) parallel(name string, st *State, item string) *dsl.Statement {
+	branches := dsl.Parallel{}
+	for i, b := range st.Branches {
+		s := c.single(b.States, b.StartAt, "", item)
+		if s == nil {
+			c.notef(name, "branch %d has no convertible steps", i)
+			continue
+		}
+		branches = append(branches, s)
+	}
+	return &dsl.Statement{ID: name, Parallel: &branches}
+}
+
+func (c *converter) mapState(name string, st *State, item string) *dsl.Statement {
+	m := &dsl.Map{
+		ItemsRef:    c.ref(name, st.ItemsPath, item),
+		ItemVar:     "item",
+		Concurrency: st.MaxConcurrency,
+		CollectVar:  c.resultVar(name, st.ResultPath),
+	}
+	proc := st.ItemProcessor
+	if proc == nil {
+		proc = st.Iterator
+	}
+	if proc == nil {
+		c.notef(name, "Map has no ItemProcessor/Iterator")
+		return nil
+	}
+	m.Body = c.single(proc.States, proc.StartAt, "", m.ItemVar)
+	if m.Body == nil {
+		c.notef(name, "Map body has no convertible steps")
+		return nil
+	}
+	// 收集变量依赖 Body 写入同名变量
+	if m.CollectVar != "" && m.Body.Activity != nil && m.Body.Activity.Result == "" {
+		m.Body.Activity.Result = m.CollectVar
+	}
+	return &dsl.Statement{ID: name, Map: m}
+}
+
+// pass 把带 Result 的 Pass 状态折叠为初始变量；DSL 没有赋值语句
+func (c *converter) pass(name string, st *State) {
+	if st.Result == nil {
+		return
+	}
+	if st.ResultPath == "" {
+		c.notef(name, "Pass Result without ResultPath is not converted")
+		return
+	}
+	c.vars[c.ref(name, st.ResultPath, "")] = st.Result
+	c.notef(name, "Pass Result folded into initial variables")
+}
+
+// choice 转为 if/else 链：Choices[0] 为 then，其余规则与 Default 依次嵌套在 else 中
+func (c *converter) choice(name string, st *State, states map[string]*State, join, item string) *dsl.Statement {
+	branch := func(target string) *dsl.Statement {
+		if target == "" || target == join {
+			return nil
+		}
+		return c.single(states, target, join, item)
+	}
+	tail := branch(st.Default)
+	for i := len(st.Choices) - 1; i >= 0; i-- {
+		rule := st.Choices[i]
+		cond := c.cond(name, rule)
+		then := branch(rule.Next)
+		switch {
+		case then == nil && tail == nil:
+			continue
+		case then == nil:
+			tail = &dsl.Statement{If: &dsl.If{Cond: dsl.Cond{Not: &cond}, Then: tail}}
+		default:
+			tail = &dsl.Statement{If: &dsl.If{Cond: cond, Then: then, Else: tail}}
+		}
+	}
+	if tail == nil {
+		return nil
+	}
+	tail.ID = name
+	return tail
+}
+
+func (c *converter) cond(state string, r ChoiceRule) dsl.Cond {
+	switch {
+	case r.Not != nil:
+		inner := c.cond(state, *r.Not)
+		return dsl.Cond{Not: &inner}
+	case len(r.And) > 0:
+		all := make([]dsl.Cond, 0, len(r.And))
+		for _, sub := range r.And {
+			all = append(all, c.cond(state, sub))
+		}
+		return dsl.Cond{All: all}
+	case len(r.Or) > 0:
+		anyOf := make([]dsl.Cond, 0, len(r.Or))
+		for _, sub := range r.Or {
+			anyOf = append(anyOf, c.cond(state, sub))
+		}
+		return dsl.Cond{Any: anyOf}
+	}
+	left := dsl.Value{Ref: c.ref(state, r.Variable, "")}
+	var right dsl.Value
+	switch {
+	case r.StringEquals != nil:
+		right, _ = literal(*r.StringEquals)
+	case r.NumericEquals != nil:
+		right, _ = literal(*r.NumericEquals)
+	case r.BooleanEquals != nil:
+		right, _ = literal(*r.BooleanEquals)
+	default:
+		c.notef(state, "comparison %s on %s is not supported; replaced with a truthy check, review the condition",
+			strings.Join(r.unsupported, ", "), r.Variable)
+		return dsl.Cond{Truthy: &left}
+	}
+	return dsl.Cond{Eq: &dsl.Compare{Left: left, Right: right}}
+}
+
+// joinOf 找出 Choice 各分支重新汇合的第一个状态；没有汇合点时返回空串。
+// visiting 防止 Choice 之间互相跳转时无限递归
+func joinOf(states map[string]*State, st *State, visiting map[*State]bool) string {
+	if visiting[st] {
+		return ""
+	}
+	visiting[st] = true
+	defer delete(visiting, st)
+	targets := []string{}
+	for _, r := range st.Choices {
+		targets = append(targets, r.Next)
+	}
+	if st.Default != "" {
+		targets = append(targets, st.Default)
+	}
+	if len(targets) == 0 {
+		return ""
+	}
+	paths := make([][]string, len(targets))
+	for i, t := range targets {
+		paths[i] = successors(states, t, visiting)
+	}
+	for _, cand := range paths[0] {
+		shared := true
+		for _, p := range paths[1:] {
+			if !slices.Contains(p, cand) {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			return cand
+		}
+	}
+	return ""
+}
+
+// successors 列出从 start 出发沿执行顺序经过的状态（嵌套 Choice 跳到其汇合点）
+func successors(states map[string]*State, start string, visiting map[*State]bool) []string {
+	var out []string
+	seen := map[string]bool{}
+	for cur := start; cur != "" && !seen[cur]; {
+		seen[cur] = true
+		out = append(out, cur)
+		st := states[cur]
+		if st == nil || st.End || st.Type == "Succeed" || st.Type == "Fail" {
+			break
+		}
+		if st.Type == "Choice" {
+			cur = joinOf(states, st, visiting)
+			continue
+		}
+		cur = st.Next
+	}
+	return out
+}
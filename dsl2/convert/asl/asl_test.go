@@ -0,0 +1,143 @@
+package asl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+const orderMachine = `{
+  "StartAt": "Init",
+  "States": {
+    "Init": { "Type": "Pass", "Result": ["a", "b"], "ResultPath": "$.items", "Next": "Validate" },
+    "Validate": {
+      "Type": "Task",
+      "Resource": "arn:aws:lambda:us-east-1:123:function:ValidateOrder:$LATEST",
+      "Parameters": { "order.$": "$.orderId", "strict": true },
+      "ResultPath": "$.valid",
+      "TimeoutSeconds": 30,
+      "Next": "IsValid"
+    },
+    "IsValid": {
+      "Type": "Choice",
+      "Choices": [
+        { "Variable": "$.valid", "BooleanEquals": true, "Next": "Fanout" },
+        { "Variable": "$.total", "NumericGreaterThan": 100, "Next": "Review" }
+      ],
+      "Default": "Reject"
+    },
+    "Review": { "Type": "Task", "Resource": "arn:aws:states:::lambda:invoke",
+      "Parameters": { "FunctionName": "ManualReview", "Payload": { "id.$": "$.orderId" } },
+      "Next": "Notify" },
+    "Reject": { "Type": "Task", "Resource": "arn:aws:states:us-east-1:123:activity:Reject", "Next": "Notify" },
+    "Fanout": {
+      "Type": "Parallel",
+      "Branches": [
+        { "StartAt": "Ship", "States": { "Ship": { "Type": "Task", "Resource": "arn:x:function:Ship", "End": true } } },
+        { "StartAt": "Bill", "States": { "Bill": { "Type": "Task", "Resource": "arn:x:function:Bill", "End": true } } }
+      ],
+      "Next": "Notify"
+    },
+    "Notify": {
+      "Type": "Map",
+      "ItemsPath": "$.items",
+      "MaxConcurrency": 2,
+      "ResultPath": "$.sent",
+      "ItemProcessor": {
+        "StartAt": "Send",
+        "States": { "Send": { "Type": "Task", "Resource": "arn:x:function:Send", "Parameters": { "to.$": "$" }, "End": true } }
+      },
+      "Next": "Done"
+    },
+    "Done": { "Type": "Succeed" }
+  }
+}`
+
+func TestConvert(t *testing.T) {
+	wf, notes, err := Convert([]byte(orderMachine), Options{})
+	require.NoError(t, err)
+	require.Equal(t, "demo", wf.TaskQueue)
+	require.Equal(t, []any{"a", "b"}, wf.Variables["items"])
+	require.Len(t, wf.Root, 3)
+
+	validate := wf.Root[0].Activity
+	require.Equal(t, "ValidateOrder", validate.Name)
+	require.Equal(t, "orderId", validate.Args[0].Ref)
+	require.True(t, *validate.Args[1].Bool)
+	require.Equal(t, "valid", validate.Result)
+	require.Equal(t, 30, validate.Opts.StartToCloseSeconds)
+
+	choice := wf.Root[1]
+	require.Equal(t, "IsValid", choice.ID)
+	require.Equal(t, "valid", choice.If.Cond.Eq.Left.Ref)
+	require.Len(t, *choice.If.Then.Parallel, 2)
+	nested := choice.If.Else.If
+	require.Equal(t, "total", nested.Cond.Truthy.Ref)
+	require.Equal(t, "ManualReview", nested.Then.Activity.Name)
+	require.Equal(t, "orderId", nested.Then.Activity.Args[0].Ref)
+	require.Equal(t, "Reject", nested.Else.Activity.Name)
+
+	m := wf.Root[2].Map
+	require.Equal(t, "items", m.ItemsRef)
+	require.Equal(t, 2, m.Concurrency)
+	require.Equal(t, "sent", m.CollectVar)
+	require.Equal(t, "item", m.Body.Activity.Args[0].Ref)
+	require.Equal(t, "sent", m.Body.Activity.Result)
+
+	require.Contains(t, notes, Note{State: "IsValid",
+		Message: "comparison NumericGreaterThan on $.total is not supported; replaced with a truthy check, review the condition"})
+	require.False(t, dsl.HasErrors(wf.Check(dsl.CheckOptions{})))
+}
+
+func TestConvertReportsDroppedSteps(t *testing.T) {
+	_, notes, err := Convert([]byte(`{
+  "StartAt": "P",
+  "States": {
+    "P": { "Type": "Parallel", "End": true, "Branches": [
+      { "StartAt": "A", "States": {
+        "A": { "Type": "Task", "Resource": "arn:x:function:A", "Next": "B" },
+        "B": { "Type": "Task", "Resource": "arn:x:function:B", "Next": "W" },
+        "W": { "Type": "Wait", "Seconds": 5, "End": true } } } ] }
+  }
+}`), Options{})
+	require.NoError(t, err)
+	require.Equal(t, []Note{
+		{State: "A", Message: "branch has 3 steps but a DSL branch holds one statement; dropped B, W"},
+	}, notes)
+}
+
+func TestConvertWaitRetryCatch(t *testing.T) {
+	wf, notes, err := Convert([]byte(`{
+  "StartAt": "Charge",
+  "States": {
+    "Charge": {
+      "Type": "Task",
+      "Resource": "arn:x:function:Charge",
+      "Retry": [
+        { "ErrorEquals": ["Timeout"], "MaxAttempts": 5 },
+        { "ErrorEquals": ["States.ALL"], "IntervalSeconds": 3, "MaxAttempts": 2, "BackoffRate": 1.5, "MaxDelaySeconds": 60 }
+      ],
+      "Catch": [{ "ErrorEquals": ["States.ALL"], "Next": "Refund" }],
+      "Next": "Cool"
+    },
+    "Cool": { "Type": "Wait", "Seconds": 30, "Next": "Later" },
+    "Later": { "Type": "Wait", "TimestampPath": "$.shipAt", "Next": "Ship" },
+    "Ship": { "Type": "Task", "Resource": "arn:x:function:Ship", "Retry": [{ "ErrorEquals": ["Busy"] }], "End": true },
+    "Refund": { "Type": "Task", "Resource": "arn:x:function:Refund", "End": true }
+  }
+}`), Options{})
+	require.NoError(t, err)
+	require.Len(t, wf.Root, 4)
+	require.Equal(t, &dsl.RetryPolicy{MaxAttempts: 3, InitialIntervalSec: 3, BackoffCoefficient: 1.5, MaxIntervalSec: 60},
+		wf.Root[0].Activity.Opts.Retry)
+	require.Equal(t, &dsl.Wait{Seconds: 30}, wf.Root[1].Wait)
+	require.Equal(t, &dsl.Wait{UntilRef: "shipAt"}, wf.Root[2].Wait)
+	require.Equal(t, &dsl.RetryPolicy{MaxAttempts: 4, InitialIntervalSec: 1, BackoffCoefficient: 2},
+		wf.Root[3].Activity.Opts.Retry)
+	require.Equal(t, []Note{
+		{State: "Charge", Message: "2 retriers found; only the one for States.ALL is converted and applies to all errors"},
+		{State: "Charge", Message: "Catch for States.ALL -> Refund is not converted; the error fails the workflow instead"},
+		{State: "Ship", Message: "retrier for Busy now applies to all errors"},
+	}, notes)
+}
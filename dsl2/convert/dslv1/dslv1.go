@@ -0,0 +1,184 @@
+// Package dslv1 把最初的 samples-go dsl 示例（v1：sequence/parallel/activity，变量均为字符串）
+// 的 YAML 转换为 dsl2 工作流，便于老用户直接升级定义文件。
+// 语义上的差异不会中断转换，而是记录在返回的 Note 列表中供人工复核
+package dslv1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow 是 v1 的顶层定义
+type Workflow struct {
+	Variables map[string]string `yaml:"variables"`
+	Root      Statement         `yaml:"root"`
+}
+
+// Statement 是 v1 的节点；三者可同时出现，执行顺序为 parallel、sequence、activity
+type Statement struct {
+	Activity *ActivityInvocation `yaml:"activity"`
+	Sequence *Sequence           `yaml:"sequence"`
+	Parallel *Parallel           `yaml:"parallel"`
+}
+
+type Sequence struct {
+	Elements []*Statement `yaml:"elements"`
+}
+
+type Parallel struct {
+	Branches []*Statement `yaml:"branches"`
+}
+
+// ActivityInvocation 的 Arguments 是变量名，v1 把它们的值作为一个 []string 参数传给 Activity
+type ActivityInvocation struct {
+	Name      string   `yaml:"name"`
+	Arguments []string `yaml:"arguments"`
+	Result    string   `yaml:"result"`
+}
+
+// Note 记录一处语义有差异的转换；Path 为 v1 定义中的位置，如 root.sequence[1].parallel[0]
+type Note struct {
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (n Note) String() string {
+	if n.Path == "" {
+		return n.Message
+	}
+	return n.Path + ": " + n.Message
+}
+
+// Options 控制生成的工作流
+type Options struct {
+	TaskQueue string // 为空时使用 "demo"
+}
+
+// Convert 解析 v1 YAML 并生成 dsl2 工作流；顶层 sequence 展开为 Root 列表
+func Convert(data []byte, opts Options) (dsl.Workflow, []Note, error) {
+	var v1 Workflow
+	if err := yaml.Unmarshal(data, &v1); err != nil {
+		return dsl.Workflow{}, nil, fmt.Errorf("parse v1 workflow: %w", err)
+	}
+	if opts.TaskQueue == "" {
+		opts.TaskQueue = "demo"
+	}
+	c := &converter{args: map[string]bool{}}
+	wf := dsl.Workflow{
+		Version:   dsl.SchemaVersion,
+		TaskQueue: opts.TaskQueue,
+		Root:      c.statement("root", &v1.Root),
+	}
+	if len(wf.Root) == 0 {
+		return dsl.Workflow{}, c.notes, fmt.Errorf("v1 workflow has no activities")
+	}
+	if len(v1.Variables) > 0 {
+		wf.Variables = map[string]any{}
+		for k, v := range v1.Variables {
+			wf.Variables[k] = v
+		}
+	}
+	if len(c.args) > 0 {
+		names := make([]string, 0, len(c.args))
+		for n := range c.args {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		c.notef("", "v1 passed arguments as a single []string; dsl2 passes them as separate args, "+
+			"so update the signatures of %s", strings.Join(names, ", "))
+	}
+	return wf, c.notes, nil
+}
+
+type converter struct {
+	notes []Note
+	args  map[string]bool // 带参数的 Activity，需调整签名
+}
+
+func (c *converter) notef(path, format string, args ...any) {
+	c.notes = append(c.notes, Note{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// statement 把一个 v1 节点转换为按顺序执行的语句列表
+func (c *converter) statement(path string, s *Statement) []*dsl.Statement {
+	if s == nil {
+		return nil
+	}
+	var out []*dsl.Statement
+	if s.Parallel != nil {
+		out = append(out, c.parallel(path+".parallel", s.Parallel)...)
+	}
+	if s.Sequence != nil {
+		for i, e := range s.Sequence.Elements {
+			out = append(out, c.statement(fmt.Sprintf("%s.sequence[%d]", path, i), e)...)
+		}
+	}
+	if s.Activity != nil {
+		out = append(out, c.activity(path+".activity", s.Activity))
+	}
+	if n := countSet(s); n > 1 {
+		c.notef(path, "node sets %d of parallel/sequence/activity; they are converted to run one after another", n)
+	}
+	return out
+}
+
+func countSet(s *Statement) int {
+	n := 0
+	for _, set := range []bool{s.Activity != nil, s.Sequence != nil, s.Parallel != nil} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *converter) activity(path string, a *ActivityInvocation) *dsl.Statement {
+	if a.Name == "" {
+		c.notef(path, "activity has no name")
+	}
+	act := &dsl.ActivityInvocation{Name: a.Name, Result: a.Result}
+	for _, arg := range a.Arguments {
+		act.Args = append(act.Args, dsl.Value{Ref: arg})
+	}
+	if len(a.Arguments) > 0 && a.Name != "" {
+		c.args[a.Name] = true
+	}
+	return &dsl.Statement{Activity: act}
+}
+
+// parallel 转换并行块。dsl2 的分支只能容纳一条语句，因此多步分支按步对齐为若干阶段：
+// 第 i 个阶段并行执行各分支的第 i 步，阶段之间顺序执行。分支内的先后依赖不变，只是并发度降低
+func (c *converter) parallel(path string, p *Parallel) []*dsl.Statement {
+	branches := make([][]*dsl.Statement, 0, len(p.Branches))
+	stages := 0
+	for i, b := range p.Branches {
+		stmts := c.statement(fmt.Sprintf("%s[%d]", path, i), b)
+		if len(stmts) == 0 {
+			continue
+		}
+		branches = append(branches, stmts)
+		stages = max(stages, len(stmts))
+	}
+	if stages > 1 {
+		c.notef(path, "branches with several steps are split into %d stages; each stage waits for every branch before the next starts", stages)
+	}
+	var out []*dsl.Statement
+	for i := 0; i < stages; i++ {
+		stage := dsl.Parallel{}
+		for _, b := range branches {
+			if i < len(b) {
+				stage = append(stage, b[i])
+			}
+		}
+		if len(stage) == 1 {
+			out = append(out, stage[0])
+			continue
+		}
+		out = append(out, &dsl.Statement{Parallel: &stage})
+	}
+	return out
+}
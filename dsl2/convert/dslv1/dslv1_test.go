@@ -0,0 +1,68 @@
+package dslv1
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+func TestConvertSample(t *testing.T) {
+	// 原 dsl 示例自带的定义：sequence 中嵌套由两个 sequence 组成的 parallel
+	data, err := os.ReadFile("../../../dsl/workflow2.yaml")
+	require.NoError(t, err)
+	wf, notes, err := Convert(data, Options{TaskQueue: "v1"})
+	require.NoError(t, err)
+	require.Equal(t, "v1", wf.TaskQueue)
+	require.Equal(t, map[string]any{"arg1": "value1", "arg2": "value2", "arg3": "value3"}, wf.Variables)
+
+	require.Len(t, wf.Root, 4)
+	first := wf.Root[0].Activity
+	require.Equal(t, "SampleActivity1", first.Name)
+	require.Equal(t, []dsl.Value{{Ref: "arg1"}}, first.Args)
+	require.Equal(t, "result1", first.Result)
+
+	// 两个分支各有两步，按步对齐为两个并行阶段
+	stage1, stage2 := *wf.Root[1].Parallel, *wf.Root[2].Parallel
+	require.Equal(t, "SampleActivity2", stage1[0].Activity.Name)
+	require.Equal(t, "SampleActivity4", stage1[1].Activity.Name)
+	require.Equal(t, "SampleActivity3", stage2[0].Activity.Name)
+	require.Equal(t, []dsl.Value{{Ref: "arg2"}, {Ref: "result2"}}, stage2[0].Activity.Args)
+	require.Equal(t, "SampleActivity5", stage2[1].Activity.Name)
+	require.Equal(t, []dsl.Value{{Ref: "result3"}, {Ref: "result5"}}, wf.Root[3].Activity.Args)
+
+	require.Equal(t, []Note{
+		{Path: "root.sequence[1].parallel", Message: "branches with several steps are split into 2 stages; each stage waits for every branch before the next starts"},
+		{Message: "v1 passed arguments as a single []string; dsl2 passes them as separate args, so update the signatures of " +
+			"SampleActivity1, SampleActivity2, SampleActivity3, SampleActivity4, SampleActivity5"},
+	}, notes)
+	require.Empty(t, wf.Check(dsl.CheckOptions{}))
+}
+
+func TestConvertUnevenBranches(t *testing.T) {
+	wf, notes, err := Convert([]byte(`
+root:
+  parallel:
+    branches:
+      - activity: { name: A }
+      - sequence:
+          elements:
+            - activity: { name: B1, result: b }
+            - activity: { name: B2 }
+  activity: { name: After }
+`), Options{})
+	require.NoError(t, err)
+	require.Equal(t, "demo", wf.TaskQueue)
+	require.Len(t, wf.Root, 3)
+	require.Len(t, *wf.Root[0].Parallel, 2)
+	require.Equal(t, "B2", wf.Root[1].Activity.Name)
+	require.Equal(t, "After", wf.Root[2].Activity.Name)
+	require.Equal(t, []Note{
+		{Path: "root.parallel", Message: "branches with several steps are split into 2 stages; each stage waits for every branch before the next starts"},
+		{Path: "root", Message: "node sets 2 of parallel/sequence/activity; they are converted to run one after another"},
+	}, notes)
+
+	_, _, err = Convert([]byte(`variables: { a: b }`), Options{})
+	require.EqualError(t, err, "v1 workflow has no activities")
+}
@@ -0,0 +1,491 @@
+// Package sw 把 CNCF Serverless Workflow（0.8 规范，JSON 或 YAML）定义转换为 DSL 工作流。
+// 函数调用映射为以函数名命名的 Activity；无法表达的结构记录在返回的 Note 列表中
+package sw
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"gopkg.in/yaml.v3"
+)
+
+// Document 只包含转换用到的字段，其余字段被忽略
+type Document struct {
+	ID          string  `yaml:"id"`
+	Name        string  `yaml:"name"`
+	SpecVersion string  `yaml:"specVersion"`
+	Start       any     `yaml:"start"` // 状态名或 {stateName: ...}
+	States      []State `yaml:"states"`
+}
+
+type State struct {
+	Name       string `yaml:"name"`
+	Type       string `yaml:"type"`
+	Transition any    `yaml:"transition"` // 状态名或 {nextState: ...}
+	End        any    `yaml:"end"`        // bool 或对象
+
+	// operation / foreach
+	Actions    []Action `yaml:"actions"`
+	ActionMode string   `yaml:"actionMode"` // sequential（默认）/ parallel
+
+	// switch
+	DataConditions   []DataCondition `yaml:"dataConditions"`
+	EventConditions  []any           `yaml:"eventConditions"`
+	DefaultCondition *DataCondition  `yaml:"defaultCondition"`
+
+	// parallel
+	Branches []Branch `yaml:"branches"`
+
+	// foreach
+	InputCollection  string `yaml:"inputCollection"`
+	OutputCollection string `yaml:"outputCollection"`
+	IterationParam   string `yaml:"iterationParam"`
+	BatchSize        any    `yaml:"batchSize"`
+
+	// inject
+	Data map[string]any `yaml:"data"`
+}
+
+type Action struct {
+	Name             string `yaml:"name"`
+	FunctionRef      any    `yaml:"functionRef"` // 函数名或 {refName, arguments}
+	ActionDataFilter struct {
+		ToStateData string `yaml:"toStateData"`
+	} `yaml:"actionDataFilter"`
+	SubFlowRef any `yaml:"subFlowRef"`
+	EventRef   any `yaml:"eventRef"`
+}
+
+type DataCondition struct {
+	Name       string `yaml:"name"`
+	Condition  string `yaml:"condition"`
+	Transition any    `yaml:"transition"`
+	End        any    `yaml:"end"`
+}
+
+type Branch struct {
+	Name    string   `yaml:"name"`
+	Actions []Action `yaml:"actions"`
+}
+
+// Note 记录一个未能（完整）转换的状态
+type Note struct {
+	State   string `json:"state,omitempty"`
+	Message string `json:"message"`
+}
+
+func (n Note) String() string {
+	if n.State == "" {
+		return n.Message
+	}
+	return n.State + ": " + n.Message
+}
+
+// Options 控制生成的工作流
+type Options struct {
+	TaskQueue string // 为空时使用 "demo"
+}
+
+// Convert 解析 Serverless Workflow 文档并生成 DSL 工作流；状态名写入 Statement.ID 便于对照
+func Convert(data []byte, opts Options) (dsl.Workflow, []Note, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return dsl.Workflow{}, nil, fmt.Errorf("parse serverless workflow: %w", err)
+	}
+	if len(doc.States) == 0 {
+		return dsl.Workflow{}, nil, fmt.Errorf("workflow needs states")
+	}
+	if opts.TaskQueue == "" {
+		opts.TaskQueue = "demo"
+	}
+	c := &converter{states: map[string]*State{}, vars: map[string]any{}}
+	for i := range doc.States {
+		c.states[doc.States[i].Name] = &doc.States[i]
+	}
+	start := target(doc.Start, "stateName")
+	if start == "" {
+		start = doc.States[0].Name
+	}
+	wf := dsl.Workflow{
+		Version:   dsl.SchemaVersion,
+		TaskQueue: opts.TaskQueue,
+		Root:      c.sequence(start, ""),
+	}
+	if len(c.vars) > 0 {
+		wf.Variables = c.vars
+	}
+	return wf, c.notes, nil
+}
+
+type converter struct {
+	states map[string]*State
+	vars   map[string]any // inject 状态折叠出的初始变量
+	notes  []Note
+}
+
+func (c *converter) notef(state, format string, args ...any) {
+	c.notes = append(c.notes, Note{State: state, Message: fmt.Sprintf(format, args...)})
+}
+
+// target 读取 "name" 或 {key: name} 形式的跳转目标
+func target(v any, key string) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case map[string]any:
+		s, _ := x[key].(string)
+		return s
+	}
+	return ""
+}
+
+// ends 报告 end 是否表示结束（true 或对象）
+func ends(v any) bool {
+	switch x := v.(type) {
+	case bool:
+		return x
+	case map[string]any:
+		return true
+	}
+	return false
+}
+
+func (st *State) next() string {
+	if ends(st.End) {
+		return ""
+	}
+	return target(st.Transition, "nextState")
+}
+
+// sequence 从 start 沿 transition 转换到 stop（不含）或结束状态
+func (c *converter) sequence(start, stop string) []*dsl.Statement {
+	var out []*dsl.Statement
+	seen := map[string]bool{}
+	for cur := start; cur != "" && cur != stop; {
+		st := c.states[cur]
+		if st == nil {
+			c.notef(cur, "state does not exist")
+			break
+		}
+		if seen[cur] {
+			c.notef(cur, "loops back to an earlier state; loops are not converted")
+			break
+		}
+		seen[cur] = true
+
+		next := st.next()
+		switch st.Type {
+		case "operation":
+			out = append(out, c.operation(st)...)
+		case "parallel":
+			out = appendStmt(out, c.parallel(st))
+		case "foreach":
+			out = appendStmt(out, c.foreach(st))
+		case "inject":
+			for k, v := range st.Data {
+				c.vars[k] = v
+			}
+			c.notef(cur, "inject data folded into initial variables")
+		case "switch":
+			join := c.joinOf(st, map[*State]bool{})
+			out = appendStmt(out, c.switchState(st, join))
+			next = join
+		default:
+			c.notef(cur, "%s state is not supported", st.Type)
+		}
+		cur = next
+	}
+	return out
+}
+
+func appendStmt(out []*dsl.Statement, s *dsl.Statement) []*dsl.Statement {
+	if s == nil {
+		return out
+	}
+	return append(out, s)
+}
+
+// single 把一段语句收敛为一条；DSL 的分支/循环体只能容纳一条语句
+func (c *converter) single(state string, stmts []*dsl.Statement) *dsl.Statement {
+	if len(stmts) == 0 {
+		return nil
+	}
+	if len(stmts) > 1 {
+		dropped := make([]string, 0, len(stmts)-1)
+		for _, s := range stmts[1:] {
+			dropped = append(dropped, s.ID)
+		}
+		c.notef(state, "branch has %d steps but a DSL branch holds one statement; dropped %s",
+			len(stmts), strings.Join(dropped, ", "))
+	}
+	return stmts[0]
+}
+
+// actions 转换动作列表；item 非空时 ${ .item } 指 foreach 的当前元素
+func (c *converter) actions(state string, actions []Action) []*dsl.Statement {
+	var out []*dsl.Statement
+	for i, a := range actions {
+		if a.SubFlowRef != nil || a.EventRef != nil {
+			c.notef(state, "action %d: only functionRef actions are supported", i)
+			continue
+		}
+		name, args := functionRef(a.FunctionRef)
+		if name == "" {
+			c.notef(state, "action %d has no functionRef", i)
+			continue
+		}
+		act := &dsl.ActivityInvocation{Name: name}
+		keys := make([]string, 0, len(args))
+		for k := range args {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			act.Args = append(act.Args, c.value(state, args[k]))
+		}
+		if f := a.ActionDataFilter.ToStateData; f != "" {
+			act.Result = c.path(state, f)
+		}
+		id := a.Name
+		if id == "" {
+			id = fmt.Sprintf("%s-%d", state, i)
+		}
+		out = append(out, &dsl.Statement{ID: id, Activity: act})
+	}
+	return out
+}
+
+func functionRef(v any) (string, map[string]any) {
+	switch x := v.(type) {
+	case string:
+		return x, nil
+	case map[string]any:
+		name, _ := x["refName"].(string)
+		args, _ := x["arguments"].(map[string]any)
+		return name, args
+	}
+	return "", nil
+}
+
+var jqPathRE = regexp.MustCompile(`^\$\{\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*\}$`)
+
+// path 把 ${ .name } 转为变量名；更复杂的 jq 表达式无法映射
+func (c *converter) path(state, expr string) string {
+	if m := jqPathRE.FindStringSubmatch(strings.TrimSpace(expr)); m != nil {
+		return m[1]
+	}
+	c.notef(state, "expression %q cannot be mapped to a variable; use ${ .name }", expr)
+	return strings.Trim(strings.NewReplacer("${", "", "}", "", ".", "_", " ", "").Replace(expr), "_")
+}
+
+func (c *converter) value(state string, v any) dsl.Value {
+	switch x := v.(type) {
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(x), "${") {
+			return dsl.Value{Ref: c.path(state, x)}
+		}
+		return dsl.Value{Str: &x}
+	case bool:
+		return dsl.Value{Bool: &x}
+	case int:
+		n := int64(x)
+		return dsl.Value{Int: &n}
+	case float64:
+		return dsl.Value{Float: &x}
+	}
+	c.notef(state, "argument %v: only scalar values are supported", v)
+	s := fmt.Sprint(v)
+	return dsl.Value{Str: &s}
+}
+
+func (c *converter) operation(st *State) []*dsl.Statement {
+	stmts := c.actions(st.Name, st.Actions)
+	if st.ActionMode != "parallel" || len(stmts) < 2 {
+		if len(stmts) == 1 {
+			stmts[0].ID = st.Name
+		}
+		return stmts
+	}
+	p := dsl.Parallel(stmts)
+	return []*dsl.Statement{{ID: st.Name, Parallel: &p}}
+}
+
+func (c *converter) parallel(st *State) *dsl.Statement {
+	branches := dsl.Parallel{}
+	for _, b := range st.Branches {
+		s := c.single(st.Name, c.actions(st.Name, b.Actions))
+		if s == nil {
+			c.notef(st.Name, "branch %q has no convertible actions", b.Name)
+			continue
+		}
+		branches = append(branches, s)
+	}
+	return &dsl.Statement{ID: st.Name, Parallel: &branches}
+}
+
+func (c *converter) foreach(st *State) *dsl.Statement {
+	m := &dsl.Map{
+		ItemsRef: c.path(st.Name, st.InputCollection),
+		ItemVar:  st.IterationParam,
+	}
+	if m.ItemVar == "" {
+		m.ItemVar = "item"
+	}
+	if st.OutputCollection != "" {
+		m.CollectVar = c.path(st.Name, st.OutputCollection)
+	}
+	switch n := st.BatchSize.(type) {
+	case int:
+		m.Concurrency = n
+	case string:
+		m.Concurrency, _ = strconv.Atoi(n)
+	}
+	m.Body = c.single(st.Name, c.actions(st.Name, st.Actions))
+	if m.Body == nil {
+		c.notef(st.Name, "foreach has no convertible actions")
+		return nil
+	}
+	// 收集变量依赖 Body 写入同名变量
+	if m.CollectVar != "" && m.Body.Activity != nil && m.Body.Activity.Result == "" {
+		m.Body.Activity.Result = m.CollectVar
+	}
+	return &dsl.Statement{ID: st.Name, Map: m}
+}
+
+// switchState 转为 if/else 链：dataConditions[0] 为 then，其余条件与 defaultCondition 依次嵌套在 else 中
+func (c *converter) switchState(st *State, join string) *dsl.Statement {
+	if len(st.EventConditions) > 0 {
+		c.notef(st.Name, "event conditions are not supported")
+	}
+	branch := func(dc *DataCondition) *dsl.Statement {
+		if dc == nil || ends(dc.End) {
+			return nil
+		}
+		t := target(dc.Transition, "nextState")
+		if t == "" || t == join {
+			return nil
+		}
+		return c.single(t, c.sequence(t, join))
+	}
+	tail := branch(st.DefaultCondition)
+	for i := len(st.DataConditions) - 1; i >= 0; i-- {
+		dc := &st.DataConditions[i]
+		cond := c.cond(st.Name, dc.Condition)
+		then := branch(dc)
+		switch {
+		case then == nil && tail == nil:
+			continue
+		case then == nil:
+			tail = &dsl.Statement{If: &dsl.If{Cond: dsl.Cond{Not: &cond}, Then: tail}}
+		default:
+			tail = &dsl.Statement{If: &dsl.If{Cond: cond, Then: then, Else: tail}}
+		}
+	}
+	if tail == nil {
+		return nil
+	}
+	tail.ID = st.Name
+	return tail
+}
+
+var condRE = regexp.MustCompile(`^\$\{\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*(?:(==|!=)\s*(.+?))?\s*\}$`)
+
+// cond 支持 ${ .x }、${ .x == v }、${ .x != v }；其他 jq 表达式退化为对整个表达式的 truthy 检查
+func (c *converter) cond(state, expr string) dsl.Cond {
+	m := condRE.FindStringSubmatch(strings.TrimSpace(expr))
+	if m == nil {
+		c.notef(state, "condition %q is not supported; replaced with a truthy check, review the condition", expr)
+		return dsl.Cond{Truthy: &dsl.Value{Ref: c.path(state, expr)}}
+	}
+	left := dsl.Value{Ref: m[1]}
+	if m[2] == "" {
+		return dsl.Cond{Truthy: &left}
+	}
+	cmp := &dsl.Compare{Left: left, Right: operand(m[3])}
+	if m[2] == "!=" {
+		return dsl.Cond{Ne: cmp}
+	}
+	return dsl.Cond{Eq: cmp}
+}
+
+func operand(s string) dsl.Value {
+	if u, err := strconv.Unquote(s); err == nil {
+		return dsl.Value{Str: &u}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return dsl.Value{Int: &n}
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return dsl.Value{Float: &f}
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return dsl.Value{Bool: &b}
+	}
+	return dsl.Value{Str: &s}
+}
+
+// joinOf 找出 switch 各分支重新汇合的第一个状态；没有汇合点时返回空串
+func (c *converter) joinOf(st *State, visiting map[*State]bool) string {
+	if visiting[st] {
+		return ""
+	}
+	visiting[st] = true
+	defer delete(visiting, st)
+	var targets []string
+	conds := st.DataConditions
+	if st.DefaultCondition != nil {
+		conds = append(slices.Clone(conds), *st.DefaultCondition)
+	}
+	for _, dc := range conds {
+		if ends(dc.End) {
+			targets = append(targets, "")
+			continue
+		}
+		targets = append(targets, target(dc.Transition, "nextState"))
+	}
+	if len(targets) == 0 {
+		return ""
+	}
+	paths := make([][]string, len(targets))
+	for i, t := range targets {
+		paths[i] = c.successors(t, visiting)
+	}
+	for _, cand := range paths[0] {
+		shared := true
+		for _, p := range paths[1:] {
+			if !slices.Contains(p, cand) {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			return cand
+		}
+	}
+	return ""
+}
+
+// successors 列出从 start 出发沿执行顺序经过的状态（嵌套 switch 跳到其汇合点）
+func (c *converter) successors(start string, visiting map[*State]bool) []string {
+	var out []string
+	seen := map[string]bool{}
+	for cur := start; cur != "" && !seen[cur]; {
+		seen[cur] = true
+		out = append(out, cur)
+		st := c.states[cur]
+		if st == nil {
+			break
+		}
+		if st.Type == "switch" {
+			cur = c.joinOf(st, visiting)
+			continue
+		}
+		cur = st.next()
+	}
+	return out
+}
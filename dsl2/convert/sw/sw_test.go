@@ -0,0 +1,73 @@
+package sw
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+const orderFlow = `{
+  "id": "order",
+  "specVersion": "0.8",
+  "start": "Init",
+  "functions": [{ "name": "ValidateOrder", "operation": "api.json#validate" }],
+  "states": [
+    { "name": "Init", "type": "inject", "data": { "items": ["a", "b"] }, "transition": "Validate" },
+    { "name": "Validate", "type": "operation",
+      "actions": [{ "functionRef": { "refName": "ValidateOrder", "arguments": { "order": "${ .orderId }", "strict": true } },
+                    "actionDataFilter": { "toStateData": "${ .valid }" } }],
+      "transition": "CheckValid" },
+    { "name": "CheckValid", "type": "switch",
+      "dataConditions": [
+        { "condition": "${ .valid == true }", "transition": "Fanout" },
+        { "condition": "${ .total > 100 }", "transition": "Review" }
+      ],
+      "defaultCondition": { "transition": "Reject" } },
+    { "name": "Review", "type": "operation", "actions": [{ "functionRef": "ManualReview" }], "transition": "Notify" },
+    { "name": "Reject", "type": "operation", "actions": [{ "functionRef": "Reject" }], "transition": "Notify" },
+    { "name": "Fanout", "type": "parallel", "branches": [
+        { "name": "ship", "actions": [{ "functionRef": "Ship" }] },
+        { "name": "bill", "actions": [{ "functionRef": "Bill" }] } ],
+      "transition": "Notify" },
+    { "name": "Notify", "type": "foreach", "inputCollection": "${ .items }", "iterationParam": "to",
+      "outputCollection": "${ .sent }", "batchSize": 2,
+      "actions": [{ "functionRef": { "refName": "Send", "arguments": { "to": "${ .to }" } } }],
+      "transition": "Wait" },
+    { "name": "Wait", "type": "sleep", "duration": "PT5S", "end": true }
+  ]
+}`
+
+func TestConvert(t *testing.T) {
+	wf, notes, err := Convert([]byte(orderFlow), Options{})
+	require.NoError(t, err)
+	require.Equal(t, []any{"a", "b"}, wf.Variables["items"])
+	require.Len(t, wf.Root, 3)
+
+	validate := wf.Root[0]
+	require.Equal(t, "Validate", validate.ID)
+	require.Equal(t, "orderId", validate.Activity.Args[0].Ref)
+	require.True(t, *validate.Activity.Args[1].Bool)
+	require.Equal(t, "valid", validate.Activity.Result)
+
+	choice := wf.Root[1]
+	require.Equal(t, "CheckValid", choice.ID)
+	require.True(t, *choice.If.Cond.Eq.Right.Bool)
+	require.Len(t, *choice.If.Then.Parallel, 2)
+	require.Equal(t, "ManualReview", choice.If.Else.If.Then.Activity.Name)
+	require.Equal(t, "Reject", choice.If.Else.If.Else.Activity.Name)
+
+	m := wf.Root[2].Map
+	require.Equal(t, "items", m.ItemsRef)
+	require.Equal(t, "to", m.ItemVar)
+	require.Equal(t, 2, m.Concurrency)
+	require.Equal(t, "sent", m.Body.Activity.Result)
+
+	require.Equal(t, []Note{
+		{State: "Init", Message: "inject data folded into initial variables"},
+		{State: "CheckValid", Message: `condition "${ .total > 100 }" is not supported; replaced with a truthy check, review the condition`},
+		{State: "CheckValid", Message: `expression "${ .total > 100 }" cannot be mapped to a variable; use ${ .name }`},
+		{State: "Wait", Message: "sleep state is not supported"},
+	}, notes)
+	require.False(t, dsl.HasErrors(wf.Check(dsl.CheckOptions{})))
+}
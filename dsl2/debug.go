@@ -0,0 +1,173 @@
+package dsl
+
+import (
+	"fmt"
+	"sort"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// 调试器：执行到断点（Statement.ID 或节点路径）前停下，由 SignalStep/SignalContinue 推进，
+// 通过 QueryDebug 查看停住的节点与其可见的变量；断点可在运行中经 UpdateBreakpoints 增删
+const (
+	QueryDebug        = "debug"       // 调试状态（DebugState）
+	UpdateBreakpoints = "breakpoints" // 增删断点（BreakpointsRequest -> []string）
+	SignalStep        = "step"        // 放行最早停住的节点，并在下一个节点前再次停下
+	SignalContinue    = "continue"    // 放行全部停住的节点，运行到下一个断点
+)
+
+// BreakpointsRequest 是 UpdateBreakpoints 的参数；先 Remove 再 Add，Clear 为 true 时先清空
+type BreakpointsRequest struct {
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+	Clear  bool     `json:"clear,omitempty"`
+}
+
+// DebugState 是 QueryDebug 的结果；Halted 按停下的先后排列（并发分支可能同时停在多个节点）
+type DebugState struct {
+	Breakpoints []string     `json:"breakpoints"`
+	Stepping    bool         `json:"stepping,omitempty"`
+	Halted      []HaltedNode `json:"halted"`
+}
+
+// HaltedNode 是一个停在开始前的节点；Bindings 为该节点可见的变量（Map 迭代内为迭代的局部变量）
+type HaltedNode struct {
+	Path     string         `json:"path"`
+	ID       string         `json:"id,omitempty"`
+	Kind     string         `json:"kind"`
+	Reason   string         `json:"reason"` // breakpoint / step
+	Bindings map[string]any `json:"bindings"`
+}
+
+type haltedNode struct {
+	HaltedNode
+	released bool
+}
+
+type debugger struct {
+	breakpoints map[string]bool
+	stepping    bool
+	halted      []*haltedNode
+}
+
+type debuggerKey struct{}
+
+// withDebugger 在 ctx 中挂载调试器，注册 QueryDebug、UpdateBreakpoints 并监听 SignalStep/SignalContinue
+func withDebugger(ctx workflow.Context, breakpoints []string) (workflow.Context, error) {
+	d := &debugger{breakpoints: map[string]bool{}}
+	for _, b := range breakpoints {
+		d.breakpoints[b] = true
+	}
+	if err := workflow.SetQueryHandler(ctx, QueryDebug, func() (DebugState, error) {
+		return d.state(), nil
+	}); err != nil {
+		return ctx, err
+	}
+	if err := workflow.SetUpdateHandlerWithOptions(ctx, UpdateBreakpoints,
+		func(ctx workflow.Context, req BreakpointsRequest) ([]string, error) {
+			if req.Clear {
+				d.breakpoints = map[string]bool{}
+			}
+			for _, b := range req.Remove {
+				delete(d.breakpoints, b)
+			}
+			for _, b := range req.Add {
+				d.breakpoints[b] = true
+			}
+			return d.list(), nil
+		},
+		workflow.UpdateHandlerOptions{Validator: func(ctx workflow.Context, req BreakpointsRequest) error {
+			for _, b := range req.Add {
+				if b == "" {
+					return fmt.Errorf("breakpoint must be a statement id or node path")
+				}
+			}
+			return nil
+		}}); err != nil {
+		return ctx, err
+	}
+
+	stepCh := workflow.GetSignalChannel(ctx, SignalStep)
+	continueCh := workflow.GetSignalChannel(ctx, SignalContinue)
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(stepCh, func(c workflow.ReceiveChannel, _ bool) {
+			c.Receive(ctx, nil)
+			d.stepping = true
+			if len(d.halted) > 0 {
+				d.release(d.halted[0])
+			}
+		})
+		selector.AddReceive(continueCh, func(c workflow.ReceiveChannel, _ bool) {
+			c.Receive(ctx, nil)
+			d.stepping = false
+			for len(d.halted) > 0 {
+				d.release(d.halted[0])
+			}
+		})
+		for {
+			selector.Select(ctx)
+		}
+	})
+	return workflow.WithValue(ctx, debuggerKey{}, d), nil
+}
+
+func debuggerFrom(ctx workflow.Context) *debugger {
+	d, _ := ctx.Value(debuggerKey{}).(*debugger)
+	return d
+}
+
+func (d *debugger) release(h *haltedNode) {
+	h.released = true
+	for i, x := range d.halted {
+		if x == h {
+			d.halted = append(d.halted[:i], d.halted[i+1:]...)
+			return
+		}
+	}
+}
+
+func (d *debugger) list() []string {
+	out := make([]string, 0, len(d.breakpoints))
+	for b := range d.breakpoints {
+		out = append(out, b)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (d *debugger) state() DebugState {
+	st := DebugState{Breakpoints: d.list(), Stepping: d.stepping, Halted: []HaltedNode{}}
+	for _, h := range d.halted {
+		n := h.HaltedNode
+		n.Bindings = cloneMap(h.Bindings)
+		st.Halted = append(st.Halted, n)
+	}
+	return st
+}
+
+// haltIfNeeded 在节点开始前调用：命中断点或处于单步状态时停下，直到被 step/continue 放行
+func haltIfNeeded(ctx workflow.Context, s *Statement, bindings map[string]any) error {
+	d := debuggerFrom(ctx)
+	if d == nil {
+		return nil
+	}
+	path := pathFrom(ctx)
+	reason := ""
+	switch {
+	case d.breakpoints[path] || s.ID != "" && d.breakpoints[s.ID]:
+		reason = "breakpoint"
+	case d.stepping:
+		reason = "step"
+	default:
+		return nil
+	}
+	h := &haltedNode{HaltedNode: HaltedNode{Path: path, ID: s.ID, Kind: s.Kind(), Reason: reason, Bindings: bindings}}
+	d.halted = append(d.halted, h)
+	workflow.GetLogger(ctx).Info("DSL workflow halted", "path", path, "reason", reason)
+	if err := workflow.Await(ctx, func() bool { return h.released }); err != nil {
+		d.release(h)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+package dsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 图表导出格式
+const (
+	DiagramMermaid = "mermaid"
+	DiagramDOT     = "dot"
+)
+
+// RenderDiagram 把工作流渲染为 Mermaid flowchart 或 Graphviz DOT，用于文档
+func RenderDiagram(wf Workflow, format string) (string, error) {
+	g, err := BuildGraph(wf)
+	if err != nil {
+		return "", err
+	}
+	// 路径 ID 含有 [ ] . 等字符，统一映射为 n0、n1...
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = "n" + strconv.Itoa(i)
+	}
+	edges := diagramEdges(g)
+
+	var b strings.Builder
+	switch format {
+	case DiagramMermaid, "":
+		b.WriteString("flowchart TD\n")
+		for _, n := range g.Nodes {
+			open, closing := mermaidShape(n.Type)
+			fmt.Fprintf(&b, "    %s%s\"%s\"%s\n", ids[n.ID], open, mermaidEscape(diagramLabel(n)), closing)
+		}
+		for _, e := range edges {
+			arrow := "-->"
+			if e.Kind == "repeat" {
+				arrow = "-.->"
+			}
+			if l := edgeLabel(e); l != "" {
+				fmt.Fprintf(&b, "    %s %s|\"%s\"| %s\n", ids[e.From], arrow, mermaidEscape(l), ids[e.To])
+			} else {
+				fmt.Fprintf(&b, "    %s %s %s\n", ids[e.From], arrow, ids[e.To])
+			}
+		}
+	case DiagramDOT:
+		b.WriteString("digraph workflow {\n    rankdir=TB;\n    node [fontname=\"Helvetica\"];\n")
+		for _, n := range g.Nodes {
+			fmt.Fprintf(&b, "    %s [label=\"%s\", shape=%s];\n", ids[n.ID], dotEscape(diagramLabel(n)), dotShape(n.Type))
+		}
+		for _, e := range edges {
+			attrs := []string{}
+			if l := edgeLabel(e); l != "" {
+				attrs = append(attrs, fmt.Sprintf("label=\"%s\"", dotEscape(l)))
+			}
+			if e.Kind == "repeat" {
+				attrs = append(attrs, "style=dashed")
+			}
+			if len(attrs) > 0 {
+				fmt.Fprintf(&b, "    %s -> %s [%s];\n", ids[e.From], ids[e.To], strings.Join(attrs, ", "))
+			} else {
+				fmt.Fprintf(&b, "    %s -> %s;\n", ids[e.From], ids[e.To])
+			}
+		}
+		b.WriteString("}\n")
+	default:
+		return "", fmt.Errorf("unknown diagram format %q (mermaid/dot)", format)
+	}
+	return b.String(), nil
+}
+
+// diagramEdges 把 while 的 body 边标为 loop，并补充回边（body -> while）
+func diagramEdges(g *Graph) []*GraphEdge {
+	types := map[string]string{}
+	for _, n := range g.Nodes {
+		types[n.ID] = n.Type
+	}
+	out := make([]*GraphEdge, 0, len(g.Edges))
+	for _, e := range g.Edges {
+		if e.Kind == "body" && types[e.From] == KindWhile {
+			out = append(out,
+				&GraphEdge{From: e.From, To: e.To, Kind: "loop"},
+				&GraphEdge{From: e.To, To: e.From, Kind: "repeat"})
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func diagramLabel(n *GraphNode) string {
+	st := n.Spec
+	prefix := ""
+	if st != nil && st.ID != "" {
+		prefix = st.ID + ": "
+	}
+	switch n.Type {
+	case KindActivity:
+		l := prefix + st.Activity.Name
+		if len(st.Activity.Args) > 0 {
+			args := make([]string, len(st.Activity.Args))
+			for i, a := range st.Activity.Args {
+				args[i] = valueString(a)
+			}
+			l += "(" + strings.Join(args, ", ") + ")"
+		}
+		if st.Activity.Result != "" {
+			l += " → " + st.Activity.Result
+		}
+		return l
+	case KindParallel:
+		return prefix + "parallel"
+	case KindMap:
+		l := fmt.Sprintf("%smap over %s", prefix, st.Map.ItemsRef)
+		if st.Map.Concurrency > 0 {
+			l += fmt.Sprintf(" ×%d", st.Map.Concurrency)
+		}
+		if st.Map.RatePerMinute > 0 {
+			l += fmt.Sprintf(" ≤%d/min", st.Map.RatePerMinute)
+		}
+		if st.Map.CollectVar != "" {
+			l += " → " + st.Map.CollectVar
+		}
+		return l
+	case KindWhile:
+		l := prefix + "while " + CondString(st.While.Cond)
+		if st.While.MaxIters > 0 {
+			l += fmt.Sprintf(" (max %d)", st.While.MaxIters)
+		}
+		return l
+	case KindIf:
+		return prefix + "if " + CondString(st.If.Cond)
+	case KindSession:
+		return prefix + "session"
+	case KindMarker:
+		l := prefix + "marker " + st.Marker.Name
+		if len(st.Marker.Refs) > 0 {
+			l += " [" + strings.Join(st.Marker.Refs, ", ") + "]"
+		}
+		return l
+	case KindLog:
+		level := st.Log.Level
+		if level == "" {
+			level = LogInfo
+		}
+		return prefix + "log " + level + ": " + st.Log.Message
+	case KindNoop:
+		l := prefix + "noop"
+		if st.Noop.Description != "" {
+			l += ": " + st.Noop.Description
+		}
+		if st.Noop.Disabled != nil {
+			l += " (disabled " + st.Noop.Disabled.Kind() + ")"
+		}
+		return l
+	case KindAppend:
+		return prefix + "append " + valueString(st.Append.Value) + " → " + st.Append.To
+	case KindMerge:
+		return prefix + "merge " + valueString(st.Merge.Value) + " → " + st.Merge.Into
+	case KindUnset:
+		return prefix + "unset " + strings.Join(st.Unset.Vars, ", ")
+	case KindIncrement:
+		by := "1"
+		if st.Increment.By != nil {
+			by = valueString(*st.Increment.By)
+		}
+		l := prefix + st.Increment.Var + " += " + by
+		if st.Increment.Min != nil || st.Increment.Max != nil {
+			bound := func(f *float64) string {
+				if f == nil {
+					return ""
+				}
+				return strconv.FormatFloat(*f, 'g', -1, 64)
+			}
+			l += " [" + bound(st.Increment.Min) + ".." + bound(st.Increment.Max) + "]"
+		}
+		return l
+	case KindRandom:
+		r := st.Random
+		num := func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+		switch {
+		case r.Int != nil:
+			return prefix + r.Result + " = random int [" + num(r.Int.Min) + ".." + num(r.Int.Max) + "]"
+		case r.Float != nil:
+			return prefix + r.Result + " = random float [" + num(r.Float.Min) + ".." + num(r.Float.Max) + ")"
+		case r.From != "":
+			return prefix + r.Result + " = random of " + r.From
+		}
+		var opts []string
+		for _, v := range r.Choice {
+			opts = append(opts, valueString(v))
+		}
+		return prefix + r.Result + " = random of {" + strings.Join(opts, ", ") + "}"
+	case KindNow:
+		l := prefix + st.Now.Result + " = now"
+		if st.Now.Truncate != "" {
+			l += " by " + st.Now.Truncate
+		}
+		if st.Now.Format != "" {
+			l += " as " + st.Now.Format
+		}
+		return l
+	case KindNewID:
+		l := prefix + st.NewID.Result + " = newId"
+		if st.NewID.Prefix != "" {
+			l += " " + strconv.Quote(st.NewID.Prefix)
+		}
+		return l
+	case KindAggregate:
+		from := st.Aggregate.From
+		if st.Aggregate.Field != "" {
+			from += "[]." + st.Aggregate.Field
+		}
+		return prefix + st.Aggregate.Result + " = " + st.Aggregate.Op + "(" + from + ")"
+	case KindSet:
+		return prefix + st.Set.Var + " = " + valueString(st.Set.Value)
+	case KindWait:
+		switch w := st.Wait; {
+		case w.SecondsRef != "":
+			return prefix + "wait " + w.SecondsRef + "s"
+		case w.Until != "":
+			return prefix + "wait until " + w.Until
+		case w.UntilRef != "":
+			return prefix + "wait until " + w.UntilRef
+		default:
+			return prefix + "wait " + (time.Duration(w.Seconds) * time.Second).String()
+		}
+	}
+	return n.Label
+}
+
+func edgeLabel(e *GraphEdge) string {
+	switch e.Kind {
+	case "then", "else", "loop", "repeat":
+		return e.Kind
+	case "body":
+		return "each"
+	case "step":
+		return fmt.Sprintf("step %d", e.Index+1)
+	}
+	return ""
+}
+
+func mermaidShape(kind string) (string, string) {
+	switch kind {
+	case "start", "end":
+		return "([", "])"
+	case KindIf:
+		return "{", "}"
+	case KindWhile:
+		return "{{", "}}"
+	case KindMap:
+		return "[/", "/]"
+	case KindParallel:
+		return "[[", "]]"
+	case KindSession:
+		return "[(", ")]"
+	case KindMarker:
+		return ">", "]"
+	case KindLog:
+		return "[\\", "\\]"
+	case KindNoop:
+		return "[/", "\\]"
+	}
+	return "[", "]"
+}
+
+func dotShape(kind string) string {
+	switch kind {
+	case "start", "end":
+		return "ellipse"
+	case KindIf:
+		return "diamond"
+	case KindWhile:
+		return "hexagon"
+	case KindMap:
+		return "parallelogram"
+	case KindParallel:
+		return "box3d"
+	case KindSession:
+		return "folder"
+	case KindMarker:
+		return "tab"
+	case KindLog:
+		return "note"
+	case KindNoop:
+		return "plain"
+	}
+	return "box"
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// CondString 把条件渲染为可读表达式，如 `x == 5 && !approved`
+func CondString(c Cond) string {
+	switch {
+	case c.Not != nil:
+		return "!(" + CondString(*c.Not) + ")"
+	case len(c.All) > 0:
+		return joinConds(c.All, " && ")
+	case len(c.Any) > 0:
+		return joinConds(c.Any, " || ")
+	case c.Truthy != nil:
+		return valueString(*c.Truthy)
+	case c.Eq != nil:
+		return valueString(c.Eq.Left) + " == " + valueString(c.Eq.Right)
+	case c.Ne != nil:
+		return valueString(c.Ne.Left) + " != " + valueString(c.Ne.Right)
+	}
+	return "?"
+}
+
+func joinConds(cs []Cond, sep string) string {
+	parts := make([]string, len(cs))
+	for i, c := range cs {
+		parts[i] = CondString(c)
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
+
+func valueString(v Value) string {
+	switch {
+	case v.Ref != "":
+		return v.Ref
+	case v.Str != nil:
+		return strconv.Quote(*v.Str)
+	case v.Int != nil:
+		return strconv.FormatInt(*v.Int, 10)
+	case v.Float != nil:
+		return strconv.FormatFloat(*v.Float, 'g', -1, 64)
+	case v.Bool != nil:
+		return strconv.FormatBool(*v.Bool)
+	case v.Fn != nil:
+		args := make([]string, len(v.Fn.Args))
+		for i, a := range v.Fn.Args {
+			args[i] = valueString(a)
+		}
+		return v.Fn.Name + "(" + strings.Join(args, ", ") + ")"
+	}
+	return "∅"
+}
@@ -0,0 +1,47 @@
+package dsl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EntryNames 返回定义中声明的入口名（按字母序）
+func (wf Workflow) EntryNames() []string {
+	names := make([]string, 0, len(wf.Entrypoints))
+	for name := range wf.Entrypoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SelectEntry 返回以入口 name 为 root 的定义，变量、重试、onCancel 等设置保持共享；返回值不再带 entrypoints。
+// name 为空时使用 root，文件只声明了 entrypoints 时报错
+func (wf Workflow) SelectEntry(name string) (Workflow, error) {
+	if name == "" {
+		return wf, wf.requireRoot()
+	}
+	root, ok := wf.Entrypoints[name]
+	if !ok {
+		if len(wf.Entrypoints) == 0 {
+			return Workflow{}, fmt.Errorf("entry point %q not found: the definition declares no entrypoints", name)
+		}
+		return Workflow{}, fmt.Errorf("entry point %q not found (have %s)", name, strings.Join(wf.EntryNames(), ", "))
+	}
+	wf.Root, wf.Entrypoints, wf.Entry = root, nil, name
+	return wf, nil
+}
+
+// requireRoot 拒绝只有 entrypoints 而未选择入口的定义
+func (wf Workflow) requireRoot() error {
+	if len(wf.Root) == 0 && len(wf.Entrypoints) > 0 {
+		return fmt.Errorf("no entry point selected (have %s)", strings.Join(wf.EntryNames(), ", "))
+	}
+	return nil
+}
+
+// entryPath 是入口中第 i 条语句在校验结果中的路径；执行时所选入口的语句路径为 root[i]
+func entryPath(name string, i int) string {
+	return fmt.Sprintf("entrypoints.%s[%d]", name, i)
+}
@@ -0,0 +1,71 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const entryTestYAML = `
+variables: { order: 7, status: new }
+entrypoints:
+  create:
+    - set: { var: status, value: { str: created } }
+  refund:
+    - activity: { name: DoA, args: [{ ref: order }], result: refundId }
+    - set: { var: status, value: { str: refunded } }
+`
+
+func TestSelectEntry(t *testing.T) {
+	wf, err := ParseYAML([]byte(entryTestYAML))
+	require.NoError(t, err)
+	require.Equal(t, []string{"create", "refund"}, wf.EntryNames())
+	require.False(t, HasErrors(wf.Check(CheckOptions{})))
+
+	_, err = wf.SelectEntry("")
+	require.EqualError(t, err, "no entry point selected (have create, refund)")
+	_, err = wf.SelectEntry("cancel")
+	require.EqualError(t, err, `entry point "cancel" not found (have create, refund)`)
+
+	refund, err := wf.SelectEntry("refund")
+	require.NoError(t, err)
+	require.Len(t, refund.Root, 2)
+	require.Nil(t, refund.Entrypoints)
+	require.Equal(t, "refund", refund.Entry)
+
+	env := startWorkflow(t, refund)
+	require.NoError(t, env.GetWorkflowError())
+	var out map[string]any
+	require.NoError(t, env.GetWorkflowResult(&out))
+	require.Equal(t, "refunded", out["status"])
+	require.Contains(t, out, "refundId")
+
+	// 未选择入口的定义由引擎拒绝
+	require.ErrorContains(t, startWorkflow(t, wf).GetWorkflowError(), "no entry point selected")
+}
+
+func TestCheckEntrypoints(t *testing.T) {
+	wf, err := ParseYAML([]byte(`
+root:
+  - noop: {}
+entrypoints:
+  empty: []
+  broken:
+    - activity: { args: [{ ref: missing }] }
+`))
+	require.NoError(t, err)
+	var got []string
+	for _, i := range wf.Check(CheckOptions{}) {
+		got = append(got, i.Severity+" "+i.String())
+	}
+	require.ElementsMatch(t, []string{
+		"error entrypoints.empty: entry point has no statements",
+		"error entrypoints.broken[0]: activity name required",
+		`warning entrypoints.broken[0]: variable "missing" is never defined`,
+	}, got)
+
+	// 同时有 root 时不选择入口即执行 root
+	sel, err := wf.SelectEntry("")
+	require.NoError(t, err)
+	require.Len(t, sel.Root, 1)
+}
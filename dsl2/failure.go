@@ -0,0 +1,138 @@
+package dsl
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// NodeFailedType 是语句失败导致工作流失败时 ApplicationError 的类型，details[0] 为 NodeFailure
+const NodeFailedType = "NodeFailed"
+
+// NodeFailure 标识导致工作流失败的节点，客户端无需解析错误文本
+type NodeFailure struct {
+	NodeID   string `json:"nodeId,omitempty"` // Statement.ID
+	Path     string `json:"path"`             // 与轨迹、BuildGraph 的节点 ID 一致
+	Kind     string `json:"kind"`
+	Activity string `json:"activity,omitempty"`
+	// Attempt 是 Activity 最后一次的尝试次数；引擎只能从重试策略推断（用尽 maxAttempts 或不重试），否则为 0
+	Attempt    int32    `json:"attempt,omitempty"`
+	RetryState string   `json:"retryState,omitempty"` // 如 MaximumAttemptsReached、NonRetryableFailure
+	Bindings   []string `json:"bindings"`             // 失败时节点可见的变量名（不含值）
+	Message    string   `json:"message"`              // 节点自身的错误
+	// Stack 是从根语句到失败节点的每一层，如 ["while[poll]", "if", "activity[Fetch]"]，见 frameOf
+	Stack []string `json:"stack"`
+}
+
+// nodeError 记录最内层失败的语句；每层外层语句在 Stack 前加上自己，由 SimpleDSLWorkflow 转换为 NodeFailed
+type nodeError struct {
+	NodeFailure
+	err error
+}
+
+// Error 形如 "while[poll] > if > activity[Fetch]: activity Fetch failed: ..."
+func (e *nodeError) Error() string { return e.trace() + ": " + e.err.Error() }
+func (e *nodeError) Unwrap() error { return e.err }
+
+func (e *nodeError) trace() string { return strings.Join(e.Stack, " > ") }
+
+// wrapNodeError 为语句的错误附加节点信息，子语句的错误只加上本层；取消与 ContinueAsNew 不处理
+func wrapNodeError(ctx workflow.Context, s *Statement, bindings map[string]any, err error) error {
+	var ne *nodeError
+	var can *workflow.ContinueAsNewError
+	if err == nil || errors.As(err, &can) || temporal.IsCanceledError(err) {
+		return err
+	}
+	frame := frameOf(ctx, s)
+	if errors.As(err, &ne) {
+		outer := *ne
+		outer.Stack = append([]string{frame}, ne.Stack...)
+		return &outer
+	}
+	f := NodeFailure{NodeID: s.ID, Path: pathFrom(ctx), Kind: s.Kind(), Bindings: sortedKeys(bindings), Message: err.Error(), Stack: []string{frame}}
+	if s.Activity != nil {
+		f.Activity = s.Activity.Name
+		var actErr *temporal.ActivityError
+		if errors.As(err, &actErr) {
+			f.RetryState = retryStateName(actErr.RetryState())
+			f.Attempt = lastAttempt(ctx, s.Activity, actErr.RetryState())
+		}
+	}
+	return &nodeError{NodeFailure: f, err: err}
+}
+
+// frameOf 返回语句在错误栈中的一层：kind[ID]；没有 ID 时 Activity 用名字，parallel 分支、session 步骤等用序号
+func frameOf(ctx workflow.Context, s *Statement) string {
+	label := s.ID
+	if label == "" && s.Activity != nil {
+		label = s.Activity.Name
+	}
+	if path := pathFrom(ctx); label == "" && strings.HasSuffix(path, "]") {
+		if i := strings.LastIndexAny(path, "[."); i >= 0 && path[i] == '[' {
+			label = path[i+1 : len(path)-1]
+		}
+	}
+	if label == "" {
+		return s.Kind()
+	}
+	return s.Kind() + "[" + label + "]"
+}
+
+// lastAttempt 由重试策略推断最后一次尝试的序号
+func lastAttempt(ctx workflow.Context, a *ActivityInvocation, state enumspb.RetryState) int32 {
+	ao := workflow.GetActivityOptions(ctx)
+	if a.Opts != nil {
+		ao = mergeActOpts(ctx, a.Opts)
+	}
+	maxAttempts := int32(0)
+	if ao.RetryPolicy != nil {
+		maxAttempts = ao.RetryPolicy.MaximumAttempts
+	}
+	if maxAttempts == 1 || state == enumspb.RETRY_STATE_MAXIMUM_ATTEMPTS_REACHED {
+		return maxAttempts
+	}
+	return 0
+}
+
+// retryStateName 返回 MaximumAttemptsReached 形式的名字，未指定时为空
+func retryStateName(state enumspb.RetryState) string {
+	if state == enumspb.RETRY_STATE_UNSPECIFIED {
+		return ""
+	}
+	return state.String()
+}
+
+// failWorkflow 把节点错误转换为带 NodeFailure 的 ApplicationError：消息为错误栈，原错误作为 cause 保留
+func failWorkflow(err error) error {
+	var ne *nodeError
+	if !errors.As(err, &ne) {
+		return err
+	}
+	return temporal.NewApplicationErrorWithCause(ne.trace(), NodeFailedType, ne.err, ne.NodeFailure)
+}
+
+// FailureOf 从执行结果的错误中取出失败节点的信息
+func FailureOf(err error) (NodeFailure, bool) {
+	var appErr *temporal.ApplicationError
+	if !errors.As(err, &appErr) || appErr.Type() != NodeFailedType || !appErr.HasDetails() {
+		return NodeFailure{}, false
+	}
+	var f NodeFailure
+	if err := appErr.Details(&f); err != nil {
+		return NodeFailure{}, false
+	}
+	return f, true
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,94 @@
+package dsl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+const failureTestYAML = `
+variables: { items: [1, 2] }
+root:
+  - activity: { name: DoA, args: [{ int: 1 }], result: a }
+  - map:
+      itemsRef: items
+      body:
+        id: charge
+        activity:
+          name: DoB
+          args: [{ ref: _item }]
+          opts: { retry: { maxAttempts: 1 } }
+`
+
+func TestNodeFailure(t *testing.T) {
+	env := startDSL(t, failureTestYAML, beforeRun(func(env *testsuite.TestWorkflowEnvironment) {
+		env.OnActivity("DoB", mock.Anything, mock.Anything).Return("", errors.New("card declined"))
+	}))
+
+	err := env.GetWorkflowError()
+	var appErr *temporal.ApplicationError
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, NodeFailedType, appErr.Type())
+	require.Equal(t, "map[1] > activity[charge]", appErr.Message())
+	require.ErrorContains(t, err, "card declined")
+
+	f, ok := FailureOf(err)
+	require.True(t, ok)
+	require.Equal(t, "charge", f.NodeID)
+	require.Equal(t, "root[1].map.body", f.Path)
+	require.Equal(t, KindActivity, f.Kind)
+	require.Equal(t, "DoB", f.Activity)
+	require.EqualValues(t, 1, f.Attempt)
+	require.Subset(t, f.Bindings, []string{"_item", "a", "items"})
+	require.Contains(t, f.Message, "activity DoB failed")
+	require.Equal(t, []string{"map[1]", "activity[charge]"}, f.Stack)
+
+	// 轨迹中每个失败事件带从该节点起的错误栈
+	stacks := map[string][]string{}
+	for _, ev := range queryTrace(t, env) {
+		if ev.Status == TraceFailed {
+			stacks[ev.Path] = ev.Stack
+		}
+	}
+	require.Equal(t, map[string][]string{
+		"root[1]":          {"map[1]", "activity[charge]"},
+		"root[1].map.body": {"activity[charge]"},
+	}, stacks)
+
+	// 测试环境不填写 RetryState，单独检查名称转换
+	require.Equal(t, "MaximumAttemptsReached", retryStateName(enumspb.RETRY_STATE_MAXIMUM_ATTEMPTS_REACHED))
+
+	// 其他错误不带节点信息
+	_, ok = FailureOf(errors.New("plain"))
+	require.False(t, ok)
+}
+
+func TestNodeFailureStack(t *testing.T) {
+	const def = `
+variables: { go: true }
+root:
+  - id: poll
+    while:
+      cond: { truthy: { ref: go } }
+      maxIters: 1
+      body:
+        parallel:
+          - noop: {}
+          - activity: { name: DoB, args: [{ int: 1 }] }
+`
+	env := startDSL(t, def, beforeRun(func(env *testsuite.TestWorkflowEnvironment) {
+		env.OnActivity("DoB", mock.Anything, mock.Anything).Return("", temporal.NewNonRetryableApplicationError("gone", "Gone", nil))
+	}))
+
+	err := env.GetWorkflowError()
+	require.ErrorContains(t, err, "while[poll] > parallel > activity[DoB] (type: NodeFailed")
+	f, ok := FailureOf(err)
+	require.True(t, ok)
+	require.Equal(t, []string{"while[poll]", "parallel", "activity[DoB]"}, f.Stack)
+	require.Equal(t, "root[0].while.body.parallel[1]", f.Path)
+}
@@ -0,0 +1,168 @@
+package dsl
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Func 是 Value 中的字符串函数，参数本身也是 Value（可嵌套 Func），例如
+//
+//	fn: { name: concat, args: [{ ref: first }, { str: " " }, { ref: last }] }
+type Func struct {
+	Name string  `yaml:"name" json:"name"`
+	Args []Value `yaml:"args,omitempty" json:"args,omitempty"`
+}
+
+// 支持的字符串函数及参数个数 [最少, 最多]（-1 表示不限）
+var funcArity = map[string][2]int{
+	"concat":    {1, -1}, // concat(a, b, ...)：各参数按 %v 拼接
+	"split":     {2, 2},  // split(s, sep) -> 列表
+	"join":      {2, 2},  // join(list, sep)：元素按 %v 拼接
+	"trim":      {1, 2},  // trim(s[, cutset])：默认去掉首尾空白
+	"upper":     {1, 1},
+	"lower":     {1, 1},
+	"substring": {2, 3},  // substring(s, start[, end])：按字符计，越界时截到两端
+	"format":    {1, -1}, // format(layout, args...)：同 fmt.Sprintf
+}
+
+func funcNames() string {
+	return "concat/split/join/trim/upper/lower/substring/format"
+}
+
+// checkFunc 检查函数名与参数个数
+func checkFunc(f Func) error {
+	arity, ok := funcArity[f.Name]
+	if !ok {
+		return fmt.Errorf("unknown function %q (want %s)", f.Name, funcNames())
+	}
+	if n := len(f.Args); n < arity[0] || arity[1] >= 0 && n > arity[1] {
+		return fmt.Errorf("%s takes %s, got %d", f.Name, arityString(arity), n)
+	}
+	return nil
+}
+
+func arityString(a [2]int) string {
+	plural := func(n int) string {
+		if n == 1 {
+			return "1 arg"
+		}
+		return fmt.Sprintf("%d args", n)
+	}
+	switch {
+	case a[1] < 0:
+		return "at least " + plural(a[0])
+	case a[0] == a[1]:
+		return plural(a[0])
+	}
+	return fmt.Sprintf("%d to %d args", a[0], a[1])
+}
+
+func evalFunc(f Func, bindings map[string]any) (any, error) {
+	if err := checkFunc(f); err != nil {
+		return nil, err
+	}
+	args := make([]any, len(f.Args))
+	for i, a := range f.Args {
+		v, err := evalValue(a, bindings)
+		if err != nil {
+			return nil, fmt.Errorf("%s: arg[%d]: %w", f.Name, i, err)
+		}
+		args[i] = v
+	}
+	str := func(i int) (string, error) {
+		s, ok := args[i].(string)
+		if !ok {
+			return "", fmt.Errorf("%s: arg[%d] is %T, not a string", f.Name, i, args[i])
+		}
+		return s, nil
+	}
+	integer := func(i int) (int, error) {
+		n, ok := toFloat(args[i])
+		if !ok || n != float64(int(n)) {
+			return 0, fmt.Errorf("%s: arg[%d] is %v, not an integer", f.Name, i, args[i])
+		}
+		return int(n), nil
+	}
+
+	switch f.Name {
+	case "concat":
+		var b strings.Builder
+		for _, a := range args {
+			fmt.Fprint(&b, a)
+		}
+		return b.String(), nil
+	case "split":
+		s, err := str(0)
+		if err != nil {
+			return nil, err
+		}
+		sep, err := str(1)
+		if err != nil {
+			return nil, err
+		}
+		var out []any
+		for _, part := range strings.Split(s, sep) {
+			out = append(out, part)
+		}
+		return out, nil
+	case "join":
+		rv := reflect.ValueOf(args[0])
+		if args[0] == nil || rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, fmt.Errorf("join: arg[0] is %T, not a list", args[0])
+		}
+		sep, err := str(1)
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]string, rv.Len())
+		for i := range parts {
+			parts[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return strings.Join(parts, sep), nil
+	case "format":
+		layout, err := str(0)
+		if err != nil {
+			return nil, err
+		}
+		return fmt.Sprintf(layout, args[1:]...), nil
+	}
+
+	// 其余函数的第一个参数都是字符串
+	s, err := str(0)
+	if err != nil {
+		return nil, err
+	}
+	switch f.Name {
+	case "trim":
+		if len(args) == 1 {
+			return strings.TrimSpace(s), nil
+		}
+		cutset, err := str(1)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Trim(s, cutset), nil
+	case "upper":
+		return strings.ToUpper(s), nil
+	case "lower":
+		return strings.ToLower(s), nil
+	}
+	// substring
+	runes := []rune(s)
+	start, err := integer(1)
+	if err != nil {
+		return nil, err
+	}
+	end := len(runes)
+	if len(args) == 3 {
+		if end, err = integer(2); err != nil {
+			return nil, err
+		}
+	}
+	start, end = max(0, min(start, len(runes))), max(0, min(end, len(runes)))
+	if start >= end {
+		return "", nil
+	}
+	return string(runes[start:end]), nil
+}
@@ -0,0 +1,51 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestEvalFunc(t *testing.T) {
+	bindings := map[string]any{
+		"first": "Ada",
+		"last":  "Lovelace",
+		"tags":  []any{"a", "b", 3},
+		"csv":   " x,y ,z ",
+		"n":     int64(42),
+	}
+	for _, tc := range []struct {
+		fn   string
+		want any
+		err  string
+	}{
+		{fn: `{ name: concat, args: [{ ref: first }, { str: " " }, { ref: last }, { int: 1 }] }`, want: "Ada Lovelace1"},
+		{fn: `{ name: split, args: [{ ref: csv }, { str: "," }] }`, want: []any{" x", "y ", "z "}},
+		{fn: `{ name: join, args: [{ ref: tags }, { str: "|" }] }`, want: "a|b|3"},
+		{fn: `{ name: trim, args: [{ ref: csv }] }`, want: "x,y ,z"},
+		{fn: `{ name: trim, args: [{ str: "--id--" }, { str: "-" }] }`, want: "id"},
+		{fn: `{ name: upper, args: [{ ref: first }] }`, want: "ADA"},
+		{fn: `{ name: lower, args: [{ fn: { name: concat, args: [{ ref: first }, { ref: last }] } }] }`, want: "adalovelace"},
+		{fn: `{ name: substring, args: [{ str: "héllo" }, { int: 1 }, { int: 3 }] }`, want: "él"},
+		{fn: `{ name: substring, args: [{ ref: last }, { int: 4 }] }`, want: "lace"},
+		{fn: `{ name: substring, args: [{ ref: last }, { int: 6 }, { int: 99 }] }`, want: "ce"},
+		{fn: `{ name: format, args: [{ str: "%s-%05d" }, { ref: first }, { ref: n }] }`, want: "Ada-00042"},
+		{fn: `{ name: upper, args: [{ ref: n }] }`, err: "upper: arg[0] is int64, not a string"},
+		{fn: `{ name: join, args: [{ ref: first }, { str: "," }] }`, err: "join: arg[0] is string, not a list"},
+		{fn: `{ name: substring, args: [{ ref: first }, { float: 1.5 }] }`, err: "substring: arg[1] is 1.5, not an integer"},
+		{fn: `{ name: split, args: [{ ref: first }] }`, err: "split takes 2 args, got 1"},
+		{fn: `{ name: reverse, args: [{ ref: first }] }`, err: `unknown function "reverse" (want concat/split/join/trim/upper/lower/substring/format)`},
+		{fn: `{ name: upper, args: [{ ref: missing }] }`, err: `upper: arg[0]: ref "missing" not found`},
+	} {
+		var f Func
+		require.NoError(t, yaml.Unmarshal([]byte(tc.fn), &f), tc.fn)
+		got, err := evalValue(Value{Fn: &f}, bindings)
+		if tc.err != "" {
+			require.EqualError(t, err, tc.err, tc.fn)
+			continue
+		}
+		require.NoError(t, err, tc.fn)
+		require.Equal(t, tc.want, got, tc.fn)
+	}
+}
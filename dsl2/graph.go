@@ -0,0 +1,150 @@
+package dsl
+
+// Graph 是工作流定义的规范化节点/边表示，供可视化画布直接渲染，
+// 保证画布与引擎使用同一份经过校验的结构
+type Graph struct {
+	Nodes []*GraphNode `json:"nodes"`
+	Edges []*GraphEdge `json:"edges"`
+}
+
+// GraphNode 是图中的一个节点
+type GraphNode struct {
+	ID     string     `json:"id"`               // 稳定的路径 ID，如 root[1].parallel[0]
+	Type   string     `json:"type"`             // start/end/activity/parallel/map/while/if
+	Label  string     `json:"label"`            // 展示名：Statement.ID 或 Activity 名
+	Parent string     `json:"parent,omitempty"` // 所属组合节点
+	Depth  int        `json:"depth"`
+	Spec   *Statement `json:"spec,omitempty"` // 节点自身配置；子语句已剥离，由边表达
+}
+
+// GraphEdge 是两节点间的关系
+type GraphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Kind  string `json:"kind"`            // next/branch/body/then/else/step
+	Index int    `json:"index,omitempty"` // parallel 分支序号或 session 步骤序号
+}
+
+// 画布上的起止节点 ID
+const (
+	GraphStartID = "start"
+	GraphEndID   = "end"
+)
+
+// BuildGraph 校验工作流并生成节点/边图
+func BuildGraph(wf Workflow) (*Graph, error) {
+	if err := wf.validate(); err != nil {
+		return nil, err
+	}
+	g := &Graph{}
+	g.Nodes = append(g.Nodes, &GraphNode{ID: GraphStartID, Type: "start", Label: "Start"})
+
+	var add func(path, parent string, st *Statement, depth int)
+	add = func(path, parent string, st *Statement, depth int) {
+		g.Nodes = append(g.Nodes, &GraphNode{
+			ID:     path,
+			Type:   st.Kind(),
+			Label:  nodeLabel(st),
+			Parent: parent,
+			Depth:  depth,
+			Spec:   shallowSpec(st),
+		})
+		for _, c := range st.children() {
+			childPath := path + "." + c.rel
+			g.Edges = append(g.Edges, &GraphEdge{From: path, To: childPath, Kind: c.edge, Index: c.index})
+			add(childPath, path, c.stmt, depth+1)
+		}
+	}
+
+	prev := GraphStartID
+	for i, st := range wf.Root {
+		path := rootPath(i)
+		g.Edges = append(g.Edges, &GraphEdge{From: prev, To: path, Kind: "next"})
+		add(path, "", st, 0)
+		prev = path
+	}
+	g.Nodes = append(g.Nodes, &GraphNode{ID: GraphEndID, Type: "end", Label: "End"})
+	g.Edges = append(g.Edges, &GraphEdge{From: prev, To: GraphEndID, Kind: "next"})
+	return g, nil
+}
+
+func nodeLabel(st *Statement) string {
+	if st.ID != "" {
+		return st.ID
+	}
+	if st.Activity != nil {
+		return st.Activity.Name
+	}
+	if st.Marker != nil && st.Marker.Name != "" {
+		return st.Marker.Name
+	}
+	return st.Kind()
+}
+
+// shallowSpec 复制语句自身的配置并去掉子语句
+func shallowSpec(st *Statement) *Statement {
+	cp := &Statement{ID: st.ID}
+	switch {
+	case st.Activity != nil:
+		a := *st.Activity
+		cp.Activity = &a
+	case st.Parallel != nil:
+		cp.Parallel = &Parallel{}
+	case st.Map != nil:
+		m := *st.Map
+		m.Body = nil
+		cp.Map = &m
+	case st.While != nil:
+		w := *st.While
+		w.Body = nil
+		cp.While = &w
+	case st.If != nil:
+		i := *st.If
+		i.Then, i.Else = nil, nil
+		cp.If = &i
+	case st.Session != nil:
+		se := *st.Session
+		se.Steps = nil
+		cp.Session = &se
+	case st.Marker != nil:
+		m := *st.Marker
+		cp.Marker = &m
+	case st.Log != nil:
+		l := *st.Log
+		cp.Log = &l
+	case st.Noop != nil:
+		n := *st.Noop
+		cp.Noop = &n
+	case st.Append != nil:
+		a := *st.Append
+		cp.Append = &a
+	case st.Merge != nil:
+		m := *st.Merge
+		cp.Merge = &m
+	case st.Unset != nil:
+		u := *st.Unset
+		cp.Unset = &u
+	case st.Increment != nil:
+		inc := *st.Increment
+		cp.Increment = &inc
+	case st.Random != nil:
+		r := *st.Random
+		cp.Random = &r
+	case st.Now != nil:
+		n := *st.Now
+		cp.Now = &n
+	case st.NewID != nil:
+		n := *st.NewID
+		cp.NewID = &n
+	case st.Aggregate != nil:
+		a := *st.Aggregate
+		cp.Aggregate = &a
+	case st.Set != nil:
+		set := *st.Set
+		cp.Set = &set
+	case st.Wait != nil:
+		w := *st.Wait
+		cp.Wait = &w
+	}
+	return cp
+}
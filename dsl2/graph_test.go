@@ -0,0 +1,98 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const graphTestYAML = `
+version: "1.0"
+taskQueue: demo
+variables:
+  x: 5
+  urls: ["a", "b"]
+root:
+  - id: fanout
+    parallel:
+      - activity: { name: DoA, args: [{ ref: x }], result: a }
+      - activity: { name: DoB, args: [{ int: 2 }], result: b }
+  - map:
+      itemsRef: urls
+      itemVar: url
+      collectVar: pages
+      body:
+        activity: { name: Fetch, args: [{ ref: url }], result: page }
+  - if:
+      cond:
+        eq: { left: { ref: x }, right: { int: 5 } }
+      then:
+        while:
+          cond: { not: { truthy: { ref: approved } } }
+          maxIters: 3
+          body:
+            activity: { name: MockApprove, result: approved }
+      else:
+        activity: { name: DoC, args: [{ ref: a }, { ref: b }], result: c }
+  - session:
+      creationTimeoutSec: 30
+      steps:
+        - activity: { name: DoA, args: [{ ref: c }], result: d }
+        - activity: { name: DoB, args: [{ ref: d }], result: e }
+  - marker: { name: approved, refs: [approved, e] }
+`
+
+func TestBuildGraph(t *testing.T) {
+	var wf Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(graphTestYAML), &wf))
+
+	g, err := BuildGraph(wf)
+	require.NoError(t, err)
+
+	byID := map[string]*GraphNode{}
+	for _, n := range g.Nodes {
+		byID[n.ID] = n
+	}
+	require.Equal(t, "fanout", byID["root[0]"].Label)
+	require.Equal(t, KindActivity, byID["root[0].parallel[1]"].Type)
+	require.Equal(t, "root[0]", byID["root[0].parallel[1]"].Parent)
+	require.Equal(t, 2, byID["root[2].if.then.while.body"].Depth)
+	require.Nil(t, byID["root[1]"].Spec.Map.Body, "children are expressed as edges")
+	require.Contains(t, g.Edges, &GraphEdge{From: "root[2]", To: "root[2].if.else", Kind: "else"})
+	require.Equal(t, 30, byID["root[3]"].Spec.Session.CreationTimeoutSec)
+	require.Contains(t, g.Edges, &GraphEdge{From: "root[3]", To: "root[3].session.steps[1]", Kind: "step", Index: 1})
+	require.Equal(t, "approved", byID["root[4]"].Label)
+	require.Equal(t, []string{"approved", "e"}, byID["root[4]"].Spec.Marker.Refs)
+	require.Contains(t, g.Edges, &GraphEdge{From: "root[4]", To: GraphEndID, Kind: "next"})
+}
+
+func TestComposeGraphRoundTrip(t *testing.T) {
+	var wf Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(graphTestYAML), &wf))
+	g, err := BuildGraph(wf)
+	require.NoError(t, err)
+
+	root, err := ComposeGraph(g)
+	require.NoError(t, err)
+	require.Equal(t, wf.Root, root)
+
+	out, err := MarshalYAML(Workflow{Version: wf.Version, TaskQueue: wf.TaskQueue, Variables: wf.Variables, Root: root})
+	require.NoError(t, err)
+	var again Workflow
+	require.NoError(t, yaml.Unmarshal(out, &again))
+	require.Equal(t, wf, again)
+}
+
+func TestComposeGraphRejectsDanglingNodes(t *testing.T) {
+	g := &Graph{
+		Nodes: []*GraphNode{
+			{ID: GraphStartID, Type: "start"},
+			{ID: "a", Type: KindActivity, Spec: &Statement{Activity: &ActivityInvocation{Name: "DoA"}}},
+			{ID: "orphan", Type: KindActivity, Spec: &Statement{Activity: &ActivityInvocation{Name: "DoB"}}},
+		},
+		Edges: []*GraphEdge{{From: GraphStartID, To: "a", Kind: "next"}},
+	}
+	_, err := ComposeGraph(g)
+	require.ErrorContains(t, err, "orphan")
+}
@@ -0,0 +1,7 @@
+// Package integration 是端到端测试：在 Temporal 开发服务器上启动真实的 worker 与 starter 二进制，
+// 执行 web UI 的全部示例，并与进程内测试环境（注册同样的 Activity）得到的结果比较。
+// 测试需要 integration 构建标签，以及 PATH 中的 Temporal CLI（或 TEMPORAL_CLI）或已运行的服务器：
+//
+//	go test -tags integration ./integration
+//	DSL_INTEGRATION_HOSTPORT=localhost:7233 go test -tags integration ./integration
+package integration
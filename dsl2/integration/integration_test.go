@@ -0,0 +1,301 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// harness 是测试共用的服务器、worker 与构建产物；第一个测试启动它，TestMain 结束时停止
+var harness struct {
+	once      sync.Once
+	skip, err string
+
+	dir       string // 二进制、日志与改写后的示例
+	hostPort  string
+	taskQueue string
+	procs     []*exec.Cmd
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	for _, p := range harness.procs {
+		_ = p.Process.Kill()
+		_ = p.Wait()
+	}
+	if harness.dir != "" {
+		os.RemoveAll(harness.dir)
+	}
+	os.Exit(code)
+}
+
+// setup 启动（仅一次）开发服务器与 worker；没有 Temporal CLI 也没有 DSL_INTEGRATION_HOSTPORT 时跳过
+func setup(t *testing.T) {
+	t.Helper()
+	harness.once.Do(func() {
+		if err := start(); err != nil {
+			harness.err = err.Error()
+		}
+	})
+	if harness.skip != "" {
+		t.Skip(harness.skip)
+	}
+	if harness.err != "" {
+		t.Fatal(harness.err)
+	}
+}
+
+func start() error {
+	dir, err := os.MkdirTemp("", "dsl-integration-")
+	if err != nil {
+		return err
+	}
+	harness.dir = dir
+	harness.taskQueue = fmt.Sprintf("dsl-integration-%d", time.Now().UnixNano())
+
+	harness.hostPort = os.Getenv("DSL_INTEGRATION_HOSTPORT")
+	if harness.hostPort == "" {
+		cli := os.Getenv("TEMPORAL_CLI")
+		if cli == "" {
+			if cli, err = exec.LookPath("temporal"); err != nil {
+				harness.skip = "Temporal CLI not found; install it, set TEMPORAL_CLI or DSL_INTEGRATION_HOSTPORT"
+				return nil
+			}
+		}
+		port, err := freePort()
+		if err != nil {
+			return err
+		}
+		harness.hostPort = fmt.Sprintf("127.0.0.1:%d", port)
+		if err := spawn("server", cli, nil, "server", "start-dev", "--headless",
+			"--ip", "127.0.0.1", "--port", fmt.Sprint(port), "--log-level", "error"); err != nil {
+			return err
+		}
+	}
+	if err := waitForServer(60 * time.Second); err != nil {
+		return fmt.Errorf("temporal server at %s: %v\n%s", harness.hostPort, err, logTail("server"))
+	}
+
+	// 与发布一致地构建二进制，覆盖 main 包中的参数与连接配置
+	for _, cmd := range []string{"worker", "starter"} {
+		out, err := exec.Command("go", "build", "-o", filepath.Join(dir, cmd), "../cmd/"+cmd).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("build %s: %v\n%s", cmd, err, out)
+		}
+	}
+
+	healthPort, err := freePort()
+	if err != nil {
+		return err
+	}
+	if err := spawn("worker", filepath.Join(dir, "worker"), []string{
+		"HEALTH_ADDR=127.0.0.1:" + fmt.Sprint(healthPort),
+		"WORKER_CONFIG=",
+	}); err != nil {
+		return err
+	}
+	if err := waitForReady(fmt.Sprintf("http://127.0.0.1:%d/readyz", healthPort), 60*time.Second); err != nil {
+		return fmt.Errorf("worker: %v\n%s", err, logTail("worker"))
+	}
+	return nil
+}
+
+// spawn 在后台运行 name 对应的进程，输出写入 <dir>/<name>.log
+func spawn(name, bin string, env []string, args ...string) error {
+	log, err := os.Create(filepath.Join(harness.dir, name+".log"))
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(bin, args...)
+	cmd.Env = append(connEnv(), env...)
+	cmd.Stdout, cmd.Stderr = log, log
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start %s: %w", name, err)
+	}
+	harness.procs = append(harness.procs, cmd)
+	return nil
+}
+
+// connEnv 让 worker 与 starter 连接测试服务器，不受本机 Profile 与环境的影响
+func connEnv() []string {
+	return append(os.Environ(),
+		"TEMPORAL_HOSTPORT="+harness.hostPort,
+		"TEMPORAL_NAMESPACE=default",
+		"TASK_QUEUE="+harness.taskQueue,
+		"DSL_CONFIG="+filepath.Join(harness.dir, "no-config.yaml"),
+		"DSL_PROFILE=",
+	)
+}
+
+func waitForServer(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		c, err := client.Dial(client.Options{HostPort: harness.hostPort})
+		if err == nil {
+			_, err = c.CheckHealth(context.Background(), &client.CheckHealthRequest{})
+			c.Close()
+			if err == nil {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func waitForReady(url string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+			err = fmt.Errorf("%s: %s", url, resp.Status)
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// logTail 返回进程日志的最后几行，附在失败信息中
+func logTail(name string) string {
+	b, _ := os.ReadFile(filepath.Join(harness.dir, name+".log"))
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	return name + " log:\n" + strings.Join(lines[max(len(lines)-20, 0):], "\n")
+}
+
+// startResult 是 starter -output json 的输出（见 cmd/starter 的 StartResult）
+type startResult struct {
+	WorkflowID string         `json:"workflowId"`
+	Status     string         `json:"status"`
+	Result     map[string]any `json:"result"`
+	Error      string         `json:"error"`
+}
+
+// runStarter 用 starter 二进制执行 yamlPath 并等待结果
+func runStarter(t *testing.T, yamlPath string, args ...string) startResult {
+	t.Helper()
+	cmd := exec.Command(filepath.Join(harness.dir, "starter"),
+		append([]string{"-f", yamlPath, "-output", "json", "-timeout", "2m"}, args...)...)
+	cmd.Env = connEnv()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, _ := cmd.Output() // 执行失败时 starter 以非零码退出，结果仍在 stdout
+	var res startResult
+	require.NoError(t, json.Unmarshal(out, &res), "starter output:\n%s\n%s\n%s", out, stderr.String(), logTail("worker"))
+	return res
+}
+
+// inProcess 在测试环境中用真实的 Activity 实现执行 wf，作为端到端结果的参照
+func inProcess(t *testing.T, wf dsl.Workflow) map[string]any {
+	t.Helper()
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(dsl.SimpleDSLWorkflow)
+	env.RegisterActivity(&dsl.Activities{})
+	env.ExecuteWorkflow(dsl.SimpleDSLWorkflow, wf)
+	require.NoError(t, env.GetWorkflowError())
+	var out map[string]any
+	require.NoError(t, env.GetWorkflowResult(&out))
+	return normalize(t, out)
+}
+
+func normalize(t *testing.T, m map[string]any) map[string]any {
+	b, err := json.Marshal(m)
+	require.NoError(t, err)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(b, &out))
+	return out
+}
+
+// needsNetwork 中的示例调用外部 HTTP 服务，只在 DSL_INTEGRATION_NETWORK=1 时执行
+var needsNetwork = map[string]bool{"http-request": true}
+
+func TestExamples(t *testing.T) {
+	setup(t)
+	files, err := filepath.Glob("../cmd/webui/examples/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			if needsNetwork[name] && os.Getenv("DSL_INTEGRATION_NETWORK") != "1" {
+				t.Skip("calls external services; set DSL_INTEGRATION_NETWORK=1")
+			}
+			t.Parallel()
+			b, err := os.ReadFile(file)
+			require.NoError(t, err)
+			// starter 不认识示例的 front-matter，去掉后另存
+			if rest, ok := bytes.CutPrefix(b, []byte("---\n")); ok {
+				_, b, ok = bytes.Cut(rest, []byte("\n---\n"))
+				require.True(t, ok, "unterminated front-matter")
+			}
+			path := filepath.Join(harness.dir, name+".yaml")
+			require.NoError(t, os.WriteFile(path, b, 0o644))
+			wf, err := dsl.ParseYAML(b)
+			require.NoError(t, err)
+
+			res := runStarter(t, path, "-id", "it-"+name+"-"+harness.taskQueue)
+			require.Equal(t, "Completed", res.Status, "%s\n%s", res.Error, logTail("worker"))
+			require.Equal(t, inProcess(t, wf), res.Result)
+		})
+	}
+}
+
+func TestFailureIsReported(t *testing.T) {
+	setup(t)
+	path := filepath.Join(harness.dir, "failing.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+retry: { maxAttempts: 1 }
+root:
+  - activity: { name: DoesNotExist, result: r }
+`), 0o644))
+	res := runStarter(t, path)
+	require.Equal(t, "Failed", res.Status)
+	require.Contains(t, res.Error, "DoesNotExist")
+}
+
+func TestVariableOverrides(t *testing.T) {
+	setup(t)
+	path := filepath.Join(harness.dir, "vars.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+variables: { x: 1 }
+root:
+  - activity: { name: DoA, args: [{ ref: x }], result: a }
+`), 0o644))
+	res := runStarter(t, path, "-var", "x=42")
+	require.Equal(t, "Completed", res.Status, res.Error)
+	require.Equal(t, "A:42", res.Result["a"])
+}
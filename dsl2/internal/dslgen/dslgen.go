@@ -0,0 +1,182 @@
+// Package dslgen 随机生成合法的工作流定义（深度与宽度有界），并给出执行后应得的绑定，
+// 供属性测试在测试环境中检查引擎的不变式：不死锁、重复执行结果一致、绑定守恒
+package dslgen
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+// Options 限定生成的规模；零值使用默认值
+type Options struct {
+	MaxDepth int // 组合语句的最大嵌套层数，默认 3
+	MaxWidth int // 顺序块、parallel 分支的最大语句数，默认 3
+	MaxItems int // map 的最大元素数，默认 6
+}
+
+// Case 是一个生成的定义及其预期结果
+type Case struct {
+	Workflow dsl.Workflow
+	// Want 是执行成功后的全部绑定（JSON 形式，数字为 float64）；不多不少
+	Want map[string]any
+	// Skipped 是只在未选中的 If 分支中写入、不应出现在结果中的变量
+	Skipped []string
+	// MapItems 按 Map 节点路径给出元素个数，即该 map.body 应执行的次数
+	MapItems map[string]int
+}
+
+// Generate 按 seed 生成一个定义；同一 seed 总是生成相同的定义
+func Generate(seed uint64, opts Options) Case {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = 3
+	}
+	if opts.MaxWidth <= 0 {
+		opts.MaxWidth = 3
+	}
+	if opts.MaxItems <= 0 {
+		opts.MaxItems = 6
+	}
+	g := &generator{
+		r:    rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+		opts: opts,
+		c: Case{
+			Workflow: dsl.Workflow{Version: dsl.SchemaVersion, TaskQueue: "fuzz", Variables: map[string]any{}},
+			Want:     map[string]any{},
+			MapItems: map[string]int{},
+		},
+	}
+	n := 1 + g.r.IntN(opts.MaxWidth)
+	for i := range n {
+		g.c.Workflow.Root = append(g.c.Workflow.Root, g.statement(fmt.Sprintf("root[%d]", i), 0, true, false))
+	}
+	// 语句写入的值优先于初始值（如 while 计数器）
+	for k, v := range g.c.Workflow.Variables {
+		if _, ok := g.c.Want[k]; !ok {
+			g.c.Want[k] = v
+		}
+	}
+	return g.c
+}
+
+type generator struct {
+	r    *rand.Rand
+	opts Options
+	c    Case
+	next int
+	// inSession 为 true 时正在生成 session 的步骤；session 不能嵌套
+	inSession bool
+}
+
+// name 返回带序号的唯一名字，保证各语句写入的变量互不冲突
+func (g *generator) name(prefix string) string {
+	g.next++
+	return fmt.Sprintf("%s%d", prefix, g.next)
+}
+
+// write 记录语句写入的变量：live 为 false 表示所在 If 分支不会被选中
+func (g *generator) write(v string, val any, live bool) {
+	if live {
+		g.c.Want[v] = val
+	} else {
+		g.c.Skipped = append(g.c.Skipped, v)
+	}
+}
+
+// statement 生成 path 处的语句。concurrent 为 true 时位于 parallel 分支或 map 迭代中，
+// 只能写入新变量（引擎合并时修改已有变量视为冲突），因此不生成 while
+func (g *generator) statement(path string, depth int, live, concurrent bool) *dsl.Statement {
+	kinds := []string{dsl.KindActivity, dsl.KindSet, dsl.KindLog}
+	if depth < g.opts.MaxDepth {
+		kinds = append(kinds, dsl.KindParallel, dsl.KindMap, dsl.KindIf, dsl.KindIf)
+		if !concurrent {
+			kinds = append(kinds, dsl.KindWhile)
+			if !g.inSession {
+				kinds = append(kinds, dsl.KindSession)
+			}
+		}
+	}
+	switch kinds[g.r.IntN(len(kinds))] {
+	case dsl.KindSet:
+		v := g.name("s")
+		n := int64(g.r.IntN(100))
+		g.write(v, float64(n), live)
+		return &dsl.Statement{Set: &dsl.Set{Var: v, Value: dsl.Value{Int: &n}}}
+
+	case dsl.KindLog:
+		return &dsl.Statement{Log: &dsl.Log{Message: "fuzz " + path}}
+
+	case dsl.KindParallel:
+		var p dsl.Parallel
+		for i := range 1 + g.r.IntN(g.opts.MaxWidth) {
+			p = append(p, g.statement(fmt.Sprintf("%s.parallel[%d]", path, i), depth+1, live, true))
+		}
+		return &dsl.Statement{Parallel: &p}
+
+	case dsl.KindMap:
+		items, act, collect := g.name("items"), g.name("M"), g.name("c")
+		n := g.r.IntN(g.opts.MaxItems + 1)
+		list := make([]any, n)
+		out := make([]any, n)
+		for i := range list {
+			list[i] = float64(i)
+			out[i] = act + ":mock"
+		}
+		g.c.Workflow.Variables[items] = list
+		g.write(collect, out, live)
+		if live {
+			g.c.MapItems[path] = n
+		}
+		m := &dsl.Map{
+			ItemsRef:    items,
+			Concurrency: 1 + g.r.IntN(3),
+			CollectVar:  collect,
+			Body:        &dsl.Statement{Activity: &dsl.ActivityInvocation{Name: act, Args: []dsl.Value{{Ref: "_item"}}, Result: collect}},
+		}
+		// 偶尔限速，覆盖按定时器补位的路径（测试环境跳过定时器）
+		if g.r.IntN(4) == 0 {
+			m.RatePerMinute = 60
+		}
+		return &dsl.Statement{Map: m}
+
+	case dsl.KindIf:
+		flag := g.name("flag")
+		cond := g.r.IntN(2) == 0
+		g.c.Workflow.Variables[flag] = cond
+		i := &dsl.If{
+			Cond: dsl.Cond{Truthy: &dsl.Value{Ref: flag}},
+			Then: g.statement(path+".if.then", depth+1, live && cond, concurrent),
+		}
+		if g.r.IntN(2) == 0 {
+			i.Else = g.statement(path+".if.else", depth+1, live && !cond, concurrent)
+		}
+		return &dsl.Statement{If: i}
+
+	case dsl.KindWhile:
+		counter := g.name("w")
+		n := int64(g.r.IntN(4))
+		g.c.Workflow.Variables[counter] = float64(0)
+		if live {
+			g.c.Want[counter] = float64(n)
+		}
+		return &dsl.Statement{While: &dsl.While{
+			Cond:     dsl.Cond{Ne: &dsl.Compare{Left: dsl.Value{Ref: counter}, Right: dsl.Value{Int: &n}}},
+			MaxIters: int(n) + 1,
+			Body:     &dsl.Statement{Increment: &dsl.Increment{Var: counter}},
+		}}
+
+	case dsl.KindSession:
+		s := &dsl.Session{}
+		g.inSession = true
+		defer func() { g.inSession = false }()
+		for i := range 1 + g.r.IntN(g.opts.MaxWidth) {
+			s.Steps = append(s.Steps, g.statement(fmt.Sprintf("%s.session.steps[%d]", path, i), depth+1, live, concurrent))
+		}
+		return &dsl.Statement{Session: s}
+	}
+
+	act, v := g.name("A"), g.name("v")
+	g.write(v, act+":mock", live)
+	return &dsl.Statement{Activity: &dsl.ActivityInvocation{Name: act, Result: v}}
+}
@@ -0,0 +1,101 @@
+package dslgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/simulate"
+)
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	a, b := Generate(7, Options{}), Generate(7, Options{})
+	require.Equal(t, a, b)
+	require.NotEqual(t, a.Workflow, Generate(8, Options{}).Workflow)
+}
+
+func TestEngineInvariants(t *testing.T) {
+	n := uint64(200)
+	if testing.Short() {
+		n = 30
+	}
+	for seed := range n {
+		t.Run(fmt.Sprint(seed), func(t *testing.T) { checkInvariants(t, Generate(seed, Options{})) })
+	}
+}
+
+func FuzzEngine(f *testing.F) {
+	for _, seed := range []uint64{0, 1, 42, 1 << 40} {
+		f.Add(seed, uint8(3), uint8(3))
+	}
+	f.Fuzz(func(t *testing.T, seed uint64, depth, width uint8) {
+		checkInvariants(t, Generate(seed, Options{MaxDepth: int(depth%4) + 1, MaxWidth: int(width%4) + 1}))
+	})
+}
+
+// checkInvariants 执行 c 两次并检查：定义合法、执行成功（不死锁）、绑定与预期一致、
+// 两次的结果与轨迹相同、每个开始事件恰有一个结束事件、map.body 执行次数等于元素个数
+func checkInvariants(t *testing.T, c Case) {
+	t.Helper()
+	dump := func() string { b, _ := json.MarshalIndent(c.Workflow, "", "  "); return string(b) }
+
+	for _, issue := range c.Workflow.Check(dsl.CheckOptions{}) {
+		require.NotEqual(t, "error", issue.Severity, "%s\n%s", issue, dump())
+	}
+
+	first := simulate.Run(c.Workflow, nil)
+	require.True(t, first.Success, "%s\n%s", first.Error, dump())
+	require.Equal(t, c.Want, normalize(t, first.Result), dump())
+	for _, v := range c.Skipped {
+		require.NotContains(t, first.Result, v, "variable from an untaken branch")
+	}
+
+	second := simulate.Run(c.Workflow, nil)
+	require.Equal(t, first.Result, second.Result, "bindings differ between runs")
+	require.Equal(t, first.Calls, second.Calls, "activity calls differ between runs")
+	require.Equal(t, events(first.Trace), events(second.Trace), "trace differs between runs")
+
+	ends := map[int]int{}
+	bodies := map[string]int{}
+	for _, e := range first.Trace {
+		switch e.Status {
+		case "started":
+			if p, ok := strings.CutSuffix(e.Path, ".map.body"); ok {
+				bodies[p]++
+			}
+		case "completed", "failed":
+			ends[e.StartSeq]++
+		}
+	}
+	for _, e := range first.Trace {
+		if e.Status == "started" {
+			require.Equal(t, 1, ends[e.Seq], "%s started once but ended %d time(s)", e.Path, ends[e.Seq])
+		}
+	}
+	for path, n := range c.MapItems {
+		require.Equal(t, n, bodies[path], "iterations of %s", path)
+	}
+}
+
+// events 把轨迹化为排序后的事件集合：测试环境中并发 Activity 的完成顺序不固定，
+// 只比较执行了哪些节点、各自的结果
+func events(trace []dsl.TraceEvent) []string {
+	out := make([]string, len(trace))
+	for i, e := range trace {
+		out[i] = fmt.Sprintf("%s %s %s %s", e.Path, e.Kind, e.Status, e.Error)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func normalize(t *testing.T, m map[string]any) map[string]any {
+	b, err := json.Marshal(m)
+	require.NoError(t, err)
+	out := map[string]any{}
+	require.NoError(t, json.Unmarshal(b, &out))
+	return out
+}
@@ -0,0 +1,391 @@
+// Package jsonschema 实现 JSON Schema 的一个常用子集，用于校验工作流结果的结构；
+// 支持 type/enum/const、对象（properties/required/additionalProperties）、数组（items/minItems/maxItems）、
+// 数值与字符串范围、pattern 以及 allOf/anyOf/oneOf/not；不支持 $ref，title/description 等注解忽略
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Check 检查 schema 本身是否合法（关键字类型正确、不含不支持的关键字），返回第一个问题
+func Check(schema any) error {
+	return check("", schema)
+}
+
+func check(at string, schema any) error {
+	if _, ok := schema.(bool); ok {
+		return nil
+	}
+	m, ok := schema.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: schema must be an object or boolean", pointer(at))
+	}
+	if _, ok := m["$ref"]; ok {
+		return fmt.Errorf("%s: $ref is not supported", pointer(at))
+	}
+	if t, ok := m["type"]; ok {
+		for _, name := range typeNames(t) {
+			if !knownTypes[name] {
+				return fmt.Errorf("%s: unknown type %v", pointer(at), name)
+			}
+		}
+		if len(typeNames(t)) == 0 {
+			return fmt.Errorf("%s: type must be a string or an array of strings", pointer(at))
+		}
+	}
+	if e, ok := m["enum"]; ok {
+		if _, ok := e.([]any); !ok {
+			return fmt.Errorf("%s: enum must be an array", pointer(at))
+		}
+	}
+	if p, ok := m["properties"]; ok {
+		props, ok := p.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: properties must be an object", pointer(at))
+		}
+		for _, name := range sortedKeys(props) {
+			if err := check(at+"/properties/"+name, props[name]); err != nil {
+				return err
+			}
+		}
+	}
+	if r, ok := m["required"]; ok {
+		list, ok := r.([]any)
+		if !ok {
+			return fmt.Errorf("%s: required must be an array of strings", pointer(at))
+		}
+		for _, name := range list {
+			if _, ok := name.(string); !ok {
+				return fmt.Errorf("%s: required must be an array of strings", pointer(at))
+			}
+		}
+	}
+	for _, key := range []string{"additionalProperties", "items", "not"} {
+		if sub, ok := m[key]; ok {
+			if err := check(at+"/"+key, sub); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if v, ok := m[key]; ok {
+			list, ok := v.([]any)
+			if !ok || len(list) == 0 {
+				return fmt.Errorf("%s: %s must be a non-empty array", pointer(at), key)
+			}
+			for i, sub := range list {
+				if err := check(fmt.Sprintf("%s/%s/%d", at, key, i), sub); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, key := range []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "minLength", "maxLength", "minItems", "maxItems"} {
+		if v, ok := m[key]; ok {
+			if _, ok := number(v); !ok {
+				return fmt.Errorf("%s: %s must be a number", pointer(at), key)
+			}
+		}
+	}
+	if p, ok := m["pattern"]; ok {
+		s, ok := p.(string)
+		if !ok {
+			return fmt.Errorf("%s: pattern must be a string", pointer(at))
+		}
+		if _, err := regexp.Compile(s); err != nil {
+			return fmt.Errorf("%s: pattern: %w", pointer(at), err)
+		}
+	}
+	return nil
+}
+
+// Validate 返回 v 不满足 schema 的全部问题，形如 "/orders/0/id: expected integer, got string"；
+// v 先经过一次 JSON 编解码，因此结构体与各种数值类型都按 JSON 的形态校验
+func Validate(schema, v any) ([]string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode value: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("decode value: %w", err)
+	}
+	var errs []string
+	validate("", schema, doc, &errs)
+	return errs, nil
+}
+
+var knownTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true,
+}
+
+func validate(at string, schema, v any, errs *[]string) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, pointer(at)+": "+fmt.Sprintf(format, args...))
+	}
+	if b, ok := schema.(bool); ok {
+		if !b {
+			fail("no value is allowed")
+		}
+		return
+	}
+	m, _ := schema.(map[string]any)
+
+	if t, ok := m["type"]; ok {
+		names := typeNames(t)
+		matched := false
+		for _, name := range names {
+			if hasType(v, name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("expected %s, got %s", strings.Join(names, " or "), typeOf(v))
+			return // 类型不符时其余关键字的报错没有意义
+		}
+	}
+	if e, ok := m["enum"].([]any); ok {
+		found := false
+		for _, x := range e {
+			if equal(x, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value must be one of %s", compact(e))
+		}
+	}
+	if c, ok := m["const"]; ok && !equal(c, v) {
+		fail("value must be %s", compact(c))
+	}
+
+	switch x := v.(type) {
+	case map[string]any:
+		props, _ := m["properties"].(map[string]any)
+		if req, ok := m["required"].([]any); ok {
+			for _, r := range req {
+				if name, _ := r.(string); name != "" {
+					if _, ok := x[name]; !ok {
+						fail("missing required property %q", name)
+					}
+				}
+			}
+		}
+		for _, k := range sortedKeys(x) {
+			if sub, ok := props[k]; ok {
+				validate(at+"/"+escape(k), sub, x[k], errs)
+			} else if add, ok := m["additionalProperties"]; ok {
+				if b, ok := add.(bool); ok && !b {
+					fail("unexpected property %q", k)
+				} else if !ok {
+					validate(at+"/"+escape(k), add, x[k], errs)
+				}
+			}
+		}
+	case []any:
+		if n, ok := number(m["minItems"]); ok && float64(len(x)) < n {
+			fail("expected at least %v items, got %d", n, len(x))
+		}
+		if n, ok := number(m["maxItems"]); ok && float64(len(x)) > n {
+			fail("expected at most %v items, got %d", n, len(x))
+		}
+		if items, ok := m["items"]; ok {
+			for i, item := range x {
+				validate(fmt.Sprintf("%s/%d", at, i), items, item, errs)
+			}
+		}
+	case string:
+		length := float64(len([]rune(x)))
+		if n, ok := number(m["minLength"]); ok && length < n {
+			fail("expected at least %v characters", n)
+		}
+		if n, ok := number(m["maxLength"]); ok && length > n {
+			fail("expected at most %v characters", n)
+		}
+		if p, ok := m["pattern"].(string); ok {
+			if re, err := regexp.Compile(p); err == nil && !re.MatchString(x) {
+				fail("value does not match pattern %q", p)
+			}
+		}
+	case float64:
+		if n, ok := number(m["minimum"]); ok && x < n {
+			fail("value %v is less than minimum %v", x, n)
+		}
+		if n, ok := number(m["maximum"]); ok && x > n {
+			fail("value %v is greater than maximum %v", x, n)
+		}
+		if n, ok := number(m["exclusiveMinimum"]); ok && x <= n {
+			fail("value %v must be greater than %v", x, n)
+		}
+		if n, ok := number(m["exclusiveMaximum"]); ok && x >= n {
+			fail("value %v must be less than %v", x, n)
+		}
+	}
+
+	if all, ok := m["allOf"].([]any); ok {
+		for _, sub := range all {
+			validate(at, sub, v, errs)
+		}
+	}
+	if anyOf, ok := m["anyOf"].([]any); ok && matching(anyOf, v) == 0 {
+		fail("value matches none of anyOf")
+	}
+	if one, ok := m["oneOf"].([]any); ok {
+		if n := matching(one, v); n != 1 {
+			fail("value must match exactly one of oneOf, matched %d", n)
+		}
+	}
+	if not, ok := m["not"]; ok {
+		var sub []string
+		validate(at, not, v, &sub)
+		if len(sub) == 0 {
+			fail("value must not match the schema in not")
+		}
+	}
+}
+
+// matching 返回 v 满足的子 schema 个数
+func matching(schemas []any, v any) int {
+	n := 0
+	for _, sub := range schemas {
+		var errs []string
+		validate("", sub, v, &errs)
+		if len(errs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func typeNames(t any) []string {
+	switch x := t.(type) {
+	case string:
+		return []string{x}
+	case []any:
+		var out []string
+		for _, e := range x {
+			s, ok := e.(string)
+			if !ok {
+				return nil
+			}
+			out = append(out, s)
+		}
+		return out
+	}
+	return nil
+}
+
+func hasType(v any, name string) bool {
+	switch name {
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	}
+	return typeOf(v) == name
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// number 把 YAML/JSON 解码得到的各种数值统一为 float64
+func number(v any) (float64, bool) {
+	switch x := v.(type) {
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+// equal 按 JSON 语义比较 schema 中的字面量与值（YAML 的 int 与 JSON 的 float64 视为相等）
+func equal(a, b any) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			if yv, ok := y[k]; !ok || !equal(xv, yv) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func compact(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pointer 返回 JSON Pointer 形式的位置；根为 "/"
+func pointer(at string) string {
+	if at == "" {
+		return "/"
+	}
+	return at
+}
+
+func escape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,66 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidate(t *testing.T) {
+	var schema map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(`
+type: object
+required: [total, orders]
+additionalProperties: false
+properties:
+  total: { type: integer, minimum: 0 }
+  status: { enum: [ok, partial] }
+  orders:
+    type: array
+    minItems: 1
+    items:
+      type: object
+      required: [id]
+      properties:
+        id: { type: string, pattern: "^o-" }
+`), &schema))
+	require.NoError(t, Check(schema))
+
+	problems, err := Validate(schema, map[string]any{
+		"total":  3,
+		"status": "ok",
+		"orders": []map[string]any{{"id": "o-1"}},
+	})
+	require.NoError(t, err)
+	require.Empty(t, problems)
+
+	problems, err = Validate(schema, map[string]any{
+		"total":  1.5,
+		"status": "failed",
+		"orders": []any{map[string]any{"id": "x-1"}, map[string]any{}},
+		"debug":  true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`/: unexpected property "debug"`,
+		`/orders/0/id: value does not match pattern "^o-"`,
+		`/orders/1: missing required property "id"`,
+		`/status: value must be one of ["ok","partial"]`,
+		`/total: expected integer, got number`,
+	}, problems)
+}
+
+func TestCheck(t *testing.T) {
+	for schema, want := range map[string]string{
+		`{ type: map }`:                          "/: unknown type map",
+		`{ properties: { a: { $ref: "#/x" } } }`: "/properties/a: $ref is not supported",
+		`{ anyOf: [] }`:                          "/: anyOf must be a non-empty array",
+		`{ items: { pattern: "(" } }`:            "/items: pattern: error parsing regexp: missing closing ): `(`",
+		`{ type: [string, 1] }`:                  "/: type must be a string or an array of strings",
+	} {
+		var m map[string]any
+		require.NoError(t, yaml.Unmarshal([]byte(schema), &m))
+		require.EqualError(t, Check(m), want, schema)
+	}
+}
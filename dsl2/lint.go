@@ -0,0 +1,203 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Lint 规则 ID；前五条由 Check 产生，其余只在 Lint 中检查
+const (
+	RuleUnknownRef        = "unknown-ref"
+	RuleUnusedVariable    = "unused-variable"
+	RuleUnknownActivity   = "unknown-activity"
+	RuleUnboundedWhile    = "unbounded-while"
+	RuleParallelConflict  = "parallel-conflict"
+	RuleUnreachableBranch = "unreachable-branch"
+	RuleMissingTimeout    = "missing-timeout"
+	RuleOversizedLiteral  = "oversized-literal"
+)
+
+// SeverityOff 在 LintOptions.Severity 中表示关闭规则
+const SeverityOff = "off"
+
+// DefaultMaxLiteralBytes 是 oversized-literal 的默认阈值；字面量会原样写入工作流输入与历史
+const DefaultMaxLiteralBytes = 4096
+
+// LintRule 描述一条规则及其默认级别
+type LintRule struct {
+	ID          string `json:"id"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// LintRules 列出全部规则
+var LintRules = []LintRule{
+	{RuleUnknownRef, SeverityWarning, "a ref names a variable that is never declared or written"},
+	{RuleUnusedVariable, SeverityWarning, "a declared variable is never referenced"},
+	{RuleUnknownActivity, SeverityWarning, "an activity is not registered by the worker"},
+	{RuleUnboundedWhile, SeverityWarning, "a while loop has no maxIters and may grow history without bound"},
+	{RuleParallelConflict, SeverityWarning, "several parallel branches write the same variable"},
+	{RuleUnreachableBranch, SeverityWarning, "a condition is constant, so a branch or loop body never runs"},
+	{RuleMissingTimeout, SeverityWarning, "an activity relies on the 30s default timeout (no opts or workflow timeoutSec)"},
+	{RuleOversizedLiteral, SeverityWarning, "a literal argument or variable is larger than maxLiteralBytes"},
+}
+
+// LintOptions 控制 Lint
+type LintOptions struct {
+	KnownActivities []string
+	// Severity 按规则 ID 覆盖级别：error / warning / off
+	Severity map[string]string
+	// MaxLiteralBytes 为 0 时使用 DefaultMaxLiteralBytes
+	MaxLiteralBytes int
+}
+
+// Validate 检查 Severity 中的规则 ID 与级别是否有效
+func (o LintOptions) Validate() error {
+	ids := make([]string, 0, len(o.Severity))
+	for id := range o.Severity {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if defaultSeverity(id) == "" {
+			return fmt.Errorf("unknown lint rule %q", id)
+		}
+		switch o.Severity[id] {
+		case SeverityError, SeverityWarning, SeverityOff:
+		default:
+			return fmt.Errorf("rule %s: unsupported severity %q (want error, warning or off)", id, o.Severity[id])
+		}
+	}
+	return nil
+}
+
+func defaultSeverity(rule string) string {
+	for _, r := range LintRules {
+		if r.ID == rule {
+			return r.Severity
+		}
+	}
+	return ""
+}
+
+// Lint 在 Check 的基础上运行额外规则，并按 opts.Severity 调整或关闭带规则 ID 的问题
+func (wf Workflow) Lint(opts LintOptions) []Issue {
+	l := &linter{max: opts.MaxLiteralBytes}
+	if l.max <= 0 {
+		l.max = DefaultMaxLiteralBytes
+	}
+	l.issues = wf.Check(CheckOptions{KnownActivities: opts.KnownActivities})
+
+	names := make([]string, 0, len(wf.Variables))
+	for k := range wf.Variables {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		if bs, err := json.Marshal(wf.Variables[k]); err == nil && len(bs) > l.max {
+			l.add(RuleOversizedLiteral, "", "variable %q is %d bytes (limit %d); pass large data by reference", k, len(bs), l.max)
+		}
+	}
+	l.wfTimeout = wf.TimeoutSec > 0
+	for i, st := range wf.Root {
+		l.stmt(rootPath(i), st)
+	}
+	for i, st := range wf.OnCancel {
+		l.stmt(onCancelPath(i), st)
+	}
+	for _, name := range wf.EntryNames() {
+		for i, st := range wf.Entrypoints[name] {
+			l.stmt(entryPath(name, i), st)
+		}
+	}
+
+	out := make([]Issue, 0, len(l.issues))
+	for _, i := range l.issues {
+		if i.Rule != "" {
+			if sev, ok := opts.Severity[i.Rule]; ok {
+				if sev == SeverityOff {
+					continue
+				}
+				i.Severity = sev
+			}
+		}
+		out = append(out, i)
+	}
+	return out
+}
+
+type linter struct {
+	issues    []Issue
+	max       int
+	wfTimeout bool
+}
+
+func (l *linter) add(rule, path, format string, args ...any) {
+	l.issues = append(l.issues, Issue{Severity: defaultSeverity(rule), Path: path, Message: fmt.Sprintf(format, args...), Rule: rule})
+}
+
+func (l *linter) stmt(path string, s *Statement) {
+	if s == nil {
+		return
+	}
+	switch {
+	case s.Activity != nil:
+		a := s.Activity
+		if !l.wfTimeout && (a.Opts == nil || a.Opts.StartToCloseSeconds <= 0 && a.Opts.ScheduleToCloseSeconds <= 0) {
+			l.add(RuleMissingTimeout, path, "activity %q has no timeout; set opts.startToCloseSeconds or workflow timeoutSec", a.Name)
+		}
+		for i, v := range a.Args {
+			if v.Str != nil && len(*v.Str) > l.max {
+				l.add(RuleOversizedLiteral, path, "arg[%d] is %d bytes (limit %d)", i, len(*v.Str), l.max)
+			}
+		}
+	case s.If != nil:
+		if ok, constant := constCond(s.If.Cond); constant {
+			if ok && s.If.Else != nil {
+				l.add(RuleUnreachableBranch, path, "condition is always true; else branch never runs")
+			} else if !ok {
+				l.add(RuleUnreachableBranch, path, "condition is always false; then branch never runs")
+			}
+		}
+	case s.While != nil:
+		if ok, constant := constCond(s.While.Cond); constant && !ok {
+			l.add(RuleUnreachableBranch, path, "condition is always false; loop body never runs")
+		}
+	}
+	for _, ch := range s.children() {
+		l.stmt(path+"."+ch.rel, ch.stmt)
+	}
+}
+
+// constCond 在条件不引用任何变量时求值；constant 为 false 表示结果取决于运行时变量
+func constCond(c Cond) (result, constant bool) {
+	if condHasRef(c) {
+		return false, false
+	}
+	ok, err := evalCond(c, nil)
+	if err != nil {
+		return false, false // 空条件等由 Check 报告
+	}
+	return ok, true
+}
+
+func condHasRef(c Cond) bool {
+	if c.Not != nil && condHasRef(*c.Not) {
+		return true
+	}
+	for _, sub := range append(append([]Cond{}, c.All...), c.Any...) {
+		if condHasRef(sub) {
+			return true
+		}
+	}
+	if c.Truthy != nil && c.Truthy.Ref != "" {
+		return true
+	}
+	for _, cmp := range []*Compare{c.Eq, c.Ne} {
+		if cmp != nil && (cmp.Left.Ref != "" || cmp.Right.Ref != "") {
+			return true
+		}
+	}
+	return false
+}
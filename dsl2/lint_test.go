@@ -0,0 +1,68 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLintRules(t *testing.T) {
+	var wf Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(`
+variables:
+  blob: "`+strings.Repeat("x", 20)+`"
+root:
+  - if:
+      cond: { eq: { left: { int: 1 }, right: { int: 2 } } }
+      then:
+        activity: { name: A, args: [{ ref: blob }], opts: { startToCloseSeconds: 5 } }
+  - if:
+      cond: { truthy: { bool: true } }
+      then:
+        activity: { name: B, args: [{ str: "`+strings.Repeat("y", 20)+`" }] }
+      else:
+        activity: { name: C, opts: { startToCloseSeconds: 5 } }
+  - while:
+      cond: { truthy: { ref: missing } }
+      maxIters: 3
+      body:
+        activity: { name: D, opts: { startToCloseSeconds: 5 } }
+`), &wf))
+
+	lint := func(opts LintOptions) []string {
+		var got []string
+		for _, i := range wf.Lint(opts) {
+			got = append(got, i.Severity+" "+i.Rule+" "+i.String())
+		}
+		return got
+	}
+	require.ElementsMatch(t, []string{
+		`warning oversized-literal variable "blob" is 22 bytes (limit 10); pass large data by reference`,
+		"warning unreachable-branch root[0]: condition is always false; then branch never runs",
+		"warning unreachable-branch root[1]: condition is always true; else branch never runs",
+		`warning missing-timeout root[1].if.then: activity "B" has no timeout; set opts.startToCloseSeconds or workflow timeoutSec`,
+		"warning oversized-literal root[1].if.then: arg[0] is 20 bytes (limit 10)",
+		`warning unknown-ref root[2]: variable "missing" is never defined`,
+	}, lint(LintOptions{MaxLiteralBytes: 10}))
+
+	got := lint(LintOptions{Severity: map[string]string{
+		RuleUnknownRef:        SeverityError,
+		RuleOversizedLiteral:  SeverityOff,
+		RuleUnreachableBranch: SeverityOff,
+		RuleMissingTimeout:    SeverityOff,
+	}})
+	require.Equal(t, []string{`error unknown-ref root[2]: variable "missing" is never defined`}, got)
+
+	wf.TimeoutSec = 10
+	for _, s := range lint(LintOptions{}) {
+		require.NotContains(t, s, RuleMissingTimeout)
+	}
+}
+
+func TestLintOptionsValidate(t *testing.T) {
+	require.NoError(t, LintOptions{Severity: map[string]string{RuleMissingTimeout: SeverityOff}}.Validate())
+	require.EqualError(t, LintOptions{Severity: map[string]string{"nope": SeverityOff}}.Validate(), `unknown lint rule "nope"`)
+	require.Error(t, LintOptions{Severity: map[string]string{RuleMissingTimeout: "info"}}.Validate())
+}
@@ -0,0 +1,120 @@
+package dsl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion 是当前定义格式的版本；未写 version 的定义按当前版本解析
+const SchemaVersion = "1.0"
+
+// migration 把 from 版本的定义就地改写为 to 版本，返回每处改写的说明（路径: 说明）
+type migration struct {
+	from, to string
+	apply    func(doc *yaml.Node) ([]string, error)
+}
+
+// migrations 按版本顺序排列；格式不兼容地变化时在此追加一步并提升 SchemaVersion。
+// 原 dsl 示例的格式不是旧版本，由 convert/dslv1 转换
+var migrations = []migration{}
+
+// checkVersion 拒绝旧版本与未知版本的定义，避免按当前格式误读
+func checkVersion(v string) error {
+	if v == "" || v == SchemaVersion {
+		return nil
+	}
+	if slices.ContainsFunc(migrations, func(m migration) bool { return m.from == v }) {
+		return fmt.Errorf("schema version %q is outdated; migrate the definition to %s first", v, SchemaVersion)
+	}
+	return fmt.Errorf("unsupported schema version %q (this build reads %s)", v, SchemaVersion)
+}
+
+// Migrate 把旧版本的 YAML 定义逐步升级到 SchemaVersion，保留注释与字段顺序；notes 说明每处改写。
+// from 用于没有写 version 的旧文件，为空时按文件中的 version；已是当前版本时原样返回
+func Migrate(b []byte, from string) ([]byte, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, errors.New("definition must be a YAML mapping")
+	}
+	top := doc.Content[0]
+	vn := mapValue(top, "version")
+	if from == "" && vn != nil {
+		from = vn.Value
+	}
+	if from == "" || from == SchemaVersion {
+		return b, nil, nil
+	}
+
+	var notes []string
+	for from != SchemaVersion {
+		i := slices.IndexFunc(migrations, func(m migration) bool { return m.from == from })
+		if i < 0 {
+			return nil, nil, fmt.Errorf("unsupported schema version %q (%s)", from, migrationSources())
+		}
+		m := migrations[i]
+		n, err := m.apply(top)
+		if err != nil {
+			return nil, nil, fmt.Errorf("migrate %s to %s: %w", m.from, m.to, err)
+		}
+		notes = append(notes, n...)
+		from = m.to
+	}
+	if vn == nil {
+		vn = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+		top.Content = append([]*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"}, vn}, top.Content...)
+	}
+	vn.Value, vn.Tag, vn.Style = SchemaVersion, "!!str", yaml.DoubleQuotedStyle
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, err
+	}
+	// 改写结果必须能按当前格式解析
+	if _, err := ParseYAML(buf.Bytes()); err != nil {
+		return nil, notes, fmt.Errorf("migrated definition does not parse: %w", err)
+	}
+	return buf.Bytes(), notes, nil
+}
+
+func migrationSources() string {
+	if len(migrations) == 0 {
+		return "this build has no migrations"
+	}
+	from := make([]string, 0, len(migrations))
+	for _, m := range migrations {
+		from = append(from, m.from)
+	}
+	return "this build migrates from " + strings.Join(from, ", ")
+}
+
+func mapIndex(n *yaml.Node, key string) int {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func mapValue(n *yaml.Node, key string) *yaml.Node {
+	if i := mapIndex(n, key); i >= 0 {
+		return n.Content[i+1]
+	}
+	return nil
+}
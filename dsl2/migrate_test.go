@@ -0,0 +1,44 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	const current = `version: "1.0"
+# 注释原样保留
+root: [{ noop: {} }]
+`
+	// 当前版本与未写 version 的定义原样返回
+	for _, def := range []string{current, "root: [{ noop: {} }]\n"} {
+		out, notes, err := Migrate([]byte(def), "")
+		require.NoError(t, err)
+		require.Equal(t, def, string(out))
+		require.Empty(t, notes)
+	}
+
+	_, _, err := Migrate([]byte("version: \"0.9\"\nroot: []\n"), "")
+	require.EqualError(t, err, `unsupported schema version "0.9" (this build has no migrations)`)
+	// 没有写 version 的文件由调用方指定版本
+	_, _, err = Migrate([]byte("root: []\n"), "0.5")
+	require.EqualError(t, err, `unsupported schema version "0.5" (this build has no migrations)`)
+	_, _, err = Migrate([]byte("- noop: {}\n"), "")
+	require.EqualError(t, err, "definition must be a YAML mapping")
+}
+
+func TestCheckVersion(t *testing.T) {
+	_, err := ParseYAML([]byte("version: 2.0\nroot: [{ noop: {} }]\n"))
+	require.EqualError(t, err, `unsupported schema version "2.0" (this build reads 1.0)`)
+	wf, err := ParseYAML([]byte("version: 1.0\nroot: [{ noop: {} }]\n"))
+	require.NoError(t, err)
+	require.Equal(t, "1.0", wf.Version)
+	wf, err = ParseYAML([]byte("root: [{ noop: {} }]\n"))
+	require.NoError(t, err)
+	require.NoError(t, wf.Validate())
+
+	// 不经 YAML 构造的定义（JSON、API）由 Check 拒绝
+	wf.Version = "0.9"
+	require.EqualError(t, wf.Validate(), `unsupported schema version "0.9" (this build reads 1.0)`)
+}
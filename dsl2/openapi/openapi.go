@@ -0,0 +1,512 @@
+// Package openapi 从 OpenAPI 3 文档为每个操作生成一个按 operationId 命名的 Activity，
+// DSL 作者即可直接调用文档化的 API 而无需拼装 HTTPRequest 的参数。
+// 每个 Activity 接收一个对象参数：路径/查询/头部参数按名字给出，请求体放在 body；
+// 调用前按文档中的 schema 校验参数（见 internal/jsonschema 支持的子集），请求由内置的 HTTPRequest 发送
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"github.com/temporalio/samples-go/dsl2/internal/jsonschema"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"gopkg.in/yaml.v3"
+)
+
+// BodyArg 是参数对象中请求体的键
+const BodyArg = "body"
+
+// Config 是 worker 配置文件中声明的一份 OpenAPI 文档：
+//
+//	openapi:
+//	  - spec: /specs/petstore.yaml
+//	    prefix: petstore.              # Activity 名前缀，避免与其他文档重名
+//	    baseUrl: https://petstore.internal/v1
+//	    headerEnv: { Authorization: PETSTORE_AUTH }
+type Config struct {
+	Spec    string `yaml:"spec"`
+	Prefix  string `yaml:"prefix,omitempty"`
+	BaseURL string `yaml:"baseUrl,omitempty"` // 默认取文档的 servers[0].url
+	// Headers 随每个请求发送；HeaderEnv 的值是环境变量名，适合放凭据。二者都只在 worker 内使用，不进入历史
+	Headers    map[string]string `yaml:"headers,omitempty"`
+	HeaderEnv  map[string]string `yaml:"headerEnv,omitempty"`
+	TimeoutSec int               `yaml:"timeoutSec,omitempty"` // 单次请求超时，默认使用 Activity 的超时
+}
+
+// Param 是一个路径/查询/头部参数
+type Param struct {
+	Name     string `json:"name"`
+	In       string `json:"in"` // path/query/header
+	Required bool   `json:"required,omitempty"`
+	Schema   any    `json:"schema,omitempty"`
+}
+
+// Operation 是文档中的一个操作及其生成的 Activity
+type Operation struct {
+	Name        string  `json:"name"` // Activity 名：Prefix + operationId
+	OperationID string  `json:"operationId"`
+	Method      string  `json:"method"`
+	Path        string  `json:"path"`
+	Summary     string  `json:"summary,omitempty"`
+	Params      []Param `json:"params,omitempty"`
+	// Body 是 application/json 请求体的 schema；没有请求体时为 nil
+	Body         any  `json:"body,omitempty"`
+	BodyRequired bool `json:"bodyRequired,omitempty"`
+	// Args 是参数对象的完整 schema（各参数加 body），调用前据此校验
+	Args map[string]any `json:"args"`
+
+	baseURL    string
+	headers    map[string]string
+	timeoutSec int
+}
+
+// Load 读取 cfg.Spec 并生成操作；HeaderEnv 引用的环境变量未设置时报错
+func Load(cfg Config) ([]Operation, error) {
+	b, err := os.ReadFile(cfg.Spec)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{}
+	for k, v := range cfg.Headers {
+		headers[k] = v
+	}
+	for k, env := range cfg.HeaderEnv {
+		v := os.Getenv(env)
+		if v == "" {
+			return nil, fmt.Errorf("header %s: environment variable %s is not set", k, env)
+		}
+		headers[k] = v
+	}
+	cfg.Headers, cfg.HeaderEnv = headers, nil
+	ops, err := Operations(b, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.Spec, err)
+	}
+	return ops, nil
+}
+
+// Operations 解析 OpenAPI 3 文档（YAML 或 JSON），按 Name 排序返回全部操作；
+// 没有 operationId 的操作按方法与路径生成名字，如 GET /pets/{id} -> getPetsId
+func Operations(data []byte, cfg Config) ([]Operation, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse document: %w", err)
+	}
+	if v, _ := doc["openapi"].(string); !strings.HasPrefix(v, "3.") {
+		return nil, fmt.Errorf("unsupported document version %q (want openapi 3.x)", v)
+	}
+	r := &resolver{doc: doc}
+	base := cfg.BaseURL
+	if base == "" {
+		if servers, _ := doc["servers"].([]any); len(servers) > 0 {
+			s, _ := servers[0].(map[string]any)
+			base, _ = s["url"].(string)
+		}
+	}
+	if base == "" {
+		return nil, errors.New("no servers in the document; set baseUrl")
+	}
+
+	paths, _ := doc["paths"].(map[string]any)
+	var ops []Operation
+	seen := map[string]string{}
+	for _, path := range sortedKeys(paths) {
+		item, _ := r.resolve(paths[path]).(map[string]any)
+		for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+			raw, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			op, err := r.operation(method, path, item, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			op.Name = cfg.Prefix + op.OperationID
+			if other, dup := seen[op.Name]; dup {
+				return nil, fmt.Errorf("%s %s: activity name %q is also used by %s", strings.ToUpper(method), path, op.Name, other)
+			}
+			seen[op.Name] = op.Method + " " + op.Path
+			op.baseURL, op.headers, op.timeoutSec = strings.TrimSuffix(base, "/"), cfg.Headers, cfg.TimeoutSec
+			ops = append(ops, op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Name < ops[j].Name })
+	return ops, nil
+}
+
+// operation 汇总一个操作的参数（路径级参数可被操作级同名参数覆盖）与请求体，生成参数 schema
+func (r *resolver) operation(method, path string, item, raw map[string]any) (Operation, error) {
+	op := Operation{Method: strings.ToUpper(method), Path: path}
+	op.OperationID, _ = raw["operationId"].(string)
+	if op.OperationID == "" {
+		op.OperationID = derivedID(method, path)
+	}
+	op.Summary, _ = raw["summary"].(string)
+
+	var params []Param
+	index := map[string]int{}
+	for _, list := range []any{item["parameters"], raw["parameters"]} {
+		items, _ := list.([]any)
+		for _, p := range items {
+			pm, _ := r.resolve(p).(map[string]any)
+			param := Param{}
+			param.Name, _ = pm["name"].(string)
+			param.In, _ = pm["in"].(string)
+			param.Required, _ = pm["required"].(bool)
+			switch param.In {
+			case "path":
+				param.Required = true
+			case "query", "header":
+			default:
+				return op, fmt.Errorf("parameter %q: %q parameters are not supported", param.Name, param.In)
+			}
+			if param.Name == "" || param.Name == BodyArg {
+				return op, fmt.Errorf("parameter name %q is not allowed", param.Name)
+			}
+			schema, err := r.schema(pm["schema"])
+			if err != nil {
+				return op, fmt.Errorf("parameter %q: %w", param.Name, err)
+			}
+			param.Schema = schema
+			key := param.In + ":" + param.Name
+			if i, ok := index[key]; ok {
+				params[i] = param
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, param)
+		}
+	}
+
+	props := map[string]any{}
+	required := []any{}
+	for _, p := range params {
+		if _, dup := props[p.Name]; dup {
+			return op, fmt.Errorf("parameter %q appears in more than one location", p.Name)
+		}
+		props[p.Name] = p.Schema
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	if body, ok := r.resolve(raw["requestBody"]).(map[string]any); ok {
+		content, _ := body["content"].(map[string]any)
+		media, ok := content["application/json"].(map[string]any)
+		if !ok {
+			return op, errors.New("only application/json request bodies are supported")
+		}
+		schema, err := r.schema(media["schema"])
+		if err != nil {
+			return op, fmt.Errorf("request body: %w", err)
+		}
+		op.Body = schema
+		op.BodyRequired, _ = body["required"].(bool)
+		props[BodyArg] = schema
+		if op.BodyRequired {
+			required = append(required, BodyArg)
+		}
+	}
+	op.Params = params
+	op.Args = map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	if len(required) > 0 {
+		op.Args["required"] = required
+	}
+	if err := jsonschema.Check(op.Args); err != nil {
+		return op, err
+	}
+	return op, nil
+}
+
+// derivedID 由方法与路径生成名字，路径参数的花括号去掉
+func derivedID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(method)
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// Request 校验参数并生成 HTTPRequest 的入参
+func (op Operation) Request(args map[string]any) (dsl.HTTPRequestInput, error) {
+	if args == nil {
+		args = map[string]any{}
+	}
+	problems, err := jsonschema.Validate(op.Args, args)
+	if err != nil {
+		return dsl.HTTPRequestInput{}, err
+	}
+	if len(problems) > 0 {
+		return dsl.HTTPRequestInput{}, fmt.Errorf("invalid arguments for %s: %s", op.Name, strings.Join(problems, "; "))
+	}
+
+	path := op.Path
+	query := url.Values{}
+	in := dsl.HTTPRequestInput{Method: op.Method, Headers: map[string]string{}, TimeoutSec: op.timeoutSec}
+	for k, v := range op.headers {
+		in.Headers[k] = v
+	}
+	for _, p := range op.Params {
+		v, ok := args[p.Name]
+		if !ok || v == nil {
+			continue
+		}
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(paramString(v)))
+		case "query":
+			// 数组按 OpenAPI 默认的 form/explode 方式重复参数
+			if list, ok := v.([]any); ok {
+				for _, x := range list {
+					query.Add(p.Name, paramString(x))
+				}
+			} else {
+				query.Set(p.Name, paramString(v))
+			}
+		case "header":
+			in.Headers[p.Name] = paramString(v)
+		}
+	}
+	in.URL = op.baseURL + path
+	if len(query) > 0 {
+		in.URL += "?" + query.Encode()
+	}
+	if body, ok := args[BodyArg]; ok {
+		in.Body = body
+	}
+	return in, nil
+}
+
+func paramString(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case []any:
+		parts := make([]string, len(x))
+		for i, e := range x {
+			parts[i] = paramString(e)
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// Registry 是 worker.ActivityRegistry 中 Register 用到的部分
+type Registry interface {
+	RegisterActivityWithOptions(a any, options activity.RegisterOptions)
+}
+
+// Register 把每个操作注册为 Activity，请求由 acts.HTTPRequest 发送；
+// 与 reserved（内置或插件 Activity）重名时报错，避免 SDK 在重复注册时 panic
+func Register(r Registry, ops []Operation, acts *dsl.Activities, reserved []string) error {
+	taken := map[string]bool{}
+	for _, name := range reserved {
+		taken[name] = true
+	}
+	for _, op := range ops {
+		if taken[op.Name] {
+			return fmt.Errorf("activity %q is already registered", op.Name)
+		}
+		taken[op.Name] = true
+	}
+	for _, op := range ops {
+		fn := func(ctx context.Context, args map[string]any) (dsl.HTTPResponse, error) {
+			in, err := op.Request(args)
+			if err != nil {
+				return dsl.HTTPResponse{}, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidArguments", err)
+			}
+			return acts.HTTPRequest(ctx, in)
+		}
+		r.RegisterActivityWithOptions(fn, activity.RegisterOptions{Name: op.Name})
+	}
+	return nil
+}
+
+// resolver 展开文档内的 $ref（#/components/...），并把 OpenAPI 3.0 特有的 schema 写法转换为 JSON Schema
+type resolver struct {
+	doc map[string]any
+}
+
+// resolve 展开一层（可能是链式的）引用
+func (r *resolver) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return v
+		}
+		v = r.lookup(ref)
+	}
+	return nil
+}
+
+func (r *resolver) lookup(ref string) any {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var cur any = r.doc
+	for _, tok := range strings.Split(ref[2:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[tok]
+	}
+	return cur
+}
+
+// schema 返回展开引用后的 schema；递归引用在第二次出现处以 true（接受任意值）截断
+func (r *resolver) schema(v any) (any, error) {
+	if v == nil {
+		return true, nil
+	}
+	return r.convert(v, map[string]bool{})
+}
+
+func (r *resolver) convert(v any, active map[string]bool) (any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v, nil
+	}
+	if ref, ok := m["$ref"].(string); ok {
+		if active[ref] {
+			return true, nil
+		}
+		target := r.lookup(ref)
+		if target == nil {
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+		active[ref] = true
+		defer delete(active, ref)
+		return r.convert(target, active)
+	}
+
+	out := make(map[string]any, len(m))
+	for k, x := range m {
+		out[k] = x
+	}
+	var err error
+	sub := func(x any) any {
+		if err != nil {
+			return nil
+		}
+		var s any
+		s, err = r.convert(x, active)
+		return s
+	}
+	if props, ok := m["properties"].(map[string]any); ok {
+		conv := make(map[string]any, len(props))
+		for name, p := range props {
+			conv[name] = sub(p)
+		}
+		out["properties"] = conv
+	}
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		if x, ok := m[key]; ok {
+			out[key] = sub(x)
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if list, ok := m[key].([]any); ok {
+			conv := make([]any, len(list))
+			for i, x := range list {
+				conv[i] = sub(x)
+			}
+			out[key] = conv
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// OpenAPI 3.0：nullable 对应类型中的 null；布尔形式的 exclusiveMinimum/Maximum 修饰 minimum/maximum
+	if nullable, _ := m["nullable"].(bool); nullable {
+		if t, ok := m["type"].(string); ok {
+			out["type"] = []any{t, "null"}
+		}
+	}
+	delete(out, "nullable")
+	for _, pair := range [][2]string{{"exclusiveMinimum", "minimum"}, {"exclusiveMaximum", "maximum"}} {
+		if excl, ok := m[pair[0]].(bool); ok {
+			delete(out, pair[0])
+			if bound, ok := m[pair[1]]; ok && excl {
+				out[pair[0]] = bound
+				delete(out, pair[1])
+			}
+		}
+	}
+	return out, nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Example 返回一个满足 Args 的参数对象骨架：只含必填项，取 schema 中的 example/default/enum 或类型的零值，
+// 用于生成 DSL 片段
+func (op Operation) Example() map[string]any {
+	out, _ := exampleValue(op.Args, 0).(map[string]any)
+	return out
+}
+
+func exampleValue(schema any, depth int) any {
+	m, ok := schema.(map[string]any)
+	if !ok || depth > 8 {
+		return nil
+	}
+	for _, key := range []string{"example", "default"} {
+		if v, ok := m[key]; ok {
+			return v
+		}
+	}
+	if enum, ok := m["enum"].([]any); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	t := m["type"]
+	if list, ok := t.([]any); ok && len(list) > 0 {
+		t = list[0]
+	}
+	switch t {
+	case "object":
+		out := map[string]any{}
+		props, _ := m["properties"].(map[string]any)
+		required, _ := m["required"].([]any)
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				out[s] = exampleValue(props[s], depth+1)
+			}
+		}
+		return out
+	case "array":
+		return []any{exampleValue(m["items"], depth+1)}
+	case "string":
+		return ""
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	if all, ok := m["allOf"].([]any); ok && len(all) > 0 {
+		return exampleValue(all[0], depth+1)
+	}
+	return nil
+}
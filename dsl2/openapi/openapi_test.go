@@ -0,0 +1,222 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"go.temporal.io/sdk/activity"
+)
+
+const petstore = `
+openapi: 3.0.3
+servers:
+  - url: https://petstore.example.com/v1/
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - { name: tag, in: query, schema: { type: array, items: { type: string } } }
+        - { $ref: "#/components/parameters/Limit" }
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: { $ref: "#/components/schemas/Pet" }
+  /pets/{petId}:
+    parameters:
+      - { name: petId, in: path, schema: { type: integer } }
+    get:
+      summary: Info for a pet
+      parameters:
+        - { name: X-Request-Id, in: header, schema: { type: string } }
+    delete:
+      operationId: deletePet
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema: { type: integer, minimum: 0, exclusiveMinimum: true, maximum: 100 }
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: { type: string }
+        tag: { type: string, nullable: true }
+        parent: { $ref: "#/components/schemas/Pet" }
+`
+
+func TestOperations(t *testing.T) {
+	ops, err := Operations([]byte(petstore), Config{Prefix: "pets."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, op := range ops {
+		names = append(names, op.Name+" "+op.Method+" "+op.Path)
+	}
+	want := []string{
+		"pets.createPet POST /pets",
+		"pets.deletePet DELETE /pets/{petId}",
+		"pets.getPetsPetId GET /pets/{petId}",
+		"pets.listPets GET /pets",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("operations = %v, want %v", names, want)
+	}
+
+	list := ops[3]
+	limit := list.Args["properties"].(map[string]any)["limit"]
+	if want := map[string]any{"type": "integer", "exclusiveMinimum": 0, "maximum": 100}; !reflect.DeepEqual(limit, want) {
+		t.Errorf("limit schema = %v, want %v", limit, want)
+	}
+	create := ops[0]
+	if !create.BodyRequired || !reflect.DeepEqual(create.Args["required"], []any{BodyArg}) {
+		t.Errorf("createPet body required = %v, args required = %v", create.BodyRequired, create.Args["required"])
+	}
+	pet := create.Body.(map[string]any)["properties"].(map[string]any)
+	if !reflect.DeepEqual(pet["tag"], map[string]any{"type": []any{"string", "null"}}) {
+		t.Errorf("nullable tag = %v", pet["tag"])
+	}
+	if pet["parent"] != true {
+		t.Errorf("recursive $ref not cut: %v", pet["parent"])
+	}
+
+	if got, want := create.Example(), map[string]any{"body": map[string]any{"name": ""}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("createPet example = %v, want %v", got, want)
+	}
+	if got, want := ops[1].Example(), map[string]any{"petId": 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deletePet example = %v, want %v", got, want)
+	}
+}
+
+func TestOperationsErrors(t *testing.T) {
+	for name, doc := range map[string]string{
+		"swagger 2":  "swagger: '2.0'\npaths: {}",
+		"no servers": "openapi: 3.0.0\npaths: {}",
+		"form body": `openapi: 3.0.0
+servers: [{url: http://x}]
+paths:
+  /a:
+    post:
+      requestBody: {content: {application/x-www-form-urlencoded: {schema: {type: object}}}}`,
+		"cookie": `openapi: 3.0.0
+servers: [{url: http://x}]
+paths:
+  /a:
+    get:
+      parameters: [{name: s, in: cookie}]`,
+		"duplicate name": `openapi: 3.0.0
+servers: [{url: http://x}]
+paths:
+  /a: {get: {operationId: op}}
+  /b: {get: {operationId: op}}`,
+	} {
+		if _, err := Operations([]byte(doc), Config{}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRequest(t *testing.T) {
+	ops, err := Operations([]byte(petstore), Config{BaseURL: "http://api.local", Headers: map[string]string{"Authorization": "Bearer t"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]Operation{}
+	for _, op := range ops {
+		byName[op.Name] = op
+	}
+
+	in, err := byName["listPets"].Request(map[string]any{"tag": []any{"a b", "c"}, "limit": 10.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.Method != "GET" || in.URL != "http://api.local/pets?limit=10&tag=a+b&tag=c" || in.Headers["Authorization"] != "Bearer t" {
+		t.Errorf("listPets request = %+v", in)
+	}
+
+	in, err = byName["getPetsPetId"].Request(map[string]any{"petId": 7, "X-Request-Id": "r1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.URL != "http://api.local/pets/7" || in.Headers["X-Request-Id"] != "r1" {
+		t.Errorf("getPetsPetId request = %+v", in)
+	}
+
+	for _, tc := range []struct {
+		op   string
+		args map[string]any
+		want string
+	}{
+		{"getPetsPetId", map[string]any{"petId": "seven"}, "/petId: expected integer"},
+		{"getPetsPetId", nil, "petId"},
+		{"listPets", map[string]any{"limit": 0}, "/limit"},
+		{"listPets", map[string]any{"limt": 5}, "limt"},
+		{"createPet", map[string]any{"body": map[string]any{"tag": "x"}}, "name"},
+	} {
+		_, err := byName[tc.op].Request(tc.args)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s(%v): error = %v, want it to mention %q", tc.op, tc.args, err, tc.want)
+		}
+	}
+}
+
+type registry map[string]any
+
+func (r registry) RegisterActivityWithOptions(a any, options activity.RegisterOptions) {
+	r[options.Name] = a
+}
+
+func TestRegister(t *testing.T) {
+	var got struct {
+		method, path string
+		body         map[string]any
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.method, got.path = r.Method, r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &got.body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer srv.Close()
+
+	ops, err := Operations([]byte(petstore), Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Register(registry{}, ops, &dsl.Activities{}, []string{"listPets"}); err == nil {
+		t.Fatal("expected a conflict with a reserved name")
+	}
+	reg := registry{}
+	if err := Register(reg, ops, &dsl.Activities{}, dsl.ActivityNames()); err != nil {
+		t.Fatal(err)
+	}
+	create := reg["createPet"].(func(context.Context, map[string]any) (dsl.HTTPResponse, error))
+	resp, err := create(context.Background(), map[string]any{"body": map[string]any{"name": "rex"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.method != "POST" || got.path != "/pets" || got.body["name"] != "rex" {
+		t.Errorf("request = %+v", got)
+	}
+	if resp.Status != http.StatusCreated || resp.JSON.(map[string]any)["id"] != 1.0 {
+		t.Errorf("response = %+v", resp)
+	}
+	if _, err := create(context.Background(), map[string]any{}); err == nil {
+		t.Error("expected missing body to fail")
+	}
+}
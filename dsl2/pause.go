@@ -0,0 +1,62 @@
+package dsl
+
+import (
+	"go.temporal.io/sdk/workflow"
+)
+
+// 引擎内置的暂停/恢复信号：暂停后不再开始新节点（已在执行的节点继续完成），
+// 恢复后从暂停处继续；比取消温和，供运维紧急制动。信号不带参数
+const (
+	SignalPause  = "pause"
+	SignalResume = "resume"
+)
+
+// pauser 记录暂停状态，由监听信号的协程更新
+type pauser struct {
+	paused bool
+}
+
+type pauserKey struct{}
+
+// withPauser 在 ctx 中挂载暂停状态并开始监听 SignalPause/SignalResume
+func withPauser(ctx workflow.Context) workflow.Context {
+	p := &pauser{}
+	pauseCh := workflow.GetSignalChannel(ctx, SignalPause)
+	resumeCh := workflow.GetSignalChannel(ctx, SignalResume)
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		logger := workflow.GetLogger(ctx)
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(pauseCh, func(c workflow.ReceiveChannel, _ bool) {
+			c.Receive(ctx, nil)
+			if !p.paused {
+				logger.Info("DSL workflow paused")
+			}
+			p.paused = true
+		})
+		selector.AddReceive(resumeCh, func(c workflow.ReceiveChannel, _ bool) {
+			c.Receive(ctx, nil)
+			if p.paused {
+				logger.Info("DSL workflow resumed")
+			}
+			p.paused = false
+		})
+		for {
+			selector.Select(ctx)
+		}
+	})
+	return workflow.WithValue(ctx, pauserKey{}, p)
+}
+
+func pauserFrom(ctx workflow.Context) *pauser {
+	p, _ := ctx.Value(pauserKey{}).(*pauser)
+	return p
+}
+
+// waitIfPaused 在节点开始前调用：暂停期间阻塞，直到恢复或 ctx 被取消
+func waitIfPaused(ctx workflow.Context) error {
+	p := pauserFrom(ctx)
+	if p == nil || !p.paused {
+		return nil
+	}
+	return workflow.Await(ctx, func() bool { return !p.paused })
+}
@@ -0,0 +1,123 @@
+package dsl
+
+import (
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// 除 QueryTrace 外引擎注册的查询
+const (
+	QueryBindings = "bindings"    // 当前变量快照（map[string]any）
+	QueryProgress = "getProgress" // 节点执行进度（Progress）
+
+	// QueryProgressAlias 是 QueryProgress 的旧名，仍然注册以兼容已有的调用方
+	QueryProgressAlias = "progress"
+)
+
+// Progress 由轨迹汇总而来；Running 按开始顺序排列，Current 为最近开始且仍在执行的节点；
+// Paused 表示收到了 SignalPause，不再开始新节点。
+// Completed/Failed/Skipped 按执行次数计数（Map/While 的 Body 每次执行都计一次），Done/Total 则按定义中的节点计数：
+// 节点自身或任一祖先已结束且当前不在执行即视为完成，因此未选中的 If 分支随 If 一起完成。
+// ETASec 按已用时间与完成比例线性外推，只是粗略估计
+type Progress struct {
+	Completed int      `json:"completed"`
+	Failed    int      `json:"failed"`
+	Skipped   int      `json:"skipped,omitempty"`
+	Running   []string `json:"running"`
+	Current   string   `json:"current,omitempty"`
+	Paused    bool     `json:"paused,omitempty"`
+	Done      int      `json:"done"`
+	Total     int      `json:"total"`
+	Percent   int      `json:"percent"`
+	ETASec    int64    `json:"etaSec,omitempty"`
+}
+
+// progress 汇总轨迹；paths 为定义中全部节点的路径，now 用于估算剩余时间
+func (t *tracer) progress(paths []string, now time.Time) Progress {
+	p := Progress{Running: []string{}, Total: len(paths)}
+	finished := map[int]bool{}
+	for _, ev := range t.events {
+		switch ev.Status {
+		case TraceCompleted:
+			p.Completed++
+			finished[ev.StartSeq] = true
+		case TraceFailed:
+			p.Failed++
+			finished[ev.StartSeq] = true
+		case TraceSkipped:
+			p.Skipped++
+		}
+	}
+	for _, ev := range t.events {
+		if ev.Status == TraceStarted && !finished[ev.Seq] {
+			p.Running = append(p.Running, ev.Path)
+			p.Current = ev.Path
+		}
+	}
+
+	ended, running := map[string]bool{}, map[string]bool{}
+	for _, ev := range t.events {
+		if ev.Status != TraceStarted {
+			ended[ev.Path] = true
+		}
+	}
+	for _, path := range p.Running {
+		running[path] = true
+	}
+	for _, path := range paths {
+		if !running[path] && endedWithAncestor(ended, path) {
+			p.Done++
+		}
+	}
+	if p.Total > 0 {
+		p.Percent = p.Done * 100 / p.Total
+	}
+	if p.Done > 0 && p.Done < p.Total && len(t.events) > 0 {
+		elapsed := now.Sub(t.events[0].Time)
+		p.ETASec = int64((elapsed * time.Duration(p.Total-p.Done) / time.Duration(p.Done)).Seconds())
+	}
+	return p
+}
+
+// endedWithAncestor 判断节点自身或其任一祖先是否已结束
+func endedWithAncestor(ended map[string]bool, path string) bool {
+	for {
+		if ended[path] {
+			return true
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// registerQueries 注册变量与进度查询；bindings 在执行过程中原地更新，查询时返回副本，
+// 运行中 Map 的 collectVar 为已完成元素的部分结果。节点总数在启动时按定义预先计算
+func registerQueries(ctx workflow.Context, wf Workflow, bindings map[string]any) error {
+	reg, _ := ctx.Value(mapRegistryKey{}).(*mapRegistry)
+	if err := workflow.SetQueryHandler(ctx, QueryBindings, func() (map[string]any, error) {
+		cp := make(map[string]any, len(bindings))
+		for k, v := range bindings {
+			cp[k] = v
+		}
+		reg.overlay(cp)
+		return cp, nil
+	}); err != nil {
+		return err
+	}
+	t, p := tracerFrom(ctx), pauserFrom(ctx)
+	paths := wf.nodePaths()
+	progress := func() (Progress, error) {
+		progress := t.progress(paths, workflow.Now(ctx))
+		progress.Paused = p != nil && p.paused
+		return progress, nil
+	}
+	if err := workflow.SetQueryHandler(ctx, QueryProgress, progress); err != nil {
+		return err
+	}
+	return workflow.SetQueryHandler(ctx, QueryProgressAlias, progress)
+}
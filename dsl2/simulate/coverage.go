@@ -0,0 +1,112 @@
+package simulate
+
+import (
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+// Coverage 汇总若干次执行（trace）覆盖了定义中的哪些节点与 If 分支
+type Coverage struct {
+	Nodes           []NodeCoverage   `json:"nodes"`
+	Branches        []BranchCoverage `json:"branches"`
+	NodesCovered    int              `json:"nodesCovered"`
+	BranchesCovered int              `json:"branchesCovered"`
+	Runs            int              `json:"runs"`
+	nodesByID       map[string]int
+}
+
+// NodeCoverage 是一个节点的执行次数；ID 与 BuildGraph 的节点 ID 一致
+type NodeCoverage struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+	Hits  int    `json:"hits"`
+}
+
+// BranchCoverage 是 If 节点某个分支被选中的次数。没有 else 的 If 仍有 else 分支，
+// 表示条件为假时跳过 then（Implicit 为 true）
+type BranchCoverage struct {
+	Node     string `json:"node"`
+	Branch   string `json:"branch"` // then/else
+	Implicit bool   `json:"implicit,omitempty"`
+	Hits     int    `json:"hits"`
+}
+
+// NewCoverage 按定义的节点图建立空的覆盖率，定义无效时返回错误
+func NewCoverage(wf dsl.Workflow) (*Coverage, error) {
+	g, err := dsl.BuildGraph(wf)
+	if err != nil {
+		return nil, err
+	}
+	c := &Coverage{Nodes: []NodeCoverage{}, Branches: []BranchCoverage{}, nodesByID: map[string]int{}}
+	hasElse := map[string]bool{}
+	for _, e := range g.Edges {
+		if e.Kind == "else" {
+			hasElse[e.From] = true
+		}
+	}
+	for _, n := range g.Nodes {
+		if n.ID == dsl.GraphStartID || n.ID == dsl.GraphEndID {
+			continue
+		}
+		c.nodesByID[n.ID] = len(c.Nodes)
+		c.Nodes = append(c.Nodes, NodeCoverage{ID: n.ID, Type: n.Type, Label: n.Label})
+		if n.Type == dsl.KindIf {
+			c.Branches = append(c.Branches,
+				BranchCoverage{Node: n.ID, Branch: "then"},
+				BranchCoverage{Node: n.ID, Branch: "else", Implicit: !hasElse[n.ID]})
+		}
+	}
+	return c, nil
+}
+
+// Add 计入一次执行的 trace。显式分支按其子节点的开始次数计；
+// 隐式 else 按 If 成功结束而 then 未成功结束的次数计
+func (c *Coverage) Add(trace []dsl.TraceEvent) {
+	started, completed := map[string]int{}, map[string]int{}
+	for _, ev := range trace {
+		switch ev.Status {
+		case dsl.TraceStarted:
+			started[ev.Path]++
+		case dsl.TraceCompleted:
+			completed[ev.Path]++
+		}
+	}
+	c.Runs++
+	c.NodesCovered, c.BranchesCovered = 0, 0
+	for i := range c.Nodes {
+		n := &c.Nodes[i]
+		n.Hits += started[n.ID]
+		if n.Hits > 0 {
+			c.NodesCovered++
+		}
+	}
+	for i := range c.Branches {
+		b := &c.Branches[i]
+		if b.Implicit {
+			b.Hits += max(completed[b.Node]-completed[b.Node+".if.then"], 0)
+		} else {
+			b.Hits += started[b.Node+".if."+b.Branch]
+		}
+		if b.Hits > 0 {
+			c.BranchesCovered++
+		}
+	}
+}
+
+// Uncovered 返回未执行的节点与未选中的隐式分支（显式分支未选中时其子节点已在节点中列出）
+func (c *Coverage) Uncovered() ([]NodeCoverage, []BranchCoverage) {
+	var nodes []NodeCoverage
+	var branches []BranchCoverage
+	for _, n := range c.Nodes {
+		if n.Hits == 0 {
+			nodes = append(nodes, n)
+		}
+	}
+	for _, b := range c.Branches {
+		// If 本身未执行时已在 nodes 中列出
+		if b.Implicit && b.Hits == 0 && c.Nodes[c.nodesByID[b.Node]].Hits > 0 {
+			branches = append(branches, b)
+		}
+	}
+	return nodes, branches
+}
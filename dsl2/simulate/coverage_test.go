@@ -0,0 +1,45 @@
+package simulate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+func TestCoverage(t *testing.T) {
+	wf, err := dsl.ParseYAML([]byte(`
+taskQueue: demo
+root:
+  - activity: { name: Check, result: ok }
+  - if:
+      cond: { truthy: { ref: ok } }
+      then:
+        activity: { name: Notify }
+  - id: done
+    log: { message: done }
+`))
+	require.NoError(t, err)
+	cov, err := NewCoverage(wf)
+	require.NoError(t, err)
+	require.Len(t, cov.Nodes, 4)
+	require.Equal(t, []BranchCoverage{
+		{Node: "root[1]", Branch: "then"},
+		{Node: "root[1]", Branch: "else", Implicit: true},
+	}, cov.Branches)
+
+	cov.Add(Run(wf, map[string]Mock{"Check": {Result: true}}).Trace)
+	nodes, branches := cov.Uncovered()
+	require.Equal(t, 4, cov.NodesCovered)
+	require.Empty(t, nodes)
+	require.Equal(t, "else", branches[0].Branch)
+
+	cov.Add(Run(wf, map[string]Mock{"Check": {Result: false}}).Trace)
+	nodes, branches = cov.Uncovered()
+	require.Empty(t, nodes)
+	require.Empty(t, branches)
+	require.Equal(t, 2, cov.Runs)
+	require.Equal(t, 2, cov.BranchesCovered)
+	require.Equal(t, 2, cov.Nodes[0].Hits)
+	require.Equal(t, 1, cov.Branches[1].Hits)
+}
@@ -0,0 +1,94 @@
+// Package simulate 在内存中的 TestWorkflowEnvironment 里执行 DSL 定义，Activity 全部由 Mock 代替，
+// 不需要 Temporal 服务或 worker。web UI 的 Simulate 与 starter 的 -dry-run 共用
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	sdklog "go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+)
+
+// Timeout 限制单次模拟的真实耗时（定时器在测试环境中会被跳过，不计入）
+const Timeout = 10 * time.Second
+
+// Mock 是模拟执行中某个 Activity 的返回值
+type Mock struct {
+	Result  any    `json:"result,omitempty" yaml:"result,omitempty"`   // 每次调用返回的值
+	Results []any  `json:"results,omitempty" yaml:"results,omitempty"` // 依次返回；用完后重复最后一个（优先于 result）
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`     // 非空时返回不可重试的错误
+}
+
+// Result 是一次模拟的结果；Trace 与真实执行的 trace 查询格式相同
+type Result struct {
+	Success bool             `json:"success"`
+	Error   string           `json:"error,omitempty"`
+	Result  map[string]any   `json:"result,omitempty"`
+	Trace   []dsl.TraceEvent `json:"trace,omitempty"`
+	Calls   map[string]int   `json:"calls,omitempty"` // 每个 Activity 被调用的次数（含 Mock 返回错误的调用）
+}
+
+// Run 执行工作流；mocks 按 Activity 名，未列出的 Activity 返回 "<name>:mock"
+func Run(workflow dsl.Workflow, mocks map[string]Mock) (res Result) {
+	// 测试环境在超时等情况下会 panic，转换为普通错误返回
+	defer func() {
+		if p := recover(); p != nil {
+			res = Result{Success: false, Error: fmt.Sprintf("simulation aborted: %v", p)}
+		}
+	}()
+
+	var suite testsuite.WorkflowTestSuite
+	suite.SetLogger(sdklog.NewStructuredLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	env := suite.NewTestWorkflowEnvironment()
+	env.SetTestTimeout(Timeout)
+	// session 语句需要会话 worker
+	env.SetWorkerOptions(worker.Options{EnableSessionWorker: true})
+	env.RegisterWorkflow(dsl.SimpleDSLWorkflow)
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	env.RegisterDynamicActivity(func(ctx context.Context, _ converter.EncodedValues) (any, error) {
+		name := activity.GetInfo(ctx).ActivityType.Name
+		mu.Lock()
+		n := calls[name]
+		calls[name]++
+		mu.Unlock()
+
+		m, ok := mocks[name]
+		switch {
+		case !ok:
+			return name + ":mock", nil
+		case m.Error != "":
+			return nil, temporal.NewNonRetryableApplicationError(m.Error, "SimulatedFailure", nil)
+		case len(m.Results) > 0:
+			return m.Results[min(n, len(m.Results)-1)], nil
+		}
+		return m.Result, nil
+	}, activity.DynamicRegisterOptions{})
+
+	env.ExecuteWorkflow(dsl.SimpleDSLWorkflow, workflow)
+
+	res.Success = true
+	if err := env.GetWorkflowError(); err != nil {
+		res.Success, res.Error = false, err.Error()
+	} else if err := env.GetWorkflowResult(&res.Result); err != nil {
+		res.Success, res.Error = false, err.Error()
+	}
+	mu.Lock()
+	res.Calls = calls
+	mu.Unlock()
+	if v, err := env.QueryWorkflow(dsl.QueryTrace); err == nil {
+		_ = v.Get(&res.Trace)
+	}
+	return res
+}
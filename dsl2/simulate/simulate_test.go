@@ -0,0 +1,65 @@
+package simulate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+const approvalYAML = `
+taskQueue: demo
+variables:
+  approved: false
+root:
+  - activity: { name: Prepare, result: prepared }
+  - while:
+      cond: { not: { truthy: { ref: approved } } }
+      maxIters: 5
+      body:
+        activity: { name: Approve, result: approved }
+`
+
+func TestRunWithMocks(t *testing.T) {
+	wf, err := dsl.ParseYAML([]byte(approvalYAML))
+	require.NoError(t, err)
+
+	res := Run(wf, map[string]Mock{"Approve": {Results: []any{false, false, true}}})
+	require.True(t, res.Success, res.Error)
+	require.Equal(t, "Prepare:mock", res.Result["prepared"])
+	require.Equal(t, true, res.Result["approved"])
+
+	bodyRuns := 0
+	for _, ev := range res.Trace {
+		if ev.Path == "root[1].while.body" && ev.Status == dsl.TraceCompleted {
+			bodyRuns++
+		}
+	}
+	require.Equal(t, 3, bodyRuns)
+}
+
+func TestRunMockedFailure(t *testing.T) {
+	wf, err := dsl.ParseYAML([]byte(approvalYAML))
+	require.NoError(t, err)
+
+	res := Run(wf, map[string]Mock{"Prepare": {Error: "boom"}})
+	require.False(t, res.Success)
+	require.Contains(t, res.Error, "boom")
+}
+
+func TestRunSession(t *testing.T) {
+	wf, err := dsl.ParseYAML([]byte(`
+taskQueue: demo
+root:
+  - session:
+      steps:
+        - activity: { name: Download, result: file }
+        - activity: { name: Process, args: [{ ref: file }], result: out }
+`))
+	require.NoError(t, err)
+
+	res := Run(wf, nil)
+	require.True(t, res.Success, res.Error)
+	require.Equal(t, "Process:mock", res.Result["out"])
+	require.Equal(t, "Download:mock", res.Result["file"])
+}
@@ -0,0 +1,210 @@
+package simulate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Redaction 把快照中每次执行都不同的值替换为固定的占位符：
+// key 按变量或字段名（path.Match 通配符）替换整个值，pattern 替换字符串中匹配的部分
+//
+//	redact:
+//	  - key: "*At"
+//	    with: <time>
+//	  - pattern: "ord-[0-9]+"
+//	    with: <order>
+type Redaction struct {
+	Key     string `json:"key,omitempty" yaml:"key,omitempty"`
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	With    string `json:"with,omitempty" yaml:"with,omitempty"` // 默认 "<redacted>"
+}
+
+// DefaultRedactions 总是生效：RFC 3339 时间戳（now 语句等）与 UUID（newId 语句等）
+var DefaultRedactions = []Redaction{
+	{Pattern: `\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`, With: "<time>"},
+	{Pattern: `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`, With: "<uuid>"},
+}
+
+type compiledRedaction struct {
+	Redaction
+	re *regexp.Regexp
+}
+
+// Redact 返回按 rules 替换后的 v（先转换为 JSON 类型）；v 本身不被修改
+func Redact(v any, rules []Redaction) (any, error) {
+	compiled := make([]compiledRedaction, len(rules))
+	for i, r := range rules {
+		if r.Key == "" && r.Pattern == "" {
+			return nil, fmt.Errorf("redact[%d]: key or pattern is required", i)
+		}
+		if _, err := path.Match(r.Key, ""); err != nil {
+			return nil, fmt.Errorf("redact[%d].key: %w", i, err)
+		}
+		if r.With == "" {
+			r.With = "<redacted>"
+		}
+		compiled[i].Redaction = r
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("redact[%d].pattern: %w", i, err)
+			}
+			compiled[i].re = re
+		}
+	}
+	return redact(normalize(v), compiled), nil
+}
+
+func redact(v any, rules []compiledRedaction) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			out[k] = redactKey(k, val, rules)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = redact(val, rules)
+		}
+		return out
+	case string:
+		for _, r := range rules {
+			if r.re != nil {
+				v = r.re.ReplaceAllLiteralString(v, r.With)
+			}
+		}
+		return v
+	}
+	return v
+}
+
+func redactKey(k string, v any, rules []compiledRedaction) any {
+	for _, r := range rules {
+		if r.Key == "" {
+			continue
+		}
+		if ok, _ := path.Match(r.Key, k); ok {
+			return r.With
+		}
+	}
+	return redact(v, rules)
+}
+
+// Snapshot 返回 bindings 经 DefaultRedactions 与 rules 处理后的快照：键有序、两空格缩进的 JSON
+func Snapshot(bindings map[string]any, rules []Redaction) ([]byte, error) {
+	v, err := Redact(bindings, append(append([]Redaction{}, DefaultRedactions...), rules...))
+	if err != nil {
+		return nil, err
+	}
+	// 不转义 <>&，占位符保持可读
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CompareSnapshot 比较快照与 golden 文件，不同时返回带逐行差异的错误；
+// update 为 true 时改为用 got 写入（必要时创建）该文件
+func CompareSnapshot(golden string, got []byte, update bool) error {
+	if update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(golden, got, 0o644)
+	}
+	want, err := os.ReadFile(golden)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("snapshot %s does not exist; rerun with -update to create it", golden)
+	}
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(bytes.TrimSpace(want), bytes.TrimSpace(got)) {
+		return nil
+	}
+	return fmt.Errorf("snapshot %s differs (-want +got):\n%s", golden, Diff(string(want), string(got)))
+}
+
+// diffContext 是差异前后保留的相同行数
+const diffContext = 2
+
+// Diff 返回 want 与 got 的逐行差异：删除的行以 "- " 开头，新增的行以 "+ " 开头，
+// 只保留改动附近的相同行，省略处以 "  ..." 表示
+func Diff(want, got string) string {
+	a := strings.Split(strings.TrimRight(want, "\n"), "\n")
+	b := strings.Split(strings.TrimRight(got, "\n"), "\n")
+
+	// lcs[i][j] 是 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i]})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j]})
+			j++
+		}
+	}
+
+	// 标出改动行及其上下文
+	keep := make([]bool, len(lines))
+	for k, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		for c := max(k-diffContext, 0); c <= min(k+diffContext, len(lines)-1); c++ {
+			keep[c] = true
+		}
+	}
+	var out strings.Builder
+	skipped := false
+	for k, l := range lines {
+		if !keep[k] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			out.WriteString("  ...\n")
+		}
+		skipped = false
+		fmt.Fprintf(&out, "%c %s\n", l.op, l.text)
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
@@ -0,0 +1,113 @@
+package simulate
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dsl "github.com/temporalio/samples-go/dsl2"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden snapshot files under testdata")
+
+// TestExampleSnapshots 执行 web UI 的示例定义并与 testdata/snapshots 中的结果比较；
+// 引擎改变了示例的输出时失败，确认无误后用 go test ./simulate -run Snapshots -update 更新
+func TestExampleSnapshots(t *testing.T) {
+	files, err := filepath.Glob("../cmd/webui/examples/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			b, err := os.ReadFile(file)
+			require.NoError(t, err)
+			// 去掉示例的 front-matter
+			if rest, ok := bytes.CutPrefix(b, []byte("---\n")); ok {
+				_, b, ok = bytes.Cut(rest, []byte("\n---\n"))
+				require.True(t, ok, "unterminated front-matter")
+			}
+			wf, err := dsl.ParseYAML(b)
+			require.NoError(t, err)
+			res := Run(wf, nil)
+			require.True(t, res.Success, res.Error)
+			got, err := Snapshot(res.Result, nil)
+			require.NoError(t, err)
+			require.NoError(t, CompareSnapshot(filepath.Join("testdata", "snapshots", name+".json"), got, *update))
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	got, err := Redact(map[string]any{
+		"createdAt": "yesterday",
+		"order":     map[string]any{"id": "ord-42", "requestId": "0b0e9a5c-9a4b-4d7e-8a1f-1c2d3e4f5a6b"},
+		"log":       []any{"started at 2024-05-01T10:00:00.123Z", 3},
+	}, append(DefaultRedactions, Redaction{Key: "*At"}, Redaction{Pattern: `ord-\d+`, With: "<order>"}))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"createdAt": "<redacted>",
+		"order":     map[string]any{"id": "<order>", "requestId": "<uuid>"},
+		"log":       []any{"started at <time>", float64(3)},
+	}, got)
+
+	_, err = Redact(nil, []Redaction{{With: "x"}})
+	require.ErrorContains(t, err, "key or pattern is required")
+	_, err = Redact(nil, []Redaction{{Pattern: "("}})
+	require.ErrorContains(t, err, "redact[0].pattern")
+}
+
+func TestCompareSnapshot(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "snap", "order.json")
+	before, err := Snapshot(map[string]any{"status": "shipped", "total": 10, "items": []any{"a", "b", "c", "d", "e"}}, nil)
+	require.NoError(t, err)
+
+	require.ErrorContains(t, CompareSnapshot(golden, before, false), "rerun with -update")
+	require.NoError(t, CompareSnapshot(golden, before, true))
+	require.NoError(t, CompareSnapshot(golden, before, false))
+
+	after, err := Snapshot(map[string]any{"status": "pending", "total": 10, "items": []any{"a", "b", "c", "d", "e"}}, nil)
+	require.NoError(t, err)
+	err = CompareSnapshot(golden, after, false)
+	require.Error(t, err)
+	require.Equal(t, "snapshot "+golden+` differs (-want +got):
+  ...
+      "e"
+    ],
+-   "status": "shipped",
++   "status": "pending",
+    "total": 10
+  }`, err.Error())
+}
+
+func TestSuiteSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "order.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(orderYAML+`
+  - name: snapshot
+    mocks:
+      Charge: { result: true }
+    expect:
+      snapshot: testdata/order.json
+      redact: [{ key: shipment }]
+`), 0o644))
+	s, wf, err := LoadSuite(path)
+	require.NoError(t, err)
+
+	s.UpdateSnapshots = true
+	require.True(t, s.Run(wf, nil)[2].Passed)
+	b, err := os.ReadFile(filepath.Join(dir, "testdata", "order.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"shipment": "<redacted>"`)
+
+	s.UpdateSnapshots = false
+	s.Tests[2].Mocks["Charge"] = Mock{Result: "yes"}
+	r := s.Run(wf, nil)[2]
+	require.False(t, r.Passed)
+	require.Len(t, r.Failures, 1)
+	require.Contains(t, r.Failures[0], `-   "paid": true,`)
+	require.Contains(t, r.Failures[0], `+   "paid": "yes",`)
+}
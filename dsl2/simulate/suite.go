@@ -0,0 +1,276 @@
+package simulate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	dsl "github.com/temporalio/samples-go/dsl2"
+	"gopkg.in/yaml.v3"
+)
+
+// TestFileSuffix 是独立测试文件的后缀；orders.test.yaml 默认测试同目录的 orders.yaml
+const TestFileSuffix = ".test.yaml"
+
+// Suite 是一组测试用例，可以写在独立的测试文件中，也可以写在定义文件顶层的 tests 段
+// （执行工作流时忽略该键）：
+//
+//	workflow: orders.yaml        # 仅独立测试文件使用，相对测试文件；省略时按文件名推断
+//	tests:
+//	  - name: ships paid orders
+//	    variables: { orderId: 42 }
+//	    mocks:
+//	      Charge: { result: { ok: true } }
+//	    expect:
+//	      variables: { status: shipped }
+//	      calls: { Charge: 1, Refund: 0 }
+//	      trace: [charge, "root[1].if.then"]
+//	      snapshot: testdata/ships-paid-orders.json
+type Suite struct {
+	Workflow string `json:"workflow,omitempty" yaml:"workflow,omitempty"`
+	Tests    []Case `json:"tests" yaml:"tests"`
+	// UpdateSnapshots 为 true 时用执行结果重写各用例 expect.snapshot 指向的文件，不做比较
+	UpdateSnapshots bool `json:"-" yaml:"-"`
+
+	dir string // 测试所在文件的目录，snapshot 相对它解析
+}
+
+// Case 是一个测试用例：用 variables 覆盖定义中的变量，按 mocks 模拟 Activity 后检查 expect
+type Case struct {
+	Name      string          `json:"name,omitempty" yaml:"name,omitempty"`
+	Variables map[string]any  `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Mocks     map[string]Mock `json:"mocks,omitempty" yaml:"mocks,omitempty"`
+	Expect    Expect          `json:"expect" yaml:"expect"`
+}
+
+// Expect 是用例的断言，未设置的项不检查
+type Expect struct {
+	Success    *bool          `json:"success,omitempty" yaml:"success,omitempty"`       // 默认：error 为空时期望成功
+	Error      string         `json:"error,omitempty" yaml:"error,omitempty"`           // 期望失败，且错误信息包含该文本
+	Variables  map[string]any `json:"variables,omitempty" yaml:"variables,omitempty"`   // 结束时的绑定；对象按键递归部分匹配，其余值完全相等
+	Calls      map[string]int `json:"calls,omitempty" yaml:"calls,omitempty"`           // Activity 调用次数，0 表示不得调用
+	Trace      []string       `json:"trace,omitempty" yaml:"trace,omitempty"`           // 依次执行的节点（路径或 Statement ID），按子序列匹配
+	NotVisited []string       `json:"notVisited,omitempty" yaml:"notVisited,omitempty"` // 不得执行的节点
+	// Snapshot 是保存全部结束绑定的 golden JSON 文件（相对测试文件），见 CompareSnapshot
+	Snapshot string      `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+	Redact   []Redaction `json:"redact,omitempty" yaml:"redact,omitempty"` // 快照的额外替换规则，DefaultRedactions 总是生效
+
+	update bool
+}
+
+// CaseResult 是一个用例的结果；Failures 列出所有未满足的断言
+type CaseResult struct {
+	Name       string   `json:"name"`
+	Passed     bool     `json:"passed"`
+	Failures   []string `json:"failures,omitempty"`
+	DurationMs int64    `json:"durationMs"`
+}
+
+// LoadSuite 读取 path 中的测试及其被测定义：以 TestFileSuffix 结尾的是独立测试文件，
+// 其他文件视为带 tests 段的定义（没有 tests 段时返回空 Suite）
+func LoadSuite(path string) (Suite, dsl.Workflow, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Suite{}, dsl.Workflow{}, err
+	}
+	var s Suite
+	if err := yaml.Unmarshal(b, &s); err != nil {
+		return Suite{}, dsl.Workflow{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	s.dir = filepath.Dir(path)
+	if !strings.HasSuffix(path, TestFileSuffix) {
+		if s.Workflow != "" {
+			return Suite{}, dsl.Workflow{}, fmt.Errorf("%s: workflow is only allowed in %s files", path, TestFileSuffix)
+		}
+		wf, err := dsl.ParseYAML(b)
+		if err != nil {
+			return Suite{}, dsl.Workflow{}, fmt.Errorf("parse %s: %w", path, err)
+		}
+		return s, wf, nil
+	}
+
+	wfPath := strings.TrimSuffix(path, TestFileSuffix) + ".yaml"
+	if s.Workflow != "" {
+		wfPath = filepath.Join(filepath.Dir(path), s.Workflow)
+	}
+	wb, err := os.ReadFile(wfPath)
+	if err != nil {
+		return Suite{}, dsl.Workflow{}, fmt.Errorf("%s: %w", path, err)
+	}
+	wf, err := dsl.ParseYAML(wb)
+	if err != nil {
+		return Suite{}, dsl.Workflow{}, fmt.Errorf("parse %s: %w", wfPath, err)
+	}
+	s.Workflow = wfPath
+	return s, wf, nil
+}
+
+// Run 依次执行全部用例；cov 非空时计入每个用例的 trace
+func (s Suite) Run(wf dsl.Workflow, cov *Coverage) []CaseResult {
+	results := make([]CaseResult, len(s.Tests))
+	for i, c := range s.Tests {
+		if c.Expect.Snapshot != "" && !filepath.IsAbs(c.Expect.Snapshot) {
+			c.Expect.Snapshot = filepath.Join(s.dir, c.Expect.Snapshot)
+		}
+		c.Expect.update = s.UpdateSnapshots
+		results[i] = c.Run(wf, cov)
+		if results[i].Name == "" {
+			results[i].Name = fmt.Sprintf("test %d", i+1)
+		}
+	}
+	return results
+}
+
+// Run 在测试环境中执行用例并检查断言；cov 非空时计入本次执行的 trace
+func (c Case) Run(wf dsl.Workflow, cov *Coverage) CaseResult {
+	begin := time.Now()
+	res := Run(wf.WithVariables(c.Variables), c.Mocks)
+	if cov != nil {
+		cov.Add(res.Trace)
+	}
+	failures := c.Expect.check(res)
+	return CaseResult{
+		Name:       c.Name,
+		Passed:     len(failures) == 0,
+		Failures:   failures,
+		DurationMs: time.Since(begin).Milliseconds(),
+	}
+}
+
+func (e Expect) check(res Result) []string {
+	var failures []string
+	wantSuccess := e.Error == ""
+	if e.Success != nil {
+		wantSuccess = *e.Success
+	}
+	switch {
+	case wantSuccess && !res.Success:
+		failures = append(failures, "workflow failed: "+res.Error)
+	case !wantSuccess && res.Success:
+		failures = append(failures, "workflow succeeded, expected a failure")
+	case e.Error != "" && !strings.Contains(res.Error, e.Error):
+		failures = append(failures, fmt.Sprintf("error %q does not contain %q", res.Error, e.Error))
+	}
+
+	for _, k := range sortedKeys(e.Variables) {
+		got, ok := res.Result[k]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("variable %s is not set", k))
+			continue
+		}
+		if m := match(normalize(e.Variables[k]), got, k); m != nil {
+			failures = append(failures, fmt.Sprintf("variable %s = %s, want %s", m.path, jsonText(m.got), jsonText(m.want)))
+		}
+	}
+
+	for _, name := range sortedKeys(e.Calls) {
+		if got := res.Calls[name]; got != e.Calls[name] {
+			failures = append(failures, fmt.Sprintf("activity %s called %d time(s), want %d", name, got, e.Calls[name]))
+		}
+	}
+
+	var visited []dsl.TraceEvent
+	for _, ev := range res.Trace {
+		if ev.Status == dsl.TraceStarted {
+			visited = append(visited, ev)
+		}
+	}
+	next := 0
+	for _, node := range e.Trace {
+		for next < len(visited) && !isNode(visited[next], node) {
+			next++
+		}
+		if next == len(visited) {
+			failures = append(failures, fmt.Sprintf("trace: %s was not visited in the expected order", node))
+			break
+		}
+		next++
+	}
+	for _, node := range e.NotVisited {
+		for _, ev := range visited {
+			if isNode(ev, node) {
+				failures = append(failures, fmt.Sprintf("trace: %s was visited", node))
+				break
+			}
+		}
+	}
+
+	// 失败的执行没有绑定，快照只对成功的执行有意义
+	if e.Snapshot != "" && res.Success {
+		got, err := Snapshot(res.Result, e.Redact)
+		if err == nil {
+			err = CompareSnapshot(e.Snapshot, got, e.update)
+		}
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	return failures
+}
+
+// isNode 判断事件是否属于节点：节点可以写路径或 Statement ID
+func isNode(ev dsl.TraceEvent, node string) bool {
+	return ev.Path == node || (ev.ID != "" && ev.ID == node)
+}
+
+// normalize 把 YAML 中的期望值转换为与执行结果相同的 JSON 类型（数字为 float64 等）
+func normalize(v any) any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// mismatch 是 match 找到的第一个不同之处
+type mismatch struct {
+	path      string
+	want, got any
+}
+
+// match 递归比较：want 为对象时只比较其中的键，匹配时返回 nil
+func match(want, got any, path string) *mismatch {
+	wm, ok := want.(map[string]any)
+	if !ok {
+		if reflect.DeepEqual(want, got) {
+			return nil
+		}
+		return &mismatch{path, want, got}
+	}
+	gm, ok := got.(map[string]any)
+	if !ok {
+		return &mismatch{path, want, got}
+	}
+	for _, k := range sortedKeys(wm) {
+		if m := match(wm[k], gm[k], path+"."+k); m != nil {
+			return m
+		}
+	}
+	return nil
+}
+
+func jsonText(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,90 @@
+package simulate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const orderYAML = `
+taskQueue: demo
+variables:
+  order: { id: 1, amount: 10 }
+root:
+  - id: charge
+    activity: { name: Charge, args: [{ ref: order }], result: paid }
+  - if:
+      cond: { truthy: { ref: paid } }
+      then:
+        activity: { name: Ship, result: shipment }
+      else:
+        activity: { name: Refund, result: refund }
+tests:
+  - name: ships paid orders
+    variables: { order: { id: 7, amount: 3 } }
+    mocks:
+      Charge: { result: true }
+    expect:
+      variables: { paid: true, shipment: "Ship:mock", order: { id: 7 } }
+      calls: { Charge: 1, Refund: 0 }
+      trace: [charge, "root[1].if.then"]
+      notVisited: ["root[1].if.else"]
+  - mocks:
+      Charge: { error: card declined }
+    expect:
+      error: declined
+      calls: { Ship: 0 }
+`
+
+func TestSuiteInline(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "order.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(orderYAML), 0o644))
+
+	s, wf, err := LoadSuite(path)
+	require.NoError(t, err)
+	require.Len(t, s.Tests, 2)
+
+	results := s.Run(wf, nil)
+	for _, r := range results {
+		require.True(t, r.Passed, "%s: %v", r.Name, r.Failures)
+	}
+	require.Equal(t, "ships paid orders", results[0].Name)
+	require.Equal(t, "test 2", results[1].Name)
+}
+
+func TestSuiteFileFailures(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "order.yaml"), []byte(orderYAML), 0o644))
+	path := filepath.Join(dir, "order"+TestFileSuffix)
+	require.NoError(t, os.WriteFile(path, []byte(`
+tests:
+  - name: wrong expectations
+    mocks:
+      Charge: { result: false }
+    expect:
+      variables: { order: { id: 8 }, missing: 1 }
+      calls: { Refund: 2 }
+      trace: ["root[1].if.then"]
+  - expect: { error: boom }
+`), 0o644))
+
+	s, wf, err := LoadSuite(path)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "order.yaml"), s.Workflow)
+
+	results := s.Run(wf, nil)
+	require.False(t, results[0].Passed)
+	require.Equal(t, []string{
+		"variable missing is not set",
+		"variable order.id = 1, want 8",
+		"activity Refund called 1 time(s), want 2",
+		"trace: root[1].if.then was not visited in the expected order",
+	}, results[0].Failures)
+	require.Equal(t, []string{"workflow succeeded, expected a failure"}, results[1].Failures)
+
+	_, _, err = LoadSuite(filepath.Join(dir, "missing"+TestFileSuffix))
+	require.Error(t, err)
+}
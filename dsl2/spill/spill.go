@@ -0,0 +1,239 @@
+// Package spill 让大的 Activity 结果不进入工作流状态：worker 的 DataConverter 在 Activity 中把编码后超过阈值的结果
+// 存入对象存储（key 按 workflow ID/run ID 组织），返回给工作流的只是一个引用，变量、历史与 ContinueAsNew 携带的都是引用；
+// Activity 收到含引用的参数时，在解码前透明地取回原值。
+//
+// 工作流本身看不到原值：条件、set 等语句作用于引用对象，需要读取内容时交给 Activity。
+// 对象不会自动删除，可按 runs/<workflowId>/ 前缀或存储的生命周期规则（Redis 的 ttl）清理
+package spill
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/temporalio/samples-go/dsl2/blob"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/workflow"
+)
+
+// RefKey 是引用对象唯一的键：{"$spill": {"store": ..., "key": ..., "size": ...}}
+const RefKey = "$spill"
+
+// DefaultThreshold 是未配置阈值时的转存下限（编码后的字节数）
+const DefaultThreshold = 64 << 10
+
+// Config 配置 worker 的变量转存
+//
+//	spill: { store: artifacts, threshold: 262144 }
+type Config struct {
+	Store     string `yaml:"store"`               // worker blobStores 中的名字
+	Threshold int    `yaml:"threshold,omitempty"` // 字节，默认 DefaultThreshold
+}
+
+// Ref 是变量中代替原值的引用
+type Ref struct {
+	Store string `json:"store"`
+	Key   string `json:"key"`
+	Size  int    `json:"size"` // 原载荷的字节数
+}
+
+// RefOf 判断 v 是否为引用对象（工作流与 Activity 中解码后的形式）
+func RefOf(v any) (Ref, bool) {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) != 1 {
+		return Ref{}, false
+	}
+	inner, ok := m[RefKey].(map[string]any)
+	if !ok {
+		return Ref{}, false
+	}
+	key, _ := inner["key"].(string)
+	if key == "" {
+		return Ref{}, false
+	}
+	store, _ := inner["store"].(string)
+	size, _ := inner["size"].(float64)
+	if n, ok := inner["size"].(json.Number); ok {
+		i, _ := n.Int64()
+		size = float64(i)
+	}
+	return Ref{Store: store, Key: key, Size: int(size)}, true
+}
+
+// NewDataConverter 包装 worker 的 DataConverter（nil 为 SDK 默认）：只在 Activity 的上下文中转存与取回，
+// 工作流、查询与客户端的编解码保持不变。store 是 cfg.Store 对应的已打开的存储
+func NewDataConverter(dc converter.DataConverter, store blob.Store, cfg Config) converter.DataConverter {
+	if dc == nil {
+		dc = converter.GetDefaultDataConverter()
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = DefaultThreshold
+	}
+	return &dataConverter{DataConverter: dc, store: store, cfg: cfg}
+}
+
+type dataConverter struct {
+	converter.DataConverter
+	store blob.Store
+	cfg   Config
+}
+
+// contextAware 是 SDK 对 DataConverter 的上下文接口（未从公开包导出）
+type contextAware interface {
+	WithWorkflowContext(workflow.Context) converter.DataConverter
+	WithContext(context.Context) converter.DataConverter
+}
+
+// WithWorkflowContext 保留包装：工作流中的编解码不变，但 SDK 可能用它执行 Activity（如测试环境）
+func (dc *dataConverter) WithWorkflowContext(ctx workflow.Context) converter.DataConverter {
+	if inner, ok := dc.DataConverter.(contextAware); ok {
+		return &dataConverter{DataConverter: inner.WithWorkflowContext(ctx), store: dc.store, cfg: dc.cfg}
+	}
+	return dc
+}
+
+func (dc *dataConverter) WithContext(ctx context.Context) converter.DataConverter {
+	inner := dc.DataConverter
+	if ca, ok := inner.(contextAware); ok {
+		inner = ca.WithContext(ctx)
+	}
+	if !activity.IsActivity(ctx) {
+		return inner
+	}
+	run := activity.GetInfo(ctx).WorkflowExecution
+	return &activityConverter{
+		DataConverter: inner,
+		ctx:           ctx,
+		store:         dc.store,
+		cfg:           dc.cfg,
+		prefix:        "runs/" + run.ID + "/" + run.RunID + "/",
+	}
+}
+
+// activityConverter 在一次 Activity 执行中转存结果、取回参数
+type activityConverter struct {
+	converter.DataConverter
+	ctx    context.Context
+	store  blob.Store
+	cfg    Config
+	prefix string
+}
+
+// ToPayload 编码 value；超过阈值时把编码后的载荷（含 codec 的加密、压缩）存入存储，返回引用的载荷。
+// key 由内容的 SHA-256 决定，重试写入的是同一个对象
+func (c *activityConverter) ToPayload(value any) (*commonpb.Payload, error) {
+	p, err := c.DataConverter.ToPayload(value)
+	if err != nil || p.Size() <= c.cfg.Threshold {
+		return p, err
+	}
+	data, err := p.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	ref := Ref{Store: c.cfg.Store, Key: c.prefix + hex.EncodeToString(sum[:]), Size: len(data)}
+	if err := c.store.Put(c.ctx, ref.Key, data, "application/x-protobuf"); err != nil {
+		return nil, fmt.Errorf("spill %d-byte value: %w", len(data), err)
+	}
+	activity.GetLogger(c.ctx).Debug("Spilled activity result", "key", ref.Key, "size", ref.Size)
+	return c.DataConverter.ToPayload(map[string]Ref{RefKey: ref})
+}
+
+func (c *activityConverter) ToPayloads(values ...any) (*commonpb.Payloads, error) {
+	out := &commonpb.Payloads{}
+	for i, v := range values {
+		p, err := c.ToPayload(v)
+		if err != nil {
+			return nil, fmt.Errorf("values[%d]: %w", i, err)
+		}
+		out.Payloads = append(out.Payloads, p)
+	}
+	return out, nil
+}
+
+// FromPayload 解码 p；其中含引用时先取回原值（可在任意嵌套位置，如 Map 收集的列表中）
+func (c *activityConverter) FromPayload(p *commonpb.Payload, valuePtr any) error {
+	var raw json.RawMessage
+	if err := c.DataConverter.FromPayload(p, &raw); err != nil || !bytes.Contains(raw, []byte(`"`+RefKey+`"`)) {
+		return c.DataConverter.FromPayload(p, valuePtr)
+	}
+	// UseNumber 保留大整数的精度；取回的值以 json.RawMessage 原样嵌回
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	v, err := c.rehydrate(v)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, valuePtr)
+}
+
+func (c *activityConverter) FromPayloads(payloads *commonpb.Payloads, valuePtrs ...any) error {
+	for i, p := range payloads.GetPayloads() {
+		if i >= len(valuePtrs) {
+			break
+		}
+		if err := c.FromPayload(p, valuePtrs[i]); err != nil {
+			return fmt.Errorf("payload item %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (c *activityConverter) rehydrate(v any) (any, error) {
+	if ref, ok := RefOf(v); ok {
+		return c.load(ref)
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			r, err := c.rehydrate(val)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = r
+		}
+	case []any:
+		for i, val := range v {
+			r, err := c.rehydrate(val)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = r
+		}
+	}
+	return v, nil
+}
+
+// load 取回引用的载荷并解码为 JSON
+func (c *activityConverter) load(ref Ref) (json.RawMessage, error) {
+	data, err := c.store.Get(c.ctx, ref.Key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, fmt.Errorf("spilled value %s no longer exists", ref.Key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load spilled value %s: %w", ref.Key, err)
+	}
+	p := &commonpb.Payload{}
+	if err := p.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("spilled value %s: %w", ref.Key, err)
+	}
+	var raw json.RawMessage
+	if err := c.DataConverter.FromPayload(p, &raw); err != nil {
+		return nil, fmt.Errorf("spilled value %s: %w", ref.Key, err)
+	}
+	return raw, nil
+}
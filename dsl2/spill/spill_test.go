@@ -0,0 +1,98 @@
+package spill
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temporalio/samples-go/dsl2/blob"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+type report struct {
+	Rows []string `json:"rows"`
+	ID   int64    `json:"id"`
+}
+
+func produce(_ context.Context, n int) (report, error) {
+	rows := make([]string, n)
+	for i := range rows {
+		rows[i] = strings.Repeat("x", 100)
+	}
+	return report{Rows: rows, ID: 1<<53 + 1}, nil
+}
+
+// consume 收到的是取回后的原值，包括嵌在列表中的引用
+func consume(_ context.Context, in struct {
+	Reports []report `json:"reports"`
+	Note    string   `json:"note"`
+}) (map[string]any, error) {
+	rows := 0
+	for _, r := range in.Reports {
+		rows += len(r.Rows)
+	}
+	return map[string]any{"rows": rows, "exactId": in.Reports[0].ID == 1<<53+1, "note": in.Note}, nil
+}
+
+// spillWorkflow 模拟 DSL 引擎：Activity 结果存入变量，再作为另一个 Activity 的参数
+func spillWorkflow(ctx workflow.Context) (map[string]any, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{StartToCloseTimeout: time.Minute})
+	var big, small any
+	if err := workflow.ExecuteActivity(ctx, produce, 100).Get(ctx, &big); err != nil {
+		return nil, err
+	}
+	if err := workflow.ExecuteActivity(ctx, produce, 1).Get(ctx, &small); err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	err := workflow.ExecuteActivity(ctx, consume, map[string]any{"reports": []any{big, small}, "note": "n"}).Get(ctx, &out)
+	run := workflow.GetInfo(ctx).WorkflowExecution
+	return map[string]any{"big": big, "small": small, "consumed": out, "run": run.ID + "/" + run.RunID}, err
+}
+
+func TestDataConverter(t *testing.T) {
+	store, err := blob.Open(blob.Config{Type: blob.TypeFile, Dir: t.TempDir()})
+	require.NoError(t, err)
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.SetDataConverter(NewDataConverter(nil, store, Config{Store: "scratch", Threshold: 1024}))
+	env.RegisterWorkflow(spillWorkflow)
+	env.RegisterActivity(produce)
+	env.RegisterActivity(consume)
+	env.ExecuteWorkflow(spillWorkflow)
+	require.NoError(t, env.GetWorkflowError())
+	var out map[string]any
+	require.NoError(t, env.GetWorkflowResult(&out))
+
+	// 工作流只持有引用；小的结果不转存
+	ref, ok := RefOf(out["big"])
+	require.True(t, ok, "%v", out["big"])
+	require.Equal(t, "scratch", ref.Store)
+	require.True(t, strings.HasPrefix(ref.Key, "runs/"+out["run"].(string)+"/"), ref.Key)
+	require.Greater(t, ref.Size, 10000)
+	_, ok = RefOf(out["small"])
+	require.False(t, ok)
+
+	objs, err := store.List(context.Background(), "runs/")
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+
+	require.Equal(t, map[string]any{"rows": float64(101), "exactId": true, "note": "n"}, out["consumed"])
+}
+
+func TestRefOf(t *testing.T) {
+	_, ok := RefOf(map[string]any{RefKey: map[string]any{"key": ""}})
+	require.False(t, ok)
+	_, ok = RefOf(map[string]any{RefKey: map[string]any{"key": "k"}, "other": 1})
+	require.False(t, ok)
+	ref, ok := RefOf(map[string]any{RefKey: map[string]any{"key": "k", "store": "s", "size": float64(3)}})
+	require.True(t, ok)
+	require.Equal(t, Ref{Store: "s", Key: "k", Size: 3}, ref)
+}
+
+var _ converter.DataConverter = (*activityConverter)(nil)
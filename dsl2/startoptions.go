@@ -0,0 +1,71 @@
+package dsl
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	enumspb "go.temporal.io/api/enums/v1"
+)
+
+// IDReusePolicies 是 idReusePolicy（以及 starter 的 -id-reuse-policy）接受的取值
+var IDReusePolicies = map[string]enumspb.WorkflowIdReusePolicy{
+	"AllowDuplicate":           enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+	"AllowDuplicateFailedOnly": enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY,
+	"RejectDuplicate":          enumspb.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+	"TerminateIfRunning":       enumspb.WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING,
+}
+
+// ParseIDReusePolicy 把策略名转换为枚举；空串返回 UNSPECIFIED（服务端默认 AllowDuplicate）
+func ParseIDReusePolicy(s string) (enumspb.WorkflowIdReusePolicy, error) {
+	if s == "" {
+		return enumspb.WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED, nil
+	}
+	p, ok := IDReusePolicies[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown policy %q (want AllowDuplicate, AllowDuplicateFailedOnly, RejectDuplicate or TerminateIfRunning)", s)
+	}
+	return p, nil
+}
+
+// ParseIDTemplate 解析 Workflow ID 模板（Go text/template，如 order-{{.orderId}}）；引用不存在的变量时渲染失败
+func ParseIDTemplate(s string) (*template.Template, error) {
+	return template.New("id").Option("missingkey=error").Parse(s)
+}
+
+// RenderID 用变量渲染 ID 模板；结果为空时报错
+func RenderID(t *template.Template, vars map[string]any) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, vars); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(b.String()) == "" {
+		return "", fmt.Errorf("rendered an empty ID")
+	}
+	return b.String(), nil
+}
+
+// StartID 按 workflowIdTemplate 用当前变量（调用方应先合并覆盖）渲染 Workflow ID；未设置模板时返回 ""
+func (wf Workflow) StartID() (string, error) {
+	if wf.WorkflowIDTemplate == "" {
+		return "", nil
+	}
+	t, err := ParseIDTemplate(wf.WorkflowIDTemplate)
+	if err != nil {
+		return "", fmt.Errorf("workflowIdTemplate: %w", err)
+	}
+	id, err := RenderID(t, wf.Variables)
+	if err != nil {
+		return "", fmt.Errorf("workflowIdTemplate: %w", err)
+	}
+	return id, nil
+}
+
+// StartReusePolicy 返回 idReusePolicy 对应的枚举
+func (wf Workflow) StartReusePolicy() (enumspb.WorkflowIdReusePolicy, error) {
+	p, err := ParseIDReusePolicy(wf.IDReusePolicy)
+	if err != nil {
+		return 0, fmt.Errorf("idReusePolicy: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,86 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"gopkg.in/yaml.v3"
+)
+
+func TestStartID(t *testing.T) {
+	var wf Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(`
+workflowIdTemplate: "order-{{.orderId}}"
+idReusePolicy: RejectDuplicate
+variables: { orderId: o-1 }
+root:
+  - noop: {}
+`), &wf))
+	require.Empty(t, wf.Check(CheckOptions{}))
+
+	id, err := wf.StartID()
+	require.NoError(t, err)
+	require.Equal(t, "order-o-1", id)
+	// 启动时的变量覆盖参与渲染
+	id, err = wf.WithVariables(map[string]any{"orderId": "o-2"}).StartID()
+	require.NoError(t, err)
+	require.Equal(t, "order-o-2", id)
+
+	p, err := wf.StartReusePolicy()
+	require.NoError(t, err)
+	require.Equal(t, enumspb.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE, p)
+
+	wf.Variables = nil
+	_, err = wf.StartID()
+	require.ErrorContains(t, err, "workflowIdTemplate")
+	wf.WorkflowIDTemplate = "{{if false}}x{{end}}"
+	_, err = wf.StartID()
+	require.ErrorContains(t, err, "empty ID")
+
+	wf.WorkflowIDTemplate = ""
+	wf.IDReusePolicy = ""
+	id, err = wf.StartID()
+	require.NoError(t, err)
+	require.Empty(t, id)
+	p, err = wf.StartReusePolicy()
+	require.NoError(t, err)
+	require.Equal(t, enumspb.WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED, p)
+}
+
+func TestCheckStartOptions(t *testing.T) {
+	wf := Workflow{
+		WorkflowIDTemplate: "order-{{.orderId",
+		IDReusePolicy:      "Sometimes",
+		Root:               []*Statement{{Noop: &Noop{}}},
+	}
+	var got []string
+	for _, i := range wf.Check(CheckOptions{}) {
+		got = append(got, i.Severity+" "+i.String())
+	}
+	require.Len(t, got, 2)
+	require.Contains(t, got[0], "error workflowIdTemplate:")
+	require.Contains(t, got[1], `error idReusePolicy: unknown policy "Sometimes"`)
+	require.Error(t, wf.validate())
+
+	wf = Workflow{WorkflowIDTemplate: "order-{{.orderId}}", Root: []*Statement{{Noop: &Noop{}}}}
+	issues := wf.Check(CheckOptions{})
+	require.Len(t, issues, 1)
+	require.Equal(t, SeverityWarning, issues[0].Severity)
+	require.Contains(t, issues[0].Message, `references "orderId", which is not in variables`)
+}
+
+func TestCheckTimeouts(t *testing.T) {
+	wf := Workflow{ExecutionTimeoutSec: -1, RunTimeoutSec: -1, Root: []*Statement{{Noop: &Noop{}}}}
+	var got []string
+	for _, i := range wf.Check(CheckOptions{}) {
+		got = append(got, i.Severity+" "+i.String())
+	}
+	require.Equal(t, []string{"error executionTimeoutSec must not be negative", "error runTimeoutSec must not be negative"}, got)
+
+	wf = Workflow{ExecutionTimeoutSec: 60, RunTimeoutSec: 120, Root: []*Statement{{Noop: &Noop{}}}}
+	issues := wf.Check(CheckOptions{})
+	require.Len(t, issues, 1)
+	require.Equal(t, SeverityWarning, issues[0].Severity)
+	require.Contains(t, issues[0].Message, "runTimeoutSec 120 exceeds executionTimeoutSec 60")
+}
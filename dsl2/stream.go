@@ -0,0 +1,76 @@
+package dsl
+
+import (
+	"fmt"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// SignalMapResult 是 Map 未指定 streamTo.signal 时推送每个元素结果所用的信号名，参数为 MapItemResult
+const SignalMapResult = "map-result"
+
+// MapStream 把 Map 每个元素完成时的结果以信号推送给另一个工作流，消费方不必等整个 Map 结束
+//
+//	streamTo: { workflowIdRef: consumerId, signal: page-ready }
+type MapStream struct {
+	WorkflowID    string `yaml:"workflowId,omitempty" json:"workflowId,omitempty"`
+	WorkflowIDRef string `yaml:"workflowIdRef,omitempty" json:"workflowIdRef,omitempty"` // 变量名，Map 开始时读取
+	Signal        string `yaml:"signal,omitempty" json:"signal,omitempty"`               // 默认 SignalMapResult
+}
+
+// MapItemResult 是推送的一个元素的结果；Value 与 collectVar 中该元素的值相同
+type MapItemResult struct {
+	Path      string `json:"path"` // Map 节点路径
+	Index     int    `json:"index"`
+	Value     any    `json:"value,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Completed int    `json:"completed"` // 含本元素在内已完成的元素数
+	Total     int    `json:"total"`
+}
+
+// mapStreamer 发送信号并记录未完成的发送；信号失败只记日志，不影响 Map
+type mapStreamer struct {
+	workflowID string
+	signal     string
+	pending    []workflow.Future
+}
+
+// newMapStreamer 解析目标工作流；未配置 streamTo 时返回 nil
+func newMapStreamer(s *MapStream, bindings map[string]any) (*mapStreamer, error) {
+	if s == nil {
+		return nil, nil
+	}
+	id := s.WorkflowID
+	if s.WorkflowIDRef != "" {
+		v, ok := bindings[s.WorkflowIDRef].(string)
+		if !ok || v == "" {
+			return nil, fmt.Errorf("map streamTo workflowIdRef %q is not a non-empty string", s.WorkflowIDRef)
+		}
+		id = v
+	}
+	signal := s.Signal
+	if signal == "" {
+		signal = SignalMapResult
+	}
+	return &mapStreamer{workflowID: id, signal: signal}, nil
+}
+
+func (s *mapStreamer) send(ctx workflow.Context, r MapItemResult) {
+	if s == nil {
+		return
+	}
+	s.pending = append(s.pending, workflow.SignalExternalWorkflow(ctx, s.workflowID, "", s.signal, r))
+}
+
+// wait 等待已发出的信号，失败的记日志
+func (s *mapStreamer) wait(ctx workflow.Context) {
+	if s == nil {
+		return
+	}
+	for _, f := range s.pending {
+		if err := f.Get(ctx, nil); err != nil {
+			workflow.GetLogger(ctx).Warn("Map result signal failed", "workflowId", s.workflowID, "signal", s.signal, "error", err)
+		}
+	}
+	s.pending = nil
+}
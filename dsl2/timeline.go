@@ -0,0 +1,275 @@
+package dsl
+
+import (
+	"strings"
+	"time"
+
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+// Timeline 是从一次执行的事件历史整理出的时间线，按节点标注，便于外部工具绘制甘特图。
+// Activity 与定时器通过其 Summary（引擎写入的节点路径）归属到节点；
+// 组合节点的时间与统计覆盖其全部子节点
+type Timeline struct {
+	WorkflowID   string             `json:"workflowId,omitempty"`
+	RunID        string             `json:"runId,omitempty"`
+	WorkflowType string             `json:"workflowType,omitempty"`
+	Status       string             `json:"status"` // running/completed/failed/canceled/terminated/timedOut/continuedAsNew
+	Start        time.Time          `json:"start"`
+	End          *time.Time         `json:"end,omitempty"`
+	DurationMs   int64              `json:"durationMs,omitempty"`
+	Events       int                `json:"events"`
+	Nodes        []TimelineNode     `json:"nodes"`
+	Activities   []TimelineActivity `json:"activities"`
+	Timers       []TimelineTimer    `json:"timers"`
+}
+
+// TimelineNode 是定义中的一个节点（路径与 BuildGraph 的节点 ID 一致）；没有 Activity/定时器的节点不带时间
+type TimelineNode struct {
+	Path        string     `json:"path"`
+	ID          string     `json:"id,omitempty"`
+	Kind        string     `json:"kind"`
+	Start       *time.Time `json:"start,omitempty"`
+	End         *time.Time `json:"end,omitempty"`
+	DurationMs  int64      `json:"durationMs,omitempty"`
+	Activities  int        `json:"activities,omitempty"`
+	Retries     int        `json:"retries,omitempty"` // 各 Activity 的尝试次数减一之和
+	Failed      int        `json:"failed,omitempty"`
+	InputBytes  int        `json:"inputBytes,omitempty"`
+	ResultBytes int        `json:"resultBytes,omitempty"`
+}
+
+// TimelineActivity 是一次 Activity 调度；QueueMs 为调度到最后一次开始的间隔（含重试等待）
+type TimelineActivity struct {
+	ActivityID  string     `json:"activityId"`
+	Type        string     `json:"type"`
+	Node        string     `json:"node,omitempty"`
+	Status      string     `json:"status"` // running/completed/failed/timedOut/canceled
+	Scheduled   time.Time  `json:"scheduled"`
+	Started     *time.Time `json:"started,omitempty"`
+	Closed      *time.Time `json:"closed,omitempty"`
+	QueueMs     int64      `json:"queueMs,omitempty"`
+	RunMs       int64      `json:"runMs,omitempty"`
+	Attempts    int        `json:"attempts,omitempty"`
+	InputBytes  int        `json:"inputBytes"`
+	ResultBytes int        `json:"resultBytes,omitempty"`
+	Failure     string     `json:"failure,omitempty"`
+}
+
+// TimelineTimer 是一个持久化定时器（wait 语句、While 的 sleepSeconds 等）
+type TimelineTimer struct {
+	TimerID string     `json:"timerId"`
+	Node    string     `json:"node,omitempty"`
+	Status  string     `json:"status"` // running/fired/canceled
+	Start   time.Time  `json:"start"`
+	End     *time.Time `json:"end,omitempty"`
+	// DurationMs 为定时器设定的时长
+	DurationMs int64 `json:"durationMs"`
+}
+
+// BuildTimeline 遍历事件历史生成时间线；dc 用于解码工作流输入与 Summary，为 nil 时使用默认转换器
+func BuildTimeline(history *historypb.History, dc converter.DataConverter) Timeline {
+	if dc == nil {
+		dc = converter.GetDefaultDataConverter()
+	}
+	tl := Timeline{Status: "running", Events: len(history.GetEvents()), Activities: []TimelineActivity{}, Timers: []TimelineTimer{}}
+	var wf Workflow
+	hasDef := false
+	activities := map[int64]int{} // ScheduledEventId -> Activities 下标
+	timers := map[int64]int{}     // StartedEventId -> Timers 下标
+	closeAt := func(ev *historypb.HistoryEvent, status string) {
+		t := ev.GetEventTime().AsTime()
+		tl.Status, tl.End = status, &t
+	}
+
+	for _, ev := range history.GetEvents() {
+		at := ev.GetEventTime().AsTime()
+		switch ev.GetEventType() {
+		case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED:
+			attrs := ev.GetWorkflowExecutionStartedEventAttributes()
+			tl.Start = at
+			tl.WorkflowType = attrs.GetWorkflowType().GetName()
+			hasDef = dc.FromPayloads(attrs.GetInput(), &wf) == nil
+		case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED:
+			closeAt(ev, "completed")
+		case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED:
+			closeAt(ev, "failed")
+		case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CANCELED:
+			closeAt(ev, "canceled")
+		case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED:
+			closeAt(ev, "terminated")
+		case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT:
+			closeAt(ev, "timedOut")
+		case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW:
+			closeAt(ev, "continuedAsNew")
+
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED:
+			attrs := ev.GetActivityTaskScheduledEventAttributes()
+			activities[ev.GetEventId()] = len(tl.Activities)
+			tl.Activities = append(tl.Activities, TimelineActivity{
+				ActivityID: attrs.GetActivityId(),
+				Type:       attrs.GetActivityType().GetName(),
+				Node:       summary(ev, dc),
+				Status:     "running",
+				Scheduled:  at,
+				InputBytes: payloadBytes(attrs.GetInput()),
+			})
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED:
+			attrs := ev.GetActivityTaskStartedEventAttributes()
+			if i, ok := activities[attrs.GetScheduledEventId()]; ok {
+				a := &tl.Activities[i]
+				a.Started, a.Attempts = &at, int(attrs.GetAttempt())
+				a.QueueMs = at.Sub(a.Scheduled).Milliseconds()
+			}
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_COMPLETED:
+			attrs := ev.GetActivityTaskCompletedEventAttributes()
+			if a := closeActivity(tl.Activities, activities, attrs.GetScheduledEventId(), at, "completed"); a != nil {
+				a.ResultBytes = payloadBytes(attrs.GetResult())
+			}
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED:
+			attrs := ev.GetActivityTaskFailedEventAttributes()
+			if a := closeActivity(tl.Activities, activities, attrs.GetScheduledEventId(), at, "failed"); a != nil {
+				a.Failure = attrs.GetFailure().GetMessage()
+			}
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_TIMED_OUT:
+			attrs := ev.GetActivityTaskTimedOutEventAttributes()
+			if a := closeActivity(tl.Activities, activities, attrs.GetScheduledEventId(), at, "timedOut"); a != nil {
+				a.Failure = attrs.GetFailure().GetMessage()
+			}
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_CANCELED:
+			closeActivity(tl.Activities, activities, ev.GetActivityTaskCanceledEventAttributes().GetScheduledEventId(), at, "canceled")
+
+		case enumspb.EVENT_TYPE_TIMER_STARTED:
+			attrs := ev.GetTimerStartedEventAttributes()
+			timers[ev.GetEventId()] = len(tl.Timers)
+			tl.Timers = append(tl.Timers, TimelineTimer{
+				TimerID:    attrs.GetTimerId(),
+				Node:       summary(ev, dc),
+				Status:     "running",
+				Start:      at,
+				DurationMs: attrs.GetStartToFireTimeout().AsDuration().Milliseconds(),
+			})
+		case enumspb.EVENT_TYPE_TIMER_FIRED:
+			if i, ok := timers[ev.GetTimerFiredEventAttributes().GetStartedEventId()]; ok {
+				tl.Timers[i].Status, tl.Timers[i].End = "fired", &at
+			}
+		case enumspb.EVENT_TYPE_TIMER_CANCELED:
+			if i, ok := timers[ev.GetTimerCanceledEventAttributes().GetStartedEventId()]; ok {
+				tl.Timers[i].Status, tl.Timers[i].End = "canceled", &at
+			}
+		}
+	}
+	if tl.End != nil {
+		tl.DurationMs = tl.End.Sub(tl.Start).Milliseconds()
+	}
+	tl.Nodes = timelineNodes(wf, hasDef, tl.Activities, tl.Timers)
+	return tl
+}
+
+func closeActivity(list []TimelineActivity, index map[int64]int, scheduledID int64, at time.Time, status string) *TimelineActivity {
+	i, ok := index[scheduledID]
+	if !ok {
+		return nil
+	}
+	a := &list[i]
+	a.Status, a.Closed = status, &at
+	if a.Started != nil {
+		a.RunMs = at.Sub(*a.Started).Milliseconds()
+	}
+	return a
+}
+
+// summary 解码事件的 Summary；引擎把节点路径写在这里，旧版本产生的历史没有
+func summary(ev *historypb.HistoryEvent, dc converter.DataConverter) string {
+	p := ev.GetUserMetadata().GetSummary()
+	if p == nil {
+		return ""
+	}
+	var s string
+	if err := dc.FromPayload(p, &s); err != nil {
+		return ""
+	}
+	return s
+}
+
+func payloadBytes(p *commonpb.Payloads) int {
+	n := 0
+	for _, x := range p.GetPayloads() {
+		n += len(x.GetData())
+	}
+	return n
+}
+
+// timelineNodes 按定义列出节点并汇总其下的 Activity 与定时器；没有定义时只列出出现过的节点路径
+func timelineNodes(wf Workflow, hasDef bool, acts []TimelineActivity, timers []TimelineTimer) []TimelineNode {
+	var nodes []TimelineNode
+	if hasDef {
+		var add func(path string, st *Statement)
+		add = func(path string, st *Statement) {
+			if st == nil {
+				return
+			}
+			nodes = append(nodes, TimelineNode{Path: path, ID: st.ID, Kind: st.Kind()})
+			for _, c := range st.children() {
+				add(path+"."+c.rel, c.stmt)
+			}
+		}
+		for i, st := range wf.Root {
+			add(rootPath(i), st)
+		}
+		for i, st := range wf.OnCancel {
+			add(onCancelPath(i), st)
+		}
+	} else {
+		seen := map[string]bool{}
+		for _, a := range acts {
+			if a.Node != "" && !seen[a.Node] {
+				seen[a.Node] = true
+				nodes = append(nodes, TimelineNode{Path: a.Node, Kind: KindActivity})
+			}
+		}
+	}
+
+	under := func(node, path string) bool {
+		return path == node || strings.HasPrefix(path, node+".")
+	}
+	span := func(n *TimelineNode, start time.Time, end *time.Time) {
+		if n.Start == nil || start.Before(*n.Start) {
+			n.Start = &start
+		}
+		if end != nil && (n.End == nil || end.After(*n.End)) {
+			n.End = end
+		}
+	}
+	for i := range nodes {
+		n := &nodes[i]
+		for _, a := range acts {
+			if a.Node == "" || !under(n.Path, a.Node) {
+				continue
+			}
+			span(n, a.Scheduled, a.Closed)
+			n.Activities++
+			n.Retries += max(a.Attempts-1, 0)
+			n.InputBytes += a.InputBytes
+			n.ResultBytes += a.ResultBytes
+			if a.Status == "failed" || a.Status == "timedOut" {
+				n.Failed++
+			}
+		}
+		for _, t := range timers {
+			if t.Node != "" && under(n.Path, t.Node) {
+				span(n, t.Start, t.End)
+			}
+		}
+		if n.Start != nil && n.End != nil {
+			n.DurationMs = n.End.Sub(*n.Start).Milliseconds()
+		}
+	}
+	if nodes == nil {
+		nodes = []TimelineNode{}
+	}
+	return nodes
+}
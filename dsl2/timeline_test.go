@@ -0,0 +1,127 @@
+package dsl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	sdkpb "go.temporal.io/api/sdk/v1"
+	"go.temporal.io/sdk/converter"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	var wf Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(`
+taskQueue: demo
+root:
+  - activity: { name: DoA, args: [{ int: 1 }], result: a }
+  - id: fanout
+    parallel:
+      - activity: { name: DoB, args: [{ int: 2 }], result: b }
+      - wait: { seconds: 5 }
+`), &wf))
+	dc := converter.GetDefaultDataConverter()
+	payloads := func(v any) *commonpb.Payloads {
+		p, err := dc.ToPayloads(v)
+		require.NoError(t, err)
+		return p
+	}
+	meta := func(path string) *sdkpb.UserMetadata {
+		p, err := dc.ToPayload(path)
+		require.NoError(t, err)
+		return &sdkpb.UserMetadata{Summary: p}
+	}
+	t0 := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) *timestamppb.Timestamp {
+		return timestamppb.New(t0.Add(time.Duration(ms) * time.Millisecond))
+	}
+
+	var events []*historypb.HistoryEvent
+	add := func(ms int, typ enumspb.EventType, md *sdkpb.UserMetadata, attrs func(ev *historypb.HistoryEvent)) {
+		ev := &historypb.HistoryEvent{EventId: int64(len(events) + 1), EventTime: at(ms), EventType: typ, UserMetadata: md}
+		attrs(ev)
+		events = append(events, ev)
+	}
+	add(0, enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED, nil, func(ev *historypb.HistoryEvent) {
+		ev.Attributes = &historypb.HistoryEvent_WorkflowExecutionStartedEventAttributes{WorkflowExecutionStartedEventAttributes: &historypb.WorkflowExecutionStartedEventAttributes{
+			WorkflowType: &commonpb.WorkflowType{Name: WorkflowType}, Input: payloads(wf)}}
+	})
+	add(10, enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED, meta("root[0]"), func(ev *historypb.HistoryEvent) {
+		ev.Attributes = &historypb.HistoryEvent_ActivityTaskScheduledEventAttributes{ActivityTaskScheduledEventAttributes: &historypb.ActivityTaskScheduledEventAttributes{
+			ActivityId: "5", ActivityType: &commonpb.ActivityType{Name: "DoA"}, Input: payloads(1)}}
+	})
+	add(2010, enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED, nil, func(ev *historypb.HistoryEvent) {
+		ev.Attributes = &historypb.HistoryEvent_ActivityTaskStartedEventAttributes{ActivityTaskStartedEventAttributes: &historypb.ActivityTaskStartedEventAttributes{
+			ScheduledEventId: 2, Attempt: 3}}
+	})
+	add(2110, enumspb.EVENT_TYPE_ACTIVITY_TASK_COMPLETED, nil, func(ev *historypb.HistoryEvent) {
+		ev.Attributes = &historypb.HistoryEvent_ActivityTaskCompletedEventAttributes{ActivityTaskCompletedEventAttributes: &historypb.ActivityTaskCompletedEventAttributes{
+			ScheduledEventId: 2, Result: payloads("A:1")}}
+	})
+	add(2200, enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED, meta("root[1].parallel[0]"), func(ev *historypb.HistoryEvent) {
+		ev.Attributes = &historypb.HistoryEvent_ActivityTaskScheduledEventAttributes{ActivityTaskScheduledEventAttributes: &historypb.ActivityTaskScheduledEventAttributes{
+			ActivityId: "9", ActivityType: &commonpb.ActivityType{Name: "DoB"}, Input: payloads(2)}}
+	})
+	add(2200, enumspb.EVENT_TYPE_TIMER_STARTED, meta("root[1].parallel[1]"), func(ev *historypb.HistoryEvent) {
+		ev.Attributes = &historypb.HistoryEvent_TimerStartedEventAttributes{TimerStartedEventAttributes: &historypb.TimerStartedEventAttributes{
+			TimerId: "10", StartToFireTimeout: durationpb.New(5 * time.Second)}}
+	})
+	add(2300, enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED, nil, func(ev *historypb.HistoryEvent) {
+		ev.Attributes = &historypb.HistoryEvent_ActivityTaskStartedEventAttributes{ActivityTaskStartedEventAttributes: &historypb.ActivityTaskStartedEventAttributes{
+			ScheduledEventId: 5, Attempt: 1}}
+	})
+	add(2400, enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED, nil, func(ev *historypb.HistoryEvent) {
+		ev.Attributes = &historypb.HistoryEvent_ActivityTaskFailedEventAttributes{ActivityTaskFailedEventAttributes: &historypb.ActivityTaskFailedEventAttributes{
+			ScheduledEventId: 5, Failure: &failurepb.Failure{Message: "boom"}}}
+	})
+	add(7200, enumspb.EVENT_TYPE_TIMER_FIRED, nil, func(ev *historypb.HistoryEvent) {
+		ev.Attributes = &historypb.HistoryEvent_TimerFiredEventAttributes{TimerFiredEventAttributes: &historypb.TimerFiredEventAttributes{
+			TimerId: "10", StartedEventId: 6}}
+	})
+	add(7300, enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED, nil, func(ev *historypb.HistoryEvent) {
+		ev.Attributes = &historypb.HistoryEvent_WorkflowExecutionFailedEventAttributes{WorkflowExecutionFailedEventAttributes: &historypb.WorkflowExecutionFailedEventAttributes{}}
+	})
+
+	tl := BuildTimeline(&historypb.History{Events: events}, nil)
+	require.Equal(t, "failed", tl.Status)
+	require.Equal(t, WorkflowType, tl.WorkflowType)
+	require.EqualValues(t, 7300, tl.DurationMs)
+	require.Equal(t, 10, tl.Events)
+
+	require.Len(t, tl.Activities, 2)
+	a := tl.Activities[0]
+	require.Equal(t, "root[0]", a.Node)
+	require.Equal(t, "completed", a.Status)
+	require.Equal(t, 3, a.Attempts)
+	require.EqualValues(t, 2000, a.QueueMs)
+	require.EqualValues(t, 100, a.RunMs)
+	require.Equal(t, len("1"), a.InputBytes)
+	require.Equal(t, len(`"A:1"`), a.ResultBytes)
+	require.Equal(t, "boom", tl.Activities[1].Failure)
+	require.Equal(t, []TimelineTimer{{TimerID: "10", Node: "root[1].parallel[1]", Status: "fired",
+		Start: t0.Add(2200 * time.Millisecond), End: ptr(t0.Add(7200 * time.Millisecond)), DurationMs: 5000}}, tl.Timers)
+
+	byPath := map[string]TimelineNode{}
+	for _, n := range tl.Nodes {
+		byPath[n.Path] = n
+	}
+	require.Len(t, tl.Nodes, 4)
+	require.Equal(t, 2, byPath["root[0]"].Retries)
+	require.EqualValues(t, 2100, byPath["root[0]"].DurationMs)
+	fanout := byPath["root[1]"]
+	require.Equal(t, "fanout", fanout.ID)
+	require.Equal(t, KindParallel, fanout.Kind)
+	require.Equal(t, 1, fanout.Activities)
+	require.Equal(t, 1, fanout.Failed)
+	require.EqualValues(t, 5000, fanout.DurationMs) // 覆盖 Activity 与定时器
+	require.EqualValues(t, 5000, byPath["root[1].parallel[1]"].DurationMs)
+}
+
+func ptr[T any](v T) *T { return &v }
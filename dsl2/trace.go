@@ -0,0 +1,120 @@
+package dsl
+
+import (
+	"errors"
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// QueryTrace 返回节点执行轨迹（[]TraceEvent）
+const QueryTrace = "trace"
+
+// 节点执行状态
+const (
+	TraceStarted   = "started"
+	TraceCompleted = "completed"
+	TraceFailed    = "failed"
+	TraceSkipped   = "skipped" // when 条件为假，节点未执行；只有这一个事件
+)
+
+// TraceEvent 记录一个节点的一次状态变化；Path 与 BuildGraph 生成的节点 ID 一致，
+// Map/While 的 Body 每次执行都会产生一组事件。结束事件通过 StartSeq 关联对应的开始事件
+// （并发的 Map 迭代共享同一 Path）
+type TraceEvent struct {
+	Seq        int       `json:"seq"`
+	Path       string    `json:"path"`
+	ID         string    `json:"id,omitempty"` // Statement.ID
+	Kind       string    `json:"kind"`
+	Status     string    `json:"status"`
+	Time       time.Time `json:"time"`
+	StartSeq   int       `json:"startSeq,omitempty"`
+	DurationMs int64     `json:"durationMs,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	// Stack 是失败事件从根语句到出错节点的错误栈，与 NodeFailure.Stack 相同
+	Stack []string `json:"stack,omitempty"`
+	// Reason 是跳过的原因，如 "when approved is false"
+	Reason string `json:"reason,omitempty"`
+}
+
+type tracer struct {
+	events []TraceEvent
+}
+
+// start 记录节点开始，返回的事件传给 finish 以计算耗时
+func (t *tracer) start(ctx workflow.Context, s *Statement) TraceEvent {
+	ev := TraceEvent{
+		Seq:    len(t.events) + 1,
+		Path:   pathFrom(ctx),
+		ID:     s.ID,
+		Kind:   s.Kind(),
+		Status: TraceStarted,
+		Time:   workflow.Now(ctx),
+	}
+	t.events = append(t.events, ev)
+	return ev
+}
+
+func (t *tracer) finish(ctx workflow.Context, started TraceEvent, err error) {
+	ev := started
+	ev.Seq = len(t.events) + 1
+	ev.Status = TraceCompleted
+	ev.Time = workflow.Now(ctx)
+	ev.StartSeq = started.Seq
+	ev.DurationMs = ev.Time.Sub(started.Time).Milliseconds()
+	if err != nil {
+		ev.Status = TraceFailed
+		ev.Error = err.Error()
+		var ne *nodeError
+		if errors.As(err, &ne) {
+			ev.Stack = ne.Stack
+		}
+	}
+	t.events = append(t.events, ev)
+}
+
+// skip 记录节点因 when 条件被跳过
+func (t *tracer) skip(ctx workflow.Context, s *Statement, reason string) {
+	t.events = append(t.events, TraceEvent{
+		Seq:    len(t.events) + 1,
+		Path:   pathFrom(ctx),
+		ID:     s.ID,
+		Kind:   s.Kind(),
+		Status: TraceSkipped,
+		Time:   workflow.Now(ctx),
+		Reason: reason,
+	})
+}
+
+type tracerKey struct{}
+type pathKey struct{}
+
+// withTracer 在 ctx 中挂载轨迹记录器并注册 QueryTrace
+func withTracer(ctx workflow.Context) (workflow.Context, error) {
+	t := &tracer{}
+	if err := workflow.SetQueryHandler(ctx, QueryTrace, func() ([]TraceEvent, error) {
+		return t.events, nil
+	}); err != nil {
+		return ctx, err
+	}
+	return workflow.WithValue(ctx, tracerKey{}, t), nil
+}
+
+func tracerFrom(ctx workflow.Context) *tracer {
+	t, _ := ctx.Value(tracerKey{}).(*tracer)
+	return t
+}
+
+// withPath 记录当前语句的路径；子语句路径为 父路径 + "." + rel
+func withPath(ctx workflow.Context, path string) workflow.Context {
+	return workflow.WithValue(ctx, pathKey{}, path)
+}
+
+func withChildPath(ctx workflow.Context, rel string) workflow.Context {
+	return withPath(ctx, pathFrom(ctx)+"."+rel)
+}
+
+func pathFrom(ctx workflow.Context) string {
+	p, _ := ctx.Value(pathKey{}).(string)
+	return p
+}
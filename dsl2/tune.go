@@ -0,0 +1,151 @@
+package dsl
+
+import (
+	"fmt"
+	"slices"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// UpdateTune 是调整运行中 Map 节点并发窗口与速率的 Update（TuneRequest -> []MapTuning），
+// 用于在下游被压垮时限流而不取消工作流；调整只作用于当前正在执行的 Map
+const UpdateTune = "tune"
+
+// TuneRequest 是 UpdateTune 的参数；各字段为零值时保持不变，全部为空时只返回当前状态
+type TuneRequest struct {
+	// Path 为 Map 节点路径（与 BuildGraph 的节点 ID 一致），为空时作用于全部运行中的 Map
+	Path        string `json:"path,omitempty"`
+	Concurrency int    `json:"concurrency,omitempty"` // 新的并发窗口；已在执行的迭代不受影响
+	// RatePerMinute 为每分钟最多启动的迭代数；0 取消限制，不填表示不变
+	RatePerMinute *int `json:"ratePerMinute,omitempty"`
+}
+
+// MapTuning 是一个运行中 Map 的窗口与进度
+type MapTuning struct {
+	Path          string `json:"path"`
+	Concurrency   int    `json:"concurrency"`
+	RatePerMinute int    `json:"ratePerMinute,omitempty"`
+	Inflight      int    `json:"inflight"`
+	Remaining     int    `json:"remaining"` // 尚未启动的元素数
+}
+
+// mapRun 是一次 Map 执行的可调参数与进度；wake 通知调度循环重新计算窗口
+type mapRun struct {
+	path     string
+	window   int
+	rate     int
+	inflight int
+	next     int
+	total    int
+	wake     workflow.Channel
+	// collectVar 非空时 collected 按元素序号记录已完成元素的收集值，供 QueryBindings 提前返回
+	collectVar string
+	collected  map[int]any
+}
+
+// collect 记录一个元素的收集值；key 为空表示该元素没有可收集的变量
+func (r *mapRun) collect(idx int, key string, v any) {
+	if r.collected != nil && key != "" {
+		r.collected[idx] = v
+	}
+}
+
+// partial 按元素顺序返回已收集的值，与 Map 结束时写入 collectVar 的列表形式相同
+func (r *mapRun) partial() []any {
+	idxs := make([]int, 0, len(r.collected))
+	for i := range r.collected {
+		idxs = append(idxs, i)
+	}
+	slices.Sort(idxs)
+	out := make([]any, 0, len(idxs))
+	for _, i := range idxs {
+		if v := r.collected[i]; v != nil {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (r *mapRun) tuning() MapTuning {
+	return MapTuning{Path: r.path, Concurrency: r.window, RatePerMinute: r.rate, Inflight: r.inflight, Remaining: r.total - r.next}
+}
+
+type mapRegistry struct {
+	runs []*mapRun
+}
+
+type mapRegistryKey struct{}
+
+// withMapRegistry 在 ctx 中挂载运行中 Map 的登记表并注册 UpdateTune
+func withMapRegistry(ctx workflow.Context) (workflow.Context, error) {
+	reg := &mapRegistry{}
+	err := workflow.SetUpdateHandlerWithOptions(ctx, UpdateTune,
+		func(ctx workflow.Context, req TuneRequest) ([]MapTuning, error) {
+			out := []MapTuning{}
+			for _, r := range reg.match(req.Path) {
+				if req.Concurrency > 0 {
+					r.window = req.Concurrency
+				}
+				if req.RatePerMinute != nil {
+					r.rate = *req.RatePerMinute
+				}
+				r.wake.SendAsync(struct{}{})
+				out = append(out, r.tuning())
+			}
+			return out, nil
+		},
+		workflow.UpdateHandlerOptions{Validator: func(ctx workflow.Context, req TuneRequest) error {
+			if req.Concurrency < 0 {
+				return fmt.Errorf("concurrency must not be negative")
+			}
+			if req.RatePerMinute != nil && *req.RatePerMinute < 0 {
+				return fmt.Errorf("ratePerMinute must not be negative")
+			}
+			if len(reg.match(req.Path)) == 0 {
+				if req.Path == "" {
+					return fmt.Errorf("no map is running")
+				}
+				return fmt.Errorf("map %q is not running", req.Path)
+			}
+			return nil
+		}})
+	if err != nil {
+		return ctx, err
+	}
+	return workflow.WithValue(ctx, mapRegistryKey{}, reg), nil
+}
+
+// overlay 把运行中 Map 的部分收集结果写入变量快照 cp
+func (reg *mapRegistry) overlay(cp map[string]any) {
+	if reg == nil {
+		return
+	}
+	for _, r := range reg.runs {
+		if r.collectVar != "" {
+			cp[r.collectVar] = r.partial()
+		}
+	}
+}
+
+func (reg *mapRegistry) match(path string) []*mapRun {
+	var out []*mapRun
+	for _, r := range reg.runs {
+		if path == "" || r.path == path {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// startMapRun 登记一次 Map 执行；返回的函数在结束时注销
+func startMapRun(ctx workflow.Context, window, rate, total int) (*mapRun, func()) {
+	r := &mapRun{path: pathFrom(ctx), window: window, rate: rate, total: total, wake: workflow.NewBufferedChannel(ctx, 1)}
+	reg, _ := ctx.Value(mapRegistryKey{}).(*mapRegistry)
+	if reg == nil {
+		return r, func() {}
+	}
+	reg.runs = append(reg.runs, r)
+	return r, func() {
+		reg.runs = slices.DeleteFunc(reg.runs, func(x *mapRun) bool { return x == r })
+	}
+}
@@ -0,0 +1,134 @@
+package dsl
+
+import "fmt"
+
+// 节点类型名，用于图、路径、轨迹等展示
+const (
+	KindActivity  = "activity"
+	KindParallel  = "parallel"
+	KindMap       = "map"
+	KindWhile     = "while"
+	KindIf        = "if"
+	KindSession   = "session"
+	KindMarker    = "marker"
+	KindLog       = "log"
+	KindNoop      = "noop"
+	KindAppend    = "append"
+	KindMerge     = "merge"
+	KindUnset     = "unset"
+	KindIncrement = "increment"
+	KindRandom    = "random"
+	KindNow       = "now"
+	KindNewID     = "newId"
+	KindAggregate = "aggregate"
+	KindSet       = "set"
+	KindWait      = "wait"
+)
+
+// Kind 返回语句的节点类型；无效语句返回空串
+func (s *Statement) Kind() string {
+	switch {
+	case s == nil:
+		return ""
+	case s.Activity != nil:
+		return KindActivity
+	case s.Parallel != nil:
+		return KindParallel
+	case s.Map != nil:
+		return KindMap
+	case s.While != nil:
+		return KindWhile
+	case s.If != nil:
+		return KindIf
+	case s.Session != nil:
+		return KindSession
+	case s.Marker != nil:
+		return KindMarker
+	case s.Log != nil:
+		return KindLog
+	case s.Noop != nil:
+		return KindNoop
+	case s.Append != nil:
+		return KindAppend
+	case s.Merge != nil:
+		return KindMerge
+	case s.Unset != nil:
+		return KindUnset
+	case s.Increment != nil:
+		return KindIncrement
+	case s.Random != nil:
+		return KindRandom
+	case s.Now != nil:
+		return KindNow
+	case s.NewID != nil:
+		return KindNewID
+	case s.Aggregate != nil:
+		return KindAggregate
+	case s.Set != nil:
+		return KindSet
+	case s.Wait != nil:
+		return KindWait
+	}
+	return ""
+}
+
+// childStmt 是组合节点的一个直接子语句
+type childStmt struct {
+	stmt  *Statement
+	rel   string // 相对父节点的路径片段，如 "parallel[0]"、"map.body"
+	edge  string // 与父节点的关系：branch/body/then/else/step
+	index int    // parallel 分支序号或 session 步骤序号
+}
+
+func (s *Statement) children() []childStmt {
+	var out []childStmt
+	switch {
+	case s.Parallel != nil:
+		for i, b := range *s.Parallel {
+			out = append(out, childStmt{stmt: b, rel: fmt.Sprintf("parallel[%d]", i), edge: "branch", index: i})
+		}
+	case s.Map != nil:
+		out = append(out, childStmt{stmt: s.Map.Body, rel: "map.body", edge: "body"})
+	case s.While != nil:
+		out = append(out, childStmt{stmt: s.While.Body, rel: "while.body", edge: "body"})
+	case s.If != nil:
+		out = append(out, childStmt{stmt: s.If.Then, rel: "if.then", edge: "then"})
+		if s.If.Else != nil {
+			out = append(out, childStmt{stmt: s.If.Else, rel: "if.else", edge: "else"})
+		}
+	case s.Session != nil:
+		for i, st := range s.Session.Steps {
+			out = append(out, childStmt{stmt: st, rel: fmt.Sprintf("session.steps[%d]", i), edge: "step", index: i})
+		}
+	}
+	return out
+}
+
+// rootPath 返回根语句的路径，如 "root[0]"
+func rootPath(i int) string {
+	return fmt.Sprintf("root[%d]", i)
+}
+
+// onCancelPath 返回 onCancel 中语句的路径，如 "onCancel[0]"
+func onCancelPath(i int) string {
+	return fmt.Sprintf("onCancel[%d]", i)
+}
+
+// nodePaths 按深度优先顺序返回定义中全部节点的路径（与 BuildGraph 的节点 ID 一致，不含 start/end）
+func (wf Workflow) nodePaths() []string {
+	var out []string
+	var add func(path string, st *Statement)
+	add = func(path string, st *Statement) {
+		if st == nil {
+			return
+		}
+		out = append(out, path)
+		for _, c := range st.children() {
+			add(path+"."+c.rel, c.stmt)
+		}
+	}
+	for i, st := range wf.Root {
+		add(rootPath(i), st)
+	}
+	return out
+}
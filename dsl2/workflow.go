@@ -4,12 +4,16 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"reflect"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
+
+	"github.com/temporalio/samples-go/dsl2/internal/jsonschema"
 )
 
 /*
@@ -18,25 +22,65 @@ import (
 
 // Workflow 是整张编排图
 type Workflow struct {
-	Version    string         `yaml:"version,omitempty"`
-	TaskQueue  string         `yaml:"taskQueue,omitempty"`
-	Variables  map[string]any `yaml:"variables,omitempty"`  // 初始变量
-	Root       []*Statement   `yaml:"root"`                 // 入口 - 默认顺序执行的语句数组
-	Retry      *RetryPolicy   `yaml:"retry,omitempty"`      // 可选：全局默认重试
-	TimeoutSec int            `yaml:"timeoutSec,omitempty"` // 可选：全局默认超时
+	Version    string         `yaml:"version,omitempty" json:"version,omitempty"`
+	TaskQueue  string         `yaml:"taskQueue,omitempty" json:"taskQueue,omitempty"`
+	Variables  map[string]any `yaml:"variables,omitempty" json:"variables,omitempty"`   // 初始变量
+	Root       []*Statement   `yaml:"root" json:"root"`                                 // 入口 - 默认顺序执行的语句数组
+	Retry      *RetryPolicy   `yaml:"retry,omitempty" json:"retry,omitempty"`           // 可选：全局默认重试
+	TimeoutSec int            `yaml:"timeoutSec,omitempty" json:"timeoutSec,omitempty"` // 可选：全局默认超时
 	// Concurrency: 作为 Map 的默认并发窗口（可被 Map 节点覆盖）
-	Concurrency int `yaml:"concurrency,omitempty"`
+	Concurrency int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+	// OnCancel: 可选，执行收到取消请求后在不受取消影响的 context 中顺序执行（释放预留、通知调用方等），之后工作流才报告取消
+	OnCancel []*Statement `yaml:"onCancel,omitempty" json:"onCancel,omitempty"`
+	// Entrypoints: 可选，同一文件中的多个具名入口（如 create/cancel/refund），共享变量与其余设置；
+	// 启动方用 SelectEntry 选择其一作为 root，见 entry.go
+	Entrypoints map[string][]*Statement `yaml:"entrypoints,omitempty" json:"entrypoints,omitempty"`
+	// StartDelaySec: 可选，启动方据此设置 StartWorkflowOptions.StartDelay，延迟到指定秒数后才开始执行
+	StartDelaySec int `yaml:"startDelaySec,omitempty" json:"startDelaySec,omitempty"`
+	// ExecutionTimeoutSec/RunTimeoutSec: 可选，启动方据此设置 WorkflowExecutionTimeout/WorkflowRunTimeout，
+	// 由服务端限制整个执行（含 ContinueAsNew 之后的各次运行）与单次运行的存活时间；0 为不限
+	ExecutionTimeoutSec int `yaml:"executionTimeoutSec,omitempty" json:"executionTimeoutSec,omitempty"`
+	RunTimeoutSec       int `yaml:"runTimeoutSec,omitempty" json:"runTimeoutSec,omitempty"`
+	// WorkflowIDTemplate: 可选，启动方用合并后的变量渲染 Workflow ID（如 order-{{.orderId}}），同一业务实体重复提交得到同一 ID
+	WorkflowIDTemplate string `yaml:"workflowIdTemplate,omitempty" json:"workflowIdTemplate,omitempty"`
+	// IDReusePolicy: 可选，同 ID 已有执行时的处理：AllowDuplicate/AllowDuplicateFailedOnly/RejectDuplicate/TerminateIfRunning
+	IDReusePolicy string `yaml:"idReusePolicy,omitempty" json:"idReusePolicy,omitempty"`
+	// Breakpoints: 可选，调试断点（Statement.ID 或节点路径），执行到这些节点前停下，见 debug.go
+	Breakpoints []string `yaml:"breakpoints,omitempty" json:"breakpoints,omitempty"`
+	// OutputSchema: 可选，JSON Schema（支持的子集见 internal/jsonschema），返回前校验最终变量，不符时工作流失败
+	OutputSchema map[string]any `yaml:"outputSchema,omitempty" json:"outputSchema,omitempty"`
+	// Resume: 由引擎在 ContinueAsNew 时设置，新的执行从其中的恢复点继续，见 carryover.go
+	Resume *State `yaml:"-" json:"resume,omitempty"`
+	// Entry: 由 SelectEntry 设置，记录本次执行所选的入口
+	Entry string `yaml:"-" json:"entry,omitempty"`
 }
 
-// Statement：一个节点，要么是 Activity，要么是组合（Parallel/Map/While/If）
+// Statement：一个节点，要么是 Activity/Marker/Log/Noop/Random/Now/NewID/Wait 或变量修改（Append/Merge/Unset/
+// Increment/Aggregate/Set），要么是组合（Parallel/Map/While/If/Session）
 // 注意：移除了 Sequence，因为默认就是顺序执行
 type Statement struct {
-	ID       string              `yaml:"id,omitempty"` // 可选：便于日志/排障
-	Activity *ActivityInvocation `yaml:"activity,omitempty"`
-	Parallel *Parallel           `yaml:"parallel,omitempty"`
-	Map      *Map                `yaml:"map,omitempty"`
-	While    *While              `yaml:"while,omitempty"`
-	If       *If                 `yaml:"if,omitempty"`
+	ID        string              `yaml:"id,omitempty" json:"id,omitempty"` // 可选：便于日志/排障
+	Activity  *ActivityInvocation `yaml:"activity,omitempty" json:"activity,omitempty"`
+	Parallel  *Parallel           `yaml:"parallel,omitempty" json:"parallel,omitempty"`
+	Map       *Map                `yaml:"map,omitempty" json:"map,omitempty"`
+	While     *While              `yaml:"while,omitempty" json:"while,omitempty"`
+	If        *If                 `yaml:"if,omitempty" json:"if,omitempty"`
+	Session   *Session            `yaml:"session,omitempty" json:"session,omitempty"`
+	Marker    *Marker             `yaml:"marker,omitempty" json:"marker,omitempty"`
+	Log       *Log                `yaml:"log,omitempty" json:"log,omitempty"`
+	Noop      *Noop               `yaml:"noop,omitempty" json:"noop,omitempty"`
+	Append    *Append             `yaml:"append,omitempty" json:"append,omitempty"`
+	Merge     *Merge              `yaml:"merge,omitempty" json:"merge,omitempty"`
+	Unset     *Unset              `yaml:"unset,omitempty" json:"unset,omitempty"`
+	Increment *Increment          `yaml:"increment,omitempty" json:"increment,omitempty"`
+	Random    *Random             `yaml:"random,omitempty" json:"random,omitempty"`
+	Now       *Now                `yaml:"now,omitempty" json:"now,omitempty"`
+	NewID     *NewID              `yaml:"newId,omitempty" json:"newId,omitempty"`
+	Aggregate *Aggregate          `yaml:"aggregate,omitempty" json:"aggregate,omitempty"`
+	Set       *Set                `yaml:"set,omitempty" json:"set,omitempty"`
+	Wait      *Wait               `yaml:"wait,omitempty" json:"wait,omitempty"`
+	// When: 可选，适用于任意语句；为假时跳过本节点，轨迹中记为 skipped 并附原因
+	When *Cond `yaml:"when,omitempty" json:"when,omitempty"`
 }
 
 // 并行 - 直接是Statement数组，与根级别保持一致
@@ -44,78 +88,229 @@ type Parallel []*Statement
 
 // 集合并行（对 items 做并发执行 Body）
 type Map struct {
-	ItemsRef    string     `yaml:"itemsRef"`              // 变量名：[]any / []T
-	ItemVar     string     `yaml:"itemVar,omitempty"`     // Body 中当前元素变量名，默认 "_item"
-	Concurrency int        `yaml:"concurrency,omitempty"` // 并发窗口；0 则用 Workflow.Concurrency；<=0 视作 1
-	Body        *Statement `yaml:"body"`
-	CollectVar  string     `yaml:"collectVar,omitempty"` // 可选：收集 Body 产生的某些变量（见注释）
-	FailFast    bool       `yaml:"failFast,omitempty"`
+	ItemsRef    string     `yaml:"itemsRef" json:"itemsRef"`                           // 变量名：[]any / []T
+	ItemVar     string     `yaml:"itemVar,omitempty" json:"itemVar,omitempty"`         // Body 中当前元素变量名，默认 "_item"
+	Concurrency int        `yaml:"concurrency,omitempty" json:"concurrency,omitempty"` // 并发窗口；0 则用 Workflow.Concurrency；<=0 视作 1
+	Body        *Statement `yaml:"body" json:"body"`
+	CollectVar  string     `yaml:"collectVar,omitempty" json:"collectVar,omitempty"` // 可选：收集 Body 产生的某些变量（见注释）
+	FailFast    bool       `yaml:"failFast,omitempty" json:"failFast,omitempty"`
+	// RatePerMinute 限制每分钟最多启动的迭代数（0 表示不限制）；运行中可通过 UpdateTune 与并发窗口一起调整
+	RatePerMinute int `yaml:"ratePerMinute,omitempty" json:"ratePerMinute,omitempty"`
+	// StreamTo 可选：每个元素完成时把结果以信号推送给另一个工作流
+	StreamTo *MapStream `yaml:"streamTo,omitempty" json:"streamTo,omitempty"`
 }
 
 // 条件分支
 type If struct {
-	Cond Cond       `yaml:"cond"`           // 条件表达式
-	Then *Statement `yaml:"then"`           // 条件为真时执行的语句
-	Else *Statement `yaml:"else,omitempty"` // 可选：条件为假时执行的语句
+	Cond Cond       `yaml:"cond" json:"cond"`                     // 条件表达式
+	Then *Statement `yaml:"then" json:"then"`                     // 条件为真时执行的语句
+	Else *Statement `yaml:"else,omitempty" json:"else,omitempty"` // 可选：条件为假时执行的语句
 }
 
 // 条件循环
 type While struct {
-	Cond         Cond       `yaml:"cond"` // 条件只依赖变量
-	Body         *Statement `yaml:"body"`
-	MaxIters     int        `yaml:"maxIters,omitempty"`     // 安全上限（0 表示不限制）
-	SleepSeconds int        `yaml:"sleepSeconds,omitempty"` // 每轮之间 Sleep，避免忙等
-	// ContinueEvery int        `yaml:"continueEvery,omitempty"` // 可选：每 N 轮 ContinueAsNew（实际环境再打开）
+	Cond         Cond       `yaml:"cond" json:"cond"` // 条件只依赖变量
+	Body         *Statement `yaml:"body" json:"body"`
+	MaxIters     int        `yaml:"maxIters,omitempty" json:"maxIters,omitempty"`         // 安全上限（0 表示不限制）
+	SleepSeconds int        `yaml:"sleepSeconds,omitempty" json:"sleepSeconds,omitempty"` // 每轮之间 Sleep，避免忙等
+	// ContinueEvery int        `yaml:"continueEvery,omitempty" json:"continueEvery,omitempty"` // 可选：每 N 轮 ContinueAsNew（实际环境再打开）
+}
+
+// 会话：按顺序执行 Steps，其中的 Activity 都调度到同一个 worker 主机（需要 worker 开启 enableSessions），
+// 用于前一步在本地磁盘产生文件、后一步读取的场景
+type Session struct {
+	Steps               []*Statement `yaml:"steps" json:"steps"`
+	CreationTimeoutSec  int          `yaml:"creationTimeoutSec,omitempty" json:"creationTimeoutSec,omitempty"`   // 等待 worker 接受会话的时间，默认 60
+	ExecutionTimeoutSec int          `yaml:"executionTimeoutSec,omitempty" json:"executionTimeoutSec,omitempty"` // 会话最长存续时间，默认 3600
+}
+
+// 标记：把具名检查点与所选变量的当前值写入工作流历史（SideEffect 产生的 MarkerRecorded 事件），
+// 审计时可直接在 Temporal 事件历史中看到 "payment captured" 之类的关键节点
+type Marker struct {
+	Name string   `yaml:"name" json:"name"`
+	Refs []string `yaml:"refs,omitempty" json:"refs,omitempty"` // 一并记录的变量名
+}
+
+// MarkerRecord 是 Marker 写入历史的内容
+type MarkerRecord struct {
+	Name   string         `json:"name"`
+	Path   string         `json:"path"`
+	Values map[string]any `json:"values,omitempty"`
+}
+
+// 空语句：校验与执行都什么也不做；设计时作占位，测试时把语句原样挪到 Disabled 下即可临时关闭，
+// Disabled 中的语句既不执行也不参与校验
+type Noop struct {
+	Description string     `yaml:"description,omitempty" json:"description,omitempty"`
+	Disabled    *Statement `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// 追加：把 Value 追加到列表变量 To 的末尾，变量不存在时新建列表
+type Append struct {
+	To    string `yaml:"to" json:"to"`
+	Value Value  `yaml:"value" json:"value"`
+}
+
+// 合并：把 Value 引用的对象深度合并进对象变量 Into，变量不存在时新建；
+// 同名键两侧都是对象时递归合并，否则以 Value 为准
+type Merge struct {
+	Into  string `yaml:"into" json:"into"`
+	Value Value  `yaml:"value" json:"value"`
+}
+
+// 赋值：把 Value（字面量、引用或函数结果）写入变量 Var
+type Set struct {
+	Var   string `yaml:"var" json:"var"`
+	Value Value  `yaml:"value" json:"value"`
+}
+
+// 删除变量
+type Unset struct {
+	Vars []string `yaml:"vars" json:"vars"`
+}
+
+// 计数：把数值变量 Var 加上 By（默认 1，可为负数或引用），结果按 Min/Max 截断；变量不存在时从 0 开始。
+// 读取、计算与写回在同一步完成，用于 While 中的重试计数与分页游标
+type Increment struct {
+	Var string   `yaml:"var" json:"var"`
+	By  *Value   `yaml:"by,omitempty" json:"by,omitempty"`
+	Min *float64 `yaml:"min,omitempty" json:"min,omitempty"`
+	Max *float64 `yaml:"max,omitempty" json:"max,omitempty"`
+}
+
+// 聚合：对列表变量 From 计算 sum/avg/min/max/count，结果写入 Result；
+// 元素为对象时用 Field 取其中的数值字段。空列表的 sum/count 为 0，avg/min/max 为 null
+type Aggregate struct {
+	Op     string `yaml:"op" json:"op"`
+	From   string `yaml:"from" json:"from"`
+	Field  string `yaml:"field,omitempty" json:"field,omitempty"`
+	Result string `yaml:"result" json:"result"`
+}
+
+// Aggregate.Op 的取值
+const (
+	AggSum   = "sum"
+	AggAvg   = "avg"
+	AggMin   = "min"
+	AggMax   = "max"
+	AggCount = "count"
+)
+
+// 随机值：经 SideEffect 生成并写入历史，重放时取回同一结果；Int/Float/Choice/From 四选一，结果写入 Result
+type Random struct {
+	Result string       `yaml:"result" json:"result"`
+	Int    *RandomRange `yaml:"int,omitempty" json:"int,omitempty"`       // [Min, Max] 内的整数
+	Float  *RandomRange `yaml:"float,omitempty" json:"float,omitempty"`   // [Min, Max) 内的浮点数
+	Choice []Value      `yaml:"choice,omitempty" json:"choice,omitempty"` // 从候选值中等概率选一个
+	From   string       `yaml:"from,omitempty" json:"from,omitempty"`     // 从列表变量中等概率选一个
+}
+
+type RandomRange struct {
+	Min float64 `yaml:"min" json:"min"`
+	Max float64 `yaml:"max" json:"max"`
+}
+
+// 当前时间：把 workflow.Now 写入 Result（重放时得到同一时间）。
+// Format 为 rfc3339（默认）/rfc3339nano/date/epoch/epochMillis 或 Go 时间布局；
+// Truncate 为 day/hour/minute/second 或时长（如 15m），day 按 Timezone 的日历日截断
+type Now struct {
+	Result   string `yaml:"result" json:"result"`
+	Format   string `yaml:"format,omitempty" json:"format,omitempty"`
+	Truncate string `yaml:"truncate,omitempty" json:"truncate,omitempty"`
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"` // IANA 时区名，默认 UTC
+}
+
+// Now.Format 的预定义格式
+const (
+	NowRFC3339     = "rfc3339"
+	NowRFC3339Nano = "rfc3339nano"
+	NowDate        = "date"
+	NowEpoch       = "epoch"
+	NowEpochMillis = "epochMillis"
+)
+
+// 等待：用持久化定时器等待固定秒数（Seconds/SecondsRef）或等到某个 RFC3339 时间（Until/UntilRef），四选一；
+// 时间已过时立即继续
+type Wait struct {
+	Seconds    int    `yaml:"seconds,omitempty" json:"seconds,omitempty"`
+	SecondsRef string `yaml:"secondsRef,omitempty" json:"secondsRef,omitempty"`
+	Until      string `yaml:"until,omitempty" json:"until,omitempty"`
+	UntilRef   string `yaml:"untilRef,omitempty" json:"untilRef,omitempty"`
+}
+
+// 生成 ID：经 SideEffect 生成 UUIDv4 并写入历史，重放时取回同一 ID，适合作为下游调用的幂等键。
+// Format 为 uuid（默认，带连字符）或 hex（去掉连字符，可用 Length 截取前若干位），结果为 Prefix + ID
+type NewID struct {
+	Result string `yaml:"result" json:"result"`
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"` // 如 "ord_"
+	Format string `yaml:"format,omitempty" json:"format,omitempty"`
+	Length int    `yaml:"length,omitempty" json:"length,omitempty"` // 仅 hex：1~32，默认 32
+}
+
+// 日志：通过工作流 logger 输出一条诊断信息（重放时不重复输出）；
+// Message 是 text/template 模板，. 为当前变量，如 "order {{.orderId}} total {{.total}}"
+type Log struct {
+	Level   string `yaml:"level,omitempty" json:"level,omitempty"` // debug/info/warn/error，默认 info
+	Message string `yaml:"message" json:"message"`
 }
 
+// 日志级别
+const (
+	LogDebug = "debug"
+	LogInfo  = "info"
+	LogWarn  = "warn"
+	LogError = "error"
+)
+
 // 调用 Activity
 type ActivityInvocation struct {
-	Name   string   `yaml:"name"`             // Activity 名
-	Args   []Value  `yaml:"args,omitempty"`   // 入参（支持 ref/字面量）
-	Result string   `yaml:"result,omitempty"` // Optional：把返回值写入变量
-	Opts   *ActOpts `yaml:"opts,omitempty"`   // 节点级选项（超时/重试）
+	Name   string   `yaml:"name" json:"name"`                         // Activity 名
+	Args   []Value  `yaml:"args,omitempty" json:"args,omitempty"`     // 入参（支持 ref/字面量）
+	Result string   `yaml:"result,omitempty" json:"result,omitempty"` // Optional：把返回值写入变量
+	Opts   *ActOpts `yaml:"opts,omitempty" json:"opts,omitempty"`     // 节点级选项（超时/重试）
 }
 
 // 节点级 ActivityOptions / 重试策略
 type ActOpts struct {
-	StartToCloseSeconds    int          `yaml:"startToCloseSeconds,omitempty"`
-	ScheduleToCloseSeconds int          `yaml:"scheduleToCloseSeconds,omitempty"`
-	HeartbeatSeconds       int          `yaml:"heartbeatSeconds,omitempty"`
-	Retry                  *RetryPolicy `yaml:"retry,omitempty"`
+	StartToCloseSeconds    int          `yaml:"startToCloseSeconds,omitempty" json:"startToCloseSeconds,omitempty"`
+	ScheduleToCloseSeconds int          `yaml:"scheduleToCloseSeconds,omitempty" json:"scheduleToCloseSeconds,omitempty"`
+	HeartbeatSeconds       int          `yaml:"heartbeatSeconds,omitempty" json:"heartbeatSeconds,omitempty"`
+	Retry                  *RetryPolicy `yaml:"retry,omitempty" json:"retry,omitempty"`
 }
 
 type RetryPolicy struct {
-	MaxAttempts        int     `yaml:"maxAttempts,omitempty"`        // 0: 使用 SDK 默认；1: 不重试
-	InitialIntervalSec int     `yaml:"initialIntervalSec,omitempty"` // 初始重试间隔
-	MaxIntervalSec     int     `yaml:"maxIntervalSec,omitempty"`
-	BackoffCoefficient float64 `yaml:"backoffCoefficient,omitempty"` // 默认 2.0
+	MaxAttempts        int     `yaml:"maxAttempts,omitempty" json:"maxAttempts,omitempty"`               // 0: 使用 SDK 默认；1: 不重试
+	InitialIntervalSec int     `yaml:"initialIntervalSec,omitempty" json:"initialIntervalSec,omitempty"` // 初始重试间隔
+	MaxIntervalSec     int     `yaml:"maxIntervalSec,omitempty" json:"maxIntervalSec,omitempty"`
+	BackoffCoefficient float64 `yaml:"backoffCoefficient,omitempty" json:"backoffCoefficient,omitempty"` // 默认 2.0
 }
 
 // 条件（结构化，避免不确定解析）
 type Cond struct {
 	// truthy: 变量为 true / 非空字符串 / 非零数字 / 非空集合
-	Truthy *Value `yaml:"truthy,omitempty"`
+	Truthy *Value `yaml:"truthy,omitempty" json:"truthy,omitempty"`
 	// eq/ne: 左右值比较
-	Eq *Compare `yaml:"eq,omitempty"`
-	Ne *Compare `yaml:"ne,omitempty"`
+	Eq *Compare `yaml:"eq,omitempty" json:"eq,omitempty"`
+	Ne *Compare `yaml:"ne,omitempty" json:"ne,omitempty"`
 	// NOT / ANY / ALL（简单组合）
-	Not *Cond  `yaml:"not,omitempty"`
-	Any []Cond `yaml:"any,omitempty"`
-	All []Cond `yaml:"all,omitempty"`
+	Not *Cond  `yaml:"not,omitempty" json:"not,omitempty"`
+	Any []Cond `yaml:"any,omitempty" json:"any,omitempty"`
+	All []Cond `yaml:"all,omitempty" json:"all,omitempty"`
 }
 
 type Compare struct {
-	Left  Value `yaml:"left"`
-	Right Value `yaml:"right"`
+	Left  Value `yaml:"left" json:"left"`
+	Right Value `yaml:"right" json:"right"`
 }
 
 // Value：带类型的值或变量引用（二选一）
 type Value struct {
-	Ref   string   `yaml:"ref,omitempty"` // 引用变量，如 "foo"
-	Str   *string  `yaml:"str,omitempty"`
-	Int   *int64   `yaml:"int,omitempty"`
-	Float *float64 `yaml:"float,omitempty"`
-	Bool  *bool    `yaml:"bool,omitempty"`
+	Ref   string   `yaml:"ref,omitempty" json:"ref,omitempty"` // 引用变量，如 "foo"
+	Str   *string  `yaml:"str,omitempty" json:"str,omitempty"`
+	Int   *int64   `yaml:"int,omitempty" json:"int,omitempty"`
+	Float *float64 `yaml:"float,omitempty" json:"float,omitempty"`
+	Bool  *bool    `yaml:"bool,omitempty" json:"bool,omitempty"`
+	Fn    *Func    `yaml:"fn,omitempty" json:"fn,omitempty"` // 字符串函数，见 func.go
 	// 可按需扩展：Map、Array、JSON Raw 等
 }
 
@@ -123,15 +318,21 @@ type Value struct {
    =============== 入口与执行 ===============
 */
 
+// WorkflowType 是 SimpleDSLWorkflow 注册后的工作流类型名，用于可见性查询
+const WorkflowType = "SimpleDSLWorkflow"
+
 // SimpleDSLWorkflow 是可直接注册到 Temporal 的 Workflow 函数
 func SimpleDSLWorkflow(ctx workflow.Context, wf Workflow) (map[string]any, error) {
 	logger := workflow.GetLogger(ctx)
 
-	// 初始化变量快照（工作流内部使用）
+	// 初始化变量快照（工作流内部使用）；ContinueAsNew 后沿用上一次执行的变量
 	bindings := make(map[string]any, len(wf.Variables))
 	for k, v := range wf.Variables {
 		bindings[k] = v
 	}
+	if wf.Resume != nil {
+		bindings = cloneMap(wf.Resume.Bindings)
+	}
 
 	// 全局 ActivityOptions（可被节点覆盖）
 	ao := workflow.ActivityOptions{
@@ -142,29 +343,130 @@ func SimpleDSLWorkflow(ctx workflow.Context, wf Workflow) (map[string]any, error
 	}
 	ctx = workflow.WithActivityOptions(ctx, ao)
 
+	// 节点轨迹，可通过 QueryTrace 查询
+	ctx, err := withTracer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withPauser(ctx)
+	if ctx, err = withMapRegistry(ctx); err != nil {
+		return nil, err
+	}
+	if ctx, err = withDebugger(ctx, wf.Breakpoints); err != nil {
+		return nil, err
+	}
+	if err := registerQueries(ctx, wf, bindings); err != nil {
+		return nil, err
+	}
+	ctx = withResumer(ctx, wf.Resume)
+
 	// 校验 DSL
+	if err := wf.requireRoot(); err != nil {
+		logger.Error("DSL entry not selected", "error", err)
+		return nil, err
+	}
 	if err := wf.validate(); err != nil {
 		logger.Error("DSL validation failed", "error", err)
 		return nil, err
 	}
+	if err := checkResume(wf); err != nil {
+		logger.Error("DSL resume failed", "error", err)
+		return nil, err
+	}
 
 	// 执行根语句数组（顺序执行）
-	for _, stmt := range wf.Root {
-		if err := stmt.execute(ctx, wf, bindings); err != nil {
+	for i, stmt := range wf.Root {
+		if err := stmt.execute(withPath(ctx, rootPath(i)), wf, bindings); err != nil {
+			if errors.Is(ctx.Err(), workflow.ErrCanceled) && len(wf.OnCancel) > 0 {
+				if cerr := runOnCancel(ctx, wf, bindings); cerr != nil {
+					logger.Error("DSL onCancel failed", "error", cerr)
+					return nil, failWorkflow(cerr)
+				}
+			}
 			logger.Error("DSL workflow failed", "error", err)
-			return nil, err
+			return nil, failWorkflow(err)
 		}
 	}
 
+	if err := checkOutput(wf.OutputSchema, bindings); err != nil {
+		logger.Error("DSL workflow result rejected", "error", err)
+		return nil, err
+	}
 	logger.Info("DSL workflow completed")
 	return bindings, nil
 }
 
+// runOnCancel 在断开取消的 context 中执行 onCancel；其中的语句可以继续执行 Activity、计时器等。
+// 任一语句失败时工作流以该错误失败，而不是报告取消
+func runOnCancel(ctx workflow.Context, wf Workflow, bindings map[string]any) error {
+	workflow.GetLogger(ctx).Info("DSL workflow canceled, running onCancel", "statements", len(wf.OnCancel))
+	ctx, _ = workflow.NewDisconnectedContext(ctx)
+	ctx = withResumer(ctx, nil)
+	for i, stmt := range wf.OnCancel {
+		if err := stmt.execute(withPath(ctx, onCancelPath(i)), wf, bindings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOutput 按 outputSchema 校验最终变量；不符时返回不可重试的 OutputSchemaViolation 错误，details 为全部问题
+func checkOutput(schema map[string]any, bindings map[string]any) error {
+	if schema == nil {
+		return nil
+	}
+	problems, err := jsonschema.Validate(schema, bindings)
+	if err != nil {
+		return temporal.NewNonRetryableApplicationError("outputSchema: "+err.Error(), "OutputSchemaViolation", err)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return temporal.NewNonRetryableApplicationError(
+		"workflow result does not match outputSchema: "+strings.Join(problems, "; "), "OutputSchemaViolation", nil, problems)
+}
+
 /*
    =============== 执行实现（各节点） ===============
 */
 
+// execute 执行语句并记录轨迹；ctx 中的路径为该语句自身的路径
 func (s *Statement) execute(ctx workflow.Context, wf Workflow, bindings map[string]any) error {
+	// 从 ContinueAsNew 恢复时，恢复点之前的语句已经执行过
+	if resumerFrom(ctx).skip(pathFrom(ctx)) {
+		return nil
+	}
+	// 暂停时在节点边界等待，节点尚未开始，也不会出现在轨迹中
+	if err := waitIfPaused(ctx); err != nil {
+		return err
+	}
+	t := tracerFrom(ctx)
+	// when 为假时不执行，也不在断点处停下
+	if s.When != nil {
+		ok, err := evalCond(*s.When, bindings)
+		if err != nil {
+			return wrapNodeError(ctx, s, bindings, fmt.Errorf("when condition eval failed: %w", err))
+		}
+		if !ok {
+			if t != nil {
+				t.skip(ctx, s, "when "+CondString(*s.When)+" is false")
+			}
+			return nil
+		}
+	}
+	if err := haltIfNeeded(ctx, s, bindings); err != nil {
+		return err
+	}
+	if t == nil {
+		return wrapNodeError(ctx, s, bindings, s.run(ctx, wf, bindings))
+	}
+	started := t.start(ctx, s)
+	err := wrapNodeError(ctx, s, bindings, s.run(ctx, wf, bindings))
+	t.finish(ctx, started, err)
+	return err
+}
+
+func (s *Statement) run(ctx workflow.Context, wf Workflow, bindings map[string]any) error {
 	switch {
 	case s.Activity != nil:
 		return s.Activity.execute(ctx, wf, bindings)
@@ -176,6 +478,43 @@ func (s *Statement) execute(ctx workflow.Context, wf Workflow, bindings map[stri
 		return s.While.execute(ctx, wf, bindings)
 	case s.If != nil:
 		return s.If.execute(ctx, wf, bindings)
+	case s.Session != nil:
+		return s.Session.execute(ctx, wf, bindings)
+	case s.Marker != nil:
+		return s.Marker.execute(ctx, bindings)
+	case s.Log != nil:
+		s.Log.execute(ctx, bindings)
+		return nil
+	case s.Noop != nil:
+		return nil
+	case s.Append != nil:
+		return s.Append.execute(bindings)
+	case s.Merge != nil:
+		return s.Merge.execute(bindings)
+	case s.Set != nil:
+		v, err := evalValue(s.Set.Value, bindings)
+		if err != nil {
+			return fmt.Errorf("set %q: %w", s.Set.Var, err)
+		}
+		bindings[s.Set.Var] = v
+		return nil
+	case s.Unset != nil:
+		for _, name := range s.Unset.Vars {
+			delete(bindings, name)
+		}
+		return nil
+	case s.Increment != nil:
+		return s.Increment.execute(bindings)
+	case s.Aggregate != nil:
+		return s.Aggregate.execute(bindings)
+	case s.Random != nil:
+		return s.Random.execute(ctx, bindings)
+	case s.Now != nil:
+		return s.Now.execute(ctx, bindings)
+	case s.NewID != nil:
+		return s.NewID.execute(ctx, bindings)
+	case s.Wait != nil:
+		return s.Wait.execute(ctx, bindings)
 	default:
 		return errors.New("invalid statement: empty")
 	}
@@ -184,7 +523,7 @@ func (s *Statement) execute(ctx workflow.Context, wf Workflow, bindings map[stri
 // ----- Activity -----
 
 func (a ActivityInvocation) execute(ctx workflow.Context, wf Workflow, bindings map[string]any) error {
-	fmt.Printf("Executing activity: %+v\n", a)
+	workflow.GetLogger(ctx).Debug("Executing activity", "name", a.Name, "result", a.Result)
 	// 局部 ActivityOptions
 	if a.Opts != nil {
 		ctx = workflow.WithActivityOptions(ctx, mergeActOpts(ctx, a.Opts))
@@ -200,7 +539,10 @@ func (a ActivityInvocation) execute(ctx workflow.Context, wf Workflow, bindings
 		args = append(args, v)
 	}
 
-	// 执行
+	// 执行；Summary 记录节点路径，供 UI 展示与 BuildTimeline 把历史事件归属到节点
+	ao := workflow.GetActivityOptions(ctx)
+	ao.Summary = pathFrom(ctx)
+	ctx = workflow.WithActivityOptions(ctx, ao)
 	var result any
 	f := workflow.ExecuteActivity(ctx, a.Name, args...)
 	if err := f.Get(ctx, &result); err != nil {
@@ -238,7 +580,7 @@ func (p Parallel) execute(ctx workflow.Context, wf Workflow, bindings map[string
 
 	for i, st := range p {
 		localBindings := cloneMap(bindings) // 浅拷贝：建议变量保持标量/小对象
-		f := executeAsync(st, ctx, wf, localBindings)
+		f := executeAsync(st, withChildPath(ctx, fmt.Sprintf("parallel[%d]", i)), wf, localBindings)
 		branchIndex := i // 捕获循环变量
 		selector.AddFuture(f, func(f workflow.Future) {
 			err := f.Get(ctx, nil)
@@ -254,7 +596,7 @@ func (p Parallel) execute(ctx workflow.Context, wf Workflow, bindings map[string
 	}
 
 	fmt.Printf("Parallel: waiting for %d branches to complete\n", len(p))
-	
+
 	// 等待所有分支完成
 	for completed < len(p) {
 		fmt.Printf("Parallel: waiting for completion (%d/%d done)\n", completed, len(p))
@@ -329,25 +671,63 @@ func (m Map) execute(ctx workflow.Context, wf Workflow, bindings map[string]any)
 		local map[string]any
 		err   error
 		idx   int
+		// 成功时按 CollectVar 收集到的值及其来源变量（见 collectFrom）
+		collectKey string
+		collected  any
+	}
+	result := func(local map[string]any, err error, idx int) branchRes {
+		r := branchRes{local: local, err: err, idx: idx}
+		if err == nil && m.CollectVar != "" {
+			r.collectKey, r.collected = m.collectFrom(local, bindings, itemVar, idx)
+		}
+		return r
 	}
 
+	streamer, err := newMapStreamer(m.StreamTo, bindings)
+	if err != nil {
+		return err
+	}
+	defer streamer.wait(ctx)
+
 	childCtx, cancel := workflow.WithCancel(ctx)
 	defer cancel() // 确保清理
 
-	inflight := 0
-	next := 0
+	// 窗口与速率可在运行中通过 UpdateTune 调整，调整后经 run.wake 唤醒调度循环
+	run, done := startMapRun(ctx, window, m.RatePerMinute, len(items))
+	defer done()
+	// 不在并行分支内时，已完成元素的收集值可通过 QueryBindings 提前看到
+	if m.CollectVar != "" && !inBranch(ctx) {
+		run.collectVar, run.collected = m.CollectVar, map[int]any{}
+	}
 	selector := workflow.NewSelector(ctx)
-	
-	// 存储所有结果
+	selector.AddReceive(run.wake, func(c workflow.ReceiveChannel, _ bool) {
+		c.Receive(ctx, nil)
+	})
+
+	// 存储所有结果；从 ContinueAsNew 恢复时，上一次执行已完成的元素直接计入结果，不再执行
 	allResults := make([]branchRes, 0, len(items))
 	completed := 0
+	cursor, leave := resumerFrom(ctx).enter(pathFrom(ctx))
+	defer leave()
+	for idx := range items {
+		if st, ok := cursor.Items[idx]; ok {
+			local, err := st.restore(bindings)
+			r := result(local, err, idx)
+			allResults = append(allResults, r)
+			run.collect(idx, r.collectKey, r.collected)
+			completed++
+		}
+	}
+	if cursor.Items == nil {
+		cursor.Items = map[int]MapItemState{}
+	}
 
 	emit := func(idx int, it any) {
 		localBindings := cloneMap(bindings)
 		localBindings[itemVar] = it
-		f := executeAsync(m.Body, childCtx, wf, localBindings)
-		inflight++
-		fmt.Printf("Map: started processing item %d (inflight: %d)\n", idx, inflight)
+		f := executeAsync(m.Body, withChildPath(childCtx, "map.body"), wf, localBindings)
+		run.inflight++
+		fmt.Printf("Map: started processing item %d (inflight: %d)\n", idx, run.inflight)
 		selector.AddFuture(f, func(f workflow.Future) {
 			err := f.Get(childCtx, nil)
 			if err != nil {
@@ -355,31 +735,67 @@ func (m Map) execute(ctx workflow.Context, wf Workflow, bindings map[string]any)
 			} else {
 				fmt.Printf("Map: item %d completed successfully\n", idx)
 			}
-			allResults = append(allResults, branchRes{localBindings, err, idx})
+			r := result(localBindings, err, idx)
+			allResults = append(allResults, r)
+			cursor.Items[idx] = mapItemState(bindings, localBindings, err)
 			completed++
+			run.collect(idx, r.collectKey, r.collected)
+			item := MapItemResult{Path: run.path, Index: idx, Value: r.collected, Completed: completed, Total: len(items)}
+			if err != nil {
+				item.Error = err.Error()
+			}
+			streamer.send(ctx, item)
 		})
 	}
 
-	// 先放初始窗口
-	for next < len(items) && inflight < window {
-		emit(next, items[next])
-		next++
+	// fill 在窗口与速率允许时补位；受速率限制时设置定时器，到点后再次补位
+	var lastStart, timerAt time.Time
+	fill := func() {
+		for run.next < len(items) && run.inflight < run.window {
+			if _, ok := cursor.Items[run.next]; ok {
+				run.next++
+				continue
+			}
+			now := workflow.Now(ctx)
+			if run.rate > 0 && !lastStart.IsZero() {
+				at := lastStart.Add(time.Minute / time.Duration(run.rate))
+				if at.After(now) {
+					if timerAt.IsZero() || at.Before(timerAt) {
+						timerAt = at
+						selector.AddFuture(workflow.NewTimer(childCtx, at.Sub(now)), func(workflow.Future) {
+							if timerAt.Equal(at) {
+								timerAt = time.Time{}
+							}
+						})
+					}
+					return
+				}
+			}
+			lastStart = now
+			emit(run.next, items[run.next])
+			run.next++
+		}
 	}
 
+	// 先放初始窗口
+	fill()
+
 	fmt.Printf("Map: started initial window, waiting for results\n")
 
 	// 调度循环：简化版本，类似于 Parallel
 	totalExpected := len(items)
+	handled := completed // 已处理（释放窗口）的结果数；恢复的结果不占窗口
 	for completed < totalExpected {
-		fmt.Printf("Map: waiting (completed: %d/%d, inflight: %d)\n", completed, totalExpected, inflight)
+		fmt.Printf("Map: waiting (completed: %d/%d, inflight: %d)\n", completed, totalExpected, run.inflight)
 		selector.Select(ctx)
-		
+
 		// 检查新完成的任务
-		if completed < len(allResults) {
+		for handled < len(allResults) {
 			// 有新的结果
-			lastResult := allResults[len(allResults)-1]
-			inflight--
-			
+			lastResult := allResults[handled]
+			handled++
+			run.inflight--
+
 			if lastResult.err != nil {
 				if m.FailFast {
 					cancel()
@@ -387,13 +803,10 @@ func (m Map) execute(ctx workflow.Context, wf Workflow, bindings map[string]any)
 					return lastResult.err
 				}
 			}
-			
-			// 继续补位
-			if next < len(items) && inflight < window {
-				emit(next, items[next])
-				next++
-			}
+
 		}
+		// 继续补位（结果释放了窗口，或窗口/速率被调整）
+		fill()
 	}
 
 	fmt.Printf("Map: all items processed, processing results\n")
@@ -413,42 +826,12 @@ func (m Map) execute(ctx workflow.Context, wf Workflow, bindings map[string]any)
 			}
 		} else {
 			successResults = append(successResults, r)
-			
-			if m.CollectVar != "" {
-				// 收集逻辑：按索引顺序收集
-				var collectedValue any
-				found := false
-				
-				// 1. 优先查找 CollectVar 本身
-				if v, ok := r.local[m.CollectVar]; ok {
-					collectedValue = v
-					found = true
-					collectVars[m.CollectVar] = true
-				} else if v, ok := r.local[fmt.Sprintf("%s_%d", m.CollectVar, r.idx)]; ok {
-					// 2. 查找 CollectVar_<index>
-					collectedValue = v
-					found = true
-					collectVars[fmt.Sprintf("%s_%d", m.CollectVar, r.idx)] = true
-				} else {
-					// 3. 查找在当前迭代中新增的变量 (相对于输入 bindings)
-					for k, v := range r.local {
-						if k != itemVar && k != m.CollectVar && !strings.HasPrefix(k, m.CollectVar+"_") {
-							if _, existsInOriginal := bindings[k]; !existsInOriginal {
-								collectedValue = v
-								found = true
-								collectVars[k] = true
-								fmt.Printf("Map: collecting variable %q = %v for item %d\n", k, v, r.idx)
-								break
-							}
-						}
-					}
-				}
-				
-				if found {
-					// 确保按索引顺序放置
-					if r.idx < len(collected) {
-						collected[r.idx] = collectedValue
-					}
+
+			// 按索引顺序收集
+			if r.collectKey != "" {
+				collectVars[r.collectKey] = true
+				if r.idx < len(collected) {
+					collected[r.idx] = r.collected
 				}
 			}
 		}
@@ -457,14 +840,14 @@ func (m Map) execute(ctx workflow.Context, wf Workflow, bindings map[string]any)
 	if firstErr != nil && m.FailFast {
 		return firstErr
 	}
-	
+
 	// 合并成功分支的变量更改（检测冲突）
 	for _, r := range successResults {
 		for k, v := range r.local {
 			// 跳过临时变量 itemVar、CollectVar 相关变量，以及被收集的变量
-			if k == itemVar || 
-			   (m.CollectVar != "" && (k == m.CollectVar || strings.HasPrefix(k, m.CollectVar+"_"))) ||
-			   collectVars[k] {
+			if k == itemVar ||
+				(m.CollectVar != "" && (k == m.CollectVar || strings.HasPrefix(k, m.CollectVar+"_"))) ||
+				collectVars[k] {
 				continue
 			}
 			if _, exists := bindings[k]; exists && !reflect.DeepEqual(bindings[k], v) {
@@ -473,7 +856,7 @@ func (m Map) execute(ctx workflow.Context, wf Workflow, bindings map[string]any)
 			bindings[k] = v
 		}
 	}
-	
+
 	if m.CollectVar != "" {
 		// 过滤掉 nil 值，保持收集到的值
 		finalCollected := make([]any, 0, len(items))
@@ -490,29 +873,59 @@ func (m Map) execute(ctx workflow.Context, wf Workflow, bindings map[string]any)
 	return firstErr
 }
 
+// collectFrom 返回一个成功元素按 CollectVar 收集的值及其来源变量，未找到时 key 为空：
+// 1. 优先查找 CollectVar 本身；2. 查找 CollectVar_<index>；3. 查找在当前迭代中新增的变量（相对于输入 bindings）
+func (m Map) collectFrom(local, bindings map[string]any, itemVar string, idx int) (string, any) {
+	if v, ok := local[m.CollectVar]; ok {
+		return m.CollectVar, v
+	}
+	k := fmt.Sprintf("%s_%d", m.CollectVar, idx)
+	if v, ok := local[k]; ok {
+		return k, v
+	}
+	for k, v := range local {
+		if k != itemVar && k != m.CollectVar && !strings.HasPrefix(k, m.CollectVar+"_") {
+			if _, existsInOriginal := bindings[k]; !existsInOriginal {
+				fmt.Printf("Map: collecting variable %q = %v for item %d\n", k, v, idx)
+				return k, v
+			}
+		}
+	}
+	return "", nil
+}
+
 // ----- If -----
 
 func (i If) execute(ctx workflow.Context, wf Workflow, bindings map[string]any) error {
 	fmt.Printf("If: evaluating condition\n")
-	
-	// 评估条件
-	ok, err := evalCond(i.Cond, bindings)
-	if err != nil {
-		return fmt.Errorf("if condition eval failed: %w", err)
+
+	// 恢复点在某个分支内时，条件已在上一次执行中求值，沿原分支继续
+	r, path := resumerFrom(ctx), pathFrom(ctx)
+	var ok bool
+	switch {
+	case r.inside(path + ".if.then"):
+		ok = true
+	case r.inside(path + ".if.else"):
+		ok = false
+	default:
+		var err error
+		if ok, err = evalCond(i.Cond, bindings); err != nil {
+			return fmt.Errorf("if condition eval failed: %w", err)
+		}
 	}
-	
+
 	if ok {
 		fmt.Printf("If: condition is true, executing then branch\n")
 		if i.Then != nil {
-			return i.Then.execute(ctx, wf, bindings)
+			return i.Then.execute(withChildPath(ctx, "if.then"), wf, bindings)
 		}
 	} else {
 		fmt.Printf("If: condition is false, executing else branch\n")
 		if i.Else != nil {
-			return i.Else.execute(ctx, wf, bindings)
+			return i.Else.execute(withChildPath(ctx, "if.else"), wf, bindings)
 		}
 	}
-	
+
 	fmt.Printf("If: completed\n")
 	return nil
 }
@@ -520,121 +933,447 @@ func (i If) execute(ctx workflow.Context, wf Workflow, bindings map[string]any)
 // ----- While -----
 
 func (w While) execute(ctx workflow.Context, wf Workflow, bindings map[string]any) error {
-	iter := 0
+	// 进度随 ContinueAsNew 携带：已完成的轮数，以及恢复点是否在本轮循环体之内（此时本轮条件已求值）
+	r, path := resumerFrom(ctx), pathFrom(ctx)
+	cursor, done := r.enter(path)
+	defer done()
+	midIter := r.inside(path + ".while.body")
 	for {
-		ok, err := evalCond(w.Cond, bindings)
-		if err != nil {
-			return fmt.Errorf("while cond eval failed: %w", err)
-		}
-		if !ok {
-			return nil
-		}
-		if w.MaxIters > 0 && iter >= w.MaxIters {
-			return fmt.Errorf("while exceeded MaxIters=%d", w.MaxIters)
+		if !midIter {
+			ok, err := evalCond(w.Cond, bindings)
+			if err != nil {
+				return fmt.Errorf("while cond eval failed: %w", err)
+			}
+			if !ok {
+				return nil
+			}
+			if w.MaxIters > 0 && cursor.Iter >= w.MaxIters {
+				return fmt.Errorf("while exceeded MaxIters=%d", w.MaxIters)
+			}
 		}
-		if err := w.Body.execute(ctx, wf, bindings); err != nil {
+		midIter = false
+		if err := w.Body.execute(withChildPath(ctx, "while.body"), wf, bindings); err != nil {
 			return err
 		}
 		if w.SleepSeconds > 0 {
-			_ = workflow.Sleep(ctx, time.Duration(w.SleepSeconds)*time.Second)
+			_ = workflow.NewTimerWithOptions(ctx, time.Duration(w.SleepSeconds)*time.Second, workflow.TimerOptions{Summary: pathFrom(ctx)}).Get(ctx, nil)
 		}
-		iter++
+		cursor.Iter++
+	}
+}
+
+// ----- Session -----
 
-		// 如需分段历史，可在此根据 iter 或运行时指标触发 ContinueAsNew
-		// return workflow.NewContinueAsNewError(ctx, SimpleDSLWorkflow, wfNext)
+func (se Session) execute(ctx workflow.Context, wf Workflow, bindings map[string]any) error {
+	sctx, err := workflow.CreateSession(ctx, &workflow.SessionOptions{
+		CreationTimeout:  durationOrDefault(se.CreationTimeoutSec, time.Minute),
+		ExecutionTimeout: durationOrDefault(se.ExecutionTimeoutSec, time.Hour),
+	})
+	if err != nil {
+		return fmt.Errorf("create session: %w", err)
 	}
+	defer workflow.CompleteSession(sctx)
+	for i, st := range se.Steps {
+		if err := st.execute(withChildPath(sctx, fmt.Sprintf("session.steps[%d]", i)), wf, bindings); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-/*
-   =============== 校验 ===============
-*/
+// ----- Marker -----
 
-// Validate performs validation on the workflow structure (public API)
-func (wf Workflow) Validate() error {
-	return wf.validate()
+func (m Marker) execute(ctx workflow.Context, bindings map[string]any) error {
+	rec := MarkerRecord{Name: m.Name, Path: pathFrom(ctx)}
+	for _, name := range m.Refs {
+		v, ok := bindings[name]
+		if !ok {
+			return fmt.Errorf("marker %q: ref %q not found", m.Name, name)
+		}
+		if rec.Values == nil {
+			rec.Values = map[string]any{}
+		}
+		rec.Values[name] = v
+	}
+	// SideEffect 的结果随 MarkerRecorded 事件写入历史，重放时直接读取，不会重复记录
+	var recorded MarkerRecord
+	if err := workflow.SideEffect(ctx, func(workflow.Context) any { return rec }).Get(&recorded); err != nil {
+		return fmt.Errorf("marker %q: %w", m.Name, err)
+	}
+	workflow.GetLogger(ctx).Info("DSL marker recorded", "name", m.Name, "path", rec.Path)
+	return nil
 }
 
-func (wf Workflow) validate() error {
-	if len(wf.Root) == 0 {
-		return errors.New("root statement array is empty")
+// ----- Log -----
+
+// execute 输出日志；日志只用于诊断，模板渲染失败（如引用了尚未写入的变量）时输出原文与错误，不让工作流失败
+func (l Log) execute(ctx workflow.Context, bindings map[string]any) {
+	logger := workflow.GetLogger(ctx)
+	path := pathFrom(ctx)
+	msg, err := renderTemplate("log", l.Message, bindings)
+	if err != nil {
+		logger.Warn("DSL log message could not be rendered", "path", path, "message", l.Message, "error", err)
+		return
+	}
+	switch l.Level {
+	case LogDebug:
+		logger.Debug(msg, "path", path)
+	case LogWarn:
+		logger.Warn(msg, "path", path)
+	case LogError:
+		logger.Error(msg, "path", path)
+	default:
+		logger.Info(msg, "path", path)
 	}
-	// 验证所有根语句
-	for i, stmt := range wf.Root {
-		if err := stmt.validate(); err != nil {
-			return fmt.Errorf("root[%d]: %w", i, err)
+}
+
+// ----- Random -----
+
+func (r Random) execute(ctx workflow.Context, bindings map[string]any) error {
+	// 候选值在 SideEffect 之外求值，SideEffect 只负责产生随机数
+	var pool []any
+	switch {
+	case r.Choice != nil:
+		for i, c := range r.Choice {
+			v, err := evalValue(c, bindings)
+			if err != nil {
+				return fmt.Errorf("random %q: choice[%d]: %w", r.Result, i, err)
+			}
+			pool = append(pool, v)
+		}
+	case r.From != "":
+		v, ok := bindings[r.From]
+		if !ok {
+			return fmt.Errorf("random %q: ref %q not found", r.Result, r.From)
+		}
+		rv := reflect.ValueOf(v)
+		if v == nil || rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return fmt.Errorf("random %q: %q is %T, not a list", r.Result, r.From, v)
+		}
+		for i := 0; i < rv.Len(); i++ {
+			pool = append(pool, rv.Index(i).Interface())
+		}
+		if len(pool) == 0 {
+			return fmt.Errorf("random %q: %q is empty", r.Result, r.From)
 		}
 	}
+
+	// 取值失败时不写 result，变量保持原值
+	switch {
+	case r.Int != nil:
+		lo, hi := int64(math.Ceil(r.Int.Min)), int64(math.Floor(r.Int.Max))
+		var n int64
+		if err := workflow.SideEffect(ctx, func(workflow.Context) any { return lo + rand.Int63n(hi-lo+1) }).Get(&n); err != nil {
+			return fmt.Errorf("random %q: %w", r.Result, err)
+		}
+		bindings[r.Result] = n
+	case r.Float != nil:
+		lo, hi := r.Float.Min, r.Float.Max
+		var f float64
+		if err := workflow.SideEffect(ctx, func(workflow.Context) any { return lo + rand.Float64()*(hi-lo) }).Get(&f); err != nil {
+			return fmt.Errorf("random %q: %w", r.Result, err)
+		}
+		bindings[r.Result] = f
+	default:
+		var i int
+		if err := workflow.SideEffect(ctx, func(workflow.Context) any { return rand.Intn(len(pool)) }).Get(&i); err != nil {
+			return fmt.Errorf("random %q: %w", r.Result, err)
+		}
+		bindings[r.Result] = pool[i]
+	}
 	return nil
 }
 
-func (s *Statement) validate() error {
-	if s == nil {
-		return errors.New("nil statement")
+// ----- Now -----
+
+func (n Now) execute(ctx workflow.Context, bindings map[string]any) error {
+	loc, err := n.location()
+	if err != nil {
+		return fmt.Errorf("now %q: %w", n.Result, err)
+	}
+	t := workflow.Now(ctx).In(loc)
+	if t, err = truncateTime(t, n.Truncate); err != nil {
+		return fmt.Errorf("now %q: %w", n.Result, err)
+	}
+	switch n.Format {
+	case "", NowRFC3339:
+		bindings[n.Result] = t.Format(time.RFC3339)
+	case NowRFC3339Nano:
+		bindings[n.Result] = t.Format(time.RFC3339Nano)
+	case NowDate:
+		bindings[n.Result] = t.Format(time.DateOnly)
+	case NowEpoch:
+		bindings[n.Result] = t.Unix()
+	case NowEpochMillis:
+		bindings[n.Result] = t.UnixMilli()
+	default:
+		bindings[n.Result] = t.Format(n.Format)
 	}
-	cnt := 0
-	if s.Activity != nil {
-		cnt++
+	return nil
+}
+
+func (n Now) location() (*time.Location, error) {
+	if n.Timezone == "" {
+		return time.UTC, nil
 	}
-	if s.Parallel != nil {
-		cnt++
+	return time.LoadLocation(n.Timezone)
+}
+
+// truncateTime 按日历日或固定时长向下取整
+func truncateTime(t time.Time, unit string) (time.Time, error) {
+	switch unit {
+	case "":
+		return t, nil
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()), nil
+	case "hour":
+		return t.Truncate(time.Hour), nil
+	case "minute":
+		return t.Truncate(time.Minute), nil
+	case "second":
+		return t.Truncate(time.Second), nil
+	}
+	d, err := time.ParseDuration(unit)
+	if err != nil || d <= 0 {
+		return t, fmt.Errorf("invalid truncate %q (want day/hour/minute/second or a positive duration)", unit)
+	}
+	return t.Truncate(d), nil
+}
+
+// ----- Wait -----
+
+func (w Wait) execute(ctx workflow.Context, bindings map[string]any) error {
+	var d time.Duration
+	switch {
+	case w.SecondsRef != "":
+		v, ok := bindings[w.SecondsRef]
+		if !ok {
+			return fmt.Errorf("wait: ref %q not found", w.SecondsRef)
+		}
+		n, ok := toFloat(v)
+		if !ok {
+			return fmt.Errorf("wait: %q is %T, not a number", w.SecondsRef, v)
+		}
+		d = time.Duration(n * float64(time.Second))
+	case w.Until != "" || w.UntilRef != "":
+		until := w.Until
+		if w.UntilRef != "" {
+			v, ok := bindings[w.UntilRef]
+			if !ok {
+				return fmt.Errorf("wait: ref %q not found", w.UntilRef)
+			}
+			if until, ok = v.(string); !ok {
+				return fmt.Errorf("wait: %q is %T, not an RFC3339 time", w.UntilRef, v)
+			}
+		}
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return fmt.Errorf("wait: %w", err)
+		}
+		d = t.Sub(workflow.Now(ctx))
+	default:
+		d = time.Duration(w.Seconds) * time.Second
 	}
-	if s.Map != nil {
-		cnt++
+	if d <= 0 {
+		return nil
 	}
-	if s.While != nil {
-		cnt++
+	return workflow.NewTimerWithOptions(ctx, d, workflow.TimerOptions{Summary: pathFrom(ctx)}).Get(ctx, nil)
+}
+
+// ----- NewID -----
+
+func (n NewID) execute(ctx workflow.Context, bindings map[string]any) error {
+	var id string
+	if err := workflow.SideEffect(ctx, func(workflow.Context) any { return uuid.NewString() }).Get(&id); err != nil {
+		return fmt.Errorf("newId %q: %w", n.Result, err)
 	}
-	if s.If != nil {
-		cnt++
+	if n.Format == "hex" {
+		id = strings.ReplaceAll(id, "-", "")
+		if n.Length > 0 && n.Length < len(id) {
+			id = id[:n.Length]
+		}
 	}
-	if cnt != 1 {
-		return fmt.Errorf("statement(id=%s) must have exactly one of activity/parallel/map/while/if", s.ID)
+	bindings[n.Result] = n.Prefix + id
+	return nil
+}
+
+// ----- Append / Merge -----
+
+// 变量修改都写入新值而不改动原有的列表/对象：Parallel 分支与 Map 迭代持有的是变量的浅拷贝，原地修改会互相影响
+
+func (a Append) execute(bindings map[string]any) error {
+	v, err := evalValue(a.Value, bindings)
+	if err != nil {
+		return fmt.Errorf("append to %q: %w", a.To, err)
 	}
-	if s.Activity != nil {
-		if s.Activity.Name == "" {
-			return errors.New("activity name required")
+	var list []any
+	if cur := bindings[a.To]; cur != nil {
+		rv := reflect.ValueOf(cur)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return fmt.Errorf("append to %q: variable is %T, not a list", a.To, cur)
+		}
+		list = make([]any, 0, rv.Len()+1)
+		for i := 0; i < rv.Len(); i++ {
+			list = append(list, rv.Index(i).Interface())
 		}
 	}
-	if s.Parallel != nil {
-		for _, b := range *s.Parallel {
-			if err := b.validate(); err != nil {
-				return err
-			}
+	bindings[a.To] = append(list, v)
+	return nil
+}
+
+func (m Merge) execute(bindings map[string]any) error {
+	v, err := evalValue(m.Value, bindings)
+	if err != nil {
+		return fmt.Errorf("merge into %q: %w", m.Into, err)
+	}
+	src, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("merge into %q: value is %T, not an object", m.Into, v)
+	}
+	var dst map[string]any
+	if cur := bindings[m.Into]; cur != nil {
+		if dst, ok = cur.(map[string]any); !ok {
+			return fmt.Errorf("merge into %q: variable is %T, not an object", m.Into, cur)
 		}
 	}
-	if s.Map != nil {
-		if s.Map.Body == nil {
-			return errors.New("map body required")
+	bindings[m.Into] = deepMerge(dst, src)
+	return nil
+}
+
+func (inc Increment) execute(bindings map[string]any) error {
+	delta := 1.0
+	if inc.By != nil {
+		v, err := evalValue(*inc.By, bindings)
+		if err != nil {
+			return fmt.Errorf("increment %q: by: %w", inc.Var, err)
 		}
-		if err := s.Map.Body.validate(); err != nil {
-			return err
+		var ok bool
+		if delta, ok = toFloat(v); !ok {
+			return fmt.Errorf("increment %q: by is %T, not a number", inc.Var, v)
 		}
-		if s.Map.ItemsRef == "" {
-			return errors.New("map itemsRef required")
+	}
+	cur := 0.0
+	if v, ok := bindings[inc.Var]; ok && v != nil {
+		if cur, ok = toFloat(v); !ok {
+			return fmt.Errorf("increment %q: variable is %T, not a number", inc.Var, v)
 		}
 	}
-	if s.While != nil {
-		if s.While.Body == nil {
-			return errors.New("while body required")
+	n := cur + delta
+	if inc.Min != nil && n < *inc.Min {
+		n = *inc.Min
+	}
+	if inc.Max != nil && n > *inc.Max {
+		n = *inc.Max
+	}
+	bindings[inc.Var] = numberValue(n)
+	return nil
+}
+
+func (a Aggregate) execute(bindings map[string]any) error {
+	v, ok := bindings[a.From]
+	if !ok {
+		return fmt.Errorf("aggregate %q: ref %q not found", a.Result, a.From)
+	}
+	rv := reflect.ValueOf(v)
+	if v == nil || rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Errorf("aggregate %q: %q is %T, not a list", a.Result, a.From, v)
+	}
+	if a.Op == AggCount {
+		bindings[a.Result] = int64(rv.Len())
+		return nil
+	}
+	nums := make([]float64, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i).Interface()
+		if a.Field != "" {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return fmt.Errorf("aggregate %q: %s[%d] is %T, not an object", a.Result, a.From, i, item)
+			}
+			item = m[a.Field]
 		}
-		if err := s.While.Body.validate(); err != nil {
-			return err
+		n, ok := toFloat(item)
+		if !ok {
+			return fmt.Errorf("aggregate %q: %s[%d] is %T, not a number", a.Result, a.From, i, item)
 		}
+		nums = append(nums, n)
 	}
-	if s.If != nil {
-		if s.If.Then == nil {
-			return errors.New("if then branch required")
-		}
-		if err := s.If.Then.validate(); err != nil {
-			return err
+	if len(nums) == 0 {
+		if a.Op == AggSum {
+			bindings[a.Result] = int64(0)
+		} else {
+			bindings[a.Result] = nil
 		}
-		if s.If.Else != nil {
-			if err := s.If.Else.validate(); err != nil {
-				return err
+		return nil
+	}
+	sum, lo, hi := 0.0, nums[0], nums[0]
+	for _, n := range nums {
+		sum += n
+		lo, hi = math.Min(lo, n), math.Max(hi, n)
+	}
+	switch a.Op {
+	case AggSum:
+		bindings[a.Result] = numberValue(sum)
+	case AggAvg:
+		bindings[a.Result] = sum / float64(len(nums))
+	case AggMin:
+		bindings[a.Result] = numberValue(lo)
+	case AggMax:
+		bindings[a.Result] = numberValue(hi)
+	default:
+		return fmt.Errorf("aggregate %q: unknown op %q", a.Result, a.Op)
+	}
+	return nil
+}
+
+// numberValue 让整数结果保持整数，便于作为 Activity 的整型参数或分页游标
+func numberValue(n float64) any {
+	if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+		return int64(n)
+	}
+	return n
+}
+
+// deepMerge 返回 src 合并进 dst 的新对象，dst 与 src 均不被修改
+func deepMerge(dst, src map[string]any) map[string]any {
+	out := cloneMap(dst)
+	for k, sv := range src {
+		if sm, ok := sv.(map[string]any); ok {
+			if dm, ok := out[k].(map[string]any); ok {
+				out[k] = deepMerge(dm, sm)
+				continue
 			}
 		}
+		out[k] = sv
 	}
-	return nil
+	return out
+}
+
+/*
+   =============== 校验 ===============
+*/
+
+// Validate performs validation on the workflow structure (public API)
+func (wf Workflow) Validate() error {
+	return wf.validate()
+}
+
+// WithVariables 返回把 vars 覆盖到 Variables 之上的副本，原定义不受影响
+func (wf Workflow) WithVariables(vars map[string]any) Workflow {
+	if len(vars) == 0 {
+		return wf
+	}
+	merged := cloneMap(wf.Variables)
+	for k, v := range vars {
+		merged[k] = v
+	}
+	wf.Variables = merged
+	return wf
+}
+
+// validate 在执行前拒绝有 error 级问题的定义（警告不影响执行）
+func (wf Workflow) validate() error {
+	return firstError(wf.Check(CheckOptions{}))
 }
 
 /*
@@ -642,6 +1381,7 @@ func (s *Statement) validate() error {
 */
 
 func executeAsync(st *Statement, ctx workflow.Context, wf Workflow, bindings map[string]any) workflow.Future {
+	ctx = workflow.WithValue(ctx, branchKey{}, true)
 	f, set := workflow.NewFuture(ctx)
 	workflow.Go(ctx, func(ctx workflow.Context) {
 		err := st.execute(ctx, wf, bindings)
@@ -650,6 +1390,14 @@ func executeAsync(st *Statement, ctx workflow.Context, wf Workflow, bindings map
 	return f
 }
 
+// branchKey 标记在 Parallel/Map 分支内执行：分支使用 bindings 的副本
+type branchKey struct{}
+
+func inBranch(ctx workflow.Context) bool {
+	b, _ := ctx.Value(branchKey{}).(bool)
+	return b
+}
+
 // 合并全局与节点级 AO
 func mergeActOpts(ctx workflow.Context, o *ActOpts) workflow.ActivityOptions {
 	parent := workflow.GetActivityOptions(ctx)
@@ -745,6 +1493,9 @@ func evalValue(v Value, bindings map[string]any) (any, error) {
 	if v.Bool != nil {
 		return *v.Bool, nil
 	}
+	if v.Fn != nil {
+		return evalFunc(*v.Fn, bindings)
+	}
 	return nil, errors.New("empty value")
 }
 
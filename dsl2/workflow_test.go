@@ -0,0 +1,812 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"gopkg.in/yaml.v3"
+)
+
+const traceTestYAML = `
+version: "1.0"
+taskQueue: demo
+variables:
+  x: 5
+  approved: false
+root:
+  - parallel:
+      - activity: { name: DoA, args: [{ ref: x }], result: a }
+      - activity: { name: DoB, args: [{ int: 2 }], result: b }
+  - if:
+      cond:
+        eq: { left: { ref: x }, right: { int: 5 } }
+      then:
+        while:
+          cond: { not: { truthy: { ref: approved } } }
+          maxIters: 3
+          body:
+            activity: { name: MockApprove, result: approved }
+`
+
+// dslTest 是 startDSL/runDSL 的测试环境设置
+type dslTest struct {
+	strict bool // 执行前要求定义校验没有任何问题
+	start  time.Time
+	logger log.Logger
+	setup  []func(env *testsuite.TestWorkflowEnvironment)
+}
+
+type dslTestOption func(*dslTest)
+
+// strictCheck 要求定义在执行前通过校验且没有警告
+func strictCheck() dslTestOption { return func(o *dslTest) { o.strict = true } }
+
+// startAt 固定工作流的开始时间
+func startAt(at time.Time) dslTestOption { return func(o *dslTest) { o.start = at } }
+
+// withLogger 收集工作流日志
+func withLogger(l log.Logger) dslTestOption { return func(o *dslTest) { o.logger = l } }
+
+// beforeRun 在执行前配置环境，如注册延迟回调、mock 与 worker 选项
+func beforeRun(fn func(env *testsuite.TestWorkflowEnvironment)) dslTestOption {
+	return func(o *dslTest) { o.setup = append(o.setup, fn) }
+}
+
+// startDSL 解析 YAML 定义并在测试环境中执行到结束，返回环境供断言结果、错误与查询
+func startDSL(t *testing.T, def string, opts ...dslTestOption) *testsuite.TestWorkflowEnvironment {
+	t.Helper()
+	var wf Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(def), &wf))
+	return startWorkflow(t, wf, opts...)
+}
+
+// startWorkflow 与 startDSL 相同，用于已经构造好的定义
+func startWorkflow(t *testing.T, wf Workflow, opts ...dslTestOption) *testsuite.TestWorkflowEnvironment {
+	t.Helper()
+	var o dslTest
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.strict {
+		require.Empty(t, wf.Check(CheckOptions{KnownActivities: ActivityNames()}))
+	}
+
+	testSuite := &testsuite.WorkflowTestSuite{}
+	if o.logger != nil {
+		testSuite.SetLogger(o.logger)
+	}
+	env := testSuite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(SimpleDSLWorkflow)
+	env.RegisterActivity(&Activities{})
+	if !o.start.IsZero() {
+		env.SetStartTime(o.start)
+	}
+	for _, fn := range o.setup {
+		fn(env)
+	}
+	env.ExecuteWorkflow(SimpleDSLWorkflow, wf)
+	require.True(t, env.IsWorkflowCompleted())
+	return env
+}
+
+// runDSL 执行定义，要求成功完成并返回最终的变量
+func runDSL(t *testing.T, def string, opts ...dslTestOption) map[string]any {
+	t.Helper()
+	env := startDSL(t, def, opts...)
+	require.NoError(t, env.GetWorkflowError())
+	var bindings map[string]any
+	require.NoError(t, env.GetWorkflowResult(&bindings))
+	return bindings
+}
+
+// queryTrace 返回执行轨迹
+func queryTrace(t *testing.T, env *testsuite.TestWorkflowEnvironment) []TraceEvent {
+	t.Helper()
+	v, err := env.QueryWorkflow(QueryTrace)
+	require.NoError(t, err)
+	var trace []TraceEvent
+	require.NoError(t, v.Get(&trace))
+	return trace
+}
+
+// queryProgress 返回进度
+func queryProgress(t *testing.T, env *testsuite.TestWorkflowEnvironment) Progress {
+	t.Helper()
+	v, err := env.QueryWorkflow(QueryProgress)
+	require.NoError(t, err)
+	var progress Progress
+	require.NoError(t, v.Get(&progress))
+	return progress
+}
+
+func TestSimpleDSLWorkflowTrace(t *testing.T) {
+	env := startDSL(t, traceTestYAML)
+	require.NoError(t, env.GetWorkflowError())
+
+	var bindings map[string]any
+	require.NoError(t, env.GetWorkflowResult(&bindings))
+	require.Equal(t, "A:5", bindings["a"])
+	require.Equal(t, true, bindings["approved"])
+
+	trace := queryTrace(t, env)
+	var completed []string
+	for _, ev := range trace {
+		if ev.Status == TraceCompleted {
+			completed = append(completed, ev.Path)
+			require.Equal(t, ev.Path, trace[ev.StartSeq-1].Path)
+			require.Equal(t, TraceStarted, trace[ev.StartSeq-1].Status)
+		}
+	}
+	require.ElementsMatch(t, []string{
+		"root[0].parallel[0]", "root[0].parallel[1]", "root[0]",
+		"root[1].if.then.while.body", "root[1].if.then", "root[1]",
+	}, completed)
+	require.Equal(t, "root[0]", trace[0].Path)
+	require.Equal(t, TraceStarted, trace[0].Status)
+
+	require.Equal(t, Progress{Completed: 6, Running: []string{}, Done: 6, Total: 6, Percent: 100}, queryProgress(t, env))
+	// 旧名 progress 仍可查询
+	v, err := env.QueryWorkflow(QueryProgressAlias)
+	require.NoError(t, err)
+	var alias Progress
+	require.NoError(t, v.Get(&alias))
+	require.Equal(t, queryProgress(t, env), alias)
+
+	v, err = env.QueryWorkflow(QueryBindings)
+	require.NoError(t, err)
+	var current map[string]any
+	require.NoError(t, v.Get(&current))
+	require.Equal(t, bindings, current)
+}
+
+func TestSimpleDSLWorkflowMapWindow(t *testing.T) {
+	bindings := runDSL(t, `
+taskQueue: demo
+variables:
+  urls: ["a", "b", "c"]
+root:
+  - map:
+      itemsRef: urls
+      itemVar: url
+      concurrency: 1
+      collectVar: pages
+      body:
+        activity: { name: Fetch, args: [{ ref: url }], result: page }
+`)
+	require.Equal(t, []any{"content-of-a", "content-of-b", "content-of-c"}, bindings["pages"])
+}
+
+func TestWithVariables(t *testing.T) {
+	wf := Workflow{Variables: map[string]any{"x": 1, "mode": "prod"}}
+	got := wf.WithVariables(map[string]any{"x": 42, "extra": true})
+	require.Equal(t, map[string]any{"x": 42, "mode": "prod", "extra": true}, got.Variables)
+	require.Equal(t, map[string]any{"x": 1, "mode": "prod"}, wf.Variables)
+}
+
+func TestParseYAML(t *testing.T) {
+	wf, err := ParseYAML([]byte(traceTestYAML))
+	require.NoError(t, err)
+	require.NoError(t, wf.Validate())
+	require.Len(t, wf.Root, 2)
+	require.Equal(t, "DoA", (*wf.Root[0].Parallel)[0].Activity.Name)
+
+	_, err = ParseYAML([]byte("root: { sequence: [] }"))
+	require.Error(t, err)
+}
+
+func TestSimpleDSLWorkflowSession(t *testing.T) {
+	bindings := runDSL(t, `
+taskQueue: demo
+variables:
+  x: 1
+root:
+  - session:
+      steps:
+        - activity: { name: DoA, args: [{ ref: x }], result: a }
+        - activity: { name: DoC, args: [{ ref: a }, { str: local }], result: c }
+`, beforeRun(func(env *testsuite.TestWorkflowEnvironment) {
+		env.SetWorkerOptions(worker.Options{EnableSessionWorker: true})
+	}))
+	require.Equal(t, "A:1", bindings["a"])
+	require.Equal(t, "C(A:1+local)", bindings["c"])
+}
+
+func TestSimpleDSLWorkflowTuneMap(t *testing.T) {
+	var rejected error
+	var tuned []MapTuning
+	env := startDSL(t, `
+taskQueue: demo
+variables:
+  urls: ["a", "b", "c"]
+root:
+  - map:
+      itemsRef: urls
+      itemVar: url
+      concurrency: 3
+      ratePerMinute: 1
+      collectVar: pages
+      body:
+        activity: { name: Fetch, args: [{ ref: url }], result: page }
+`, beforeRun(func(env *testsuite.TestWorkflowEnvironment) {
+		env.RegisterDelayedCallback(func() {
+			env.UpdateWorkflow(UpdateTune, "bad-path", &testsuite.TestUpdateCallback{
+				OnReject: func(err error) { rejected = err },
+			}, TuneRequest{Path: "root[9]"})
+		}, 5*time.Second)
+		env.RegisterDelayedCallback(func() {
+			unlimited := 0
+			env.UpdateWorkflow(UpdateTune, "unthrottle", &testsuite.TestUpdateCallback{
+				OnReject: func(err error) { require.NoError(t, err) },
+				OnComplete: func(v any, err error) {
+					require.NoError(t, err)
+					tuned = v.([]MapTuning)
+				},
+			}, TuneRequest{Path: "root[0]", RatePerMinute: &unlimited})
+		}, 10*time.Second)
+	}))
+	require.NoError(t, env.GetWorkflowError())
+	require.EqualError(t, rejected, `map "root[9]" is not running`)
+	require.Equal(t, []MapTuning{{Path: "root[0]", Concurrency: 3, Inflight: 0, Remaining: 2}}, tuned)
+
+	// 限速每分钟 1 个；解除限制后剩余元素在 1 分钟内全部启动
+	var starts []time.Time
+	for _, ev := range queryTrace(t, env) {
+		if ev.Path == "root[0].map.body" && ev.Status == TraceStarted {
+			starts = append(starts, ev.Time)
+		}
+	}
+	require.Len(t, starts, 3)
+	require.Less(t, starts[2].Sub(starts[0]), time.Minute)
+	require.GreaterOrEqual(t, starts[1].Sub(starts[0]), 10*time.Second)
+}
+
+func TestSimpleDSLWorkflowPauseResume(t *testing.T) {
+	var paused Progress
+	env := startDSL(t, `
+taskQueue: demo
+variables:
+  urls: ["a", "b", "c"]
+root:
+  - map:
+      itemsRef: urls
+      itemVar: url
+      concurrency: 3
+      ratePerMinute: 1
+      collectVar: pages
+      body:
+        activity: { name: Fetch, args: [{ ref: url }], result: page }
+`, beforeRun(func(env *testsuite.TestWorkflowEnvironment) {
+		env.RegisterDelayedCallback(func() { env.SignalWorkflow(SignalPause, nil) }, 30*time.Second)
+		env.RegisterDelayedCallback(func() { paused = queryProgress(t, env) }, 5*time.Minute)
+		env.RegisterDelayedCallback(func() { env.SignalWorkflow(SignalResume, nil) }, 10*time.Minute)
+	}))
+	require.NoError(t, env.GetWorkflowError())
+	require.True(t, paused.Paused)
+	require.Equal(t, 1, paused.Completed, "only the first iteration ran before the pause")
+	require.Equal(t, []string{"root[0]"}, paused.Running)
+	// Body 已结束一次且当前不在执行，按定义节点计数为一半；剩余时间按已用 5 分钟线性外推
+	require.Equal(t, 1, paused.Done)
+	require.Equal(t, 2, paused.Total)
+	require.Equal(t, 50, paused.Percent)
+	require.EqualValues(t, 300, paused.ETASec)
+
+	// 暂停期间不开始新迭代，恢复后继续
+	require.False(t, queryProgress(t, env).Paused)
+	var starts []time.Time
+	for _, ev := range queryTrace(t, env) {
+		if ev.Path == "root[0].map.body" && ev.Status == TraceStarted {
+			starts = append(starts, ev.Time)
+		}
+	}
+	require.Len(t, starts, 3)
+	require.GreaterOrEqual(t, starts[1].Sub(starts[0]), 10*time.Minute)
+}
+
+func TestSimpleDSLWorkflowMapStream(t *testing.T) {
+	var pushed []MapItemResult
+	var partial map[string]any
+	env := startDSL(t, `
+taskQueue: demo
+variables:
+  urls: ["a", "b", "c"]
+  consumer: report-1
+root:
+  - map:
+      itemsRef: urls
+      itemVar: url
+      concurrency: 3
+      ratePerMinute: 1
+      collectVar: pages
+      streamTo: { workflowIdRef: consumer }
+      body:
+        activity: { name: Fetch, args: [{ ref: url }], result: page }
+`, beforeRun(func(env *testsuite.TestWorkflowEnvironment) {
+		env.OnSignalExternalWorkflow(mock.Anything, "report-1", "", SignalMapResult, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) { pushed = append(pushed, args.Get(4).(MapItemResult)) })
+		// 每分钟启动一个元素：90 秒时前两个已完成
+		env.RegisterDelayedCallback(func() {
+			v, err := env.QueryWorkflow(QueryBindings)
+			require.NoError(t, err)
+			require.NoError(t, v.Get(&partial))
+		}, 90*time.Second)
+	}))
+	require.NoError(t, env.GetWorkflowError())
+	require.Equal(t, []any{"content-of-a", "content-of-b"}, partial["pages"])
+
+	var result map[string]any
+	require.NoError(t, env.GetWorkflowResult(&result))
+	require.Equal(t, []any{"content-of-a", "content-of-b", "content-of-c"}, result["pages"])
+	require.Equal(t, []MapItemResult{
+		{Path: "root[0]", Index: 0, Value: "content-of-a", Completed: 1, Total: 3},
+		{Path: "root[0]", Index: 1, Value: "content-of-b", Completed: 2, Total: 3},
+		{Path: "root[0]", Index: 2, Value: "content-of-c", Completed: 3, Total: 3},
+	}, pushed)
+}
+
+func TestSimpleDSLWorkflowDebugger(t *testing.T) {
+	query := func(env *testsuite.TestWorkflowEnvironment) DebugState {
+		v, err := env.QueryWorkflow(QueryDebug)
+		require.NoError(t, err)
+		var st DebugState
+		require.NoError(t, v.Get(&st))
+		return st
+	}
+	var atBreakpoint, afterStep DebugState
+	env := startDSL(t, `
+taskQueue: demo
+variables:
+  x: 5
+breakpoints: [second]
+root:
+  - activity: { name: DoA, args: [{ ref: x }], result: a }
+  - id: second
+    activity: { name: DoB, args: [{ int: 2 }], result: b }
+  - activity: { name: DoC, args: [{ ref: a }, { ref: b }], result: c }
+`, beforeRun(func(env *testsuite.TestWorkflowEnvironment) {
+		env.RegisterDelayedCallback(func() {
+			atBreakpoint = query(env)
+			env.SignalWorkflow(SignalStep, nil)
+		}, time.Minute)
+		env.RegisterDelayedCallback(func() {
+			afterStep = query(env)
+			env.SignalWorkflow(SignalContinue, nil)
+		}, 2*time.Minute)
+	}))
+	require.NoError(t, env.GetWorkflowError())
+
+	// 断点停在 second 之前，可以看到前一个节点写入的变量
+	require.Len(t, atBreakpoint.Halted, 1)
+	require.Equal(t, "root[1]", atBreakpoint.Halted[0].Path)
+	require.Equal(t, "breakpoint", atBreakpoint.Halted[0].Reason)
+	require.Equal(t, "A:5", atBreakpoint.Halted[0].Bindings["a"])
+	require.NotContains(t, atBreakpoint.Halted[0].Bindings, "b")
+
+	// step 执行一个节点后停在下一个节点之前
+	require.True(t, afterStep.Stepping)
+	require.Len(t, afterStep.Halted, 1)
+	require.Equal(t, "root[2]", afterStep.Halted[0].Path)
+	require.Equal(t, "step", afterStep.Halted[0].Reason)
+	require.Contains(t, afterStep.Halted[0].Bindings, "b")
+
+	st := query(env)
+	require.False(t, st.Stepping)
+	require.Empty(t, st.Halted)
+	require.Equal(t, []string{"second"}, st.Breakpoints)
+}
+
+func TestSimpleDSLWorkflowOutputSchema(t *testing.T) {
+	const def = `
+taskQueue: demo
+variables:
+  x: 5
+root:
+  - activity: { name: DoA, args: [{ ref: x }], result: a }
+outputSchema:
+  type: object
+  required: [a, %s]
+  additionalProperties: false
+  properties:
+    a: { type: string, pattern: "^A:" }
+    x: { type: integer }
+`
+	run := func(required string) error {
+		return startDSL(t, fmt.Sprintf(def, required)).GetWorkflowError()
+	}
+
+	require.NoError(t, run("x"))
+
+	// 结果缺少 schema 要求的变量时工作流失败，错误类型固定，details 列出全部问题
+	err := run("total")
+	var appErr *temporal.ApplicationError
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, "OutputSchemaViolation", appErr.Type())
+	require.True(t, appErr.NonRetryable())
+	var problems []string
+	require.NoError(t, appErr.Details(&problems))
+	require.Equal(t, []string{`/: missing required property "total"`}, problems)
+}
+
+func TestSimpleDSLWorkflowMarker(t *testing.T) {
+	run := func(refs string) (*testsuite.TestWorkflowEnvironment, error) {
+		env := startDSL(t, `
+taskQueue: demo
+variables:
+  x: 5
+root:
+  - activity: { name: DoA, args: [{ ref: x }], result: a }
+  - marker: { name: payment captured, refs: [`+refs+`] }
+`)
+		return env, env.GetWorkflowError()
+	}
+
+	env, err := run("a, x")
+	require.NoError(t, err)
+	trace := queryTrace(t, env)
+	last := trace[len(trace)-1]
+	require.Equal(t, "root[1]", last.Path)
+	require.Equal(t, KindMarker, last.Kind)
+	require.Equal(t, TraceCompleted, last.Status)
+
+	// 运行时缺少要记录的变量视为错误，避免审计记录悄悄缺项
+	_, err = run("missing")
+	require.ErrorContains(t, err, `marker "payment captured": ref "missing" not found`)
+}
+
+// captureLogger 收集工作流日志，供断言 log 语句的输出
+type captureLogger struct{ lines []string }
+
+func (l *captureLogger) add(level, msg string)              { l.lines = append(l.lines, level+" "+msg) }
+func (l *captureLogger) Debug(msg string, _ ...interface{}) { l.add("DEBUG", msg) }
+func (l *captureLogger) Info(msg string, _ ...interface{})  { l.add("INFO", msg) }
+func (l *captureLogger) Warn(msg string, _ ...interface{})  { l.add("WARN", msg) }
+func (l *captureLogger) Error(msg string, _ ...interface{}) { l.add("ERROR", msg) }
+
+func TestSimpleDSLWorkflowLog(t *testing.T) {
+	logger := &captureLogger{}
+	env := startDSL(t, `
+taskQueue: demo
+variables:
+  x: 5
+root:
+  - activity: { name: DoA, args: [{ ref: x }], result: a }
+  - log: { message: "x={{.x}} a={{.a}}" }
+  - log: { level: warn, message: "{{upper .a}} looks odd" }
+  - log: { message: "{{.notYet}}" }
+`, withLogger(logger))
+	require.NoError(t, env.GetWorkflowError(), "a log statement never fails the workflow")
+
+	require.Contains(t, logger.lines, "INFO x=5 a=A:5")
+	require.Contains(t, logger.lines, "WARN A:5 looks odd")
+	require.Contains(t, logger.lines, "WARN DSL log message could not be rendered")
+}
+
+func TestSimpleDSLWorkflowNoop(t *testing.T) {
+	// disabled 中的语句不参与校验
+	bindings := runDSL(t, `
+taskQueue: demo
+variables:
+  x: 5
+root:
+  - noop: { description: "TODO: charge the card" }
+  - if:
+      cond: { truthy: { ref: x } }
+      then:
+        noop:
+          description: switched off while testing
+          disabled:
+            activity: { args: [{ ref: undefinedVar }] }
+  - activity: { name: DoA, args: [{ ref: x }], result: a }
+`, strictCheck())
+	require.Equal(t, map[string]any{"x": float64(5), "a": "A:5"}, bindings)
+}
+
+func TestSimpleDSLWorkflowWhen(t *testing.T) {
+	const def = `
+taskQueue: demo
+variables:
+  x: 5
+  express: false
+  items: [1, 2, 3]
+root:
+  - id: expedite
+    when: { truthy: { ref: express } }
+    activity: { name: DoA, args: [{ ref: x }], result: a }
+  - when: { eq: { left: { ref: x }, right: { int: 5 } } }
+    activity: { name: DoB, args: [{ ref: x }], result: b }
+  - map:
+      itemsRef: items
+      collectVar: out
+      body:
+        when: { ne: { left: { ref: _item }, right: { int: 2 } } }
+        activity: { name: ProcessItem, args: [{ ref: _item }], result: r }
+`
+	wf, err := ParseYAML([]byte(def))
+	require.NoError(t, err)
+	require.False(t, HasErrors(wf.Check(CheckOptions{KnownActivities: ActivityNames()})))
+
+	env := startDSL(t, def)
+	require.NoError(t, env.GetWorkflowError())
+	var bindings map[string]any
+	require.NoError(t, env.GetWorkflowResult(&bindings))
+	require.NotContains(t, bindings, "a")
+	require.Equal(t, "B:5", bindings["b"])
+	require.Len(t, bindings["out"], 2)
+
+	var skipped []TraceEvent
+	for _, ev := range queryTrace(t, env) {
+		if ev.Status == TraceSkipped {
+			skipped = append(skipped, ev)
+		}
+	}
+	require.Len(t, skipped, 2)
+	require.Equal(t, "root[0]", skipped[0].Path)
+	require.Equal(t, "expedite", skipped[0].ID)
+	require.Equal(t, "when express is false", skipped[0].Reason)
+	require.Equal(t, "root[2].map.body", skipped[1].Path)
+	require.Equal(t, "when _item != 2 is false", skipped[1].Reason)
+
+	progress := queryProgress(t, env)
+	require.Equal(t, 2, progress.Skipped)
+	require.Equal(t, 100, progress.Percent)
+}
+
+func TestSimpleDSLWorkflowMutations(t *testing.T) {
+	bindings := runDSL(t, `
+taskQueue: demo
+variables:
+  x: 5
+  seen: ["start"]
+  config: { retry: { max: 3, backoff: 2 }, region: eu }
+  override: { retry: { max: 5 }, dryRun: true }
+  scratch: temp
+root:
+  - activity: { name: DoA, args: [{ ref: x }], result: a }
+  - append: { to: seen, value: { ref: a } }
+  - append: { to: seen, value: { int: 7 } }
+  - append: { to: fresh, value: { str: first } }
+  - merge: { into: config, value: { ref: override } }
+  - unset: { vars: [scratch, override] }
+`, strictCheck())
+	require.Equal(t, []any{"start", "A:5", float64(7)}, bindings["seen"])
+	require.Equal(t, []any{"first"}, bindings["fresh"])
+	require.Equal(t, map[string]any{
+		"retry":  map[string]any{"max": float64(5), "backoff": float64(2)},
+		"region": "eu",
+		"dryRun": true,
+	}, bindings["config"])
+	require.NotContains(t, bindings, "scratch")
+	require.NotContains(t, bindings, "override")
+}
+
+func TestSimpleDSLWorkflowIncrement(t *testing.T) {
+	bindings := runDSL(t, `
+taskQueue: demo
+variables:
+  attempts: 0
+  step: 2
+  ratio: 0.5
+root:
+  - while:
+      cond: { ne: { left: { ref: attempts }, right: { int: 3 } } }
+      maxIters: 10
+      body:
+        increment: { var: attempts }
+  - increment: { var: cursor, by: { ref: step } }
+  - increment: { var: cursor, by: { int: 100 }, max: 50 }
+  - increment: { var: floor, by: { int: -7 }, min: -5 }
+  - increment: { var: ratio, by: { float: 0.25 } }
+`, strictCheck())
+	require.Equal(t, float64(3), bindings["attempts"])
+	require.Equal(t, float64(50), bindings["cursor"])
+	require.Equal(t, float64(-5), bindings["floor"])
+	require.Equal(t, 0.75, bindings["ratio"])
+
+	local := map[string]any{"n": 1, "s": "x"}
+	require.NoError(t, Increment{Var: "n"}.execute(local))
+	require.Equal(t, int64(2), local["n"])
+	require.EqualError(t, Increment{Var: "s"}.execute(local), `increment "s": variable is string, not a number`)
+}
+
+func TestSimpleDSLWorkflowRandom(t *testing.T) {
+	bindings := runDSL(t, `
+taskQueue: demo
+variables:
+  regions: [eu, us, ap]
+root:
+  - random: { result: dice, int: { min: 1, max: 6 } }
+  - random: { result: ratio, float: { min: 0, max: 0.5 } }
+  - random: { result: variant, choice: [{ str: A }, { str: B }] }
+  - random: { result: region, from: regions }
+  - if:
+      cond: { eq: { left: { ref: variant }, right: { str: A } } }
+      then:
+        activity: { name: DoA, args: [{ ref: dice }], result: out }
+      else:
+        activity: { name: DoB, args: [{ ref: dice }], result: out }
+`, strictCheck())
+	require.Contains(t, []float64{1, 2, 3, 4, 5, 6}, bindings["dice"])
+	require.GreaterOrEqual(t, bindings["ratio"], 0.0)
+	require.Less(t, bindings["ratio"], 0.5)
+	require.Contains(t, []any{"A", "B"}, bindings["variant"])
+	require.Contains(t, []any{"eu", "us", "ap"}, bindings["region"])
+	require.Equal(t, fmt.Sprintf("%s:%v", bindings["variant"], bindings["dice"]), bindings["out"])
+
+	require.EqualError(t, Random{Result: "x", From: "regions"}.execute(nil, map[string]any{"regions": "eu"}),
+		`random "x": "regions" is string, not a list`)
+
+	// 超过 ±2^53 的范围会让 hi-lo+1 溢出，由 Check 拒绝
+	var wf Workflow
+	require.NoError(t, yaml.Unmarshal([]byte("root: [{ random: { result: x, int: { min: -9.3e18, max: 9.3e18 } } }]"), &wf))
+	require.EqualError(t, wf.Validate(), "root[0]: random int range [-9.3e+18, 9.3e+18] exceeds ±9007199254740992")
+}
+
+func TestSimpleDSLWorkflowNow(t *testing.T) {
+	start := time.Date(2024, 3, 9, 17, 41, 30, 0, time.UTC)
+	bindings := runDSL(t, `
+taskQueue: demo
+root:
+  - now: { result: ts }
+  - now: { result: day, format: date, timezone: Asia/Shanghai }
+  - now: { result: epoch, format: epoch, truncate: hour }
+  - now: { result: millis, format: epochMillis }
+  - now: { result: slot, format: "15:04", truncate: 15m }
+  - now: { result: midnight, truncate: day, timezone: Asia/Shanghai }
+`, strictCheck(), startAt(start))
+	require.Equal(t, "2024-03-09T17:41:30Z", bindings["ts"])
+	require.Equal(t, "2024-03-10", bindings["day"])
+	require.Equal(t, float64(start.Truncate(time.Hour).Unix()), bindings["epoch"])
+	require.Equal(t, float64(start.UnixMilli()), bindings["millis"])
+	require.Equal(t, "17:30", bindings["slot"])
+	require.Equal(t, "2024-03-10T00:00:00+08:00", bindings["midnight"])
+}
+
+func TestSimpleDSLWorkflowNewID(t *testing.T) {
+	bindings := runDSL(t, `
+taskQueue: demo
+root:
+  - newId: { result: key }
+  - newId: { result: order, prefix: ord_, format: hex, length: 12 }
+  - activity: { name: DoC, args: [{ ref: order }, { ref: key }], result: joined }
+`, strictCheck())
+	require.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[0-9a-f]{4}-[0-9a-f]{12}$`, bindings["key"])
+	require.Regexp(t, `^ord_[0-9a-f]{12}$`, bindings["order"])
+	require.Equal(t, fmt.Sprintf("C(%s+%s)", bindings["order"], bindings["key"]), bindings["joined"])
+}
+
+func TestSimpleDSLWorkflowAggregate(t *testing.T) {
+	bindings := runDSL(t, `
+taskQueue: demo
+variables:
+  items: [3, 1, 4]
+  orders: [{ id: a, amount: 2.5 }, { id: b, amount: 4 }]
+  none: []
+root:
+  - map:
+      itemsRef: items
+      body:
+        increment: { var: score, by: { ref: _item } }
+      collectVar: score
+  - aggregate: { op: sum, from: score, result: total }
+  - aggregate: { op: avg, from: score, result: mean }
+  - aggregate: { op: min, from: score, result: lowest }
+  - aggregate: { op: max, from: orders, field: amount, result: biggest }
+  - aggregate: { op: count, from: orders, result: n }
+  - aggregate: { op: max, from: none, result: empty }
+  - if:
+      cond: { eq: { left: { ref: total }, right: { int: 8 } } }
+      then:
+        noop: {}
+`, strictCheck())
+	require.Equal(t, float64(8), bindings["total"])
+	require.InDelta(t, 8.0/3, bindings["mean"], 1e-9)
+	require.Equal(t, float64(1), bindings["lowest"])
+	require.Equal(t, float64(4), bindings["biggest"])
+	require.Equal(t, float64(2), bindings["n"])
+	require.Contains(t, bindings, "empty")
+	require.Nil(t, bindings["empty"])
+
+	require.EqualError(t, Aggregate{Op: AggSum, From: "orders", Result: "x"}.execute(map[string]any{"orders": []any{map[string]any{}}}),
+		`aggregate "x": orders[0] is map[string]interface {}, not a number`)
+}
+
+func TestSimpleDSLWorkflowSetWithFunc(t *testing.T) {
+	bindings := runDSL(t, `
+taskQueue: demo
+variables:
+  email: "  Ada@Example.com "
+root:
+  - set: { var: email, value: { fn: { name: lower, args: [{ fn: { name: trim, args: [{ ref: email }] } }] } } }
+  - set: { var: domain, value: { fn: { name: substring, args: [{ ref: email }, { int: 4 }] } } }
+  - activity:
+      name: DoC
+      args:
+        - { fn: { name: upper, args: [{ ref: domain }] } }
+        - { fn: { name: format, args: [{ str: "%s/%d" }, { ref: email }, { int: 7 }] } }
+      result: out
+`, strictCheck())
+	require.Equal(t, "ada@example.com", bindings["email"])
+	require.Equal(t, "example.com", bindings["domain"])
+	require.Equal(t, "C(EXAMPLE.COM+ada@example.com/7)", bindings["out"])
+}
+
+func TestSimpleDSLWorkflowWait(t *testing.T) {
+	bindings := runDSL(t, `
+taskQueue: demo
+variables:
+  delay: 90
+  deadline: "2024-03-09T12:10:00Z"
+root:
+  - wait: { seconds: 60 }
+  - wait: { secondsRef: delay }
+  - wait: { untilRef: deadline }
+  - wait: { until: "2024-03-09T00:00:00Z" }
+  - now: { result: done }
+`, strictCheck(), startAt(time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)))
+	// 60s + 90s 之后到 12:02:30，再等到 12:10:00；过去的时间不等待
+	require.Equal(t, "2024-03-09T12:10:00Z", bindings["done"])
+}
+
+func TestMutationTypeErrors(t *testing.T) {
+	bindings := map[string]any{"n": 1, "obj": map[string]any{"a": 1}}
+	require.EqualError(t, Append{To: "n", Value: Value{Ref: "obj"}}.execute(bindings), `append to "n": variable is int, not a list`)
+	require.EqualError(t, Merge{Into: "obj", Value: Value{Ref: "n"}}.execute(bindings), `merge into "obj": value is int, not an object`)
+	require.EqualError(t, Merge{Into: "n", Value: Value{Ref: "obj"}}.execute(bindings), `merge into "n": variable is int, not an object`)
+
+	// 合并产生新对象，不改动被引用的原对象
+	orig := bindings["obj"].(map[string]any)
+	require.NoError(t, Merge{Into: "copy", Value: Value{Ref: "obj"}}.execute(bindings))
+	bindings["copy"].(map[string]any)["a"] = 2
+	require.Equal(t, 1, orig["a"])
+}
+
+func TestSimpleDSLWorkflowOnCancel(t *testing.T) {
+	const def = `
+variables: { order: o-1 }
+root:
+  - activity: { name: DoA, args: [{ int: 1 }], result: reserved }
+  - wait: { seconds: 3600 }
+onCancel:
+  - wait: { seconds: 5 }
+  - activity: { name: %s, args: [{ ref: reserved }, { ref: order }] }
+`
+	run := func(cleanup string) ([]string, error) {
+		var started []string
+		env := startDSL(t, fmt.Sprintf(def, cleanup), beforeRun(func(env *testsuite.TestWorkflowEnvironment) {
+			env.SetOnActivityStartedListener(func(info *activity.Info, _ context.Context, _ converter.EncodedValues) {
+				started = append(started, info.ActivityType.Name)
+			})
+			env.RegisterDelayedCallback(env.CancelWorkflow, time.Minute)
+		}))
+		return started, env.GetWorkflowError()
+	}
+
+	// 取消后 onCancel 在断开的 context 中执行（计时器与 Activity 不受取消影响），之后工作流报告取消
+	started, err := run("DoC")
+	var canceled *temporal.CanceledError
+	require.True(t, errors.As(err, &canceled), "%v", err)
+	require.Equal(t, []string{"DoA", "DoC"}, started)
+
+	// onCancel 失败时工作流以该错误失败
+	_, err = run("Missing")
+	f, ok := FailureOf(err)
+	require.True(t, ok, "%v", err)
+	require.Equal(t, "onCancel[1]", f.Path)
+
+	// onCancel 中的语句与 root 一样校验
+	wf := Workflow{Root: []*Statement{{Noop: &Noop{}}}, OnCancel: []*Statement{{}}}
+	issues := wf.Check(CheckOptions{})
+	require.True(t, HasErrors(issues))
+	require.Equal(t, "onCancel[0]", issues[0].Path)
+}